@@ -0,0 +1,89 @@
+package credential
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+)
+
+// defaultBatchCacheSize bounds the canonicalization cache shared across a batch. Credentials are rarely identical,
+// but the proof options signed alongside them, which only vary by their creation time, often are.
+const defaultBatchCacheSize = 64
+
+// BatchSignOptions configures how SignBatch signs credentials
+type BatchSignOptions struct {
+	// Suite signs each credential. It defaults to a JsonWebSignature2020 suite which shares a document loader,
+	// preloaded with the contexts bundled with the SDK, and a canonicalization cache across the batch.
+	Suite cryptosuite.CryptoSuite
+	// Parallelism is the number of credentials signed concurrently, defaulting to 1. The signer and suite must be
+	// safe for concurrent use when it is greater than 1.
+	Parallelism int
+	// SignOptions are provided to the suite when signing each credential
+	SignOptions []cryptosuite.Option
+}
+
+// SignBatch adds a Data Integrity proof to each of the given credentials, for issuers producing many credentials at
+// once. Contexts are loaded and the suite is set up once for the whole batch, rather than once per credential.
+// The signed credentials are returned in the order given, and the given credentials are not modified. If any
+// credential cannot be signed, an error is returned for the first such credential and no credentials are returned.
+func SignBatch(signer cryptosuite.Signer, creds []VerifiableCredential, opts BatchSignOptions) ([]VerifiableCredential, error) {
+	if signer == nil {
+		return nil, errors.New("signer cannot be empty")
+	}
+	suite := opts.Suite
+	if suite == nil {
+		loader, err := cryptosuite.NewDefaultDocumentLoader()
+		if err != nil {
+			return nil, errors.Wrap(err, "creating document loader")
+		}
+		cache, err := cryptosuite.NewCanonicalizationCache(defaultBatchCacheSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating canonicalization cache")
+		}
+		if suite, err = jws2020.NewJSONWebSignature2020Suite(cryptosuite.WithDocumentLoader(loader), cryptosuite.WithCanonicalizationCache(cache)); err != nil {
+			return nil, errors.Wrap(err, "creating suite")
+		}
+	}
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	signed := make([]VerifiableCredential, len(creds))
+	errs := make([]error, len(creds))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism && w < len(creds); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				cred := creds[i]
+				if cred.IsEmpty() {
+					errs[i] = errors.New("credential cannot be empty")
+					continue
+				}
+				if err := suite.Sign(signer, &cred, opts.SignOptions...); err != nil {
+					errs[i] = err
+					continue
+				}
+				signed[i] = cred
+			}
+		}()
+	}
+	for i := range creds {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.Wrapf(err, "signing credential<%d>", i)
+		}
+	}
+	return signed, nil
+}
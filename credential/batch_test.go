@@ -0,0 +1,67 @@
+package credential
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+)
+
+func TestSignBatch(t *testing.T) {
+	issuerID := "did:example:issuer"
+	jwk, err := jws2020.GenerateJSONWebKey2020(jws2020.OKP, jws2020.Ed25519)
+	require.NoError(t, err)
+	signer, err := jws2020.NewJSONWebKeySigner(issuerID, jwk.PrivateKeyJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+	verifier, err := jws2020.NewJSONWebKeyVerifier(issuerID, jwk.PublicKeyJWK)
+	require.NoError(t, err)
+
+	var creds []VerifiableCredential
+	for i := 0; i < 5; i++ {
+		creds = append(creds, VerifiableCredential{
+			Context:           []any{VerifiableCredentialsLinkedDataContext, "https://w3id.org/security/suites/jws-2020/v1"},
+			ID:                fmt.Sprintf("urn:uuid:credential-%d", i),
+			Type:              []any{VerifiableCredentialType},
+			Issuer:            issuerID,
+			IssuanceDate:      "2023-01-01T00:00:00Z",
+			CredentialSubject: map[string]any{"id": fmt.Sprintf("did:example:subject-%d", i)},
+		})
+	}
+
+	t.Run("sign batch", func(tt *testing.T) {
+		signed, err := SignBatch(signer, creds, BatchSignOptions{})
+		assert.NoError(tt, err)
+		assert.Len(tt, signed, len(creds))
+		suite := jws2020.GetJSONWebSignature2020Suite()
+		for i := range signed {
+			assert.Equal(tt, creds[i].ID, signed[i].ID)
+			assert.NoError(tt, suite.Verify(verifier, &signed[i]))
+			// the given credentials are not modified
+			assert.Nil(tt, creds[i].Proof)
+		}
+	})
+
+	t.Run("sign batch in parallel", func(tt *testing.T) {
+		signed, err := SignBatch(signer, creds, BatchSignOptions{Parallelism: 3, SignOptions: []cryptosuite.Option{cryptosuite.WithProofValue()}})
+		assert.NoError(tt, err)
+		assert.Len(tt, signed, len(creds))
+		for i := range signed {
+			assert.Equal(tt, creds[i].ID, signed[i].ID)
+			assert.NotEmpty(tt, signed[i].GetProof().ProofValue)
+		}
+	})
+
+	t.Run("invalid credential", func(tt *testing.T) {
+		invalid := append([]VerifiableCredential{}, creds...)
+		invalid[2] = VerifiableCredential{}
+		_, err := SignBatch(signer, invalid, BatchSignOptions{Parallelism: 2})
+		assert.ErrorContains(tt, err, "signing credential<2>: credential cannot be empty")
+
+		_, err = SignBatch(nil, creds, BatchSignOptions{})
+		assert.ErrorContains(tt, err, "signer cannot be empty")
+	})
+}
@@ -22,6 +22,10 @@ const (
 	VerifiableCredentialJSONSchemaProperty string = "jsonSchema"
 	VerifiablePresentationType             string = "VerifiablePresentation"
 
+	// VerifiableCredentialsV2LinkedDataContext is the base context of v2.0 of the data model
+	// https://www.w3.org/TR/vc-data-model-2.0/#base-context
+	VerifiableCredentialsV2LinkedDataContext string = "https://www.w3.org/ns/credentials/v2"
+
 	BuilderEmptyError string = "builder cannot be empty"
 
 	EmptyIDValue    IDValue = ""         // EmptyIDValue indicates setting the ID value to empty
@@ -213,6 +217,24 @@ func (vcb *VerifiableCredentialBuilder) SetCredentialSubject(subject CredentialS
 	return nil
 }
 
+// AddCredentialSubject adds a subject to the credential, making its credentialSubject an array once it has more than
+// one subject, as for a credential about a group or a role held by several parties.
+func (vcb *VerifiableCredentialBuilder) AddCredentialSubject(subject CredentialSubject) error {
+	if vcb.IsEmpty() {
+		return errors.New(BuilderEmptyError)
+	}
+
+	if len(subject) == 0 {
+		return errors.New("credential subject cannot be empty")
+	}
+	if vcb.CredentialSubject == nil {
+		vcb.CredentialSubject = subject
+		return nil
+	}
+	vcb.AdditionalCredentialSubjects = append(vcb.AdditionalCredentialSubjects, subject)
+	return nil
+}
+
 func (vcb *VerifiableCredentialBuilder) SetCredentialSchema(schema CredentialSchema) error {
 	if vcb.IsEmpty() {
 		return errors.New(BuilderEmptyError)
@@ -251,13 +273,18 @@ func (vcb *VerifiableCredentialBuilder) SetTermsOfUse(terms []TermsOfUse) error
 	return nil
 }
 
-func (vcb *VerifiableCredentialBuilder) SetEvidence(evidence []any) error {
+func (vcb *VerifiableCredentialBuilder) SetEvidence(evidence []Evidence) error {
 	if vcb.IsEmpty() {
 		return errors.New(BuilderEmptyError)
 	}
 	if len(evidence) == 0 {
 		return errors.New("evidence cannot be empty")
 	}
+	for i, e := range evidence {
+		if err := e.IsValid(); err != nil {
+			return errors.Wrapf(err, "evidence<%d> is not valid", i)
+		}
+	}
 
 	vcb.Evidence = evidence
 	return nil
@@ -362,6 +389,18 @@ func (vpb *VerifiablePresentationBuilder) SetPresentationSubmission(ps any) erro
 	return nil
 }
 
+func (vpb *VerifiablePresentationBuilder) SetTermsOfUse(terms []TermsOfUse) error {
+	if vpb.IsEmpty() {
+		return errors.New(BuilderEmptyError)
+	}
+	if len(terms) == 0 {
+		return errors.New("terms of use cannot be empty")
+	}
+
+	vpb.TermsOfUse = terms
+	return nil
+}
+
 // AddVerifiableCredentials appends the given credentials to the verifiable presentation.
 // It does not check for duplicates.
 func (vpb *VerifiablePresentationBuilder) AddVerifiableCredentials(creds ...any) error {
@@ -374,3 +413,16 @@ func (vpb *VerifiablePresentationBuilder) AddVerifiableCredentials(creds ...any)
 	vpb.VerifiableCredential = append(vpb.VerifiableCredential, creds...)
 	return nil
 }
+
+// AddEnvelopedVerifiableCredential envelops a secured credential of the given media type, such as a `vc+jwt` or
+// `vc+sd-jwt`, and appends it to the verifiable presentation.
+func (vpb *VerifiablePresentationBuilder) AddEnvelopedVerifiableCredential(mediaType string, secured []byte) error {
+	if vpb.IsEmpty() {
+		return errors.New(BuilderEmptyError)
+	}
+	if mediaType == "" || len(secured) == 0 {
+		return errors.New("enveloped credential must have a media type and content")
+	}
+	vpb.VerifiableCredential = append(vpb.VerifiableCredential, NewEnvelopedVerifiableCredential(mediaType, secured))
+	return nil
+}
@@ -232,12 +232,17 @@ func TestCredentialBuilder(t *testing.T) {
 	assert.NoError(t, err)
 
 	// empty evidence
-	err = builder.SetEvidence([]any{})
+	err = builder.SetEvidence([]Evidence{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "evidence cannot be empty")
 
+	// evidence without a type
+	err = builder.SetEvidence([]Evidence{{"id": "https://example.edu/evidence/1"}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "evidence must have a type")
+
 	// valid evidence
-	evidence := []any{"evidence"}
+	evidence := []Evidence{{"id": "https://example.edu/evidence/1", "type": "DocumentVerification"}}
 	err = builder.SetEvidence(evidence)
 	assert.NoError(t, err)
 
@@ -256,6 +261,25 @@ func TestCredentialBuilder(t *testing.T) {
 	assert.Equal(t, terms, cred.TermsOfUse)
 }
 
+func TestCredentialBuilderMultipleSubjects(t *testing.T) {
+	builder := NewVerifiableCredentialBuilder(GenerateIDValue)
+	assert.NoError(t, builder.SetIssuer("did:example:issuer"))
+
+	err := builder.AddCredentialSubject(CredentialSubject{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "credential subject cannot be empty")
+
+	alice := CredentialSubject{"id": "did:example:alice", "role": "lead"}
+	bob := CredentialSubject{"id": "did:example:bob", "role": "engineer"}
+	assert.NoError(t, builder.AddCredentialSubject(alice))
+	assert.NoError(t, builder.AddCredentialSubject(bob))
+
+	cred, err := builder.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, alice, cred.CredentialSubject)
+	assert.Equal(t, []CredentialSubject{alice, bob}, cred.CredentialSubjects())
+}
+
 func TestVerifiablePresentationBuilder(t *testing.T) {
 	badBuilder := VerifiablePresentationBuilder{}
 	_, err := badBuilder.Build()
@@ -0,0 +1,157 @@
+package credential
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/gowebpki/jcs"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// CredentialDifference is a difference between two credentials at a JSON pointer https://www.rfc-editor.org/rfc/rfc6901
+// Old is nil when the value was added, and New is nil when the value was removed.
+type CredentialDifference struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+func (d CredentialDifference) String() string {
+	switch {
+	case d.Old == nil:
+		return fmt.Sprintf("%s: added %v", d.Path, d.New)
+	case d.New == nil:
+		return fmt.Sprintf("%s: removed %v", d.Path, d.Old)
+	default:
+		return fmt.Sprintf("%s: %v -> %v", d.Path, d.Old, d.New)
+	}
+}
+
+// CanonicalizeCredential returns the JCS canonical form https://www.rfc-editor.org/rfc/rfc8785 of a credential without
+// its proof, in which equivalent representations of the same claims are normalized: a single `@context` or `type` is
+// an array, types are sorted, and an issuer with only an `id` is its id. Two credentials with the same canonical form
+// make the same claims, even if they were issued, or re-issued, with different proofs.
+func CanonicalizeCredential(cred VerifiableCredential) ([]byte, error) {
+	normalized, err := normalizeCredential(cred)
+	if err != nil {
+		return nil, err
+	}
+	normalizedBytes, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling normalized credential")
+	}
+	canonical, err := jcs.Transform(normalizedBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing credential")
+	}
+	return canonical, nil
+}
+
+// SemanticallyEqual returns whether two credentials make the same claims, comparing their canonical forms as given by
+// CanonicalizeCredential, so their proofs are ignored
+func SemanticallyEqual(a, b VerifiableCredential) (bool, error) {
+	canonicalA, err := CanonicalizeCredential(a)
+	if err != nil {
+		return false, errors.Wrap(err, "canonicalizing first credential")
+	}
+	canonicalB, err := CanonicalizeCredential(b)
+	if err != nil {
+		return false, errors.Wrap(err, "canonicalizing second credential")
+	}
+	return bytes.Equal(canonicalA, canonicalB), nil
+}
+
+// DiffCredentials returns the differences between the normalized forms of two credentials, as described by
+// CanonicalizeCredential, sorted by path. Objects are compared property by property and arrays element by element, so
+// no differences are returned for semantically equal credentials.
+func DiffCredentials(a, b VerifiableCredential) ([]CredentialDifference, error) {
+	normalizedA, err := normalizeCredential(a)
+	if err != nil {
+		return nil, errors.Wrap(err, "normalizing first credential")
+	}
+	normalizedB, err := normalizeCredential(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "normalizing second credential")
+	}
+	var differences []CredentialDifference
+	diffValues("", normalizedA, normalizedB, &differences)
+	sort.SliceStable(differences, func(i, j int) bool { return differences[i].Path < differences[j].Path })
+	return differences, nil
+}
+
+// normalizeCredential converts a credential without its proof into its normalized JSON form
+func normalizeCredential(cred VerifiableCredential) (map[string]any, error) {
+	cred.Proof = nil
+	credMap, err := util.ToJSONMap(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting credential to json map")
+	}
+	for _, property := range []string{"@context", "type"} {
+		if value, ok := credMap[property]; ok {
+			if _, isArray := value.([]any); !isArray {
+				credMap[property] = []any{value}
+			}
+		}
+	}
+	if types, ok := credMap["type"].([]any); ok {
+		sort.SliceStable(types, func(i, j int) bool { return fmt.Sprint(types[i]) < fmt.Sprint(types[j]) })
+	}
+	if issuer, ok := credMap["issuer"].(map[string]any); ok && len(issuer) == 1 {
+		if id, ok := issuer[VerifiableCredentialIDProperty]; ok {
+			credMap["issuer"] = id
+		}
+	}
+	return credMap, nil
+}
+
+func diffValues(path string, a, b any, differences *[]CredentialDifference) {
+	mapA, aIsMap := a.(map[string]any)
+	mapB, bIsMap := b.(map[string]any)
+	if aIsMap && bIsMap {
+		for k, v := range mapA {
+			if other, ok := mapB[k]; ok {
+				diffValues(path+"/"+escapeJSONPointerToken(k), v, other, differences)
+			} else {
+				*differences = append(*differences, CredentialDifference{Path: path + "/" + escapeJSONPointerToken(k), Old: v})
+			}
+		}
+		for k, v := range mapB {
+			if _, ok := mapA[k]; !ok {
+				*differences = append(*differences, CredentialDifference{Path: path + "/" + escapeJSONPointerToken(k), New: v})
+			}
+		}
+		return
+	}
+
+	arrayA, aIsArray := a.([]any)
+	arrayB, bIsArray := b.([]any)
+	if aIsArray && bIsArray {
+		for i := 0; i < len(arrayA) || i < len(arrayB); i++ {
+			elementPath := fmt.Sprintf("%s/%d", path, i)
+			switch {
+			case i >= len(arrayA):
+				*differences = append(*differences, CredentialDifference{Path: elementPath, New: arrayB[i]})
+			case i >= len(arrayB):
+				*differences = append(*differences, CredentialDifference{Path: elementPath, Old: arrayA[i]})
+			default:
+				diffValues(elementPath, arrayA[i], arrayB[i], differences)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*differences = append(*differences, CredentialDifference{Path: path, Old: a, New: b})
+	}
+}
+
+// escapeJSONPointerToken escapes a property name for use in a JSON pointer
+func escapeJSONPointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,77 @@
+package credential
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func getComparisonCredential() VerifiableCredential {
+	return VerifiableCredential{
+		Context:      VerifiableCredentialsLinkedDataContext,
+		ID:           "https://example.com/credentials/1872",
+		Type:         []any{"VerifiableCredential", "AlumniCredential"},
+		Issuer:       map[string]any{"id": "did:example:issuer"},
+		IssuanceDate: "2010-01-01T19:23:24Z",
+		CredentialSubject: CredentialSubject{
+			"id":       "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			"alumniOf": map[string]any{"name": "Example University", "degrees": []any{"BSc", "MSc"}},
+		},
+	}
+}
+
+func TestSemanticallyEqual(t *testing.T) {
+	t.Run("equivalent representations", func(tt *testing.T) {
+		a := getComparisonCredential()
+		b := getComparisonCredential()
+		b.Context = []any{VerifiableCredentialsLinkedDataContext}
+		b.Type = []any{"AlumniCredential", "VerifiableCredential"}
+		b.Issuer = "did:example:issuer"
+		b.Proof = &crypto.Proof{Type: "Ed25519Signature2020"}
+
+		equal, err := SemanticallyEqual(a, b)
+		require.NoError(tt, err)
+		assert.True(tt, equal)
+
+		differences, err := DiffCredentials(a, b)
+		require.NoError(tt, err)
+		assert.Empty(tt, differences)
+	})
+
+	t.Run("different claims", func(tt *testing.T) {
+		a := getComparisonCredential()
+		b := getComparisonCredential()
+		b.CredentialSubject["alumniOf"] = map[string]any{"name": "Example University", "degrees": []any{"BSc"}}
+
+		equal, err := SemanticallyEqual(a, b)
+		require.NoError(tt, err)
+		assert.False(tt, equal)
+	})
+}
+
+func TestDiffCredentials(t *testing.T) {
+	a := getComparisonCredential()
+	b := getComparisonCredential()
+	b.IssuanceDate = "2011-01-01T19:23:24Z"
+	b.ExpirationDate = "2030-01-01T19:23:24Z"
+	b.CredentialSubject = CredentialSubject{
+		"id":       "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		"alumniOf": map[string]any{"degrees": []any{"BSc"}},
+		"a/b":      "escaped",
+	}
+
+	differences, err := DiffCredentials(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []CredentialDifference{
+		{Path: "/credentialSubject/alumniOf/degrees/1", Old: "MSc"},
+		{Path: "/credentialSubject/alumniOf/name", Old: "Example University"},
+		{Path: "/credentialSubject/a~1b", New: "escaped"},
+		{Path: "/expirationDate", New: "2030-01-01T19:23:24Z"},
+		{Path: "/issuanceDate", Old: "2010-01-01T19:23:24Z", New: "2011-01-01T19:23:24Z"},
+	}, differences)
+	assert.Equal(t, "/issuanceDate: 2010-01-01T19:23:24Z -> 2011-01-01T19:23:24Z", differences[4].String())
+	assert.Equal(t, "/expirationDate: added 2030-01-01T19:23:24Z", differences[3].String())
+}
@@ -0,0 +1,213 @@
+package credential
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// Enveloped credentials and presentations are secured by an enveloping proof, such as a JWT, which is carried in a
+// data: URL as the id of the envelope, so they can be included in the graph of a v2.0 presentation
+// https://www.w3.org/TR/vc-data-model-2.0/#enveloped-verifiable-credentials
+
+const (
+	EnvelopedVerifiableCredentialType   string = "EnvelopedVerifiableCredential"
+	EnvelopedVerifiablePresentationType string = "EnvelopedVerifiablePresentation"
+
+	// VCJWTMediaType is the media type of a credential secured with JOSE https://www.w3.org/TR/vc-jose-cose/#vc-ld-jwt
+	VCJWTMediaType string = "application/vc+jwt"
+	// VCSDJWTMediaType is the media type of a credential secured with SD-JWT https://www.w3.org/TR/vc-jose-cose/#vc-ld-sd-jwt
+	VCSDJWTMediaType string = "application/vc+sd-jwt"
+	// VPJWTMediaType is the media type of a presentation secured with JOSE https://www.w3.org/TR/vc-jose-cose/#vp-ld-jwt
+	VPJWTMediaType string = "application/vp+jwt"
+
+	dataURLScheme string = "data:"
+)
+
+// EnvelopedVerifiableCredential is a credential secured with an enveloping proof, whose id is a data: URL holding
+// the secured credential
+type EnvelopedVerifiableCredential struct {
+	Context any    `json:"@context,omitempty"`
+	ID      string `json:"id" validate:"required"`
+	Type    any    `json:"type" validate:"required"`
+}
+
+// NewEnvelopedVerifiableCredential envelops a secured credential of the given media type, such as a `vc+jwt`
+func NewEnvelopedVerifiableCredential(mediaType string, secured []byte) EnvelopedVerifiableCredential {
+	return EnvelopedVerifiableCredential{
+		Context: VerifiableCredentialsV2LinkedDataContext,
+		ID:      toDataURL(mediaType, secured),
+		Type:    EnvelopedVerifiableCredentialType,
+	}
+}
+
+// IsValid checks the envelope has the EnvelopedVerifiableCredential type and a data: URL id
+func (e EnvelopedVerifiableCredential) IsValid() error {
+	if err := util.IsValidStruct(e); err != nil {
+		return errors.Wrap(err, "enveloped credential is not valid")
+	}
+	if !hasType(e.Type, EnvelopedVerifiableCredentialType) {
+		return fmt.Errorf("enveloped credential must have the %s type", EnvelopedVerifiableCredentialType)
+	}
+	_, _, err := parseDataURL(e.ID)
+	return err
+}
+
+// Content returns the media type and the secured credential carried by the envelope
+func (e EnvelopedVerifiableCredential) Content() (mediaType string, secured []byte, err error) {
+	return parseDataURL(e.ID)
+}
+
+// EnvelopedVerifiablePresentation is a presentation secured with an enveloping proof, whose id is a data: URL
+// holding the secured presentation
+type EnvelopedVerifiablePresentation struct {
+	Context any    `json:"@context,omitempty"`
+	ID      string `json:"id" validate:"required"`
+	Type    any    `json:"type" validate:"required"`
+}
+
+// NewEnvelopedVerifiablePresentation envelops a secured presentation of the given media type, such as a `vp+jwt`
+func NewEnvelopedVerifiablePresentation(mediaType string, secured []byte) EnvelopedVerifiablePresentation {
+	return EnvelopedVerifiablePresentation{
+		Context: VerifiableCredentialsV2LinkedDataContext,
+		ID:      toDataURL(mediaType, secured),
+		Type:    EnvelopedVerifiablePresentationType,
+	}
+}
+
+// IsValid checks the envelope has the EnvelopedVerifiablePresentation type and a data: URL id
+func (e EnvelopedVerifiablePresentation) IsValid() error {
+	if err := util.IsValidStruct(e); err != nil {
+		return errors.Wrap(err, "enveloped presentation is not valid")
+	}
+	if !hasType(e.Type, EnvelopedVerifiablePresentationType) {
+		return fmt.Errorf("enveloped presentation must have the %s type", EnvelopedVerifiablePresentationType)
+	}
+	_, _, err := parseDataURL(e.ID)
+	return err
+}
+
+// Content returns the media type and the secured presentation carried by the envelope
+func (e EnvelopedVerifiablePresentation) Content() (mediaType string, secured []byte, err error) {
+	return parseDataURL(e.ID)
+}
+
+// ToEnvelopedVerifiableCredential returns the enveloped credential a generic credential holds, and whether it is one.
+// The credential may be an EnvelopedVerifiableCredential, a VerifiableCredential, a JSON object or JSON.
+func ToEnvelopedVerifiableCredential(genericCred any) (*EnvelopedVerifiableCredential, bool) {
+	var envelope EnvelopedVerifiableCredential
+	switch typedCred := genericCred.(type) {
+	case EnvelopedVerifiableCredential:
+		envelope = typedCred
+	case *EnvelopedVerifiableCredential:
+		if typedCred == nil {
+			return nil, false
+		}
+		envelope = *typedCred
+	default:
+		if !toEnvelope(genericCred, &envelope) {
+			return nil, false
+		}
+	}
+	if envelope.IsValid() != nil {
+		return nil, false
+	}
+	return &envelope, true
+}
+
+// ToEnvelopedVerifiablePresentation returns the enveloped presentation a generic presentation holds, and whether it
+// is one. The presentation may be an EnvelopedVerifiablePresentation, a JSON object or JSON.
+func ToEnvelopedVerifiablePresentation(genericPres any) (*EnvelopedVerifiablePresentation, bool) {
+	var envelope EnvelopedVerifiablePresentation
+	switch typedPres := genericPres.(type) {
+	case EnvelopedVerifiablePresentation:
+		envelope = typedPres
+	case *EnvelopedVerifiablePresentation:
+		if typedPres == nil {
+			return nil, false
+		}
+		envelope = *typedPres
+	default:
+		if !toEnvelope(genericPres, &envelope) {
+			return nil, false
+		}
+	}
+	if envelope.IsValid() != nil {
+		return nil, false
+	}
+	return &envelope, true
+}
+
+// toEnvelope decodes a generic value holding an envelope's JSON, returning whether it could be decoded
+func toEnvelope(generic any, envelope any) bool {
+	var envelopeBytes []byte
+	switch typed := generic.(type) {
+	case nil:
+		return false
+	case []byte:
+		envelopeBytes = typed
+	case string:
+		envelopeBytes = []byte(typed)
+	default:
+		var err error
+		if envelopeBytes, err = json.Marshal(typed); err != nil {
+			return false
+		}
+	}
+	trimmed := strings.TrimSpace(string(envelopeBytes))
+	if !strings.HasPrefix(trimmed, "{") || !strings.Contains(trimmed, dataURLScheme) {
+		return false
+	}
+	return json.Unmarshal(envelopeBytes, envelope) == nil
+}
+
+// toDataURL encodes content of the given media type as a data: URL. Compact JWTs and SD-JWTs only use URL safe
+// characters, and are included as they are.
+func toDataURL(mediaType string, content []byte) string {
+	return dataURLScheme + mediaType + "," + string(content)
+}
+
+// parseDataURL returns the media type and content of a data: URL https://www.rfc-editor.org/rfc/rfc2397
+func parseDataURL(dataURL string) (string, []byte, error) {
+	rest, ok := strings.CutPrefix(dataURL, dataURLScheme)
+	if !ok {
+		return "", nil, fmt.Errorf("envelope id<%s> must be a data: URL", dataURL)
+	}
+	header, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return "", nil, errors.New("data: URL must have content")
+	}
+	mediaType, params, _ := strings.Cut(header, ";")
+	if mediaType == "" {
+		return "", nil, errors.New("data: URL must have a media type")
+	}
+	for _, param := range strings.Split(params, ";") {
+		if param == "base64" {
+			content, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				return "", nil, errors.Wrap(err, "decoding base64 data: URL content")
+			}
+			return mediaType, content, nil
+		}
+	}
+	content, err := url.PathUnescape(data)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "decoding data: URL content")
+	}
+	return mediaType, []byte(content), nil
+}
+
+// hasType returns whether a type property, which is a string or set of strings, includes the given type
+func hasType(types any, t string) bool {
+	typeStrings, err := util.InterfaceToStrings(types)
+	if err != nil {
+		return false
+	}
+	return util.Contains(t, typeStrings)
+}
@@ -0,0 +1,237 @@
+package credential
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+const (
+	EvidenceIDProperty   string = "id"
+	EvidenceTypeProperty string = "type"
+
+	// DocumentVerificationEvidenceType is the type of evidence describing the verification of a document presented
+	// by the subject https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#evidence
+	DocumentVerificationEvidenceType string = "DocumentVerification"
+)
+
+// Evidence provides information to a verifier about the process the issuer used to issue a credential
+// https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#evidence
+// Evidence must have a type, which determines the rest of its properties, so it is kept as a JSON object; typed
+// models, such as DocumentVerification, can be converted to and from it.
+type Evidence map[string]any
+
+// GetID returns the evidence's id, if present
+func (e Evidence) GetID() string {
+	id, _ := e[EvidenceIDProperty].(string)
+	return id
+}
+
+// GetTypes returns the evidence's type or types
+func (e Evidence) GetTypes() []string {
+	switch t := e[EvidenceTypeProperty].(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, maybeType := range t {
+			if typeString, ok := maybeType.(string); ok {
+				types = append(types, typeString)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// HasType returns whether the evidence is of the given type
+func (e Evidence) HasType(evidenceType string) bool {
+	for _, t := range e.GetTypes() {
+		if t == evidenceType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid checks the evidence has one or more types and, if present, its id is a URL
+func (e Evidence) IsValid() error {
+	if len(e) == 0 {
+		return errors.New("evidence cannot be empty")
+	}
+	if maybeID, ok := e[EvidenceIDProperty]; ok {
+		id, isString := maybeID.(string)
+		if !isString || !isValidURI(id) {
+			return fmt.Errorf("evidence id<%v> is not a valid URL", maybeID)
+		}
+	}
+	types := e.GetTypes()
+	if len(types) == 0 {
+		return errors.New("evidence must have a type")
+	}
+	for _, t := range types {
+		if t == "" {
+			return errors.New("evidence type cannot be empty")
+		}
+	}
+	return nil
+}
+
+// NewEvidence converts a typed evidence model, such as DocumentVerification, to Evidence
+func NewEvidence(model any) (Evidence, error) {
+	evidenceJSON, err := util.ToJSONMap(model)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting evidence model to JSON")
+	}
+	evidence := Evidence(evidenceJSON)
+	if err = evidence.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "evidence model is not valid")
+	}
+	return evidence, nil
+}
+
+// DocumentVerification is evidence an issuer verified a document presented by the subject, such as a driver's license
+// https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#evidence
+type DocumentVerification struct {
+	ID   string   `json:"id,omitempty"`
+	Type []string `json:"type" validate:"required"`
+	// the entity which verified the document
+	Verifier string `json:"verifier" validate:"required"`
+	// the kind of document verified, e.g. DriversLicense
+	EvidenceDocument string `json:"evidenceDocument" validate:"required"`
+	// whether the subject and document were present physically or digitally
+	SubjectPresence  string `json:"subjectPresence,omitempty"`
+	DocumentPresence string `json:"documentPresence,omitempty"`
+	LicenseNumber    string `json:"licenseNumber,omitempty"`
+}
+
+// NewDocumentVerification returns document verification evidence of a document verified by the given verifier
+func NewDocumentVerification(verifier, evidenceDocument string) DocumentVerification {
+	return DocumentVerification{
+		Type:             []string{DocumentVerificationEvidenceType},
+		Verifier:         verifier,
+		EvidenceDocument: evidenceDocument,
+	}
+}
+
+// IsValid checks the document verification evidence has its required properties
+func (dv DocumentVerification) IsValid() error {
+	if err := util.IsValidStruct(dv); err != nil {
+		return errors.Wrap(err, "invalid document verification evidence")
+	}
+	if !util.Contains(DocumentVerificationEvidenceType, dv.Type) {
+		return fmt.Errorf("evidence type<%v> does not include %s", dv.Type, DocumentVerificationEvidenceType)
+	}
+	return nil
+}
+
+// ToDocumentVerification converts evidence to DocumentVerification, returning an error if the evidence is not valid
+// document verification evidence
+func (e Evidence) ToDocumentVerification() (*DocumentVerification, error) {
+	if !e.HasType(DocumentVerificationEvidenceType) {
+		return nil, fmt.Errorf("evidence<%s> is not %s evidence", e.GetID(), DocumentVerificationEvidenceType)
+	}
+	// a single type is permitted in the JSON representation, so the type is set separately
+	properties := make(map[string]any, len(e))
+	for k, v := range e {
+		if k != EvidenceTypeProperty {
+			properties[k] = v
+		}
+	}
+	propertiesBytes, err := json.Marshal(properties)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling evidence")
+	}
+	var dv DocumentVerification
+	if err = json.Unmarshal(propertiesBytes, &dv); err != nil {
+		return nil, errors.Wrap(err, "converting evidence to document verification")
+	}
+	dv.Type = e.GetTypes()
+	if err = dv.IsValid(); err != nil {
+		return nil, err
+	}
+	return &dv, nil
+}
+
+// EvidenceBuilder uses the builder pattern to construct evidence
+type EvidenceBuilder struct {
+	types []string
+	Evidence
+}
+
+// NewEvidenceBuilder returns an initialized evidence builder of the given type
+func NewEvidenceBuilder(evidenceType string) EvidenceBuilder {
+	types := []string{evidenceType}
+	return EvidenceBuilder{
+		types:    types,
+		Evidence: Evidence{EvidenceTypeProperty: types},
+	}
+}
+
+// Build attempts to turn a builder into valid evidence
+func (eb *EvidenceBuilder) Build() (Evidence, error) {
+	if eb.IsEmpty() {
+		return nil, errors.New(BuilderEmptyError)
+	}
+	if err := eb.Evidence.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "evidence not ready to be built")
+	}
+	return eb.Evidence, nil
+}
+
+func (eb *EvidenceBuilder) IsEmpty() bool {
+	if eb == nil {
+		return true
+	}
+	return reflect.DeepEqual(eb, &EvidenceBuilder{})
+}
+
+func (eb *EvidenceBuilder) SetID(id string) error {
+	if eb.IsEmpty() {
+		return errors.New(BuilderEmptyError)
+	}
+	if !isValidURI(id) {
+		return fmt.Errorf("evidence id<%s> is not a valid URL", id)
+	}
+	eb.Evidence[EvidenceIDProperty] = id
+	return nil
+}
+
+func (eb *EvidenceBuilder) AddType(evidenceType string) error {
+	if eb.IsEmpty() {
+		return errors.New(BuilderEmptyError)
+	}
+	if evidenceType == "" {
+		return errors.New("evidence type cannot be empty")
+	}
+	eb.types = append(eb.types, evidenceType)
+	eb.Evidence[EvidenceTypeProperty] = eb.types
+	return nil
+}
+
+// SetProperty sets a type specific property of the evidence, e.g. verifier
+func (eb *EvidenceBuilder) SetProperty(name string, value any) error {
+	if eb.IsEmpty() {
+		return errors.New(BuilderEmptyError)
+	}
+	if name == EvidenceIDProperty || name == EvidenceTypeProperty {
+		return fmt.Errorf("evidence property<%s> must be set using its setter", name)
+	}
+	if name == "" {
+		return errors.New("evidence property name cannot be empty")
+	}
+	eb.Evidence[name] = value
+	return nil
+}
+
+func isValidURI(input string) bool {
+	_, err := url.ParseRequestURI(input)
+	return err == nil
+}
@@ -0,0 +1,105 @@
+package credential
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvidence(t *testing.T) {
+	t.Run("from JSON", func(tt *testing.T) {
+		evidenceJSON := `{
+  "id": "https://example.edu/evidence/f2aeec97-fc0d-42bf-8ca7-0548192d4231",
+  "type": ["DocumentVerification"],
+  "verifier": "https://example.edu/issuers/14",
+  "evidenceDocument": "DriversLicense",
+  "subjectPresence": "Physical",
+  "documentPresence": "Physical",
+  "licenseNumber": "123AB4567"
+}`
+		var evidence Evidence
+		require.NoError(tt, json.Unmarshal([]byte(evidenceJSON), &evidence))
+		assert.NoError(tt, evidence.IsValid())
+		assert.Equal(tt, "https://example.edu/evidence/f2aeec97-fc0d-42bf-8ca7-0548192d4231", evidence.GetID())
+		assert.True(tt, evidence.HasType(DocumentVerificationEvidenceType))
+
+		dv, err := evidence.ToDocumentVerification()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://example.edu/issuers/14", dv.Verifier)
+		assert.Equal(tt, "DriversLicense", dv.EvidenceDocument)
+		assert.Equal(tt, "123AB4567", dv.LicenseNumber)
+	})
+
+	t.Run("single type", func(tt *testing.T) {
+		evidence := Evidence{"type": DocumentVerificationEvidenceType, "verifier": "https://example.edu/issuers/14", "evidenceDocument": "Passport"}
+		assert.NoError(tt, evidence.IsValid())
+		dv, err := evidence.ToDocumentVerification()
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{DocumentVerificationEvidenceType}, dv.Type)
+	})
+
+	t.Run("invalid evidence", func(tt *testing.T) {
+		assert.ErrorContains(tt, Evidence{}.IsValid(), "evidence cannot be empty")
+		assert.ErrorContains(tt, Evidence{"verifier": "https://example.edu/issuers/14"}.IsValid(), "evidence must have a type")
+		assert.ErrorContains(tt, Evidence{"type": []any{""}}.IsValid(), "evidence type cannot be empty")
+		assert.ErrorContains(tt, Evidence{"id": "not a url", "type": "Evidence"}.IsValid(), "evidence id<not a url> is not a valid URL")
+
+		_, err := Evidence{"type": "SupportingActivity"}.ToDocumentVerification()
+		assert.ErrorContains(tt, err, "is not DocumentVerification evidence")
+
+		_, err = Evidence{"type": DocumentVerificationEvidenceType, "evidenceDocument": "Passport"}.ToDocumentVerification()
+		assert.ErrorContains(tt, err, "invalid document verification evidence")
+	})
+
+	t.Run("from model", func(tt *testing.T) {
+		dv := NewDocumentVerification("https://example.edu/issuers/14", "DriversLicense")
+		dv.SubjectPresence = "Digital"
+		evidence, err := NewEvidence(dv)
+		assert.NoError(tt, err)
+		assert.True(tt, evidence.HasType(DocumentVerificationEvidenceType))
+		assert.Equal(tt, "Digital", evidence["subjectPresence"])
+
+		roundTripped, err := evidence.ToDocumentVerification()
+		assert.NoError(tt, err)
+		assert.Equal(tt, dv, *roundTripped)
+
+		_, err = NewEvidence(DocumentVerification{Verifier: "https://example.edu/issuers/14"})
+		assert.ErrorContains(tt, err, "evidence must have a type")
+	})
+}
+
+func TestEvidenceBuilder(t *testing.T) {
+	badBuilder := EvidenceBuilder{}
+	_, err := badBuilder.Build()
+	assert.ErrorContains(t, err, BuilderEmptyError)
+
+	builder := NewEvidenceBuilder(DocumentVerificationEvidenceType)
+
+	err = builder.SetID("not a url")
+	assert.ErrorContains(t, err, "evidence id<not a url> is not a valid URL")
+	err = builder.SetID("https://example.edu/evidence/1")
+	assert.NoError(t, err)
+
+	err = builder.AddType("")
+	assert.ErrorContains(t, err, "evidence type cannot be empty")
+	err = builder.AddType("IdentityVerification")
+	assert.NoError(t, err)
+
+	err = builder.SetProperty("type", "Evidence")
+	assert.ErrorContains(t, err, "evidence property<type> must be set using its setter")
+	err = builder.SetProperty("verifier", "https://example.edu/issuers/14")
+	assert.NoError(t, err)
+	err = builder.SetProperty("evidenceDocument", "DriversLicense")
+	assert.NoError(t, err)
+
+	evidence, err := builder.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.edu/evidence/1", evidence.GetID())
+	assert.Equal(t, []string{DocumentVerificationEvidenceType, "IdentityVerification"}, evidence.GetTypes())
+
+	dv, err := evidence.ToDocumentVerification()
+	assert.NoError(t, err)
+	assert.Equal(t, "DriversLicense", dv.EvidenceDocument)
+}
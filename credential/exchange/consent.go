@@ -0,0 +1,173 @@
+package exchange
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// ConsentRequest is the content of a wallet's consent screen for a presentation definition: what the verifier asks
+// for and why, grouped by input descriptor, with which of the holder's claims would satisfy each, so that wallets
+// present requests consistently
+type ConsentRequest struct {
+	DefinitionID string `json:"definitionId"`
+	Name         string `json:"name,omitempty"`
+	Purpose      string `json:"purpose,omitempty"`
+	// Sections are the consent for each input descriptor, in the order of the definition
+	Sections []ConsentSection `json:"sections"`
+	// Requirements are the definition's submission requirements, for wallets to present choices between sections
+	Requirements []ConsentRequirement `json:"requirements,omitempty"`
+	// Satisfiable is whether the holder's claims can fulfill every input descriptor or, for a definition with
+	// submission requirements, every submission requirement
+	Satisfiable bool `json:"satisfiable"`
+}
+
+// ConsentSection is the consent for a single input descriptor
+type ConsentSection struct {
+	InputDescriptorID string   `json:"inputDescriptorId"`
+	Name              string   `json:"name,omitempty"`
+	Purpose           string   `json:"purpose,omitempty"`
+	Group             []string `json:"group,omitempty"`
+	// Fields are the data requested by the input descriptor
+	Fields []ConsentField `json:"fields,omitempty"`
+	// Candidates are the claims which would satisfy the input descriptor, from the best match to the worst
+	Candidates []ConsentCandidate `json:"candidates,omitempty"`
+	// Reason is why no claim would satisfy the input descriptor
+	Reason string `json:"reason,omitempty"`
+}
+
+// ConsentField is the data requested by a single field of an input descriptor
+type ConsentField struct {
+	FieldID        string   `json:"fieldId,omitempty"`
+	Name           string   `json:"name,omitempty"`
+	Purpose        string   `json:"purpose,omitempty"`
+	Path           []string `json:"path"`
+	Optional       bool     `json:"optional,omitempty"`
+	IntentToRetain bool     `json:"intentToRetain,omitempty"`
+}
+
+// ConsentCandidate is a claim which would satisfy an input descriptor, and the data of it each field would disclose
+type ConsentCandidate struct {
+	ClaimID string `json:"claimId,omitempty"`
+	// Index is the index of the claim among the evaluated claims
+	Index int     `json:"index"`
+	Score float64 `json:"score"`
+	// Disclosed maps the index of each field matched by the claim to the path of the data it would disclose
+	Disclosed map[int]string `json:"disclosed,omitempty"`
+}
+
+// ConsentRequirement is a submission requirement, picking between the sections of a group or nested requirements
+type ConsentRequirement struct {
+	Name    string    `json:"name,omitempty"`
+	Purpose string    `json:"purpose,omitempty"`
+	Rule    Selection `json:"rule"`
+	Count   int       `json:"count,omitempty"`
+	Minimum int       `json:"min,omitempty"`
+	Maximum int       `json:"max,omitempty"`
+	// InputDescriptorIDs are the input descriptors of the requirement's group
+	InputDescriptorIDs []string             `json:"inputDescriptorIds,omitempty"`
+	Nested             []ConsentRequirement `json:"nested,omitempty"`
+}
+
+// EvaluateConsentRequest evaluates each input descriptor of a presentation definition against a holder's claims, as
+// EvaluatePresentationDefinition does, for the content of a consent screen. Unlike building a submission, definitions
+// with submission requirements are supported, as wallets present their choices. Input descriptor names and purposes
+// are localized for the preferred languages, given in order of preference.
+func EvaluateConsentRequest(def PresentationDefinition, claims []NormalizedClaim, preferred ...language.Tag) (*ConsentRequest, error) {
+	if def.IsEmpty() {
+		return nil, errors.New("presentation definition cannot be empty")
+	}
+	request := ConsentRequest{DefinitionID: def.ID, Name: def.Name, Purpose: def.Purpose}
+	fulfilled := make(map[string][]int)
+	groups := make(map[string][]string)
+	for _, id := range def.InputDescriptors {
+		section := ConsentSection{
+			InputDescriptorID: id.ID,
+			Name:              id.LocalizedName(preferred...),
+			Purpose:           id.LocalizedPurpose(preferred...),
+			Group:             id.Group,
+		}
+		if id.Constraints != nil {
+			for _, field := range id.Constraints.Fields {
+				section.Fields = append(section.Fields, ConsentField{
+					FieldID:        field.ID,
+					Name:           field.Name,
+					Purpose:        field.Purpose,
+					Path:           field.Path,
+					Optional:       field.Optional,
+					IntentToRetain: field.IntentToRetain,
+				})
+			}
+		}
+
+		evaluation, _ := evaluateInputDescriptor(id, claims)
+		section.Candidates = consentCandidates(evaluation)
+		if len(section.Candidates) == 0 {
+			section.Reason = evaluation.Reason
+		}
+		for _, candidate := range section.Candidates {
+			fulfilled[id.ID] = append(fulfilled[id.ID], candidate.Index)
+		}
+		for _, group := range id.Group {
+			groups[group] = append(groups[group], id.ID)
+		}
+		request.Sections = append(request.Sections, section)
+	}
+
+	request.Satisfiable = true
+	if len(def.SubmissionRequirements) == 0 {
+		request.Satisfiable = len(fulfilled) == len(def.InputDescriptors)
+	}
+	for _, requirement := range def.SubmissionRequirements {
+		if ok, _ := satisfiesRequirement(requirement, groups, fulfilled); !ok {
+			request.Satisfiable = false
+		}
+		request.Requirements = append(request.Requirements, consentRequirement(requirement, groups))
+	}
+	return &request, nil
+}
+
+// consentCandidates returns the claims fulfilling an input descriptor, from the highest score to the lowest. An
+// evaluation's claims are those evaluated, in order, unless none were.
+func consentCandidates(evaluation InputDescriptorEvaluation) []ConsentCandidate {
+	var candidates []ConsentCandidate
+	for i, claim := range evaluation.Claims {
+		if !claim.Fulfilled {
+			continue
+		}
+		candidate := ConsentCandidate{ClaimID: claim.ClaimID, Index: i, Score: claim.Score}
+		for j, field := range claim.Fields {
+			if field.Matched {
+				if candidate.Disclosed == nil {
+					candidate.Disclosed = make(map[int]string)
+				}
+				candidate.Disclosed[j] = field.Path
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+// consentRequirement returns the consent of a submission requirement and its nested requirements
+func consentRequirement(requirement SubmissionRequirement, groups map[string][]string) ConsentRequirement {
+	consent := ConsentRequirement{
+		Name:    requirement.Name,
+		Purpose: requirement.Purpose,
+		Rule:    requirement.Rule,
+		Count:   requirement.Count,
+		Minimum: requirement.Minimum,
+		Maximum: requirement.Maximum,
+	}
+	if requirement.From != "" {
+		consent.InputDescriptorIDs = groups[requirement.From]
+	}
+	for _, nested := range requirement.FromNested {
+		consent.Nested = append(consent.Nested, consentRequirement(nested, groups))
+	}
+	return consent
+}
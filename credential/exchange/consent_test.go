@@ -0,0 +1,123 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+)
+
+func TestEvaluateConsentRequest(t *testing.T) {
+	def := PresentationDefinition{
+		ID:      "test-id",
+		Name:    "Employment check",
+		Purpose: "We need to verify your employment",
+		InputDescriptors: []InputDescriptor{
+			{
+				ID:            "employer",
+				Name:          "Employer",
+				NameLanguages: LanguageMap{"es": "Empleador"},
+				Purpose:       "Your current employer",
+				Group:         []string{"A"},
+				Constraints: &Constraints{
+					Fields: []Field{
+						{ID: "company-field", Name: "Company", Path: []string{"$.credentialSubject.company"}, IntentToRetain: true},
+						{
+							ID:       "issuer-field",
+							Path:     []string{"$.issuer"},
+							Filter:   &Filter{Type: "string", Const: "trusted-issuer"},
+							Optional: true,
+						},
+					},
+				},
+			},
+			{
+				ID:    "license",
+				Name:  "Driver License",
+				Group: []string{"A"},
+				Constraints: &Constraints{
+					Fields: []Field{{ID: "license-field", Path: []string{"$.credentialSubject.licenseNumber"}}},
+				},
+			},
+		},
+	}
+
+	untrustedVC := getTestVerifiableCredential("untrusted-issuer", "test-subject")
+	untrustedVC.ID = "untrusted-credential"
+	trustedVC := getTestVerifiableCredential("trusted-issuer", "test-subject")
+	var claims []PresentationClaim
+	for _, vc := range []credential.VerifiableCredential{untrustedVC, trustedVC} {
+		vc := vc
+		claims = append(claims, PresentationClaim{
+			Credential:                    &vc,
+			LDPFormat:                     LDPVC.Ptr(),
+			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
+		})
+	}
+	normalized, err := NormalizePresentationClaims(claims)
+	require.NoError(t, err)
+
+	t.Run("sections are grouped by input descriptor with ranked candidates", func(tt *testing.T) {
+		request, err := EvaluateConsentRequest(def, normalized, language.Spanish)
+		require.NoError(tt, err)
+		assert.Equal(tt, "Employment check", request.Name)
+		assert.Equal(tt, "We need to verify your employment", request.Purpose)
+		assert.False(tt, request.Satisfiable)
+		require.Len(tt, request.Sections, 2)
+
+		employer := request.Sections[0]
+		assert.Equal(tt, "Empleador", employer.Name)
+		assert.Equal(tt, "Your current employer", employer.Purpose)
+		require.Len(tt, employer.Fields, 2)
+		assert.Equal(tt, "Company", employer.Fields[0].Name)
+		assert.True(tt, employer.Fields[0].IntentToRetain)
+		assert.True(tt, employer.Fields[1].Optional)
+		assert.Empty(tt, employer.Reason)
+
+		// the claim matching the optional field is the best match
+		require.Len(tt, employer.Candidates, 2)
+		assert.Equal(tt, ConsentCandidate{
+			ClaimID:   "test-verifiable-credential",
+			Index:     1,
+			Score:     1,
+			Disclosed: map[int]string{0: "$.credentialSubject.company", 1: "$.issuer"},
+		}, employer.Candidates[0])
+		assert.Equal(tt, ConsentCandidate{
+			ClaimID:   "untrusted-credential",
+			Index:     0,
+			Score:     0.5,
+			Disclosed: map[int]string{0: "$.credentialSubject.company"},
+		}, employer.Candidates[1])
+
+		license := request.Sections[1]
+		assert.Empty(tt, license.Candidates)
+		assert.Equal(tt, "no claims could fulfill the input descriptor: license", license.Reason)
+	})
+
+	t.Run("submission requirements are presented as choices", func(tt *testing.T) {
+		withRequirements := def
+		withRequirements.SubmissionRequirements = []SubmissionRequirement{{
+			Name:       "Proof of identity",
+			Rule:       Pick,
+			Count:      1,
+			FromOption: FromOption{From: "A"},
+		}}
+
+		request, err := EvaluateConsentRequest(withRequirements, normalized)
+		require.NoError(tt, err)
+		assert.True(tt, request.Satisfiable)
+		assert.Equal(tt, "Employer", request.Sections[0].Name)
+		require.Len(tt, request.Requirements, 1)
+		assert.Equal(tt, "Proof of identity", request.Requirements[0].Name)
+		assert.Equal(tt, []string{"employer", "license"}, request.Requirements[0].InputDescriptorIDs)
+	})
+
+	t.Run("empty definition", func(tt *testing.T) {
+		_, err := EvaluateConsentRequest(PresentationDefinition{}, normalized)
+		assert.ErrorContains(tt, err, "presentation definition cannot be empty")
+	})
+}
@@ -0,0 +1,248 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// Predicate is a predicate over the claim at a path of a credential, which holds when the claim is valid against
+// the filter of the field it fulfills
+// https://identity.foundation/presentation-exchange/#predicate-feature
+type Predicate struct {
+	FieldID string
+	Path    string
+	Filter  Filter
+}
+
+// PredicateSuite is a DerivableSuite which can prove predicates over claims without revealing them, such as a suite
+// with zero-knowledge range proofs. Its derived provables carry the result of each predicate, true, in place of the
+// claim at the predicate's path. Like cryptosuite.DerivableSuite, it is currently experimental.
+type PredicateSuite interface {
+	cryptosuite.DerivableSuite
+
+	// DerivePredicateProof derives a selectively disclosed provable as DeriveProof does, which additionally proves
+	// each predicate. The given provable is not modified.
+	DerivePredicateProof(p cryptosuite.WithEmbeddedProof, revealDocument map[string]any, predicates []Predicate, opts ...cryptosuite.Option) (*cryptosuite.GenericProvable, error)
+}
+
+// BuildDerivedPresentationSubmissionVP fulfills a presentation definition with credentials signed by a
+// DerivableSuite, such as a BBS+ suite, constructing a presentation submission as a Verifiable Presentation.
+// For input descriptors which require or prefer limited disclosure, a credential is derived revealing only the fields
+// the input descriptor requests of the credential's subject, rather than presenting the whole credential. Since
+// derived proofs have a different type than the base proofs they are derived from, credentials are only filtered by
+// the ldp_vc format of an input descriptor, and not its proof types.
+// If the definition has a JSON-LD frame, every credential is derived using the frame as its reveal document, and must
+// still fulfill its input descriptor's fields once framed.
+// Fields with predicates are proven with the suite if it is a PredicateSuite. Otherwise, preferred predicates are
+// fulfilled by revealing their claims, and required predicates cannot be fulfilled.
+// This is currently experimental: it has only been exercised with test suites, as this SDK has no DerivableSuite.
+// https://identity.foundation/presentation-exchange/#limited-disclosure-submissions
+func BuildDerivedPresentationSubmissionVP(submitter string, def PresentationDefinition, suite cryptosuite.DerivableSuite, creds []credential.VerifiableCredential, opts ...cryptosuite.Option) (*credential.VerifiablePresentation, error) {
+	if err := canProcessDefinition(def); err != nil {
+		return nil, errors.Wrap(err, "feature not supported in processing given presentation definition")
+	}
+	if suite == nil {
+		return nil, errors.New("suite cannot be empty")
+	}
+	if len(creds) == 0 {
+		return nil, errors.New("no credentials provided; cannot continue processing")
+	}
+	frame, err := getFrame(def)
+	if err != nil {
+		return nil, err
+	}
+	builder := credential.NewVerifiablePresentationBuilder()
+	if err := builder.AddContext(PresentationSubmissionContext); err != nil {
+		return nil, err
+	}
+	if err := builder.AddType(PresentationSubmissionType); err != nil {
+		return nil, err
+	}
+	if err := builder.SetHolder(submitter); err != nil {
+		return nil, err
+	}
+
+	submission := PresentationSubmission{
+		ID:           uuid.NewString(),
+		DefinitionID: def.ID,
+	}
+	for i, id := range def.InputDescriptors {
+		cred, revealedPaths, predicates, err := findDerivableCredential(id, creds)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error processing input descriptor: %s", id.ID)
+		}
+		predicateSuite, canProvePredicates := suite.(PredicateSuite)
+		if len(predicates) > 0 && !canProvePredicates {
+			if hasRequiredPredicate(id.Constraints.Fields) {
+				return nil, fmt.Errorf("suite<%s> cannot prove the predicates required by input descriptor: %s", suite.ID(), id.ID)
+			}
+			for _, predicate := range predicates {
+				revealedPaths = append(revealedPaths, predicate.Path)
+			}
+			predicates = nil
+		}
+		revealDocument := frame
+		if revealDocument == nil {
+			revealDocument = revealDocumentForPaths(*cred, revealedPaths)
+		}
+		disclosure := id.Constraints.LimitDisclosure
+		switch {
+		case len(predicates) > 0:
+			if cred, err = derivePredicateCredential(predicateSuite, *cred, revealDocument, predicates, opts...); err != nil {
+				return nil, errors.Wrapf(err, "deriving credential with predicates for input descriptor: %s", id.ID)
+			}
+		case frame != nil || (disclosure != nil && (*disclosure == Required || *disclosure == Preferred)):
+			if cred, err = integrity.DeriveCredential(suite, *cred, revealDocument, opts...); err != nil {
+				return nil, errors.Wrapf(err, "deriving credential for input descriptor: %s", id.ID)
+			}
+		}
+		if frame != nil {
+			if err = fulfillsFields(id.Constraints.Fields, *cred); err != nil {
+				return nil, errors.Wrapf(err, "framed credential for input descriptor<%s>", id.ID)
+			}
+		}
+		if err = builder.AddVerifiableCredentials(*cred); err != nil {
+			return nil, errors.Wrap(err, "adding claim to verifiable presentation")
+		}
+		submission.DescriptorMap = append(submission.DescriptorMap, SubmissionDescriptor{
+			ID:     id.ID,
+			Format: LDPVC.String(),
+			Path:   fmt.Sprintf("$.verifiableCredential[%d]", i),
+		})
+	}
+
+	if err := builder.SetPresentationSubmission(submission); err != nil {
+		return nil, err
+	}
+	return builder.Build()
+}
+
+// findDerivableCredential returns the first credential with a proof which fulfills every field of an input
+// descriptor, along with the paths of the fields it fulfilled, and the predicates of fields with predicates
+func findDerivableCredential(id InputDescriptor, creds []credential.VerifiableCredential) (*credential.VerifiableCredential, []string, []Predicate, error) {
+	if id.Constraints == nil || len(id.Constraints.Fields) == 0 {
+		return nil, nil, nil, fmt.Errorf("unable to process input descriptor without fields: %s", id.ID)
+	}
+	if id.Format != nil && !util.Contains(LDPVC.String(), id.Format.FormatValues()) && !util.Contains(LDP.String(), id.Format.FormatValues()) {
+		return nil, nil, nil, fmt.Errorf("input descriptor<%s> does not accept the %s format", id.ID, LDPVC)
+	}
+	for i, cred := range creds {
+		if cred.GetProof() == nil {
+			continue
+		}
+		credJSON, err := util.ToJSONMap(cred)
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "turning credential into json")
+		}
+		for _, subjectJSON := range SubjectViews(credJSON) {
+			var paths []string
+			var predicates []Predicate
+			fulfilled := true
+			for _, field := range id.Constraints.Fields {
+				limited, ok := processInputDescriptorField(field, subjectJSON)
+				if !ok {
+					fulfilled = false
+					break
+				}
+				switch {
+				case limited == nil:
+				case field.Predicate != nil:
+					predicates = append(predicates, Predicate{FieldID: field.ID, Path: limited.Path, Filter: *field.Filter})
+				default:
+					paths = append(paths, limited.Path)
+				}
+			}
+			if fulfilled {
+				return &creds[i], paths, predicates, nil
+			}
+		}
+	}
+	return nil, nil, nil, fmt.Errorf("no claims could fulfill the input descriptor: %s", id.ID)
+}
+
+// fulfillsFields returns an error unless every field, other than those with predicates, matches the same subject of
+// the credential
+func fulfillsFields(fields []Field, cred credential.VerifiableCredential) error {
+	credJSON, err := util.ToJSONMap(cred)
+	if err != nil {
+		return errors.Wrap(err, "turning credential into json")
+	}
+	for _, subjectJSON := range SubjectViews(credJSON) {
+		fulfilled := true
+		for _, field := range fields {
+			if field.Predicate != nil {
+				continue
+			}
+			if _, ok := processInputDescriptorField(field, subjectJSON); !ok {
+				fulfilled = false
+				break
+			}
+		}
+		if fulfilled {
+			return nil
+		}
+	}
+	return errors.New("credential does not reveal every field")
+}
+
+// derivePredicateCredential derives a credential with a predicate proof, as integrity.DeriveCredential derives one
+// with a selective disclosure proof
+func derivePredicateCredential(suite PredicateSuite, cred credential.VerifiableCredential, revealDocument map[string]any, predicates []Predicate, opts ...cryptosuite.Option) (*credential.VerifiableCredential, error) {
+	derived, err := suite.DerivePredicateProof(&cred, revealDocument, predicates, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "deriving predicate proof for credential<%s>", cred.ID)
+	}
+	var derivedCred credential.VerifiableCredential
+	if err = derived.ToDocument(&derivedCred); err != nil {
+		return nil, errors.Wrap(err, "reconstructing derived credential")
+	}
+	if err = derivedCred.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "derived credential is not valid")
+	}
+	if derivedCred.GetProof() == nil {
+		return nil, errors.New("derived credential does not have a proof")
+	}
+	return &derivedCred, nil
+}
+
+// revealDocumentForPaths builds a JSON-LD frame revealing only the given JSON paths of a credential's subject, and
+// its top-level properties, which include those the credential is required to have
+func revealDocumentForPaths(cred credential.VerifiableCredential, paths []string) map[string]any {
+	revealDocument := map[string]any{
+		"@context": cred.Context,
+		"type":     cred.Type,
+	}
+	for _, path := range paths {
+		var parts []string
+		for _, part := range strings.Split(normalizeJSONPath(path), ".") {
+			if part = normalizeJSONPartPath(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		// top-level properties are always revealed, so only nested properties are framed
+		if len(parts) < 2 {
+			continue
+		}
+		curr := revealDocument
+		for i, part := range parts {
+			next, ok := curr[part].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				curr[part] = next
+			}
+			if i < len(parts)-1 {
+				next["@explicit"] = true
+			}
+			curr = next
+		}
+	}
+	return revealDocument
+}
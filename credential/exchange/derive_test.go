@@ -0,0 +1,210 @@
+package exchange
+
+import (
+	gocrypto "crypto"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+)
+
+// testDerivableSuite derives proofs recording which statements of the base document were revealed
+type testDerivableSuite struct{}
+
+func (testDerivableSuite) ID() string                                    { return "test-derivable-suite" }
+func (testDerivableSuite) Type() cryptosuite.LDKeyType                   { return cryptosuite.MultikeyType }
+func (testDerivableSuite) CanonicalizationAlgorithm() string             { return "" }
+func (testDerivableSuite) MessageDigestAlgorithm() gocrypto.Hash         { return gocrypto.SHA256 }
+func (testDerivableSuite) SignatureAlgorithm() cryptosuite.SignatureType { return "TestSignature2020" }
+func (testDerivableSuite) RequiredContexts() []string                    { return nil }
+func (testDerivableSuite) Sign(cryptosuite.Signer, cryptosuite.WithEmbeddedProof, ...cryptosuite.Option) error {
+	return nil
+}
+func (testDerivableSuite) Verify(cryptosuite.Verifier, cryptosuite.WithEmbeddedProof, ...cryptosuite.Option) error {
+	return nil
+}
+
+func (testDerivableSuite) DeriveProof(p cryptosuite.WithEmbeddedProof, revealDocument map[string]any, _ ...cryptosuite.Option) (*cryptosuite.GenericProvable, error) {
+	revealed, err := cryptosuite.RevealStatements(p, revealDocument, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	derived := cryptosuite.GenericProvable(revealed.Document)
+	derived.SetProof(&crypto.Proof{Type: "TestSignatureProof2020", ProofValue: fmt.Sprint(revealed.RevealIndexes)})
+	return &derived, nil
+}
+
+// testPredicateSuite derives proofs as testDerivableSuite does, replacing the claims of predicates with true
+type testPredicateSuite struct {
+	testDerivableSuite
+}
+
+func (s testPredicateSuite) DerivePredicateProof(p cryptosuite.WithEmbeddedProof, revealDocument map[string]any, predicates []Predicate, opts ...cryptosuite.Option) (*cryptosuite.GenericProvable, error) {
+	derived, err := s.DeriveProof(p, revealDocument, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, predicate := range predicates {
+		curr := map[string]any(*derived)
+		parts := strings.Split(strings.TrimPrefix(predicate.Path, "$."), ".")
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := curr[part].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				curr[part] = next
+			}
+			curr = next
+		}
+		curr[parts[len(parts)-1]] = true
+	}
+	return derived, nil
+}
+
+func TestBuildDerivedPresentationSubmissionVP(t *testing.T) {
+	degreeCred := credential.VerifiableCredential{
+		Context:      []any{credential.VerifiableCredentialsLinkedDataContext, "https://www.w3.org/2018/credentials/examples/v1"},
+		ID:           "http://example.edu/credentials/1872",
+		Type:         []any{credential.VerifiableCredentialType, "UniversityDegreeCredential"},
+		Issuer:       "did:example:issuer",
+		IssuanceDate: "2010-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{
+			"id":   "did:example:subject",
+			"name": "Jayden Doe",
+			"degree": map[string]any{
+				"type": "BachelorDegree",
+				"name": "Bachelor of Science and Arts",
+			},
+		},
+		Proof: &crypto.Proof{Type: "TestSignature2020", ProofValue: "z123"},
+	}
+	unsignedCred := degreeCred
+	unsignedCred.Proof = nil
+	getDefinition := func(disclosure *Preference) PresentationDefinition {
+		return PresentationDefinition{
+			ID: "test-id",
+			InputDescriptors: []InputDescriptor{
+				{
+					ID: "degree",
+					Constraints: &Constraints{
+						LimitDisclosure: disclosure,
+						Fields: []Field{
+							{Path: []string{"$.credentialSubject.degree.type"}},
+							{Path: []string{"$.credentialSubject.nickname"}, Optional: true},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("limited disclosure", func(tt *testing.T) {
+		vp, err := BuildDerivedPresentationSubmissionVP("did:example:subject", getDefinition(Required.Ptr()), testDerivableSuite{}, []credential.VerifiableCredential{unsignedCred, degreeCred})
+		assert.NoError(tt, err)
+		require.NotNil(tt, vp)
+		require.Len(tt, vp.VerifiableCredential, 1)
+
+		derived, ok := vp.VerifiableCredential[0].(credential.VerifiableCredential)
+		require.True(tt, ok)
+		assert.Equal(tt, degreeCred.ID, derived.ID)
+		assert.Equal(tt, "TestSignatureProof2020", derived.GetProof().Type)
+		assert.NotContains(tt, derived.CredentialSubject, "name")
+		degree, ok := derived.CredentialSubject["degree"].(map[string]any)
+		require.True(tt, ok)
+		assert.Equal(tt, "BachelorDegree", degree["type"])
+		assert.NotContains(tt, degree, "name")
+
+		submission, ok := vp.PresentationSubmission.(PresentationSubmission)
+		require.True(tt, ok)
+		assert.Equal(tt, []SubmissionDescriptor{{ID: "degree", Format: LDPVC.String(), Path: "$.verifiableCredential[0]"}}, submission.DescriptorMap)
+	})
+
+	t.Run("no limited disclosure", func(tt *testing.T) {
+		vp, err := BuildDerivedPresentationSubmissionVP("did:example:subject", getDefinition(nil), testDerivableSuite{}, []credential.VerifiableCredential{degreeCred})
+		assert.NoError(tt, err)
+		require.NotNil(tt, vp)
+		assert.Equal(tt, degreeCred, vp.VerifiableCredential[0])
+	})
+
+	t.Run("unfulfilled input descriptor", func(tt *testing.T) {
+		_, err := BuildDerivedPresentationSubmissionVP("did:example:subject", getDefinition(Required.Ptr()), testDerivableSuite{}, []credential.VerifiableCredential{unsignedCred})
+		assert.ErrorContains(tt, err, "no claims could fulfill the input descriptor: degree")
+
+		def := getDefinition(Required.Ptr())
+		def.InputDescriptors[0].Format = &ClaimFormat{JWTVC: &JWTType{Alg: []crypto.SignatureAlgorithm{crypto.EdDSA}}}
+		_, err = BuildDerivedPresentationSubmissionVP("did:example:subject", def, testDerivableSuite{}, []credential.VerifiableCredential{degreeCred})
+		assert.ErrorContains(tt, err, "does not accept the ldp_vc format")
+	})
+
+	t.Run("frame", func(tt *testing.T) {
+		def := getDefinition(nil)
+		def.Frame = map[string]any{
+			"@context":          degreeCred.Context,
+			"type":              degreeCred.Type,
+			"credentialSubject": map[string]any{"@explicit": true, "degree": map[string]any{}},
+		}
+		vp, err := BuildDerivedPresentationSubmissionVP("did:example:subject", def, testDerivableSuite{}, []credential.VerifiableCredential{degreeCred})
+		assert.NoError(tt, err)
+		require.NotNil(tt, vp)
+		derived, ok := vp.VerifiableCredential[0].(credential.VerifiableCredential)
+		require.True(tt, ok)
+		assert.Equal(tt, "TestSignatureProof2020", derived.GetProof().Type)
+		assert.NotContains(tt, derived.CredentialSubject, "name")
+		degree, ok := derived.CredentialSubject["degree"].(map[string]any)
+		require.True(tt, ok)
+		assert.Equal(tt, "Bachelor of Science and Arts", degree["name"])
+
+		// a frame hiding the fields of an input descriptor cannot fulfill it
+		def.Frame = map[string]any{
+			"@context":          degreeCred.Context,
+			"type":              degreeCred.Type,
+			"credentialSubject": map[string]any{"@explicit": true, "name": map[string]any{}},
+		}
+		_, err = BuildDerivedPresentationSubmissionVP("did:example:subject", def, testDerivableSuite{}, []credential.VerifiableCredential{degreeCred})
+		assert.ErrorContains(tt, err, "credential does not reveal every field")
+	})
+
+	t.Run("predicates", func(tt *testing.T) {
+		def := getDefinition(Required.Ptr())
+		def.InputDescriptors[0].Constraints.Fields[0] = Field{
+			ID:        "bachelor",
+			Path:      []string{"$.credentialSubject.degree.type"},
+			Predicate: Required.Ptr(),
+			Filter:    &Filter{Type: "string", Pattern: "^Bachelor"},
+		}
+		vp, err := BuildDerivedPresentationSubmissionVP("did:example:subject", def, testPredicateSuite{}, []credential.VerifiableCredential{degreeCred})
+		assert.NoError(tt, err)
+		require.NotNil(tt, vp)
+		derived, ok := vp.VerifiableCredential[0].(credential.VerifiableCredential)
+		require.True(tt, ok)
+		degree, ok := derived.CredentialSubject["degree"].(map[string]any)
+		require.True(tt, ok)
+		assert.Equal(tt, true, degree["type"])
+		assert.NotContains(tt, degree, "name")
+
+		// a suite which cannot prove predicates cannot fulfill required predicates
+		_, err = BuildDerivedPresentationSubmissionVP("did:example:subject", def, testDerivableSuite{}, []credential.VerifiableCredential{degreeCred})
+		assert.ErrorContains(tt, err, "cannot prove the predicates required by input descriptor: degree")
+
+		// but fulfills preferred predicates by revealing their claims
+		def.InputDescriptors[0].Constraints.Fields[0].Predicate = Preferred.Ptr()
+		vp, err = BuildDerivedPresentationSubmissionVP("did:example:subject", def, testDerivableSuite{}, []credential.VerifiableCredential{degreeCred})
+		assert.NoError(tt, err)
+		require.NotNil(tt, vp)
+		derived, ok = vp.VerifiableCredential[0].(credential.VerifiableCredential)
+		require.True(tt, ok)
+		degree, ok = derived.CredentialSubject["degree"].(map[string]any)
+		require.True(tt, ok)
+		assert.Equal(tt, "BachelorDegree", degree["type"])
+
+		// credentials whose claims do not satisfy the predicate cannot fulfill it
+		def.InputDescriptors[0].Constraints.Fields[0].Filter = &Filter{Type: "string", Pattern: "^Master"}
+		_, err = BuildDerivedPresentationSubmissionVP("did:example:subject", def, testPredicateSuite{}, []credential.VerifiableCredential{degreeCred})
+		assert.ErrorContains(tt, err, "no claims could fulfill the input descriptor: degree")
+	})
+}
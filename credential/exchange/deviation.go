@@ -0,0 +1,219 @@
+package exchange
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/schema"
+)
+
+// ValidationMode is how strictly a presentation definition is validated against the Presentation Exchange JSON schemas
+type ValidationMode string
+
+const (
+	// StrictValidation requires a presentation definition to be valid against the JSON schema of its version
+	StrictValidation ValidationMode = "strict"
+	// LaxValidation tolerates known deviations from the JSON schemas common among vendors, reporting each one found
+	LaxValidation ValidationMode = "lax"
+)
+
+// Deviation is a known deviation from the Presentation Exchange JSON schemas found by lax validation
+type Deviation struct {
+	// Path is the JSON path of the deviating value
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// DefinitionValidationReport is the result of validating a presentation definition against the JSON schema of its
+// version, with the deviations tolerated by lax validation
+type DefinitionValidationReport struct {
+	Version    Version     `json:"version"`
+	Deviations []Deviation `json:"deviations,omitempty"`
+}
+
+// registeredClaimFormats are the claim format designations of the claim format registry schema
+var registeredClaimFormats = []string{
+	JWT.String(), JWTVC.String(), JWTVP.String(), LDP.String(), LDPVC.String(), LDPVP.String(), SDJWTVC.String(),
+}
+
+// definitionProperties are the properties each version defines for presentation definitions, input descriptors,
+// and fields
+var definitionProperties = map[Version]struct{ definition, inputDescriptor, field []string }{
+	V1: {
+		definition:      []string{"id", "name", "purpose", "format", "submission_requirements", "input_descriptors"},
+		inputDescriptor: []string{"id", "name", "purpose", "group", "schema", "constraints"},
+		field:           []string{"id", "path", "purpose", "filter", "predicate"},
+	},
+	V2: {
+		definition:      []string{"id", "name", "purpose", "format", "frame", "submission_requirements", "input_descriptors"},
+		inputDescriptor: []string{"id", "name", "purpose", "format", "group", "constraints"},
+		field:           []string{"id", "optional", "path", "purpose", "name", "intent_to_retain", "filter", "predicate"},
+	},
+}
+
+// ValidatePresentationDefinition validates a presentation definition, or presentation definition envelope, of either
+// version against the JSON schema of its version. Strict validation fails on any deviation from the schema. Lax
+// validation tolerates known deviations, validating the definition as if each were corrected, and reports them:
+//   - claim format designations missing from the claim format registry, such as jwt_vc_json, which are ignored
+//   - properties the version does not define on the definition, its input descriptors, or their fields, which are
+//     ignored
+//   - limit_disclosure given as a boolean rather than a preference
+//   - version 2 input descriptors without constraints
+//   - version 1 input descriptors with a single schema object rather than an array of them
+func ValidatePresentationDefinition(definition []byte, mode ValidationMode) (*DefinitionValidationReport, error) {
+	if mode != StrictValidation && mode != LaxValidation {
+		return nil, fmt.Errorf("unsupported validation mode<%s>", mode)
+	}
+	version, err := DetectDefinitionVersion(definition)
+	if err != nil {
+		return nil, err
+	}
+	var definitionJSON map[string]any
+	if err = json.Unmarshal(definition, &definitionJSON); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling presentation definition")
+	}
+	path := "$"
+	if inner, ok := definitionJSON["presentation_definition"].(map[string]any); ok {
+		definitionJSON = inner
+		path = "$.presentation_definition"
+	}
+
+	report := DefinitionValidationReport{Version: version}
+	if mode == LaxValidation {
+		report.Deviations = correctDeviations(definitionJSON, version, path)
+	}
+
+	schemaFile := schema.PresentationDefinitionSchema
+	if version == V1 {
+		schemaFile = schema.PresentationDefinitionV1Schema
+	}
+	s, err := schema.LoadSchema(schemaFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting presentation definition schema")
+	}
+	definitionBytes, err := json.Marshal(definitionJSON)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling presentation definition")
+	}
+	if err = schema.IsValidAgainstJSONSchema(string(definitionBytes), s); err != nil {
+		return nil, errors.Wrapf(err, "presentation definition not valid against version %s schema", version)
+	}
+	return &report, nil
+}
+
+// correctDeviations corrects the known deviations of a presentation definition in place, returning each one found
+func correctDeviations(definitionJSON map[string]any, version Version, path string) []Deviation {
+	properties := definitionProperties[version]
+	deviations := removeUnknownProperties(definitionJSON, properties.definition, path)
+	deviations = append(deviations, removeUnregisteredFormats(definitionJSON, path)...)
+
+	descriptors, _ := definitionJSON["input_descriptors"].([]any)
+	for i, d := range descriptors {
+		descriptor, ok := d.(map[string]any)
+		if !ok {
+			continue
+		}
+		descriptorPath := fmt.Sprintf("%s.input_descriptors[%d]", path, i)
+		deviations = append(deviations, removeUnknownProperties(descriptor, properties.inputDescriptor, descriptorPath)...)
+		if version == V2 {
+			deviations = append(deviations, removeUnregisteredFormats(descriptor, descriptorPath)...)
+		}
+		if schemaObject, ok := descriptor["schema"].(map[string]any); ok && version == V1 {
+			descriptor["schema"] = []any{schemaObject}
+			deviations = append(deviations, Deviation{
+				Path:        descriptorPath + ".schema",
+				Description: "schema is a single object rather than an array",
+			})
+		}
+
+		constraints, ok := descriptor["constraints"].(map[string]any)
+		if !ok {
+			if _, present := descriptor["constraints"]; !present && version == V2 {
+				descriptor["constraints"] = map[string]any{}
+				deviations = append(deviations, Deviation{
+					Path:        descriptorPath,
+					Description: "input descriptor has no constraints",
+				})
+			}
+			continue
+		}
+		if limitDisclosure, ok := constraints["limit_disclosure"].(bool); ok {
+			if limitDisclosure {
+				constraints["limit_disclosure"] = string(Required)
+			} else {
+				delete(constraints, "limit_disclosure")
+			}
+			deviations = append(deviations, Deviation{
+				Path:        descriptorPath + ".constraints.limit_disclosure",
+				Description: "limit_disclosure is a boolean rather than a preference",
+			})
+		}
+		fields, _ := constraints["fields"].([]any)
+		for j, f := range fields {
+			if field, ok := f.(map[string]any); ok {
+				fieldPath := fmt.Sprintf("%s.constraints.fields[%d]", descriptorPath, j)
+				deviations = append(deviations, removeUnknownProperties(field, properties.field, fieldPath)...)
+			}
+		}
+	}
+	return deviations
+}
+
+// removeUnknownProperties removes the properties of an object other than the known properties
+func removeUnknownProperties(object map[string]any, known []string, path string) []Deviation {
+	var unknown []string
+	for property := range object {
+		if !containsString(known, property) {
+			unknown = append(unknown, property)
+		}
+	}
+	sort.Strings(unknown)
+	deviations := make([]Deviation, 0, len(unknown))
+	for _, property := range unknown {
+		delete(object, property)
+		deviations = append(deviations, Deviation{
+			Path:        fmt.Sprintf("%s.%s", path, property),
+			Description: fmt.Sprintf("unknown property<%s> is ignored", property),
+		})
+	}
+	return deviations
+}
+
+// removeUnregisteredFormats removes the claim format designations of an object's format missing from the registry
+func removeUnregisteredFormats(object map[string]any, path string) []Deviation {
+	format, ok := object["format"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	var unregistered []string
+	for designation := range format {
+		if !containsString(registeredClaimFormats, designation) {
+			unregistered = append(unregistered, designation)
+		}
+	}
+	sort.Strings(unregistered)
+	deviations := make([]Deviation, 0, len(unregistered))
+	for _, designation := range unregistered {
+		delete(format, designation)
+		deviations = append(deviations, Deviation{
+			Path:        fmt.Sprintf("%s.format.%s", path, designation),
+			Description: fmt.Sprintf("unregistered claim format designation<%s> is ignored", designation),
+		})
+	}
+	if len(format) == 0 {
+		delete(object, "format")
+	}
+	return deviations
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePresentationDefinition(t *testing.T) {
+	t.Run("valid definitions pass strict validation", func(tt *testing.T) {
+		definition := []byte(`{
+			"id": "test-id",
+			"format": {"ldp_vc": {"proof_type": ["JsonWebSignature2020"]}},
+			"input_descriptors": [{
+				"id": "issuer",
+				"constraints": {"limit_disclosure": "preferred", "fields": [{"path": ["$.issuer"]}]}
+			}]
+		}`)
+		report, err := ValidatePresentationDefinition(definition, StrictValidation)
+		require.NoError(tt, err)
+		assert.Equal(tt, V2, report.Version)
+		assert.Empty(tt, report.Deviations)
+
+		v1Definition := []byte(`{"presentation_definition": {
+			"id": "test-id",
+			"input_descriptors": [{
+				"id": "employment",
+				"schema": [{"uri": "https://example.com/schemas/employment.json"}],
+				"constraints": {"fields": [{"path": ["$.credentialSubject.company"]}]}
+			}]
+		}}`)
+		report, err = ValidatePresentationDefinition(v1Definition, StrictValidation)
+		require.NoError(tt, err)
+		assert.Equal(tt, V1, report.Version)
+		assert.Empty(tt, report.Deviations)
+	})
+
+	t.Run("vendor deviations are reported by lax validation", func(tt *testing.T) {
+		definition := []byte(`{
+			"id": "test-id",
+			"vendor_extension": true,
+			"format": {
+				"jwt_vc_json": {"alg": ["ES256"]},
+				"ldp_vc": {"proof_type": ["JsonWebSignature2020"]}
+			},
+			"input_descriptors": [
+				{
+					"id": "issuer",
+					"constraints": {
+						"limit_disclosure": true,
+						"fields": [{"path": ["$.issuer"], "filter_hint": "trusted issuers only"}]
+					}
+				},
+				{"id": "any", "format": {"mso_mdoc": {"alg": ["ES256"]}}}
+			]
+		}`)
+		_, err := ValidatePresentationDefinition(definition, StrictValidation)
+		assert.ErrorContains(tt, err, "presentation definition not valid against version 2.0.0 schema")
+
+		report, err := ValidatePresentationDefinition(definition, LaxValidation)
+		require.NoError(tt, err)
+		assert.Equal(tt, V2, report.Version)
+		paths := make([]string, 0, len(report.Deviations))
+		for _, deviation := range report.Deviations {
+			paths = append(paths, deviation.Path)
+		}
+		assert.Equal(tt, []string{
+			"$.vendor_extension",
+			"$.format.jwt_vc_json",
+			"$.input_descriptors[0].constraints.limit_disclosure",
+			"$.input_descriptors[0].constraints.fields[0].filter_hint",
+			"$.input_descriptors[1].format.mso_mdoc",
+			"$.input_descriptors[1]",
+		}, paths)
+		assert.Equal(tt, "unknown property<vendor_extension> is ignored", report.Deviations[0].Description)
+		assert.Equal(tt, "limit_disclosure is a boolean rather than a preference", report.Deviations[2].Description)
+	})
+
+	t.Run("version 1 deviations are reported by lax validation", func(tt *testing.T) {
+		definition := []byte(`{"presentation_definition": {
+			"id": "test-id",
+			"input_descriptors": [{
+				"id": "employment",
+				"schema": {"uri": "https://example.com/schemas/employment.json"}
+			}]
+		}}`)
+		_, err := ValidatePresentationDefinition(definition, StrictValidation)
+		assert.ErrorContains(tt, err, "presentation definition not valid against version 1.0.0 schema")
+
+		report, err := ValidatePresentationDefinition(definition, LaxValidation)
+		require.NoError(tt, err)
+		assert.Equal(tt, V1, report.Version)
+		assert.Equal(tt, []Deviation{{
+			Path:        "$.presentation_definition.input_descriptors[0].schema",
+			Description: "schema is a single object rather than an array",
+		}}, report.Deviations)
+	})
+
+	t.Run("unknown deviations fail lax validation", func(tt *testing.T) {
+		definition := []byte(`{"input_descriptors": [{"id": "issuer", "constraints": {}}]}`)
+		_, err := ValidatePresentationDefinition(definition, LaxValidation)
+		assert.ErrorContains(tt, err, "presentation definition not valid against version 2.0.0 schema")
+	})
+
+	t.Run("unsupported mode", func(tt *testing.T) {
+		_, err := ValidatePresentationDefinition([]byte(`{}`), "loose")
+		assert.ErrorContains(tt, err, "unsupported validation mode<loose>")
+	})
+}
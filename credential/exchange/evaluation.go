@@ -0,0 +1,274 @@
+package exchange
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// EvaluationReport details the evaluation of a presentation definition's input descriptors against a set of claims:
+// which claim fulfilled each input descriptor and, for those which could not be fulfilled, why each claim did not
+type EvaluationReport struct {
+	DefinitionID     string                      `json:"definitionId"`
+	InputDescriptors []InputDescriptorEvaluation `json:"inputDescriptors"`
+}
+
+// InputDescriptorEvaluation is the evaluation of a single input descriptor
+type InputDescriptorEvaluation struct {
+	InputDescriptorID string `json:"inputDescriptorId"`
+	Fulfilled         bool   `json:"fulfilled"`
+	// Claims are the evaluations of each claim considered for the input descriptor
+	Claims []ClaimEvaluation `json:"claims,omitempty"`
+	// Reason is why the input descriptor was not fulfilled
+	Reason string `json:"reason,omitempty"`
+}
+
+// ClaimEvaluation is the evaluation of a single claim against an input descriptor
+type ClaimEvaluation struct {
+	ClaimID string `json:"claimId,omitempty"`
+	// Path is the JSON path of the claim among the evaluated claims or, for a presentation submission, the path of its
+	// submission descriptor
+	Path      string `json:"path"`
+	Fulfilled bool   `json:"fulfilled"`
+	// Score is the fraction of the input descriptor's fields, optional or not, matched by the claim. Of the claims
+	// fulfilling an input descriptor, those matching more optional fields score higher.
+	Score float64 `json:"score"`
+	// Fields are the evaluations of each field of the input descriptor for the claim. For a claim about multiple
+	// subjects, they are those of the subject fulfilling the input descriptor with the highest score, or else the one
+	// matching most fields.
+	Fields []FieldEvaluation `json:"fields,omitempty"`
+	// Reason is why the claim did not fulfill the input descriptor
+	Reason string `json:"reason,omitempty"`
+}
+
+// FieldEvaluation is the evaluation of a single field of an input descriptor against a claim
+type FieldEvaluation struct {
+	FieldID  string `json:"fieldId,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+	Matched  bool   `json:"matched"`
+	// Path is the path whose data matched the field
+	Path string `json:"path,omitempty"`
+	// Reason is why the field did not match
+	Reason string `json:"reason,omitempty"`
+}
+
+// IsFulfilled returns whether every input descriptor was fulfilled
+func (r EvaluationReport) IsFulfilled() bool {
+	for _, id := range r.InputDescriptors {
+		if !id.Fulfilled {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns an error listing why each unfulfilled input descriptor was not fulfilled, or nil if every input
+// descriptor was fulfilled
+func (r EvaluationReport) Err() error {
+	errs := util.NewAppendError()
+	for _, id := range r.InputDescriptors {
+		if err := id.Err(); err != nil {
+			errs.Append(errors.Wrapf(err, "input descriptor<%s>", id.InputDescriptorID))
+		}
+	}
+	return errs.Error()
+}
+
+// Err returns an error with why the input descriptor was not fulfilled, followed by why each claim did not fulfill
+// it, or nil if it was fulfilled
+func (e InputDescriptorEvaluation) Err() error {
+	if e.Fulfilled {
+		return nil
+	}
+	errs := util.NewAppendError()
+	errs.AppendString(e.Reason)
+	for _, claim := range e.Claims {
+		if !claim.Fulfilled && claim.Reason != "" {
+			errs.AppendString(fmt.Sprintf("claim<%s>: %s", claim.Path, claim.Reason))
+		}
+	}
+	return errs.Error()
+}
+
+// RankedClaims returns the evaluations of the claims fulfilling the input descriptor, from the highest score to the
+// lowest, so that wallets can rank candidate claims. Claims with equal scores keep their order.
+func (e InputDescriptorEvaluation) RankedClaims() []ClaimEvaluation {
+	var ranked []ClaimEvaluation
+	for _, claim := range e.Claims {
+		if claim.Fulfilled {
+			ranked = append(ranked, claim)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
+
+// EvaluatePresentationDefinition evaluates each input descriptor of a presentation definition against a set of
+// claims, as BuildPresentationSubmissionVP does when selecting claims, and reports the result for each input
+// descriptor, claim, and field. An error is returned only for definitions which cannot be processed.
+func EvaluatePresentationDefinition(def PresentationDefinition, claims []NormalizedClaim) (*EvaluationReport, error) {
+	if err := canProcessDefinition(def); err != nil {
+		return nil, errors.Wrap(err, "feature not supported in processing given presentation definition")
+	}
+	report := EvaluationReport{DefinitionID: def.ID}
+	for _, id := range def.InputDescriptors {
+		evaluation, _ := evaluateInputDescriptor(id, claims)
+		report.InputDescriptors = append(report.InputDescriptors, evaluation)
+	}
+	return &report, nil
+}
+
+// evaluateInputDescriptor runs the input evaluation algorithm described in the spec for a specific input descriptor,
+// evaluating every claim, and returns the claim fulfilling it with the highest score, if any
+// https://identity.foundation/presentation-exchange/#input-evaluation
+func evaluateInputDescriptor(id InputDescriptor, claims []NormalizedClaim) (InputDescriptorEvaluation, *NormalizedClaim) {
+	evaluation := InputDescriptorEvaluation{InputDescriptorID: id.ID}
+	constraints := id.Constraints
+	if constraints == nil {
+		evaluation.Reason = "unable to process input descriptor without constraints"
+		return evaluation, nil
+	}
+	fields := constraints.Fields
+	if len(fields) == 0 {
+		evaluation.Reason = fmt.Sprintf("unable to process input descriptor without fields: %s", id.ID)
+		return evaluation, nil
+	}
+	disclosure := constraints.LimitDisclosure
+	if disclosure != nil && *disclosure == Required {
+		// limiting disclosure requires deriving credentials, see BuildDerivedPresentationSubmissionVP
+		// otherwise, we won't be able to send back a claim with a signature attached
+		evaluation.Reason = "requiring limit disclosure is not supported"
+		return evaluation, nil
+	}
+	if hasRequiredPredicate(fields) {
+		// predicates replace claims with their results, which requires deriving credentials with a PredicateSuite
+		evaluation.Reason = fmt.Sprintf("input descriptor<%s> requires predicates, which are not supported", id.ID)
+		return evaluation, nil
+	}
+
+	// only claims conforming with the format required by the input descriptor are considered
+	if len(filterClaimsByFormat(claims, id.Format)) == 0 {
+		evaluation.Reason = fmt.Sprintf("no claims match the required format, and jwt alg/proof type requirements "+
+			"for input descriptor: %s", id.ID)
+		return evaluation, nil
+	}
+
+	// each field needs to match a claim for it to fulfill the input descriptor; a claim about multiple subjects
+	// fulfills the input descriptor if all fields match one of its subjects
+	var fulfilling *NormalizedClaim
+	var bestScore float64
+	for i, claim := range claims {
+		claimEvaluation := ClaimEvaluation{ClaimID: claim.ID, Path: fmt.Sprintf("$[%d]", i)}
+		if !isClaimFormatAccepted(claim, id.Format) {
+			claimEvaluation.Reason = fmt.Sprintf("format<%s> with alg or proof type<%s> is not accepted", claim.Format, claim.AlgOrProofType)
+			evaluation.Claims = append(evaluation.Claims, claimEvaluation)
+			continue
+		}
+		claimEvaluation.Fields, _ = evaluateSubjects(claim.Data, func(subjectData map[string]any) ([]FieldEvaluation, any) {
+			return matchFields(fields, subjectData), nil
+		})
+		claimEvaluation.Fulfilled = fieldsFulfilled(claimEvaluation.Fields)
+		claimEvaluation.Score = scoreFields(claimEvaluation.Fields)
+		if claimEvaluation.Fulfilled {
+			if fulfilling == nil || claimEvaluation.Score > bestScore {
+				fulfilling, bestScore = &claims[i], claimEvaluation.Score
+			}
+		} else {
+			claimEvaluation.Reason = unmatchedFieldsReason(claimEvaluation.Fields)
+		}
+		evaluation.Claims = append(evaluation.Claims, claimEvaluation)
+	}
+	if fulfilling == nil {
+		evaluation.Reason = fmt.Sprintf("no claims could fulfill the input descriptor: %s", id.ID)
+		return evaluation, nil
+	}
+	evaluation.Fulfilled = true
+	return evaluation, fulfilling
+}
+
+// matchFields evaluates each field against the claim data with Field.Match
+func matchFields(fields []Field, claimData map[string]any) []FieldEvaluation {
+	evaluations := make([]FieldEvaluation, 0, len(fields))
+	for _, field := range fields {
+		evaluation := FieldEvaluation{FieldID: field.ID, Optional: field.Optional}
+		path, _, err := field.Match(claimData)
+		if err != nil {
+			evaluation.Reason = err.Error()
+		} else {
+			evaluation.Matched = true
+			evaluation.Path = path
+		}
+		evaluations = append(evaluations, evaluation)
+	}
+	return evaluations
+}
+
+// ScoreClaim scores claim data against the fields of an input descriptor, returning the fraction of the fields,
+// optional or not, it matches, and whether it matches every non-optional field. For a claim about multiple subjects,
+// the score is that of its subject fulfilling the fields with the highest score.
+func ScoreClaim(fields []Field, claimData map[string]any) (float64, bool) {
+	evaluations, _ := evaluateSubjects(claimData, func(subjectData map[string]any) ([]FieldEvaluation, any) {
+		return matchFields(fields, subjectData), nil
+	})
+	return scoreFields(evaluations), fieldsFulfilled(evaluations)
+}
+
+// evaluateSubjects evaluates fields against each subject view of a claim, returning the evaluations, and data, of
+// the subject fulfilling every non-optional field with the highest score or, if none does, of the subject matching
+// most fields
+func evaluateSubjects(claimData map[string]any, evaluate func(subjectData map[string]any) ([]FieldEvaluation, any)) ([]FieldEvaluation, any) {
+	var best []FieldEvaluation
+	var bestData any
+	bestFulfilled, bestScore := false, -1.0
+	for _, subjectData := range SubjectViews(claimData) {
+		evaluations, data := evaluate(subjectData)
+		fulfilled, score := fieldsFulfilled(evaluations), scoreFields(evaluations)
+		if (fulfilled && !bestFulfilled) || (fulfilled == bestFulfilled && score > bestScore) {
+			best, bestData, bestFulfilled, bestScore = evaluations, data, fulfilled, score
+		}
+	}
+	return best, bestData
+}
+
+// scoreFields returns the fraction of fields matched, which is 1 when there are no fields
+func scoreFields(evaluations []FieldEvaluation) float64 {
+	if len(evaluations) == 0 {
+		return 1
+	}
+	matched := 0
+	for _, e := range evaluations {
+		if e.Matched {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(evaluations))
+}
+
+// fieldsFulfilled returns whether every non-optional field matched
+func fieldsFulfilled(evaluations []FieldEvaluation) bool {
+	for _, e := range evaluations {
+		if !e.Matched && !e.Optional {
+			return false
+		}
+	}
+	return true
+}
+
+// unmatchedFieldsReason describes why each non-optional field did not match
+func unmatchedFieldsReason(evaluations []FieldEvaluation) string {
+	errs := util.NewAppendError()
+	for _, e := range evaluations {
+		if !e.Matched && !e.Optional {
+			errs.AppendString(fmt.Sprintf("field<%s> not matched: %s", e.FieldID, e.Reason))
+		}
+	}
+	if err := errs.Error(); err != nil {
+		return err.Error()
+	}
+	return ""
+}
@@ -0,0 +1,239 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+)
+
+func TestEvaluatePresentationDefinition(t *testing.T) {
+	def := PresentationDefinition{
+		ID: "test-id",
+		InputDescriptors: []InputDescriptor{
+			{
+				ID: "issuer",
+				Constraints: &Constraints{
+					Fields: []Field{
+						{
+							ID:     "issuer-field",
+							Path:   []string{"$.issuer"},
+							Filter: &Filter{Type: "string", Const: "trusted-issuer"},
+						},
+						{
+							ID:       "website-field",
+							Path:     []string{"$.credentialSubject.website"},
+							Optional: true,
+						},
+					},
+				},
+			},
+			{
+				ID: "jwt",
+				Format: &ClaimFormat{
+					JWTVC: &JWTType{Alg: []crypto.SignatureAlgorithm{crypto.EdDSA}},
+				},
+				Constraints: &Constraints{
+					Fields: []Field{{ID: "any-issuer", Path: []string{"$.issuer"}}},
+				},
+			},
+		},
+	}
+
+	untrustedVC := getTestVerifiableCredential("untrusted-issuer", "test-subject")
+	untrustedVC.ID = "untrusted-credential"
+	trustedVC := getTestVerifiableCredential("trusted-issuer", "test-subject")
+	var claims []PresentationClaim
+	for _, vc := range []credential.VerifiableCredential{untrustedVC, trustedVC} {
+		vc := vc
+		claims = append(claims, PresentationClaim{
+			Credential:                    &vc,
+			LDPFormat:                     LDPVC.Ptr(),
+			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
+		})
+	}
+	normalized, err := NormalizePresentationClaims(claims)
+	require.NoError(t, err)
+
+	t.Run("details each descriptor, claim, and field", func(tt *testing.T) {
+		report, err := EvaluatePresentationDefinition(def, normalized)
+		require.NoError(tt, err)
+		assert.Equal(tt, "test-id", report.DefinitionID)
+		assert.False(tt, report.IsFulfilled())
+		require.Len(tt, report.InputDescriptors, 2)
+
+		issuer := report.InputDescriptors[0]
+		assert.True(tt, issuer.Fulfilled)
+		assert.NoError(tt, issuer.Err())
+		require.Len(tt, issuer.Claims, 2)
+
+		untrusted := issuer.Claims[0]
+		assert.Equal(tt, "untrusted-credential", untrusted.ClaimID)
+		assert.Equal(tt, "$[0]", untrusted.Path)
+		assert.False(tt, untrusted.Fulfilled)
+		assert.Contains(tt, untrusted.Reason, "field<issuer-field> not matched")
+		assert.Equal(tt, 0.5, untrusted.Score)
+		require.Len(tt, untrusted.Fields, 2)
+		assert.False(tt, untrusted.Fields[0].Matched)
+		assert.Contains(tt, untrusted.Fields[0].Reason, "unable to apply filter")
+		assert.True(tt, untrusted.Fields[1].Matched)
+		assert.Equal(tt, "$.credentialSubject.website", untrusted.Fields[1].Path)
+
+		trusted := issuer.Claims[1]
+		assert.Equal(tt, "test-verifiable-credential", trusted.ClaimID)
+		assert.True(tt, trusted.Fulfilled)
+		assert.Empty(tt, trusted.Reason)
+		assert.Equal(tt, 1.0, trusted.Score)
+
+		jwt := report.InputDescriptors[1]
+		assert.False(tt, jwt.Fulfilled)
+		assert.Contains(tt, jwt.Reason, "no claims match the required format")
+		assert.Empty(tt, jwt.Claims)
+
+		err = report.Err()
+		assert.ErrorContains(tt, err, "input descriptor<jwt>")
+		assert.NotContains(tt, err.Error(), "input descriptor<issuer>")
+	})
+
+	t.Run("unfulfilled descriptor explains each claim", func(tt *testing.T) {
+		report, err := EvaluatePresentationDefinition(def, normalized[:1])
+		require.NoError(tt, err)
+		issuer := report.InputDescriptors[0]
+		assert.False(tt, issuer.Fulfilled)
+		assert.Equal(tt, "no claims could fulfill the input descriptor: issuer", issuer.Reason)
+		assert.ErrorContains(tt, issuer.Err(), "claim<$[0]>: field<issuer-field> not matched")
+	})
+
+	t.Run("ranks claims matching optional fields higher", func(tt *testing.T) {
+		id := InputDescriptor{
+			ID: "ranked",
+			Constraints: &Constraints{
+				Fields: []Field{
+					{ID: "company-field", Path: []string{"$.credentialSubject.company"}},
+					{
+						ID:       "issuer-field",
+						Path:     []string{"$.issuer"},
+						Filter:   &Filter{Type: "string", Const: "trusted-issuer"},
+						Optional: true,
+					},
+				},
+			},
+		}
+		evaluation, claim := evaluateInputDescriptor(id, normalized)
+		assert.True(tt, evaluation.Fulfilled)
+		require.NotNil(tt, claim)
+		assert.Equal(tt, "test-verifiable-credential", claim.ID)
+
+		ranked := evaluation.RankedClaims()
+		require.Len(tt, ranked, 2)
+		assert.Equal(tt, "test-verifiable-credential", ranked[0].ClaimID)
+		assert.Equal(tt, 1.0, ranked[0].Score)
+		assert.Equal(tt, "untrusted-credential", ranked[1].ClaimID)
+		assert.Equal(tt, 0.5, ranked[1].Score)
+
+		score, ok := ScoreClaim(id.Constraints.Fields, normalized[0].Data)
+		assert.True(tt, ok)
+		assert.Equal(tt, 0.5, score)
+	})
+
+	t.Run("unsupported definition", func(tt *testing.T) {
+		_, err := EvaluatePresentationDefinition(PresentationDefinition{}, normalized)
+		assert.ErrorContains(tt, err, "presentation definition cannot be empty")
+	})
+}
+
+func TestEvaluatePresentationSubmissionVP(t *testing.T) {
+	def := PresentationDefinition{
+		ID: "test-id",
+		InputDescriptors: []InputDescriptor{
+			{
+				ID: "issuer",
+				Constraints: &Constraints{
+					Fields: []Field{
+						{
+							ID:     "issuer-field",
+							Path:   []string{"$.issuer"},
+							Filter: &Filter{Type: "string", Const: "trusted-issuer"},
+						},
+						{
+							ID:   "company-field",
+							Path: []string{"$.credentialSubject.company"},
+						},
+					},
+				},
+			},
+			{
+				ID: "missing",
+				Constraints: &Constraints{
+					Fields: []Field{{ID: "any-issuer", Path: []string{"$.issuer"}}},
+				},
+			},
+		},
+	}
+	presentation := credential.VerifiablePresentation{
+		Context: []string{"https://www.w3.org/2018/credentials/v1",
+			"https://identity.foundation/presentation-exchange/submission/v1"},
+		ID:   "55da1f5c-e2b3-443a-b687-0434712c5469",
+		Type: []string{"VerifiablePresentation", "PresentationSubmission"},
+		PresentationSubmission: PresentationSubmission{
+			ID:           "45da2588-3637-45b0-84f1-17e97945ac09",
+			DefinitionID: "test-id",
+			DescriptorMap: []SubmissionDescriptor{
+				{
+					Format: "ldp_vc",
+					ID:     "issuer",
+					Path:   "$.verifiableCredential[0]",
+				},
+			},
+		},
+		VerifiableCredential: []any{
+			getTestVerifiableCredential("untrusted-issuer", "test-subject"),
+		},
+	}
+
+	t.Run("reports every input descriptor", func(tt *testing.T) {
+		report, err := EvaluatePresentationSubmissionVP(def, presentation)
+		require.NoError(tt, err)
+		assert.False(tt, report.IsFulfilled())
+		require.Len(tt, report.InputDescriptors, 2)
+
+		issuer := report.InputDescriptors[0]
+		assert.False(tt, issuer.Fulfilled)
+		assert.Contains(tt, issuer.Reason, "not fulfilled for non-optional field<issuer-field>")
+		require.Len(tt, issuer.Claims, 1)
+		claim := issuer.Claims[0]
+		assert.Equal(tt, "test-verifiable-credential", claim.ClaimID)
+		assert.Equal(tt, "$.verifiableCredential[0]", claim.Path)
+		assert.False(tt, claim.Fulfilled)
+		require.Len(tt, claim.Fields, 2)
+		assert.False(tt, claim.Fields[0].Matched)
+		assert.True(tt, claim.Fields[1].Matched)
+
+		missing := report.InputDescriptors[1]
+		assert.False(tt, missing.Fulfilled)
+		assert.Contains(tt, missing.Reason, "unfulfilled input descriptor<missing>")
+		assert.Empty(tt, missing.Claims)
+	})
+
+	t.Run("fulfilled submission", func(tt *testing.T) {
+		fulfilled := presentation
+		fulfilled.VerifiableCredential = []any{getTestVerifiableCredential("trusted-issuer", "test-subject")}
+		report, err := EvaluatePresentationSubmissionVP(PresentationDefinition{
+			ID:               "test-id",
+			InputDescriptors: def.InputDescriptors[:1],
+		}, fulfilled)
+		require.NoError(tt, err)
+		assert.True(tt, report.IsFulfilled())
+		assert.NoError(tt, report.Err())
+		assert.True(tt, report.InputDescriptors[0].Claims[0].Fulfilled)
+	})
+
+	t.Run("submission for another definition", func(tt *testing.T) {
+		_, err := EvaluatePresentationSubmissionVP(PresentationDefinition{ID: "other-id", InputDescriptors: def.InputDescriptors}, presentation)
+		assert.ErrorContains(tt, err, "mismatched between presentation definition ID<other-id>")
+	})
+}
@@ -0,0 +1,413 @@
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/schema"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// DefinitionBuilder builds a PresentationDefinition through chained calls, e.g.
+//
+//	def, err := NewDefinition().
+//		Purpose("verify your age").
+//		InputDescriptor(NewDescriptor("age").
+//			JWTVCFormat(crypto.EdDSA).
+//			Field(NewField("$.credentialSubject.birthDate").Filter(Filter{Type: "string", Format: "date"}))).
+//		Build()
+//
+// Unlike PresentationDefinitionBuilder, problems are not returned by each call, but collected and returned together
+// by Build, which also validates the whole definition.
+type DefinitionBuilder struct {
+	definition  PresentationDefinition
+	descriptors []*DescriptorBuilder
+	errs        *util.AppendError
+}
+
+// NewDefinition returns a builder for a presentation definition with a random ID
+func NewDefinition() *DefinitionBuilder {
+	return &DefinitionBuilder{
+		definition: PresentationDefinition{ID: uuid.NewString()},
+		errs:       util.NewAppendError(),
+	}
+}
+
+// ID sets the ID of the definition, replacing the random ID
+func (b *DefinitionBuilder) ID(id string) *DefinitionBuilder {
+	if id == "" {
+		b.errs.AppendString("cannot set empty id")
+	}
+	b.definition.ID = id
+	return b
+}
+
+func (b *DefinitionBuilder) Name(name string) *DefinitionBuilder {
+	b.definition.Name = name
+	return b
+}
+
+func (b *DefinitionBuilder) Purpose(purpose string) *DefinitionBuilder {
+	b.definition.Purpose = purpose
+	return b
+}
+
+// Format sets the claim formats accepted for every input descriptor
+func (b *DefinitionBuilder) Format(format ClaimFormat) *DefinitionBuilder {
+	b.definition.Format = &format
+	return b
+}
+
+// JWTVCFormat accepts JWT credentials signed with one of the given algorithms for every input descriptor
+func (b *DefinitionBuilder) JWTVCFormat(algs ...crypto.SignatureAlgorithm) *DefinitionBuilder {
+	b.definition.Format = withJWTVCFormat(b.definition.Format, algs)
+	return b
+}
+
+// LDPVCFormat accepts Data Integrity credentials with one of the given proof types for every input descriptor
+func (b *DefinitionBuilder) LDPVCFormat(proofTypes ...cryptosuite.SignatureType) *DefinitionBuilder {
+	b.definition.Format = withLDPVCFormat(b.definition.Format, proofTypes)
+	return b
+}
+
+// InputDescriptor adds an input descriptor, which is built when the definition is built
+func (b *DefinitionBuilder) InputDescriptor(descriptor *DescriptorBuilder) *DefinitionBuilder {
+	if descriptor == nil {
+		b.errs.AppendString("cannot add empty input descriptor")
+		return b
+	}
+	b.descriptors = append(b.descriptors, descriptor)
+	return b
+}
+
+// SubmissionRequirement adds a submission requirement
+func (b *DefinitionBuilder) SubmissionRequirement(requirement SubmissionRequirement) *DefinitionBuilder {
+	b.definition.SubmissionRequirements = append(b.definition.SubmissionRequirements, requirement)
+	return b
+}
+
+// RequireAll adds a submission requirement that every input descriptor of the group is fulfilled
+func (b *DefinitionBuilder) RequireAll(group string) *DefinitionBuilder {
+	return b.SubmissionRequirement(SubmissionRequirement{Rule: All, FromOption: FromOption{From: group}})
+}
+
+// RequirePick adds a submission requirement that count of the input descriptors of the group are fulfilled
+func (b *DefinitionBuilder) RequirePick(group string, count int) *DefinitionBuilder {
+	return b.SubmissionRequirement(SubmissionRequirement{Rule: Pick, Count: count, FromOption: FromOption{From: group}})
+}
+
+// Frame sets the JSON-LD frame of the definition
+func (b *DefinitionBuilder) Frame(frame any) *DefinitionBuilder {
+	b.definition.Frame = frame
+	return b
+}
+
+// Build builds each input descriptor and returns the definition, or the problems found while building it. Input
+// descriptor IDs must be unique, and groups named by submission requirements must be used by an input descriptor.
+func (b *DefinitionBuilder) Build() (*PresentationDefinition, error) {
+	errs := util.NewAppendError()
+	if b.errs.Error() != nil {
+		errs.Append(b.errs.Error())
+	}
+
+	def := b.definition
+	def.Format = copyClaimFormat(def.Format)
+	def.InputDescriptors = nil
+	seenIDs := make(map[string]bool)
+	groups := make(map[string]bool)
+	for _, descriptorBuilder := range b.descriptors {
+		descriptor, err := descriptorBuilder.Build()
+		if err != nil {
+			errs.Append(errors.Wrapf(err, "input descriptor<%s>", descriptorBuilder.descriptor.ID))
+			continue
+		}
+		if seenIDs[descriptor.ID] {
+			errs.AppendString(fmt.Sprintf("input descriptor id<%s> duplicated", descriptor.ID))
+		}
+		seenIDs[descriptor.ID] = true
+		for _, group := range descriptor.Group {
+			groups[group] = true
+		}
+		def.InputDescriptors = append(def.InputDescriptors, *descriptor)
+	}
+	if len(b.descriptors) == 0 {
+		errs.AppendString("presentation definition must have at least one input descriptor")
+	}
+	for _, group := range requirementGroups(def.SubmissionRequirements) {
+		if !groups[group] {
+			errs.AppendString(fmt.Sprintf("submission requirement group<%s> has no input descriptors", group))
+		}
+	}
+	if errs.Error() != nil {
+		return nil, errors.Wrap(errs.Error(), "presentation definition not ready to be built")
+	}
+
+	if err := def.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "presentation definition not ready to be built")
+	}
+	return &def, nil
+}
+
+// requirementGroups returns the groups named by submission requirements, including nested requirements
+func requirementGroups(requirements []SubmissionRequirement) []string {
+	var groups []string
+	for _, requirement := range requirements {
+		if requirement.From != "" {
+			groups = append(groups, requirement.From)
+		}
+		groups = append(groups, requirementGroups(requirement.FromNested)...)
+	}
+	return groups
+}
+
+// DescriptorBuilder builds an InputDescriptor through chained calls, for a DefinitionBuilder
+type DescriptorBuilder struct {
+	descriptor  InputDescriptor
+	constraints Constraints
+	fields      []*FieldBuilder
+	errs        *util.AppendError
+}
+
+// NewDescriptor returns a builder for an input descriptor with the given ID, which must be unique within its
+// definition
+func NewDescriptor(id string) *DescriptorBuilder {
+	errs := util.NewAppendError()
+	if id == "" {
+		errs.AppendString("cannot set empty id")
+	}
+	return &DescriptorBuilder{
+		descriptor: InputDescriptor{ID: id},
+		errs:       errs,
+	}
+}
+
+func (b *DescriptorBuilder) Name(name string) *DescriptorBuilder {
+	b.descriptor.Name = name
+	return b
+}
+
+func (b *DescriptorBuilder) Purpose(purpose string) *DescriptorBuilder {
+	b.descriptor.Purpose = purpose
+	return b
+}
+
+// LocalizedName sets the name of the input descriptor in each language of a language map
+func (b *DescriptorBuilder) LocalizedName(languages LanguageMap) *DescriptorBuilder {
+	b.descriptor.NameLanguages = languages
+	return b
+}
+
+// LocalizedPurpose sets the purpose of the input descriptor in each language of a language map
+func (b *DescriptorBuilder) LocalizedPurpose(languages LanguageMap) *DescriptorBuilder {
+	b.descriptor.PurposeLanguages = languages
+	return b
+}
+
+// Format sets the claim formats accepted for the input descriptor
+func (b *DescriptorBuilder) Format(format ClaimFormat) *DescriptorBuilder {
+	b.descriptor.Format = &format
+	return b
+}
+
+// JWTVCFormat accepts JWT credentials signed with one of the given algorithms
+func (b *DescriptorBuilder) JWTVCFormat(algs ...crypto.SignatureAlgorithm) *DescriptorBuilder {
+	b.descriptor.Format = withJWTVCFormat(b.descriptor.Format, algs)
+	return b
+}
+
+// LDPVCFormat accepts Data Integrity credentials with one of the given proof types
+func (b *DescriptorBuilder) LDPVCFormat(proofTypes ...cryptosuite.SignatureType) *DescriptorBuilder {
+	b.descriptor.Format = withLDPVCFormat(b.descriptor.Format, proofTypes)
+	return b
+}
+
+// Group adds the input descriptor to groups named by submission requirements
+func (b *DescriptorBuilder) Group(groups ...string) *DescriptorBuilder {
+	b.descriptor.Group = append(b.descriptor.Group, groups...)
+	return b
+}
+
+// Field adds a field constraint, which is built when the input descriptor is built
+func (b *DescriptorBuilder) Field(field *FieldBuilder) *DescriptorBuilder {
+	if field == nil {
+		b.errs.AppendString("cannot add empty field")
+		return b
+	}
+	b.fields = append(b.fields, field)
+	return b
+}
+
+func (b *DescriptorBuilder) LimitDisclosure(preference Preference) *DescriptorBuilder {
+	b.constraints.LimitDisclosure = preference.Ptr()
+	return b
+}
+
+func (b *DescriptorBuilder) SubjectIsIssuer(preference Preference) *DescriptorBuilder {
+	b.constraints.SubjectIsIssuer = preference.Ptr()
+	return b
+}
+
+// IsHolder adds a relational constraint that the holder is the subject of the fields with the given IDs
+func (b *DescriptorBuilder) IsHolder(directive Preference, fieldIDs ...string) *DescriptorBuilder {
+	b.constraints.IsHolder = append(b.constraints.IsHolder, RelationalConstraint{FieldID: fieldIDs, Directive: directive.Ptr()})
+	return b
+}
+
+// SameSubject adds a relational constraint that the fields with the given IDs are about the same subject
+func (b *DescriptorBuilder) SameSubject(directive Preference, fieldIDs ...string) *DescriptorBuilder {
+	b.constraints.SameSubject = append(b.constraints.SameSubject, RelationalConstraint{FieldID: fieldIDs, Directive: directive.Ptr()})
+	return b
+}
+
+// Statuses sets the credential status constraint
+func (b *DescriptorBuilder) Statuses(statuses CredentialStatus) *DescriptorBuilder {
+	b.constraints.Statuses = &statuses
+	return b
+}
+
+// Build builds each field and returns the input descriptor, or the problems found while building it. Relational
+// constraints must refer to the IDs of the input descriptor's fields.
+func (b *DescriptorBuilder) Build() (*InputDescriptor, error) {
+	errs := util.NewAppendError()
+	if b.errs.Error() != nil {
+		errs.Append(b.errs.Error())
+	}
+
+	descriptor := b.descriptor
+	descriptor.Format = copyClaimFormat(descriptor.Format)
+	constraints := b.constraints
+	constraints.Fields = nil
+	fieldIDs := make(map[string]bool)
+	for i, fieldBuilder := range b.fields {
+		field, err := fieldBuilder.Build()
+		if err != nil {
+			errs.Append(errors.Wrapf(err, "field<%d>", i))
+			continue
+		}
+		if field.ID != "" {
+			fieldIDs[field.ID] = true
+		}
+		constraints.Fields = append(constraints.Fields, *field)
+	}
+	for _, relational := range append(append([]RelationalConstraint{}, constraints.IsHolder...), constraints.SameSubject...) {
+		for _, fieldID := range relational.FieldID {
+			if !fieldIDs[fieldID] {
+				errs.AppendString(fmt.Sprintf("relational constraint refers to unknown field<%s>", fieldID))
+			}
+		}
+	}
+	if errs.Error() != nil {
+		return nil, errs.Error()
+	}
+
+	descriptor.Constraints = &constraints
+	if err := descriptor.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "input descriptor not ready to be built")
+	}
+	return &descriptor, nil
+}
+
+// FieldBuilder builds a Field through chained calls, for a DescriptorBuilder
+type FieldBuilder struct {
+	field Field
+}
+
+// NewField returns a builder for a field matching the first of the given JSON paths which resolves
+func NewField(paths ...string) *FieldBuilder {
+	return &FieldBuilder{field: Field{Path: paths}}
+}
+
+// ID sets the ID of the field, which relational constraints refer to
+func (b *FieldBuilder) ID(id string) *FieldBuilder {
+	b.field.ID = id
+	return b
+}
+
+func (b *FieldBuilder) Name(name string) *FieldBuilder {
+	b.field.Name = name
+	return b
+}
+
+func (b *FieldBuilder) Purpose(purpose string) *FieldBuilder {
+	b.field.Purpose = purpose
+	return b
+}
+
+// Filter sets the JSON Schema the data at the field's path must be valid against
+func (b *FieldBuilder) Filter(filter Filter) *FieldBuilder {
+	b.field.Filter = &filter
+	return b
+}
+
+// Optional marks the field as not required to fulfill the input descriptor
+func (b *FieldBuilder) Optional() *FieldBuilder {
+	b.field.Optional = true
+	return b
+}
+
+// IntentToRetain notes that the verifier intends to retain the field's data
+func (b *FieldBuilder) IntentToRetain() *FieldBuilder {
+	b.field.IntentToRetain = true
+	return b
+}
+
+// Predicate requests the result of the field's filter in place of its data
+func (b *FieldBuilder) Predicate(preference Preference) *FieldBuilder {
+	b.field.Predicate = preference.Ptr()
+	return b
+}
+
+// Build returns the field, or an error if it has no paths or a predicate without a filter
+func (b *FieldBuilder) Build() (*Field, error) {
+	field := b.field
+	if len(field.Path) == 0 {
+		return nil, errors.New("field must have at least one path")
+	}
+	for _, path := range field.Path {
+		if path == "" {
+			return nil, errors.New("field cannot have an empty path")
+		}
+	}
+	if err := isValidPredicate(field); err != nil {
+		return nil, err
+	}
+	if field.Filter != nil {
+		filterJSON, err := field.Filter.ToJSON()
+		if err != nil {
+			return nil, errors.Wrap(err, "turning filter into JSON schema")
+		}
+		if err = schema.IsValidJSONSchema(filterJSON); err != nil {
+			return nil, errors.Wrap(err, "filter is not a valid JSON schema")
+		}
+	}
+	return &field, nil
+}
+
+func withJWTVCFormat(format *ClaimFormat, algs []crypto.SignatureAlgorithm) *ClaimFormat {
+	if format == nil {
+		format = new(ClaimFormat)
+	}
+	format.JWTVC = &JWTType{Alg: algs}
+	return format
+}
+
+func withLDPVCFormat(format *ClaimFormat, proofTypes []cryptosuite.SignatureType) *ClaimFormat {
+	if format == nil {
+		format = new(ClaimFormat)
+	}
+	format.LDPVC = &LDPType{ProofType: proofTypes}
+	return format
+}
+
+// copyClaimFormat copies a format, so that built values are not changed by later calls to their builder
+func copyClaimFormat(format *ClaimFormat) *ClaimFormat {
+	if format == nil {
+		return nil
+	}
+	formatCopy := *format
+	return &formatCopy
+}
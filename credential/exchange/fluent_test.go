@@ -0,0 +1,76 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestDefinitionBuilder(t *testing.T) {
+	t.Run("builds a valid definition", func(tt *testing.T) {
+		def, err := NewDefinition().
+			ID("age-verification").
+			Name("Age Verification").
+			Purpose("verify your age").
+			JWTVCFormat(crypto.EdDSA).
+			InputDescriptor(NewDescriptor("license").
+				Group("A").
+				SubjectIsIssuer(Preferred).
+				Field(NewField("$.credentialSubject.birthDate", "$.vc.credentialSubject.birthDate").
+					ID("birthDate").
+					Filter(Filter{Type: "string", Format: "date"}).
+					Predicate(Preferred)).
+				IsHolder(Required, "birthDate")).
+			InputDescriptor(NewDescriptor("passport").
+				Group("A").
+				Field(NewField("$.credentialSubject.nationality").Optional())).
+			RequirePick("A", 1).
+			Build()
+		require.NoError(tt, err)
+		require.NotNil(tt, def)
+		assert.Equal(tt, "age-verification", def.ID)
+		assert.Equal(tt, []string{JWTVC.String()}, def.Format.FormatValues())
+		require.Len(tt, def.InputDescriptors, 2)
+		license := def.InputDescriptors[0]
+		assert.Equal(tt, []string{"A"}, license.Group)
+		assert.Equal(tt, Preferred, *license.Constraints.SubjectIsIssuer)
+		require.Len(tt, license.Constraints.Fields, 1)
+		assert.Equal(tt, "date", license.Constraints.Fields[0].Filter.Format)
+		assert.Equal(tt, []RelationalConstraint{{FieldID: []string{"birthDate"}, Directive: Required.Ptr()}}, license.Constraints.IsHolder)
+		assert.True(tt, def.InputDescriptors[1].Constraints.Fields[0].Optional)
+		assert.Equal(tt, []SubmissionRequirement{{Rule: Pick, Count: 1, FromOption: FromOption{From: "A"}}}, def.SubmissionRequirements)
+	})
+
+	t.Run("collects problems at build", func(tt *testing.T) {
+		_, err := NewDefinition().
+			InputDescriptor(NewDescriptor("").
+				Field(NewField())).
+			InputDescriptor(NewDescriptor("dup").
+				Field(NewField("$.issuer").Predicate(Required)).
+				SameSubject(Required, "unknown")).
+			InputDescriptor(NewDescriptor("dup").Field(NewField("$.issuer"))).
+			InputDescriptor(NewDescriptor("dup").Field(NewField("$.issuer"))).
+			RequireAll("B").
+			Build()
+		require.Error(tt, err)
+		assert.Contains(tt, err.Error(), "cannot set empty id")
+		assert.Contains(tt, err.Error(), "field must have at least one path")
+		assert.Contains(tt, err.Error(), "has a predicate without a filter")
+		assert.Contains(tt, err.Error(), "relational constraint refers to unknown field<unknown>")
+		assert.Contains(tt, err.Error(), "input descriptor id<dup> duplicated")
+		assert.Contains(tt, err.Error(), "submission requirement group<B> has no input descriptors")
+	})
+
+	t.Run("no input descriptors", func(tt *testing.T) {
+		_, err := NewDefinition().Build()
+		assert.ErrorContains(tt, err, "must have at least one input descriptor")
+	})
+
+	t.Run("invalid filter", func(tt *testing.T) {
+		_, err := NewDescriptor("id").Field(NewField("$.issuer").Filter(Filter{Type: "not-a-type"})).Build()
+		assert.ErrorContains(tt, err, "filter is not a valid JSON schema")
+	})
+}
@@ -0,0 +1,147 @@
+package exchange
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Fulfillment is the result of checking whether a holder's claims can fulfill a presentation definition
+type Fulfillment struct {
+	// CanFulfill is whether the claims can fulfill every input descriptor or, for a definition with submission
+	// requirements, every submission requirement
+	CanFulfill bool `json:"canFulfill"`
+	// Candidates maps the ID of each input descriptor the claims can fulfill to the indices of the claims which can
+	Candidates map[string][]int `json:"candidates,omitempty"`
+	// Missing are the input descriptors no claim can fulfill which are needed to fulfill the definition, such as to
+	// tell a holder which credentials they need
+	Missing []MissingInputDescriptor `json:"missing,omitempty"`
+}
+
+// MissingInputDescriptor is an input descriptor no claim can fulfill, whose name and purpose describe the claim
+// needed to a holder
+type MissingInputDescriptor struct {
+	InputDescriptor InputDescriptor `json:"inputDescriptor"`
+	// Reason is why no claim can fulfill the input descriptor
+	Reason string `json:"reason"`
+}
+
+// CanFulfill quickly checks whether a holder's claims can fulfill a presentation definition, and which input
+// descriptors they are missing, without building a presentation submission. Claims fulfill an input descriptor when
+// in an accepted format and matching each of its non-optional fields. Submission requirements are checked, needing
+// as many of their input descriptors fulfilled as they pick. Constraints which cannot be checked from the claims
+// alone, such as statuses and relational constraints, are left to building the submission, as is limiting
+// disclosure.
+func CanFulfill(def PresentationDefinition, claims []PresentationClaim) (*Fulfillment, error) {
+	if def.IsEmpty() {
+		return nil, errors.New("presentation definition cannot be empty")
+	}
+	normalized, err := NormalizePresentationClaims(claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "normalizing claims")
+	}
+
+	fulfillment := Fulfillment{Candidates: make(map[string][]int)}
+	reasons := make(map[string]string)
+	for _, id := range def.InputDescriptors {
+		candidates, reason := findCandidates(id, normalized)
+		if len(candidates) > 0 {
+			fulfillment.Candidates[id.ID] = candidates
+		} else {
+			reasons[id.ID] = reason
+		}
+	}
+
+	// without submission requirements, every input descriptor is needed
+	needed := make(map[string]bool)
+	if len(def.SubmissionRequirements) == 0 {
+		for id := range reasons {
+			needed[id] = true
+		}
+	} else {
+		groups := make(map[string][]string)
+		for _, id := range def.InputDescriptors {
+			for _, group := range id.Group {
+				groups[group] = append(groups[group], id.ID)
+			}
+		}
+		for _, requirement := range def.SubmissionRequirements {
+			_, missing := satisfiesRequirement(requirement, groups, fulfillment.Candidates)
+			for _, id := range missing {
+				needed[id] = true
+			}
+		}
+	}
+	for _, id := range def.InputDescriptors {
+		if needed[id.ID] {
+			fulfillment.Missing = append(fulfillment.Missing, MissingInputDescriptor{InputDescriptor: id, Reason: reasons[id.ID]})
+		}
+	}
+	fulfillment.CanFulfill = len(fulfillment.Missing) == 0
+	return &fulfillment, nil
+}
+
+// findCandidates returns the indices of the claims in an accepted format matching each non-optional field of an
+// input descriptor or, if there are none, why not
+func findCandidates(id InputDescriptor, claims []NormalizedClaim) ([]int, string) {
+	var fields []Field
+	if id.Constraints != nil {
+		fields = id.Constraints.Fields
+	}
+	var candidates []int
+	formatAccepted := false
+	for i, claim := range claims {
+		if !isClaimFormatAccepted(claim, id.Format) {
+			continue
+		}
+		formatAccepted = true
+		if _, fulfilled := ScoreClaim(fields, claim.Data); fulfilled {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates, ""
+	}
+	if !formatAccepted {
+		return nil, "no claims are in an accepted format"
+	}
+	return nil, "no claims match its fields"
+}
+
+// satisfiesRequirement returns whether the fulfilled input descriptors satisfy a submission requirement or, if not,
+// the unfulfilled input descriptors which could satisfy it. Picking needs at least count, or else min, of the
+// requirement's input descriptors or nested requirements; a holder may choose fewer than they have to not pick more
+// than max.
+// https://identity.foundation/presentation-exchange/#submission-requirement-rules
+func satisfiesRequirement(requirement SubmissionRequirement, groups map[string][]string, fulfilled map[string][]int) (bool, []string) {
+	var satisfied, total int
+	var missing []string
+	if requirement.From != "" {
+		for _, id := range groups[requirement.From] {
+			total++
+			if len(fulfilled[id]) > 0 {
+				satisfied++
+			} else {
+				missing = append(missing, id)
+			}
+		}
+	} else {
+		for _, nested := range requirement.FromNested {
+			total++
+			if ok, nestedMissing := satisfiesRequirement(nested, groups, fulfilled); ok {
+				satisfied++
+			} else {
+				missing = append(missing, nestedMissing...)
+			}
+		}
+	}
+	needed := total
+	if requirement.Rule == Pick {
+		needed = requirement.Count
+		if needed == 0 {
+			needed = requirement.Minimum
+		}
+	}
+	if satisfied >= needed {
+		return true, nil
+	}
+	return false, missing
+}
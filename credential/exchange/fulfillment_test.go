@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+)
+
+func TestCanFulfill(t *testing.T) {
+	testVC := getTestVerifiableCredential("test-issuer", "test-subject")
+	claims := []PresentationClaim{{
+		Credential:                    &testVC,
+		LDPFormat:                     LDPVC.Ptr(),
+		SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
+	}}
+	company := InputDescriptor{
+		ID:    "company",
+		Group: []string{"A"},
+		Constraints: &Constraints{
+			Fields: []Field{{Path: []string{"$.credentialSubject.company"}}},
+		},
+	}
+	license := InputDescriptor{
+		ID:      "license",
+		Name:    "Driver's license",
+		Purpose: "We need to verify your age",
+		Group:   []string{"A"},
+		Constraints: &Constraints{
+			Fields: []Field{{Path: []string{"$.credentialSubject.licenseNumber"}}},
+		},
+	}
+	jwt := InputDescriptor{
+		ID:          "jwt",
+		Group:       []string{"B"},
+		Format:      &ClaimFormat{JWTVC: &JWTType{Alg: []crypto.SignatureAlgorithm{crypto.EdDSA}}},
+		Constraints: &Constraints{},
+	}
+
+	t.Run("fulfillable definition", func(tt *testing.T) {
+		fulfillment, err := CanFulfill(PresentationDefinition{ID: "test-id", InputDescriptors: []InputDescriptor{company}}, claims)
+		require.NoError(tt, err)
+		assert.True(tt, fulfillment.CanFulfill)
+		assert.Equal(tt, map[string][]int{"company": {0}}, fulfillment.Candidates)
+		assert.Empty(tt, fulfillment.Missing)
+	})
+
+	t.Run("reports missing input descriptors", func(tt *testing.T) {
+		def := PresentationDefinition{ID: "test-id", InputDescriptors: []InputDescriptor{company, license, jwt}}
+		fulfillment, err := CanFulfill(def, claims)
+		require.NoError(tt, err)
+		assert.False(tt, fulfillment.CanFulfill)
+		require.Len(tt, fulfillment.Missing, 2)
+		assert.Equal(tt, "Driver's license", fulfillment.Missing[0].InputDescriptor.Name)
+		assert.Equal(tt, "no claims match its fields", fulfillment.Missing[0].Reason)
+		assert.Equal(tt, "jwt", fulfillment.Missing[1].InputDescriptor.ID)
+		assert.Equal(tt, "no claims are in an accepted format", fulfillment.Missing[1].Reason)
+	})
+
+	t.Run("checks submission requirements", func(tt *testing.T) {
+		def := PresentationDefinition{
+			ID:               "test-id",
+			InputDescriptors: []InputDescriptor{company, license, jwt},
+			SubmissionRequirements: []SubmissionRequirement{
+				{Rule: Pick, Count: 1, FromOption: FromOption{From: "A"}},
+			},
+		}
+		fulfillment, err := CanFulfill(def, claims)
+		require.NoError(tt, err)
+		assert.True(tt, fulfillment.CanFulfill)
+
+		def.SubmissionRequirements = []SubmissionRequirement{
+			{
+				Rule: All,
+				FromOption: FromOption{FromNested: []SubmissionRequirement{
+					{Rule: Pick, Minimum: 1, FromOption: FromOption{From: "A"}},
+					{Rule: All, FromOption: FromOption{From: "B"}},
+				}},
+			},
+		}
+		fulfillment, err = CanFulfill(def, claims)
+		require.NoError(tt, err)
+		assert.False(tt, fulfillment.CanFulfill)
+		require.Len(tt, fulfillment.Missing, 1)
+		assert.Equal(tt, "jwt", fulfillment.Missing[0].InputDescriptor.ID)
+	})
+
+	t.Run("empty definition", func(tt *testing.T) {
+		_, err := CanFulfill(PresentationDefinition{}, claims)
+		assert.ErrorContains(tt, err, "presentation definition cannot be empty")
+	})
+}
@@ -0,0 +1,113 @@
+package exchange
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// noLanguage is the key of a language map for text without a language
+// https://www.w3.org/TR/json-ld11/#language-maps
+const noLanguage = "@none"
+
+// LanguageMap maps BCP 47 language tags to text in that language, such as an input descriptor's name or purpose
+// localized for international verifiers. In JSON, text without a language is keyed by @none.
+// https://www.w3.org/TR/json-ld11/#language-maps
+type LanguageMap map[string]string
+
+// Select returns the text in the language best matching the preferred languages, given in order of preference, and
+// whether any of its languages matched
+func (m LanguageMap) Select(preferred ...language.Tag) (string, bool) {
+	keys, tags := m.languages()
+	if len(tags) == 0 {
+		return "", false
+	}
+	_, index, confidence := language.NewMatcher(tags).Match(preferred...)
+	if confidence == language.No {
+		return "", false
+	}
+	return m[keys[index]], true
+}
+
+// languages returns the valid language tags of the map, in order, along with their keys
+func (m LanguageMap) languages() ([]string, []language.Tag) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	validKeys := make([]string, 0, len(keys))
+	tags := make([]language.Tag, 0, len(keys))
+	for _, key := range keys {
+		tag, err := language.Parse(key)
+		if err != nil {
+			continue
+		}
+		validKeys = append(validKeys, key)
+		tags = append(tags, tag)
+	}
+	return validKeys, tags
+}
+
+// localize returns the localized text in the language best matching the preferred languages, falling back to the
+// text without a language or, without any, to the localized text in the first of its languages
+func localize(text string, languages LanguageMap, preferred []language.Tag) string {
+	if localized, ok := languages.Select(preferred...); ok {
+		return localized
+	}
+	if text != "" {
+		return text
+	}
+	if keys, _ := languages.languages(); len(keys) > 0 {
+		return languages[keys[0]]
+	}
+	return ""
+}
+
+// localizedJSON returns the JSON value of text and its localizations: the text alone, or a language map including it
+// when localized
+func localizedJSON(text string, languages LanguageMap) any {
+	if len(languages) == 0 {
+		if text == "" {
+			return nil
+		}
+		return text
+	}
+	languageMap := make(LanguageMap, len(languages)+1)
+	for key, localized := range languages {
+		languageMap[key] = localized
+	}
+	if text != "" {
+		languageMap[noLanguage] = text
+	}
+	return languageMap
+}
+
+// unmarshalLocalized unmarshals either text or a language map, returning the text without a language and the
+// localizations
+func unmarshalLocalized(data json.RawMessage) (string, LanguageMap, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return "", nil, nil
+	}
+	if data[0] != '{' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return "", nil, err
+		}
+		return text, nil, nil
+	}
+	var languages LanguageMap
+	if err := json.Unmarshal(data, &languages); err != nil {
+		return "", nil, errors.Wrap(err, "unmarshalling language map")
+	}
+	text := languages[noLanguage]
+	delete(languages, noLanguage)
+	if len(languages) == 0 {
+		languages = nil
+	}
+	return text, languages, nil
+}
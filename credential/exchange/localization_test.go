@@ -0,0 +1,91 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestLanguageMap(t *testing.T) {
+	languages := LanguageMap{"en": "Proof of employment", "fr": "Preuve d'emploi", "not a language": "ignored"}
+
+	t.Run("selects the best matching language", func(tt *testing.T) {
+		text, ok := languages.Select(language.CanadianFrench)
+		assert.True(tt, ok)
+		assert.Equal(tt, "Preuve d'emploi", text)
+
+		text, ok = languages.Select(language.Japanese, language.BritishEnglish)
+		assert.True(tt, ok)
+		assert.Equal(tt, "Proof of employment", text)
+	})
+
+	t.Run("no matching language", func(tt *testing.T) {
+		_, ok := languages.Select(language.Japanese)
+		assert.False(tt, ok)
+
+		_, ok = LanguageMap{}.Select(language.English)
+		assert.False(tt, ok)
+	})
+}
+
+func TestLocalizedInputDescriptor(t *testing.T) {
+	descriptorJSON := []byte(`{
+		"id": "employment",
+		"name": {"@none": "Employment", "en": "Proof of employment", "fr": "Preuve d'emploi"},
+		"purpose": "We need to verify your employer",
+		"constraints": {"fields": [{"path": ["$.credentialSubject.company"]}]}
+	}`)
+
+	var descriptor InputDescriptor
+	require.NoError(t, json.Unmarshal(descriptorJSON, &descriptor))
+
+	t.Run("unmarshals language maps and strings", func(tt *testing.T) {
+		assert.Equal(tt, "Employment", descriptor.Name)
+		assert.Equal(tt, LanguageMap{"en": "Proof of employment", "fr": "Preuve d'emploi"}, descriptor.NameLanguages)
+		assert.Equal(tt, "We need to verify your employer", descriptor.Purpose)
+		assert.Empty(tt, descriptor.PurposeLanguages)
+		assert.Equal(tt, []string{"$.credentialSubject.company"}, descriptor.Constraints.Fields[0].Path)
+	})
+
+	t.Run("selects display strings by locale", func(tt *testing.T) {
+		assert.Equal(tt, "Preuve d'emploi", descriptor.LocalizedName(language.French))
+		assert.Equal(tt, "Employment", descriptor.LocalizedName(language.German))
+		assert.Equal(tt, "Employment", descriptor.LocalizedName())
+		assert.Equal(tt, "We need to verify your employer", descriptor.LocalizedPurpose(language.French))
+
+		unnamed := InputDescriptor{NameLanguages: LanguageMap{"fr": "Preuve d'emploi"}}
+		assert.Equal(tt, "Preuve d'emploi", unnamed.LocalizedName(language.German))
+	})
+
+	t.Run("round trips through JSON", func(tt *testing.T) {
+		marshalled, err := json.Marshal(descriptor)
+		require.NoError(tt, err)
+		assert.JSONEq(tt, string(descriptorJSON), string(marshalled))
+
+		var roundTripped InputDescriptor
+		require.NoError(tt, json.Unmarshal(marshalled, &roundTripped))
+		assert.Equal(tt, descriptor, roundTripped)
+	})
+
+	t.Run("localized definitions are valid", func(tt *testing.T) {
+		def, err := NewDefinition().
+			ID("test-id").
+			InputDescriptor(NewDescriptor("employment").
+				Name("Employment").
+				LocalizedName(LanguageMap{"fr": "Preuve d'emploi"}).
+				LocalizedPurpose(LanguageMap{"en": "Verify your employer", "fr": "Vérifier votre employeur"}).
+				Field(NewField("$.credentialSubject.company"))).
+			Build()
+		require.NoError(tt, err)
+		assert.NoError(tt, def.IsValid())
+		assert.Equal(tt, "Vérifier votre employeur", def.InputDescriptors[0].LocalizedPurpose(language.French))
+	})
+
+	t.Run("invalid name", func(tt *testing.T) {
+		err := json.Unmarshal([]byte(`{"id": "employment", "name": 1}`), &InputDescriptor{})
+		assert.ErrorContains(tt, err, "unmarshalling input descriptor name")
+	})
+}
@@ -4,10 +4,13 @@ import (
 	"reflect"
 
 	"github.com/goccy/go-json"
+	"github.com/oliveagle/jsonpath"
 	"github.com/pkg/errors"
+	"golang.org/x/text/language"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/schema"
 	"github.com/TBD54566975/ssi-sdk/util"
 )
 
@@ -17,6 +20,7 @@ type (
 	CredentialFormat string
 	JWTFormat        CredentialFormat
 	LinkedDataFormat CredentialFormat
+	SDJWTFormat      CredentialFormat
 )
 
 const (
@@ -28,6 +32,10 @@ const (
 	LDPVC LinkedDataFormat = "ldp_vc"
 	LDPVP LinkedDataFormat = "ldp_vp"
 
+	// SDJWTVC is a credential secured with SD-JWT, submitted directly rather than in a presentation
+	// https://identity.foundation/claim-format-registry/#registry
+	SDJWTVC SDJWTFormat = "vc+sd-jwt"
+
 	All  Selection = "all"
 	Pick Selection = "pick"
 
@@ -63,6 +71,18 @@ func (f JWTFormat) CredentialFormat() CredentialFormat {
 	return CredentialFormat(f)
 }
 
+func (f SDJWTFormat) Ptr() *SDJWTFormat {
+	return &f
+}
+
+func (f SDJWTFormat) String() string {
+	return string(f)
+}
+
+func (f SDJWTFormat) CredentialFormat() CredentialFormat {
+	return CredentialFormat(f)
+}
+
 type PresentationDefinitionEnvelope struct {
 	PresentationDefinition `json:"presentation_definition"`
 }
@@ -137,10 +157,12 @@ type ClaimFormat struct {
 	LDP   *LDPType `json:"ldp,omitempty" validate:"omitempty"`
 	LDPVC *LDPType `json:"ldp_vc,omitempty" validate:"omitempty"`
 	LDPVP *LDPType `json:"ldp_vp,omitempty" validate:"omitempty"`
+
+	SDJWTVC *SDJWTType `json:"vc+sd-jwt,omitempty" validate:"omitempty"`
 }
 
 func SupportedClaimFormats() []CredentialFormat {
-	return []CredentialFormat{JWT.CredentialFormat(), JWTVC.CredentialFormat(), JWTVP.CredentialFormat(), LDP.CredentialFormat(), LDPVC.CredentialFormat(), JWTVC.CredentialFormat()}
+	return []CredentialFormat{JWT.CredentialFormat(), JWTVC.CredentialFormat(), JWTVP.CredentialFormat(), LDP.CredentialFormat(), LDPVC.CredentialFormat(), JWTVC.CredentialFormat(), SDJWTVC.CredentialFormat()}
 }
 
 func (cf *ClaimFormat) IsEmpty() bool {
@@ -182,6 +204,9 @@ func (cf *ClaimFormat) FormatValues() []string {
 	if cf.LDPVP != nil {
 		res = append(res, LDPVP.String())
 	}
+	if cf.SDJWTVC != nil {
+		res = append(res, SDJWTVC.String())
+	}
 	return res
 }
 
@@ -213,6 +238,10 @@ func (cf *ClaimFormat) AlgOrProofTypePerFormat() []string {
 		for _, pt := range cf.LDPVP.ProofType {
 			res = append(res, string(pt))
 		}
+	} else if cf.SDJWTVC != nil {
+		for _, a := range cf.SDJWTVC.SDJWTAlg {
+			res = append(res, string(a))
+		}
 	}
 	return res
 }
@@ -225,18 +254,83 @@ type LDPType struct {
 	ProofType []cryptosuite.SignatureType `json:"proof_type" validate:"required"`
 }
 
+// SDJWTType is the algorithms accepted for a credential secured with SD-JWT, and for its key binding JWT
+type SDJWTType struct {
+	SDJWTAlg []crypto.SignatureAlgorithm `json:"sd-jwt_alg_values,omitempty"`
+	KBJWTAlg []crypto.SignatureAlgorithm `json:"kb-jwt_alg_values,omitempty"`
+}
+
 type InputDescriptor struct {
 	// Must be unique within the Presentation Definition
 	ID   string `json:"id" validate:"required"`
 	Name string `json:"name,omitempty"`
+	// NameLanguages localizes the name; in JSON, a localized name is a language map
+	NameLanguages LanguageMap `json:"-"`
 	// Purpose for which claim's data is being requested
-	Purpose     string       `json:"purpose,omitempty"`
-	Format      *ClaimFormat `json:"format,omitempty" validate:"omitempty"`
-	Constraints *Constraints `json:"constraints" validate:"required"`
+	Purpose string `json:"purpose,omitempty"`
+	// PurposeLanguages localizes the purpose; in JSON, a localized purpose is a language map
+	PurposeLanguages LanguageMap  `json:"-"`
+	Format           *ClaimFormat `json:"format,omitempty" validate:"omitempty"`
+	Constraints      *Constraints `json:"constraints" validate:"required"`
 	// Must match a grouping strings listed in the `from` values of a submission requirement rule
 	Group []string `json:"group,omitempty"`
 }
 
+// inputDescriptorJSON is the JSON form of an input descriptor, whose name and purpose are either strings or, when
+// localized, language maps
+type inputDescriptorJSON struct {
+	inputDescriptorAlias
+	Name    any `json:"name,omitempty"`
+	Purpose any `json:"purpose,omitempty"`
+}
+
+type inputDescriptorAlias InputDescriptor
+
+func (id InputDescriptor) MarshalJSON() ([]byte, error) {
+	if len(id.NameLanguages) == 0 && len(id.PurposeLanguages) == 0 {
+		return json.Marshal(inputDescriptorAlias(id))
+	}
+	return json.Marshal(inputDescriptorJSON{
+		inputDescriptorAlias: inputDescriptorAlias(id),
+		Name:                 localizedJSON(id.Name, id.NameLanguages),
+		Purpose:              localizedJSON(id.Purpose, id.PurposeLanguages),
+	})
+}
+
+func (id *InputDescriptor) UnmarshalJSON(data []byte) error {
+	var idJSON struct {
+		inputDescriptorAlias
+		Name    json.RawMessage `json:"name"`
+		Purpose json.RawMessage `json:"purpose"`
+	}
+	// the alias unmarshals every property but name and purpose, which may be strings or language maps
+	if err := json.Unmarshal(data, &idJSON); err != nil {
+		return errors.Wrap(err, "unmarshalling input descriptor")
+	}
+	unmarshalled := InputDescriptor(idJSON.inputDescriptorAlias)
+	var err error
+	if unmarshalled.Name, unmarshalled.NameLanguages, err = unmarshalLocalized(idJSON.Name); err != nil {
+		return errors.Wrap(err, "unmarshalling input descriptor name")
+	}
+	if unmarshalled.Purpose, unmarshalled.PurposeLanguages, err = unmarshalLocalized(idJSON.Purpose); err != nil {
+		return errors.Wrap(err, "unmarshalling input descriptor purpose")
+	}
+	*id = unmarshalled
+	return nil
+}
+
+// LocalizedName returns the input descriptor's name in the language best matching the preferred languages, given in
+// order of preference, for display on consent screens. Without a match, it falls back to the name without a language.
+func (id InputDescriptor) LocalizedName(preferred ...language.Tag) string {
+	return localize(id.Name, id.NameLanguages, preferred)
+}
+
+// LocalizedPurpose returns the input descriptor's purpose in the language best matching the preferred languages, as
+// LocalizedName does for its name
+func (id InputDescriptor) LocalizedPurpose(preferred ...language.Tag) string {
+	return localize(id.Purpose, id.PurposeLanguages, preferred)
+}
+
 func (id *InputDescriptor) IsEmpty() bool {
 	if id == nil {
 		return true
@@ -283,6 +377,40 @@ type Field struct {
 	Filter    *Filter     `json:"filter,omitempty"`
 }
 
+// Match returns the first of the field's paths which resolves to data in the claim passing the field's filter,
+// along with that data. An error is returned if no path does; if data was found which did not pass the filter, the
+// data of the first such path is returned with the error.
+// https://identity.foundation/presentation-exchange/#input-evaluation
+func (f Field) Match(claimData map[string]any) (string, any, error) {
+	var filterErr error
+	var firstData any
+	for _, path := range f.Path {
+		pathedData, err := jsonpath.JsonPathLookup(claimData, path)
+		if err != nil {
+			continue
+		}
+		if f.Filter == nil {
+			return path, pathedData, nil
+		}
+		filterJSON, err := f.Filter.ToJSON()
+		if err != nil {
+			return "", nil, errors.Wrap(err, "turning filter into JSON schema")
+		}
+		if err = schema.IsAnyValidAgainstJSONSchema(pathedData, filterJSON); err != nil {
+			if filterErr == nil {
+				firstData = pathedData
+				filterErr = errors.Wrapf(err, "unable to apply filter<%s> to data from path: %s", filterJSON, path)
+			}
+			continue
+		}
+		return path, pathedData, nil
+	}
+	if filterErr != nil {
+		return "", firstData, filterErr
+	}
+	return "", nil, errors.New("matching path for claim could not be found")
+}
+
 type RelationalConstraint struct {
 	FieldID   []string    `json:"field_id" validate:"required"`
 	Directive *Preference `json:"directive" validate:"required"`
@@ -306,6 +434,11 @@ type Filter struct {
 	Not                  any      `json:"not,omitempty"`
 	AllOf                any      `json:"allOf,omitempty"`
 	OneOf                any      `json:"oneOf,omitempty"`
+	AnyOf                any      `json:"anyOf,omitempty"`
+	Items                any      `json:"items,omitempty"`
+	Contains             any      `json:"contains,omitempty"`
+	MinItems             int      `json:"minItems,omitempty"`
+	MaxItems             int      `json:"maxItems,omitempty"`
 }
 
 func (f Filter) ToJSON() (string, error) {
@@ -318,17 +451,16 @@ func (f Filter) ToJSON() (string, error) {
 
 // CredentialStatus https://identity.foundation/presentation-exchange/#credential-status-constraint-feature
 type CredentialStatus struct {
-	Active *struct {
-		Directive Preference `json:"directive,omitempty"`
-	} `json:"active,omitempty"`
-
-	Suspended *struct {
-		Directive Preference `json:"directive,omitempty"`
-	} `json:"suspended,omitempty"`
+	Active    *StatusDirective `json:"active,omitempty"`
+	Suspended *StatusDirective `json:"suspended,omitempty"`
+	Revoked   *StatusDirective `json:"revoked,omitempty"`
+}
 
-	Revoked *struct {
-		Directive Preference `json:"directive,omitempty"`
-	} `json:"revoked,omitempty"`
+// StatusDirective is whether a credential is required, allowed, or disallowed to have a status, optionally limited to
+// the credentialStatus types able to express it
+type StatusDirective struct {
+	Directive Preference `json:"directive,omitempty"`
+	Type      []string   `json:"type,omitempty"`
 }
 
 // SubmissionRequirement https://identity.foundation/presentation-exchange/#presentation-definition-extensions
@@ -179,6 +179,47 @@ func TestPresentationSubmission(t *testing.T) {
 	})
 }
 
+func TestFieldMatch(t *testing.T) {
+	claim := map[string]any{
+		"issuer": "did:example:issuer",
+		"credentialSubject": map[string]any{
+			"age":    float64(21),
+			"skills": []any{"go", "rust"},
+		},
+	}
+
+	t.Run("first path passing the filter", func(tt *testing.T) {
+		field := Field{
+			Path:   []string{"$.credentialSubject.name", "$.issuer", "$.credentialSubject.age"},
+			Filter: &Filter{Type: "number", Minimum: 18},
+		}
+		path, data, err := field.Match(claim)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "$.credentialSubject.age", path)
+		assert.Equal(tt, float64(21), data)
+
+		field.Filter = &Filter{Type: "array", Contains: map[string]any{"const": "rust"}, MinItems: 2}
+		field.Path = []string{"$.credentialSubject.skills"}
+		_, _, err = field.Match(claim)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("no path passing the filter", func(tt *testing.T) {
+		field := Field{
+			Path:   []string{"$.credentialSubject.age"},
+			Filter: &Filter{Type: "number", Minimum: 65},
+		}
+		_, data, err := field.Match(claim)
+		assert.ErrorContains(tt, err, "unable to apply filter")
+		assert.Equal(tt, float64(21), data)
+
+		field.Path = []string{"$.credentialSubject.name"}
+		_, data, err = field.Match(claim)
+		assert.ErrorContains(tt, err, "matching path for claim could not be found")
+		assert.Nil(tt, data)
+	})
+}
+
 func getTestVector(fileName string) (string, error) {
 	b, err := testVectors.ReadFile("testdata/" + fileName)
 	return string(b), err
@@ -0,0 +1,140 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// SubmittedPresentation is one of multiple presentations submitted together as an array, such as the presentations
+// of an OpenID4VP vp_token, which may each be in a different format
+type SubmittedPresentation struct {
+	// Format is the format of the presentation, such as jwt_vp or ldp_vp, or of a credential submitted on its own,
+	// such as vc+sd-jwt
+	Format string
+	// DescriptorMap maps the input descriptors the presentation fulfills to its claims, with paths relative to the
+	// presentation, as in the presentation submission embedded by BuildPresentationSubmissionVP. A credential
+	// submitted on its own fulfills input descriptors with the path $.
+	DescriptorMap []SubmissionDescriptor
+}
+
+// CombinePresentationSubmissions builds the presentation submission of multiple presentations submitted together as
+// an array, each fulfilling some of a presentation definition's input descriptors. Submission descriptors of the
+// presentation at index i have the path $[i], and nest the presentation's own submission descriptor, which selects
+// the claim from the presentation; for a JWT presentation, the nested path selects it from the JWT's vp claim.
+// https://identity.foundation/presentation-exchange/#processing-of-submission-entries
+func CombinePresentationSubmissions(def PresentationDefinition, presentations []SubmittedPresentation) (*PresentationSubmission, error) {
+	if def.IsEmpty() {
+		return nil, errors.New("presentation definition cannot be empty")
+	}
+	if len(presentations) == 0 {
+		return nil, errors.New("presentations cannot be empty")
+	}
+	inputDescriptorIDs := make(map[string]bool, len(def.InputDescriptors))
+	for _, id := range def.InputDescriptors {
+		inputDescriptorIDs[id.ID] = true
+	}
+
+	submission := PresentationSubmission{
+		ID:           uuid.NewString(),
+		DefinitionID: def.ID,
+	}
+	fulfilled := make(map[string]int)
+	for i, presentation := range presentations {
+		if presentation.Format == "" {
+			return nil, fmt.Errorf("presentation<%d> has no format", i)
+		}
+		outerPath := fmt.Sprintf("$[%d]", i)
+		for _, d := range presentation.DescriptorMap {
+			if !inputDescriptorIDs[d.ID] {
+				return nil, fmt.Errorf("presentation<%d> fulfills unknown input descriptor<%s>", i, d.ID)
+			}
+			if previous, ok := fulfilled[d.ID]; ok {
+				return nil, fmt.Errorf("input descriptor<%s> is fulfilled by both presentation<%d> and presentation<%d>", d.ID, previous, i)
+			}
+			fulfilled[d.ID] = i
+
+			// a credential submitted on its own is the claim itself
+			if d.Path == "$" && d.PathNested == nil && d.Format == presentation.Format {
+				submission.DescriptorMap = append(submission.DescriptorMap, SubmissionDescriptor{
+					ID:     d.ID,
+					Format: presentation.Format,
+					Path:   outerPath,
+				})
+				continue
+			}
+			nested := d
+			if presentation.Format == JWTVP.String() {
+				nested.Path = "$." + integrity.VPJWTProperty + strings.TrimPrefix(d.Path, "$")
+			}
+			submission.DescriptorMap = append(submission.DescriptorMap, SubmissionDescriptor{
+				ID:         d.ID,
+				Format:     presentation.Format,
+				Path:       outerPath,
+				PathNested: &nested,
+			})
+		}
+	}
+	if err := submission.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "combined presentation submission is not valid")
+	}
+	return &submission, nil
+}
+
+// VerifyMultiPresentationSubmission verifies whether multiple presentations submitted together as an array, with a
+// presentation submission such as one built by CombinePresentationSubmissions, are a valid presentation submission
+// for a given presentation definition. Each presentation may be a JWT, a JSON object, or a credential submitted on its
+// own, such as a vc+sd-jwt. No signature verification happens here. Input descriptors with statuses constraints
+// require a status check option, such as WithStatusCheck.
+func VerifyMultiPresentationSubmission(def PresentationDefinition, submission PresentationSubmission, presentations []any, opts ...VerificationOption) ([]VerifiedSubmissionData, error) {
+	evaluator, err := newMultiPresentationEvaluator(def, submission, presentations, opts...)
+	if err != nil {
+		return nil, err
+	}
+	verifiedSubmissionData := make([]VerifiedSubmissionData, 0)
+	for _, inputDescriptor := range def.InputDescriptors {
+		_, verifiedSubmissionDatum, err := evaluator.evaluate(context.Background(), inputDescriptor)
+		if err != nil {
+			return nil, err
+		}
+		if verifiedSubmissionDatum != nil {
+			verifiedSubmissionData = append(verifiedSubmissionData, *verifiedSubmissionDatum)
+		}
+	}
+	return verifiedSubmissionData, nil
+}
+
+// EvaluateMultiPresentationSubmission evaluates multiple presentations submitted together as an array, as
+// VerifyMultiPresentationSubmission does, and reports the result for each input descriptor
+func EvaluateMultiPresentationSubmission(def PresentationDefinition, submission PresentationSubmission, presentations []any, opts ...VerificationOption) (*EvaluationReport, error) {
+	evaluator, err := newMultiPresentationEvaluator(def, submission, presentations, opts...)
+	if err != nil {
+		return nil, err
+	}
+	report := EvaluationReport{DefinitionID: def.ID}
+	for _, inputDescriptor := range def.InputDescriptors {
+		evaluation, _, _ := evaluator.evaluate(context.Background(), inputDescriptor)
+		report.InputDescriptors = append(report.InputDescriptors, evaluation)
+	}
+	return &report, nil
+}
+
+func newMultiPresentationEvaluator(def PresentationDefinition, submission PresentationSubmission, presentations []any, opts ...VerificationOption) (*submissionEvaluator, error) {
+	if err := canProcessDefinition(def); err != nil {
+		return nil, errors.Wrap(err, "not able to verify submission; feature not supported")
+	}
+	if len(presentations) == 0 {
+		return nil, errors.New("presentations cannot be empty")
+	}
+	submitted, err := util.AnyToJSONInterface(presentations)
+	if err != nil {
+		return nil, errors.Wrap(err, "turning presentations into JSON representation")
+	}
+	return newSubmissionEvaluatorFor(def, submission, submitted, opts...)
+}
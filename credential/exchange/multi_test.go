@@ -0,0 +1,159 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+)
+
+func TestMultiPresentationSubmission(t *testing.T) {
+	def := PresentationDefinition{
+		ID: "test-id",
+		InputDescriptors: []InputDescriptor{
+			{
+				ID: "company",
+				Constraints: &Constraints{
+					Fields: []Field{{Path: []string{"$.credentialSubject.company"}}},
+				},
+			},
+			{
+				ID: "jwt-issuer",
+				Constraints: &Constraints{
+					Fields: []Field{{Path: []string{"$.vc.credentialSubject.company"}}},
+				},
+			},
+			{
+				ID:     "sd-jwt-name",
+				Format: &ClaimFormat{SDJWTVC: &SDJWTType{}},
+				Constraints: &Constraints{
+					Fields: []Field{
+						{
+							Path:   []string{"$.credentialSubject.name"},
+							Filter: &Filter{Type: "string", Const: "Alice"},
+						},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, def.IsValid())
+	signer, _ := getJWKSignerVerifier(t)
+
+	// an LD presentation fulfilling the first input descriptor
+	testVC := getTestVerifiableCredential("test-issuer", "test-subject")
+	normalized, err := NormalizePresentationClaims([]PresentationClaim{{
+		Credential:                    &testVC,
+		LDPFormat:                     LDPVC.Ptr(),
+		SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
+	}})
+	require.NoError(t, err)
+	ldpVP, err := BuildPresentationSubmissionVP("holder", PresentationDefinition{
+		ID:               def.ID,
+		InputDescriptors: def.InputDescriptors[:1],
+	}, normalized)
+	require.NoError(t, err)
+	ldpSubmission, ok := ldpVP.PresentationSubmission.(PresentationSubmission)
+	require.True(t, ok)
+
+	// a JWT presentation fulfilling the second
+	jwtVC, err := integrity.SignVerifiableCredentialJWT(*signer, getTestVerifiableCredential(signer.ID, "test-subject"))
+	require.NoError(t, err)
+	jwtVP, err := integrity.SignVerifiablePresentationJWT(*signer, nil, credential.VerifiablePresentation{
+		Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+		Type:                 []string{"VerifiablePresentation"},
+		Holder:               signer.ID,
+		VerifiableCredential: []any{string(jwtVC)},
+	})
+	require.NoError(t, err)
+
+	// and an SD-JWT credential submitted on its own fulfilling the third
+	sdJWT := getTestSDJWTCredential(t, *signer, "Alice")
+
+	presentations := []SubmittedPresentation{
+		{Format: LDPVP.String(), DescriptorMap: ldpSubmission.DescriptorMap},
+		{
+			Format:        JWTVP.String(),
+			DescriptorMap: []SubmissionDescriptor{{ID: "jwt-issuer", Format: JWTVC.String(), Path: "$.verifiableCredential[0]"}},
+		},
+		{
+			Format:        SDJWTVC.String(),
+			DescriptorMap: []SubmissionDescriptor{{ID: "sd-jwt-name", Format: SDJWTVC.String(), Path: "$"}},
+		},
+	}
+	submission, err := CombinePresentationSubmissions(def, presentations)
+	require.NoError(t, err)
+	submitted := []any{*ldpVP, string(jwtVP), sdJWT}
+
+	t.Run("combines outer and nested paths", func(tt *testing.T) {
+		require.Len(tt, submission.DescriptorMap, 3)
+		assert.Equal(tt, SubmissionDescriptor{
+			ID:     "company",
+			Format: LDPVP.String(),
+			Path:   "$[0]",
+			PathNested: &SubmissionDescriptor{
+				ID:     "company",
+				Format: LDPVC.String(),
+				Path:   "$.verifiableCredential[0]",
+			},
+		}, submission.DescriptorMap[0])
+		assert.Equal(tt, "$[1]", submission.DescriptorMap[1].Path)
+		assert.Equal(tt, "$.vp.verifiableCredential[0]", submission.DescriptorMap[1].PathNested.Path)
+		assert.Equal(tt, SubmissionDescriptor{ID: "sd-jwt-name", Format: SDJWTVC.String(), Path: "$[2]"}, submission.DescriptorMap[2])
+	})
+
+	t.Run("verifies each presentation", func(tt *testing.T) {
+		verified, err := VerifyMultiPresentationSubmission(def, *submission, submitted)
+		require.NoError(tt, err)
+		require.Len(tt, verified, 3)
+		assert.Equal(tt, "Block", verified[0].FilteredData)
+		assert.Equal(tt, "Block", verified[1].FilteredData)
+		assert.Equal(tt, "Alice", verified[2].FilteredData)
+	})
+
+	t.Run("reports unfulfilled input descriptors", func(tt *testing.T) {
+		report, err := EvaluateMultiPresentationSubmission(def, *submission, []any{*ldpVP, string(jwtVP), getTestSDJWTCredential(tt, *signer, "Bob")})
+		require.NoError(tt, err)
+		assert.True(tt, report.InputDescriptors[0].Fulfilled)
+		assert.True(tt, report.InputDescriptors[1].Fulfilled)
+		assert.False(tt, report.InputDescriptors[2].Fulfilled)
+		assert.Contains(tt, report.InputDescriptors[2].Reason, "unable to apply filter")
+	})
+
+	t.Run("mismatched nested submission descriptor", func(tt *testing.T) {
+		wrong := *submission
+		wrong.DescriptorMap = append([]SubmissionDescriptor{}, submission.DescriptorMap...)
+		wrong.DescriptorMap[2] = SubmissionDescriptor{ID: "sd-jwt-name", Format: JWTVP.String(), Path: "$[1]", PathNested: submission.DescriptorMap[1].PathNested}
+		_, err := VerifyMultiPresentationSubmission(def, wrong, submitted)
+		assert.ErrorContains(tt, err, "nested submission descriptor<jwt-issuer> does not match submission descriptor<sd-jwt-name>")
+	})
+
+	t.Run("input descriptor fulfilled twice", func(tt *testing.T) {
+		_, err := CombinePresentationSubmissions(def, append(presentations, presentations[2]))
+		assert.ErrorContains(tt, err, "input descriptor<sd-jwt-name> is fulfilled by both presentation<2> and presentation<3>")
+	})
+}
+
+// getTestSDJWTCredential secures a credential about the named subject as a vc+sd-jwt, without disclosures
+func getTestSDJWTCredential(t *testing.T, signer jwx.Signer, name string) string {
+	payload, err := json.Marshal(map[string]any{
+		"@context":          []any{credential.VerifiableCredentialsV2LinkedDataContext},
+		"type":              []any{credential.VerifiableCredentialType},
+		"issuer":            signer.ID,
+		"credentialSubject": map[string]any{"id": "test-subject", "name": name},
+	})
+	require.NoError(t, err)
+	hdrs := jws.NewHeaders()
+	require.NoError(t, hdrs.Set(jws.TypeKey, integrity.VCSDJWTType))
+	signed, err := jws.Sign(payload, jws.WithKey(jwa.EdDSA, signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	require.NoError(t, err)
+	return string(signed) + "~"
+}
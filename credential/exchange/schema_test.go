@@ -30,6 +30,12 @@ func TestPresentationExchangeSchemas(t *testing.T) {
 	err = schema.IsValidJSONSchema(pdSchema)
 	assert.NoError(t, err)
 
+	pdV1Schema, err := schema.LoadSchema(schema.PresentationDefinitionV1Schema)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pdV1Schema)
+	err = schema.IsValidJSONSchema(pdV1Schema)
+	assert.NoError(t, err)
+
 	fdSchema, err := schema.LoadSchema(schema.PresentationClaimFormatDesignationsSchema)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, fdSchema)
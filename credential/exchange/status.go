@@ -0,0 +1,157 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+type (
+	VerificationOptionKey string
+)
+
+const (
+	// StatusReporterOption is the key of the option providing a StatusReporter
+	StatusReporterOption VerificationOptionKey = "status-reporter"
+)
+
+// VerificationOption configures the verification of a presentation submission
+type VerificationOption struct {
+	ID     VerificationOptionKey
+	Option any
+}
+
+// StatusReporter reports the status of a credential, for checking the statuses constraints of input descriptors
+type StatusReporter func(ctx context.Context, cred credential.VerifiableCredential) (*status.StatusReport, error)
+
+// WithStatusCheck checks the statuses constraints of input descriptors using status.GetStatusReport, fetching the
+// status list credentials of submitted credentials with the given access
+func WithStatusCheck(access status.StatusListAccess, r resolution.Resolver) VerificationOption {
+	return WithStatusReporter(func(ctx context.Context, cred credential.VerifiableCredential) (*status.StatusReport, error) {
+		return status.GetStatusReport(ctx, cred, access, r)
+	})
+}
+
+// WithStatusReporter checks the statuses constraints of input descriptors with the given reporter
+func WithStatusReporter(reporter StatusReporter) VerificationOption {
+	return VerificationOption{
+		ID:     StatusReporterOption,
+		Option: reporter,
+	}
+}
+
+// getStatusReporter returns the status reporter of the options, if one was provided
+func getStatusReporter(opts []VerificationOption) (StatusReporter, error) {
+	for _, opt := range opts {
+		if opt.ID != StatusReporterOption {
+			continue
+		}
+		reporter, ok := opt.Option.(StatusReporter)
+		if !ok || reporter == nil {
+			return nil, fmt.Errorf("invalid %s option type: %T", StatusReporterOption, opt.Option)
+		}
+		return reporter, nil
+	}
+	return nil, nil
+}
+
+// isValidStatusConstraint checks each directive of a statuses constraint is required, allowed, or disallowed
+func isValidStatusConstraint(statuses CredentialStatus) error {
+	names := []string{"active", "suspended", "revoked"}
+	for i, directive := range []*StatusDirective{statuses.Active, statuses.Suspended, statuses.Revoked} {
+		if directive == nil {
+			continue
+		}
+		switch directive.Directive {
+		case Required, Allowed, Disallowed:
+		default:
+			return fmt.Errorf("unsupported %s status directive<%s>; must be %s, %s, or %s", names[i], directive.Directive, Required, Allowed, Disallowed)
+		}
+	}
+	return nil
+}
+
+// checkStatusConstraint checks a credential's status complies with each directive of a statuses constraint. A
+// required directive must hold, and a disallowed directive must not; when a directive lists credentialStatus types,
+// the credential's status must be of one of them. Credentials whose status cannot be determined do not comply.
+// https://identity.foundation/presentation-exchange/#credential-status-constraint-feature
+func checkStatusConstraint(ctx context.Context, statuses CredentialStatus, cred credential.VerifiableCredential, reporter StatusReporter) error {
+	report, err := reporter(ctx, cred)
+	if err != nil {
+		return errors.Wrap(err, "getting credential status")
+	}
+	if report.Outcome == status.StatusOutcomeUnknown {
+		return errors.Wrap(report.Err(), "credential status could not be determined")
+	}
+	directives := []struct {
+		name      string
+		directive *StatusDirective
+		holds     bool
+	}{
+		{"active", statuses.Active, report.Outcome == status.StatusOutcomeActive},
+		{"suspended", statuses.Suspended, isStatusSet(*report, status.StatusSuspension)},
+		{"revoked", statuses.Revoked, isStatusSet(*report, status.StatusRevocation)},
+	}
+	statusTypes := credentialStatusTypes(cred)
+	for _, d := range directives {
+		if d.directive == nil || d.directive.Directive == Allowed {
+			continue
+		}
+		if len(d.directive.Type) > 0 && !anyContained(statusTypes, d.directive.Type) {
+			return fmt.Errorf("credential status types<%v> are not among the %s status types<%v>", statusTypes, d.name, d.directive.Type)
+		}
+		if d.directive.Directive == Required && !d.holds {
+			return fmt.Errorf("credential must be %s", d.name)
+		}
+		if d.directive.Directive == Disallowed && d.holds {
+			return fmt.Errorf("credential cannot be %s", d.name)
+		}
+	}
+	return nil
+}
+
+func isStatusSet(report status.StatusReport, purpose status.StatusPurpose) bool {
+	for _, s := range report.Statuses {
+		if s.Purpose == purpose && s.IsSet() {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialStatusTypes returns the types of a credential's credentialStatus entries
+func credentialStatusTypes(cred credential.VerifiableCredential) []string {
+	if cred.CredentialStatus == nil {
+		return nil
+	}
+	entries, ok := cred.CredentialStatus.([]any)
+	if !ok {
+		entries = []any{cred.CredentialStatus}
+	}
+	var types []string
+	for _, entry := range entries {
+		entryJSON, err := util.ToJSONMap(entry)
+		if err != nil {
+			continue
+		}
+		if statusType, ok := entryJSON["type"].(string); ok {
+			types = append(types, statusType)
+		}
+	}
+	return types
+}
+
+func anyContained(values, in []string) bool {
+	for _, v := range values {
+		if util.Contains(v, in) {
+			return true
+		}
+	}
+	return false
+}
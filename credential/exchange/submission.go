@@ -13,7 +13,6 @@ import (
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/v2/jwt"
-	"github.com/oliveagle/jsonpath"
 	"github.com/pkg/errors"
 )
 
@@ -149,7 +148,7 @@ func BuildPresentationSubmission(signer any, requester string, def PresentationD
 	if !IsSupportedEmbedTarget(et) {
 		return nil, fmt.Errorf("unsupported presentation submission embed target type: %s", et)
 	}
-	normalizedClaims, err := normalizePresentationClaims(claims)
+	normalizedClaims, err := NormalizePresentationClaims(claims)
 	if err != nil {
 		return nil, errors.Wrap(err, "normalizing some presentation claims")
 	}
@@ -186,10 +185,10 @@ type NormalizedClaim struct {
 	AlgOrProofType string
 }
 
-// normalizePresentationClaims takes a set of Presentation Claims and turns them into map[string]any as
-// go-JSON representations. The claim format and signature algorithm type are noted as well.
-// This method is greedy, meaning it returns the set of claims it was able to normalize.
-func normalizePresentationClaims(claims []PresentationClaim) ([]NormalizedClaim, error) {
+// NormalizePresentationClaims takes a set of Presentation Claims and turns them into map[string]any as
+// go-JSON representations, for BuildPresentationSubmissionVP. The claim format and signature algorithm type are noted
+// as well. This method is greedy, meaning it returns the set of claims it was able to normalize.
+func NormalizePresentationClaims(claims []PresentationClaim) ([]NormalizedClaim, error) {
 	var normalizedClaims []NormalizedClaim
 	errs := util.NewAppendError()
 	for _, claim := range claims {
@@ -237,6 +236,10 @@ func BuildPresentationSubmissionVP(submitter string, def PresentationDefinition,
 	if err := canProcessDefinition(def); err != nil {
 		return nil, errors.Wrap(err, "feature not supported in processing given presentation definition")
 	}
+	if def.Frame != nil {
+		// framing requires deriving credentials, see BuildDerivedPresentationSubmissionVP
+		return nil, errors.New("JSON-LD framing requires derived credentials, which are not supported")
+	}
 	builder := credential.NewVerifiablePresentationBuilder()
 	if err := builder.AddContext(PresentationSubmissionContext); err != nil {
 		return nil, err
@@ -329,86 +332,49 @@ type limitedInputDescriptor struct {
 	Data any
 }
 
-// processInputDescriptor runs the input evaluation algorithm described in the spec for a specific input descriptor
+// processInputDescriptor runs the input evaluation algorithm described in the spec for a specific input descriptor,
+// returning the claim which fulfills it with the highest score, preferring those matching optional fields. Why it
+// could not be fulfilled is detailed by evaluateInputDescriptor.
 // https://identity.foundation/presentation-exchange/#input-evaluation
 func processInputDescriptor(id InputDescriptor, claims []NormalizedClaim) (*processedInputDescriptor, error) {
-	constraints := id.Constraints
-	if constraints == nil {
-		return nil, fmt.Errorf("unable to process input descriptor without constraints")
-	}
-	fields := constraints.Fields
-	if len(fields) == 0 {
-		return nil, fmt.Errorf("unable to process input descriptor without fields: %s", id.ID)
-	}
-
-	// bookkeeping to check whether we've fulfilled all required fields, and whether we need to limit disclosure
-	fieldsToProcess := len(fields)
-	disclosure := constraints.LimitDisclosure
-	if disclosure != nil && *disclosure == Required {
-		// TODO(gabe) enable limiting disclosure for ZKP/SD creds https://github.com/TBD54566975/ssi-sdk/issues/354
-		// otherwise, we won't be able to send back a claim with a signature attached
-		return nil, errors.New("requiring limit disclosure is not supported")
-	}
-
-	// first, reduce the set of claims that conform with the format required by the input descriptor
-	filteredClaims := filterClaimsByFormat(claims, id.Format)
-	if len(filteredClaims) == 0 {
-		return nil, fmt.Errorf("no claims match the required format, and jwt alg/proof type requirements "+
-			"for input descriptor: %s", id.ID)
-	}
-
-	// for the input descriptor to be successfully processed each field needs to yield a result for a given claim,
-	// so we need to iterate through each claim, and test it against each field, and each path within each field.
-	// if we find a match for each field, we know a claim can fulfill the given input descriptor.
-	for _, claim := range filteredClaims {
-		fieldsProcessed := 0
-		claimValue := claim.Data
-		for _, field := range fields {
-			// apply the field to the claim, and return the processed value, which we only care about for
-			// filtering and/or limit_disclosure settings
-			if _, fulfilled := processInputDescriptorField(field, claimValue); !fulfilled {
-				// we know this claim is not sufficient to fulfill the input descriptor
-				break
-			}
-			// we've fulfilled the field, so note it
-			fieldsProcessed++
-		}
-
-		// if a claim has matched all fields, we can fulfill the input descriptor with this claim
-		if fieldsProcessed == fieldsToProcess {
-			return &processedInputDescriptor{
-				ID:      id.ID,
-				ClaimID: claim.ID,
-				Claim:   claim.RawClaim,
-				Format:  claim.Format,
-			}, nil
-		}
-	}
-	return nil, fmt.Errorf("no claims could fulfill the input descriptor: %s", id.ID)
+	evaluation, claim := evaluateInputDescriptor(id, claims)
+	if claim == nil {
+		return nil, evaluation.Err()
+	}
+	return &processedInputDescriptor{
+		ID:      id.ID,
+		ClaimID: claim.ID,
+		Claim:   claim.RawClaim,
+		Format:  claim.Format,
+	}, nil
 }
 
 // filterClaimsByFormat returns a set of claims that comply with a given ClaimFormat according to its
 // supported format(s) and signature types per format
 func filterClaimsByFormat(claims []NormalizedClaim, format *ClaimFormat) []NormalizedClaim {
-	// no format, which is an optional property
-	if format == nil {
-		return claims
-	}
-	formatValues := format.FormatValues()
 	var filteredClaims []NormalizedClaim
 	for _, claim := range claims {
-		// if the format matches, check the alg type
-		if util.Contains(claim.Format, formatValues) {
-			// get the supported alg or proof types for this format
-			algOrProofTypes := format.AlgOrProofTypePerFormat()
-			if util.Contains(claim.AlgOrProofType, algOrProofTypes) {
-				filteredClaims = append(filteredClaims, claim)
-			}
+		if isClaimFormatAccepted(claim, format) {
+			filteredClaims = append(filteredClaims, claim)
 		}
 	}
 	return filteredClaims
 }
 
+// isClaimFormatAccepted returns whether a claim's format, and its alg or proof type, comply with a given ClaimFormat
+func isClaimFormatAccepted(claim NormalizedClaim, format *ClaimFormat) bool {
+	// no format, which is an optional property
+	if format == nil {
+		return true
+	}
+	// if the format matches, check the alg type
+	if !util.Contains(claim.Format, format.FormatValues()) {
+		return false
+	}
+	// get the supported alg or proof types for this format
+	return util.Contains(claim.AlgOrProofType, format.AlgOrProofTypePerFormat())
+}
+
 // constructLimitedClaim builds a limited disclosure/filtered claim from a set of filtered input descriptors
 func constructLimitedClaim(limitedDescriptors []limitedInputDescriptor) map[string]any {
 	result := make(map[string]any)
@@ -457,19 +423,51 @@ func normalizeJSONPath(path string) string {
 	return pathRegex.ReplaceAllString(path, "")
 }
 
-// processInputDescriptorField applies all possible path values to a claim, and checks to see if any match.
-// if a path matches fulfilled will be set to true and no processed value will be returned. if limitDisclosure is
-// set to true, the processed value will be returned as well.
-func processInputDescriptorField(field Field, claimData map[string]any) (*limitedInputDescriptor, bool) {
-	for _, path := range field.Path {
-		pathedData, err := jsonpath.JsonPathLookup(claimData, path)
-		if err == nil {
-			limited := &limitedInputDescriptor{
-				Path: path,
-				Data: pathedData,
+// SubjectViews returns a view of a claim for each subject of a credential whose credentialSubject is an array, in
+// which credentialSubject is that one subject, so that paths are resolved against a single subject at a time. The
+// credential may be the claim itself or, for a JWT, its vc claim. Claims with a single subject are returned as is.
+func SubjectViews(claimData map[string]any) []map[string]any {
+	if subjects, ok := claimData["credentialSubject"].([]any); ok && len(subjects) > 0 {
+		views := make([]map[string]any, 0, len(subjects))
+		for _, subject := range subjects {
+			views = append(views, withProperty(claimData, "credentialSubject", subject))
+		}
+		return views
+	}
+	if vc, ok := claimData["vc"].(map[string]any); ok {
+		if _, ok = vc["credentialSubject"].([]any); ok {
+			var views []map[string]any
+			for _, vcView := range SubjectViews(vc) {
+				views = append(views, withProperty(claimData, "vc", vcView))
 			}
-			return limited, true
+			return views
+		}
+	}
+	return []map[string]any{claimData}
+}
+
+// withProperty returns a shallow copy of the JSON object with the property set to the given value
+func withProperty(object map[string]any, property string, value any) map[string]any {
+	result := make(map[string]any, len(object))
+	for k, v := range object {
+		result[k] = v
+	}
+	result[property] = value
+	return result
+}
+
+// processInputDescriptorField applies all possible path values to a claim, and checks to see if any match, passing
+// the field's filter if present. if a path matches fulfilled will be set to true and no processed value will be
+// returned. if limitDisclosure is set to true, the processed value will be returned as well. a field with a predicate
+// only matches data satisfying its filter, since the predicate's result must be true.
+func processInputDescriptorField(field Field, claimData map[string]any) (*limitedInputDescriptor, bool) {
+	path, pathedData, err := field.Match(claimData)
+	if err == nil {
+		limited := &limitedInputDescriptor{
+			Path: path,
+			Data: pathedData,
 		}
+		return limited, true
 	}
 	if field.Optional {
 		return nil, true
@@ -478,8 +476,8 @@ func processInputDescriptorField(field Field, claimData map[string]any) (*limite
 }
 
 // TODO(gabe) https://github.com/TBD54566975/ssi-sdk/issues/56
-// check for certain features we may not support yet: submission requirements, predicates, relational constraints,
-// credential status, JSON-LD framing from https://identity.foundation/presentation-exchange/#features
+// check for certain features we may not support yet: submission requirements and relational constraints from
+// https://identity.foundation/presentation-exchange/#features
 func canProcessDefinition(def PresentationDefinition) error {
 	if def.IsEmpty() {
 		return errors.New("presentation definition cannot be empty")
@@ -493,11 +491,9 @@ func canProcessDefinition(def PresentationDefinition) error {
 			if len(id.Group) > 0 {
 				return submissionRequirementsErr
 			}
-			if len(id.Constraints.Fields) > 0 {
-				for _, field := range id.Constraints.Fields {
-					if field.Predicate != nil {
-						return errors.New("predicate feature not supported")
-					}
+			for _, field := range id.Constraints.Fields {
+				if err := isValidPredicate(field); err != nil {
+					return errors.Wrapf(err, "input descriptor<%s>", id.ID)
 				}
 			}
 		}
@@ -509,15 +505,57 @@ func canProcessDefinition(def PresentationDefinition) error {
 	}
 	for _, id := range def.InputDescriptors {
 		if id.Constraints != nil && id.Constraints.Statuses != nil {
-			return errors.New("credential status constraint feature not supported")
+			if err := isValidStatusConstraint(*id.Constraints.Statuses); err != nil {
+				return errors.Wrapf(err, "input descriptor<%s>", id.ID)
+			}
 		}
 	}
 	if def.Frame != nil {
-		return errors.New("JSON-LD framing feature not supported")
+		if _, err := getFrame(def); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// getFrame returns the JSON-LD frame of a definition, which must be a JSON object, or nil if it has none
+// https://identity.foundation/presentation-exchange/#json-ld-framing-feature
+func getFrame(def PresentationDefinition) (map[string]any, error) {
+	if def.Frame == nil {
+		return nil, nil
+	}
+	frame, err := util.ToJSONMap(def.Frame)
+	if err != nil || len(frame) == 0 {
+		return nil, errors.New("JSON-LD frame must be a non-empty JSON object")
+	}
+	return frame, nil
+}
+
+// isValidPredicate checks a field's predicate, which must be required or preferred, and have a filter to evaluate
+// https://identity.foundation/presentation-exchange/#predicate-feature
+func isValidPredicate(field Field) error {
+	if field.Predicate == nil {
+		return nil
+	}
+	if *field.Predicate != Required && *field.Predicate != Preferred {
+		return fmt.Errorf("unsupported predicate<%s> for field<%s>; must be %s or %s", *field.Predicate, field.ID, Required, Preferred)
+	}
+	if field.Filter == nil {
+		return fmt.Errorf("field<%s> has a predicate without a filter", field.ID)
+	}
+	return nil
+}
+
+// hasRequiredPredicate returns whether any of the fields requires a predicate result in place of its value
+func hasRequiredPredicate(fields []Field) bool {
+	for _, field := range fields {
+		if field.Predicate != nil && *field.Predicate == Required {
+			return true
+		}
+	}
+	return false
+}
+
 // hasRelationalConstraint checks a constraint property for relational constraint field values
 // except for subject is issuer, which is supported
 func hasRelationalConstraint(constraints *Constraints) bool {
@@ -147,7 +147,7 @@ func TestBuildPresentationSubmissionVP(t *testing.T) {
 			LDPFormat:                     LDPVC.Ptr(),
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
 		assert.NoError(tt, err)
 		vp, err := BuildPresentationSubmissionVP("submitter", def, normalized)
 		assert.NoError(tt, err)
@@ -253,7 +253,7 @@ func TestBuildPresentationSubmissionVP(t *testing.T) {
 			LDPFormat:                     LDPVC.Ptr(),
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
 		assert.NoError(tt, err)
 		vp, err := BuildPresentationSubmissionVP("submitter", def, normalized)
 		assert.NoError(tt, err)
@@ -331,7 +331,7 @@ func TestBuildPresentationSubmissionVP(t *testing.T) {
 			SignatureAlgorithmOrProofType: string(crypto.Ed25519DSA),
 		}
 
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim, presentationClaimJWT})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim, presentationClaimJWT})
 		assert.NoError(tt, err)
 		vp, err := BuildPresentationSubmissionVP("submitter", def, normalized)
 		assert.NoError(tt, err)
@@ -390,7 +390,7 @@ func TestProcessInputDescriptor(t *testing.T) {
 			LDPFormat:                     LDPVC.Ptr(),
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
 		assert.NoError(tt, err)
 		processed, err := processInputDescriptor(id, normalized)
 		assert.NoError(tt, err)
@@ -423,13 +423,50 @@ func TestProcessInputDescriptor(t *testing.T) {
 			LDPFormat:                     LDPVC.Ptr(),
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
 		assert.NoError(tt, err)
 		_, err = processInputDescriptor(id, normalized)
 		assert.Error(tt, err)
 		assert.Contains(tt, err.Error(), "requiring limit disclosure is not supported")
 	})
 
+	t.Run("Descriptor with filter selecting between claims", func(tt *testing.T) {
+		id := InputDescriptor{
+			ID: "id-1",
+			Constraints: &Constraints{
+				Fields: []Field{
+					{
+						Path:   []string{"$.vc.issuer", "$.issuer"},
+						ID:     "issuer-input-descriptor",
+						Filter: &Filter{Type: "string", Const: "trusted-issuer"},
+					},
+				},
+			},
+		}
+		untrustedVC := getTestVerifiableCredential("untrusted-issuer", "test-subject")
+		untrustedVC.ID = "untrusted-credential"
+		trustedVC := getTestVerifiableCredential("trusted-issuer", "test-subject")
+		var claims []PresentationClaim
+		for _, vc := range []credential.VerifiableCredential{untrustedVC, trustedVC} {
+			vc := vc
+			claims = append(claims, PresentationClaim{
+				Credential:                    &vc,
+				LDPFormat:                     LDPVC.Ptr(),
+				SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
+			})
+		}
+		normalized, err := NormalizePresentationClaims(claims)
+		assert.NoError(tt, err)
+		processed, err := processInputDescriptor(id, normalized)
+		assert.NoError(tt, err)
+		require.NotEmpty(tt, processed)
+		assert.Equal(tt, "test-verifiable-credential", processed.ClaimID)
+
+		// no claim passes the filter
+		_, err = processInputDescriptor(id, normalized[:1])
+		assert.ErrorContains(tt, err, "no claims could fulfill the input descriptor: id-1")
+	})
+
 	t.Run("Descriptor with no matching paths", func(tt *testing.T) {
 		id := InputDescriptor{
 			ID: "id-1",
@@ -450,7 +487,7 @@ func TestProcessInputDescriptor(t *testing.T) {
 			LDPFormat:                     LDPVC.Ptr(),
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
 		assert.NoError(tt, err)
 		_, err = processInputDescriptor(id, normalized)
 		assert.Error(tt, err)
@@ -481,7 +518,7 @@ func TestProcessInputDescriptor(t *testing.T) {
 			LDPFormat:                     LDPVC.Ptr(),
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
 		assert.NoError(tt, err)
 		_, err = processInputDescriptor(id, normalized)
 		assert.Error(tt, err)
@@ -512,13 +549,48 @@ func TestProcessInputDescriptor(t *testing.T) {
 			LDPFormat:                     LDPVC.Ptr(),
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
 		assert.NoError(tt, err)
 		processed, err := processInputDescriptor(id, normalized)
 		assert.NoError(tt, err)
 		assert.NotEmpty(tt, processed)
 		assert.Equal(tt, id.ID, processed.ID)
 	})
+
+	t.Run("Descriptor with fields matching one of multiple subjects", func(tt *testing.T) {
+		testVC := getTestVerifiableCredential("test-issuer", "test-subject")
+		testVC.CredentialSubject = credential.CredentialSubject{"id": "did:example:alice", "reports": 3}
+		testVC.AdditionalCredentialSubjects = []credential.CredentialSubject{{"id": "did:example:bob", "badge": "1234"}}
+		presentationClaim := PresentationClaim{
+			Credential:                    &testVC,
+			LDPFormat:                     LDPVC.Ptr(),
+			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
+		}
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
+		assert.NoError(tt, err)
+
+		// both fields match bob
+		processed, err := processInputDescriptor(InputDescriptor{
+			ID: "id-1",
+			Constraints: &Constraints{Fields: []Field{
+				{Path: []string{"$.credentialSubject.id"}},
+				{Path: []string{"$.credentialSubject.badge"}},
+			}},
+		}, normalized)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "id-1", processed.ID)
+
+		// the fields each match a different subject
+		_, err = processInputDescriptor(InputDescriptor{
+			ID: "id-2",
+			Constraints: &Constraints{Fields: []Field{
+				{Path: []string{"$.credentialSubject.badge"}},
+				{Path: []string{"$.credentialSubject.reports"}},
+			}},
+		}, normalized)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "no claims could fulfill the input descriptor: id-2")
+	})
 }
 
 func TestCanProcessDefinition(tt *testing.T) {
@@ -555,7 +627,15 @@ func TestCanProcessDefinition(tt *testing.T) {
 		}
 		err := canProcessDefinition(def)
 		assert.Error(tt, err)
-		assert.Contains(tt, err.Error(), "predicate feature not supported")
+		assert.Contains(tt, err.Error(), "unsupported predicate<allowed>")
+
+		def.InputDescriptors[0].Constraints.Fields[0].Predicate = Required.Ptr()
+		err = canProcessDefinition(def)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has a predicate without a filter")
+
+		def.InputDescriptors[0].Constraints.Fields[0].Filter = &Filter{Type: "string"}
+		assert.NoError(tt, canProcessDefinition(def))
 	})
 
 	tt.Run("With Relational Constraint", func(tt *testing.T) {
@@ -588,11 +668,7 @@ func TestCanProcessDefinition(tt *testing.T) {
 					ID: "id-with-credential-status",
 					Constraints: &Constraints{
 						Statuses: &CredentialStatus{
-							Active: &struct {
-								Directive Preference `json:"directive,omitempty"`
-							}{
-								Directive: Required,
-							},
+							Active: &StatusDirective{Directive: Preferred},
 						},
 					},
 				},
@@ -600,7 +676,10 @@ func TestCanProcessDefinition(tt *testing.T) {
 		}
 		err := canProcessDefinition(def)
 		assert.Error(tt, err)
-		assert.Contains(tt, err.Error(), "credential status constraint feature not supported")
+		assert.Contains(tt, err.Error(), "unsupported active status directive<preferred>")
+
+		def.InputDescriptors[0].Constraints.Statuses.Active.Directive = Required
+		assert.NoError(tt, canProcessDefinition(def))
 	})
 
 	tt.Run("With LD Framing", func(t *testing.T) {
@@ -610,7 +689,10 @@ func TestCanProcessDefinition(tt *testing.T) {
 		}
 		err := canProcessDefinition(def)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "JSON-LD framing feature not supported")
+		assert.Contains(t, err.Error(), "JSON-LD frame must be a non-empty JSON object")
+
+		def.Frame = map[string]any{"type": "VerifiableCredential"}
+		assert.NoError(t, canProcessDefinition(def))
 	})
 }
 
@@ -763,7 +845,7 @@ func TestNormalizePresentationClaims(t *testing.T) {
 			SignatureAlgorithmOrProofType: string(crypto.Ed25519DSA),
 		}
 
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
 		assert.NoError(tt, err)
 		assert.NotEmpty(tt, normalized)
 		assert.True(tt, len(normalized) == 1)
@@ -782,7 +864,7 @@ func TestNormalizePresentationClaims(t *testing.T) {
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
 
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
 		assert.NoError(tt, err)
 		assert.NotEmpty(tt, normalized)
 		assert.True(tt, len(normalized) == 1)
@@ -801,7 +883,7 @@ func TestNormalizePresentationClaims(t *testing.T) {
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
 
-		normalized, err := normalizePresentationClaims([]PresentationClaim{presentationClaim})
+		normalized, err := NormalizePresentationClaims([]PresentationClaim{presentationClaim})
 		assert.NoError(tt, err)
 		assert.NotEmpty(tt, normalized)
 		assert.True(tt, len(normalized) == 1)
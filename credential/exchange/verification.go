@@ -9,9 +9,9 @@ import (
 	"github.com/TBD54566975/ssi-sdk/credential/parsing"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
-	"github.com/TBD54566975/ssi-sdk/schema"
 
 	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/oliveagle/jsonpath"
 	"github.com/pkg/errors"
 
@@ -35,10 +35,11 @@ type VerifiedSubmissionData struct {
 // with the specification. It is assumed that the caller knows the submission embed target, and the corresponding
 // presentation definition, and has access to the public key of the signer. A DID resolution is required to resolve
 // the DID and keys of the signer for each credential in the presentation, whose signatures also need to be verified.
+// Input descriptors with statuses constraints require a status check option, such as WithStatusCheck.
 // Note: this method does not support LD cryptosuites, and prefers JWT representations. Future refactors
 // may include an analog method for LD suites.
 // TODO(gabe) remove embed target, have it detected from the submission
-func VerifyPresentationSubmission(ctx context.Context, verifier any, resolver resolution.Resolver, et EmbedTarget, def PresentationDefinition, submission []byte) ([]VerifiedSubmissionData, error) { //revive:disable-line
+func VerifyPresentationSubmission(ctx context.Context, verifier any, resolver resolution.Resolver, et EmbedTarget, def PresentationDefinition, submission []byte, opts ...VerificationOption) ([]VerifiedSubmissionData, error) { //revive:disable-line
 	if resolver == nil {
 		return nil, errors.New("resolution cannot be empty")
 	}
@@ -62,15 +63,74 @@ func VerifyPresentationSubmission(ctx context.Context, verifier any, resolver re
 		if err != nil {
 			return nil, errors.Wrap(err, "verification of the presentation submission failed")
 		}
-		return VerifyPresentationSubmissionVP(def, *vp)
+		return verifyPresentationSubmissionVP(ctx, def, *vp, opts...)
 	default:
 		return nil, fmt.Errorf("presentation submission embed target <%s> is not implemented", et)
 	}
 }
 
 // VerifyPresentationSubmissionVP verifies whether a verifiable presentation is a valid presentation submission
-// for a given presentation definition. No signature verification happens here.
-func VerifyPresentationSubmissionVP(def PresentationDefinition, vp credential.VerifiablePresentation) ([]VerifiedSubmissionData, error) {
+// for a given presentation definition. No signature verification happens here. Input descriptors with statuses
+// constraints require a status check option, such as WithStatusCheck.
+func VerifyPresentationSubmissionVP(def PresentationDefinition, vp credential.VerifiablePresentation, opts ...VerificationOption) ([]VerifiedSubmissionData, error) {
+	return verifyPresentationSubmissionVP(context.Background(), def, vp, opts...)
+}
+
+// EvaluatePresentationSubmissionVP evaluates whether a verifiable presentation is a valid presentation submission
+// for a given presentation definition, as VerifyPresentationSubmissionVP does, and reports the result for each input
+// descriptor, its submitted claim, and each of its fields rather than stopping at the first failure. An error is
+// returned only when the presentation or its submission is invalid, or is not for the definition.
+func EvaluatePresentationSubmissionVP(def PresentationDefinition, vp credential.VerifiablePresentation, opts ...VerificationOption) (*EvaluationReport, error) {
+	evaluator, err := newSubmissionEvaluator(def, vp, opts...)
+	if err != nil {
+		return nil, err
+	}
+	report := EvaluationReport{DefinitionID: def.ID}
+	for _, inputDescriptor := range def.InputDescriptors {
+		evaluation, _, _ := evaluator.evaluate(context.Background(), inputDescriptor)
+		report.InputDescriptors = append(report.InputDescriptors, evaluation)
+	}
+	return &report, nil
+}
+
+func verifyPresentationSubmissionVP(ctx context.Context, def PresentationDefinition, vp credential.VerifiablePresentation, opts ...VerificationOption) ([]VerifiedSubmissionData, error) {
+	evaluator, err := newSubmissionEvaluator(def, vp, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// store results for each input descriptor
+	verifiedSubmissionData := make([]VerifiedSubmissionData, 0)
+
+	// validate each input descriptor is fulfilled
+	for _, inputDescriptor := range def.InputDescriptors {
+		_, verifiedSubmissionDatum, err := evaluator.evaluate(ctx, inputDescriptor)
+		if err != nil {
+			return nil, err
+		}
+
+		// once we get here we know the input descriptor is satisfied, and we can append the filtered
+		// data to the value being returned
+		if verifiedSubmissionDatum != nil {
+			verifiedSubmissionData = append(verifiedSubmissionData, *verifiedSubmissionDatum)
+		}
+
+		// TODO(gabe) is_holder and same_subject cannot yet be implemented https://github.com/TBD54566975/ssi-sdk/issues/64
+	}
+	return verifiedSubmissionData, nil
+}
+
+// submissionEvaluator evaluates the claims of a presentation submission against the input descriptors they fulfill
+type submissionEvaluator struct {
+	// submission descriptors indexed by the id of their input descriptor
+	submissionDescriptors map[string]SubmissionDescriptor
+	// the submitted value as JSON, such as a VP, so we can use the paths from the submission descriptors to resolve
+	// each claim
+	submitted      any
+	statusReporter StatusReporter
+}
+
+func newSubmissionEvaluator(def PresentationDefinition, vp credential.VerifiablePresentation, opts ...VerificationOption) (*submissionEvaluator, error) {
 	if err := vp.IsValid(); err != nil {
 		return nil, errors.Wrap(err, "presentation submission does not contain a valid VP")
 	}
@@ -80,6 +140,22 @@ func VerifyPresentationSubmissionVP(def PresentationDefinition, vp credential.Ve
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to parse presentation submission from verifiable presentation")
 	}
+
+	// turn the vp into JSON so we can use the paths from the submission descriptor to resolve each claim
+	vpJSON, err := util.ToJSONMap(vp)
+	if err != nil {
+		return nil, errors.Wrap(err, "turning VP into JSON representation")
+	}
+	return newSubmissionEvaluatorFor(def, *submission, vpJSON, opts...)
+}
+
+// newSubmissionEvaluatorFor returns an evaluator of a presentation submission whose paths select claims from the
+// submitted JSON value
+func newSubmissionEvaluatorFor(def PresentationDefinition, submission PresentationSubmission, submitted any, opts ...VerificationOption) (*submissionEvaluator, error) {
+	statusReporter, err := getStatusReporter(opts)
+	if err != nil {
+		return nil, err
+	}
 	if err = submission.IsValid(); err != nil {
 		return nil, errors.Wrap(err, "invalid presentation submission in provided verifiable presentation")
 	}
@@ -93,116 +169,154 @@ func VerifyPresentationSubmissionVP(def PresentationDefinition, vp credential.Ve
 	for _, d := range submission.DescriptorMap {
 		submissionDescriptorLookup[d.ID] = d
 	}
+	return &submissionEvaluator{
+		submissionDescriptors: submissionDescriptorLookup,
+		submitted:             submitted,
+		statusReporter:        statusReporter,
+	}, nil
+}
 
-	// turn the vp into JSON so we can use the paths from the submission descriptor to resolve each claim
-	vpJSON, err := util.ToJSONMap(vp)
+// evaluate evaluates the claim submitted for an input descriptor, returning an error if it does not fulfill the input
+// descriptor, and otherwise its verified data, unless the input descriptor has no constraints
+func (e submissionEvaluator) evaluate(ctx context.Context, inputDescriptor InputDescriptor) (InputDescriptorEvaluation, *VerifiedSubmissionData, error) {
+	evaluation := InputDescriptorEvaluation{InputDescriptorID: inputDescriptor.ID}
+	verifiedSubmissionDatum, err := e.verify(ctx, inputDescriptor, &evaluation)
 	if err != nil {
-		return nil, errors.Wrap(err, "turning VP into JSON representation")
+		evaluation.Reason = err.Error()
+		for i := range evaluation.Claims {
+			evaluation.Claims[i].Reason = err.Error()
+		}
+		return evaluation, nil, err
 	}
+	evaluation.Fulfilled = true
+	for i := range evaluation.Claims {
+		evaluation.Claims[i].Fulfilled = true
+	}
+	return evaluation, verifiedSubmissionDatum, nil
+}
 
-	// store results for each input descriptor
-	verifiedSubmissionData := make([]VerifiedSubmissionData, 0)
+// verify verifies the claim submitted for an input descriptor, adding the evaluation of the claim, once resolved, and
+// of its fields to the input descriptor's evaluation
+func (e submissionEvaluator) verify(ctx context.Context, inputDescriptor InputDescriptor, evaluation *InputDescriptorEvaluation) (*VerifiedSubmissionData, error) {
+	inputDescriptorID := inputDescriptor.ID
 
-	// validate each input descriptor is fulfilled
-	inputDescriptorLookup := make(map[string]InputDescriptor)
-	for _, inputDescriptor := range def.InputDescriptors {
-		inputDescriptorID := inputDescriptor.ID
+	// build verifiedSubmissionDatum should the input descriptor be fulfilled
+	verifiedSubmissionDatum := VerifiedSubmissionData{InputDescriptorID: inputDescriptorID}
 
-		// build verifiedSubmissionDatum should the input descriptor be fulfilled
-		verifiedSubmissionDatum := VerifiedSubmissionData{InputDescriptorID: inputDescriptorID}
+	submissionDescriptor, ok := e.submissionDescriptors[inputDescriptorID]
+	if !ok {
+		return nil, fmt.Errorf("unfulfilled input descriptor<%s>; submission not valid", inputDescriptorID)
+	}
 
-		inputDescriptorLookup[inputDescriptorID] = inputDescriptor
-		submissionDescriptor, ok := submissionDescriptorLookup[inputDescriptorID]
-		if !ok {
-			return nil, fmt.Errorf("unfulfilled input descriptor<%s>; submission not valid", inputDescriptorID)
-		}
+	// resolve the claim from the JSON path expressions in the submission descriptor, and any nested within it
+	claim, claimDescriptor, err := resolveSubmittedClaim(e.submitted, submissionDescriptor)
+	if err != nil {
+		return nil, err
+	}
 
-		// if the format on the submitted claim does not match the input descriptor, we cannot process
-		if inputDescriptor.Format != nil && !util.Contains(submissionDescriptor.Format, inputDescriptor.Format.FormatValues()) {
-			return nil, fmt.Errorf("for input descriptor<%s>, the format of submission descriptor<%s> is not one"+
-				"  of the supported formats: %s", inputDescriptorID, submissionDescriptor.Format,
-				strings.Join(inputDescriptor.Format.FormatValues(), ", "))
-		}
+	// if the format on the submitted claim does not match the input descriptor, we cannot process
+	if inputDescriptor.Format != nil && !util.Contains(claimDescriptor.Format, inputDescriptor.Format.FormatValues()) {
+		return nil, fmt.Errorf("for input descriptor<%s>, the format of submission descriptor<%s> is not one"+
+			"  of the supported formats: %s", inputDescriptorID, claimDescriptor.Format,
+			strings.Join(inputDescriptor.Format.FormatValues(), ", "))
+	}
 
-		// TODO(gabe) support nested paths in presentation submissions https://github.com/TBD54566975/ssi-sdk/issues/73
-		if submissionDescriptor.PathNested != nil {
-			return nil, fmt.Errorf("submission with nested paths not supported: %s", submissionDescriptor.ID)
-		}
+	// get the credential from the claim
+	_, _, cred, err := parsing.ToCredential(claim)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting claim as json: <%s>", claim)
+	}
+	evaluation.Claims = append(evaluation.Claims, ClaimEvaluation{ClaimID: cred.ID, Path: submissionDescriptor.Path})
+	claimEvaluation := &evaluation.Claims[len(evaluation.Claims)-1]
 
-		// resolve the claim from the JSON path expression in the submission descriptor
-		claim, err := jsonpath.JsonPathLookup(vpJSON, submissionDescriptor.Path)
-		if err != nil {
-			return nil, errors.Wrapf(err, "could not resolve claim from submission descriptor<%s> with path: %s",
-				submissionDescriptor.ID, submissionDescriptor.Path)
-		}
+	// verify the submitted claim complies with the input descriptor
 
-		// get the credential from the claim
-		_, _, cred, err := parsing.ToCredential(claim)
-		if err != nil {
-			return nil, errors.Wrapf(err, "getting claim as json: <%s>", claim)
+	// if there are no constraints, we are done checking for validity
+	constraints := inputDescriptor.Constraints
+	if constraints == nil {
+		claimEvaluation.Score = scoreFields(nil)
+		return nil, nil
+	}
+
+	// TODO(gabe) consider enforcing limited disclosure if present
+	// for each field we need to verify at least one path matches, for the same subject of the credential
+	credJSON, err := parsing.ToCredentialJSONMap(claim)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting credential as json: %v", cred)
+	}
+	var pathedData any
+	claimEvaluation.Fields, pathedData = evaluateSubjects(credJSON, func(subjectJSON map[string]any) ([]FieldEvaluation, any) {
+		return evaluateSubmittedFields(constraints.Fields, subjectJSON)
+	})
+	claimEvaluation.Score = scoreFields(claimEvaluation.Fields)
+	for _, field := range claimEvaluation.Fields {
+		if !field.Matched && !field.Optional {
+			return nil, fmt.Errorf("input descriptor<%s> not fulfilled for non-optional field<%s>: %s", inputDescriptorID, field.FieldID, field.Reason)
 		}
+	}
 
-		// verify the submitted claim complies with the input descriptor
+	// add claim and pathed data to the verifiedSubmissionDatum once we know it is valid
+	if len(constraints.Fields) > 0 {
+		verifiedSubmissionDatum.Claim = claim
+		verifiedSubmissionDatum.FilteredData = pathedData
+	}
 
-		// if there are no constraints, we are done checking for validity
-		constraints := inputDescriptor.Constraints
-		if constraints == nil {
-			continue
+	// check relational constraints if present
+	subjectIsIssuerConstraint := constraints.SubjectIsIssuer
+	if subjectIsIssuerConstraint != nil && *subjectIsIssuerConstraint == Required {
+		issuer, ok := cred.Issuer.(string)
+		if !ok {
+			return nil, fmt.Errorf("unable to get issuer from cred: %s", cred.Issuer)
 		}
-
-		// TODO(gabe) consider enforcing limited disclosure if present
-		// for each field we need to verify at least one path matches
-		credJSON, err := parsing.ToCredentialJSONMap(claim)
-		if err != nil {
-			return nil, errors.Wrapf(err, "getting credential as json: %v", cred)
+		subject, ok := cred.CredentialSubject[credential.VerifiableCredentialIDProperty]
+		if !ok {
+			return nil, fmt.Errorf("unable to get subject from cred: %s", cred.CredentialSubject)
 		}
-		for _, field := range constraints.Fields {
-			// get data from path
-			pathedData, err := getDataFromJSONPath(credJSON, field.Path)
-			if err != nil && !field.Optional {
-				return nil, errors.Wrapf(err, "input descriptor<%s> not fulfilled for non-optional field: %s", inputDescriptorID, field.ID)
-			}
-
-			// apply json schema filter if present
-			if field.Filter != nil {
-				filterJSON, err := field.Filter.ToJSON()
-				if err != nil && !field.Optional {
-					return nil, errors.Wrapf(err, "turning filter into JSON schema")
-				}
-				if err = schema.IsAnyValidAgainstJSONSchema(pathedData, filterJSON); err != nil && !field.Optional {
-					return nil, errors.Wrapf(err, "unable to apply filter<%s> to data from path: %s", filterJSON, field.Path)
-				}
-			}
-
-			// add claim and pathed data to the verifiedSubmissionDatum once we know it is valid
-			verifiedSubmissionDatum.Claim = claim
-			verifiedSubmissionDatum.FilteredData = pathedData
+		if issuer != subject {
+			return nil, fmt.Errorf("subject<%s> is not the same as issuer<%s>", subject, issuer)
 		}
+	}
 
-		// check relational constraints if present
-		subjectIsIssuerConstraint := constraints.SubjectIsIssuer
-		if subjectIsIssuerConstraint != nil && *subjectIsIssuerConstraint == Required {
-			issuer, ok := cred.Issuer.(string)
-			if !ok {
-				return nil, fmt.Errorf("unable to get issuer from cred: %s", cred.Issuer)
-			}
-			subject, ok := cred.CredentialSubject[credential.VerifiableCredentialIDProperty]
-			if !ok {
-				return nil, fmt.Errorf("unable to get subject from cred: %s", cred.CredentialSubject)
-			}
-			if issuer != subject {
-				return nil, fmt.Errorf("subject<%s> is not the same as issuer<%s>", subject, issuer)
-			}
+	// check the credential's status if constrained
+	if constraints.Statuses != nil {
+		if e.statusReporter == nil {
+			return nil, fmt.Errorf("input descriptor<%s> has a statuses constraint, which requires a status check option", inputDescriptorID)
 		}
+		if err = checkStatusConstraint(ctx, *constraints.Statuses, *cred, e.statusReporter); err != nil {
+			return nil, errors.Wrapf(err, "input descriptor<%s> statuses constraint not fulfilled", inputDescriptorID)
+		}
+	}
+	return &verifiedSubmissionDatum, nil
+}
 
-		// once we get here we know the input descriptor is satisfied, and we can append the filtered
-		// data to the value being returned
-		verifiedSubmissionData = append(verifiedSubmissionData, verifiedSubmissionDatum)
-
-		// TODO(gabe) is_holder and same_subject cannot yet be implemented https://github.com/TBD54566975/ssi-sdk/issues/64
-		// TODO(gabe) check credential status https://github.com/TBD54566975/ssi-sdk/issues/65
+// resolveSubmittedClaim resolves the claim a submission descriptor selects from the submitted value, following its
+// nested paths, each of which selects a claim from the one its parent selects; a claim which is a JWT, such as a JWT
+// VP, is nested within its payload. It returns the claim along with the innermost submission descriptor, whose format
+// is the claim's.
+// https://identity.foundation/presentation-exchange/#processing-of-submission-entries
+func resolveSubmittedClaim(submitted any, d SubmissionDescriptor) (any, *SubmissionDescriptor, error) {
+	claim, err := jsonpath.JsonPathLookup(submitted, d.Path)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not resolve claim from submission descriptor<%s> with path: %s",
+			d.ID, d.Path)
 	}
-	return verifiedSubmissionData, nil
+	if d.PathNested == nil {
+		return claim, &d, nil
+	}
+	if d.PathNested.ID != d.ID {
+		return nil, nil, fmt.Errorf("nested submission descriptor<%s> does not match submission descriptor<%s>",
+			d.PathNested.ID, d.ID)
+	}
+	if token, ok := claim.(string); ok {
+		parsed, err := jwt.Parse([]byte(token), jwt.WithValidate(false), jwt.WithVerify(false))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "parsing claim of submission descriptor<%s> with path<%s> as a JWT", d.ID, d.Path)
+		}
+		if claim, err = util.ToJSONMap(parsed); err != nil {
+			return nil, nil, errors.Wrapf(err, "getting claim of submission descriptor<%s> with path<%s> as json", d.ID, d.Path)
+		}
+	}
+	return resolveSubmittedClaim(claim, *d.PathNested)
 }
 
 func toPresentationSubmission(maybePresentationSubmission any) (*PresentationSubmission, error) {
@@ -217,6 +331,51 @@ func toPresentationSubmission(maybePresentationSubmission any) (*PresentationSub
 	return &submission, nil
 }
 
+// evaluateSubmittedFields evaluates whether each of an input descriptor's fields matches the credential, with data at
+// one of its paths passing its filter, returning the data of the last field matched
+func evaluateSubmittedFields(fields []Field, credJSON map[string]any) ([]FieldEvaluation, any) {
+	var pathedData any
+	evaluations := make([]FieldEvaluation, 0, len(fields))
+	for _, field := range fields {
+		evaluation := FieldEvaluation{FieldID: field.ID, Optional: field.Optional}
+
+		// a predicate's result may be submitted in place of the data, and must be true
+		if field.Predicate != nil {
+			if data, err := getDataFromJSONPath(credJSON, field.Path); err == nil {
+				if result, ok := data.(bool); ok {
+					if result {
+						evaluation.Matched = true
+						pathedData = result
+					} else {
+						evaluation.Reason = "predicate result is false"
+					}
+					evaluations = append(evaluations, evaluation)
+					continue
+				}
+				if *field.Predicate == Required {
+					evaluation.Reason = fmt.Sprintf("a predicate result is required in place of data from path: %s", field.Path)
+					evaluations = append(evaluations, evaluation)
+					continue
+				}
+			}
+		}
+
+		// get data from the first path whose data passes the json schema filter, if present
+		path, data, err := field.Match(credJSON)
+		if data != nil {
+			pathedData = data
+		}
+		if err != nil {
+			evaluation.Reason = err.Error()
+		} else {
+			evaluation.Matched = true
+			evaluation.Path = path
+		}
+		evaluations = append(evaluations, evaluation)
+	}
+	return evaluations, pathedData
+}
+
 func getDataFromJSONPath(claim any, paths []string) (any, error) {
 	for _, path := range paths {
 		if pathedData, err := jsonpath.JsonPathLookup(claim, path); err == nil {
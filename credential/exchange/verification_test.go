@@ -14,6 +14,7 @@ import (
 	"github.com/TBD54566975/ssi-sdk/util"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/status"
 )
 
 func TestVerifyPresentationSubmission(t *testing.T) {
@@ -504,6 +505,152 @@ func TestVerifyPresentationSubmissionVP(t *testing.T) {
 		assert.Equal(tt, "Block", verifiedSubmissionData[0].FilteredData)
 	})
 
+	t.Run("Input Descriptor with predicate", func(tt *testing.T) {
+		def := PresentationDefinition{
+			ID: "test-id",
+			InputDescriptors: []InputDescriptor{
+				{
+					ID: "id-1",
+					Constraints: &Constraints{
+						Fields: []Field{
+							{
+								ID:        "company-input-descriptor",
+								Path:      []string{"$.credentialSubject.company"},
+								Predicate: Required.Ptr(),
+								Filter: &Filter{
+									Type:    "string",
+									Pattern: "Block",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		assert.NoError(tt, def.IsValid())
+
+		testVC := getTestVerifiableCredential("test-issuer", "test-subject")
+		presentation := credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1",
+				"https://identity.foundation/presentation-exchange/submission/v1"},
+			ID:   "55da1f5c-e2b3-443a-b687-0434712c5469",
+			Type: []string{"VerifiablePresentation", "PresentationSubmission"},
+			PresentationSubmission: PresentationSubmission{
+				ID:           "45da2588-3637-45b0-84f1-17e97945ac09",
+				DefinitionID: "test-id",
+				DescriptorMap: []SubmissionDescriptor{
+					{
+						Format: "ldp_vc",
+						ID:     "id-1",
+						Path:   "$.verifiableCredential[0]",
+					},
+				},
+			},
+			VerifiableCredential: []any{testVC},
+		}
+
+		// the value itself does not fulfill a required predicate
+		_, err := VerifyPresentationSubmissionVP(def, presentation)
+		assert.ErrorContains(tt, err, "a predicate result is required")
+
+		testVC.CredentialSubject["company"] = true
+		presentation.VerifiableCredential = []any{testVC}
+		verifiedSubmissionData, err := VerifyPresentationSubmissionVP(def, presentation)
+		assert.NoError(tt, err)
+		assert.Len(tt, verifiedSubmissionData, 1)
+
+		testVC.CredentialSubject["company"] = false
+		presentation.VerifiableCredential = []any{testVC}
+		_, err = VerifyPresentationSubmissionVP(def, presentation)
+		assert.ErrorContains(tt, err, "predicate result is false")
+
+		// a preferred predicate may be fulfilled by the value
+		def.InputDescriptors[0].Constraints.Fields[0].Predicate = Preferred.Ptr()
+		testVC.CredentialSubject["company"] = "Block"
+		presentation.VerifiableCredential = []any{testVC}
+		verifiedSubmissionData, err = VerifyPresentationSubmissionVP(def, presentation)
+		assert.NoError(tt, err)
+		assert.Len(tt, verifiedSubmissionData, 1)
+	})
+
+	t.Run("Input Descriptor with statuses constraint", func(tt *testing.T) {
+		def := PresentationDefinition{
+			ID: "test-id",
+			InputDescriptors: []InputDescriptor{
+				{
+					ID: "id-1",
+					Constraints: &Constraints{
+						Fields: []Field{{Path: []string{"$.issuer"}}},
+						Statuses: &CredentialStatus{
+							Active:  &StatusDirective{Directive: Required},
+							Revoked: &StatusDirective{Directive: Disallowed, Type: []string{"BitstringStatusListEntry"}},
+						},
+					},
+				},
+			},
+		}
+		assert.NoError(tt, def.IsValid())
+
+		testVC := getTestVerifiableCredential("test-issuer", "test-subject")
+		testVC.CredentialStatus = map[string]any{"type": "BitstringStatusListEntry", "statusPurpose": "revocation"}
+		presentation := credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1",
+				"https://identity.foundation/presentation-exchange/submission/v1"},
+			ID:   "55da1f5c-e2b3-443a-b687-0434712c5469",
+			Type: []string{"VerifiablePresentation", "PresentationSubmission"},
+			PresentationSubmission: PresentationSubmission{
+				ID:           "45da2588-3637-45b0-84f1-17e97945ac09",
+				DefinitionID: "test-id",
+				DescriptorMap: []SubmissionDescriptor{
+					{
+						Format: "ldp_vc",
+						ID:     "id-1",
+						Path:   "$.verifiableCredential[0]",
+					},
+				},
+			},
+			VerifiableCredential: []any{testVC},
+		}
+		reportWith := func(report status.StatusReport) VerificationOption {
+			return WithStatusReporter(func(context.Context, credential.VerifiableCredential) (*status.StatusReport, error) {
+				return &report, nil
+			})
+		}
+
+		_, err := VerifyPresentationSubmissionVP(def, presentation)
+		assert.ErrorContains(tt, err, "requires a status check option")
+
+		verifiedSubmissionData, err := VerifyPresentationSubmissionVP(def, presentation, reportWith(status.StatusReport{
+			Outcome:  status.StatusOutcomeActive,
+			Statuses: []status.PurposeStatus{{Purpose: status.StatusRevocation}},
+		}))
+		assert.NoError(tt, err)
+		assert.Len(tt, verifiedSubmissionData, 1)
+
+		_, err = VerifyPresentationSubmissionVP(def, presentation, reportWith(status.StatusReport{
+			Outcome:  status.StatusOutcomeRevoked,
+			Statuses: []status.PurposeStatus{{Purpose: status.StatusRevocation, Status: 1}},
+		}))
+		assert.ErrorContains(tt, err, "credential must be active")
+
+		def.InputDescriptors[0].Constraints.Statuses.Active = nil
+		_, err = VerifyPresentationSubmissionVP(def, presentation, reportWith(status.StatusReport{
+			Outcome:  status.StatusOutcomeRevoked,
+			Statuses: []status.PurposeStatus{{Purpose: status.StatusRevocation, Status: 1}},
+		}))
+		assert.ErrorContains(tt, err, "credential cannot be revoked")
+
+		_, err = VerifyPresentationSubmissionVP(def, presentation, reportWith(status.StatusReport{
+			Outcome: status.StatusOutcomeUnknown,
+			Reason:  "status list unavailable",
+		}))
+		assert.ErrorContains(tt, err, "credential status could not be determined")
+
+		def.InputDescriptors[0].Constraints.Statuses.Revoked.Type = []string{"StatusList2021Entry"}
+		_, err = VerifyPresentationSubmissionVP(def, presentation, reportWith(status.StatusReport{Outcome: status.StatusOutcomeActive}))
+		assert.ErrorContains(tt, err, "are not among the revoked status types")
+	})
+
 	t.Run("Verification with JWT credential", func(tt *testing.T) {
 		def := PresentationDefinition{
 			ID: "test-id",
@@ -0,0 +1,232 @@
+package exchange
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// Version is a version of the Presentation Exchange specification
+type Version string
+
+const (
+	// V1 https://identity.foundation/presentation-exchange/spec/v1.0.0/
+	V1 Version = "1.0.0"
+	// V2 https://identity.foundation/presentation-exchange/spec/v2.0.0/, which the models of this package follow
+	V2 Version = "2.0.0"
+)
+
+// PresentationDefinitionV1 is a presentation definition as specified by version 1, whose input descriptors name the
+// schemas of the credentials they accept
+// https://identity.foundation/presentation-exchange/spec/v1.0.0/#presentation-definition
+type PresentationDefinitionV1 struct {
+	ID                     string                  `json:"id" validate:"required"`
+	Name                   string                  `json:"name,omitempty"`
+	Purpose                string                  `json:"purpose,omitempty"`
+	Format                 *ClaimFormat            `json:"format,omitempty"`
+	InputDescriptors       []InputDescriptorV1     `json:"input_descriptors" validate:"required,dive"`
+	SubmissionRequirements []SubmissionRequirement `json:"submission_requirements,omitempty"`
+}
+
+// InputDescriptorV1 https://identity.foundation/presentation-exchange/spec/v1.0.0/#input-descriptor-object
+type InputDescriptorV1 struct {
+	ID          string       `json:"id" validate:"required"`
+	Name        string       `json:"name,omitempty"`
+	Purpose     string       `json:"purpose,omitempty"`
+	Group       []string     `json:"group,omitempty"`
+	Schema      []SchemaV1   `json:"schema" validate:"required,min=1"`
+	Constraints *Constraints `json:"constraints,omitempty"`
+}
+
+// SchemaV1 is a schema of the credentials an input descriptor accepts, which version 2 replaced with fields
+type SchemaV1 struct {
+	URI      string `json:"uri" validate:"required"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// v1SchemaPaths are the paths of the field which replaces the schemas of a version 1 input descriptor, matching
+// either a credential's schema or one of its types
+var v1SchemaPaths = []string{"$.credentialSchema.id", "$.vc.credentialSchema.id", "$.type", "$.vc.type"}
+
+// DetectDefinitionVersion returns the version of a presentation definition, or of the definition in a presentation
+// definition envelope. Definitions whose input descriptors have schemas are version 1; those using properties added
+// by version 2, or compatible with both versions, are version 2.
+func DetectDefinitionVersion(definition []byte) (Version, error) {
+	var definitionJSON map[string]any
+	if err := json.Unmarshal(definition, &definitionJSON); err != nil {
+		return "", errors.Wrap(err, "unmarshalling presentation definition")
+	}
+	if inner, ok := definitionJSON["presentation_definition"].(map[string]any); ok {
+		definitionJSON = inner
+	}
+	descriptors, ok := definitionJSON["input_descriptors"].([]any)
+	if !ok {
+		return "", errors.New("presentation definition has no input descriptors")
+	}
+	for _, d := range descriptors {
+		descriptor, ok := d.(map[string]any)
+		if !ok {
+			return "", errors.New("presentation definition has an invalid input descriptor")
+		}
+		if _, ok = descriptor["schema"]; ok {
+			return V1, nil
+		}
+	}
+	return V2, nil
+}
+
+// ParsePresentationDefinition parses a presentation definition of either version, converting a version 1 definition
+// to version 2, and returns the version it was in
+func ParsePresentationDefinition(definition []byte) (*PresentationDefinition, Version, error) {
+	version, err := DetectDefinitionVersion(definition)
+	if err != nil {
+		return nil, "", err
+	}
+	var envelope struct {
+		Definition json.RawMessage `json:"presentation_definition"`
+	}
+	if err = json.Unmarshal(definition, &envelope); err == nil && len(envelope.Definition) > 0 {
+		definition = envelope.Definition
+	}
+
+	if version == V1 {
+		var v1 PresentationDefinitionV1
+		if err = json.Unmarshal(definition, &v1); err != nil {
+			return nil, "", errors.Wrap(err, "unmarshalling version 1 presentation definition")
+		}
+		def, err := ConvertDefinitionFromV1(v1)
+		if err != nil {
+			return nil, "", err
+		}
+		return def, version, nil
+	}
+	var def PresentationDefinition
+	if err = json.Unmarshal(definition, &def); err != nil {
+		return nil, "", errors.Wrap(err, "unmarshalling presentation definition")
+	}
+	return &def, version, nil
+}
+
+// ConvertDefinitionFromV1 converts a version 1 presentation definition to version 2. The schemas of each input
+// descriptor become a field matching a credential whose schema, or one of whose types, is any of the schema URIs, as
+// version 1 specifies.
+func ConvertDefinitionFromV1(v1 PresentationDefinitionV1) (*PresentationDefinition, error) {
+	def := PresentationDefinition{
+		ID:                     v1.ID,
+		Name:                   v1.Name,
+		Purpose:                v1.Purpose,
+		Format:                 v1.Format,
+		SubmissionRequirements: v1.SubmissionRequirements,
+	}
+	for _, v1Descriptor := range v1.InputDescriptors {
+		if len(v1Descriptor.Schema) == 0 {
+			return nil, fmt.Errorf("version 1 input descriptor<%s> has no schema", v1Descriptor.ID)
+		}
+		uris := make([]string, 0, len(v1Descriptor.Schema))
+		for _, s := range v1Descriptor.Schema {
+			uris = append(uris, s.URI)
+		}
+		var constraints Constraints
+		if v1Descriptor.Constraints != nil {
+			constraints = *v1Descriptor.Constraints
+		}
+		constraints.Fields = append([]Field{schemaField(uris)}, constraints.Fields...)
+		def.InputDescriptors = append(def.InputDescriptors, InputDescriptor{
+			ID:          v1Descriptor.ID,
+			Name:        v1Descriptor.Name,
+			Purpose:     v1Descriptor.Purpose,
+			Group:       v1Descriptor.Group,
+			Constraints: &constraints,
+		})
+	}
+	return &def, nil
+}
+
+// ConvertDefinitionToV1 converts a presentation definition to version 1, for verifiers which only support it. Each
+// input descriptor must have a field converted from version 1 schemas by ConvertDefinitionFromV1, and the definition
+// cannot use properties version 1 lacks: input descriptor formats, optional fields, intent to retain, or a frame.
+func ConvertDefinitionToV1(def PresentationDefinition) (*PresentationDefinitionV1, error) {
+	if def.Frame != nil {
+		return nil, errors.New("version 1 does not support JSON-LD frames")
+	}
+	v1 := PresentationDefinitionV1{
+		ID:                     def.ID,
+		Name:                   def.Name,
+		Purpose:                def.Purpose,
+		Format:                 def.Format,
+		SubmissionRequirements: def.SubmissionRequirements,
+	}
+	for _, descriptor := range def.InputDescriptors {
+		if descriptor.Format != nil {
+			return nil, fmt.Errorf("version 1 does not support the format of input descriptor<%s>", descriptor.ID)
+		}
+		if descriptor.Constraints == nil {
+			return nil, fmt.Errorf("input descriptor<%s> has no schema field, which version 1 requires", descriptor.ID)
+		}
+		constraints := *descriptor.Constraints
+		constraints.Fields = nil
+		var schemas []SchemaV1
+		for _, field := range descriptor.Constraints.Fields {
+			if uris, ok := schemaFieldURIs(field); ok && schemas == nil {
+				for _, uri := range uris {
+					schemas = append(schemas, SchemaV1{URI: uri})
+				}
+				continue
+			}
+			if field.Optional || field.IntentToRetain {
+				return nil, fmt.Errorf("version 1 does not support optional or retained field<%s> of input descriptor<%s>", field.ID, descriptor.ID)
+			}
+			// field names are only for display, so are dropped
+			field.Name = ""
+			constraints.Fields = append(constraints.Fields, field)
+		}
+		if len(schemas) == 0 {
+			return nil, fmt.Errorf("input descriptor<%s> has no schema field, which version 1 requires", descriptor.ID)
+		}
+		v1Descriptor := InputDescriptorV1{
+			ID:      descriptor.ID,
+			Name:    descriptor.Name,
+			Purpose: descriptor.Purpose,
+			Group:   descriptor.Group,
+			Schema:  schemas,
+		}
+		if !reflect.DeepEqual(constraints, Constraints{}) {
+			v1Descriptor.Constraints = &constraints
+		}
+		v1.InputDescriptors = append(v1.InputDescriptors, v1Descriptor)
+	}
+	return &v1, nil
+}
+
+// schemaField returns a field matching a credential whose schema ID, or one of whose types, is one of the URIs
+func schemaField(uris []string) Field {
+	return Field{
+		Path: append([]string{}, v1SchemaPaths...),
+		Filter: &Filter{
+			AnyOf: []any{
+				map[string]any{"type": "string", "enum": uris},
+				map[string]any{"type": "array", "contains": map[string]any{"type": "string", "enum": uris}},
+			},
+		},
+	}
+}
+
+// schemaFieldURIs returns the schema URIs of a field built by schemaField, and whether it is one
+func schemaFieldURIs(field Field) ([]string, bool) {
+	if !reflect.DeepEqual(field.Path, v1SchemaPaths) || field.Filter == nil || field.Filter.AnyOf == nil {
+		return nil, false
+	}
+	anyOfBytes, err := json.Marshal(field.Filter.AnyOf)
+	if err != nil {
+		return nil, false
+	}
+	var anyOf []struct {
+		Enum []string `json:"enum"`
+	}
+	if err = json.Unmarshal(anyOfBytes, &anyOf); err != nil || len(anyOf) == 0 || len(anyOf[0].Enum) == 0 {
+		return nil, false
+	}
+	return anyOf[0].Enum, true
+}
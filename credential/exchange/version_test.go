@@ -0,0 +1,80 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+const v1DefinitionJSON = `{
+	"presentation_definition": {
+		"id": "32f54163-7166-48f1-93d8-ff217bdb0653",
+		"input_descriptors": [
+			{
+				"id": "bankaccount_input",
+				"name": "Full Bank Account Routing Information",
+				"schema": [{"uri": "https://bank-standards.example.com/fullaccountroute.json"}],
+				"constraints": {
+					"fields": [{"path": ["$.credentialSubject.account[*].id"], "purpose": "We need your bank account"}]
+				}
+			}
+		]
+	}
+}`
+
+func TestDetectDefinitionVersion(t *testing.T) {
+	version, err := DetectDefinitionVersion([]byte(v1DefinitionJSON))
+	assert.NoError(t, err)
+	assert.Equal(t, V1, version)
+
+	v2, err := json.Marshal(getDummyPresentationDefinition())
+	require.NoError(t, err)
+	version, err = DetectDefinitionVersion(v2)
+	assert.NoError(t, err)
+	assert.Equal(t, V2, version)
+
+	_, err = DetectDefinitionVersion([]byte(`{"id": "no-descriptors"}`))
+	assert.ErrorContains(t, err, "has no input descriptors")
+}
+
+func TestConvertDefinitionVersions(t *testing.T) {
+	def, version, err := ParsePresentationDefinition([]byte(v1DefinitionJSON))
+	require.NoError(t, err)
+	assert.Equal(t, V1, version)
+	assert.NoError(t, def.IsValid())
+	fields := def.InputDescriptors[0].Constraints.Fields
+	require.Len(t, fields, 2)
+	assert.Equal(t, "$.credentialSubject.account[*].id", fields[1].Path[0])
+
+	t.Run("schema field matches credential types and schemas", func(tt *testing.T) {
+		schemaURI := "https://bank-standards.example.com/fullaccountroute.json"
+		_, _, err := fields[0].Match(map[string]any{"type": []any{credential.VerifiableCredentialType, schemaURI}})
+		assert.NoError(tt, err)
+		_, _, err = fields[0].Match(map[string]any{"credentialSchema": map[string]any{"id": schemaURI}})
+		assert.NoError(tt, err)
+		_, _, err = fields[0].Match(map[string]any{"type": []any{credential.VerifiableCredentialType}})
+		assert.Error(tt, err)
+	})
+
+	t.Run("round trip to version 1", func(tt *testing.T) {
+		v1, err := ConvertDefinitionToV1(*def)
+		require.NoError(tt, err)
+		require.Len(tt, v1.InputDescriptors, 1)
+		assert.Equal(tt, []SchemaV1{{URI: "https://bank-standards.example.com/fullaccountroute.json"}}, v1.InputDescriptors[0].Schema)
+		assert.Len(tt, v1.InputDescriptors[0].Constraints.Fields, 1)
+	})
+
+	t.Run("version 2 features", func(tt *testing.T) {
+		_, err := ConvertDefinitionToV1(getDummyPresentationDefinition())
+		assert.ErrorContains(tt, err, "no schema field")
+
+		withFrame := *def
+		withFrame.Frame = map[string]any{"type": "VerifiableCredential"}
+		_, err = ConvertDefinitionToV1(withFrame)
+		assert.ErrorContains(tt, err, "does not support JSON-LD frames")
+	})
+}
@@ -0,0 +1,147 @@
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// DefaultVerifyAllParallelism is the number of credentials VerifyAll verifies concurrently by default
+const DefaultVerifyAllParallelism = 8
+
+// VerifyAllOptions configures how VerifyAll verifies credentials
+type VerifyAllOptions struct {
+	// Parallelism is the number of credentials verified concurrently, defaulting to DefaultVerifyAllParallelism
+	Parallelism int
+	// Options are applied when verifying each credential, as they are by VerifyCredentialSignature
+	Options []VerificationOption
+}
+
+// CredentialVerificationResult is the result of verifying the signature of a single credential
+type CredentialVerificationResult struct {
+	// Index is the position of the credential in those given to VerifyAll
+	Index    int
+	Verified bool
+	Error    error
+}
+
+// VerificationResults are the results of verifying credentials with VerifyAll, in the order the credentials were given
+type VerificationResults []CredentialVerificationResult
+
+// AllVerified returns whether the signatures of all credentials were verified
+func (r VerificationResults) AllVerified() bool {
+	for _, result := range r {
+		if !result.Verified {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the results of credentials whose signatures could not be verified
+func (r VerificationResults) Failed() VerificationResults {
+	var failed VerificationResults
+	for _, result := range r {
+		if !result.Verified {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Error returns an error describing every credential which could not be verified, or nil if all were verified
+func (r VerificationResults) Error() error {
+	ae := util.NewAppendError()
+	for _, result := range r.Failed() {
+		ae.AppendString(fmt.Sprintf("credential<%d>: %s", result.Index, result.Error))
+	}
+	if ae.IsEmpty() {
+		return nil
+	}
+	return fmt.Errorf("<%d> credential(s) failed verification: %s", ae.NumErrors(), ae.Error().Error())
+}
+
+// VerifyAll verifies the signatures of many credentials of any type supported by VerifyCredentialSignature, such as
+// those in a large presentation or an audit backfill. Credentials are verified by a bounded pool of workers, and each
+// DID is resolved at most once, with its resolution shared by all credentials referencing it. A result is returned for
+// every credential; an error is only returned if the input is invalid. Credentials not verified before the context is
+// done fail with the context's error.
+func VerifyAll(ctx context.Context, creds []any, r resolution.Resolver, opts VerifyAllOptions) (VerificationResults, error) {
+	if r == nil {
+		return nil, errors.New("resolution cannot be empty")
+	}
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultVerifyAllParallelism
+	}
+
+	resolver := newCachingResolver(r)
+	results := make(VerificationResults, len(creds))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism && w < len(creds); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result := CredentialVerificationResult{Index: i}
+				if err := ctx.Err(); err != nil {
+					result.Error = err
+				} else {
+					result.Verified, result.Error = VerifyCredentialSignature(ctx, creds[i], resolver, opts.Options...)
+				}
+				results[i] = result
+			}
+		}()
+	}
+	for i := range creds {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results, nil
+}
+
+// cachingResolver resolves each DID at most once, sharing the result between concurrent callers. Resolution options
+// are not part of the cache key, so it is only used where DIDs are resolved without options.
+type cachingResolver struct {
+	resolver resolution.Resolver
+	mu       sync.Mutex
+	cache    map[string]*cachedResolution
+}
+
+type cachedResolution struct {
+	once   sync.Once
+	result *resolution.Result
+	err    error
+}
+
+var _ resolution.Resolver = (*cachingResolver)(nil)
+
+func newCachingResolver(r resolution.Resolver) *cachingResolver {
+	return &cachingResolver{resolver: r, cache: make(map[string]*cachedResolution)}
+}
+
+func (c *cachingResolver) Resolve(ctx context.Context, id string, opts ...resolution.Option) (*resolution.Result, error) {
+	c.mu.Lock()
+	cached, ok := c.cache[id]
+	if !ok {
+		cached = new(cachedResolution)
+		c.cache[id] = cached
+	}
+	c.mu.Unlock()
+	cached.once.Do(func() {
+		cached.result, cached.err = c.resolver.Resolve(ctx, id, opts...)
+	})
+	return cached.result, cached.err
+}
+
+func (c *cachingResolver) Methods() []did.Method {
+	return c.resolver.Methods()
+}
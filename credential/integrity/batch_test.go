@@ -0,0 +1,110 @@
+package integrity
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// countingResolver counts the DIDs it resolves
+type countingResolver struct {
+	resolution.Resolver
+	resolved atomic.Int32
+}
+
+func (c *countingResolver) Resolve(ctx context.Context, id string, opts ...resolution.Option) (*resolution.Result, error) {
+	c.resolved.Add(1)
+	return c.Resolver.Resolve(ctx, id, opts...)
+}
+
+func TestVerifyAll(t *testing.T) {
+	keyResolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	_, privKeyJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, privKey)
+	require.NoError(t, err)
+	signer, err := jws2020.NewJSONWebKeySigner(kid, *privKeyJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+	jwtSigner, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+	suite := jws2020.GetJSONWebSignature2020Suite()
+
+	var creds []any
+	for i := 0; i < 10; i++ {
+		cred := getTestCredential()
+		cred.Issuer = didKey.String()
+		require.NoError(t, suite.Sign(signer, &cred))
+		creds = append(creds, cred)
+	}
+	creds = append(creds, getTestJWTCredential(t, *jwtSigner))
+
+	t.Run("all verified", func(tt *testing.T) {
+		resolver := &countingResolver{Resolver: keyResolver}
+		results, err := VerifyAll(context.Background(), creds, resolver, VerifyAllOptions{Parallelism: 4})
+		assert.NoError(tt, err)
+		assert.Len(tt, results, len(creds))
+		assert.True(tt, results.AllVerified())
+		assert.NoError(tt, results.Error())
+		// every credential has the same issuer, which is only resolved once
+		assert.Equal(tt, int32(1), resolver.resolved.Load())
+	})
+
+	t.Run("some not verified", func(tt *testing.T) {
+		tampered := getTestCredential()
+		tampered.Issuer = didKey.String()
+		require.NoError(tt, suite.Sign(signer, &tampered))
+		tampered.IssuanceDate = "2022-01-01T19:23:24Z"
+		mixed := append([]any{tampered, getTestCredential()}, creds...)
+
+		results, err := VerifyAll(context.Background(), mixed, keyResolver, VerifyAllOptions{})
+		assert.NoError(tt, err)
+		assert.False(tt, results.AllVerified())
+		failed := results.Failed()
+		require.Len(tt, failed, 2)
+		assert.Equal(tt, 0, failed[0].Index)
+		assert.Equal(tt, 1, failed[1].Index)
+		assert.ErrorContains(tt, failed[1].Error, "credential must have a proof")
+		assert.ErrorContains(tt, results.Error(), "<2> credential(s) failed verification")
+	})
+
+	t.Run("verification options", func(tt *testing.T) {
+		jwtCred := creds[len(creds)-1]
+		results, err := VerifyAll(context.Background(), []any{jwtCred}, keyResolver, VerifyAllOptions{Options: []VerificationOption{WithIssuer(didKey.String())}})
+		assert.NoError(tt, err)
+		assert.True(tt, results.AllVerified())
+
+		results, err = VerifyAll(context.Background(), []any{jwtCred}, keyResolver, VerifyAllOptions{Options: []VerificationOption{WithIssuer("did:example:other")}})
+		assert.NoError(tt, err)
+		require.Len(tt, results.Failed(), 1)
+		assert.ErrorContains(tt, results[0].Error, "validating JWT claims")
+	})
+
+	t.Run("cancelled context", func(tt *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results, err := VerifyAll(ctx, creds, keyResolver, VerifyAllOptions{})
+		assert.NoError(tt, err)
+		assert.Len(tt, results.Failed(), len(creds))
+		assert.ErrorIs(tt, results[0].Error, context.Canceled)
+	})
+
+	t.Run("no resolver", func(tt *testing.T) {
+		_, err := VerifyAll(context.Background(), creds, nil, VerifyAllOptions{})
+		assert.ErrorContains(tt, err, "resolution cannot be empty")
+	})
+}
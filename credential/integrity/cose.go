@@ -0,0 +1,85 @@
+package integrity
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/veraison/go-cose"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+// Securing v2.0 credentials with COSE https://www.w3.org/TR/vc-jose-cose/#securing-with-cose
+// The credential is the payload of a COSE_Sign1 message, as with JOSE.
+
+const (
+	// VCCOSEType is the `typ` header of a credential secured with COSE
+	VCCOSEType string = "application/vc+cose"
+	// VCCOSEContentType is the content type header of a credential secured with COSE
+	VCCOSEContentType string = "application/vc"
+
+	// coseHeaderLabelType is the label of the `typ` header https://www.rfc-editor.org/rfc/rfc9596
+	coseHeaderLabelType int64 = 16
+)
+
+// SignVerifiableCredentialCOSE secures a v2.0 credential as a COSE_Sign1 message according to
+// https://www.w3.org/TR/vc-jose-cose/#securing-vcs-with-cose
+func SignVerifiableCredentialCOSE(signer jwx.Signer, cred credential.VerifiableCredential) ([]byte, error) {
+	payload, err := securedCredentialPayload(cred)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := cose.Headers{
+		Protected: cose.ProtectedHeader{
+			coseHeaderLabelType:         VCCOSEType,
+			cose.HeaderLabelContentType: VCCOSEContentType,
+		},
+	}
+	if signer.KID != "" {
+		headers.Protected[cose.HeaderLabelKeyID] = []byte(signer.KID)
+	}
+	msg, err := crypto.SignCOSESign1(signer.PrivateKey, headers, payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing COSE credential")
+	}
+	signed, err := msg.MarshalCBOR()
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding COSE credential")
+	}
+	return signed, nil
+}
+
+// VerifyVerifiableCredentialCOSE verifies the signature of a credential secured with COSE and parses its credential
+func VerifyVerifiableCredentialCOSE(verifier jwx.Verifier, data []byte) (*cose.Sign1Message, *credential.VerifiableCredential, error) {
+	msg, cred, err := ParseVerifiableCredentialFromCOSE(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKey, err := verifier.PublicKeyJWK.ToPublicKey()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "getting verifier public key")
+	}
+	if err = crypto.VerifyCOSESign1(msg, publicKey); err != nil {
+		return nil, nil, errors.Wrap(err, "verifying COSE credential")
+	}
+	return msg, cred, nil
+}
+
+// ParseVerifiableCredentialFromCOSE parses the credential of a COSE_Sign1 message without verifying its signature
+func ParseVerifiableCredentialFromCOSE(data []byte) (*cose.Sign1Message, *credential.VerifiableCredential, error) {
+	var msg cose.Sign1Message
+	if err := msg.UnmarshalCBOR(data); err != nil {
+		return nil, nil, errors.Wrap(err, "parsing COSE credential")
+	}
+	if typ := msg.Headers.Protected[coseHeaderLabelType]; typ != VCCOSEType {
+		return nil, nil, fmt.Errorf("COSE credential typ<%v> must be %s", typ, VCCOSEType)
+	}
+	cred, err := parseSecuredCredentialPayload(msg.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &msg, cred, nil
+}
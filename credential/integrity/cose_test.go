@@ -0,0 +1,71 @@
+package integrity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veraison/go-cose"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+func TestVerifiableCredentialCOSE(t *testing.T) {
+	testCredential := getTestV2Credential()
+
+	for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.P256, crypto.P384} {
+		t.Run(string(kt), func(tt *testing.T) {
+			_, privKey, err := crypto.GenerateKeyByKeyType(kt)
+			assert.NoError(tt, err)
+			kid := "key-1"
+			signer, err := jwx.NewJWXSigner("did:example:123", &kid, privKey)
+			assert.NoError(tt, err)
+			verifier, err := signer.ToVerifier(signer.ID)
+			assert.NoError(tt, err)
+
+			signed, err := SignVerifiableCredentialCOSE(*signer, testCredential)
+			assert.NoError(tt, err)
+
+			msg, cred, err := VerifyVerifiableCredentialCOSE(*verifier, signed)
+			assert.NoError(tt, err)
+			assert.Equal(tt, &testCredential, cred)
+			assert.Equal(tt, VCCOSEContentType, msg.Headers.Protected[cose.HeaderLabelContentType])
+			assert.Equal(tt, []byte(kid), msg.Headers.Protected[cose.HeaderLabelKeyID])
+		})
+	}
+
+	t.Run("tampered credential", func(tt *testing.T) {
+		signer := getTestVectorKey0Signer(tt)
+		verifier, err := signer.ToVerifier(signer.ID)
+		assert.NoError(tt, err)
+		signed, err := SignVerifiableCredentialCOSE(signer, testCredential)
+		assert.NoError(tt, err)
+
+		var msg cose.Sign1Message
+		assert.NoError(tt, msg.UnmarshalCBOR(signed))
+		msg.Payload = append(msg.Payload[:len(msg.Payload)-1], []byte(`,"extra":true}`)...)
+		tampered, err := msg.MarshalCBOR()
+		assert.NoError(tt, err)
+
+		_, _, err = VerifyVerifiableCredentialCOSE(*verifier, tampered)
+		assert.ErrorContains(tt, err, "verifying COSE credential")
+	})
+
+	t.Run("other COSE messages are rejected", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		assert.NoError(tt, err)
+		msg, err := crypto.SignCOSESign1(privKey, cose.Headers{}, []byte(`{}`))
+		assert.NoError(tt, err)
+		signed, err := msg.MarshalCBOR()
+		assert.NoError(tt, err)
+
+		_, _, err = ParseVerifiableCredentialFromCOSE(signed)
+		assert.ErrorContains(tt, err, "must be application/vc+cose")
+
+		v1Credential := testCredential
+		v1Credential.Context = credential.VerifiableCredentialsLinkedDataContext
+		_, err = SignVerifiableCredentialCOSE(getTestVectorKey0Signer(tt), v1Credential)
+		assert.ErrorContains(tt, err, "must have the base context")
+	})
+}
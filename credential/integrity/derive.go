@@ -0,0 +1,52 @@
+package integrity
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+)
+
+// DeriveCredential selectively discloses a credential signed with a DerivableSuite, such as a BBS+ suite. The reveal
+// document is a JSON-LD frame matching the claims to disclose; if it has no @context, the credential's contexts are
+// used. The derived credential contains only the framed claims and carries a proof derived from the credential's base
+// proof, which verifiers check using the suite's Verify method. The given credential is not modified.
+// This is currently experimental, like cryptosuite.DerivableSuite, which this SDK has no implementation of.
+func DeriveCredential(suite cryptosuite.DerivableSuite, cred credential.VerifiableCredential, revealDocument map[string]any, opts ...cryptosuite.Option) (*credential.VerifiableCredential, error) {
+	if suite == nil {
+		return nil, errors.New("suite cannot be empty")
+	}
+	if cred.IsEmpty() {
+		return nil, errors.New("credential cannot be empty")
+	}
+	if cred.GetProof() == nil {
+		return nil, errors.New("credential must have a single base proof to derive from")
+	}
+	if len(revealDocument) == 0 {
+		return nil, errors.New("reveal document cannot be empty")
+	}
+
+	frame := make(map[string]any, len(revealDocument)+1)
+	for k, v := range revealDocument {
+		frame[k] = v
+	}
+	if _, ok := frame["@context"]; !ok {
+		frame["@context"] = cred.Context
+	}
+
+	derived, err := suite.DeriveProof(&cred, frame, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "deriving proof for credential<%s>", cred.ID)
+	}
+	var derivedCred credential.VerifiableCredential
+	if err = derived.ToDocument(&derivedCred); err != nil {
+		return nil, errors.Wrap(err, "reconstructing derived credential")
+	}
+	if err = derivedCred.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "derived credential is not valid; the reveal document must keep its required properties")
+	}
+	if derivedCred.GetProof() == nil {
+		return nil, errors.New("derived credential does not have a proof")
+	}
+	return &derivedCred, nil
+}
@@ -0,0 +1,105 @@
+package integrity
+
+import (
+	gocrypto "crypto"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+)
+
+// testDerivableSuite derives proofs recording which statements of the base document were revealed
+type testDerivableSuite struct{}
+
+func (testDerivableSuite) ID() string                                    { return "test-derivable-suite" }
+func (testDerivableSuite) Type() cryptosuite.LDKeyType                   { return cryptosuite.MultikeyType }
+func (testDerivableSuite) CanonicalizationAlgorithm() string             { return "" }
+func (testDerivableSuite) MessageDigestAlgorithm() gocrypto.Hash         { return gocrypto.SHA256 }
+func (testDerivableSuite) SignatureAlgorithm() cryptosuite.SignatureType { return "TestSignature2020" }
+func (testDerivableSuite) RequiredContexts() []string                    { return nil }
+func (testDerivableSuite) Sign(cryptosuite.Signer, cryptosuite.WithEmbeddedProof, ...cryptosuite.Option) error {
+	return nil
+}
+func (testDerivableSuite) Verify(cryptosuite.Verifier, cryptosuite.WithEmbeddedProof, ...cryptosuite.Option) error {
+	return nil
+}
+
+func (testDerivableSuite) DeriveProof(p cryptosuite.WithEmbeddedProof, revealDocument map[string]any, _ ...cryptosuite.Option) (*cryptosuite.GenericProvable, error) {
+	revealed, err := cryptosuite.RevealStatements(p, revealDocument, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	derived := cryptosuite.GenericProvable(revealed.Document)
+	derived.SetProof(&crypto.Proof{
+		Type:               "TestSignatureProof2020",
+		VerificationMethod: p.GetProof().VerificationMethod,
+		ProofPurpose:       p.GetProof().ProofPurpose,
+		ProofValue:         fmt.Sprint(revealed.RevealIndexes),
+	})
+	return &derived, nil
+}
+
+func TestDeriveCredential(t *testing.T) {
+	cred := credential.VerifiableCredential{
+		Context:      []any{credential.VerifiableCredentialsLinkedDataContext, "https://www.w3.org/2018/credentials/examples/v1"},
+		ID:           "http://example.edu/credentials/1872",
+		Type:         []any{credential.VerifiableCredentialType, "UniversityDegreeCredential"},
+		Issuer:       "did:example:issuer",
+		IssuanceDate: "2010-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{
+			"id":   "did:example:subject",
+			"name": "Jayden Doe",
+			"degree": map[string]any{
+				"type": "BachelorDegree",
+				"name": "Bachelor of Science and Arts",
+			},
+		},
+		Proof: &crypto.Proof{
+			Type:               "TestSignature2020",
+			VerificationMethod: "did:example:issuer#key-1",
+			ProofPurpose:       "assertionMethod",
+			ProofValue:         "z123",
+		},
+	}
+	revealDocument := map[string]any{
+		"type": []any{credential.VerifiableCredentialType, "UniversityDegreeCredential"},
+		"credentialSubject": map[string]any{
+			"@explicit": true,
+			"degree":    map[string]any{},
+		},
+	}
+
+	t.Run("derive credential", func(tt *testing.T) {
+		derived, err := DeriveCredential(testDerivableSuite{}, cred, revealDocument)
+		assert.NoError(tt, err)
+		require.NotNil(tt, derived)
+		assert.Equal(tt, cred.ID, derived.ID)
+		assert.Equal(tt, cred.IssuerID(), derived.IssuerID())
+		assert.Contains(tt, derived.CredentialSubject, "degree")
+		assert.NotContains(tt, derived.CredentialSubject, "name")
+		assert.Equal(tt, "TestSignatureProof2020", derived.GetProof().Type)
+		assert.NotEmpty(tt, derived.GetProof().ProofValue)
+
+		// the credential is not modified
+		assert.Contains(tt, cred.CredentialSubject, "name")
+		assert.Equal(tt, "TestSignature2020", cred.GetProof().Type)
+	})
+
+	t.Run("bad input", func(tt *testing.T) {
+		_, err := DeriveCredential(nil, cred, revealDocument)
+		assert.ErrorContains(tt, err, "suite cannot be empty")
+
+		_, err = DeriveCredential(testDerivableSuite{}, cred, nil)
+		assert.ErrorContains(tt, err, "reveal document cannot be empty")
+
+		unsigned := cred
+		unsigned.Proof = nil
+		_, err = DeriveCredential(testDerivableSuite{}, unsigned, revealDocument)
+		assert.ErrorContains(tt, err, "credential must have a single base proof to derive from")
+	})
+}
@@ -0,0 +1,181 @@
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// EnvelopeVerifiableCredentialJOSE secures a v2.0 credential as a `vc+jwt`, and envelops it so it can be included in
+// a presentation https://www.w3.org/TR/vc-data-model-2.0/#enveloped-verifiable-credentials
+func EnvelopeVerifiableCredentialJOSE(signer jwx.Signer, cred credential.VerifiableCredential) (*credential.EnvelopedVerifiableCredential, error) {
+	signed, err := SignVerifiableCredentialJOSE(signer, cred)
+	if err != nil {
+		return nil, err
+	}
+	envelope := credential.NewEnvelopedVerifiableCredential(credential.VCJWTMediaType, signed)
+	return &envelope, nil
+}
+
+// EnvelopeVerifiablePresentationJOSE secures a v2.0 presentation as a `vp+jwt`, and envelops it
+// https://www.w3.org/TR/vc-data-model-2.0/#enveloped-verifiable-presentations
+func EnvelopeVerifiablePresentationJOSE(signer jwx.Signer, parameters *JWTVVPParameters, pres credential.VerifiablePresentation) (*credential.EnvelopedVerifiablePresentation, error) {
+	signed, err := SignVerifiablePresentationJOSE(signer, parameters, pres)
+	if err != nil {
+		return nil, err
+	}
+	envelope := credential.NewEnvelopedVerifiablePresentation(credential.VPJWTMediaType, signed)
+	return &envelope, nil
+}
+
+// ParseEnvelopedVerifiableCredential parses the `vc+jwt` or `vc+sd-jwt` credential carried by an envelope, without
+// verifying its signature
+func ParseEnvelopedVerifiableCredential(envelope credential.EnvelopedVerifiableCredential) (jws.Headers, *credential.VerifiableCredential, error) {
+	mediaType, secured, err := envelope.Content()
+	if err != nil {
+		return nil, nil, err
+	}
+	switch mediaType {
+	case credential.VCJWTMediaType:
+		return ParseVerifiableCredentialFromJOSE(string(secured))
+	case credential.VCSDJWTMediaType:
+		return ParseVerifiableCredentialFromSDJWT(string(secured))
+	}
+	return nil, nil, fmt.Errorf("unsupported enveloped credential media type<%s>", mediaType)
+}
+
+// ParseEnvelopedVerifiablePresentation parses the `vp+jwt` presentation carried by an envelope, without verifying its
+// signature
+func ParseEnvelopedVerifiablePresentation(envelope credential.EnvelopedVerifiablePresentation) (jws.Headers, *credential.VerifiablePresentation, error) {
+	mediaType, secured, err := envelope.Content()
+	if err != nil {
+		return nil, nil, err
+	}
+	if mediaType != credential.VPJWTMediaType {
+		return nil, nil, fmt.Errorf("unsupported enveloped presentation media type<%s>", mediaType)
+	}
+	return ParseVerifiablePresentationFromJOSE(string(secured))
+}
+
+// VerifyEnvelopedCredential verifies the signature of the `vc+jwt` or `vc+sd-jwt` credential carried by an envelope,
+// using the key of the credential's issuer matching the KID in its header. If the WithStatusCheck option is
+// provided, the credential's status is checked once its signature has been verified.
+func VerifyEnvelopedCredential(ctx context.Context, envelope credential.EnvelopedVerifiableCredential, r resolution.Resolver, opts ...VerificationOption) (bool, error) {
+	if r == nil {
+		return false, errors.New("resolution cannot be empty")
+	}
+	mediaType, secured, err := envelope.Content()
+	if err != nil {
+		return false, err
+	}
+	headers, cred, err := ParseEnvelopedVerifiableCredential(envelope)
+	if err != nil {
+		return false, errors.Wrap(err, "parsing enveloped credential")
+	}
+	verifier, err := resolveEnvelopeVerifier(ctx, r, headers, cred.IssuerID())
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting key to verify credential<%s>", cred.ID)
+	}
+	if mediaType == credential.VCSDJWTMediaType {
+		_, _, err = VerifyVerifiableCredentialSDJWT(*verifier, string(secured))
+	} else {
+		_, _, err = VerifyVerifiableCredentialJOSE(*verifier, string(secured))
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "error verifying credential<%s>", cred.ID)
+	}
+	if err = checkStatus(ctx, *cred, opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// VerifyEnvelopedPresentation verifies the signature of the `vp+jwt` presentation carried by an envelope, using the
+// key of the presentation's holder matching the KID in its header, and the signatures of the credentials it contains.
+// The WithChallenge and WithDomain options are checked against the presentation's `nonce` and `aud` claims.
+func VerifyEnvelopedPresentation(ctx context.Context, envelope credential.EnvelopedVerifiablePresentation, r resolution.Resolver, opts ...VerificationOption) (bool, error) {
+	if r == nil {
+		return false, errors.New("resolution cannot be empty")
+	}
+	mediaType, secured, err := envelope.Content()
+	if err != nil {
+		return false, err
+	}
+	if mediaType != credential.VPJWTMediaType {
+		return false, fmt.Errorf("unsupported enveloped presentation media type<%s>", mediaType)
+	}
+	headers, pres, payload, err := parseJOSEPresentation(string(secured))
+	if err != nil {
+		return false, errors.Wrap(err, "parsing enveloped presentation")
+	}
+	verifier, err := resolveEnvelopeVerifier(ctx, r, headers, pres.Holder)
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting key to verify presentation<%s>", pres.ID)
+	}
+	if err = verifier.VerifyJWS(string(secured)); err != nil {
+		return false, errors.Wrapf(err, "error verifying presentation<%s>", pres.ID)
+	}
+	if challenge, ok := getVerificationOption(opts, ChallengeOption); ok && payload[NonceProperty] != challenge {
+		return false, errors.Errorf("challenge mismatch: expected [%s], got [%v]", challenge, payload[NonceProperty])
+	}
+	if domain, ok := getVerificationOption(opts, DomainOption); ok && !audienceContains(payload["aud"], domain) {
+		return false, errors.Errorf("domain mismatch: expected [%s], got [%v]", domain, payload["aud"])
+	}
+	for i, cred := range pres.VerifiableCredential {
+		verified, err := VerifyCredentialSignature(ctx, cred, r, opts...)
+		if err != nil {
+			return false, errors.Wrapf(err, "verifying credential %d", i)
+		}
+		if !verified {
+			return false, errors.Errorf("credential %d failed signature validation", i)
+		}
+	}
+	return true, nil
+}
+
+// resolveEnvelopeVerifier constructs a verifier with the key matching the KID of a secured credential or
+// presentation, which must be a key of the DID which secured it: the credential's issuer or the presentation's holder.
+func resolveEnvelopeVerifier(ctx context.Context, r resolution.Resolver, headers jws.Headers, signerDID string) (*jwx.Verifier, error) {
+	kid := headers.KeyID()
+	if kid == "" {
+		return nil, errors.New("missing kid in header")
+	}
+	if signerDID == "" {
+		return nil, fmt.Errorf("cannot resolve kid<%s> without a DID", kid)
+	}
+	if kidDID, _, _ := strings.Cut(kid, "#"); strings.HasPrefix(kid, "did:") && kidDID != signerDID {
+		return nil, fmt.Errorf("kid<%s> is not a key of DID<%s>", kid, signerDID)
+	}
+	resolved, err := r.Resolve(ctx, signerDID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving DID<%s>", signerDID)
+	}
+	key, err := did.GetKeyFromVerificationMethod(resolved.Document, kid)
+	if err != nil {
+		return nil, err
+	}
+	return jwx.NewJWXVerifier(resolved.Document.ID, &kid, key)
+}
+
+// audienceContains returns whether an `aud` claim, which is a string or set of strings, contains the domain
+func audienceContains(aud any, domain string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == domain
+	case []any:
+		for _, v := range a {
+			if v == domain {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package integrity
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestEnvelopedVerifiableCredential(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	issuer := getTestDIDKeySigner(t)
+	holder := getTestDIDKeySigner(t)
+
+	cred := getTestV2Credential()
+	cred.Issuer = issuer.ID
+
+	t.Run("vc+jwt", func(tt *testing.T) {
+		envelope, err := EnvelopeVerifiableCredentialJOSE(issuer, cred)
+		require.NoError(tt, err)
+		assert.Equal(tt, credential.EnvelopedVerifiableCredentialType, envelope.Type)
+		assert.True(tt, strings.HasPrefix(envelope.ID, "data:application/vc+jwt,ey"))
+
+		_, parsed, err := ParseEnvelopedVerifiableCredential(*envelope)
+		require.NoError(tt, err)
+		assert.Equal(tt, cred.ID, parsed.ID)
+
+		verified, err := VerifyCredentialSignature(context.Background(), envelope, resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		// as JSON, as it would be within a presentation
+		envelopeJSON, err := json.Marshal(envelope)
+		require.NoError(tt, err)
+		verified, err = VerifyCredentialSignature(context.Background(), envelopeJSON, resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		// signed by someone other than the issuer
+		forged := cred
+		forged.Issuer = holder.ID
+		forgedEnvelope, err := EnvelopeVerifiableCredentialJOSE(issuer, forged)
+		require.NoError(tt, err)
+		_, err = VerifyCredentialSignature(context.Background(), forgedEnvelope, resolver)
+		assert.Error(tt, err)
+	})
+
+	t.Run("vc+sd-jwt", func(tt *testing.T) {
+		token := getTestSDJWTCredential(tt, issuer, cred, "alumniOf")
+		assert.True(tt, IsVerifiableCredentialSDJWT(token))
+
+		_, disclosed, err := ParseVerifiableCredentialFromSDJWT(token)
+		require.NoError(tt, err)
+		assert.Equal(tt, "Example University", disclosed.CredentialSubject["alumniOf"])
+
+		// without its disclosure the claim is left out
+		issuerJWT, _, _ := strings.Cut(token, "~")
+		_, undisclosed, err := ParseVerifiableCredentialFromSDJWT(issuerJWT + "~")
+		require.NoError(tt, err)
+		assert.NotContains(tt, undisclosed.CredentialSubject, "alumniOf")
+		assert.NotContains(tt, undisclosed.CredentialSubject, "_sd")
+
+		envelope := credential.NewEnvelopedVerifiableCredential(credential.VCSDJWTMediaType, []byte(token))
+		verified, err := VerifyEnvelopedCredential(context.Background(), envelope, resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		// only claims of the credential subject can be disclosed
+		_, err = SignVerifiableCredentialSDJWT(issuer, cred, "missing")
+		assert.ErrorContains(tt, err, "credential subject has no claim<missing> to disclose")
+
+		// disclosures may only be presented once
+		disclosure := strings.Split(token, "~")[1]
+		_, _, err = ParseVerifiableCredentialFromSDJWT(issuerJWT + "~" + disclosure + "~" + disclosure + "~")
+		assert.ErrorContains(tt, err, "is presented more than once")
+
+		// disclosures of another SD-JWT are not referenced by this one
+		other := getTestSDJWTCredential(tt, issuer, cred, "alumniOf")
+		_, _, err = ParseVerifiableCredentialFromSDJWT(issuerJWT + "~" + strings.Split(other, "~")[1] + "~")
+		assert.ErrorContains(tt, err, "is not referenced by the SD-JWT")
+
+		// digests may only appear once
+		disclosures, err := newSDDisclosures(nil)
+		require.NoError(tt, err)
+		_, err = disclosures.apply(map[string]any{
+			"_sd":    []any{"digest"},
+			"nested": map[string]any{"_sd": []any{"digest"}},
+		}, make(map[string]bool))
+		assert.ErrorContains(tt, err, "SD-JWT digest<digest> appears more than once")
+	})
+
+	t.Run("vc+sd-jwt confirmation key", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		holderKey, err := jwx.NewJWXSigner("holder", nil, privKey)
+		require.NoError(tt, err)
+		holderJWK := holderKey.PrivateKeyJWK.ToPublicKeyJWK()
+
+		_, err = SignVerifiableCredentialSDJWTWithConfirmationKey(issuer, cred, jwx.PublicKeyJWK{}, "alumniOf")
+		assert.ErrorContains(tt, err, "holder key cannot be empty")
+
+		signed, err := SignVerifiableCredentialSDJWTWithConfirmationKey(issuer, cred, holderJWK, "alumniOf")
+		require.NoError(tt, err)
+		issuerJWT, _, _ := strings.Cut(string(signed), "~")
+		_, payload, err := parseSDJWTIssuerJWT(issuerJWT)
+		require.NoError(tt, err)
+		confirmationKey, err := getConfirmationKey(payload["cnf"])
+		require.NoError(tt, err)
+		require.NotNil(tt, confirmationKey)
+		assert.Equal(tt, holderJWK.X, confirmationKey.X)
+
+		_, disclosed, err := ParseVerifiableCredentialFromSDJWT(string(signed))
+		require.NoError(tt, err)
+		assert.Equal(tt, "Example University", disclosed.CredentialSubject["alumniOf"])
+
+		// the key binding JWT is verified against the confirmation key, not a key of the credential subject
+		presented, err := AddSDJWTKeyBinding(*holderKey, string(signed), "did:example:verifier", "nonce")
+		require.NoError(tt, err)
+		assert.NoError(tt, VerifySDJWTKeyBinding(context.Background(), presented, resolver, "did:example:verifier", "nonce"))
+
+		forged, err := AddSDJWTKeyBinding(holder, string(signed), "did:example:verifier", "nonce")
+		require.NoError(tt, err)
+		err = VerifySDJWTKeyBinding(context.Background(), forged, resolver, "did:example:verifier", "nonce")
+		assert.ErrorContains(tt, err, "verifying key binding JWT")
+	})
+
+	t.Run("vc+sd-jwt key binding", func(tt *testing.T) {
+		holderCred := cred
+		holderCred.CredentialSubject = credential.CredentialSubject{"id": holder.ID, "alumniOf": "Example University", "degree": "BSc"}
+		signed, err := SignVerifiableCredentialSDJWT(issuer, holderCred, "alumniOf", "degree")
+		require.NoError(tt, err)
+
+		selected, err := SelectSDJWTDisclosures(string(signed), "alumniOf")
+		require.NoError(tt, err)
+		assert.Equal(tt, 2, strings.Count(selected, "~"))
+		_, disclosed, err := ParseVerifiableCredentialFromSDJWT(selected)
+		require.NoError(tt, err)
+		assert.Equal(tt, "Example University", disclosed.CredentialSubject["alumniOf"])
+		assert.NotContains(tt, disclosed.CredentialSubject, "degree")
+
+		presented, err := AddSDJWTKeyBinding(holder, selected, "did:example:verifier", "nonce")
+		require.NoError(tt, err)
+		assert.True(tt, strings.HasPrefix(presented, selected))
+		assert.NoError(tt, VerifySDJWTKeyBinding(context.Background(), presented, resolver, "did:example:verifier", "nonce"))
+		verified, err := VerifyCredentialSignature(context.Background(), presented, resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		err = VerifySDJWTKeyBinding(context.Background(), presented, resolver, "did:example:other", "nonce")
+		assert.ErrorContains(tt, err, "does not contain [did:example:other]")
+		err = VerifySDJWTKeyBinding(context.Background(), presented, resolver, "did:example:verifier", "other")
+		assert.ErrorContains(tt, err, "challenge mismatch")
+		err = VerifySDJWTKeyBinding(context.Background(), selected, resolver, "did:example:verifier", "nonce")
+		assert.ErrorContains(tt, err, "SD-JWT has no key binding JWT")
+
+		// the key binding JWT covers the disclosures presented with it
+		kbJWT := strings.TrimPrefix(presented, selected)
+		err = VerifySDJWTKeyBinding(context.Background(), string(signed)+kbJWT, resolver, "did:example:verifier", "nonce")
+		assert.ErrorContains(tt, err, "sd_hash is not the digest of the SD-JWT")
+
+		// only the credential subject can bind it
+		forged, err := AddSDJWTKeyBinding(issuer, selected, "did:example:verifier", "nonce")
+		require.NoError(tt, err)
+		err = VerifySDJWTKeyBinding(context.Background(), forged, resolver, "did:example:verifier", "nonce")
+		assert.ErrorContains(tt, err, "is not a key of the credential subject")
+	})
+
+	t.Run("unsupported media type", func(tt *testing.T) {
+		envelope := credential.NewEnvelopedVerifiableCredential("application/vc+cose", []byte("abc"))
+		_, _, err := ParseEnvelopedVerifiableCredential(envelope)
+		assert.ErrorContains(tt, err, "unsupported enveloped credential media type<application/vc+cose>")
+	})
+}
+
+func TestEnvelopedVerifiablePresentation(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	issuer := getTestDIDKeySigner(t)
+	holder := getTestDIDKeySigner(t)
+
+	cred := getTestV2Credential()
+	cred.Issuer = issuer.ID
+	credEnvelope, err := EnvelopeVerifiableCredentialJOSE(issuer, cred)
+	require.NoError(t, err)
+
+	pres := credential.VerifiablePresentation{
+		Context:              []any{credential.VerifiableCredentialsV2LinkedDataContext},
+		Type:                 []any{credential.VerifiablePresentationType},
+		Holder:               holder.ID,
+		VerifiableCredential: []any{credEnvelope},
+	}
+	envelope, err := EnvelopeVerifiablePresentationJOSE(holder, &JWTVVPParameters{Challenge: "challenge", Domain: "verifier.example"}, pres)
+	require.NoError(t, err)
+
+	t.Run("verify with challenge and domain", func(tt *testing.T) {
+		verified, err := VerifyPresentationSignature(context.Background(), envelope, resolver, WithChallenge("challenge"), WithDomain("verifier.example"))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		_, err = VerifyPresentationSignature(context.Background(), envelope, resolver, WithChallenge("other"))
+		assert.ErrorContains(tt, err, "challenge mismatch")
+
+		_, err = VerifyPresentationSignature(context.Background(), envelope, resolver, WithDomain("other.example"))
+		assert.ErrorContains(tt, err, "domain mismatch")
+	})
+
+	t.Run("parse", func(tt *testing.T) {
+		_, parsed, err := ParseEnvelopedVerifiablePresentation(*envelope)
+		require.NoError(tt, err)
+		assert.Equal(tt, holder.ID, parsed.Holder)
+		require.Len(tt, parsed.VerifiableCredential, 1)
+		parsedEnvelope, ok := credential.ToEnvelopedVerifiableCredential(parsed.VerifiableCredential[0])
+		assert.True(tt, ok)
+		assert.Equal(tt, credEnvelope.ID, parsedEnvelope.ID)
+	})
+
+	t.Run("presentation with a forged credential", func(tt *testing.T) {
+		forged := cred
+		forged.Issuer = holder.ID
+		forgedEnvelope, err := EnvelopeVerifiableCredentialJOSE(issuer, forged)
+		require.NoError(tt, err)
+		forgedPres := pres
+		forgedPres.VerifiableCredential = []any{forgedEnvelope}
+		signed, err := SignVerifiablePresentationJOSE(holder, nil, forgedPres)
+		require.NoError(tt, err)
+
+		_, err = VerifyPresentationSignature(context.Background(), string(signed), resolver)
+		assert.ErrorContains(tt, err, "verifying credential 0")
+	})
+}
+
+func getTestDIDKeySigner(t *testing.T) jwx.Signer {
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+	return *signer
+}
+
+// getTestSDJWTCredential secures a credential as a `vc+sd-jwt`, making the given subject claim selectively disclosable
+func getTestSDJWTCredential(t *testing.T, signer jwx.Signer, cred credential.VerifiableCredential, claim string) string {
+	signed, err := SignVerifiableCredentialSDJWT(signer, cred, claim)
+	require.NoError(t, err)
+	return string(signed)
+}
@@ -0,0 +1,227 @@
+package integrity
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// Securing v2.0 credentials with JOSE https://www.w3.org/TR/vc-jose-cose/#securing-with-jose
+// Unlike v1.1 VC-JWTs, the payload of the JWT is the credential itself: no properties are mapped to JWT claims, and
+// there is no `vc` claim.
+
+const (
+	// VCJOSEType is the `typ` header of a credential secured with JOSE
+	VCJOSEType string = "vc+jwt"
+	// VCJOSEContentType is the `cty` header of a credential secured with JOSE
+	VCJOSEContentType string = "vc"
+	// VPJOSEType is the `typ` header of a presentation secured with JOSE
+	VPJOSEType string = "vp+jwt"
+	// VPJOSEContentType is the `cty` header of a presentation secured with JOSE
+	VPJOSEContentType string = "vp"
+)
+
+// SignVerifiableCredentialJOSE secures a v2.0 credential as a `vc+jwt` according to
+// https://www.w3.org/TR/vc-jose-cose/#securing-vcs-with-jose
+func SignVerifiableCredentialJOSE(signer jwx.Signer, cred credential.VerifiableCredential) ([]byte, error) {
+	payload, err := securedCredentialPayload(cred)
+	if err != nil {
+		return nil, err
+	}
+	return signJOSE(signer, payload, VCJOSEType, VCJOSEContentType, "credential")
+}
+
+// VerifyVerifiableCredentialJOSE verifies the signature of a `vc+jwt` and parses its credential
+func VerifyVerifiableCredentialJOSE(verifier jwx.Verifier, token string) (jws.Headers, *credential.VerifiableCredential, error) {
+	if err := verifier.VerifyJWS(token); err != nil {
+		return nil, nil, errors.Wrap(err, "verifying JOSE credential")
+	}
+	return ParseVerifiableCredentialFromJOSE(token)
+}
+
+// ParseVerifiableCredentialFromJOSE parses the credential of a `vc+jwt` without verifying its signature
+func ParseVerifiableCredentialFromJOSE(token string) (jws.Headers, *credential.VerifiableCredential, error) {
+	msg, err := jws.Parse([]byte(token))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing JOSE credential")
+	}
+	if len(msg.Signatures()) != 1 {
+		return nil, nil, fmt.Errorf("expected 1 signature, got %d", len(msg.Signatures()))
+	}
+	headers := msg.Signatures()[0].ProtectedHeaders()
+	if headers.Type() != VCJOSEType {
+		return nil, nil, fmt.Errorf("JOSE credential typ<%s> must be %s", headers.Type(), VCJOSEType)
+	}
+	cred, err := parseSecuredCredentialPayload(msg.Payload())
+	if err != nil {
+		return nil, nil, err
+	}
+	return headers, cred, nil
+}
+
+// IsVerifiableCredentialJOSE returns whether a token is a credential secured with JOSE, rather than a v1.1 VC-JWT
+func IsVerifiableCredentialJOSE(token string) bool {
+	headers, err := jwx.GetJWSHeaders([]byte(token))
+	return err == nil && headers.Type() == VCJOSEType
+}
+
+// SignVerifiablePresentationJOSE secures a v2.0 presentation as a `vp+jwt` according to
+// https://www.w3.org/TR/vc-jose-cose/#securing-vps-with-jose
+// The challenge and domain of the optional parameters are added to the payload as its `nonce` and `aud` claims.
+func SignVerifiablePresentationJOSE(signer jwx.Signer, parameters *JWTVVPParameters, pres credential.VerifiablePresentation) ([]byte, error) {
+	if pres.IsEmpty() {
+		return nil, errors.New("presentation cannot be empty")
+	}
+	if pres.Proof != nil {
+		return nil, errors.New("presentation cannot already have a proof")
+	}
+	if !hasV2BaseContext(pres.Context) {
+		return nil, fmt.Errorf("presentation must have the base context<%s> first", credential.VerifiableCredentialsV2LinkedDataContext)
+	}
+	payload, err := util.ToJSONMap(pres)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling presentation")
+	}
+	if parameters != nil {
+		if parameters.Challenge != "" {
+			payload[NonceProperty] = parameters.Challenge
+		}
+		if parameters.Domain != "" {
+			payload[jwt.AudienceKey] = parameters.Domain
+		}
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling presentation payload")
+	}
+	return signJOSE(signer, payloadBytes, VPJOSEType, VPJOSEContentType, "presentation")
+}
+
+// VerifyVerifiablePresentationJOSE verifies the signature of a `vp+jwt` and parses its presentation. The signatures
+// of the presentation's credentials are not verified.
+func VerifyVerifiablePresentationJOSE(verifier jwx.Verifier, token string) (jws.Headers, *credential.VerifiablePresentation, error) {
+	if err := verifier.VerifyJWS(token); err != nil {
+		return nil, nil, errors.Wrap(err, "verifying JOSE presentation")
+	}
+	return ParseVerifiablePresentationFromJOSE(token)
+}
+
+// ParseVerifiablePresentationFromJOSE parses the presentation of a `vp+jwt` without verifying its signature
+func ParseVerifiablePresentationFromJOSE(token string) (jws.Headers, *credential.VerifiablePresentation, error) {
+	headers, pres, _, err := parseJOSEPresentation(token)
+	return headers, pres, err
+}
+
+// IsVerifiablePresentationJOSE returns whether a token is a presentation secured with JOSE, rather than a v1.1 VP-JWT
+func IsVerifiablePresentationJOSE(token string) bool {
+	headers, err := jwx.GetJWSHeaders([]byte(token))
+	return err == nil && headers.Type() == VPJOSEType
+}
+
+// parseJOSEPresentation parses the presentation of a `vp+jwt`, and the payload it was parsed from
+func parseJOSEPresentation(token string) (jws.Headers, *credential.VerifiablePresentation, map[string]any, error) {
+	msg, err := jws.Parse([]byte(token))
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "parsing JOSE presentation")
+	}
+	if len(msg.Signatures()) != 1 {
+		return nil, nil, nil, fmt.Errorf("expected 1 signature, got %d", len(msg.Signatures()))
+	}
+	headers := msg.Signatures()[0].ProtectedHeaders()
+	if headers.Type() != VPJOSEType {
+		return nil, nil, nil, fmt.Errorf("JOSE presentation typ<%s> must be %s", headers.Type(), VPJOSEType)
+	}
+	var payload map[string]any
+	if err = json.Unmarshal(msg.Payload(), &payload); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "unmarshalling JOSE presentation payload")
+	}
+	var pres credential.VerifiablePresentation
+	if err = json.Unmarshal(msg.Payload(), &pres); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "reconstructing Verifiable Presentation")
+	}
+	if !hasV2BaseContext(pres.Context) {
+		return nil, nil, nil, fmt.Errorf("presentation must have the base context<%s> first", credential.VerifiableCredentialsV2LinkedDataContext)
+	}
+	return headers, &pres, payload, nil
+}
+
+// signJOSE signs the payload of a credential or presentation as a compact JWS with the given `typ` and `cty` headers
+func signJOSE(signer jwx.Signer, payload []byte, typ, cty, what string) ([]byte, error) {
+	hdrs := jws.NewHeaders()
+	if signer.KID != "" {
+		if err := hdrs.Set(jws.KeyIDKey, signer.KID); err != nil {
+			return nil, errors.Wrap(err, "setting KID protected header")
+		}
+	}
+	if err := hdrs.Set(jws.TypeKey, typ); err != nil {
+		return nil, errors.Wrap(err, "setting typ protected header")
+	}
+	if err := hdrs.Set(jws.ContentTypeKey, cty); err != nil {
+		return nil, errors.Wrap(err, "setting cty protected header")
+	}
+
+	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+	alg := signer.ALG
+	if alg == "Ed25519" {
+		alg = jwa.EdDSA.String()
+	}
+	signed, err := jws.Sign(payload, jws.WithKey(jwa.SignatureAlgorithm(alg), signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "signing JOSE %s", what)
+	}
+	return signed, nil
+}
+
+// securedCredentialPayload returns the payload of a credential secured with JOSE or COSE, which must be a v2.0
+// credential without an embedded proof
+func securedCredentialPayload(cred credential.VerifiableCredential) ([]byte, error) {
+	if cred.IsEmpty() {
+		return nil, errors.New("credential cannot be empty")
+	}
+	if cred.Proof != nil {
+		return nil, errors.New("credential cannot already have a proof")
+	}
+	if !hasV2BaseContext(cred.Context) {
+		return nil, fmt.Errorf("credential must have the base context<%s> first", credential.VerifiableCredentialsV2LinkedDataContext)
+	}
+	if cred.IssuerID() == "" {
+		return nil, errors.New("credential must have an issuer")
+	}
+	payload, err := json.Marshal(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential")
+	}
+	return payload, nil
+}
+
+func parseSecuredCredentialPayload(payload []byte) (*credential.VerifiableCredential, error) {
+	var cred credential.VerifiableCredential
+	if err := json.Unmarshal(payload, &cred); err != nil {
+		return nil, errors.Wrap(err, "reconstructing Verifiable Credential")
+	}
+	if !hasV2BaseContext(cred.Context) {
+		return nil, fmt.Errorf("credential must have the base context<%s> first", credential.VerifiableCredentialsV2LinkedDataContext)
+	}
+	return &cred, nil
+}
+
+// hasV2BaseContext returns whether the first context of a credential is the v2.0 base context
+func hasV2BaseContext(context any) bool {
+	switch c := context.(type) {
+	case string:
+		return c == credential.VerifiableCredentialsV2LinkedDataContext
+	case []string:
+		return len(c) > 0 && c[0] == credential.VerifiableCredentialsV2LinkedDataContext
+	case []any:
+		return len(c) > 0 && c[0] == credential.VerifiableCredentialsV2LinkedDataContext
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package integrity
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+func getTestV2Credential() credential.VerifiableCredential {
+	return credential.VerifiableCredential{
+		Context:           []any{credential.VerifiableCredentialsV2LinkedDataContext, "https://www.w3.org/ns/credentials/examples/v2"},
+		ID:                "http://university.example/credentials/1872",
+		Type:              []any{"VerifiableCredential", "ExampleAlumniCredential"},
+		Issuer:            "did:example:123",
+		ValidFrom:         "2010-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456", "alumniOf": "Example University"},
+	}
+}
+
+func TestVerifiableCredentialJOSE(t *testing.T) {
+	testCredential := getTestV2Credential()
+	signer := getTestVectorKey0Signer(t)
+	verifier, err := signer.ToVerifier(signer.ID)
+	assert.NoError(t, err)
+
+	t.Run("credential is the unmapped payload", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJOSE(signer, testCredential)
+		assert.NoError(tt, err)
+
+		msg, err := jws.Parse(signed)
+		assert.NoError(tt, err)
+		headers := msg.Signatures()[0].ProtectedHeaders()
+		assert.Equal(tt, VCJOSEType, headers.Type())
+		assert.Equal(tt, VCJOSEContentType, headers.ContentType())
+		assert.Equal(tt, signer.KID, headers.KeyID())
+
+		var payload map[string]any
+		assert.NoError(tt, json.Unmarshal(msg.Payload(), &payload))
+		assert.NotContains(tt, payload, VCJWTProperty)
+		assert.NotContains(tt, payload, "iss")
+		assert.Equal(tt, testCredential.ID, payload["id"])
+		assert.Equal(tt, testCredential.Issuer, payload["issuer"])
+	})
+
+	t.Run("verify and parse", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJOSE(signer, testCredential)
+		assert.NoError(tt, err)
+
+		headers, cred, err := VerifyVerifiableCredentialJOSE(*verifier, string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, VCJOSEType, headers.Type())
+		assert.Equal(tt, &testCredential, cred)
+		assert.True(tt, IsVerifiableCredentialJOSE(string(signed)))
+	})
+
+	t.Run("verify with another key", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJOSE(signer, testCredential)
+		assert.NoError(tt, err)
+
+		_, privKey, err := crypto.GenerateEd25519Key()
+		assert.NoError(tt, err)
+		kid := "other-key"
+		otherSigner, err := jwx.NewJWXSigner("other", &kid, privKey)
+		assert.NoError(tt, err)
+		otherVerifier, err := otherSigner.ToVerifier(otherSigner.ID)
+		assert.NoError(tt, err)
+		_, _, err = VerifyVerifiableCredentialJOSE(*otherVerifier, string(signed))
+		assert.ErrorContains(tt, err, "verifying JOSE credential")
+	})
+
+	t.Run("v1.1 credentials and JWTs are rejected", func(tt *testing.T) {
+		v1Credential := testCredential
+		v1Credential.Context = []any{credential.VerifiableCredentialsLinkedDataContext}
+		v1Credential.IssuanceDate = "2010-01-01T19:23:24Z"
+		_, err := SignVerifiableCredentialJOSE(signer, v1Credential)
+		assert.ErrorContains(tt, err, "credential must have the base context<https://www.w3.org/ns/credentials/v2> first")
+
+		vcJWT, err := SignVerifiableCredentialJWT(signer, v1Credential)
+		assert.NoError(tt, err)
+		assert.False(tt, IsVerifiableCredentialJOSE(string(vcJWT)))
+		_, _, err = ParseVerifiableCredentialFromJOSE(string(vcJWT))
+		assert.ErrorContains(tt, err, "JOSE credential typ<JWT> must be vc+jwt")
+	})
+
+	t.Run("credentials with proofs are rejected", func(tt *testing.T) {
+		provedCredential := testCredential
+		provedCredential.Proof = &crypto.Proof{}
+		_, err := SignVerifiableCredentialJOSE(signer, provedCredential)
+		assert.ErrorContains(tt, err, "credential cannot already have a proof")
+	})
+}
@@ -26,6 +26,19 @@ const (
 // SignVerifiableCredentialJWT is prepared according to https://w3c.github.io/vc-jwt/#version-1.1
 // which will soon be deprecated by https://w3c.github.io/vc-jwt/ see: https://github.com/TBD54566975/ssi-sdk/issues/191
 func SignVerifiableCredentialJWT(signer jwx.Signer, cred credential.VerifiableCredential) ([]byte, error) {
+	return signVerifiableCredentialJWT(signer, cred, nil)
+}
+
+// SignVerifiableCredentialJWTWithConfirmationKey signs a credential like SignVerifiableCredentialJWT, binding it to
+// the holder's key with a `cnf` claim https://www.rfc-editor.org/rfc/rfc7800.html#section-3.2
+func SignVerifiableCredentialJWTWithConfirmationKey(signer jwx.Signer, cred credential.VerifiableCredential, holderKey jwx.PublicKeyJWK) ([]byte, error) {
+	if holderKey.IsEmpty() {
+		return nil, errors.New("holder key cannot be empty")
+	}
+	return signVerifiableCredentialJWT(signer, cred, &holderKey)
+}
+
+func signVerifiableCredentialJWT(signer jwx.Signer, cred credential.VerifiableCredential, holderKey *jwx.PublicKeyJWK) ([]byte, error) {
 	if cred.IsEmpty() {
 		return nil, errors.New("credential cannot be empty")
 	}
@@ -37,6 +50,11 @@ func SignVerifiableCredentialJWT(signer jwx.Signer, cred credential.VerifiableCr
 	if err != nil {
 		return nil, err
 	}
+	if holderKey != nil {
+		if err = t.Set(confirmationKeyProperty, map[string]any{confirmationKeyJWKProperty: holderKey}); err != nil {
+			return nil, errors.Wrap(err, "setting cnf value")
+		}
+	}
 
 	hdrs := jws.NewHeaders()
 	if signer.KID != "" {
@@ -97,8 +115,9 @@ func JWTClaimSetFromVC(cred credential.VerifiableCredential) (jwt.Token, error)
 		cred.ID = ""
 	}
 
+	// sub can only identify the subject of a credential with a single subject
 	subVal := cred.CredentialSubject.GetID()
-	if subVal != "" {
+	if subVal != "" && len(cred.AdditionalCredentialSubjects) == 0 {
 		if err := t.Set(jwt.SubjectKey, subVal); err != nil {
 			return nil, errors.Wrap(err, "setting subject value")
 		}
@@ -148,6 +167,12 @@ func ParseVerifiableCredentialFromJWT(token string) (jws.Headers, jwt.Token, *cr
 	return headers, parsed, cred, nil
 }
 
+// GetConfirmationKey returns the holder's key of the `cnf` claim of a JWT credential, or nil if it has none
+func GetConfirmationKey(token jwt.Token) (*jwx.PublicKeyJWK, error) {
+	cnf, _ := token.Get(confirmationKeyProperty)
+	return getConfirmationKey(cnf)
+}
+
 // ParseVerifiableCredentialFromToken takes a JWT object and parses it into a VerifiableCredential
 func ParseVerifiableCredentialFromToken(token jwt.Token) (*credential.VerifiableCredential, error) {
 	// parse remaining JWT properties and set in the credential
@@ -191,7 +216,7 @@ func ParseVerifiableCredentialFromToken(token jwt.Token) (*credential.Verifiable
 
 	sub, hasSub := token.Get(jwt.SubjectKey)
 	subStr, ok := sub.(string)
-	if hasSub && ok && subStr != "" {
+	if hasSub && ok && subStr != "" && len(cred.AdditionalCredentialSubjects) == 0 {
 		if cred.CredentialSubject == nil {
 			cred.CredentialSubject = make(map[string]any)
 		}
@@ -207,6 +232,165 @@ type JWTVVPParameters struct {
 	Audience []string
 	// Expiration is an optional expiration time of the JWT using the `exp` property.
 	Expiration int
+	// Challenge is an optional challenge supplied by the verifier, set as the `nonce` of the JWT in place of a random
+	// value, which binds the presentation to the verifier's request to prevent replay.
+	Challenge string
+	// Domain is an optional domain supplied by the verifier, which is added to the audience of the JWT.
+	Domain string
+}
+
+type (
+	// VerificationOptionKey uniquely represents an option to be used when verifying a presentation
+	VerificationOptionKey string
+)
+
+const (
+	ChallengeOption         VerificationOptionKey = "challenge"
+	DomainOption            VerificationOptionKey = "domain"
+	StatusCheckOption       VerificationOptionKey = "status-check"
+	AudienceOption          VerificationOptionKey = "audience"
+	LeewayOption            VerificationOptionKey = "leeway"
+	AllowedAlgorithmsOption VerificationOptionKey = "allowed-algorithms"
+	IssuerOption            VerificationOptionKey = "issuer"
+	MaxTokenAgeOption       VerificationOptionKey = "max-token-age"
+)
+
+// VerificationOption represents a single option that may be provided when verifying a credential or presentation
+type VerificationOption struct {
+	ID     VerificationOptionKey
+	Option any
+}
+
+// WithChallenge requires the `nonce` of a JWT presentation to be the given challenge, as supplied by the verifier
+// when requesting the presentation
+func WithChallenge(challenge string) VerificationOption {
+	return VerificationOption{
+		ID:     ChallengeOption,
+		Option: challenge,
+	}
+}
+
+// WithDomain requires the `aud` of a JWT presentation to contain the given domain, as supplied by the verifier when
+// requesting the presentation
+func WithDomain(domain string) VerificationOption {
+	return VerificationOption{
+		ID:     DomainOption,
+		Option: domain,
+	}
+}
+
+// WithAudience requires the `aud` of a JWT credential to contain the given audience
+func WithAudience(audience string) VerificationOption {
+	return VerificationOption{
+		ID:     AudienceOption,
+		Option: audience,
+	}
+}
+
+// WithLeeway tolerates the given difference between the verifier's clock and the issuer's when checking the `exp`,
+// `nbf`, and `iat` of a JWT credential, which otherwise must be valid at the time of verification
+func WithLeeway(leeway time.Duration) VerificationOption {
+	return VerificationOption{
+		ID:     LeewayOption,
+		Option: leeway,
+	}
+}
+
+// WithAllowedAlgorithms requires a JWT credential to be signed with one of the given algorithms, as named by the
+// `alg` of its header, e.g. EdDSA or ES256K
+func WithAllowedAlgorithms(algs ...string) VerificationOption {
+	return VerificationOption{
+		ID:     AllowedAlgorithmsOption,
+		Option: algs,
+	}
+}
+
+// WithIssuer requires the `iss` of a JWT credential to be the given issuer, pinning verification to a known issuer
+func WithIssuer(issuer string) VerificationOption {
+	return VerificationOption{
+		ID:     IssuerOption,
+		Option: issuer,
+	}
+}
+
+// WithMaxTokenAge requires a JWT credential to have been issued, according to its `iat`, within the given duration
+// of the time of verification
+func WithMaxTokenAge(maxAge time.Duration) VerificationOption {
+	return VerificationOption{
+		ID:     MaxTokenAgeOption,
+		Option: maxAge,
+	}
+}
+
+// validateCredentialJWT checks the claims of a JWT credential whose signature has been verified against the
+// options. The `exp`, `nbf`, and `iat` claims are always checked, with the leeway option if it is provided.
+func validateCredentialJWT(headers jws.Headers, token jwt.Token, opts []VerificationOption) error {
+	var leeway, maxAge time.Duration
+	var algs []string
+	for _, opt := range opts {
+		var ok bool
+		switch opt.ID {
+		case LeewayOption:
+			leeway, ok = opt.Option.(time.Duration)
+		case MaxTokenAgeOption:
+			maxAge, ok = opt.Option.(time.Duration)
+		case AllowedAlgorithmsOption:
+			algs, ok = opt.Option.([]string)
+		default:
+			continue
+		}
+		if !ok {
+			return fmt.Errorf("invalid %s option type: %T", opt.ID, opt.Option)
+		}
+	}
+
+	if len(algs) > 0 {
+		alg := headers.Algorithm().String()
+		allowed := false
+		for _, a := range algs {
+			// Ed25519 keys sign with EdDSA https://github.com/TBD54566975/ssi-sdk/issues/520
+			if a == alg || (a == "Ed25519" && alg == jwa.EdDSA.String()) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("algorithm<%s> is not allowed", alg)
+		}
+	}
+
+	validateOpts := []jwt.ValidateOption{jwt.WithAcceptableSkew(leeway)}
+	if audience, ok := getVerificationOption(opts, AudienceOption); ok {
+		validateOpts = append(validateOpts, jwt.WithAudience(audience))
+	}
+	if issuer, ok := getVerificationOption(opts, IssuerOption); ok {
+		validateOpts = append(validateOpts, jwt.WithIssuer(issuer))
+	}
+	if err := jwt.Validate(token, validateOpts...); err != nil {
+		return errors.Wrap(err, "validating JWT claims")
+	}
+
+	if maxAge > 0 {
+		iat := token.IssuedAt()
+		if iat.IsZero() {
+			return errors.New("token has no iat, so its age cannot be checked")
+		}
+		if age := time.Since(iat); age > maxAge+leeway {
+			return fmt.Errorf("token issued at %s exceeds the maximum age of %s", iat.Format(time.RFC3339), maxAge)
+		}
+	}
+	return nil
+}
+
+func getVerificationOption(opts []VerificationOption, id VerificationOptionKey) (string, bool) {
+	var value string
+	var found bool
+	for _, opt := range opts {
+		if opt.ID == id {
+			value, found = opt.Option.(string)
+		}
+	}
+	return value, found && value != ""
 }
 
 // SignVerifiablePresentationJWT transforms a VP into a VP JWT and signs it
@@ -225,8 +409,15 @@ func SignVerifiablePresentationJWT(signer jwx.Signer, parameters *JWTVVPParamete
 	// NOTE: according to the JWT encoding rules (https://www.w3.org/TR/vc-data-model/#jwt-encoding) aud is a required
 	// property; however, aud is not required according to the JWT spec. Requiring audience limits a number of cases
 	// where JWT-VPs can be used, so we do not enforce this requirement.
-	if parameters != nil && parameters.Audience != nil {
-		if err := t.Set(jwt.AudienceKey, parameters.Audience); err != nil {
+	var audience []string
+	if parameters != nil {
+		audience = append(audience, parameters.Audience...)
+		if parameters.Domain != "" {
+			audience = append(audience, parameters.Domain)
+		}
+	}
+	if len(audience) > 0 {
+		if err := t.Set(jwt.AudienceKey, audience); err != nil {
 			return nil, errors.Wrap(err, "setting audience value")
 		}
 	}
@@ -238,7 +429,11 @@ func SignVerifiablePresentationJWT(signer jwx.Signer, parameters *JWTVVPParamete
 		return nil, errors.Wrap(err, "setting nbf value")
 	}
 
-	if err := t.Set(NonceProperty, uuid.New().String()); err != nil {
+	nonce := uuid.New().String()
+	if parameters != nil && parameters.Challenge != "" {
+		nonce = parameters.Challenge
+	}
+	if err := t.Set(NonceProperty, nonce); err != nil {
 		return nil, errors.Wrap(err, "setting nonce value")
 	}
 
@@ -294,7 +489,10 @@ func SignVerifiablePresentationJWT(signer jwx.Signer, parameters *JWTVVPParamete
 // After decoding the signature of each credential in the presentation is verified. If there are any issues during
 // decoding or signature validation, an error is returned. As a result, a successfully decoded VerifiablePresentation
 // object is returned.
-func VerifyVerifiablePresentationJWT(ctx context.Context, verifier jwx.Verifier, r resolution.Resolver, token string) (jws.Headers, jwt.Token, *credential.VerifiablePresentation, error) {
+// The challenge and domain supplied by the verifier when requesting the presentation can be enforced with the
+// WithChallenge and WithDomain options, which protect against the presentation being replayed, and the status of each
+// credential can be checked with the WithStatusCheck option.
+func VerifyVerifiablePresentationJWT(ctx context.Context, verifier jwx.Verifier, r resolution.Resolver, token string, opts ...VerificationOption) (jws.Headers, jwt.Token, *credential.VerifiablePresentation, error) {
 	if r == nil {
 		return nil, nil, nil, errors.New("r cannot be empty")
 	}
@@ -310,11 +508,34 @@ func VerifyVerifiablePresentationJWT(ctx context.Context, verifier jwx.Verifier,
 		return nil, nil, nil, errors.Wrap(err, "parsing VP from JWT")
 	}
 
+	// make sure the nonce is the challenge, if we expect one
+	if challenge, ok := getVerificationOption(opts, ChallengeOption); ok {
+		nonce, _ := vpToken.Get(NonceProperty)
+		if nonce != challenge {
+			return nil, nil, nil, errors.Errorf("challenge mismatch: expected [%s], got [%v]", challenge, nonce)
+		}
+	}
+
+	// make sure the audience contains the domain, if we expect one
+	domain, hasDomain := getVerificationOption(opts, DomainOption)
+	if hasDomain {
+		domainMatch := false
+		for _, aud := range vpToken.Audience() {
+			if aud == domain {
+				domainMatch = true
+				break
+			}
+		}
+		if !domainMatch {
+			return nil, nil, nil, errors.Errorf("domain mismatch: expected [%s], got %s", domain, vpToken.Audience())
+		}
+	}
+
 	// make sure the audience matches the verifier, if we have an audience
 	if len(vpToken.Audience()) != 0 {
 		audMatch := false
 		for _, aud := range vpToken.Audience() {
-			if aud == verifier.ID || aud == verifier.KID {
+			if aud == verifier.ID || aud == verifier.KID || (hasDomain && aud == domain) {
 				audMatch = true
 				break
 			}
@@ -327,7 +548,7 @@ func VerifyVerifiablePresentationJWT(ctx context.Context, verifier jwx.Verifier,
 	// verify signature for each credential in the vp
 	for i, cred := range vp.VerifiableCredential {
 		// verify the signature on the credential
-		verified, err := VerifyCredentialSignature(ctx, cred, r)
+		verified, err := VerifyCredentialSignature(ctx, cred, r, opts...)
 		if err != nil {
 			return nil, nil, nil, errors.Wrapf(err, "verifying credential %d", i)
 		}
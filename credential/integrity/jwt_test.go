@@ -146,6 +146,49 @@ func TestVerifiablePresentationJWT(t *testing.T) {
 		assert.NoError(tt, err)
 	})
 
+	t.Run("challenge and domain", func(tt *testing.T) {
+		signer := getTestVectorKey0Signer(tt)
+
+		testPresentation := credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1",
+				"https://w3id.org/security/suites/jws-2020/v1"},
+			Type:   []string{"VerifiablePresentation"},
+			Holder: signer.ID,
+		}
+
+		params := JWTVVPParameters{Challenge: "test-challenge", Domain: "https://verifier.example.com"}
+		signed, err := SignVerifiablePresentationJWT(signer, &params, testPresentation)
+		assert.NoError(tt, err)
+
+		verifier, err := signer.ToVerifier("did:example:verifier")
+		assert.NoError(tt, err)
+
+		resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+		require.NoError(tt, err)
+
+		token := string(signed)
+		_, vpToken, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, token, WithChallenge("test-challenge"), WithDomain("https://verifier.example.com"))
+		assert.NoError(tt, err)
+		nonce, ok := vpToken.Get(NonceProperty)
+		assert.True(tt, ok)
+		assert.Equal(tt, "test-challenge", nonce)
+		assert.Equal(tt, []string{"https://verifier.example.com"}, vpToken.Audience())
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, token, WithChallenge("other-challenge"))
+		assert.ErrorContains(tt, err, "challenge mismatch")
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, token, WithDomain("https://other.example.com"))
+		assert.ErrorContains(tt, err, "domain mismatch")
+
+		// without a challenge the nonce is random, so a verifier expecting one rejects the presentation
+		unbound, err := SignVerifiablePresentationJWT(signer, nil, testPresentation)
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(unbound), WithChallenge("test-challenge"))
+		assert.ErrorContains(tt, err, "challenge mismatch")
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(unbound), WithDomain("https://verifier.example.com"))
+		assert.ErrorContains(tt, err, "domain mismatch")
+	})
+
 	t.Run("no VCs", func(tt *testing.T) {
 		signer := getTestVectorKey0Signer(tt)
 
@@ -0,0 +1,499 @@
+package integrity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// Securing v2.0 credentials with SD-JWT https://www.w3.org/TR/vc-jose-cose/#securing-with-sd-jwt
+// The issuer-signed JWT's payload is the credential, in which selectively disclosable claims are replaced by the
+// digests of their disclosures https://datatracker.ietf.org/doc/draft-ietf-oauth-selective-disclosure-jwt/
+
+const (
+	// VCSDJWTType is the `typ` header of a credential secured with SD-JWT
+	VCSDJWTType string = "vc+sd-jwt"
+	// KBJWTType is the `typ` header of the key binding JWT of an SD-JWT, with which the holder presents it
+	KBJWTType string = "kb+jwt"
+
+	sdJWTSeparator     = "~"
+	sdDigestsProperty  = "_sd"
+	sdAlgProperty      = "_sd_alg"
+	sdArrayElementKey  = "..."
+	sdDefaultAlgorithm = "sha-256"
+	sdHashProperty     = "sd_hash"
+
+	// confirmationKeyProperty is the claim binding an SD-JWT to the holder's key, which it holds as a JWK
+	// https://www.rfc-editor.org/rfc/rfc7800.html#section-3.2
+	confirmationKeyProperty    = "cnf"
+	confirmationKeyJWKProperty = "jwk"
+
+	// sdSaltSize is the size, in bytes, of the random salt of each disclosure
+	sdSaltSize = 16
+)
+
+// SignVerifiableCredentialSDJWT secures a v2.0 credential as a `vc+sd-jwt`, making the given claims of its credential
+// subject selectively disclosable. The issuer-signed JWT is returned followed by a disclosure of each claim, so
+// that the holder may present only those it chooses.
+func SignVerifiableCredentialSDJWT(signer jwx.Signer, cred credential.VerifiableCredential, disclosable ...string) ([]byte, error) {
+	return signVerifiableCredentialSDJWT(signer, cred, nil, disclosable)
+}
+
+// SignVerifiableCredentialSDJWTWithConfirmationKey secures a credential like SignVerifiableCredentialSDJWT, binding it
+// to the holder's key with a `cnf` claim, against which the key binding JWTs it is presented with are verified
+func SignVerifiableCredentialSDJWTWithConfirmationKey(signer jwx.Signer, cred credential.VerifiableCredential, holderKey jwx.PublicKeyJWK, disclosable ...string) ([]byte, error) {
+	if holderKey.IsEmpty() {
+		return nil, errors.New("holder key cannot be empty")
+	}
+	return signVerifiableCredentialSDJWT(signer, cred, &holderKey, disclosable)
+}
+
+func signVerifiableCredentialSDJWT(signer jwx.Signer, cred credential.VerifiableCredential, holderKey *jwx.PublicKeyJWK, disclosable []string) ([]byte, error) {
+	payload, err := securedCredentialPayload(cred)
+	if err != nil {
+		return nil, err
+	}
+	var payloadJSON map[string]any
+	if err = json.Unmarshal(payload, &payloadJSON); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling credential payload")
+	}
+	if holderKey != nil {
+		payloadJSON[confirmationKeyProperty] = map[string]any{confirmationKeyJWKProperty: holderKey}
+	}
+
+	var disclosures []string
+	if len(disclosable) > 0 {
+		subject, ok := payloadJSON["credentialSubject"].(map[string]any)
+		if !ok {
+			return nil, errors.New("credential must have a single credential subject to disclose claims of")
+		}
+		digests := make([]any, 0, len(disclosable))
+		for _, claim := range disclosable {
+			value, ok := subject[claim]
+			if !ok {
+				return nil, fmt.Errorf("credential subject has no claim<%s> to disclose", claim)
+			}
+			disclosure, err := newSDDisclosure(claim, value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "disclosing claim<%s>", claim)
+			}
+			digest := sha256.Sum256([]byte(disclosure))
+			digests = append(digests, base64.RawURLEncoding.EncodeToString(digest[:]))
+			disclosures = append(disclosures, disclosure)
+			delete(subject, claim)
+		}
+		subject[sdDigestsProperty] = digests
+		payloadJSON[sdAlgProperty] = sdDefaultAlgorithm
+	}
+	if payload, err = json.Marshal(payloadJSON); err != nil {
+		return nil, errors.Wrap(err, "marshalling SD-JWT payload")
+	}
+
+	signed, err := signJOSE(signer, payload, VCSDJWTType, VCJOSEContentType, "SD-JWT credential")
+	if err != nil {
+		return nil, err
+	}
+	// without a key binding JWT, the SD-JWT ends with a separator
+	return []byte(strings.Join(append([]string{string(signed)}, disclosures...), sdJWTSeparator) + sdJWTSeparator), nil
+}
+
+// newSDDisclosure returns the encoded disclosure of an object property, as [salt, name, value]
+func newSDDisclosure(name string, value any) (string, error) {
+	salt := make([]byte, sdSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "generating salt")
+	}
+	disclosure, err := json.Marshal([]any{base64.RawURLEncoding.EncodeToString(salt), name, value})
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling disclosure")
+	}
+	return base64.RawURLEncoding.EncodeToString(disclosure), nil
+}
+
+// VerifyVerifiableCredentialSDJWT verifies the signature of the issuer-signed JWT of a `vc+sd-jwt` and parses its
+// credential, with the claims it discloses
+func VerifyVerifiableCredentialSDJWT(verifier jwx.Verifier, token string) (jws.Headers, *credential.VerifiableCredential, error) {
+	issuerJWT, _, _ := strings.Cut(token, sdJWTSeparator)
+	if err := verifier.VerifyJWS(issuerJWT); err != nil {
+		return nil, nil, errors.Wrap(err, "verifying SD-JWT credential")
+	}
+	return ParseVerifiableCredentialFromSDJWT(token)
+}
+
+// ParseVerifiableCredentialFromSDJWT parses the credential of a `vc+sd-jwt` without verifying its signature. Claims
+// are disclosed by the disclosures following the issuer-signed JWT, and undisclosed claims are left out. Disclosures
+// which the issuer-signed JWT does not reference, and digests which appear more than once, are rejected. A key
+// binding JWT, if present, is ignored.
+func ParseVerifiableCredentialFromSDJWT(token string) (jws.Headers, *credential.VerifiableCredential, error) {
+	parts := strings.Split(token, sdJWTSeparator)
+	headers, payload, err := parseSDJWTIssuerJWT(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	if alg, ok := payload[sdAlgProperty]; ok && alg != sdDefaultAlgorithm {
+		return nil, nil, fmt.Errorf("unsupported SD-JWT digest algorithm<%v>", alg)
+	}
+	delete(payload, sdAlgProperty)
+	delete(payload, confirmationKeyProperty)
+
+	// the disclosures are between the issuer-signed JWT and the key binding JWT, which is empty when not present
+	var disclosures []string
+	if len(parts) > 2 {
+		disclosures = parts[1 : len(parts)-1]
+	}
+	disclosed, err := newSDDisclosures(disclosures)
+	if err != nil {
+		return nil, nil, err
+	}
+	referenced := make(map[string]bool)
+	credJSON, err := disclosed.apply(payload, referenced)
+	if err != nil {
+		return nil, nil, err
+	}
+	for digest := range disclosed {
+		if !referenced[digest] {
+			return nil, nil, fmt.Errorf("disclosure<%s> is not referenced by the SD-JWT", digest)
+		}
+	}
+	credBytes, err := json.Marshal(credJSON)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "marshalling disclosed credential")
+	}
+	cred, err := parseSecuredCredentialPayload(credBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return headers, cred, nil
+}
+
+// GetSDJWTConfirmationKey returns the holder's key of the `cnf` claim of a `vc+sd-jwt`, or nil if it has none
+func GetSDJWTConfirmationKey(token string) (*jwx.PublicKeyJWK, error) {
+	issuerJWT, _, _ := strings.Cut(token, sdJWTSeparator)
+	_, payload, err := parseSDJWTIssuerJWT(issuerJWT)
+	if err != nil {
+		return nil, err
+	}
+	return getConfirmationKey(payload[confirmationKeyProperty])
+}
+
+// parseSDJWTIssuerJWT returns the headers and payload of the issuer-signed JWT of a `vc+sd-jwt`, without verifying its
+// signature
+func parseSDJWTIssuerJWT(issuerJWT string) (jws.Headers, map[string]any, error) {
+	msg, err := jws.Parse([]byte(issuerJWT))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing SD-JWT credential")
+	}
+	if len(msg.Signatures()) != 1 {
+		return nil, nil, fmt.Errorf("expected 1 signature, got %d", len(msg.Signatures()))
+	}
+	headers := msg.Signatures()[0].ProtectedHeaders()
+	if headers.Type() != VCSDJWTType {
+		return nil, nil, fmt.Errorf("SD-JWT credential typ<%s> must be %s", headers.Type(), VCSDJWTType)
+	}
+	var payload map[string]any
+	if err = json.Unmarshal(msg.Payload(), &payload); err != nil {
+		return nil, nil, errors.Wrap(err, "unmarshalling SD-JWT payload")
+	}
+	return headers, payload, nil
+}
+
+// getConfirmationKey returns the holder's key of a `cnf` claim, or nil if there is no claim
+func getConfirmationKey(cnf any) (*jwx.PublicKeyJWK, error) {
+	if cnf == nil {
+		return nil, nil
+	}
+	cnfMap, ok := cnf.(map[string]any)
+	if !ok {
+		return nil, errors.New("cnf claim must be an object")
+	}
+	holderJWK, ok := cnfMap[confirmationKeyJWKProperty]
+	if !ok {
+		return nil, errors.New("cnf claim does not contain a jwk")
+	}
+	holderJWKBytes, err := json.Marshal(holderJWK)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling cnf jwk")
+	}
+	var holderKey jwx.PublicKeyJWK
+	if err = json.Unmarshal(holderJWKBytes, &holderKey); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling cnf jwk")
+	}
+	if holderKey.IsEmpty() {
+		return nil, errors.New("cnf jwk cannot be empty")
+	}
+	return &holderKey, nil
+}
+
+// SelectSDJWTDisclosures returns an SD-JWT presenting only the disclosures of the given claims of its credential
+// subject, by name, without any key binding JWT
+func SelectSDJWTDisclosures(token string, claims ...string) (string, error) {
+	parts := strings.Split(token, sdJWTSeparator)
+	if len(parts) < 2 {
+		return "", errors.New("SD-JWT must end with a separator")
+	}
+	selected := []string{parts[0]}
+	for _, encoded := range parts[1 : len(parts)-1] {
+		disclosures, err := newSDDisclosures([]string{encoded})
+		if err != nil {
+			return "", err
+		}
+		for _, disclosure := range disclosures {
+			if len(disclosure) == 3 && slices.Contains(claims, fmt.Sprint(disclosure[1])) {
+				selected = append(selected, encoded)
+			}
+		}
+	}
+	return strings.Join(selected, sdJWTSeparator) + sdJWTSeparator, nil
+}
+
+// AddSDJWTKeyBinding presents an SD-JWT to a verifier, appending a key binding JWT signed by the holder, which binds
+// the SD-JWT to the verifier's audience and nonce, and to its disclosures by its `sd_hash`. Any key binding JWT the
+// SD-JWT already has is replaced. The signer's key is the one the SD-JWT's `cnf` claim holds or, without the claim,
+// the verification method of the DID of the credential's subject identified by the signer's KID.
+// https://datatracker.ietf.org/doc/html/draft-ietf-oauth-selective-disclosure-jwt#name-key-binding-jwt
+func AddSDJWTKeyBinding(signer jwx.Signer, token, audience, nonce string) (string, error) {
+	lastSeparator := strings.LastIndex(token, sdJWTSeparator)
+	if lastSeparator < 0 {
+		return "", errors.New("SD-JWT must have a separator")
+	}
+	presented := token[:lastSeparator+1]
+
+	t := jwt.New()
+	if err := t.Set(jwt.IssuedAtKey, time.Now()); err != nil {
+		return "", errors.Wrap(err, "setting iat")
+	}
+	if err := t.Set(jwt.AudienceKey, audience); err != nil {
+		return "", errors.Wrap(err, "setting aud")
+	}
+	if err := t.Set(NonceProperty, nonce); err != nil {
+		return "", errors.Wrap(err, "setting nonce")
+	}
+	if err := t.Set(sdHashProperty, sdHash(presented)); err != nil {
+		return "", errors.Wrap(err, "setting sd_hash")
+	}
+	hdrs := jws.NewHeaders()
+	if err := hdrs.Set(jws.TypeKey, KBJWTType); err != nil {
+		return "", errors.Wrap(err, "setting typ")
+	}
+	if signer.KID != "" {
+		if err := hdrs.Set(jws.KeyIDKey, signer.KID); err != nil {
+			return "", errors.Wrap(err, "setting kid")
+		}
+	}
+	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+	alg := signer.ALG
+	if alg == "Ed25519" {
+		alg = jwa.EdDSA.String()
+	}
+	kbJWT, err := jwt.Sign(t, jwt.WithKey(jwa.SignatureAlgorithm(alg), signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	if err != nil {
+		return "", errors.Wrap(err, "signing key binding JWT")
+	}
+	return presented + string(kbJWT), nil
+}
+
+// VerifySDJWTKeyBinding verifies the key binding JWT of an SD-JWT: it must be signed by the holder's key of the
+// SD-JWT's `cnf` claim or, without the claim, by a key of the DID of the credential's subject, resolved by its kid. It
+// must be bound to the given audience and nonce, and its `sd_hash` must be the digest of the SD-JWT it follows. The
+// signature of the SD-JWT itself is not verified.
+func VerifySDJWTKeyBinding(ctx context.Context, token string, r resolution.Resolver, audience, nonce string) error {
+	if r == nil {
+		return errors.New("resolution cannot be empty")
+	}
+	lastSeparator := strings.LastIndex(token, sdJWTSeparator)
+	if lastSeparator < 0 || lastSeparator == len(token)-1 {
+		return errors.New("SD-JWT has no key binding JWT")
+	}
+	presented, kbJWT := token[:lastSeparator+1], token[lastSeparator+1:]
+	headers, err := jwx.GetJWSHeaders([]byte(kbJWT))
+	if err != nil {
+		return errors.Wrap(err, "getting key binding JWT headers")
+	}
+	if headers.Type() != KBJWTType {
+		return fmt.Errorf("key binding JWT typ<%s> must be %s", headers.Type(), KBJWTType)
+	}
+
+	_, cred, err := ParseVerifiableCredentialFromSDJWT(token)
+	if err != nil {
+		return err
+	}
+	confirmationKey, err := GetSDJWTConfirmationKey(token)
+	if err != nil {
+		return err
+	}
+	var verifier *jwx.Verifier
+	if confirmationKey != nil {
+		if verifier, err = jwx.NewJWXVerifierFromJWK(confirmationKeyProperty, *confirmationKey); err != nil {
+			return errors.Wrap(err, "constructing verifier for cnf key")
+		}
+	} else {
+		holder := cred.CredentialSubject.GetID()
+		kid := headers.KeyID()
+		if holder == "" || !strings.HasPrefix(kid, holder+"#") {
+			return fmt.Errorf("key binding JWT kid<%s> is not a key of the credential subject<%s>", kid, holder)
+		}
+		key, err := resolution.ResolveKeyForDID(ctx, r, holder, kid)
+		if err != nil {
+			return errors.Wrapf(err, "resolving key binding key<%s>", kid)
+		}
+		if verifier, err = jwx.NewJWXVerifier(holder, &kid, key); err != nil {
+			return errors.Wrapf(err, "constructing verifier for key binding key<%s>", kid)
+		}
+	}
+	_, kbToken, err := verifier.VerifyAndParse(kbJWT)
+	if err != nil {
+		return errors.Wrap(err, "verifying key binding JWT")
+	}
+	if !slices.Contains(kbToken.Audience(), audience) {
+		return fmt.Errorf("key binding JWT audience %s does not contain [%s]", kbToken.Audience(), audience)
+	}
+	if kbNonce, _ := kbToken.Get(NonceProperty); kbNonce != nonce {
+		return fmt.Errorf("challenge mismatch: expected [%s], got [%v]", nonce, kbNonce)
+	}
+	if hash, _ := kbToken.Get(sdHashProperty); hash != sdHash(presented) {
+		return errors.New("key binding JWT sd_hash is not the digest of the SD-JWT")
+	}
+	if kbToken.IssuedAt().IsZero() {
+		return errors.New("key binding JWT has no iat")
+	}
+	return nil
+}
+
+// sdHash returns the digest of an SD-JWT presented with a key binding JWT
+func sdHash(presented string) string {
+	digest := sha256.Sum256([]byte(presented))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// IsVerifiableCredentialSDJWT returns whether a token is a credential secured with SD-JWT
+func IsVerifiableCredentialSDJWT(token string) bool {
+	issuerJWT, _, ok := strings.Cut(token, sdJWTSeparator)
+	if !ok {
+		return false
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(issuerJWT))
+	return err == nil && headers.Type() == VCSDJWTType
+}
+
+// sdDisclosures are the disclosures of an SD-JWT, keyed by their digests
+type sdDisclosures map[string][]any
+
+func newSDDisclosures(encoded []string) (sdDisclosures, error) {
+	disclosures := make(sdDisclosures, len(encoded))
+	for _, e := range encoded {
+		decoded, err := base64.RawURLEncoding.DecodeString(e)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding disclosure")
+		}
+		var disclosure []any
+		if err = json.Unmarshal(decoded, &disclosure); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling disclosure")
+		}
+		// object properties are disclosed as [salt, name, value], and array elements as [salt, value]
+		if len(disclosure) != 2 && len(disclosure) != 3 {
+			return nil, fmt.Errorf("disclosure must have 2 or 3 elements, got %d", len(disclosure))
+		}
+		digest := sha256.Sum256([]byte(e))
+		encodedDigest := base64.RawURLEncoding.EncodeToString(digest[:])
+		if _, ok := disclosures[encodedDigest]; ok {
+			return nil, fmt.Errorf("disclosure<%s> is presented more than once", encodedDigest)
+		}
+		disclosures[encodedDigest] = disclosure
+	}
+	return disclosures, nil
+}
+
+// apply replaces the digests in a value with the claims they disclose, removing those which are not disclosed. Each
+// digest is recorded in referenced, and may only appear once.
+func (d sdDisclosures) apply(value any, referenced map[string]bool) (any, error) {
+	switch typed := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(typed))
+		for k, v := range typed {
+			if k == sdDigestsProperty {
+				continue
+			}
+			applied, err := d.apply(v, referenced)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = applied
+		}
+		digests, _ := typed[sdDigestsProperty].([]any)
+		for _, digest := range digests {
+			disclosure, ok, err := d.reference(digest, referenced)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			name, ok := disclosure[1].(string)
+			if len(disclosure) != 3 || !ok {
+				return nil, errors.New("object property disclosure must have a name")
+			}
+			if _, exists := result[name]; exists {
+				return nil, fmt.Errorf("disclosed property<%s> already exists", name)
+			}
+			applied, err := d.apply(disclosure[2], referenced)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = applied
+		}
+		return result, nil
+	case []any:
+		result := make([]any, 0, len(typed))
+		for _, element := range typed {
+			if digestObject, ok := element.(map[string]any); ok && len(digestObject) == 1 && digestObject[sdArrayElementKey] != nil {
+				disclosure, disclosed, err := d.reference(digestObject[sdArrayElementKey], referenced)
+				if err != nil {
+					return nil, err
+				}
+				if !disclosed {
+					continue
+				}
+				if len(disclosure) != 2 {
+					return nil, errors.New("array element disclosure must not have a name")
+				}
+				element = disclosure[1]
+			}
+			applied, err := d.apply(element, referenced)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, applied)
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// reference records a digest of the SD-JWT in referenced, returning its disclosure if it is disclosed
+func (d sdDisclosures) reference(digest any, referenced map[string]bool) ([]any, bool, error) {
+	encodedDigest, ok := digest.(string)
+	if !ok {
+		return nil, false, errors.New("SD-JWT digest must be a string")
+	}
+	if referenced[encodedDigest] {
+		return nil, false, fmt.Errorf("SD-JWT digest<%s> appears more than once", encodedDigest)
+	}
+	referenced[encodedDigest] = true
+	disclosure, disclosed := d[encodedDigest]
+	return disclosure, disclosed, nil
+}
@@ -5,24 +5,64 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/rsa2018"
 	"github.com/TBD54566975/ssi-sdk/did"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
 
 	"github.com/pkg/errors"
 )
 
-// VerifyCredentialSignature verifies the signature of a credential of any type
+// StatusChecker checks the status of a credential, returning an error if it has been revoked or suspended
+type StatusChecker interface {
+	CheckStatus(ctx context.Context, cred credential.VerifiableCredential) error
+}
+
+// WithStatusCheck checks the status of each credential with the given checker once its signature has been verified,
+// so a credential which has been revoked or suspended fails verification
+func WithStatusCheck(checker StatusChecker) VerificationOption {
+	return VerificationOption{
+		ID:     StatusCheckOption,
+		Option: checker,
+	}
+}
+
+// checkStatus checks the status of a verified credential if a status check option is provided
+func checkStatus(ctx context.Context, cred credential.VerifiableCredential, opts []VerificationOption) error {
+	for _, opt := range opts {
+		if opt.ID != StatusCheckOption {
+			continue
+		}
+		checker, ok := opt.Option.(StatusChecker)
+		if !ok || checker == nil {
+			return errors.New("the status check option provided must be a StatusChecker")
+		}
+		if err := checker.CheckStatus(ctx, cred); err != nil {
+			return errors.Wrapf(err, "checking status of credential<%s>", cred.ID)
+		}
+	}
+	return nil
+}
+
+// VerifyCredentialSignature verifies the signature of a credential of any type, including enveloped credentials. If
+// the WithStatusCheck option is provided, the credential's status is checked once its signature has been verified.
 // TODO(gabe) support other types of credentials https://github.com/TBD54566975/ssi-sdk/issues/352
-func VerifyCredentialSignature(ctx context.Context, genericCred any, r resolution.Resolver) (bool, error) {
+func VerifyCredentialSignature(ctx context.Context, genericCred any, r resolution.Resolver, opts ...VerificationOption) (bool, error) {
 	if genericCred == nil {
 		return false, errors.New("credential cannot be empty")
 	}
 	if r == nil {
 		return false, errors.New("resolution cannot be empty")
 	}
+	if envelope, ok := credential.ToEnvelopedVerifiableCredential(genericCred); ok {
+		return VerifyEnvelopedCredential(ctx, *envelope, r, opts...)
+	}
 	switch typedCred := genericCred.(type) {
 	case map[string]any:
 		typedCredBytes, err := json.Marshal(typedCred)
@@ -36,38 +76,50 @@ func VerifyCredentialSignature(ctx context.Context, genericCred any, r resolutio
 		if cred.IsEmpty() {
 			return false, errors.New("map is not a valid credential")
 		}
-		return VerifyCredentialSignature(ctx, cred, r)
+		return VerifyCredentialSignature(ctx, cred, r, opts...)
 	case *credential.VerifiableCredential:
-		return VerifyDataIntegrityCredential(ctx, *typedCred, r)
+		return VerifyDataIntegrityCredential(ctx, *typedCred, r, opts...)
 	case credential.VerifiableCredential:
-		return VerifyDataIntegrityCredential(ctx, typedCred, r)
+		return VerifyDataIntegrityCredential(ctx, typedCred, r, opts...)
 	case []byte:
 		// turn it into a string and try again
-		return VerifyCredentialSignature(ctx, string(typedCred), r)
+		return VerifyCredentialSignature(ctx, string(typedCred), r, opts...)
 	case string:
 		// could be a Data Integrity credential
 		var cred credential.VerifiableCredential
 		if err := json.Unmarshal([]byte(typedCred), &cred); err == nil {
-			return VerifyCredentialSignature(ctx, cred, r)
+			return VerifyCredentialSignature(ctx, cred, r, opts...)
+		}
+
+		// could be a v2.0 credential secured with JOSE or SD-JWT
+		if IsVerifiableCredentialJOSE(typedCred) {
+			envelope := credential.NewEnvelopedVerifiableCredential(credential.VCJWTMediaType, []byte(typedCred))
+			return VerifyEnvelopedCredential(ctx, envelope, r, opts...)
+		}
+		if IsVerifiableCredentialSDJWT(typedCred) {
+			envelope := credential.NewEnvelopedVerifiableCredential(credential.VCSDJWTMediaType, []byte(typedCred))
+			return VerifyEnvelopedCredential(ctx, envelope, r, opts...)
 		}
 
 		// could be a JWT
-		return VerifyJWTCredential(ctx, typedCred, r)
+		return VerifyJWTCredential(ctx, typedCred, r, opts...)
 	}
 	return false, fmt.Errorf("invalid credential type: %s", reflect.TypeOf(genericCred).Kind().String())
 }
 
 // VerifyJWTCredential verifies the signature of a JWT credential after parsing it to resolve the issuer DID
 // The issuer DID is resolution from the provided resolution, and used to find the issuer's public key matching
-// the KID in the JWT header.
-func VerifyJWTCredential(ctx context.Context, cred string, r resolution.Resolver) (bool, error) {
+// the KID in the JWT header. The token's `exp`, `nbf`, and `iat` are checked, tolerating the WithLeeway option, and its
+// claims are checked against the WithAudience, WithIssuer, WithAllowedAlgorithms, and WithMaxTokenAge options. If the
+// WithStatusCheck option is provided, the credential's status is checked once its signature has been verified.
+func VerifyJWTCredential(ctx context.Context, cred string, r resolution.Resolver, opts ...VerificationOption) (bool, error) {
 	if cred == "" {
 		return false, errors.New("credential cannot be empty")
 	}
 	if r == nil {
 		return false, errors.New("resolution cannot be empty")
 	}
-	headers, token, _, err := ParseVerifiableCredentialFromJWT(cred)
+	headers, token, parsedCred, err := ParseVerifiableCredentialFromJWT(cred)
 	if err != nil {
 		return false, errors.Wrap(err, "parsing JWT")
 	}
@@ -91,30 +143,197 @@ func VerifyJWTCredential(ctx context.Context, cred string, r resolution.Resolver
 	if err != nil {
 		return false, errors.Wrapf(err, "error constructing verifier for credential<%s>", token.JwtID())
 	}
-	// verify the signature
-	if _, _, _, err = VerifyVerifiableCredentialJWT(*credVerifier, cred); err != nil {
+	// verify the signature, then the claims, with the leeway given in the options
+	if err = credVerifier.VerifyJWS(cred); err != nil {
 		return false, errors.Wrapf(err, "error verifying credential<%s>", token.JwtID())
 	}
+	if err = validateCredentialJWT(headers, token, opts); err != nil {
+		return false, errors.Wrapf(err, "error validating credential<%s>", token.JwtID())
+	}
+	if err = checkStatus(ctx, *parsedCred, opts); err != nil {
+		return false, err
+	}
 	return true, nil
 }
 
-// VerifyDataIntegrityCredential verifies the signature of a Data Integrity credential
-// TODO(gabe): https://github.com/TBD54566975/ssi-sdk/issues/196
-func VerifyDataIntegrityCredential(_ context.Context, cred credential.VerifiableCredential, _ resolution.Resolver) (bool, error) {
+// VerifyDataIntegrityCredential verifies the signature of a Data Integrity credential. The verification method
+// referenced by the credential's proof is resolved using the provided resolver. If the WithStatusCheck option is
+// provided, the credential's status is checked once its signature has been verified.
+func VerifyDataIntegrityCredential(ctx context.Context, cred credential.VerifiableCredential, r resolution.Resolver, opts ...VerificationOption) (bool, error) {
 	if cred.IsEmpty() {
 		return false, errors.New("credential cannot be empty")
 	}
 	if cred.GetProof() == nil {
 		return false, errors.New("credential must have a proof")
 	}
+	if err := VerifyDataIntegrityProof(ctx, &cred, r, cred.IssuerID(), did.AssertionMethod); err != nil {
+		return false, errors.Wrapf(err, "error verifying credential<%s>", cred.ID)
+	}
+	if err := checkStatus(ctx, cred, opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// VerifyDataIntegrityProof verifies the embedded proof of a provable document made by a DID, such as the issuer of a
+// credential or the holder of a presentation, for a purpose. The proof's verificationMethod must be of the DID, and
+// the proof must be for the purpose, which the DID's document, resolved using the provided resolver, must authorize
+// the verification method for. The method's key is used to verify the proof with the suite registered for the
+// proof's type.
+func VerifyDataIntegrityProof(ctx context.Context, provable cryptosuite.WithEmbeddedProof, r resolution.Resolver, controller string, purpose did.PublicKeyPurpose, opts ...cryptosuite.Option) error {
+	if provable == nil || provable.GetProof() == nil {
+		return errors.New("provable must have a proof")
+	}
+	if r == nil {
+		return errors.New("resolution cannot be empty")
+	}
+	proof := provable.GetProof()
+	verificationMethod, err := getProofVerificationMethod(*proof)
+	if err != nil {
+		return err
+	}
+
+	// the proof must be made by the controller, for the purpose
+	id, _, _ := strings.Cut(verificationMethod, "#")
+	if controller == "" || id != controller {
+		return errors.Errorf("verification method<%s> is not of did<%s>", verificationMethod, controller)
+	}
+	if proof.ProofPurpose != string(purpose) {
+		return errors.Errorf("proof purpose<%s> must be %s", proof.ProofPurpose, purpose)
+	}
+
+	// get key to verify the proof with, which the DID must authorize for the purpose
+	resolved, err := r.Resolve(ctx, id)
+	if err != nil {
+		return errors.Wrapf(err, "error resolving DID<%s> to verify proof", id)
+	}
+	pubKey, err := did.GetAuthorizedKey(resolved.Document, purpose, verificationMethod)
+	if err != nil {
+		return errors.Wrapf(err, "error getting key for verification method<%s>", verificationMethod)
+	}
+	pubKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(&verificationMethod, pubKey)
+	if err != nil {
+		return errors.Wrapf(err, "error converting key for verification method<%s>", verificationMethod)
+	}
+
+	// construct a verifier
+	verifier, err := jws2020.NewJSONWebKeyVerifier(verificationMethod, *pubKeyJWK)
+	if err != nil {
+		return errors.Wrapf(err, "error constructing verifier for verification method<%s>", verificationMethod)
+	}
+	registry, err := cryptosuite.NewRegistry(jws2020.GetJSONWebSignature2020Suite(), rsa2018.GetRSASignature2018Suite())
+	if err != nil {
+		return errors.Wrap(err, "constructing suite registry")
+	}
+	return registry.Verify(verifier, provable, opts...)
+}
+
+// getProofVerificationMethod returns the verificationMethod property of a single proof, or the creator property
+// used in its place by legacy proofs
+func getProofVerificationMethod(p crypto.Proof) (string, error) {
+	if p.VerificationMethod != "" {
+		return p.VerificationMethod, nil
+	}
+	if creator, ok := p.GetProperty("creator"); ok {
+		if creatorID, isString := creator.(string); isString && creatorID != "" {
+			return creatorID, nil
+		}
+	}
+	return "", errors.New("proof does not have a verification method")
+}
+
+// VerifyPresentationSignature verifies the signature of a presentation of any type, and the signatures of the
+// credentials it contains. The challenge and domain supplied by the verifier when requesting the presentation can be
+// enforced with the WithChallenge and WithDomain options.
+func VerifyPresentationSignature(ctx context.Context, genericPres any, r resolution.Resolver, opts ...VerificationOption) (bool, error) {
+	if genericPres == nil {
+		return false, errors.New("presentation cannot be empty")
+	}
+	if r == nil {
+		return false, errors.New("resolution cannot be empty")
+	}
+	if envelope, ok := credential.ToEnvelopedVerifiablePresentation(genericPres); ok {
+		return VerifyEnvelopedPresentation(ctx, *envelope, r, opts...)
+	}
+	switch typedPres := genericPres.(type) {
+	case map[string]any:
+		typedPresBytes, err := json.Marshal(typedPres)
+		if err != nil {
+			return false, errors.Wrap(err, "marshalling presentation map")
+		}
+		var pres credential.VerifiablePresentation
+		if err = json.Unmarshal(typedPresBytes, &pres); err != nil {
+			return false, errors.Wrap(err, "unmarshalling presentation object")
+		}
+		if pres.IsEmpty() {
+			return false, errors.New("map is not a valid presentation")
+		}
+		return VerifyDataIntegrityPresentation(ctx, pres, r, opts...)
+	case *credential.VerifiablePresentation:
+		return VerifyDataIntegrityPresentation(ctx, *typedPres, r, opts...)
+	case credential.VerifiablePresentation:
+		return VerifyDataIntegrityPresentation(ctx, typedPres, r, opts...)
+	case []byte:
+		// turn it into a string and try again
+		return VerifyPresentationSignature(ctx, string(typedPres), r, opts...)
+	case string:
+		// could be a Data Integrity presentation
+		var pres credential.VerifiablePresentation
+		if err := json.Unmarshal([]byte(typedPres), &pres); err == nil {
+			return VerifyDataIntegrityPresentation(ctx, pres, r, opts...)
+		}
 
-	return false, errors.New("not implemented")
+		// could be a v2.0 presentation secured with JOSE
+		if IsVerifiablePresentationJOSE(typedPres) {
+			envelope := credential.NewEnvelopedVerifiablePresentation(credential.VPJWTMediaType, []byte(typedPres))
+			return VerifyEnvelopedPresentation(ctx, envelope, r, opts...)
+		}
+
+		// could be a JWT
+		return VerifyJWTPresentation(ctx, typedPres, r, opts...)
+	}
+	return false, fmt.Errorf("invalid presentation type: %s", reflect.TypeOf(genericPres).Kind().String())
+}
+
+// VerifyDataIntegrityPresentation verifies the signature of a Data Integrity presentation, and the signatures of the
+// credentials it contains. The challenge and domain options are checked against those of the presentation's proof,
+// and the status check option is applied to each credential.
+func VerifyDataIntegrityPresentation(ctx context.Context, pres credential.VerifiablePresentation, r resolution.Resolver, opts ...VerificationOption) (bool, error) {
+	if pres.IsEmpty() {
+		return false, errors.New("presentation cannot be empty")
+	}
+	proof := pres.GetProof()
+	if proof == nil {
+		return false, errors.New("presentation must have a proof")
+	}
+	if challenge, ok := getVerificationOption(opts, ChallengeOption); ok && proof.Challenge != challenge {
+		return false, errors.Errorf("challenge mismatch: expected [%s], got [%s]", challenge, proof.Challenge)
+	}
+	if domain, ok := getVerificationOption(opts, DomainOption); ok && proof.Domain != domain {
+		return false, errors.Errorf("domain mismatch: expected [%s], got [%s]", domain, proof.Domain)
+	}
+	if pres.Holder == "" {
+		return false, errors.New("presentation must have a holder")
+	}
+	if err := VerifyDataIntegrityProof(ctx, &pres, r, pres.Holder, did.Authentication); err != nil {
+		return false, errors.Wrapf(err, "error verifying presentation<%s>", pres.ID)
+	}
+	for i, cred := range pres.VerifiableCredential {
+		verified, err := VerifyCredentialSignature(ctx, cred, r, opts...)
+		if err != nil {
+			return false, errors.Wrapf(err, "verifying credential %d", i)
+		}
+		if !verified {
+			return false, errors.Errorf("credential %d failed signature validation", i)
+		}
+	}
+	return true, nil
 }
 
 // VerifyJWTPresentation verifies the signature of a JWT presentation after parsing it to resolve the issuer DID
 // The issuer DID is resolution from the provided resolution, and used to find the issuer's public key matching
-// the KID in the JWT header.
-func VerifyJWTPresentation(ctx context.Context, pres string, r resolution.Resolver) (bool, error) {
+// the KID in the JWT header. Options are passed to VerifyVerifiablePresentationJWT.
+func VerifyJWTPresentation(ctx context.Context, pres string, r resolution.Resolver, opts ...VerificationOption) (bool, error) {
 	if pres == "" {
 		return false, errors.New("presentation cannot be empty")
 	}
@@ -146,7 +365,7 @@ func VerifyJWTPresentation(ctx context.Context, pres string, r resolution.Resolv
 		return false, errors.Wrapf(err, "error constructing verifier for presentation<%s>", token.JwtID())
 	}
 	// verify the signature
-	if _, _, _, err = VerifyVerifiablePresentationJWT(ctx, *presVerifier, r, pres); err != nil {
+	if _, _, _, err = VerifyVerifiablePresentationJWT(ctx, *presVerifier, r, pres, opts...); err != nil {
 		return false, errors.Wrapf(err, "error verifying presentation<%s>", token.JwtID())
 	}
 
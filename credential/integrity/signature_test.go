@@ -12,11 +12,17 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
 	"github.com/TBD54566975/ssi-sdk/did/key"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
 	"github.com/TBD54566975/ssi-sdk/did/web"
+	"github.com/TBD54566975/ssi-sdk/util"
 
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -257,6 +263,290 @@ func getTestJWTCredential(t *testing.T, signer jwx.Signer) string {
 	return string(signed)
 }
 
+func TestVerifyJWTCredentialOptions(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	sign := func(tt *testing.T, issued, expires time.Time, audience string) string {
+		cred := credential.VerifiableCredential{
+			ID:                uuid.NewString(),
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      issued.Format(time.RFC3339),
+			CredentialSubject: map[string]any{"id": "did:example:123"},
+		}
+		if !expires.IsZero() {
+			cred.ExpirationDate = expires.Format(time.RFC3339)
+		}
+		token, err := JWTClaimSetFromVC(cred)
+		require.NoError(tt, err)
+		if audience != "" {
+			require.NoError(tt, token.Set(jwt.AudienceKey, audience))
+		}
+		headers := jws.NewHeaders()
+		require.NoError(tt, headers.Set(jws.KeyIDKey, kid))
+		signed, err := jwt.Sign(token, jwt.WithKey(jwa.EdDSA, signer.PrivateKey, jws.WithProtectedHeaders(headers)))
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	now := time.Now()
+
+	t.Run("expiration and leeway", func(tt *testing.T) {
+		expired := sign(tt, now.Add(-time.Hour), now.Add(-time.Minute), "")
+		_, err := VerifyJWTCredential(context.Background(), expired, resolver)
+		assert.ErrorContains(tt, err, "exp")
+
+		verified, err := VerifyJWTCredential(context.Background(), expired, resolver, WithLeeway(5*time.Minute))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		notYetValid := sign(tt, now.Add(time.Minute), time.Time{}, "")
+		_, err = VerifyJWTCredential(context.Background(), notYetValid, resolver)
+		assert.Error(tt, err)
+
+		verified, err = VerifyJWTCredential(context.Background(), notYetValid, resolver, WithLeeway(5*time.Minute))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+	})
+
+	t.Run("audience", func(tt *testing.T) {
+		token := sign(tt, now, time.Time{}, "https://verifier.example.com")
+		verified, err := VerifyJWTCredential(context.Background(), token, resolver, WithAudience("https://verifier.example.com"))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		_, err = VerifyJWTCredential(context.Background(), token, resolver, WithAudience("https://other.example.com"))
+		assert.ErrorContains(tt, err, "aud")
+
+		_, err = VerifyJWTCredential(context.Background(), sign(tt, now, time.Time{}, ""), resolver, WithAudience("https://verifier.example.com"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("issuer pinning", func(tt *testing.T) {
+		token := sign(tt, now, time.Time{}, "")
+		verified, err := VerifyJWTCredential(context.Background(), token, resolver, WithIssuer(didKey.String()))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		_, err = VerifyJWTCredential(context.Background(), token, resolver, WithIssuer("did:example:other"))
+		assert.ErrorContains(tt, err, "iss")
+	})
+
+	t.Run("algorithm allowlist", func(tt *testing.T) {
+		token := sign(tt, now, time.Time{}, "")
+		verified, err := VerifyJWTCredential(context.Background(), token, resolver, WithAllowedAlgorithms("ES256", "EdDSA"))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		_, err = VerifyJWTCredential(context.Background(), token, resolver, WithAllowedAlgorithms("ES256"))
+		assert.ErrorContains(tt, err, "algorithm<EdDSA> is not allowed")
+	})
+
+	t.Run("maximum token age", func(tt *testing.T) {
+		old := sign(tt, now.Add(-2*time.Hour), time.Time{}, "")
+		_, err := VerifyJWTCredential(context.Background(), old, resolver, WithMaxTokenAge(time.Hour))
+		assert.ErrorContains(tt, err, "exceeds the maximum age of 1h0m0s")
+
+		verified, err := VerifyJWTCredential(context.Background(), old, resolver, WithMaxTokenAge(3*time.Hour))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+	})
+
+	t.Run("invalid option", func(tt *testing.T) {
+		token := sign(tt, now, time.Time{}, "")
+		_, err := VerifyJWTCredential(context.Background(), token, resolver, VerificationOption{ID: LeewayOption, Option: "5m"})
+		assert.ErrorContains(tt, err, "invalid leeway option type: string")
+	})
+}
+
+func TestVerifyDataIntegrityCredential(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	_, privKeyJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, privKey)
+	require.NoError(t, err)
+	signer, err := jws2020.NewJSONWebKeySigner(kid, *privKeyJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+	suite := jws2020.GetJSONWebSignature2020Suite()
+
+	t.Run("valid credential", func(tt *testing.T) {
+		cred := getTestCredential()
+		cred.Issuer = didKey.String()
+		assert.NoError(tt, suite.Sign(signer, &cred))
+
+		verified, err := VerifyDataIntegrityCredential(context.Background(), cred, resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		// verify through the generic entry point as well
+		verified, err = VerifyCredentialSignature(context.Background(), &cred, resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+	})
+
+	t.Run("tampered credential", func(tt *testing.T) {
+		cred := getTestCredential()
+		cred.Issuer = didKey.String()
+		assert.NoError(tt, suite.Sign(signer, &cred))
+
+		cred.IssuanceDate = "2022-01-01T19:23:24Z"
+		verified, err := VerifyDataIntegrityCredential(context.Background(), cred, resolver)
+		assert.Error(tt, err)
+		assert.False(tt, verified)
+	})
+
+	t.Run("unknown verification method", func(tt *testing.T) {
+		_, otherDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		assert.NoError(tt, err)
+		otherKeyJWK := *privKeyJWK
+		otherKeyJWK.KID = otherDIDKey.String() + "#bad-key"
+		otherSigner, err := jws2020.NewJSONWebKeySigner(otherKeyJWK.KID, otherKeyJWK, cryptosuite.AssertionMethod)
+		assert.NoError(tt, err)
+
+		cred := getTestCredential()
+		cred.Issuer = otherDIDKey.String()
+		assert.NoError(tt, suite.Sign(otherSigner, &cred))
+		_, err = VerifyDataIntegrityCredential(context.Background(), cred, resolver)
+		assert.ErrorContains(tt, err, "error getting key for verification method")
+	})
+
+	t.Run("credential signed by another DID", func(tt *testing.T) {
+		otherPrivKey, otherDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		otherExpanded, err := otherDIDKey.Expand()
+		require.NoError(tt, err)
+		otherKID := otherExpanded.VerificationMethod[0].ID
+		_, otherPrivKeyJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&otherKID, otherPrivKey)
+		require.NoError(tt, err)
+		otherSigner, err := jws2020.NewJSONWebKeySigner(otherKID, *otherPrivKeyJWK, cryptosuite.AssertionMethod)
+		require.NoError(tt, err)
+
+		cred := getTestCredential()
+		cred.Issuer = didKey.String()
+		assert.NoError(tt, suite.Sign(otherSigner, &cred))
+		verified, err := VerifyDataIntegrityCredential(context.Background(), cred, resolver)
+		assert.ErrorContains(tt, err, "is not of did")
+		assert.False(tt, verified)
+	})
+
+	t.Run("proof for another purpose", func(tt *testing.T) {
+		authSigner, err := jws2020.NewJSONWebKeySigner(kid, *privKeyJWK, cryptosuite.Authentication)
+		require.NoError(tt, err)
+
+		cred := getTestCredential()
+		cred.Issuer = didKey.String()
+		assert.NoError(tt, suite.Sign(authSigner, &cred))
+		verified, err := VerifyDataIntegrityCredential(context.Background(), cred, resolver)
+		assert.ErrorContains(tt, err, "proof purpose<authentication> must be assertionMethod")
+		assert.False(tt, verified)
+	})
+
+	t.Run("unresolvable DID", func(tt *testing.T) {
+		unknownKeyJWK := *privKeyJWK
+		unknownKeyJWK.KID = "did:example:123#key-1"
+		unknownSigner, err := jws2020.NewJSONWebKeySigner(unknownKeyJWK.KID, unknownKeyJWK, cryptosuite.AssertionMethod)
+		assert.NoError(tt, err)
+
+		cred := getTestCredential()
+		assert.NoError(tt, suite.Sign(unknownSigner, &cred))
+		_, err = VerifyDataIntegrityCredential(context.Background(), cred, resolver)
+		assert.ErrorContains(tt, err, "error resolving DID<did:example:123>")
+	})
+}
+
+func TestVerifyDataIntegrityPresentation(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	_, privKeyJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, privKey)
+	require.NoError(t, err)
+	suite := jws2020.GetJSONWebSignature2020Suite()
+
+	credSigner, err := jws2020.NewJSONWebKeySigner(kid, *privKeyJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+	cred := getTestCredential()
+	cred.Issuer = didKey.String()
+	require.NoError(t, suite.Sign(credSigner, &cred))
+
+	presSigner, err := jws2020.NewJSONWebKeySigner(kid, *privKeyJWK, cryptosuite.Authentication)
+	require.NoError(t, err)
+	signPresentation := func(tt *testing.T, creds ...any) credential.VerifiablePresentation {
+		pres := credential.VerifiablePresentation{
+			Context:              []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               didKey.String(),
+			VerifiableCredential: creds,
+		}
+		require.NoError(tt, suite.Sign(presSigner, &pres))
+		return pres
+	}
+
+	t.Run("valid presentation", func(tt *testing.T) {
+		pres := signPresentation(tt, cred)
+		verified, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver, WithChallenge(pres.GetProof().Challenge))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		// verify through the generic entry point as well
+		presMap, err := util.ToJSONMap(pres)
+		require.NoError(tt, err)
+		verified, err = VerifyPresentationSignature(context.Background(), presMap, resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+	})
+
+	t.Run("challenge mismatch", func(tt *testing.T) {
+		pres := signPresentation(tt, cred)
+		verified, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver, WithChallenge("other-challenge"))
+		assert.ErrorContains(tt, err, "challenge mismatch")
+		assert.False(tt, verified)
+	})
+
+	t.Run("tampered credential", func(tt *testing.T) {
+		tampered := cred
+		tampered.IssuanceDate = "2022-01-01T19:23:24Z"
+		pres := signPresentation(tt, tampered)
+		verified, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver)
+		assert.ErrorContains(tt, err, "verifying credential 0")
+		assert.False(tt, verified)
+	})
+
+	t.Run("presentation signed by another holder", func(tt *testing.T) {
+		pres := signPresentation(tt, cred)
+		pres.Holder = "did:example:123"
+		verified, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver, WithChallenge(pres.GetProof().Challenge))
+		assert.ErrorContains(tt, err, "is not of did<did:example:123>")
+		assert.False(tt, verified)
+	})
+
+	t.Run("no proof", func(tt *testing.T) {
+		pres := credential.VerifiablePresentation{
+			Context: []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+		}
+		_, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver)
+		assert.ErrorContains(tt, err, "presentation must have a proof")
+	})
+}
+
 func getTestCredential() credential.VerifiableCredential {
 	return credential.VerifiableCredential{
 		Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
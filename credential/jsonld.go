@@ -0,0 +1,83 @@
+package credential
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// UndefinedTerms expands a credential, or any other JSON-LD document, and returns the properties which JSON-LD
+// processors silently drop because none of the document's contexts define them, e.g. `credentialSubject.favoriteColor`.
+// Dropped properties are not signed by Data Integrity proofs, and are not understood by verifiers processing the
+// document as JSON-LD. Contexts are resolved with the provided loader, or with one preloaded with the contexts bundled
+// with the SDK if it is nil.
+func UndefinedTerms(document any, docLoader ld.DocumentLoader) ([]string, error) {
+	docMap, err := util.ToJSONMap(document)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting document to json map")
+	}
+	contexts, ok := docMap["@context"]
+	if !ok {
+		return nil, errors.New("document has no @context")
+	}
+
+	var processor *util.LDProcessor
+	if docLoader == nil {
+		if processor, err = util.NewLDProcessor(); err != nil {
+			return nil, errors.Wrap(err, "creating LD processor")
+		}
+	} else {
+		processor = util.NewLDProcessorWithDocumentLoader(docLoader)
+	}
+
+	// Terms which are not defined are dropped by expansion, so they are missing once the document is compacted again
+	expanded, err := processor.Expand(docMap, processor.GetOptions())
+	if err != nil {
+		return nil, errors.Wrap(err, "expanding document")
+	}
+	compacted, err := processor.Compact(expanded, map[string]any{"@context": contexts}, processor.GetOptions())
+	if err != nil {
+		return nil, errors.Wrap(err, "compacting document")
+	}
+
+	retained := make(map[string]bool)
+	collectTermPaths(compacted, "", func(path string) { retained[path] = true })
+	var undefined []string
+	seen := make(map[string]bool)
+	collectTermPaths(docMap, "", func(path string) {
+		if !retained[path] && !seen[path] {
+			seen[path] = true
+			undefined = append(undefined, path)
+		}
+	})
+	sort.Strings(undefined)
+	return undefined, nil
+}
+
+// collectTermPaths calls found with the dot-separated path of each property in a document, ignoring keywords, their
+// `id` and `type` aliases, and array indices
+func collectTermPaths(value any, prefix string, found func(path string)) {
+	switch v := value.(type) {
+	case map[string]any:
+		for term, child := range v {
+			// the base contexts alias id and type, which are lost when a node with only an id compacts to its IRI
+			if strings.HasPrefix(term, "@") || term == VerifiableCredentialIDProperty || term == "type" {
+				continue
+			}
+			path := term
+			if prefix != "" {
+				path = prefix + "." + term
+			}
+			found(path)
+			collectTermPaths(child, path, found)
+		}
+	case []any:
+		for _, child := range v {
+			collectTermPaths(child, prefix, found)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package credential
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUndefinedTerms(t *testing.T) {
+	t.Run("all terms defined", func(tt *testing.T) {
+		cred := VerifiableCredential{
+			Context:      []any{VerifiableCredentialsLinkedDataContext, "https://www.w3.org/2018/credentials/examples/v1"},
+			ID:           "http://example.edu/credentials/1872",
+			Type:         []any{VerifiableCredentialType, "UniversityDegreeCredential"},
+			Issuer:       "https://example.edu/issuers/565049",
+			IssuanceDate: "2010-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{
+				"id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+				"degree": map[string]any{
+					"type": "BachelorDegree",
+					"name": "Bachelor of Science and Arts",
+				},
+			},
+		}
+		undefined, err := UndefinedTerms(cred, nil)
+		assert.NoError(tt, err)
+		assert.Empty(tt, undefined)
+	})
+
+	t.Run("undefined terms", func(tt *testing.T) {
+		cred := VerifiableCredential{
+			Context:      []any{VerifiableCredentialsLinkedDataContext},
+			Type:         []any{VerifiableCredentialType},
+			Issuer:       "https://example.edu/issuers/565049",
+			IssuanceDate: "2010-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{
+				"id":            "did:example:ebfeb1f712ebc6f1c276e12ec21",
+				"favoriteColor": "blue",
+				"address": []any{
+					map[string]any{"city": "Berlin"},
+				},
+			},
+		}
+		undefined, err := UndefinedTerms(cred, nil)
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{"credentialSubject.address", "credentialSubject.address.city", "credentialSubject.favoriteColor"}, undefined)
+	})
+
+	t.Run("no context", func(tt *testing.T) {
+		_, err := UndefinedTerms(map[string]any{"name": "Alice"}, nil)
+		assert.ErrorContains(tt, err, "document has no @context")
+	})
+}
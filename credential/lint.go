@@ -0,0 +1,285 @@
+package credential
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/piprate/json-gold/ld"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// LintSeverity is how serious an issue found when linting a credential is
+type LintSeverity int
+
+const (
+	// LintInfo issues are worth knowing about, but are often intentional
+	LintInfo LintSeverity = iota
+	// LintWarning issues are likely mistakes, or are likely to cause problems for verifiers
+	LintWarning
+	// LintError issues are very likely to cause the credential to be rejected by verifiers
+	LintError
+)
+
+func (s LintSeverity) String() string {
+	switch s {
+	case LintInfo:
+		return "info"
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	default:
+		return fmt.Sprintf("unknown severity<%d>", s)
+	}
+}
+
+// Lint rules which issues are reported for
+const (
+	MissingIDLintRule      string = "missing-id"
+	ContextLintRule        string = "context"
+	UnknownContextLintRule string = "unknown-context"
+	TypeLintRule           string = "type"
+	UndefinedTypeLintRule  string = "undefined-type"
+	DateLintRule           string = "date"
+	SuspiciousDateLintRule string = "suspicious-date"
+	SubjectLintRule        string = "subject"
+	BroadSubjectLintRule   string = "broad-subject"
+	UndefinedTermLintRule  string = "undefined-term"
+)
+
+// DefaultMaxSubjectClaims is the number of claims about a subject above which it is considered overly broad
+const DefaultMaxSubjectClaims = 50
+
+// LintIssue is a non-fatal problem with a credential, which does not make it invalid according to IsValid
+type LintIssue struct {
+	Rule     string
+	Severity LintSeverity
+	// Property is the credential property the issue was found in
+	Property string
+	Message  string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("[%s] %s: %s (%s)", i.Severity, i.Property, i.Message, i.Rule)
+}
+
+// LintIssues are the issues found when linting a credential
+type LintIssues []LintIssue
+
+// AtLeast returns the issues with at least the given severity
+func (l LintIssues) AtLeast(severity LintSeverity) LintIssues {
+	var issues LintIssues
+	for _, issue := range l {
+		if issue.Severity >= severity {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// HasErrors returns whether any issue has the error severity
+func (l LintIssues) HasErrors() bool {
+	return len(l.AtLeast(LintError)) > 0
+}
+
+// LintOptions configures which issues are found when linting a credential
+type LintOptions struct {
+	// KnownContexts are contexts which are expected in credentials, in addition to the base contexts and those bundled
+	// with the SDK
+	KnownContexts []string
+	// Now is the time dates are checked against, defaulting to the current time
+	Now time.Time
+	// MaxSubjectClaims is the number of claims about a subject above which it is considered overly broad, defaulting
+	// to DefaultMaxSubjectClaims
+	MaxSubjectClaims int
+	// CheckJSONLDTerms expands the credential as JSON-LD to find properties which are not defined by its contexts
+	CheckJSONLDTerms bool
+	// DocumentLoader resolves the credential's contexts when checking JSON-LD terms, defaulting to one preloaded with
+	// the contexts bundled with the SDK
+	DocumentLoader ld.DocumentLoader
+}
+
+// Lint checks a credential for issues which do not make it invalid, but which issuers may want to catch before
+// issuing it, such as missing ids, unknown contexts, undefined types, suspicious dates, and overly broad subjects.
+// Issues are returned in the order the credential's properties are checked.
+func Lint(cred VerifiableCredential, opts LintOptions) LintIssues {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	maxSubjectClaims := opts.MaxSubjectClaims
+	if maxSubjectClaims <= 0 {
+		maxSubjectClaims = DefaultMaxSubjectClaims
+	}
+
+	var issues LintIssues
+	add := func(rule string, severity LintSeverity, property, format string, args ...any) {
+		issues = append(issues, LintIssue{Rule: rule, Severity: severity, Property: property, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if cred.ID == "" {
+		add(MissingIDLintRule, LintInfo, "id", "credential has no id, so it cannot be referred to, e.g. for revocation")
+	} else if !isValidURI(cred.ID) {
+		add(MissingIDLintRule, LintWarning, "id", "credential id<%s> is not a URI", cred.ID)
+	}
+
+	customContexts := lintContexts(cred.Context, opts.KnownContexts, add)
+	lintTypes(cred.Type, customContexts, add)
+	lintDates(cred, now, add)
+	lintSubject(cred.CredentialSubject, maxSubjectClaims, add)
+	if opts.CheckJSONLDTerms {
+		lintTerms(cred, opts.DocumentLoader, add)
+	}
+	return issues
+}
+
+type lintAdder func(rule string, severity LintSeverity, property, format string, args ...any)
+
+// lintContexts checks the credential's contexts, returning whether it has any contexts besides the base contexts
+func lintContexts(context any, knownContexts []string, add lintAdder) bool {
+	contexts, err := util.InterfaceToInterfaceArray(context)
+	if err != nil || len(contexts) == 0 || context == nil {
+		add(ContextLintRule, LintError, "@context", "credential has no contexts")
+		return false
+	}
+	if first, ok := contexts[0].(string); !ok || (first != VerifiableCredentialsLinkedDataContext && first != VerifiableCredentialsV2LinkedDataContext) {
+		add(ContextLintRule, LintError, "@context", "the first context must be <%s> or <%s>", VerifiableCredentialsLinkedDataContext, VerifiableCredentialsV2LinkedDataContext)
+	}
+
+	known := util.GetKnownContexts()
+	known[VerifiableCredentialsV2LinkedDataContext] = ""
+	for _, c := range knownContexts {
+		known[c] = ""
+	}
+	hasCustomContexts := false
+	for i, c := range contexts {
+		property := fmt.Sprintf("@context[%d]", i)
+		contextURL, ok := c.(string)
+		if !ok {
+			hasCustomContexts = true
+			add(UnknownContextLintRule, LintInfo, property, "embedded contexts are not shared with verifiers, who may not be able to process them")
+			continue
+		}
+		if contextURL != VerifiableCredentialsLinkedDataContext && contextURL != VerifiableCredentialsV2LinkedDataContext {
+			hasCustomContexts = true
+		}
+		if !isValidURI(contextURL) {
+			add(UnknownContextLintRule, LintError, property, "context<%s> is not a URL", contextURL)
+			continue
+		}
+		if _, ok = known[contextURL]; !ok {
+			add(UnknownContextLintRule, LintWarning, property, "context<%s> is not known, so verifiers may need to fetch it", contextURL)
+		}
+	}
+	return hasCustomContexts
+}
+
+// lintTypes checks the credential's types. Types which are not URIs must be defined by a context, so they are
+// undefined if the credential only has the base contexts.
+func lintTypes(credTypes any, hasCustomContexts bool, add lintAdder) {
+	types, err := util.InterfaceToStrings(credTypes)
+	if err != nil || len(types) == 0 {
+		add(TypeLintRule, LintError, "type", "credential has no types")
+		return
+	}
+	if !util.Contains(VerifiableCredentialType, types) {
+		add(TypeLintRule, LintError, "type", "credential types must include <%s>", VerifiableCredentialType)
+	}
+	if len(types) == 1 && types[0] == VerifiableCredentialType {
+		add(TypeLintRule, LintInfo, "type", "credential has no type besides <%s>, so verifiers cannot tell what it is", VerifiableCredentialType)
+	}
+	for _, t := range types {
+		if t == VerifiableCredentialType || isValidURI(t) {
+			continue
+		}
+		if strings.ContainsAny(t, " \t\n") {
+			add(UndefinedTypeLintRule, LintError, "type", "type<%s> contains whitespace", t)
+		} else if !hasCustomContexts {
+			add(UndefinedTypeLintRule, LintWarning, "type", "type<%s> is not a URI and no context defines it", t)
+		}
+	}
+}
+
+// lintDates checks the credential's dates are well-formed and consistent with each other and now
+func lintDates(cred VerifiableCredential, now time.Time, add lintAdder) {
+	parse := func(property, value string) *time.Time {
+		if value == "" {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			add(DateLintRule, LintError, property, "date<%s> is not an RFC3339 timestamp", value)
+			return nil
+		}
+		return &t
+	}
+	starts := []struct {
+		property string
+		t        *time.Time
+	}{{"issuanceDate", parse("issuanceDate", cred.IssuanceDate)}, {"validFrom", parse("validFrom", cred.ValidFrom)}}
+	ends := []struct {
+		property string
+		t        *time.Time
+	}{{"expirationDate", parse("expirationDate", cred.ExpirationDate)}, {"validUntil", parse("validUntil", cred.ValidUntil)}}
+
+	if cred.IssuanceDate == "" && cred.ValidFrom == "" {
+		add(DateLintRule, LintWarning, "issuanceDate", "credential has neither an issuanceDate nor a validFrom date")
+	}
+	for _, start := range starts {
+		if start.t != nil && start.t.After(now.Add(DefaultClockSkew)) {
+			add(SuspiciousDateLintRule, LintWarning, start.property, "credential is not valid until %s", start.t.Format(time.RFC3339))
+		}
+	}
+	for _, end := range ends {
+		if end.t == nil {
+			continue
+		}
+		if end.t.Before(now) {
+			add(SuspiciousDateLintRule, LintWarning, end.property, "credential expired at %s", end.t.Format(time.RFC3339))
+		}
+		for _, start := range starts {
+			if start.t != nil && !end.t.After(*start.t) {
+				add(SuspiciousDateLintRule, LintError, end.property, "%s is not after %s", end.property, start.property)
+			}
+		}
+	}
+}
+
+// lintSubject checks the credential subject makes claims about an identified subject, without claiming too much
+func lintSubject(subject CredentialSubject, maxSubjectClaims int, add lintAdder) {
+	if len(subject) == 0 {
+		add(SubjectLintRule, LintError, "credentialSubject", "credential makes no claims")
+		return
+	}
+	id, hasID := subject[VerifiableCredentialIDProperty]
+	if !hasID {
+		add(MissingIDLintRule, LintInfo, "credentialSubject.id", "subject has no id, so the credential is a bearer credential")
+	} else if idStr, ok := id.(string); !ok || !isValidURI(idStr) {
+		add(MissingIDLintRule, LintWarning, "credentialSubject.id", "subject id<%v> is not a URI", id)
+	}
+	claims := len(subject)
+	if hasID {
+		claims--
+	}
+	if claims == 0 {
+		add(SubjectLintRule, LintWarning, "credentialSubject", "credential makes no claims about its subject besides its id")
+	}
+	if claims > maxSubjectClaims {
+		add(BroadSubjectLintRule, LintWarning, "credentialSubject", "subject has %d claims, more than %d, so the credential may disclose more than needed", claims, maxSubjectClaims)
+	}
+}
+
+// lintTerms checks the credential's properties are all defined by its contexts, since JSON-LD processors silently
+// drop those which are not
+func lintTerms(cred VerifiableCredential, docLoader ld.DocumentLoader, add lintAdder) {
+	undefined, err := UndefinedTerms(cred, docLoader)
+	if err != nil {
+		add(UndefinedTermLintRule, LintError, "@context", "credential could not be processed as JSON-LD: %s", err.Error())
+		return
+	}
+	for _, term := range undefined {
+		add(UndefinedTermLintRule, LintWarning, term, "property is not defined by any context, so it is dropped by JSON-LD processors")
+	}
+}
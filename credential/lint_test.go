@@ -0,0 +1,116 @@
+package credential
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	opts := LintOptions{Now: now}
+	getLintCredential := func() VerifiableCredential {
+		return VerifiableCredential{
+			Context:           []any{VerifiableCredentialsLinkedDataContext, "https://www.w3.org/2018/credentials/examples/v1"},
+			ID:                "urn:uuid:3978344f-8596-4c3a-a978-8fcaba3903c5",
+			Type:              []string{VerifiableCredentialType, "UniversityDegreeCredential"},
+			Issuer:            "did:example:issuer",
+			IssuanceDate:      "2023-01-01T00:00:00Z",
+			ExpirationDate:    "2024-01-01T00:00:00Z",
+			CredentialSubject: CredentialSubject{"id": "did:example:subject", "degree": "Bachelor of Science"},
+		}
+	}
+	rules := func(issues LintIssues) []string {
+		var found []string
+		for _, issue := range issues {
+			found = append(found, fmt.Sprintf("%s:%s:%s", issue.Severity, issue.Rule, issue.Property))
+		}
+		return found
+	}
+
+	t.Run("clean credential", func(tt *testing.T) {
+		issues := Lint(getLintCredential(), opts)
+		assert.Empty(tt, issues)
+		assert.False(tt, issues.HasErrors())
+	})
+
+	t.Run("missing ids", func(tt *testing.T) {
+		cred := getLintCredential()
+		cred.ID = ""
+		delete(cred.CredentialSubject, "id")
+		issues := Lint(cred, opts)
+		assert.Equal(tt, []string{"info:missing-id:id", "info:missing-id:credentialSubject.id"}, rules(issues))
+		assert.Empty(tt, issues.AtLeast(LintWarning))
+
+		cred.ID = "not a uri"
+		cred.CredentialSubject["id"] = "subject"
+		assert.Equal(tt, []string{"warning:missing-id:id", "warning:missing-id:credentialSubject.id"}, rules(Lint(cred, opts)))
+	})
+
+	t.Run("contexts", func(tt *testing.T) {
+		cred := getLintCredential()
+		cred.Context = []any{"https://example.com/custom/v1", VerifiableCredentialsLinkedDataContext, map[string]any{"degree": "https://example.com/degree"}}
+		issues := Lint(cred, opts)
+		assert.Equal(tt, []string{"error:context:@context", "warning:unknown-context:@context[0]", "info:unknown-context:@context[2]"}, rules(issues))
+		assert.True(tt, issues.HasErrors())
+
+		cred.Context = []any{VerifiableCredentialsLinkedDataContext, "https://example.com/custom/v1"}
+		assert.Empty(tt, Lint(cred, LintOptions{Now: now, KnownContexts: []string{"https://example.com/custom/v1"}}))
+	})
+
+	t.Run("types", func(tt *testing.T) {
+		cred := getLintCredential()
+		cred.Context = []any{VerifiableCredentialsV2LinkedDataContext}
+		cred.Type = []string{"University Degree"}
+		assert.Equal(tt, []string{"error:type:type", "error:undefined-type:type"}, rules(Lint(cred, opts)))
+
+		cred.Type = []string{VerifiableCredentialType, "UniversityDegreeCredential", "https://example.com/UniversityDegree"}
+		assert.Equal(tt, []string{"warning:undefined-type:type"}, rules(Lint(cred, opts)))
+
+		cred.Type = VerifiableCredentialType
+		assert.Equal(tt, []string{"info:type:type"}, rules(Lint(cred, opts)))
+	})
+
+	t.Run("dates", func(tt *testing.T) {
+		cred := getLintCredential()
+		cred.IssuanceDate = "2023-07-01T00:00:00Z"
+		cred.ExpirationDate = "2023-05-01T00:00:00Z"
+		assert.Equal(tt, []string{"warning:suspicious-date:issuanceDate", "warning:suspicious-date:expirationDate", "error:suspicious-date:expirationDate"}, rules(Lint(cred, opts)))
+
+		cred.IssuanceDate = ""
+		cred.ExpirationDate = "tomorrow"
+		assert.Equal(tt, []string{"error:date:expirationDate", "warning:date:issuanceDate"}, rules(Lint(cred, opts)))
+
+		cred.ExpirationDate = ""
+		cred.ValidFrom = "2023-01-01T00:00:00Z"
+		assert.Empty(tt, Lint(cred, opts))
+	})
+
+	t.Run("subjects", func(tt *testing.T) {
+		cred := getLintCredential()
+		cred.CredentialSubject = CredentialSubject{"id": "did:example:subject"}
+		assert.Equal(tt, []string{"warning:subject:credentialSubject"}, rules(Lint(cred, opts)))
+
+		cred.CredentialSubject = nil
+		assert.Equal(tt, []string{"error:subject:credentialSubject"}, rules(Lint(cred, opts)))
+
+		cred.CredentialSubject = CredentialSubject{"id": "did:example:subject", "a": 1, "b": 2, "c": 3}
+		assert.Equal(tt, []string{"warning:broad-subject:credentialSubject"}, rules(Lint(cred, LintOptions{Now: now, MaxSubjectClaims: 2})))
+	})
+
+	t.Run("json-ld terms", func(tt *testing.T) {
+		jsonLDOpts := LintOptions{Now: now, CheckJSONLDTerms: true}
+		cred := getLintCredential()
+		assert.Empty(tt, Lint(cred, jsonLDOpts))
+
+		cred.CredentialSubject["favoriteColor"] = "blue"
+		assert.Equal(tt, []string{"warning:undefined-term:credentialSubject.favoriteColor"}, rules(Lint(cred, jsonLDOpts)))
+	})
+
+	t.Run("issue string", func(tt *testing.T) {
+		issue := LintIssue{Rule: MissingIDLintRule, Severity: LintInfo, Property: "id", Message: "credential has no id"}
+		assert.Equal(tt, "[info] id: credential has no id (missing-id)", issue.String())
+	})
+}
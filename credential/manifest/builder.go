@@ -1,7 +1,9 @@
 package manifest
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -10,6 +12,13 @@ import (
 	"github.com/TBD54566975/ssi-sdk/util"
 )
 
+const (
+	// presentationCredentialsPathPrefix and applicationCredentialsPathPrefix select credentials in a presentation,
+	// and those submitted with an application, in a CredentialApplicationWrapper
+	presentationCredentialsPathPrefix = "$.verifiableCredential"
+	applicationCredentialsPathPrefix  = "$.verifiableCredentials"
+)
+
 const (
 	BuilderEmptyError string = "builder cannot be empty"
 	SpecVersion       string = "https://identity.foundation/credential-manifest/spec/v1.0.0/"
@@ -134,6 +143,8 @@ func (cmb *CredentialManifestBuilder) SetPresentationDefinition(definition excha
 
 type CredentialApplicationBuilder struct {
 	*CredentialApplication
+	// credentials submitted with the application, selected by its presentation submission
+	credentials []ClaimEnvelope
 }
 
 func NewCredentialApplicationBuilder(manifestID string) CredentialApplicationBuilder {
@@ -158,6 +169,16 @@ func (cab *CredentialApplicationBuilder) Build() (*CredentialApplication, error)
 	return cab.CredentialApplication, nil
 }
 
+// BuildWrapper builds the credential application along with the credentials it submits, as set by
+// SetPresentationSubmissionForManifest
+func (cab *CredentialApplicationBuilder) BuildWrapper() (*CredentialApplicationWrapper, error) {
+	application, err := cab.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &CredentialApplicationWrapper{CredentialApplication: *application, Credentials: cab.credentials}, nil
+}
+
 func (cab *CredentialApplicationBuilder) IsEmpty() bool {
 	if cab == nil || cab.CredentialApplication.IsEmpty() {
 		return true
@@ -315,3 +336,59 @@ func (crb *CredentialResponseBuilder) SetDenial(reason string, inputDescriptors
 	}
 	return nil
 }
+
+// SetPresentationSubmissionForManifest sets the manifest the application is for and, using the presentation exchange
+// matching engine, builds a presentation submission fulfilling the manifest's presentation definition from a
+// wallet's credentials. The credentials selected are submitted with the application, where the submission's paths
+// select them, as built by BuildWrapper. Without a format of its own, the application takes the manifest's or, if it
+// has none, that of its presentation definition.
+func (cab *CredentialApplicationBuilder) SetPresentationSubmissionForManifest(cm CredentialManifest, claims []exchange.PresentationClaim) error {
+	if cab.IsEmpty() {
+		return errors.New(BuilderEmptyError)
+	}
+
+	cab.ManifestID = cm.ID
+	if cab.Format == nil {
+		if !cm.Format.IsEmpty() {
+			format := *cm.Format
+			cab.Format = &format
+		} else if !cm.PresentationDefinition.IsEmpty() && cm.PresentationDefinition.Format != nil {
+			format := *cm.PresentationDefinition.Format
+			cab.Format = &format
+		}
+	}
+
+	// a manifest without a presentation definition needs no submission
+	if cm.PresentationDefinition.IsEmpty() {
+		cab.PresentationSubmission = nil
+		cab.credentials = nil
+		return nil
+	}
+
+	normalizedClaims, err := exchange.NormalizePresentationClaims(claims)
+	if err != nil {
+		return errors.Wrap(err, "normalizing claims")
+	}
+	vp, err := exchange.BuildPresentationSubmissionVP(cab.Applicant, *cm.PresentationDefinition, normalizedClaims)
+	if err != nil {
+		return errors.Wrap(err, "building presentation submission for manifest")
+	}
+	submission, ok := vp.PresentationSubmission.(exchange.PresentationSubmission)
+	if !ok {
+		return fmt.Errorf("unexpected presentation submission type<%T>", vp.PresentationSubmission)
+	}
+
+	// credentials are submitted alongside the application, rather than in a presentation
+	for i, d := range submission.DescriptorMap {
+		submission.DescriptorMap[i].Path = applicationCredentialsPathPrefix + strings.TrimPrefix(d.Path, presentationCredentialsPathPrefix)
+	}
+	if err = cab.SetPresentationSubmission(submission); err != nil {
+		return err
+	}
+	credentials, err := NewClaimEnvelopes(vp.VerifiableCredential...)
+	if err != nil {
+		return errors.Wrap(err, "enveloping submitted credentials")
+	}
+	cab.credentials = credentials
+	return nil
+}
@@ -3,7 +3,9 @@ package manifest
 import (
 	"testing"
 
+	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/TBD54566975/ssi-sdk/crypto"
@@ -168,6 +170,50 @@ func TestCredentialApplicationBuilder(t *testing.T) {
 	assert.NotEmpty(t, application)
 }
 
+func TestCredentialApplicationBuilderForManifest(t *testing.T) {
+	cm, wrapper := getValidTestCredManifestCredApplicationJWTCred(t)
+	// relational constraints are not supported in building submissions
+	cm.PresentationDefinition.InputDescriptors[0].Constraints.SubjectIsIssuer = nil
+	token := wrapper.Credentials[0].Claim().(string)
+	claims := []exchange.PresentationClaim{{
+		Token:                         &token,
+		JWTFormat:                     exchange.JWTVC.Ptr(),
+		SignatureAlgorithmOrProofType: string(crypto.EdDSA),
+	}}
+
+	t.Run("builds a valid application from a wallet's credentials", func(tt *testing.T) {
+		builder := NewCredentialApplicationBuilder("")
+		require.NoError(tt, builder.SetApplicantID("did:example:123"))
+		require.NoError(tt, builder.SetPresentationSubmissionForManifest(cm, claims))
+
+		applicationWrapper, err := builder.BuildWrapper()
+		require.NoError(tt, err)
+		assert.Equal(tt, cm.ID, applicationWrapper.CredentialApplication.ManifestID)
+		assert.Equal(tt, cm.PresentationDefinition.Format, applicationWrapper.CredentialApplication.Format)
+		require.Len(tt, applicationWrapper.Credentials, 1)
+		assert.Equal(tt, JWTEnvelope, applicationWrapper.Credentials[0].Format())
+		assert.Equal(tt, token, applicationWrapper.Credentials[0].Claim())
+		descriptorMap := applicationWrapper.CredentialApplication.PresentationSubmission.DescriptorMap
+		require.Len(tt, descriptorMap, 1)
+		assert.Equal(tt, "$.verifiableCredentials[0]", descriptorMap[0].Path)
+
+		applicationBytes, err := json.Marshal(applicationWrapper)
+		require.NoError(tt, err)
+		var applicationJSON map[string]any
+		require.NoError(tt, json.Unmarshal(applicationBytes, &applicationJSON))
+		unfulfilledIDs, err := IsValidCredentialApplicationForManifest(cm, applicationJSON)
+		assert.NoError(tt, err)
+		assert.Empty(tt, unfulfilledIDs)
+	})
+
+	t.Run("credentials cannot fulfill the manifest", func(tt *testing.T) {
+		builder := NewCredentialApplicationBuilder("")
+		require.NoError(tt, builder.SetApplicantID("did:example:123"))
+		err := builder.SetPresentationSubmissionForManifest(cm, nil)
+		assert.ErrorContains(tt, err, "building presentation submission for manifest")
+	})
+}
+
 func TestCredentialResponseBuilder(t *testing.T) {
 	t.Run("test credential fulfillment builder", func(tt *testing.T) {
 		builder := NewCredentialResponseBuilder("manifest-id")
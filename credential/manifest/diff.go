@@ -0,0 +1,343 @@
+package manifest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// ChangeSeverity is whether a change to a credential manifest breaks applicants of its previous version
+type ChangeSeverity string
+
+const (
+	// BreakingChange may reject applications, or issue credentials, which the previous version of the manifest did
+	// not, such as by removing an output descriptor or tightening an input descriptor
+	BreakingChange ChangeSeverity = "breaking"
+	// CompatibleChange accepts every application the previous version of the manifest did, issuing the same credentials
+	CompatibleChange ChangeSeverity = "compatible"
+)
+
+// ManifestChange is a change between two versions of a credential manifest
+type ManifestChange struct {
+	Severity ChangeSeverity `json:"severity"`
+	// Property locates the changed property in the manifest, with descriptors and fields given by id
+	Property    string `json:"property"`
+	Description string `json:"description"`
+}
+
+// ManifestDiff is every change between two versions of a credential manifest
+type ManifestDiff struct {
+	Changes []ManifestChange `json:"changes,omitempty"`
+}
+
+// IsBreaking returns whether any change is breaking
+func (md *ManifestDiff) IsBreaking() bool {
+	return len(md.Breaking()) > 0
+}
+
+// Breaking returns the breaking changes
+func (md *ManifestDiff) Breaking() []ManifestChange {
+	var breaking []ManifestChange
+	for _, change := range md.Changes {
+		if change.Severity == BreakingChange {
+			breaking = append(breaking, change)
+		}
+	}
+	return breaking
+}
+
+func (md *ManifestDiff) add(severity ChangeSeverity, property, description string, a ...any) {
+	md.Changes = append(md.Changes, ManifestChange{
+		Severity:    severity,
+		Property:    property,
+		Description: fmt.Sprintf(description, a...),
+	})
+}
+
+// DiffManifests diffs two versions of a credential manifest, reporting which changes break applicants of the previous
+// version, so issuers can evolve manifests safely. Breaking changes are those which remove or change output
+// descriptors, narrow formats, and tighten the presentation definition, such as by adding input descriptors or
+// requiring fields. Changes which cannot be told to be compatible, such as to filters, are taken to be breaking.
+func DiffManifests(previous, next CredentialManifest) (*ManifestDiff, error) {
+	diff := ManifestDiff{}
+	if previous.ID != next.ID {
+		diff.add(BreakingChange, "id", "manifest id changed from %s to %s, which applications reference", previous.ID, next.ID)
+	}
+	if previous.Issuer.ID != next.Issuer.ID {
+		diff.add(BreakingChange, "issuer.id", "issuer changed from %s to %s", previous.Issuer.ID, next.Issuer.ID)
+	}
+
+	diffOutputDescriptors(&diff, previous.OutputDescriptors, next.OutputDescriptors)
+	if err := diffFormats(&diff, "format", previous.Format, next.Format); err != nil {
+		return nil, errors.Wrap(err, "diffing manifest formats")
+	}
+	if err := diffPresentationDefinitions(&diff, previous.PresentationDefinition, next.PresentationDefinition); err != nil {
+		return nil, errors.Wrap(err, "diffing presentation definitions")
+	}
+	return &diff, nil
+}
+
+// diffOutputDescriptors diffs output descriptors by id; applicants may rely on each credential a manifest issues
+func diffOutputDescriptors(diff *ManifestDiff, previous, next []OutputDescriptor) {
+	nextByID := make(map[string]OutputDescriptor, len(next))
+	for _, od := range next {
+		nextByID[od.ID] = od
+	}
+	previousIDs := make(map[string]bool, len(previous))
+	for _, previousOD := range previous {
+		previousIDs[previousOD.ID] = true
+		property := fmt.Sprintf("output_descriptors[%s]", previousOD.ID)
+		nextOD, ok := nextByID[previousOD.ID]
+		if !ok {
+			diff.add(BreakingChange, property, "output descriptor removed")
+			continue
+		}
+		if previousOD.Schema != nextOD.Schema {
+			diff.add(BreakingChange, property+".schema", "schema changed from %s to %s", previousOD.Schema, nextOD.Schema)
+		}
+		previousOD.Schema, nextOD.Schema = "", ""
+		if !reflect.DeepEqual(previousOD, nextOD) {
+			diff.add(CompatibleChange, property, "output descriptor display changed")
+		}
+	}
+	for _, od := range next {
+		if !previousIDs[od.ID] {
+			diff.add(CompatibleChange, fmt.Sprintf("output_descriptors[%s]", od.ID), "output descriptor added")
+		}
+	}
+}
+
+// diffFormats diffs claim formats, where no format is any format. Narrowing formats, or their algorithms and proof
+// types, is breaking: it may reject claims, or issue credentials, in a format applicants rely on.
+func diffFormats(diff *ManifestDiff, property string, previous, next *exchange.ClaimFormat) error {
+	if reflect.DeepEqual(previous, next) || (previous.IsEmpty() && next.IsEmpty()) {
+		return nil
+	}
+	if previous.IsEmpty() {
+		diff.add(BreakingChange, property, "formats restricted to %s", strings.Join(next.FormatValues(), ", "))
+		return nil
+	}
+	if next.IsEmpty() {
+		diff.add(CompatibleChange, property, "formats no longer restricted")
+		return nil
+	}
+
+	previousValues, err := formatValues(*previous)
+	if err != nil {
+		return err
+	}
+	nextValues, err := formatValues(*next)
+	if err != nil {
+		return err
+	}
+	for _, format := range sortedKeys(previousValues) {
+		nextFormatValues, ok := nextValues[format]
+		if !ok {
+			diff.add(BreakingChange, property+"."+format, "format removed")
+			continue
+		}
+		for _, value := range previousValues[format] {
+			if !util.Contains(value, nextFormatValues) {
+				diff.add(BreakingChange, property+"."+format, "%s removed", value)
+			}
+		}
+		for _, value := range nextFormatValues {
+			if !util.Contains(value, previousValues[format]) {
+				diff.add(CompatibleChange, property+"."+format, "%s added", value)
+			}
+		}
+	}
+	for _, format := range sortedKeys(nextValues) {
+		if _, ok := previousValues[format]; !ok {
+			diff.add(CompatibleChange, property+"."+format, "format added")
+		}
+	}
+	return nil
+}
+
+// formatValues returns the algorithms and proof types of each format of a claim format, by format
+func formatValues(format exchange.ClaimFormat) (map[string][]string, error) {
+	formatJSON, err := util.ToJSONMap(format)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting claim format to JSON")
+	}
+	values := make(map[string][]string, len(formatJSON))
+	for name, formatType := range formatJSON {
+		values[name] = []string{}
+		formatTypeJSON, ok := formatType.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, property := range sortedKeys(formatTypeJSON) {
+			propertyValues, ok := formatTypeJSON[property].([]any)
+			if !ok {
+				continue
+			}
+			for _, value := range propertyValues {
+				values[name] = append(values[name], fmt.Sprintf("%s %v", property, value))
+			}
+		}
+	}
+	return values, nil
+}
+
+// diffPresentationDefinitions diffs the presentation definitions applications must fulfill
+func diffPresentationDefinitions(diff *ManifestDiff, previous, next *exchange.PresentationDefinition) error {
+	const property = "presentation_definition"
+	switch {
+	case previous.IsEmpty() && next.IsEmpty():
+		return nil
+	case previous.IsEmpty():
+		diff.add(BreakingChange, property, "presentation definition added, which applications must fulfill")
+		return nil
+	case next.IsEmpty():
+		diff.add(CompatibleChange, property, "presentation definition removed")
+		return nil
+	}
+
+	if previous.ID != next.ID {
+		diff.add(BreakingChange, property+".id", "presentation definition id changed from %s to %s, which "+
+			"presentation submissions reference", previous.ID, next.ID)
+	}
+	if err := diffFormats(diff, property+".format", previous.Format, next.Format); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(previous.SubmissionRequirements, next.SubmissionRequirements) {
+		diff.add(BreakingChange, property+".submission_requirements", "submission requirements changed")
+	}
+	if !reflect.DeepEqual(previous.Frame, next.Frame) {
+		diff.add(BreakingChange, property+".frame", "frame changed")
+	}
+
+	nextByID := make(map[string]exchange.InputDescriptor, len(next.InputDescriptors))
+	for _, id := range next.InputDescriptors {
+		nextByID[id.ID] = id
+	}
+	previousIDs := make(map[string]bool, len(previous.InputDescriptors))
+	for _, previousID := range previous.InputDescriptors {
+		previousIDs[previousID.ID] = true
+		idProperty := fmt.Sprintf("%s.input_descriptors[%s]", property, previousID.ID)
+		nextID, ok := nextByID[previousID.ID]
+		if !ok {
+			diff.add(CompatibleChange, idProperty, "input descriptor removed")
+			continue
+		}
+		if err := diffInputDescriptors(diff, idProperty, previousID, nextID); err != nil {
+			return err
+		}
+	}
+	for _, id := range next.InputDescriptors {
+		if !previousIDs[id.ID] {
+			diff.add(BreakingChange, fmt.Sprintf("%s.input_descriptors[%s]", property, id.ID),
+				"input descriptor added, which applications must fulfill")
+		}
+	}
+	return nil
+}
+
+// diffInputDescriptors diffs two versions of an input descriptor, where tightening its constraints is breaking
+func diffInputDescriptors(diff *ManifestDiff, property string, previous, next exchange.InputDescriptor) error {
+	if err := diffFormats(diff, property+".format", previous.Format, next.Format); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(previous.Group, next.Group) {
+		diff.add(BreakingChange, property+".group", "groups changed")
+	}
+
+	var previousConstraints, nextConstraints exchange.Constraints
+	if previous.Constraints != nil {
+		previousConstraints = *previous.Constraints
+	}
+	if next.Constraints != nil {
+		nextConstraints = *next.Constraints
+	}
+	constraintsProperty := property + ".constraints"
+	if isRequired(nextConstraints.LimitDisclosure) && !isRequired(previousConstraints.LimitDisclosure) {
+		diff.add(BreakingChange, constraintsProperty+".limit_disclosure", "limited disclosure required")
+	}
+	if !reflect.DeepEqual(previousConstraints.SubjectIsIssuer, nextConstraints.SubjectIsIssuer) ||
+		!reflect.DeepEqual(previousConstraints.IsHolder, nextConstraints.IsHolder) ||
+		!reflect.DeepEqual(previousConstraints.SameSubject, nextConstraints.SameSubject) {
+		diff.add(BreakingChange, constraintsProperty, "relational constraints changed")
+	}
+	if !reflect.DeepEqual(previousConstraints.Statuses, nextConstraints.Statuses) {
+		diff.add(BreakingChange, constraintsProperty+".statuses", "status constraints changed")
+	}
+
+	diffFields(diff, constraintsProperty+".fields", previousConstraints.Fields, nextConstraints.Fields)
+	return nil
+}
+
+// diffFields diffs the fields of an input descriptor, identified by id or, without one, by their paths
+func diffFields(diff *ManifestDiff, property string, previous, next []exchange.Field) {
+	fieldKey := func(field exchange.Field) string {
+		if field.ID != "" {
+			return field.ID
+		}
+		return strings.Join(field.Path, ",")
+	}
+	nextByKey := make(map[string]exchange.Field, len(next))
+	for _, field := range next {
+		nextByKey[fieldKey(field)] = field
+	}
+	previousKeys := make(map[string]bool, len(previous))
+	for _, previousField := range previous {
+		key := fieldKey(previousField)
+		previousKeys[key] = true
+		fieldProperty := fmt.Sprintf("%s[%s]", property, key)
+		nextField, ok := nextByKey[key]
+		if !ok {
+			diff.add(CompatibleChange, fieldProperty, "field removed")
+			continue
+		}
+		if previousField.Optional && !nextField.Optional {
+			diff.add(BreakingChange, fieldProperty+".optional", "field required")
+		} else if !previousField.Optional && nextField.Optional {
+			diff.add(CompatibleChange, fieldProperty+".optional", "field made optional")
+		}
+		for _, path := range previousField.Path {
+			if !util.Contains(path, nextField.Path) {
+				diff.add(BreakingChange, fieldProperty+".path", "path %s removed", path)
+			}
+		}
+		for _, path := range nextField.Path {
+			if !util.Contains(path, previousField.Path) {
+				diff.add(CompatibleChange, fieldProperty+".path", "path %s added", path)
+			}
+		}
+		if !reflect.DeepEqual(previousField.Filter, nextField.Filter) || !reflect.DeepEqual(previousField.Predicate, nextField.Predicate) {
+			diff.add(BreakingChange, fieldProperty+".filter", "filter changed, which may reject claims it accepted")
+		}
+	}
+	for _, field := range next {
+		key := fieldKey(field)
+		if previousKeys[key] {
+			continue
+		}
+		fieldProperty := fmt.Sprintf("%s[%s]", property, key)
+		if field.Optional {
+			diff.add(CompatibleChange, fieldProperty, "optional field added")
+		} else {
+			diff.add(BreakingChange, fieldProperty, "required field added")
+		}
+	}
+}
+
+func isRequired(preference *exchange.Preference) bool {
+	return preference != nil && *preference == exchange.Required
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
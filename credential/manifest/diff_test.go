@@ -0,0 +1,84 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestDiffManifests(t *testing.T) {
+	getManifest := func(tt *testing.T) CredentialManifest {
+		manifestJSON, err := getTestVector(FullManifestVector)
+		require.NoError(tt, err)
+		var cm CredentialManifest
+		require.NoError(tt, json.Unmarshal([]byte(manifestJSON), &cm))
+		return cm
+	}
+
+	t.Run("same manifest", func(tt *testing.T) {
+		diff, err := DiffManifests(getManifest(tt), getManifest(tt))
+		require.NoError(tt, err)
+		assert.Empty(tt, diff.Changes)
+		assert.False(tt, diff.IsBreaking())
+	})
+
+	t.Run("compatible changes", func(tt *testing.T) {
+		previous, next := getManifest(tt), getManifest(tt)
+		next.OutputDescriptors = append(next.OutputDescriptors, OutputDescriptor{ID: "new_credential", Schema: "https://example.com/new.json"})
+		fields := next.PresentationDefinition.InputDescriptors[0].Constraints.Fields
+		fields[0].Optional = true
+		next.PresentationDefinition.InputDescriptors[0].Constraints.Fields = append(fields, exchange.Field{
+			ID:       "nickname",
+			Path:     []string{"$.credentialSubject.nickname"},
+			Optional: true,
+		})
+		next.PresentationDefinition.Format.JWT.Alg = append(next.PresentationDefinition.Format.JWT.Alg, crypto.ES256)
+
+		diff, err := DiffManifests(previous, next)
+		require.NoError(tt, err)
+		assert.False(tt, diff.IsBreaking())
+		assert.Len(tt, diff.Changes, 4)
+	})
+
+	t.Run("breaking changes", func(tt *testing.T) {
+		previous, next := getManifest(tt), getManifest(tt)
+		next.OutputDescriptors[0].Schema = "https://example.com/other.json"
+		next.Format = &exchange.ClaimFormat{JWTVC: &exchange.JWTType{Alg: []crypto.SignatureAlgorithm{crypto.EdDSA}}}
+		next.PresentationDefinition.InputDescriptors[0].Constraints.Fields = append(
+			next.PresentationDefinition.InputDescriptors[0].Constraints.Fields,
+			exchange.Field{ID: "licenseNumber", Path: []string{"$.credentialSubject.licenseNumber"}},
+		)
+		next.PresentationDefinition.Format.JWT.Alg = []crypto.SignatureAlgorithm{crypto.ES256}
+
+		diff, err := DiffManifests(previous, next)
+		require.NoError(tt, err)
+		assert.True(tt, diff.IsBreaking())
+
+		var breaking []string
+		for _, change := range diff.Breaking() {
+			breaking = append(breaking, change.Property)
+		}
+		assert.ElementsMatch(tt, []string{
+			"output_descriptors[kyc_credential].schema",
+			"format",
+			"presentation_definition.format.jwt",
+			"presentation_definition.input_descriptors[kycid1].constraints.fields[licenseNumber]",
+		}, breaking)
+	})
+
+	t.Run("removed output descriptor", func(tt *testing.T) {
+		previous, next := getManifest(tt), getManifest(tt)
+		next.OutputDescriptors = nil
+		next.PresentationDefinition = nil
+
+		diff, err := DiffManifests(previous, next)
+		require.NoError(tt, err)
+		require.Len(tt, diff.Breaking(), 1)
+		assert.Equal(tt, "output descriptor removed", diff.Breaking()[0].Description)
+	})
+}
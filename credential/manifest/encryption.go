@@ -0,0 +1,90 @@
+package manifest
+
+import (
+	"context"
+	gocrypto "crypto"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+const (
+	// ApplicationKeyAlgorithm is the algorithm by which credential applications are encrypted to issuers' key
+	// agreement keys, which may be X25519 or NIST curve keys
+	ApplicationKeyAlgorithm = jwa.ECDH_ES_A256KW
+	// ApplicationContentEncryption is the algorithm by which credential applications are encrypted
+	ApplicationContentEncryption = jwa.A256GCM
+)
+
+// EncryptCredentialApplication encrypts a credential application, with the credentials it submits, as a compact JWE
+// to an issuer's key agreement key, identified by its kid, since applications carry credentials laden with personal
+// information in transit
+func EncryptCredentialApplication(application CredentialApplicationWrapper, kid string, issuerKey gocrypto.PublicKey) ([]byte, error) {
+	if err := application.CredentialApplication.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "credential application not valid")
+	}
+	applicationBytes, err := json.Marshal(application)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential application")
+	}
+
+	key, err := jwk.FromRaw(issuerKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting issuer key to JWK")
+	}
+	headers := jwe.NewHeaders()
+	if kid != "" {
+		if err = headers.Set(jwe.KeyIDKey, kid); err != nil {
+			return nil, errors.Wrap(err, "setting kid")
+		}
+	}
+	encrypted, err := jwe.Encrypt(applicationBytes,
+		jwe.WithKey(ApplicationKeyAlgorithm, key),
+		jwe.WithContentEncryption(ApplicationContentEncryption),
+		jwe.WithProtectedHeaders(headers))
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypting credential application")
+	}
+	return encrypted, nil
+}
+
+// EncryptCredentialApplicationForIssuer encrypts a credential application, as EncryptCredentialApplication does, to
+// the first key agreement key of the issuer of its credential manifest [cm], resolving the issuer's DID
+func EncryptCredentialApplicationForIssuer(ctx context.Context, r resolution.Resolver, cm CredentialManifest, application CredentialApplicationWrapper) ([]byte, error) {
+	if r == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	resolved, err := r.Resolve(ctx, cm.Issuer.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving issuer<%s>", cm.Issuer.ID)
+	}
+	kid, issuerKey, err := did.GetKeyAgreementKey(resolved.Document)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting key agreement key of issuer<%s>", cm.Issuer.ID)
+	}
+	return EncryptCredentialApplication(application, kid, issuerKey)
+}
+
+// DecryptCredentialApplication decrypts a credential application encrypted to an issuer's key agreement key, as by
+// EncryptCredentialApplication, with the issuer's private key
+func DecryptCredentialApplication(encrypted []byte, issuerKey gocrypto.PrivateKey) (*CredentialApplicationWrapper, error) {
+	key, err := jwk.FromRaw(issuerKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting issuer key to JWK")
+	}
+	decrypted, err := jwe.Decrypt(encrypted, jwe.WithKey(ApplicationKeyAlgorithm, key))
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting credential application")
+	}
+	var application CredentialApplicationWrapper
+	if err = json.Unmarshal(decrypted, &application); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling credential application")
+	}
+	return &application, nil
+}
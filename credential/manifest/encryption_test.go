@@ -0,0 +1,46 @@
+package manifest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+)
+
+func TestEncryptCredentialApplication(t *testing.T) {
+	cm, application := getValidTestCredManifestCredApplicationJWTCred(t)
+
+	t.Run("issuer decrypts an application encrypted to its key agreement key", func(tt *testing.T) {
+		issuerPrivKey, issuerDID, err := key.GenerateDIDKey(crypto.X25519)
+		require.NoError(tt, err)
+		cm.Issuer.ID = issuerDID.String()
+
+		encrypted, err := EncryptCredentialApplicationForIssuer(context.Background(), key.Resolver{}, cm, application)
+		require.NoError(tt, err)
+		assert.NotContains(tt, string(encrypted), application.CredentialApplication.ID)
+
+		decrypted, err := DecryptCredentialApplication(encrypted, issuerPrivKey)
+		require.NoError(tt, err)
+		assert.Equal(tt, application.CredentialApplication.ID, decrypted.CredentialApplication.ID)
+		assert.Equal(tt, application.CredentialApplication.PresentationSubmission, decrypted.CredentialApplication.PresentationSubmission)
+		assert.Equal(tt, application.Credentials, decrypted.Credentials)
+	})
+
+	t.Run("cannot decrypt with another key", func(tt *testing.T) {
+		issuerPubKey, _, err := crypto.GenerateX25519Key()
+		require.NoError(tt, err)
+		_, otherPrivKey, err := crypto.GenerateX25519Key()
+		require.NoError(tt, err)
+
+		encrypted, err := EncryptCredentialApplication(application, "did:example:issuer#key-1", issuerPubKey)
+		require.NoError(tt, err)
+
+		_, err = DecryptCredentialApplication(encrypted, otherPrivKey)
+		assert.ErrorContains(tt, err, "decrypting credential application")
+	})
+
+}
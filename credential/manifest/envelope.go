@@ -0,0 +1,199 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	credutil "github.com/TBD54566975/ssi-sdk/credential/parsing"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// EnvelopeFormat is how a claim submitted with a credential application, or issued with a credential response, is
+// represented
+type EnvelopeFormat string
+
+const (
+	// LDEnvelope is a claim as a JSON object, such as a credential secured with a Data Integrity proof, an unsecured
+	// credential, or the decoded claims of a JWT
+	LDEnvelope EnvelopeFormat = "ld"
+	// JWTEnvelope is a claim as a compact JWT, such as a `jwt_vc` or a v2.0 credential secured with JOSE
+	JWTEnvelope EnvelopeFormat = "jwt"
+	// SDJWTEnvelope is a claim as an SD-JWT, an issuer-signed JWT followed by its disclosures
+	SDJWTEnvelope EnvelopeFormat = "sd-jwt"
+
+	sdJWTSeparator = "~"
+	proofProperty  = "proof"
+)
+
+// ClaimEnvelope holds a claim submitted with a credential application, or issued with a credential response, in
+// the representation it is exchanged in, which it marshals to and from: a JSON object for a linked data claim, and a
+// string for a JWT or SD-JWT
+type ClaimEnvelope struct {
+	format EnvelopeFormat
+	object map[string]any
+	token  string
+}
+
+// NewClaimEnvelope envelops a claim, which may be a VerifiableCredential, a JSON object, a compact JWT or SD-JWT, or
+// the JSON of any of them
+func NewClaimEnvelope(claim any) (*ClaimEnvelope, error) {
+	switch typedClaim := claim.(type) {
+	case nil:
+		return nil, errors.New("claim cannot be empty")
+	case ClaimEnvelope:
+		return NewClaimEnvelope(&typedClaim)
+	case *ClaimEnvelope:
+		if typedClaim.IsEmpty() {
+			return nil, errors.New("claim cannot be empty")
+		}
+		envelope := *typedClaim
+		return &envelope, nil
+	case string:
+		return envelopeFromString(typedClaim)
+	case []byte:
+		return envelopeFromString(string(typedClaim))
+	case map[string]any:
+		if len(typedClaim) == 0 {
+			return nil, errors.New("claim cannot be empty")
+		}
+		return &ClaimEnvelope{format: LDEnvelope, object: typedClaim}, nil
+	}
+	object, err := util.ToJSONMap(claim)
+	if err != nil {
+		return nil, errors.Wrapf(err, "claim of type<%T> is not a JSON object", claim)
+	}
+	return NewClaimEnvelope(object)
+}
+
+// NewClaimEnvelopes envelops each of the given claims, as NewClaimEnvelope does
+func NewClaimEnvelopes(claims ...any) ([]ClaimEnvelope, error) {
+	envelopes := make([]ClaimEnvelope, 0, len(claims))
+	for i, claim := range claims {
+		envelope, err := NewClaimEnvelope(claim)
+		if err != nil {
+			return nil, errors.Wrapf(err, "enveloping claim<%d>", i)
+		}
+		envelopes = append(envelopes, *envelope)
+	}
+	return envelopes, nil
+}
+
+// envelopeFromString envelops a claim which is a compact JWT or SD-JWT or, if it is a JSON object, that object
+func envelopeFromString(claim string) (*ClaimEnvelope, error) {
+	trimmed := strings.TrimSpace(claim)
+	if strings.HasPrefix(trimmed, "{") {
+		var object map[string]any
+		if err := json.Unmarshal([]byte(trimmed), &object); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling claim object")
+		}
+		return NewClaimEnvelope(object)
+	}
+
+	// only the issuer-signed JWT of an SD-JWT is compact, the disclosures following it
+	format := JWTEnvelope
+	issuerJWT, _, isSDJWT := strings.Cut(trimmed, sdJWTSeparator)
+	if isSDJWT {
+		format = SDJWTEnvelope
+	}
+	if strings.Count(issuerJWT, ".") != 2 {
+		return nil, errors.New("claim is neither a JSON object nor a compact JWT")
+	}
+	return &ClaimEnvelope{format: format, token: trimmed}, nil
+}
+
+// IsEmpty returns whether the envelope holds no claim
+func (ce *ClaimEnvelope) IsEmpty() bool {
+	return ce == nil || (len(ce.object) == 0 && ce.token == "")
+}
+
+// Format returns how the claim is represented
+func (ce ClaimEnvelope) Format() EnvelopeFormat {
+	return ce.format
+}
+
+// Claim returns the claim as it is represented: a JSON object or a token
+func (ce ClaimEnvelope) Claim() any {
+	if ce.format == LDEnvelope {
+		return ce.object
+	}
+	return ce.token
+}
+
+// AcceptsFormat returns whether the claim may be of the given claim format designation, such as that of a submission
+// descriptor. Tokens are only of the formats securing them. Objects are of the linked data formats and, if they have
+// no proof, which they do not when unsecured or when they are the decoded claims of a JWT, of the JWT formats.
+// https://identity.foundation/claim-format-registry/#registry
+func (ce ClaimEnvelope) AcceptsFormat(format string) bool {
+	isJWTFormat := util.Contains(format, []string{exchange.JWT.String(), exchange.JWTVC.String(), exchange.JWTVP.String()})
+	switch ce.format {
+	case LDEnvelope:
+		if util.Contains(format, []string{exchange.LDP.String(), exchange.LDPVC.String(), exchange.LDPVP.String()}) {
+			return true
+		}
+		_, hasProof := ce.object[proofProperty]
+		return isJWTFormat && !hasProof
+	case JWTEnvelope:
+		return isJWTFormat
+	case SDJWTEnvelope:
+		return format == exchange.SDJWTVC.String()
+	}
+	return false
+}
+
+// Parse parses the claim into a credential, with the JSON its input descriptors' fields are evaluated against
+func (ce ClaimEnvelope) Parse() (*ParsedCredential, error) {
+	if ce.IsEmpty() {
+		return nil, errors.New("claim cannot be empty")
+	}
+	_, token, cred, err := credutil.ToCredential(ce.Claim())
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s claim", ce.format)
+	}
+	// a JWT's fields are evaluated against its claims, and an object need not be converted again
+	credJSON := ce.object
+	if token != nil {
+		credJSON, err = util.ToJSONMap(token)
+	} else if ce.format != LDEnvelope {
+		credJSON, err = util.ToJSONMap(cred)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "converting credential to JSON")
+	}
+	return &ParsedCredential{Envelope: ce, Credential: cred, JSON: credJSON}, nil
+}
+
+func (ce ClaimEnvelope) MarshalJSON() ([]byte, error) {
+	if ce.IsEmpty() {
+		return nil, errors.New("cannot marshal empty claim envelope")
+	}
+	return json.Marshal(ce.Claim())
+}
+
+func (ce *ClaimEnvelope) UnmarshalJSON(data []byte) error {
+	var claim any
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var object map[string]any
+		if err := json.Unmarshal(trimmed, &object); err != nil {
+			return errors.Wrap(err, "unmarshalling claim object")
+		}
+		claim = object
+	} else {
+		var token string
+		if err := json.Unmarshal(trimmed, &token); err != nil {
+			return fmt.Errorf("claim must be a JSON object or a token: %s", err.Error())
+		}
+		claim = token
+	}
+	envelope, err := NewClaimEnvelope(claim)
+	if err != nil {
+		return err
+	}
+	*ce = *envelope
+	return nil
+}
@@ -0,0 +1,81 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+)
+
+func TestClaimEnvelope(t *testing.T) {
+	_, ldApplication := getValidTestCredManifestCredApplication(t)
+	_, jwtApplication := getValidTestCredManifestCredApplicationJWTCred(t)
+	ldEnvelope := ldApplication.Credentials[0]
+	jwtEnvelope := jwtApplication.Credentials[0]
+
+	t.Run("envelopes are format aware", func(tt *testing.T) {
+		assert.Equal(tt, LDEnvelope, ldEnvelope.Format())
+		assert.Equal(tt, JWTEnvelope, jwtEnvelope.Format())
+
+		sdJWTEnvelope, err := NewClaimEnvelope(jwtEnvelope.Claim().(string) + "~disclosure~")
+		require.NoError(tt, err)
+		assert.Equal(tt, SDJWTEnvelope, sdJWTEnvelope.Format())
+
+		assert.True(tt, ldEnvelope.AcceptsFormat(exchange.LDPVC.String()))
+		assert.True(tt, jwtEnvelope.AcceptsFormat(exchange.JWTVC.String()))
+		assert.False(tt, jwtEnvelope.AcceptsFormat(exchange.LDPVC.String()))
+		assert.True(tt, sdJWTEnvelope.AcceptsFormat(exchange.SDJWTVC.String()))
+		assert.False(tt, sdJWTEnvelope.AcceptsFormat(exchange.JWTVC.String()))
+	})
+
+	t.Run("objects with proofs are not JWTs", func(tt *testing.T) {
+		// an object without a proof may be the decoded claims of a JWT
+		assert.True(tt, ldEnvelope.AcceptsFormat(exchange.JWTVC.String()))
+
+		object := ldEnvelope.Claim().(map[string]any)
+		proven := make(map[string]any, len(object)+1)
+		for k, v := range object {
+			proven[k] = v
+		}
+		proven["proof"] = map[string]any{"type": "JsonWebSignature2020"}
+		provenEnvelope, err := NewClaimEnvelope(proven)
+		require.NoError(tt, err)
+		assert.False(tt, provenEnvelope.AcceptsFormat(exchange.JWTVC.String()))
+	})
+
+	t.Run("envelopes round trip through JSON", func(tt *testing.T) {
+		envelopesBytes, err := json.Marshal([]ClaimEnvelope{ldEnvelope, jwtEnvelope})
+		require.NoError(tt, err)
+
+		var envelopes []ClaimEnvelope
+		require.NoError(tt, json.Unmarshal(envelopesBytes, &envelopes))
+		assert.Equal(tt, []ClaimEnvelope{ldEnvelope, jwtEnvelope}, envelopes)
+	})
+
+	t.Run("envelopes parse to the same credential", func(tt *testing.T) {
+		parsedLD, err := ldEnvelope.Parse()
+		require.NoError(tt, err)
+		parsedJWT, err := jwtEnvelope.Parse()
+		require.NoError(tt, err)
+		assert.Equal(tt, parsedLD.Credential.ID, parsedJWT.Credential.ID)
+		assert.Equal(tt, ldEnvelope, parsedLD.Envelope)
+
+		// a JWT's fields are evaluated against its claims
+		assert.Contains(tt, parsedJWT.JSON, "vc")
+	})
+
+	t.Run("claims which cannot be enveloped", func(tt *testing.T) {
+		for _, claim := range []any{nil, "", "not-a-jwt", map[string]any{}, 5} {
+			_, err := NewClaimEnvelope(claim)
+			assert.Error(tt, err)
+		}
+
+		var envelope ClaimEnvelope
+		assert.Error(tt, json.Unmarshal([]byte(`5`), &envelope))
+		_, err := json.Marshal(envelope)
+		assert.Error(tt, err)
+	})
+}
@@ -84,9 +84,36 @@ func (od *OutputDescriptor) IsValid() error {
 	return util.NewValidator().Struct(od)
 }
 
+// ResolveDisplay resolves the output descriptor's display for a credential it describes, of any format supported by
+// parsing.ToCredential, into the values to display for it. An output descriptor without a display has none.
+func (od *OutputDescriptor) ResolveDisplay(genericCred any) (*rendering.ResolvedDataDisplay, error) {
+	if od.Display == nil {
+		return &rendering.ResolvedDataDisplay{}, nil
+	}
+	resolved, err := rendering.ResolveDataDisplayForCredential(*od.Display, genericCred)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving display of output descriptor<%s>", od.ID)
+	}
+	return resolved, nil
+}
+
+// RenderCard renders a credential the output descriptor describes, of any format supported by
+// parsing.ToCredential, as a card of the given format with the output descriptor's styles and display
+func (od *OutputDescriptor) RenderCard(format rendering.CardFormat, genericCred any) ([]byte, error) {
+	resolved, err := od.ResolveDisplay(genericCred)
+	if err != nil {
+		return nil, err
+	}
+	card, err := rendering.RenderCard(format, od.Styles, *resolved)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering card of output descriptor<%s>", od.ID)
+	}
+	return card, nil
+}
+
 type CredentialApplicationWrapper struct {
 	CredentialApplication CredentialApplication `json:"credential_application"`
-	Credentials           []any                 `json:"verifiableCredentials,omitempty"`
+	Credentials           []ClaimEnvelope       `json:"verifiableCredentials,omitempty"`
 }
 
 // CredentialApplication https://identity.foundation/credential-manifest/#credential-application
@@ -124,7 +151,7 @@ func (ca *CredentialApplication) IsValid() error {
 
 type CredentialResponseWrapper struct {
 	CredentialResponse CredentialResponse `json:"credential_response"`
-	Credentials        []any              `json:"verifiableCredentials,omitempty"`
+	Credentials        []ClaimEnvelope    `json:"verifiableCredentials,omitempty"`
 }
 
 // CredentialResponse https://identity.foundation/credential-manifest/#credential-response
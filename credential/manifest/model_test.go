@@ -6,6 +6,9 @@ import (
 
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
 )
 
 const (
@@ -126,6 +129,41 @@ func TestCredentialResponse(t *testing.T) {
 	})
 }
 
+func TestOutputDescriptorResolveDisplay(t *testing.T) {
+	vector, err := getTestVector(ManifestVector2)
+	require.NoError(t, err)
+	var cm struct {
+		OutputDescriptors []OutputDescriptor `json:"output_descriptors"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(vector), &cm))
+	require.NotEmpty(t, cm.OutputDescriptors)
+
+	vcJSON, err := getTestVector(FullCredentialVector)
+	require.NoError(t, err)
+	var vc credential.VerifiableCredential
+	require.NoError(t, json.Unmarshal([]byte(vcJSON), &vc))
+
+	t.Run("unresolved paths display their fallbacks", func(tt *testing.T) {
+		od := cm.OutputDescriptors[0]
+		resolved, err := od.ResolveDisplay(vc)
+		require.NoError(tt, err)
+		assert.Equal(tt, "Washington State Driver License", resolved.Title.Text)
+		assert.True(tt, resolved.Title.IsFallback)
+		assert.Equal(tt, "Class A, Commercial", resolved.Subtitle.Text)
+		assert.Equal(tt, *od.Display.Description.Text, resolved.Description.Text)
+		require.Len(tt, resolved.Properties, 1)
+		assert.Equal(tt, "Organ Donor", resolved.Properties[0].Label)
+		assert.Equal(tt, "Unknown", resolved.Properties[0].Text)
+	})
+
+	t.Run("output descriptors without a display have nothing to display", func(tt *testing.T) {
+		od := OutputDescriptor{ID: "kyc_credential", Schema: "https://schema.org/Person"}
+		resolved, err := od.ResolveDisplay(vc)
+		require.NoError(tt, err)
+		assert.Empty(tt, resolved)
+	})
+}
+
 func getTestVector(fileName string) (string, error) {
 	b, err := testVectors.ReadFile("testdata/" + fileName)
 	return string(b), err
@@ -0,0 +1,174 @@
+package manifest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	errresp "github.com/TBD54566975/ssi-sdk/error"
+)
+
+var (
+	// ErrApplicationReplayed is the error of processing a credential application, by id or nonce, already processed
+	ErrApplicationReplayed = errors.New("credential application replayed")
+	// ErrApplicantThrottled is the error of processing a credential application of an applicant applying too often
+	ErrApplicantThrottled = errors.New("applicant throttled")
+)
+
+// ReplayDetector detects credential applications an issuer has already received
+type ReplayDetector interface {
+	// CheckAndRecord records the id of an application and the nonce it is bound to, if any, returning whether either
+	// was already recorded
+	CheckAndRecord(ctx context.Context, applicationID, nonce string) (replayed bool, err error)
+}
+
+// ApplicantThrottle limits how often an applicant may apply for credentials
+type ApplicantThrottle interface {
+	// Allow records an application of the applicant, by DID, returning whether the applicant may apply
+	Allow(ctx context.Context, applicant string) (bool, error)
+}
+
+// ApplicationProcessor validates credential applications as issuers process them, first throttling applicants and,
+// once an application is valid, detecting replays. Either hook may be nil to skip it.
+type ApplicationProcessor struct {
+	ReplayDetector ReplayDetector
+	Throttle       ApplicantThrottle
+}
+
+// ProcessCredentialApplication validates a credential application for its credential manifest [cm] as
+// IsValidCredentialApplicationForManifest does, with the processor's hooks. The nonce is the one the application
+// is bound to, such as the challenge of the presentation submitting it, and may be empty. An application which is
+// throttled or replayed fails with an ApplicationError wrapping ErrApplicantThrottled or ErrApplicationReplayed.
+func (ap ApplicationProcessor) ProcessCredentialApplication(ctx context.Context, cm CredentialManifest, applicationAndCredsJSON map[string]any, nonce string) (map[string]string, error) {
+	var applicationID, applicant string
+	if applicationJSON, ok := applicationAndCredsJSON[CredentialApplicationJSONProperty].(map[string]any); ok {
+		applicationID, _ = applicationJSON["id"].(string)
+		applicant, _ = applicationJSON["applicant"].(string)
+	}
+	return ap.process(ctx, applicationID, applicant, nonce, func() (map[string]string, error) {
+		return IsValidCredentialApplicationForManifest(cm, applicationAndCredsJSON)
+	})
+}
+
+// ProcessParsedCredentialApplication validates a credential application whose credentials are already parsed, as
+// IsValidParsedCredentialApplicationForManifest does, with the processor's hooks, as ProcessCredentialApplication
+// does
+func (ap ApplicationProcessor) ProcessParsedCredentialApplication(ctx context.Context, cm CredentialManifest, application ParsedCredentialApplication, nonce string) (map[string]string, error) {
+	return ap.process(ctx, application.Application.ID, application.Application.Applicant, nonce, func() (map[string]string, error) {
+		return IsValidParsedCredentialApplicationForManifest(cm, application)
+	})
+}
+
+// process throttles the applicant, validates the application, and, once it is valid, detects whether it is replayed,
+// such that invalid applications do not use up their ids
+func (ap ApplicationProcessor) process(ctx context.Context, applicationID, applicant, nonce string, validate func() (map[string]string, error)) (map[string]string, error) {
+	if ap.Throttle != nil && applicant != "" {
+		allowed, err := ap.Throttle.Allow(ctx, applicant)
+		if err != nil {
+			return nil, errresp.NewErrorResponseWithErrorAndMsgf(errresp.CriticalError, err, "throttling applicant<%s>", applicant)
+		}
+		if !allowed {
+			return nil, errresp.NewErrorResponseWithErrorAndMsgf(errresp.ApplicationError, ErrApplicantThrottled, "applicant<%s>", applicant)
+		}
+	}
+
+	unfulfilled, err := validate()
+	if err != nil {
+		return unfulfilled, err
+	}
+
+	if ap.ReplayDetector != nil {
+		replayed, err := ap.ReplayDetector.CheckAndRecord(ctx, applicationID, nonce)
+		if err != nil {
+			return nil, errresp.NewErrorResponseWithErrorAndMsgf(errresp.CriticalError, err, "detecting replay of application<%s>", applicationID)
+		}
+		if replayed {
+			return nil, errresp.NewErrorResponseWithErrorAndMsgf(errresp.ApplicationError, ErrApplicationReplayed, "application<%s>", applicationID)
+		}
+	}
+	return unfulfilled, nil
+}
+
+// MemoryReplayDetector is a ReplayDetector recording application ids and nonces in memory for a window, after which
+// they may be reused
+type MemoryReplayDetector struct {
+	mu       sync.Mutex
+	window   time.Duration
+	recorded map[string]time.Time
+}
+
+var _ ReplayDetector = (*MemoryReplayDetector)(nil)
+
+// NewMemoryReplayDetector creates a replay detector recording ids and nonces for the given window
+func NewMemoryReplayDetector(window time.Duration) *MemoryReplayDetector {
+	return &MemoryReplayDetector{window: window, recorded: make(map[string]time.Time)}
+}
+
+func (m *MemoryReplayDetector) CheckAndRecord(_ context.Context, applicationID, nonce string) (bool, error) {
+	if applicationID == "" {
+		return false, errors.New("application id cannot be empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for key, recordedAt := range m.recorded {
+		if now.Sub(recordedAt) >= m.window {
+			delete(m.recorded, key)
+		}
+	}
+
+	// ids and nonces are recorded apart, so neither may be reused with another of the other
+	keys := []string{"id:" + applicationID}
+	if nonce != "" {
+		keys = append(keys, "nonce:"+nonce)
+	}
+	replayed := false
+	for _, key := range keys {
+		if _, ok := m.recorded[key]; ok {
+			replayed = true
+		}
+		m.recorded[key] = now
+	}
+	return replayed, nil
+}
+
+// MemoryApplicantThrottle is an ApplicantThrottle allowing each applicant, in memory, a number of applications per
+// sliding window
+type MemoryApplicantThrottle struct {
+	mu           sync.Mutex
+	limit        int
+	window       time.Duration
+	applications map[string][]time.Time
+}
+
+var _ ApplicantThrottle = (*MemoryApplicantThrottle)(nil)
+
+// NewMemoryApplicantThrottle creates a throttle allowing each applicant limit applications per window
+func NewMemoryApplicantThrottle(limit int, window time.Duration) *MemoryApplicantThrottle {
+	return &MemoryApplicantThrottle{limit: limit, window: window, applications: make(map[string][]time.Time)}
+}
+
+func (m *MemoryApplicantThrottle) Allow(_ context.Context, applicant string) (bool, error) {
+	if applicant == "" {
+		return false, errors.New("applicant cannot be empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var recent []time.Time
+	for _, appliedAt := range m.applications[applicant] {
+		if now.Sub(appliedAt) < m.window {
+			recent = append(recent, appliedAt)
+		}
+	}
+	if len(recent) >= m.limit {
+		m.applications[applicant] = recent
+		return false, nil
+	}
+	m.applications[applicant] = append(recent, now)
+	return true, nil
+}
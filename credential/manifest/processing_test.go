@@ -0,0 +1,96 @@
+package manifest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errresp "github.com/TBD54566975/ssi-sdk/error"
+)
+
+func TestApplicationProcessor(t *testing.T) {
+	toJSON := func(tt *testing.T, ca CredentialApplicationWrapper) map[string]any {
+		credAppRequestBytes, err := json.Marshal(ca)
+		require.NoError(tt, err)
+		request := make(map[string]any)
+		require.NoError(tt, json.Unmarshal(credAppRequestBytes, &request))
+		return request
+	}
+
+	t.Run("replayed applications are rejected", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		processor := ApplicationProcessor{ReplayDetector: NewMemoryReplayDetector(time.Hour)}
+
+		unfulfilledIDs, err := processor.ProcessCredentialApplication(context.Background(), cm, toJSON(tt, ca), "nonce-1")
+		assert.NoError(tt, err)
+		assert.Empty(tt, unfulfilledIDs)
+
+		_, err = processor.ProcessCredentialApplication(context.Background(), cm, toJSON(tt, ca), "nonce-2")
+		require.Error(tt, err)
+		errResp := errresp.GetErrorResponse(err)
+		assert.Equal(tt, errresp.ApplicationError, errResp.ErrorType)
+		assert.True(tt, errors.Is(errResp.Err, ErrApplicationReplayed))
+
+		// a nonce cannot be reused by another application
+		ca.CredentialApplication.ID = "another-application"
+		_, err = processor.ProcessCredentialApplication(context.Background(), cm, toJSON(tt, ca), "nonce-1")
+		assert.True(tt, errors.Is(errresp.GetErrorResponse(err).Err, ErrApplicationReplayed))
+	})
+
+	t.Run("invalid applications do not use up their ids", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		processor := ApplicationProcessor{ReplayDetector: NewMemoryReplayDetector(time.Hour)}
+
+		invalid := ca
+		invalid.CredentialApplication.ManifestID = "bad-id"
+		_, err := processor.ProcessCredentialApplication(context.Background(), cm, toJSON(tt, invalid), "")
+		assert.ErrorContains(tt, err, "must be equal to the credential manifest's id")
+
+		_, err = processor.ProcessCredentialApplication(context.Background(), cm, toJSON(tt, ca), "")
+		assert.NoError(tt, err)
+	})
+
+	t.Run("applicants applying too often are throttled", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		parsed, err := NewParsedCredentialApplication(ca.CredentialApplication, ca.Credentials)
+		require.NoError(tt, err)
+		processor := ApplicationProcessor{Throttle: NewMemoryApplicantThrottle(2, time.Hour)}
+
+		for i := 0; i < 2; i++ {
+			_, err = processor.ProcessParsedCredentialApplication(context.Background(), cm, *parsed, "")
+			assert.NoError(tt, err)
+		}
+		_, err = processor.ProcessParsedCredentialApplication(context.Background(), cm, *parsed, "")
+		require.Error(tt, err)
+		assert.True(tt, errors.Is(errresp.GetErrorResponse(err).Err, ErrApplicantThrottled))
+	})
+}
+
+func TestMemoryApplicantThrottle(t *testing.T) {
+	throttle := NewMemoryApplicantThrottle(1, 50*time.Millisecond)
+	allowed, err := throttle.Allow(context.Background(), "did:example:123")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = throttle.Allow(context.Background(), "did:example:123")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	// other applicants are throttled apart
+	allowed, err = throttle.Allow(context.Background(), "did:example:456")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	time.Sleep(60 * time.Millisecond)
+	allowed, err = throttle.Allow(context.Background(), "did:example:123")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	_, err = throttle.Allow(context.Background(), "")
+	assert.Error(t, err)
+}
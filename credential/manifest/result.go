@@ -0,0 +1,122 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+
+	errresp "github.com/TBD54566975/ssi-sdk/error"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// ValidationFailure is a check failed in validating a credential manifest, application, or response
+type ValidationFailure struct {
+	// ErrorType is the type of the failure's error response, where an ApplicationError is a failure of the objects
+	// validated, rather than of validating them
+	ErrorType errresp.Type `json:"errorType"`
+	// DescriptorID is the id of the input or output descriptor not fulfilled, if the failure is one
+	DescriptorID string `json:"descriptorId,omitempty"`
+	// Reason is why the check failed
+	Reason string `json:"reason"`
+}
+
+func (vf ValidationFailure) Error() string {
+	if vf.DescriptorID != "" {
+		return fmt.Sprintf("descriptor<%s> not fulfilled: %s", vf.DescriptorID, vf.Reason)
+	}
+	return vf.Reason
+}
+
+// ValidationResult is every check failed in validating a credential manifest, application, or response, such that
+// issuers can give applicants complete feedback
+type ValidationResult struct {
+	Failures []ValidationFailure `json:"failures,omitempty"`
+}
+
+// IsValid returns whether no check failed
+func (vr *ValidationResult) IsValid() bool {
+	return len(vr.Failures) == 0
+}
+
+// Unfulfilled returns the reason each unfulfilled input or output descriptor, by id, was not fulfilled
+func (vr *ValidationResult) Unfulfilled() map[string]string {
+	unfulfilled := make(map[string]string)
+	for _, failure := range vr.Failures {
+		if failure.DescriptorID != "" {
+			unfulfilled[failure.DescriptorID] = failure.Reason
+		}
+	}
+	return unfulfilled
+}
+
+// Error returns an error response describing every failure, or nil if no check failed. The response is a
+// CriticalError if any failure is, and an ApplicationError otherwise.
+func (vr *ValidationResult) Error() error {
+	if vr.IsValid() {
+		return nil
+	}
+	errorType := errresp.ApplicationError
+	failures := make([]string, 0, len(vr.Failures))
+	for _, failure := range vr.Failures {
+		if failure.ErrorType == errresp.CriticalError {
+			errorType = errresp.CriticalError
+		}
+		failures = append(failures, failure.Error())
+	}
+	return errresp.NewErrorResponsef(errorType, "<%d> validation check(s) failed: %s", len(failures), strings.Join(failures, "; "))
+}
+
+// ValidateCredentialManifest validates a credential manifest as CredentialManifest.IsValid does, but rather than
+// stopping at the first failed check, collects the failures of its JSON schema, output descriptors, and struct tags
+func ValidateCredentialManifest(cm CredentialManifest) *ValidationResult {
+	v := validation{collectAll: true}
+	if cm.IsEmpty() {
+		v.fail(errresp.NewErrorResponse(errresp.ApplicationError, "manifest is empty"))
+		return &v.result
+	}
+	if err := IsValidCredentialManifest(cm); err != nil {
+		v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "manifest failed json schema validation"))
+	}
+	if err := AreValidOutputDescriptors(cm.OutputDescriptors); err != nil {
+		v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "manifest's output descriptors failed json schema validation"))
+	}
+	if err := util.NewValidator().Struct(cm); err != nil {
+		v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "manifest failed validation"))
+	}
+	return &v.result
+}
+
+// validation collects the checks failed in validating, stopping at the first failed check unless collecting all
+type validation struct {
+	collectAll bool
+	result     ValidationResult
+}
+
+// fail records a failed check, returning whether to stop validating
+func (v *validation) fail(err *errresp.Response) bool {
+	v.result.Failures = append(v.result.Failures, ValidationFailure{ErrorType: err.ErrorType, Reason: err.Err.Error()})
+	return !v.collectAll
+}
+
+// unfulfilled records a descriptor which was not fulfilled, which does not stop validating
+func (v *validation) unfulfilled(descriptorID, reason string) {
+	v.result.Failures = append(v.result.Failures, ValidationFailure{
+		ErrorType:    errresp.ApplicationError,
+		DescriptorID: descriptorID,
+		Reason:       reason,
+	})
+}
+
+// firstFailure returns the unfulfilled descriptors and the error of a validation, which is the first failed check or,
+// if only descriptors were not fulfilled, an error counting them with the given format
+func (v *validation) firstFailure(unfulfilledFormat string) (map[string]string, error) {
+	for _, failure := range v.result.Failures {
+		if failure.DescriptorID == "" {
+			return nil, errresp.NewErrorResponse(failure.ErrorType, failure.Reason)
+		}
+	}
+	unfulfilled := v.result.Unfulfilled()
+	if len(unfulfilled) > 0 {
+		return unfulfilled, errresp.NewErrorResponsef(errresp.ApplicationError, unfulfilledFormat, len(unfulfilled))
+	}
+	return unfulfilled, nil
+}
@@ -1,63 +1,164 @@
 package manifest
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
-	credutil "github.com/TBD54566975/ssi-sdk/credential/parsing"
+	credschema "github.com/TBD54566975/ssi-sdk/credential/schema"
 	errresp "github.com/TBD54566975/ssi-sdk/error"
+	"github.com/TBD54566975/ssi-sdk/schema"
 	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
 	"github.com/oliveagle/jsonpath"
 	"github.com/pkg/errors"
 )
 
+// ParsedCredential is a credential submitted with a credential application, parsed once into both its known object
+// model and the JSON its input descriptors' fields are evaluated against, which for a JWT is the JWT's claims
+type ParsedCredential struct {
+	// Envelope is the credential as it was submitted
+	Envelope   ClaimEnvelope
+	Credential *credential.VerifiableCredential
+	JSON       map[string]any
+}
+
+// ParseCredential parses a credential of any representation a ClaimEnvelope holds, such as a VerifiableCredential,
+// a JWT, an SD-JWT, or their JSON
+func ParseCredential(genericCred any) (*ParsedCredential, error) {
+	envelope, err := NewClaimEnvelope(genericCred)
+	if err != nil {
+		return nil, errors.Wrap(err, "enveloping credential")
+	}
+	parsed, err := envelope.Parse()
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing credential")
+	}
+	return parsed, nil
+}
+
+// ParsedCredentialApplication is a credential application with its credentials already parsed, as typed
+// containers, such that validating it needs no further JSON round trips
+type ParsedCredentialApplication struct {
+	Application CredentialApplication
+	// Credentials are selected by the paths of the application's presentation submission, as the
+	// verifiableCredentials of a CredentialApplicationWrapper
+	Credentials []ParsedCredential
+}
+
+// NewParsedCredentialApplication parses the credentials submitted with a credential application
+func NewParsedCredentialApplication(ca CredentialApplication, credentials []ClaimEnvelope) (*ParsedCredentialApplication, error) {
+	parsedCredentials := make([]ParsedCredential, 0, len(credentials))
+	for i, cred := range credentials {
+		parsedCred, err := cred.Parse()
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing credential<%d>", i)
+		}
+		parsedCredentials = append(parsedCredentials, *parsedCred)
+	}
+	return &ParsedCredentialApplication{Application: ca, Credentials: parsedCredentials}, nil
+}
+
 // IsValidCredentialApplicationForManifest validates the rules on how a credential manifest [cm] and credential
 // application [ca] relate to each other https://identity.foundation/credential-manifest/#credential-application
 // applicationAndCredsJSON is the credential application and credentials as a JSON object
 func IsValidCredentialApplicationForManifest(cm CredentialManifest, applicationAndCredsJSON map[string]any) (map[string]string, error) {
-	var err error
+	v := validation{}
+	validateCredentialApplicationJSON(&v, cm, applicationAndCredsJSON)
+	return v.firstFailure("credential application not valid; <%d>unfulfilled input descriptor(s)")
+}
 
+// ValidateCredentialApplicationForManifest validates a credential application for its credential manifest [cm] as
+// IsValidCredentialApplicationForManifest does, but rather than stopping at the first failed check, collects every
+// failed check which does not prevent further checks, such that an applicant can be given complete feedback
+func ValidateCredentialApplicationForManifest(cm CredentialManifest, applicationAndCredsJSON map[string]any) *ValidationResult {
+	v := validation{collectAll: true}
+	validateCredentialApplicationJSON(&v, cm, applicationAndCredsJSON)
+	return &v.result
+}
+
+// validateCredentialApplicationJSON validates a credential application and its credentials as a JSON object
+func validateCredentialApplicationJSON(v *validation, cm CredentialManifest, applicationAndCredsJSON map[string]any) {
 	// parse out the application to its known object model
 	applicationJSON, ok := applicationAndCredsJSON[CredentialApplicationJSONProperty]
 	if !ok {
-		err = errresp.NewErrorResponse(errresp.ApplicationError, "credential_application property not found")
-		return nil, err
+		v.fail(errresp.NewErrorResponse(errresp.ApplicationError, "credential_application property not found"))
+		return
 	}
 
 	applicationBytes, err := json.Marshal(applicationJSON)
 	if err != nil {
-		err = errresp.NewErrorResponseWithErrorAndMsg(errresp.CriticalError, err, "failed to marshal credential application")
-		return nil, err
+		v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.CriticalError, err, "failed to marshal credential application"))
+		return
 	}
 	var ca CredentialApplication
 	if err = json.Unmarshal(applicationBytes, &ca); err != nil {
-		err = errresp.NewErrorResponseWithErrorAndMsg(errresp.CriticalError, err, "failed to unmarshal credential application")
-		return nil, err
+		v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.CriticalError, err, "failed to unmarshal credential application"))
+		return
+	}
+
+	// claims are resolved from the application and credentials as JSON, and parsed once resolved
+	validateCredentialApplication(v, cm, ca, func(path string) (any, error) {
+		return jsonpath.JsonPathLookup(applicationAndCredsJSON, path)
+	})
+}
+
+// IsValidParsedCredentialApplicationForManifest validates a credential application whose credentials are already
+// parsed against its credential manifest [cm], as IsValidCredentialApplicationForManifest does, without marshalling
+// the application or its credentials. Use it where applications are validated often, such as in issuance services.
+func IsValidParsedCredentialApplicationForManifest(cm CredentialManifest, application ParsedCredentialApplication) (map[string]string, error) {
+	// paths resolve to the index of the credential they select, rather than to the credential as JSON
+	credentialIndices := make([]any, len(application.Credentials))
+	for i := range credentialIndices {
+		credentialIndices[i] = i
 	}
+	applicationAndCredIndices := map[string]any{"verifiableCredentials": credentialIndices}
+	v := validation{}
+	validateCredentialApplication(&v, cm, application.Application, func(path string) (any, error) {
+		resolved, err := jsonpath.JsonPathLookup(applicationAndCredIndices, path)
+		if err != nil {
+			return nil, err
+		}
+		index, ok := resolved.(int)
+		if !ok {
+			return nil, fmt.Errorf("path<%s> does not select a credential", path)
+		}
+		return &application.Credentials[index], nil
+	})
+	return v.firstFailure("credential application not valid; <%d>unfulfilled input descriptor(s)")
+}
+
+// validateCredentialApplication validates a credential application for a credential manifest, resolving the claim
+// each path of its presentation submission selects with resolveClaim, which may resolve an already parsed claim
+func validateCredentialApplication(v *validation, cm CredentialManifest, ca CredentialApplication, resolveClaim func(path string) (any, error)) {
+	var err error
 
 	// Basic Validation Checks
 	if err = cm.IsValid(); err != nil {
-		err = errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential manifest is not valid")
-		return nil, err
+		if v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential manifest is not valid")) {
+			return
+		}
 	}
 
 	if err = ca.IsValid(); err != nil {
-		err = errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential application is not valid")
-		return nil, err
+		if v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential application is not valid")) {
+			return
+		}
 	}
 
 	// The object MUST contain a manifest_id property. The value of this property MUST be the id of a valid Credential Manifest.
 	if cm.ID != ca.ManifestID {
-		err = errresp.NewErrorResponsef(errresp.ApplicationError, "the credential application's manifest id: "+
-			"%s must be equal to the credential manifest's id: %s", ca.ManifestID, cm.ID)
-		return nil, err
+		if v.fail(errresp.NewErrorResponsef(errresp.ApplicationError, "the credential application's manifest id: "+
+			"%s must be equal to the credential manifest's id: %s", ca.ManifestID, cm.ID)) {
+			return
+		}
 	}
 
 	// The ca must have a format property if the related Credential Manifest specifies a format property.
 	// Its value must be a subset of the format property in the Credential Manifest that this Credential Submission
-	if !cm.Format.IsEmpty() {
+	if !cm.Format.IsEmpty() && ca.Format != nil {
 		cmFormats := make(map[string]bool)
 
 		for _, format := range cm.Format.FormatValues() {
@@ -65,55 +166,61 @@ func IsValidCredentialApplicationForManifest(cm CredentialManifest, applicationA
 		}
 
 		for _, format := range ca.Format.FormatValues() {
-			if _, ok = cmFormats[format]; !ok {
-				err = errresp.NewErrorResponse(errresp.ApplicationError, "credential application's "+
-					"format must be a subset of the format property in the credential manifest")
-				return nil, err
+			if _, ok := cmFormats[format]; !ok {
+				if v.fail(errresp.NewErrorResponse(errresp.ApplicationError, "credential application's "+
+					"format must be a subset of the format property in the credential manifest")) {
+					return
+				}
+				break
 			}
 		}
 	}
 
+	// the remaining checks need the presentation submission, and so cannot continue without one
 	if (cm.PresentationDefinition != nil && len(cm.PresentationDefinition.InputDescriptors) > 0) &&
 		(ca.PresentationSubmission == nil || len(ca.PresentationSubmission.DescriptorMap) == 0) {
-		err = errresp.NewErrorResponsef(errresp.ApplicationError, "no descriptors provided for application: "+
-			"%s against manifest: %s", ca.ID, cm.ID)
-		return nil, err
+		v.fail(errresp.NewErrorResponsef(errresp.ApplicationError, "no descriptors provided for application: "+
+			"%s against manifest: %s", ca.ID, cm.ID))
+		return
 	}
 
 	// The Credential Application object MUST contain a presentation_submission property IF the related Credential
 	// Manifest contains a presentation_definition. Its value MUST be a valid Presentation Submission:
 	if cm.PresentationDefinition.IsEmpty() {
 		if ca.PresentationSubmission != nil {
-			err = errresp.NewErrorResponse(errresp.ApplicationError, "credential application's "+
-				"presentation submission is invalid; the credential manifest's presentation definition is empty")
+			v.fail(errresp.NewErrorResponse(errresp.ApplicationError, "credential application's "+
+				"presentation submission is invalid; the credential manifest's presentation definition is empty"))
 		}
-		return nil, err
+		return
 	}
 
 	if ca.PresentationSubmission.IsEmpty() {
-		err = errresp.NewErrorResponse(errresp.ApplicationError, "credential application's "+
-			"presentation submission cannot be empty because the credential manifest's presentation definition is not empty")
-		return nil, err
+		v.fail(errresp.NewErrorResponse(errresp.ApplicationError, "credential application's "+
+			"presentation submission cannot be empty because the credential manifest's presentation definition is not empty"))
+		return
 	}
 
 	if err = cm.PresentationDefinition.IsValid(); err != nil {
-		err = errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential manifest's"+
-			" presentation definition is not valid")
-		return nil, err
+		if v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential manifest's"+
+			" presentation definition is not valid")) {
+			return
+		}
 	}
 
 	if err = ca.PresentationSubmission.IsValid(); err != nil {
-		err = errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential "+
-			"application's presentation submission is not valid")
-		return nil, err
+		if v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential "+
+			"application's presentation submission is not valid")) {
+			return
+		}
 	}
 
 	// https://identity.foundation/presentation-exchange/#presentation-submission
 	// The presentation_submission object MUST contain a definition_id property. The value of this property MUST be the id value of a valid Presentation Definition.
 	if cm.PresentationDefinition.ID != ca.PresentationSubmission.DefinitionID {
-		err = errresp.NewErrorResponsef(errresp.ApplicationError, "credential application's presentation "+
-			"submission's definition id: %s does not match the credential manifest's id: %s", ca.PresentationSubmission.DefinitionID, cm.PresentationDefinition.ID)
-		return nil, err
+		if v.fail(errresp.NewErrorResponsef(errresp.ApplicationError, "credential application's presentation "+
+			"submission's definition id: %s does not match the credential manifest's id: %s", ca.PresentationSubmission.DefinitionID, cm.PresentationDefinition.ID)) {
+			return
+		}
 	}
 
 	// The descriptor_map object MUST include a format property. The value of this property MUST be a string that matches one of the Claim Format Designation. This denotes the data format of the Claim.
@@ -123,15 +230,17 @@ func IsValidCredentialApplicationForManifest(cm CredentialManifest, applicationA
 	}
 
 	for _, submissionDescriptor := range ca.PresentationSubmission.DescriptorMap {
-		if _, ok = claimFormats[submissionDescriptor.Format]; !ok {
-			err = errresp.NewErrorResponse(errresp.ApplicationError, "claim format is invalid or not supported")
-			return nil, err
+		if _, ok := claimFormats[submissionDescriptor.Format]; !ok {
+			if v.fail(errresp.NewErrorResponse(errresp.ApplicationError, "claim format is invalid or not supported")) {
+				return
+			}
 		}
 
 		// The descriptor_map object MUST include a path property. The value of this property MUST be a JSONPath string expression.
 		if _, err = jsonpath.Compile(submissionDescriptor.Path); err != nil {
-			err = errresp.NewErrorResponsef(errresp.ApplicationError, "invalid json path: %s", submissionDescriptor.Path)
-			return nil, err
+			if v.fail(errresp.NewErrorResponsef(errresp.ApplicationError, "invalid json path: %s", submissionDescriptor.Path)) {
+				return
+			}
 		}
 	}
 
@@ -142,11 +251,10 @@ func IsValidCredentialApplicationForManifest(cm CredentialManifest, applicationA
 	}
 
 	// validate each input descriptor is fulfilled
-	unfulfilledInputDescriptors := make(map[string]string)
 	for _, inputDescriptor := range cm.PresentationDefinition.InputDescriptors {
 		submissionDescriptor, ok := submissionDescriptorLookup[inputDescriptor.ID]
 		if !ok {
-			unfulfilledInputDescriptors[inputDescriptor.ID] = "no submission descriptor found for input descriptor"
+			v.unfulfilled(inputDescriptor.ID, "no submission descriptor found for input descriptor")
 			continue
 		}
 
@@ -155,7 +263,7 @@ func IsValidCredentialApplicationForManifest(cm CredentialManifest, applicationA
 			errMsg := fmt.Sprintf("the format of submission descriptor<%s> is not one"+
 				" of the supported formats: %s", submissionDescriptor.Format,
 				strings.Join(inputDescriptor.Format.FormatValues(), ", "))
-			unfulfilledInputDescriptors[inputDescriptor.ID] = errMsg
+			v.unfulfilled(inputDescriptor.ID, errMsg)
 			continue
 		}
 
@@ -163,27 +271,36 @@ func IsValidCredentialApplicationForManifest(cm CredentialManifest, applicationA
 		// https://github.com/TBD54566975/ssi-sdk/issues/73
 		if submissionDescriptor.PathNested != nil {
 			errMsg := fmt.Sprintf("submission with nested paths not supported: %s", submissionDescriptor.ID)
-			unfulfilledInputDescriptors[inputDescriptor.ID] = errMsg
+			v.unfulfilled(inputDescriptor.ID, errMsg)
 			continue
 		}
 
 		// resolve the claim from the JSON path expression in the submission descriptor
-		submittedClaim, pathErr := jsonpath.JsonPathLookup(applicationAndCredsJSON, submissionDescriptor.Path)
+		submittedClaim, pathErr := resolveClaim(submissionDescriptor.Path)
 		if pathErr != nil {
 			errMsg := fmt.Sprintf("could not resolve claim from submission descriptor<%s> with path: %s",
 				submissionDescriptor.ID, submissionDescriptor.Path)
-			unfulfilledInputDescriptors[inputDescriptor.ID] = errMsg
+			v.unfulfilled(inputDescriptor.ID, errMsg)
 			continue
 		}
 
-		// convert submitted claim vc to map[string]any
-		_, _, cred, credErr := credutil.ToCredential(submittedClaim)
-		if credErr != nil {
-			unfulfilledInputDescriptors[inputDescriptor.ID] = "failed to extract credential from json"
+		// parse the submitted claim, unless already parsed, into a vc and map[string]any
+		parsedCred, ok := submittedClaim.(*ParsedCredential)
+		if !ok {
+			var credErr error
+			if parsedCred, credErr = ParseCredential(submittedClaim); credErr != nil {
+				v.unfulfilled(inputDescriptor.ID, "failed to extract credential from json")
+				continue
+			}
+		}
+		if !parsedCred.Envelope.AcceptsFormat(submissionDescriptor.Format) {
+			errMsg := fmt.Sprintf("%s claim cannot be of the format of submission descriptor<%s>: %s",
+				parsedCred.Envelope.Format(), submissionDescriptor.ID, submissionDescriptor.Format)
+			v.unfulfilled(inputDescriptor.ID, errMsg)
 			continue
 		}
-		if err = cred.IsValid(); err != nil {
-			unfulfilledInputDescriptors[inputDescriptor.ID] = "credential is not valid"
+		if err = parsedCred.Credential.IsValid(); err != nil {
+			v.unfulfilled(inputDescriptor.ID, "credential is not valid")
 			continue
 		}
 
@@ -196,27 +313,259 @@ func IsValidCredentialApplicationForManifest(cm CredentialManifest, applicationA
 
 		// TODO(gabe) consider enforcing limited disclosure if present
 		// for each field we need to verify at least one path matches
-		credJSON, err := credutil.ToCredentialJSONMap(submittedClaim)
-		if err != nil {
-			unfulfilledInputDescriptors[inputDescriptor.ID] = "failed to extract credential from json"
-			continue
-		}
 		for _, field := range inputDescriptor.Constraints.Fields {
-			if err = findMatchingPath(credJSON, field.Path); err != nil {
+			if err = findMatchingPath(parsedCred.JSON, field.Path); err != nil {
 				errMsg := fmt.Sprintf("input descriptor not fulfilled for field: %s", field.ID)
-				unfulfilledInputDescriptors[inputDescriptor.ID] = errMsg
+				v.unfulfilled(inputDescriptor.ID, errMsg)
 				continue
 			}
 		}
 	}
-	numUnfulfilledInputDescriptors := len(unfulfilledInputDescriptors)
-	if numUnfulfilledInputDescriptors > 0 {
-		err = errresp.NewErrorResponsef(errresp.ApplicationError, "credential application not valid; "+
-			"<%d>unfulfilled input descriptor(s)", numUnfulfilledInputDescriptors)
-		return unfulfilledInputDescriptors, err
+}
+
+// DenyInvalidCredentialApplication validates a credential application against its credential manifest [cm], as
+// IsValidCredentialApplicationForManifest does, and denies an application which is not valid with a credential
+// response https://identity.foundation/credential-manifest/#credential-response
+// The denial enumerates the unfulfilled input descriptors, if any, with its reason giving why each was not fulfilled.
+// Returns no response for a valid application, and an error only if the application could not be validated.
+func DenyInvalidCredentialApplication(cm CredentialManifest, applicationAndCredsJSON map[string]any) (*CredentialResponse, error) {
+	unfulfilledInputDescriptors, err := IsValidCredentialApplicationForManifest(cm, applicationAndCredsJSON)
+	if err == nil {
+		return nil, nil
+	}
+	errResp := errresp.GetErrorResponse(err)
+	if errResp.ErrorType != errresp.ApplicationError {
+		return nil, errors.Wrap(err, "validating credential application")
+	}
+
+	// enumerate the unfulfilled input descriptors in the order of the manifest's presentation definition
+	reason := errResp.Err.Error()
+	var inputDescriptorIDs []string
+	if len(unfulfilledInputDescriptors) > 0 {
+		reasons := make([]string, 0, len(unfulfilledInputDescriptors))
+		for _, inputDescriptor := range cm.PresentationDefinition.InputDescriptors {
+			if unfulfilledReason, ok := unfulfilledInputDescriptors[inputDescriptor.ID]; ok {
+				inputDescriptorIDs = append(inputDescriptorIDs, inputDescriptor.ID)
+				reasons = append(reasons, fmt.Sprintf("%s: %s", inputDescriptor.ID, unfulfilledReason))
+			}
+		}
+		reason = fmt.Sprintf("%s: %s", reason, strings.Join(reasons, "; "))
+	}
+
+	builder := NewCredentialResponseBuilder(cm.ID)
+	if applicationJSON, ok := applicationAndCredsJSON[CredentialApplicationJSONProperty].(map[string]any); ok {
+		if applicationID, ok := applicationJSON["id"].(string); ok {
+			if err = builder.SetApplicationID(applicationID); err != nil {
+				return nil, err
+			}
+		}
+		if applicant, ok := applicationJSON["applicant"].(string); ok {
+			if err = builder.SetApplicantID(applicant); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err = builder.SetDenial(reason, inputDescriptorIDs...); err != nil {
+		return nil, errors.Wrap(err, "setting denial")
+	}
+	denial, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "building denial")
+	}
+	return denial, nil
+}
+
+// IsValidCredentialResponseForManifest validates the rules on how a credential manifest [cm] and credential
+// response [response] relate to each other https://identity.foundation/credential-manifest/#credential-response
+// Each output descriptor of the manifest must be fulfilled once, by a credential with the output descriptor's schema,
+// which the path of its descriptor resolves to. Paths are resolved against the response with its [credentials],
+// which are both the verifiableCredential of a presentation embedding the response and the verifiableCredentials of
+// a CredentialResponseWrapper. Returns the reason each unfulfilled output descriptor, by id, was not fulfilled.
+func IsValidCredentialResponseForManifest(cm CredentialManifest, response CredentialResponse, credentials []ClaimEnvelope) (map[string]string, error) {
+	v := validation{}
+	validateCredentialResponse(&v, cm, response, credentials, nil)
+	return v.firstFailure("credential response not valid; <%d>unfulfilled output descriptor(s)")
+}
+
+// IsValidCredentialResponseForManifestSchemas validates a credential response [response] for a credential manifest
+// [cm] as IsValidCredentialResponseForManifest does, and that each credential conforms to the schema of the output
+// descriptor it fulfills, resolved using the given SchemaResolver. This protects applicants against issuers
+// misconfigured to issue credentials which do not conform to the schemas they reference.
+func IsValidCredentialResponseForManifestSchemas(ctx context.Context, r credschema.SchemaResolver, cm CredentialManifest, response CredentialResponse, credentials []ClaimEnvelope) (map[string]string, error) {
+	v := validation{}
+	validateCredentialResponse(&v, cm, response, credentials, func(od OutputDescriptor, cred credential.VerifiableCredential) error {
+		return IsValidCredentialForOutputDescriptor(ctx, r, od, cred)
+	})
+	return v.firstFailure("credential response not valid; <%d>unfulfilled output descriptor(s)")
+}
+
+// ValidateCredentialResponseForManifest validates a credential response for its credential manifest [cm] as
+// IsValidCredentialResponseForManifest does, but rather than stopping at the first failed check, collects every
+// failed check which does not prevent further checks
+func ValidateCredentialResponseForManifest(cm CredentialManifest, response CredentialResponse, credentials []ClaimEnvelope) *ValidationResult {
+	v := validation{collectAll: true}
+	validateCredentialResponse(&v, cm, response, credentials, nil)
+	return &v.result
+}
+
+// validateCredentialResponse validates a credential response for a credential manifest, validating each credential
+// for the output descriptor it fulfills with validateCredential, if given
+func validateCredentialResponse(v *validation, cm CredentialManifest, response CredentialResponse, credentials []ClaimEnvelope, validateCredential func(od OutputDescriptor, cred credential.VerifiableCredential) error) {
+	var err error
+
+	// Basic Validation Checks
+	if err = cm.IsValid(); err != nil {
+		if v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential manifest is not valid")) {
+			return
+		}
+	}
+
+	if err = response.IsValid(); err != nil {
+		if v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential response is not valid")) {
+			return
+		}
+	}
+
+	// The object MUST contain a manifest_id property. The value of this property MUST be the id of a valid Credential Manifest.
+	if cm.ID != response.ManifestID {
+		if v.fail(errresp.NewErrorResponsef(errresp.ApplicationError, "the credential response's manifest id: "+
+			"%s must be equal to the credential manifest's id: %s", response.ManifestID, cm.ID)) {
+			return
+		}
+	}
+
+	// a response either fulfills or denies the application; a denial has no credentials to validate
+	if response.Fulfillment == nil {
+		return
+	}
+
+	// index output descriptors by id
+	outputDescriptorLookup := make(map[string]OutputDescriptor)
+	for _, od := range cm.OutputDescriptors {
+		outputDescriptorLookup[od.ID] = od
+	}
+
+	// The descriptor_map object MUST include a format property. The value of this property MUST be a string that matches one of the Claim Format Designation. This denotes the data format of the Claim.
+	claimFormats := make(map[string]bool)
+	for _, format := range exchange.SupportedClaimFormats() {
+		claimFormats[string(format)] = true
+	}
+
+	// each fulfillment descriptor must fulfill an output descriptor of the manifest, only once
+	fulfillmentDescriptorLookup := make(map[string]exchange.SubmissionDescriptor)
+	for _, fulfillmentDescriptor := range response.Fulfillment.DescriptorMap {
+		if _, ok := outputDescriptorLookup[fulfillmentDescriptor.ID]; !ok {
+			if v.fail(errresp.NewErrorResponsef(errresp.ApplicationError, "fulfillment descriptor<%s> does not "+
+				"match an output descriptor of the credential manifest", fulfillmentDescriptor.ID)) {
+				return
+			}
+			continue
+		}
+		if _, ok := fulfillmentDescriptorLookup[fulfillmentDescriptor.ID]; ok {
+			if v.fail(errresp.NewErrorResponsef(errresp.ApplicationError, "output descriptor<%s> is fulfilled more "+
+				"than once", fulfillmentDescriptor.ID)) {
+				return
+			}
+			continue
+		}
+		fulfillmentDescriptorLookup[fulfillmentDescriptor.ID] = fulfillmentDescriptor
+
+		if _, ok := claimFormats[fulfillmentDescriptor.Format]; !ok {
+			if v.fail(errresp.NewErrorResponse(errresp.ApplicationError, "claim format is invalid or not supported")) {
+				return
+			}
+		}
+
+		// The descriptor_map object MUST include a path property. The value of this property MUST be a JSONPath string expression.
+		if _, err = jsonpath.Compile(fulfillmentDescriptor.Path); err != nil {
+			if v.fail(errresp.NewErrorResponsef(errresp.ApplicationError, "invalid json path: %s", fulfillmentDescriptor.Path)) {
+				return
+			}
+		}
+	}
+
+	// the response, with its credentials, as a JSON object to resolve paths against
+	responseJSON, err := util.ToJSONMap(response)
+	if err != nil {
+		v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.CriticalError, err, "failed to marshal credential response"))
+		return
+	}
+	credentialsJSON, err := util.AnyToJSONInterface(credentials)
+	if err != nil {
+		v.fail(errresp.NewErrorResponseWithErrorAndMsg(errresp.CriticalError, err, "failed to marshal credentials"))
+		return
+	}
+	responseAndCredsJSON := map[string]any{
+		CredentialResponseJSONProperty: responseJSON,
+		"verifiableCredential":         credentialsJSON,
+		"verifiableCredentials":        credentialsJSON,
 	}
 
-	return unfulfilledInputDescriptors, err
+	// validate each output descriptor is fulfilled
+	for _, outputDescriptor := range cm.OutputDescriptors {
+		fulfillmentDescriptor, ok := fulfillmentDescriptorLookup[outputDescriptor.ID]
+		if !ok {
+			v.unfulfilled(outputDescriptor.ID, "no fulfillment descriptor found for output descriptor")
+			continue
+		}
+
+		// if the format of the fulfilled credential is not one of the manifest's, the applicant cannot accept it
+		if !cm.Format.IsEmpty() && !util.Contains(fulfillmentDescriptor.Format, cm.Format.FormatValues()) {
+			errMsg := fmt.Sprintf("the format of fulfillment descriptor<%s> is not one"+
+				" of the supported formats: %s", fulfillmentDescriptor.Format,
+				strings.Join(cm.Format.FormatValues(), ", "))
+			v.unfulfilled(outputDescriptor.ID, errMsg)
+			continue
+		}
+
+		// TODO(gabe) support nested paths in presentation submissions
+		// https://github.com/TBD54566975/ssi-sdk/issues/73
+		if fulfillmentDescriptor.PathNested != nil {
+			errMsg := fmt.Sprintf("fulfillment with nested paths not supported: %s", fulfillmentDescriptor.ID)
+			v.unfulfilled(outputDescriptor.ID, errMsg)
+			continue
+		}
+
+		// resolve the credential from the JSON path expression in the fulfillment descriptor
+		fulfilledClaim, pathErr := jsonpath.JsonPathLookup(responseAndCredsJSON, fulfillmentDescriptor.Path)
+		if pathErr != nil {
+			errMsg := fmt.Sprintf("could not resolve credential from fulfillment descriptor<%s> with path: %s",
+				fulfillmentDescriptor.ID, fulfillmentDescriptor.Path)
+			v.unfulfilled(outputDescriptor.ID, errMsg)
+			continue
+		}
+
+		parsedCred, credErr := ParseCredential(fulfilledClaim)
+		if credErr != nil {
+			v.unfulfilled(outputDescriptor.ID, "failed to extract credential from json")
+			continue
+		}
+		if !parsedCred.Envelope.AcceptsFormat(fulfillmentDescriptor.Format) {
+			errMsg := fmt.Sprintf("%s claim cannot be of the format of fulfillment descriptor<%s>: %s",
+				parsedCred.Envelope.Format(), fulfillmentDescriptor.ID, fulfillmentDescriptor.Format)
+			v.unfulfilled(outputDescriptor.ID, errMsg)
+			continue
+		}
+		cred := parsedCred.Credential
+		if err = cred.IsValid(); err != nil {
+			v.unfulfilled(outputDescriptor.ID, "credential is not valid")
+			continue
+		}
+
+		// the credential must be of the schema the output descriptor describes
+		if cred.CredentialSchema == nil || cred.CredentialSchema.ID != outputDescriptor.Schema {
+			errMsg := fmt.Sprintf("credential's schema does not match the output descriptor's schema: %s", outputDescriptor.Schema)
+			v.unfulfilled(outputDescriptor.ID, errMsg)
+			continue
+		}
+		if validateCredential != nil {
+			if err = validateCredential(outputDescriptor, *cred); err != nil {
+				errMsg := fmt.Sprintf("credential does not conform to the output descriptor's schema: %s", err.Error())
+				v.unfulfilled(outputDescriptor.ID, errMsg)
+				continue
+			}
+		}
+	}
 }
 
 func findMatchingPath(claim any, paths []string) error {
@@ -229,3 +578,24 @@ func findMatchingPath(claim any, paths []string) error {
 }
 
 // TODO(gabe) support multiple embed targets https://github.com/TBD54566975/ssi-sdk/issues/57
+
+// IsValidCredentialForOutputDescriptor validates a credential against the schema referenced by an output descriptor
+// https://identity.foundation/credential-manifest/#output-descriptor
+// The schema is resolved using the given SchemaResolver, and may be a JSON Schema or a JsonSchemaCredential.
+func IsValidCredentialForOutputDescriptor(ctx context.Context, r credschema.SchemaResolver, od OutputDescriptor, cred credential.VerifiableCredential) error {
+	if od.Schema == "" {
+		return fmt.Errorf("output descriptor<%s> does not have a schema", od.ID)
+	}
+	jsonSchema, err := credschema.ResolveJSONSchema(ctx, r, od.Schema)
+	if err != nil {
+		return errors.Wrapf(err, "resolving schema for output descriptor<%s>", od.ID)
+	}
+	credBytes, err := json.Marshal(cred)
+	if err != nil {
+		return errors.Wrap(err, "marshalling credential")
+	}
+	if err = schema.IsValidAgainstJSONSchema(string(credBytes), jsonSchema.String()); err != nil {
+		return errors.Wrapf(err, "credential not valid for output descriptor<%s> schema", od.ID)
+	}
+	return nil
+}
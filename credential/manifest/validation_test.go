@@ -1,20 +1,69 @@
 package manifest
 
 import (
+	"context"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	credschema "github.com/TBD54566975/ssi-sdk/credential/schema"
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	errresp "github.com/TBD54566975/ssi-sdk/error"
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func TestIsValidCredentialForOutputDescriptor(t *testing.T) {
+	schemaURI := "https://example.com/schemas/email.json"
+	resolver := credschema.LocalSchemaResolver{
+		schemaURI: []byte(`{
+  "$id": "https://example.com/schemas/email.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "credentialSubject": {
+      "type": "object",
+      "properties": {
+        "emailAddress": {"type": "string"}
+      },
+      "required": ["emailAddress"]
+    }
+  }
+}`),
+	}
+	od := OutputDescriptor{ID: "email-output", Schema: schemaURI}
+	cred := credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		ID:                "test-credential",
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            "did:example:123",
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456", "emailAddress": "grandma@aol.com"},
+	}
+
+	t.Run("valid credential", func(tt *testing.T) {
+		err := IsValidCredentialForOutputDescriptor(context.Background(), resolver, od, cred)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("invalid credential", func(tt *testing.T) {
+		invalid := cred
+		invalid.CredentialSubject = map[string]any{"id": "did:example:456"}
+		err := IsValidCredentialForOutputDescriptor(context.Background(), resolver, od, invalid)
+		assert.ErrorContains(tt, err, "credential not valid for output descriptor<email-output> schema")
+	})
+
+	t.Run("unknown schema", func(tt *testing.T) {
+		err := IsValidCredentialForOutputDescriptor(context.Background(), resolver, OutputDescriptor{ID: "unknown", Schema: "https://example.com/unknown.json"}, cred)
+		assert.ErrorContains(tt, err, "resolving schema for output descriptor<unknown>")
+	})
+}
+
 func TestIsValidCredentialApplicationForManifest(t *testing.T) {
 	t.Run("Credential Application and Credential Manifest Pair Valid", func(tt *testing.T) {
 		cm, ca := getValidTestCredManifestCredApplication(tt)
@@ -341,6 +390,258 @@ func TestIsValidCredentialApplicationForManifest(t *testing.T) {
 	})
 }
 
+func TestIsValidParsedCredentialApplicationForManifest(t *testing.T) {
+	t.Run("Parsed Credential Application and Credential Manifest Pair Valid", func(tt *testing.T) {
+		for _, getPair := range []func(*testing.T) (CredentialManifest, CredentialApplicationWrapper){
+			getValidTestCredManifestCredApplication,
+			getValidTestCredManifestCredApplicationJWTCred,
+		} {
+			cm, ca := getPair(tt)
+			parsed, err := NewParsedCredentialApplication(ca.CredentialApplication, ca.Credentials)
+			require.NoError(tt, err)
+
+			unfulfilledIDs, err := IsValidParsedCredentialApplicationForManifest(cm, *parsed)
+			assert.NoError(tt, err)
+			assert.Empty(tt, unfulfilledIDs)
+		}
+	})
+
+	t.Run("Path Does Not Select A Credential", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		ca.CredentialApplication.PresentationSubmission.DescriptorMap[0].Path = "$.verifiableCredentials[1]"
+		parsed, err := NewParsedCredentialApplication(ca.CredentialApplication, ca.Credentials)
+		require.NoError(tt, err)
+
+		unfulfilledIDs, err := IsValidParsedCredentialApplicationForManifest(cm, *parsed)
+		assert.Error(tt, err)
+		assert.Contains(tt, unfulfilledIDs["kycid1"], "could not resolve claim from submission descriptor")
+	})
+
+	t.Run("Unparseable Credential", func(tt *testing.T) {
+		_, ca := getValidTestCredManifestCredApplication(tt)
+		credentials, err := NewClaimEnvelopes("header.payload.signature")
+		require.NoError(tt, err)
+		_, err = NewParsedCredentialApplication(ca.CredentialApplication, credentials)
+		assert.ErrorContains(tt, err, "parsing credential<0>")
+	})
+}
+
+func TestValidateCredentialApplicationForManifest(t *testing.T) {
+	toJSON := func(tt *testing.T, ca CredentialApplicationWrapper) map[string]any {
+		credAppRequestBytes, err := json.Marshal(ca)
+		require.NoError(tt, err)
+		request := make(map[string]any)
+		require.NoError(tt, json.Unmarshal(credAppRequestBytes, &request))
+		return request
+	}
+
+	t.Run("Valid Application Has No Failures", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		result := ValidateCredentialApplicationForManifest(cm, toJSON(tt, ca))
+		assert.True(tt, result.IsValid())
+		assert.NoError(tt, result.Error())
+	})
+
+	t.Run("Collects Every Failure", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		ca.CredentialApplication.ManifestID = "bad-id"
+		ca.CredentialApplication.PresentationSubmission.DefinitionID = "bad-definition-id"
+		ca.CredentialApplication.PresentationSubmission.DescriptorMap[0].Path = "$.verifiableCredentials[1]"
+		request := toJSON(tt, ca)
+
+		// validating stops at the first failed check
+		_, err := IsValidCredentialApplicationForManifest(cm, request)
+		assert.ErrorContains(tt, err, "the credential application's manifest id: bad-id must be equal to the credential manifest's id")
+
+		result := ValidateCredentialApplicationForManifest(cm, request)
+		assert.False(tt, result.IsValid())
+		require.Len(tt, result.Failures, 3)
+		assert.Contains(tt, result.Failures[0].Reason, "manifest id: bad-id")
+		assert.Contains(tt, result.Failures[1].Reason, "definition id: bad-definition-id")
+		assert.Equal(tt, "kycid1", result.Failures[2].DescriptorID)
+		assert.Contains(tt, result.Unfulfilled()["kycid1"], "could not resolve claim from submission descriptor")
+
+		errResp := errresp.GetErrorResponse(result.Error())
+		assert.Equal(tt, errresp.ApplicationError, errResp.ErrorType)
+		assert.Contains(tt, errResp.Err.Error(), "<3> validation check(s) failed")
+	})
+}
+
+func TestValidateCredentialManifest(t *testing.T) {
+	cm, _ := getValidTestCredManifestCredApplication(t)
+	assert.True(t, ValidateCredentialManifest(cm).IsValid())
+
+	cm.ID = ""
+	cm.OutputDescriptors[0].Schema = ""
+	result := ValidateCredentialManifest(cm)
+	assert.False(t, result.IsValid())
+	assert.GreaterOrEqual(t, len(result.Failures), 2)
+	assert.Error(t, cm.IsValid())
+}
+
+func TestDenyInvalidCredentialApplication(t *testing.T) {
+	toRequest := func(tt *testing.T, ca CredentialApplicationWrapper) map[string]any {
+		credAppRequestBytes, err := json.Marshal(ca)
+		require.NoError(tt, err)
+		request := make(map[string]any)
+		require.NoError(tt, json.Unmarshal(credAppRequestBytes, &request))
+		return request
+	}
+
+	t.Run("valid application is not denied", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		denial, err := DenyInvalidCredentialApplication(cm, toRequest(tt, ca))
+		assert.NoError(tt, err)
+		assert.Nil(tt, denial)
+	})
+
+	t.Run("denial enumerates unfulfilled input descriptors", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		cm.PresentationDefinition.InputDescriptors = append(cm.PresentationDefinition.InputDescriptors, cm.PresentationDefinition.InputDescriptors[0])
+		cm.PresentationDefinition.InputDescriptors[1].ID = "kycid2"
+
+		denial, err := DenyInvalidCredentialApplication(cm, toRequest(tt, ca))
+		require.NoError(tt, err)
+		require.NotNil(tt, denial)
+		assert.NoError(tt, denial.IsValid())
+		assert.Equal(tt, cm.ID, denial.ManifestID)
+		assert.Equal(tt, ca.CredentialApplication.ID, denial.ApplicationID)
+		assert.Equal(tt, ca.CredentialApplication.Applicant, denial.Applicant)
+		assert.Nil(tt, denial.Fulfillment)
+		require.NotNil(tt, denial.Denial)
+		assert.Equal(tt, []string{"kycid2"}, denial.Denial.InputDescriptors)
+		assert.Contains(tt, denial.Denial.Reason, "<1>unfulfilled input descriptor(s)")
+		assert.Contains(tt, denial.Denial.Reason, "kycid2: no submission descriptor found for input descriptor")
+	})
+
+	t.Run("denial of an invalid application", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		ca.CredentialApplication.ManifestID = "bad-id"
+
+		denial, err := DenyInvalidCredentialApplication(cm, toRequest(tt, ca))
+		require.NoError(tt, err)
+		require.NotNil(tt, denial)
+		assert.Empty(tt, denial.Denial.InputDescriptors)
+		assert.Contains(tt, denial.Denial.Reason, "the credential application's manifest id: bad-id must be equal to the credential manifest's id")
+	})
+}
+
+func TestIsValidCredentialResponseForManifest(t *testing.T) {
+	cm, _ := getValidTestCredManifestCredApplication(t)
+	vcJSON, err := getTestVector(FullCredentialVector)
+	require.NoError(t, err)
+	var vc credential.VerifiableCredential
+	require.NoError(t, json.Unmarshal([]byte(vcJSON), &vc))
+	vc.CredentialSchema = &credential.CredentialSchema{
+		ID:   cm.OutputDescriptors[0].Schema,
+		Type: "JsonSchema",
+	}
+	credentials, err := NewClaimEnvelopes(vc)
+	require.NoError(t, err)
+
+	getResponse := func(tt *testing.T, path string) CredentialResponse {
+		builder := NewCredentialResponseBuilder(cm.ID)
+		require.NoError(tt, builder.SetApplicantID("did:example:ebfeb1f712ebc6f1c276e12ec21"))
+		require.NoError(tt, builder.SetApplicationID("test-application"))
+		require.NoError(tt, builder.SetFulfillment([]exchange.SubmissionDescriptor{
+			{ID: cm.OutputDescriptors[0].ID, Format: exchange.LDPVC.String(), Path: path},
+		}))
+		response, err := builder.Build()
+		require.NoError(tt, err)
+		return *response
+	}
+
+	t.Run("Credential Response and Credential Manifest Pair Valid", func(tt *testing.T) {
+		unfulfilledIDs, err := IsValidCredentialResponseForManifest(cm, getResponse(tt, "$.verifiableCredential[0]"), credentials)
+		assert.NoError(tt, err)
+		assert.Empty(tt, unfulfilledIDs)
+
+		// paths may also select from the credentials of a response wrapper
+		unfulfilledIDs, err = IsValidCredentialResponseForManifest(cm, getResponse(tt, "$.verifiableCredentials[0]"), credentials)
+		assert.NoError(tt, err)
+		assert.Empty(tt, unfulfilledIDs)
+	})
+
+	t.Run("Denial Valid", func(tt *testing.T) {
+		builder := NewCredentialResponseBuilder(cm.ID)
+		require.NoError(tt, builder.SetApplicantID("did:example:ebfeb1f712ebc6f1c276e12ec21"))
+		require.NoError(tt, builder.SetDenial("not eligible", "kycid1"))
+		response, err := builder.Build()
+		require.NoError(tt, err)
+
+		unfulfilledIDs, err := IsValidCredentialResponseForManifest(cm, *response, nil)
+		assert.NoError(tt, err)
+		assert.Empty(tt, unfulfilledIDs)
+	})
+
+	t.Run("Mismatched Manifest ID", func(tt *testing.T) {
+		response := getResponse(tt, "$.verifiableCredential[0]")
+		response.ManifestID = "bad-id"
+		_, err := IsValidCredentialResponseForManifest(cm, response, credentials)
+		assert.ErrorContains(tt, err, "the credential response's manifest id: bad-id must be equal to the credential manifest's id")
+	})
+
+	t.Run("Unknown Output Descriptor", func(tt *testing.T) {
+		response := getResponse(tt, "$.verifiableCredential[0]")
+		response.Fulfillment.DescriptorMap[0].ID = "unknown"
+		_, err := IsValidCredentialResponseForManifest(cm, response, credentials)
+		assert.ErrorContains(tt, err, "fulfillment descriptor<unknown> does not match an output descriptor")
+	})
+
+	t.Run("Output Descriptor Fulfilled Twice", func(tt *testing.T) {
+		response := getResponse(tt, "$.verifiableCredential[0]")
+		response.Fulfillment.DescriptorMap = append(response.Fulfillment.DescriptorMap, response.Fulfillment.DescriptorMap[0])
+		_, err := IsValidCredentialResponseForManifest(cm, response, append(credentials, credentials...))
+		assert.ErrorContains(tt, err, "output descriptor<kyc_credential> is fulfilled more than once")
+	})
+
+	t.Run("Unresolvable Path", func(tt *testing.T) {
+		unfulfilledIDs, err := IsValidCredentialResponseForManifest(cm, getResponse(tt, "$.verifiableCredential[1]"), credentials)
+		assert.ErrorContains(tt, err, "<1>unfulfilled output descriptor(s)")
+		assert.Contains(tt, unfulfilledIDs["kyc_credential"], "could not resolve credential from fulfillment descriptor<kyc_credential>")
+	})
+
+	t.Run("Credentials Conform To Output Descriptor Schemas", func(tt *testing.T) {
+		getResolver := func(requiredProperty string) credschema.LocalSchemaResolver {
+			return credschema.LocalSchemaResolver{
+				cm.OutputDescriptors[0].Schema: []byte(`{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "credentialSubject": {
+      "type": "object",
+      "required": ["` + requiredProperty + `"]
+    }
+  }
+}`),
+			}
+		}
+		response := getResponse(tt, "$.verifiableCredential[0]")
+
+		unfulfilledIDs, err := IsValidCredentialResponseForManifestSchemas(context.Background(), getResolver("taxId"), cm, response, credentials)
+		assert.NoError(tt, err)
+		assert.Empty(tt, unfulfilledIDs)
+
+		unfulfilledIDs, err = IsValidCredentialResponseForManifestSchemas(context.Background(), getResolver("kycLevel"), cm, response, credentials)
+		assert.ErrorContains(tt, err, "<1>unfulfilled output descriptor(s)")
+		assert.Contains(tt, unfulfilledIDs["kyc_credential"], "credential does not conform to the output descriptor's schema")
+
+		unfulfilledIDs, err = IsValidCredentialResponseForManifestSchemas(context.Background(), credschema.LocalSchemaResolver{}, cm, response, credentials)
+		assert.Error(tt, err)
+		assert.Contains(tt, unfulfilledIDs["kyc_credential"], "resolving schema for output descriptor<kyc_credential>")
+	})
+
+	t.Run("Mismatched Schema", func(tt *testing.T) {
+		otherVC := vc
+		otherVC.CredentialSchema = &credential.CredentialSchema{ID: "https://example.com/other.json", Type: "JsonSchema"}
+		otherCredentials, err := NewClaimEnvelopes(otherVC)
+		require.NoError(tt, err)
+		unfulfilledIDs, err := IsValidCredentialResponseForManifest(cm, getResponse(tt, "$.verifiableCredential[0]"), otherCredentials)
+		assert.Error(tt, err)
+		assert.Contains(tt, unfulfilledIDs["kyc_credential"], "credential's schema does not match the output descriptor's schema")
+	})
+}
+
 func getValidTestCredManifestCredApplication(t *testing.T) (CredentialManifest, CredentialApplicationWrapper) {
 	// manifest
 	manifestJSON, err := getTestVector(FullManifestVector)
@@ -374,7 +675,9 @@ func getValidTestCredManifestCredApplication(t *testing.T) (CredentialManifest,
 	require.NotEmpty(t, vc)
 	require.NoError(t, vc.IsValid())
 
-	return cm, CredentialApplicationWrapper{CredentialApplication: ca, Credentials: []any{vc}}
+	credentials, err := NewClaimEnvelopes(vc)
+	require.NoError(t, err)
+	return cm, CredentialApplicationWrapper{CredentialApplication: ca, Credentials: credentials}
 }
 
 func getValidTestCredManifestCredApplicationJWTCred(t *testing.T) (CredentialManifest, CredentialApplicationWrapper) {
@@ -418,5 +721,7 @@ func getValidTestCredManifestCredApplicationJWTCred(t *testing.T) (CredentialMan
 	require.NoError(t, err)
 	require.NotEmpty(t, jwt)
 
-	return cm, CredentialApplicationWrapper{CredentialApplication: ca, Credentials: []any{string(jwt)}}
+	credentials, err := NewClaimEnvelopes(string(jwt))
+	require.NoError(t, err)
+	return cm, CredentialApplicationWrapper{CredentialApplication: ca, Credentials: credentials}
 }
@@ -0,0 +1,213 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// CredentialFulfillmentJSONProperty is the property of a credential fulfillment, the draft form of a response
+const CredentialFulfillmentJSONProperty = "credential_fulfillment"
+
+// Version is a version of the Credential Manifest specification, as given by the spec_version of its objects
+type Version string
+
+const (
+	// V1 https://identity.foundation/credential-manifest/spec/v1.0.0/, which the models of this package follow
+	V1 Version = Version(SpecVersion)
+	// Draft is the draft preceding v1.0.0, still used by older wallets, whose objects have no spec_version and whose
+	// responses are credential fulfillments
+	Draft Version = "draft"
+)
+
+// CredentialFulfillment is the response to a credential application in the draft preceding v1.0.0, which v1.0.0
+// replaced with a CredentialResponse whose fulfillment holds the descriptor map. It cannot deny an application.
+type CredentialFulfillment struct {
+	ID            string                          `json:"id" validate:"required"`
+	ManifestID    string                          `json:"manifest_id" validate:"required"`
+	ApplicationID string                          `json:"application_id,omitempty"`
+	DescriptorMap []exchange.SubmissionDescriptor `json:"descriptor_map" validate:"required,min=1,dive"`
+}
+
+type CredentialFulfillmentWrapper struct {
+	CredentialFulfillment CredentialFulfillment `json:"credential_fulfillment"`
+	Credentials           []ClaimEnvelope       `json:"verifiableCredentials,omitempty"`
+}
+
+func (cf *CredentialFulfillment) IsValid() error {
+	return util.NewValidator().Struct(cf)
+}
+
+// DetectVersion returns the version of a credential manifest, application, or response, which may be in its
+// envelope, such as a CredentialResponseWrapper. Objects without a spec_version, and credential fulfillments, are of
+// the draft preceding v1.0.0.
+func DetectVersion(object []byte) (Version, error) {
+	var objectJSON map[string]any
+	if err := json.Unmarshal(object, &objectJSON); err != nil {
+		return "", errors.Wrap(err, "unmarshalling object")
+	}
+	if _, ok := objectJSON[CredentialFulfillmentJSONProperty]; ok {
+		return Draft, nil
+	}
+	for _, property := range []string{CredentialManifestJSONProperty, CredentialApplicationJSONProperty, CredentialResponseJSONProperty} {
+		if inner, ok := objectJSON[property].(map[string]any); ok {
+			objectJSON = inner
+			break
+		}
+	}
+	specVersion, ok := objectJSON["spec_version"]
+	if !ok {
+		return Draft, nil
+	}
+	if specVersion != string(V1) {
+		return "", fmt.Errorf("unsupported spec version<%v>", specVersion)
+	}
+	return V1, nil
+}
+
+// ParseCredentialManifest parses and validates a credential manifest of any supported version, which may be in its
+// envelope, converting a draft manifest to v1.0.0, and returns the version it was in
+func ParseCredentialManifest(manifest []byte) (*CredentialManifest, Version, error) {
+	version, err := DetectVersion(manifest)
+	if err != nil {
+		return nil, "", err
+	}
+	var cm CredentialManifest
+	if err = unmarshalEnveloped(manifest, CredentialManifestJSONProperty, &cm); err != nil {
+		return nil, "", errors.Wrap(err, "unmarshalling credential manifest")
+	}
+	if version == Draft {
+		cm.SpecVersion = SpecVersion
+	}
+	if err = cm.IsValid(); err != nil {
+		return nil, "", errors.Wrapf(err, "credential manifest of version<%s> not valid", version)
+	}
+	return &cm, version, nil
+}
+
+// ParseCredentialApplication parses and validates a credential application of any supported version, which may be in
+// its envelope, converting a draft application to v1.0.0, and returns the version it was in. Draft applications need
+// not name their applicant.
+func ParseCredentialApplication(application []byte) (*CredentialApplication, Version, error) {
+	version, err := DetectVersion(application)
+	if err != nil {
+		return nil, "", err
+	}
+	var ca CredentialApplication
+	if err = unmarshalEnveloped(application, CredentialApplicationJSONProperty, &ca); err != nil {
+		return nil, "", errors.Wrap(err, "unmarshalling credential application")
+	}
+	if version == V1 {
+		if err = ca.IsValid(); err != nil {
+			return nil, "", errors.Wrapf(err, "credential application of version<%s> not valid", version)
+		}
+		return &ca, version, nil
+	}
+
+	ca.SpecVersion = SpecVersion
+	if err = IsValidCredentialApplication(ca); err != nil {
+		return nil, "", errors.Wrapf(err, "credential application of version<%s> not valid", version)
+	}
+	if ca.Format != nil {
+		if err = exchange.IsValidDefinitionClaimFormatDesignation(*ca.Format); err != nil {
+			return nil, "", errors.Wrap(err, "application's claim format failed json schema validation")
+		}
+	}
+	return &ca, version, nil
+}
+
+// ParseCredentialResponse parses and validates a credential response of any supported version, which may be in its
+// envelope, converting a draft credential fulfillment to a v1.0.0 response, and returns the version it was in
+func ParseCredentialResponse(response []byte) (*CredentialResponse, Version, error) {
+	version, err := DetectVersion(response)
+	if err != nil {
+		return nil, "", err
+	}
+	if version == V1 {
+		var cr CredentialResponse
+		if err = unmarshalEnveloped(response, CredentialResponseJSONProperty, &cr); err != nil {
+			return nil, "", errors.Wrap(err, "unmarshalling credential response")
+		}
+		if err = cr.IsValid(); err != nil {
+			return nil, "", errors.Wrapf(err, "credential response of version<%s> not valid", version)
+		}
+		return &cr, version, nil
+	}
+
+	var fulfillment CredentialFulfillment
+	if err = unmarshalEnveloped(response, CredentialFulfillmentJSONProperty, &fulfillment); err != nil {
+		return nil, "", errors.Wrap(err, "unmarshalling credential fulfillment")
+	}
+	if err = fulfillment.IsValid(); err != nil {
+		return nil, "", errors.Wrapf(err, "credential fulfillment of version<%s> not valid", version)
+	}
+	cr := ConvertResponseFromDraft(fulfillment)
+	return &cr, version, nil
+}
+
+// ConvertResponseFromDraft converts a credential fulfillment of the draft preceding v1.0.0 to a credential response
+func ConvertResponseFromDraft(fulfillment CredentialFulfillment) CredentialResponse {
+	return CredentialResponse{
+		ID:            fulfillment.ID,
+		SpecVersion:   SpecVersion,
+		ManifestID:    fulfillment.ManifestID,
+		ApplicationID: fulfillment.ApplicationID,
+		Fulfillment: &struct {
+			DescriptorMap []exchange.SubmissionDescriptor `json:"descriptor_map" validate:"required"`
+		}{
+			DescriptorMap: fulfillment.DescriptorMap,
+		},
+	}
+}
+
+// ConvertResponseToDraft converts a credential response to a credential fulfillment of the draft preceding v1.0.0,
+// for older wallets. Denials cannot be converted, as the draft has no way to deny an application.
+func ConvertResponseToDraft(response CredentialResponse) (*CredentialFulfillment, error) {
+	if response.Fulfillment == nil {
+		return nil, errors.New("only fulfilled credential responses can be converted to credential fulfillments")
+	}
+	fulfillment := CredentialFulfillment{
+		ID:            response.ID,
+		ManifestID:    response.ManifestID,
+		ApplicationID: response.ApplicationID,
+		DescriptorMap: response.Fulfillment.DescriptorMap,
+	}
+	if err := fulfillment.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "credential fulfillment not valid")
+	}
+	return &fulfillment, nil
+}
+
+// ResponseForVersion returns a credential response, with its credentials, in the envelope of the given version,
+// such as the version of the application it responds to, so that older wallets can still interop:
+// a CredentialResponseWrapper for v1.0.0, or a CredentialFulfillmentWrapper for the draft preceding it
+func ResponseForVersion(response CredentialResponse, credentials []ClaimEnvelope, version Version) (any, error) {
+	switch version {
+	case V1:
+		return CredentialResponseWrapper{CredentialResponse: response, Credentials: credentials}, nil
+	case Draft:
+		fulfillment, err := ConvertResponseToDraft(response)
+		if err != nil {
+			return nil, err
+		}
+		return CredentialFulfillmentWrapper{CredentialFulfillment: *fulfillment, Credentials: credentials}, nil
+	default:
+		return nil, fmt.Errorf("unsupported version<%s>", version)
+	}
+}
+
+// unmarshalEnveloped unmarshals an object which may be the value of the given property of its envelope
+func unmarshalEnveloped(data []byte, property string, v any) error {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+	if inner, ok := envelope[property]; ok {
+		data = inner
+	}
+	return json.Unmarshal(data, v)
+}
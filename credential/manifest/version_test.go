@@ -0,0 +1,140 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+)
+
+func TestDetectVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		object  string
+		version Version
+	}{
+		{"v1 response", `{"id": "test-id", "spec_version": "` + SpecVersion + `"}`, V1},
+		{"v1 response envelope", `{"credential_response": {"id": "test-id", "spec_version": "` + SpecVersion + `"}}`, V1},
+		{"draft application", `{"credential_application": {"id": "test-id", "manifest_id": "test-manifest"}}`, Draft},
+		{"draft fulfillment envelope", `{"credential_fulfillment": {"id": "test-id", "descriptor_map": []}}`, Draft},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			version, err := DetectVersion([]byte(test.object))
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.version, version)
+		})
+	}
+
+	t.Run("unsupported spec version", func(tt *testing.T) {
+		_, err := DetectVersion([]byte(`{"id": "test-id", "spec_version": "https://example.com/v2"}`))
+		assert.ErrorContains(tt, err, "unsupported spec version<https://example.com/v2>")
+	})
+}
+
+func TestParseCredentialResponse(t *testing.T) {
+	fulfillment := CredentialFulfillment{
+		ID:            "test-fulfillment",
+		ManifestID:    "test-manifest",
+		ApplicationID: "test-application",
+		DescriptorMap: []exchange.SubmissionDescriptor{
+			{ID: "kyc_credential", Format: exchange.JWTVC.String(), Path: "$.verifiableCredentials[0]"},
+		},
+	}
+
+	t.Run("draft fulfillment is converted", func(tt *testing.T) {
+		fulfillmentBytes, err := json.Marshal(CredentialFulfillmentWrapper{CredentialFulfillment: fulfillment})
+		require.NoError(tt, err)
+
+		response, version, err := ParseCredentialResponse(fulfillmentBytes)
+		require.NoError(tt, err)
+		assert.Equal(tt, Draft, version)
+		assert.Equal(tt, SpecVersion, response.SpecVersion)
+		assert.Equal(tt, "test-manifest", response.ManifestID)
+		assert.Equal(tt, fulfillment.DescriptorMap, response.Fulfillment.DescriptorMap)
+		assert.NoError(tt, response.IsValid())
+	})
+
+	t.Run("v1 response round trips through the draft", func(tt *testing.T) {
+		response := ConvertResponseFromDraft(fulfillment)
+		responseBytes, err := json.Marshal(CredentialResponseWrapper{CredentialResponse: response})
+		require.NoError(tt, err)
+
+		parsed, version, err := ParseCredentialResponse(responseBytes)
+		require.NoError(tt, err)
+		assert.Equal(tt, V1, version)
+
+		draft, err := ConvertResponseToDraft(*parsed)
+		require.NoError(tt, err)
+		assert.Equal(tt, fulfillment, *draft)
+	})
+
+	t.Run("invalid draft fulfillment", func(tt *testing.T) {
+		_, _, err := ParseCredentialResponse([]byte(`{"credential_fulfillment": {"id": "test-fulfillment"}}`))
+		assert.ErrorContains(tt, err, "credential fulfillment of version<draft> not valid")
+	})
+}
+
+func TestResponseForVersion(t *testing.T) {
+	builder := NewCredentialResponseBuilder("test-manifest")
+	require.NoError(t, builder.SetFulfillment([]exchange.SubmissionDescriptor{
+		{ID: "kyc_credential", Format: exchange.JWTVC.String(), Path: "$.verifiableCredentials[0]"},
+	}))
+	response, err := builder.Build()
+	require.NoError(t, err)
+	credentials, err := NewClaimEnvelopes("header.payload.signature")
+	require.NoError(t, err)
+
+	t.Run("responds to older wallets with a credential fulfillment", func(tt *testing.T) {
+		envelope, err := ResponseForVersion(*response, credentials, Draft)
+		require.NoError(tt, err)
+		wrapper, ok := envelope.(CredentialFulfillmentWrapper)
+		require.True(tt, ok)
+		assert.Equal(tt, response.ID, wrapper.CredentialFulfillment.ID)
+		assert.Equal(tt, credentials, wrapper.Credentials)
+
+		envelope, err = ResponseForVersion(*response, credentials, V1)
+		require.NoError(tt, err)
+		assert.IsType(tt, CredentialResponseWrapper{}, envelope)
+	})
+
+	t.Run("denials cannot be sent to older wallets", func(tt *testing.T) {
+		denialBuilder := NewCredentialResponseBuilder("test-manifest")
+		require.NoError(tt, denialBuilder.SetDenial("not eligible"))
+		denial, err := denialBuilder.Build()
+		require.NoError(tt, err)
+
+		_, err = ResponseForVersion(*denial, nil, Draft)
+		assert.ErrorContains(tt, err, "only fulfilled credential responses can be converted")
+	})
+}
+
+func TestParseCredentialApplication(t *testing.T) {
+	credAppJSON, err := getTestVector(FullApplicationVector)
+	require.NoError(t, err)
+
+	t.Run("v1 application", func(tt *testing.T) {
+		ca, version, err := ParseCredentialApplication([]byte(credAppJSON))
+		require.NoError(tt, err)
+		assert.Equal(tt, V1, version)
+		assert.NotEmpty(tt, ca.Applicant)
+	})
+
+	t.Run("draft application without spec version or applicant", func(tt *testing.T) {
+		var applicationJSON map[string]any
+		require.NoError(tt, json.Unmarshal([]byte(credAppJSON), &applicationJSON))
+		delete(applicationJSON, "spec_version")
+		delete(applicationJSON, "applicant")
+		draftBytes, err := json.Marshal(map[string]any{CredentialApplicationJSONProperty: applicationJSON})
+		require.NoError(tt, err)
+
+		ca, version, err := ParseCredentialApplication(draftBytes)
+		require.NoError(tt, err)
+		assert.Equal(tt, Draft, version)
+		assert.Equal(tt, SpecVersion, ca.SpecVersion)
+		assert.Empty(tt, ca.Applicant)
+	})
+}
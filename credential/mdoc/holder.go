@@ -0,0 +1,109 @@
+package mdoc
+
+import (
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/pkg/errors"
+	"github.com/veraison/go-cose"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+// NewDeviceResponse returns a successful device response containing the given documents
+func NewDeviceResponse(documents ...Document) DeviceResponse {
+	return DeviceResponse{
+		Version:   Version,
+		Documents: documents,
+		Status:    StatusOK,
+	}
+}
+
+// Present creates a document disclosing the requested data elements of an mdoc, by name space, signed by the device
+// key the mdoc is bound to. The session transcript is the CBOR encoded SessionTranscript of the session with the
+// reader, which binds the presentation to it and must be the same value the reader verifies it with.
+func Present(docType string, issuerSigned IssuerSigned, requested map[string][]string, deviceKey gocrypto.PrivateKey, sessionTranscript []byte) (*Document, error) {
+	if len(sessionTranscript) == 0 {
+		return nil, errors.New("session transcript cannot be empty")
+	}
+	if issuerSigned.IssuerAuth == nil {
+		return nil, errors.New("issuer signed must contain issuerAuth")
+	}
+
+	disclosed := IssuerSigned{NameSpaces: make(map[string][]EncodedCBOR), IssuerAuth: issuerSigned.IssuerAuth}
+	for nameSpace, identifiers := range requested {
+		items := issuerSigned.NameSpaces[nameSpace]
+		for _, identifier := range identifiers {
+			item, err := findItem(items, identifier)
+			if err != nil {
+				return nil, errors.Wrapf(err, "disclosing data element<%s> in name space<%s>", identifier, nameSpace)
+			}
+			disclosed.NameSpaces[nameSpace] = append(disclosed.NameSpaces[nameSpace], item)
+		}
+	}
+
+	deviceNameSpaces, err := NewEncodedCBOR(map[string]any{})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding device name spaces")
+	}
+	payload, err := deviceAuthenticationBytes(sessionTranscript, docType, deviceNameSpaces)
+	if err != nil {
+		return nil, err
+	}
+	deviceSignature, err := crypto.SignCOSESign1(deviceKey, cose.Headers{}, payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing device authentication")
+	}
+	// the payload is detached, since the reader reconstructs it from the session transcript
+	deviceSignature.Payload = nil
+
+	return &Document{
+		DocType:      docType,
+		IssuerSigned: disclosed,
+		DeviceSigned: DeviceSigned{
+			NameSpaces: deviceNameSpaces,
+			DeviceAuth: DeviceAuth{DeviceSignature: (*cose.UntaggedSign1Message)(deviceSignature)},
+		},
+	}, nil
+}
+
+// findItem returns the issuer signed item with the given data element identifier
+func findItem(items []EncodedCBOR, identifier string) (EncodedCBOR, error) {
+	for _, item := range items {
+		var decoded IssuerSignedItem
+		if err := item.Decode(&decoded); err != nil {
+			return nil, errors.Wrap(err, "decoding issuer signed item")
+		}
+		if decoded.ElementIdentifier == identifier {
+			return item, nil
+		}
+	}
+	return nil, errors.New("data element not found")
+}
+
+// deviceAuthenticationBytes returns the tagged encoding of the DeviceAuthentication structure signed by the device key
+func deviceAuthenticationBytes(sessionTranscript []byte, docType string, deviceNameSpaces EncodedCBOR) ([]byte, error) {
+	deviceAuth, err := NewEncodedCBOR(deviceAuthentication{
+		Context:               DeviceAuthenticationContext,
+		SessionTranscript:     cbor.RawMessage(sessionTranscript),
+		DocType:               docType,
+		DeviceNameSpacesBytes: deviceNameSpaces,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding device authentication")
+	}
+	return deviceAuth.Tagged()
+}
+
+// normalizePublicKey returns pointers to ECDSA and RSA public keys, which are used as values throughout the SDK
+func normalizePublicKey(key gocrypto.PublicKey) gocrypto.PublicKey {
+	switch k := key.(type) {
+	case ecdsa.PublicKey:
+		return &k
+	case rsa.PublicKey:
+		return &k
+	}
+	return key
+}
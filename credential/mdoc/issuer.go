@@ -0,0 +1,145 @@
+package mdoc
+
+import (
+	gocrypto "crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/veraison/go-cose"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+// randomLength is the length of the random value of each issuer signed item, which must be at least 16 bytes
+const randomLength = 32
+
+// Issuer issues mdocs, signing their mobile security objects with its document signer key
+type Issuer struct {
+	privateKey gocrypto.PrivateKey
+	// certificateChain is the document signer certificate followed by any intermediate certificates, which is included
+	// in the x5chain header of the issuer's signature
+	certificateChain [][]byte
+}
+
+// NewIssuer creates an mdoc issuer from its document signer key and certificate chain. The chain starts with the
+// document signer certificate, and is used by readers to establish trust in the issuer.
+func NewIssuer(privateKey gocrypto.PrivateKey, certificateChain ...*x509.Certificate) (*Issuer, error) {
+	if privateKey == nil {
+		return nil, errors.New("private key cannot be nil")
+	}
+	if _, err := crypto.GetCOSEAlgorithmForPrivateKey(privateKey); err != nil {
+		return nil, errors.Wrap(err, "getting issuer signing algorithm")
+	}
+	issuer := Issuer{privateKey: privateKey}
+	for _, cert := range certificateChain {
+		issuer.certificateChain = append(issuer.certificateChain, cert.Raw)
+	}
+	return &issuer, nil
+}
+
+// Issue creates an mdoc of the given type containing the given data elements, bound to the device key of its holder
+// and valid for the given period
+func (i *Issuer) Issue(docType string, nameSpaces NameSpaces, deviceKey gocrypto.PublicKey, validityInfo ValidityInfo) (*IssuerSigned, error) {
+	if docType == "" {
+		return nil, errors.New("doc type cannot be empty")
+	}
+	if len(nameSpaces) == 0 {
+		return nil, errors.New("mdoc must contain at least one data element")
+	}
+	if !validityInfo.ValidUntil.After(validityInfo.ValidFrom) {
+		return nil, errors.New("validUntil must be after validFrom")
+	}
+	if validityInfo.Signed.IsZero() {
+		validityInfo.Signed = time.Now()
+	}
+	validityInfo.Signed = validityInfo.Signed.UTC().Truncate(time.Second)
+	validityInfo.ValidFrom = validityInfo.ValidFrom.UTC().Truncate(time.Second)
+	validityInfo.ValidUntil = validityInfo.ValidUntil.UTC().Truncate(time.Second)
+
+	coseDeviceKey, err := cose.NewKeyFromPublic(normalizePublicKey(deviceKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "converting device key to cose key")
+	}
+
+	issuerSigned := IssuerSigned{NameSpaces: make(map[string][]EncodedCBOR, len(nameSpaces))}
+	mso := MobileSecurityObject{
+		Version:         Version,
+		DigestAlgorithm: DigestAlgorithmSHA256,
+		ValueDigests:    make(map[string]map[uint64][]byte, len(nameSpaces)),
+		DeviceKeyInfo:   DeviceKeyInfo{DeviceKey: coseDeviceKey},
+		DocType:         docType,
+		ValidityInfo:    validityInfo,
+	}
+
+	var digestID uint64
+	for _, nameSpace := range sortedKeys(nameSpaces) {
+		elements := nameSpaces[nameSpace]
+		mso.ValueDigests[nameSpace] = make(map[uint64][]byte, len(elements))
+		for _, identifier := range sortedKeys(elements) {
+			random := make([]byte, randomLength)
+			if _, err = rand.Read(random); err != nil {
+				return nil, errors.Wrap(err, "generating random value")
+			}
+			item, err := NewEncodedCBOR(IssuerSignedItem{
+				DigestID:          digestID,
+				Random:            random,
+				ElementIdentifier: identifier,
+				ElementValue:      elements[identifier],
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "encoding data element<%s>", identifier)
+			}
+			digest, err := digestItem(item)
+			if err != nil {
+				return nil, errors.Wrapf(err, "digesting data element<%s>", identifier)
+			}
+			issuerSigned.NameSpaces[nameSpace] = append(issuerSigned.NameSpaces[nameSpace], item)
+			mso.ValueDigests[nameSpace][digestID] = digest
+			digestID++
+		}
+	}
+
+	msoBytes, err := NewEncodedCBOR(mso)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding mobile security object")
+	}
+	payload, err := msoBytes.Tagged()
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding mobile security object")
+	}
+	headers := cose.Headers{Unprotected: cose.UnprotectedHeader{}}
+	if len(i.certificateChain) == 1 {
+		headers.Unprotected[cose.HeaderLabelX5Chain] = i.certificateChain[0]
+	} else if len(i.certificateChain) > 1 {
+		headers.Unprotected[cose.HeaderLabelX5Chain] = i.certificateChain
+	}
+	issuerAuth, err := crypto.SignCOSESign1(i.privateKey, headers, payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing mobile security object")
+	}
+	issuerSigned.IssuerAuth = (*cose.UntaggedSign1Message)(issuerAuth)
+	return &issuerSigned, nil
+}
+
+// digestItem returns the SHA-256 digest of an issuer signed item, which is computed over its tagged encoding
+func digestItem(item EncodedCBOR) ([]byte, error) {
+	tagged, err := item.Tagged()
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(tagged)
+	return digest[:], nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
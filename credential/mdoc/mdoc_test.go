@@ -0,0 +1,191 @@
+package mdoc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/go-cose"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestIssuePresentVerify(t *testing.T) {
+	issuerKey, issuerCert, roots := createIssuerCertificate(t)
+	issuer, err := NewIssuer(issuerKey, issuerCert)
+	require.NoError(t, err)
+
+	devicePublicKey, devicePrivateKey, err := crypto.GenerateP256Key()
+	require.NoError(t, err)
+
+	validityInfo := ValidityInfo{
+		ValidFrom:  time.Now().Add(-time.Hour),
+		ValidUntil: time.Now().Add(24 * time.Hour),
+	}
+	issuerSigned, err := issuer.Issue(MDLDocType, NameSpaces{
+		MDLNameSpace: {
+			"family_name":     "Doe",
+			"given_name":      "John",
+			"birth_date":      "1980-01-01",
+			"age_over_18":     true,
+			"issuing_country": "US",
+		},
+	}, devicePublicKey, validityInfo)
+	require.NoError(t, err)
+	assert.Len(t, issuerSigned.NameSpaces[MDLNameSpace], 5)
+
+	sessionTranscript, err := cbor.Marshal([]any{nil, nil, "test-handover"})
+	require.NoError(t, err)
+	requested := map[string][]string{MDLNameSpace: {"family_name", "age_over_18"}}
+
+	t.Run("verify presented elements", func(tt *testing.T) {
+		doc, err := Present(MDLDocType, *issuerSigned, requested, devicePrivateKey, sessionTranscript)
+		assert.NoError(tt, err)
+
+		responseBytes, err := NewDeviceResponse(*doc).ToCBOR()
+		assert.NoError(tt, err)
+		response, err := ParseDeviceResponse(responseBytes)
+		assert.NoError(tt, err)
+
+		verified, err := VerifyDeviceResponse(*response, VerificationOptions{
+			SessionTranscript:   sessionTranscript,
+			TrustedCertificates: roots,
+		})
+		assert.NoError(tt, err)
+		assert.Equal(tt, NameSpaces{MDLNameSpace: {"family_name": "Doe", "age_over_18": true}}, verified[MDLDocType])
+
+		// the issuer key can be provided directly instead of trusted certificates
+		verified, err = VerifyDeviceResponse(*response, VerificationOptions{
+			SessionTranscript: sessionTranscript,
+			IssuerKey:         issuerKey.PublicKey,
+		})
+		assert.NoError(tt, err)
+		assert.Len(tt, verified[MDLDocType][MDLNameSpace], 2)
+	})
+
+	t.Run("requested element not issued", func(tt *testing.T) {
+		_, err := Present(MDLDocType, *issuerSigned, map[string][]string{MDLNameSpace: {"portrait"}}, devicePrivateKey, sessionTranscript)
+		assert.ErrorContains(tt, err, "disclosing data element<portrait> in name space<org.iso.18013.5.1>: data element not found")
+	})
+
+	t.Run("untrusted issuer", func(tt *testing.T) {
+		doc, err := Present(MDLDocType, *issuerSigned, requested, devicePrivateKey, sessionTranscript)
+		assert.NoError(tt, err)
+		_, _, otherRoots := createIssuerCertificate(tt)
+		_, err = VerifyDocument(*doc, VerificationOptions{SessionTranscript: sessionTranscript, TrustedCertificates: otherRoots})
+		assert.ErrorContains(tt, err, "verifying document signer certificate")
+
+		_, err = VerifyDocument(*doc, VerificationOptions{SessionTranscript: sessionTranscript})
+		assert.ErrorContains(tt, err, "an issuer key or trusted certificates are required")
+	})
+
+	t.Run("tampered element", func(tt *testing.T) {
+		doc, err := Present(MDLDocType, *issuerSigned, requested, devicePrivateKey, sessionTranscript)
+		assert.NoError(tt, err)
+		var item IssuerSignedItem
+		assert.NoError(tt, doc.IssuerSigned.NameSpaces[MDLNameSpace][0].Decode(&item))
+		item.ElementValue = "Smith"
+		tampered, err := NewEncodedCBOR(item)
+		assert.NoError(tt, err)
+		doc.IssuerSigned.NameSpaces[MDLNameSpace] = []EncodedCBOR{tampered}
+
+		_, err = VerifyDocument(*doc, VerificationOptions{SessionTranscript: sessionTranscript, TrustedCertificates: roots})
+		assert.ErrorContains(tt, err, "does not match")
+	})
+
+	t.Run("different session", func(tt *testing.T) {
+		doc, err := Present(MDLDocType, *issuerSigned, requested, devicePrivateKey, sessionTranscript)
+		assert.NoError(tt, err)
+		otherTranscript, err := cbor.Marshal([]any{nil, nil, "other-handover"})
+		assert.NoError(tt, err)
+		_, err = VerifyDocument(*doc, VerificationOptions{SessionTranscript: otherTranscript, TrustedCertificates: roots})
+		assert.ErrorContains(tt, err, "verifying device signature")
+	})
+
+	t.Run("presented by another device", func(tt *testing.T) {
+		_, otherDeviceKey, err := crypto.GenerateP256Key()
+		assert.NoError(tt, err)
+		doc, err := Present(MDLDocType, *issuerSigned, requested, otherDeviceKey, sessionTranscript)
+		assert.NoError(tt, err)
+		_, err = VerifyDocument(*doc, VerificationOptions{SessionTranscript: sessionTranscript, TrustedCertificates: roots})
+		assert.ErrorContains(tt, err, "verifying device signature")
+	})
+
+	t.Run("outside validity period", func(tt *testing.T) {
+		doc, err := Present(MDLDocType, *issuerSigned, requested, devicePrivateKey, sessionTranscript)
+		assert.NoError(tt, err)
+		_, err = VerifyDocument(*doc, VerificationOptions{SessionTranscript: sessionTranscript, IssuerKey: issuerKey.PublicKey, Now: time.Now().Add(48 * time.Hour)})
+		assert.ErrorContains(tt, err, "mdoc expired at")
+		_, err = VerifyDocument(*doc, VerificationOptions{SessionTranscript: sessionTranscript, IssuerKey: issuerKey.PublicKey, Now: time.Now().Add(-48 * time.Hour)})
+		assert.ErrorContains(tt, err, "mdoc is not valid until")
+
+		// the issuer's certificate is verified at the same time
+		_, err = VerifyDocument(*doc, VerificationOptions{SessionTranscript: sessionTranscript, TrustedCertificates: roots, Now: time.Now().Add(48 * time.Hour)})
+		assert.ErrorContains(tt, err, "verifying document signer certificate")
+	})
+
+	t.Run("empty certificate chain", func(tt *testing.T) {
+		doc, err := Present(MDLDocType, *issuerSigned, requested, devicePrivateKey, sessionTranscript)
+		assert.NoError(tt, err)
+		issuerAuth := *doc.IssuerSigned.IssuerAuth
+		issuerAuth.Headers.Unprotected = cose.UnprotectedHeader{cose.HeaderLabelX5Chain: []any{}}
+		doc.IssuerSigned.IssuerAuth = &issuerAuth
+		_, err = VerifyDocument(*doc, VerificationOptions{SessionTranscript: sessionTranscript, TrustedCertificates: roots})
+		assert.ErrorContains(tt, err, "x5chain must contain certificates")
+	})
+}
+
+func TestIssueErrors(t *testing.T) {
+	_, err := NewIssuer(nil)
+	assert.ErrorContains(t, err, "private key cannot be nil")
+
+	_, secpKey, err := crypto.GenerateSECP256k1Key()
+	assert.NoError(t, err)
+	_, err = NewIssuer(secpKey)
+	assert.ErrorContains(t, err, "unsupported private key type for cose")
+
+	_, issuerKey, err := crypto.GenerateEd25519Key()
+	assert.NoError(t, err)
+	issuer, err := NewIssuer(issuerKey)
+	assert.NoError(t, err)
+	deviceKey, _, err := crypto.GenerateEd25519Key()
+	assert.NoError(t, err)
+
+	validityInfo := ValidityInfo{ValidFrom: time.Now(), ValidUntil: time.Now().Add(time.Hour)}
+	_, err = issuer.Issue("", NameSpaces{MDLNameSpace: {"given_name": "John"}}, deviceKey, validityInfo)
+	assert.ErrorContains(t, err, "doc type cannot be empty")
+	_, err = issuer.Issue(MDLDocType, nil, deviceKey, validityInfo)
+	assert.ErrorContains(t, err, "at least one data element")
+	_, err = issuer.Issue(MDLDocType, NameSpaces{MDLNameSpace: {"given_name": "John"}}, deviceKey, ValidityInfo{ValidFrom: time.Now(), ValidUntil: time.Now().Add(-time.Hour)})
+	assert.ErrorContains(t, err, "validUntil must be after validFrom")
+}
+
+// createIssuerCertificate returns a document signer key and its self-signed certificate, along with a pool trusting it
+func createIssuerCertificate(t *testing.T) (*ecdsa.PrivateKey, *x509.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Document Signer", Country: []string{"US"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return key, cert, roots
+}
@@ -0,0 +1,197 @@
+package mdoc
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/pkg/errors"
+	"github.com/veraison/go-cose"
+)
+
+// Mobile documents (mdocs) as specified in ISO/IEC 18013-5, the standard for mobile driving licences (mDL)
+
+const (
+	// MDLDocType is the document type of a mobile driving licence
+	MDLDocType string = "org.iso.18013.5.1.mDL"
+	// MDLNameSpace is the name space of the data elements of a mobile driving licence
+	MDLNameSpace string = "org.iso.18013.5.1"
+
+	// Version is the version of the mobile security object and device response structures
+	Version string = "1.0"
+
+	// DigestAlgorithmSHA256 is the digest algorithm used for the value digests of issued mdocs
+	DigestAlgorithmSHA256 string = "SHA-256"
+
+	// DeviceAuthenticationContext is the context of the structure signed by the device key in a presentation
+	DeviceAuthenticationContext string = "DeviceAuthentication"
+
+	// StatusOK is the status of a device response which was processed successfully
+	StatusOK uint64 = 0
+
+	// encodedCBORTag is the CBOR tag of a data item embedded in a byte string (#6.24(bstr .cbor))
+	encodedCBORTag uint64 = 24
+)
+
+var (
+	encMode cbor.EncMode
+	decMode cbor.DecMode
+
+	// data element values are decoded with string keys, so they can be used like JSON values
+	mapStringAny = reflect.TypeOf(map[string]any{})
+)
+
+func init() {
+	var err error
+	// tdate values are tagged RFC3339 strings, and maps are sorted as required for deterministic encoding
+	encMode, err = cbor.EncOptions{Sort: cbor.SortCoreDeterministic, Time: cbor.TimeRFC3339, TimeTag: cbor.EncTagRequired}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	decMode, err = cbor.DecOptions{DefaultMapType: mapStringAny}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NameSpaces holds data element values by data element identifier, by name space
+// e.g. {"org.iso.18013.5.1": {"family_name": "Doe", "given_name": "John"}}
+type NameSpaces map[string]map[string]any
+
+// EncodedCBOR is a CBOR data item embedded in a byte string tagged with 24, which keeps the exact bytes that were
+// signed or digested. Its bytes are the encoding of the embedded data item.
+type EncodedCBOR []byte
+
+// NewEncodedCBOR encodes a data item to be embedded as EncodedCBOR
+func NewEncodedCBOR(v any) (EncodedCBOR, error) {
+	data, err := encMode.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding cbor")
+	}
+	return data, nil
+}
+
+// Decode decodes the embedded data item into v
+func (e EncodedCBOR) Decode(v any) error {
+	if err := decMode.Unmarshal(e, v); err != nil {
+		return errors.Wrap(err, "decoding embedded cbor")
+	}
+	return nil
+}
+
+// Tagged returns the encoding of the tagged byte string, over which digests and signatures are computed
+func (e EncodedCBOR) Tagged() ([]byte, error) {
+	return encMode.Marshal(cbor.Tag{Number: encodedCBORTag, Content: []byte(e)})
+}
+
+func (e EncodedCBOR) MarshalCBOR() ([]byte, error) {
+	return e.Tagged()
+}
+
+func (e *EncodedCBOR) UnmarshalCBOR(data []byte) error {
+	var tag cbor.RawTag
+	if err := tag.UnmarshalCBOR(data); err != nil {
+		return errors.Wrap(err, "decoding tagged cbor")
+	}
+	if tag.Number != encodedCBORTag {
+		return errors.Errorf("expected cbor tag<%d>, got<%d>", encodedCBORTag, tag.Number)
+	}
+	var content []byte
+	if err := decMode.Unmarshal(tag.Content, &content); err != nil {
+		return errors.Wrap(err, "decoding tagged cbor content")
+	}
+	*e = content
+	return nil
+}
+
+// IssuerSignedItem is a single data element issued in an mdoc. Its digest is included in the mobile security object.
+type IssuerSignedItem struct {
+	DigestID          uint64 `cbor:"digestID"`
+	Random            []byte `cbor:"random"`
+	ElementIdentifier string `cbor:"elementIdentifier"`
+	ElementValue      any    `cbor:"elementValue"`
+}
+
+// IssuerSigned contains the data elements of an mdoc, each encoded as an IssuerSignedItem, and the issuer's signature
+// over the mobile security object in issuerAuth
+type IssuerSigned struct {
+	NameSpaces map[string][]EncodedCBOR   `cbor:"nameSpaces,omitempty"`
+	IssuerAuth *cose.UntaggedSign1Message `cbor:"issuerAuth"`
+}
+
+// MobileSecurityObject is signed by the issuer of an mdoc, and contains the digests of its data elements, the key of
+// the device it is bound to, and its validity period
+type MobileSecurityObject struct {
+	Version         string                       `cbor:"version"`
+	DigestAlgorithm string                       `cbor:"digestAlgorithm"`
+	ValueDigests    map[string]map[uint64][]byte `cbor:"valueDigests"`
+	DeviceKeyInfo   DeviceKeyInfo                `cbor:"deviceKeyInfo"`
+	DocType         string                       `cbor:"docType"`
+	ValidityInfo    ValidityInfo                 `cbor:"validityInfo"`
+}
+
+// DeviceKeyInfo contains the key of the device an mdoc is bound to, as a COSE_Key
+type DeviceKeyInfo struct {
+	DeviceKey *cose.Key `cbor:"deviceKey"`
+}
+
+// ValidityInfo is the validity period of an mdoc
+type ValidityInfo struct {
+	Signed         time.Time  `cbor:"signed"`
+	ValidFrom      time.Time  `cbor:"validFrom"`
+	ValidUntil     time.Time  `cbor:"validUntil"`
+	ExpectedUpdate *time.Time `cbor:"expectedUpdate,omitempty"`
+}
+
+// DeviceResponse is returned by an mdoc holder to a reader, containing the requested documents
+type DeviceResponse struct {
+	Version   string     `cbor:"version"`
+	Documents []Document `cbor:"documents,omitempty"`
+	Status    uint64     `cbor:"status"`
+}
+
+// Document is a presentation of an mdoc, containing the disclosed issuer signed data elements and the device's
+// signature binding them to the session
+type Document struct {
+	DocType      string       `cbor:"docType"`
+	IssuerSigned IssuerSigned `cbor:"issuerSigned"`
+	DeviceSigned DeviceSigned `cbor:"deviceSigned"`
+}
+
+// DeviceSigned contains data elements returned by the device, which are always empty here, and the device's signature
+type DeviceSigned struct {
+	NameSpaces EncodedCBOR `cbor:"nameSpaces"`
+	DeviceAuth DeviceAuth  `cbor:"deviceAuth"`
+}
+
+// DeviceAuth authenticates a document to a reader. Only device signatures are supported, not device MACs.
+type DeviceAuth struct {
+	DeviceSignature *cose.UntaggedSign1Message `cbor:"deviceSignature,omitempty"`
+}
+
+// deviceAuthentication is the structure signed by the device key, with a detached payload
+type deviceAuthentication struct {
+	_                     struct{} `cbor:",toarray"`
+	Context               string
+	SessionTranscript     cbor.RawMessage
+	DocType               string
+	DeviceNameSpacesBytes EncodedCBOR
+}
+
+// ToCBOR encodes the device response as CBOR
+func (r DeviceResponse) ToCBOR() ([]byte, error) {
+	data, err := encMode.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding device response")
+	}
+	return data, nil
+}
+
+// ParseDeviceResponse decodes a CBOR device response
+func ParseDeviceResponse(data []byte) (*DeviceResponse, error) {
+	var response DeviceResponse
+	if err := decMode.Unmarshal(data, &response); err != nil {
+		return nil, errors.Wrap(err, "decoding device response")
+	}
+	return &response, nil
+}
@@ -0,0 +1,222 @@
+package mdoc
+
+import (
+	"bytes"
+	gocrypto "crypto"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/veraison/go-cose"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+// VerificationOptions configures how a reader verifies the documents in a device response
+type VerificationOptions struct {
+	// SessionTranscript is the CBOR encoded SessionTranscript of the session with the holder, which the device
+	// signature of each document must be bound to
+	SessionTranscript []byte
+	// IssuerKey verifies the issuer's signature of each document. If it is not set, the issuer's signature is verified
+	// with the document signer certificate in its x5chain header, which must chain to one of the TrustedCertificates.
+	IssuerKey gocrypto.PublicKey
+	// TrustedCertificates are the issuing authority certificates trusted by the reader
+	TrustedCertificates *x509.CertPool
+	// Now is the time the documents are verified at, defaulting to the current time
+	Now time.Time
+}
+
+// VerifyDeviceResponse verifies each document in a device response, returning the data elements disclosed in each,
+// by doc type
+func VerifyDeviceResponse(response DeviceResponse, opts VerificationOptions) (map[string]NameSpaces, error) {
+	if response.Version != Version {
+		return nil, fmt.Errorf("unsupported device response version<%s>", response.Version)
+	}
+	if response.Status != StatusOK {
+		return nil, fmt.Errorf("device response has error status<%d>", response.Status)
+	}
+	if len(response.Documents) == 0 {
+		return nil, errors.New("device response does not contain any documents")
+	}
+	verified := make(map[string]NameSpaces, len(response.Documents))
+	for _, doc := range response.Documents {
+		nameSpaces, err := VerifyDocument(doc, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "verifying document<%s>", doc.DocType)
+		}
+		verified[doc.DocType] = nameSpaces
+	}
+	return verified, nil
+}
+
+// VerifyDocument verifies a document presented by a holder, returning its disclosed data elements. It verifies the
+// issuer's signature of the mobile security object, the digest of each data element, the validity period of the
+// mdoc, and the device signature binding the document to the session.
+func VerifyDocument(doc Document, opts VerificationOptions) (NameSpaces, error) {
+	mso, err := VerifyIssuerSigned(doc.IssuerSigned, opts)
+	if err != nil {
+		return nil, err
+	}
+	if mso.DocType != doc.DocType {
+		return nil, fmt.Errorf("document doc type<%s> does not match mobile security object doc type<%s>", doc.DocType, mso.DocType)
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if now.Before(mso.ValidityInfo.ValidFrom) {
+		return nil, fmt.Errorf("mdoc is not valid until %s", mso.ValidityInfo.ValidFrom)
+	}
+	if now.After(mso.ValidityInfo.ValidUntil) {
+		return nil, fmt.Errorf("mdoc expired at %s", mso.ValidityInfo.ValidUntil)
+	}
+
+	if err = verifyDeviceSigned(doc, *mso, opts.SessionTranscript); err != nil {
+		return nil, errors.Wrap(err, "verifying device signature")
+	}
+
+	nameSpaces := make(NameSpaces, len(doc.IssuerSigned.NameSpaces))
+	for nameSpace, items := range doc.IssuerSigned.NameSpaces {
+		nameSpaces[nameSpace] = make(map[string]any, len(items))
+		for _, item := range items {
+			var decoded IssuerSignedItem
+			if err = item.Decode(&decoded); err != nil {
+				return nil, errors.Wrap(err, "decoding issuer signed item")
+			}
+			nameSpaces[nameSpace][decoded.ElementIdentifier] = decoded.ElementValue
+		}
+	}
+	return nameSpaces, nil
+}
+
+// VerifyIssuerSigned verifies the issuer's signature of an mdoc and the digests of its data elements, returning its
+// mobile security object
+func VerifyIssuerSigned(issuerSigned IssuerSigned, opts VerificationOptions) (*MobileSecurityObject, error) {
+	if issuerSigned.IssuerAuth == nil {
+		return nil, errors.New("issuer signed must contain issuerAuth")
+	}
+	issuerAuth := (*cose.Sign1Message)(issuerSigned.IssuerAuth)
+	issuerKey := opts.IssuerKey
+	if issuerKey == nil {
+		cert, err := verifyCertificateChain(issuerAuth, opts.TrustedCertificates, opts.Now)
+		if err != nil {
+			return nil, errors.Wrap(err, "verifying issuer certificate chain")
+		}
+		issuerKey = cert.PublicKey
+	}
+	if err := crypto.VerifyCOSESign1(issuerAuth, issuerKey); err != nil {
+		return nil, errors.Wrap(err, "verifying issuer signature")
+	}
+
+	var msoBytes EncodedCBOR
+	if err := decMode.Unmarshal(issuerAuth.Payload, &msoBytes); err != nil {
+		return nil, errors.Wrap(err, "decoding mobile security object")
+	}
+	var mso MobileSecurityObject
+	if err := msoBytes.Decode(&mso); err != nil {
+		return nil, errors.Wrap(err, "decoding mobile security object")
+	}
+	if mso.Version != Version {
+		return nil, fmt.Errorf("unsupported mobile security object version<%s>", mso.Version)
+	}
+	if mso.DigestAlgorithm != DigestAlgorithmSHA256 {
+		return nil, fmt.Errorf("unsupported digest algorithm<%s>", mso.DigestAlgorithm)
+	}
+
+	for nameSpace, items := range issuerSigned.NameSpaces {
+		for _, item := range items {
+			var decoded IssuerSignedItem
+			if err := item.Decode(&decoded); err != nil {
+				return nil, errors.Wrap(err, "decoding issuer signed item")
+			}
+			expected, ok := mso.ValueDigests[nameSpace][decoded.DigestID]
+			if !ok {
+				return nil, fmt.Errorf("digest<%d> for data element<%s> not found in name space<%s>", decoded.DigestID, decoded.ElementIdentifier, nameSpace)
+			}
+			digest, err := digestItem(item)
+			if err != nil {
+				return nil, errors.Wrapf(err, "digesting data element<%s>", decoded.ElementIdentifier)
+			}
+			if !bytes.Equal(expected, digest) {
+				return nil, fmt.Errorf("digest of data element<%s> in name space<%s> does not match", decoded.ElementIdentifier, nameSpace)
+			}
+		}
+	}
+	return &mso, nil
+}
+
+// verifyCertificateChain verifies the x5chain of the issuer's signature chains to a trusted certificate at a time,
+// defaulting to the current time, returning the document signer certificate
+func verifyCertificateChain(issuerAuth *cose.Sign1Message, trusted *x509.CertPool, now time.Time) (*x509.Certificate, error) {
+	if trusted == nil {
+		return nil, errors.New("an issuer key or trusted certificates are required")
+	}
+	var chain [][]byte
+	switch x5chain := issuerAuth.Headers.Unprotected[cose.HeaderLabelX5Chain].(type) {
+	case []byte:
+		chain = [][]byte{x5chain}
+	case []any:
+		for _, cert := range x5chain {
+			certBytes, ok := cert.([]byte)
+			if !ok {
+				return nil, errors.New("x5chain must contain certificates")
+			}
+			chain = append(chain, certBytes)
+		}
+	default:
+		return nil, errors.New("x5chain header not found")
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("x5chain must contain certificates")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(chain))
+	for _, certBytes := range chain {
+		cert, err := x509.ParseCertificate(certBytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing certificate")
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         trusted,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, errors.Wrap(err, "verifying document signer certificate")
+	}
+	return certs[0], nil
+}
+
+// verifyDeviceSigned verifies the device signature of a document with the device key in its mobile security object
+func verifyDeviceSigned(doc Document, mso MobileSecurityObject, sessionTranscript []byte) error {
+	if len(sessionTranscript) == 0 {
+		return errors.New("session transcript cannot be empty")
+	}
+	if doc.DeviceSigned.DeviceAuth.DeviceSignature == nil {
+		return errors.New("document does not contain a device signature")
+	}
+	if mso.DeviceKeyInfo.DeviceKey == nil {
+		return errors.New("mobile security object does not contain a device key")
+	}
+	deviceKey, err := mso.DeviceKeyInfo.DeviceKey.PublicKey()
+	if err != nil {
+		return errors.Wrap(err, "getting device key")
+	}
+
+	payload, err := deviceAuthenticationBytes(sessionTranscript, doc.DocType, doc.DeviceSigned.NameSpaces)
+	if err != nil {
+		return err
+	}
+	// the device signature payload is detached, so it is verified over a copy with the reconstructed payload
+	deviceSignature := *(*cose.Sign1Message)(doc.DeviceSigned.DeviceAuth.DeviceSignature)
+	deviceSignature.Payload = payload
+	return crypto.VerifyCOSESign1(&deviceSignature, deviceKey)
+}
@@ -1,8 +1,12 @@
 package credential
 
 import (
+	"bytes"
 	"reflect"
 
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/util"
 )
@@ -18,20 +22,87 @@ type VerifiableCredential struct {
 	// either a URI or an object containing an `id` property.
 	Issuer any `json:"issuer,omitempty" validate:"required"`
 	// https://www.w3.org/TR/xmlschema11-2/#dateTimes
-	IssuanceDate     string `json:"issuanceDate,omitempty" validate:"required"`
-	ExpirationDate   string `json:"expirationDate,omitempty"`
+	IssuanceDate   string `json:"issuanceDate,omitempty" validate:"required"`
+	ExpirationDate string `json:"expirationDate,omitempty"`
+	// validFrom and validUntil replace issuanceDate and expirationDate in v2.0 of the data model
+	// https://www.w3.org/TR/vc-data-model-2.0/#validity-period
+	ValidFrom        string `json:"validFrom,omitempty"`
+	ValidUntil       string `json:"validUntil,omitempty"`
 	CredentialStatus any    `json:"credentialStatus,omitempty" validate:"omitempty"`
 	// This is where the subject's ID *may* be present
 	CredentialSubject CredentialSubject `json:"credentialSubject" validate:"required"`
-	CredentialSchema  *CredentialSchema `json:"credentialSchema,omitempty" validate:"omitempty"`
-	RefreshService    *RefreshService   `json:"refreshService,omitempty" validate:"omitempty"`
-	TermsOfUse        []TermsOfUse      `json:"termsOfUse,omitempty" validate:"omitempty,dive"`
-	Evidence          []any             `json:"evidence,omitempty" validate:"omitempty"`
+	// AdditionalCredentialSubjects holds the subjects after the first of a credential about multiple subjects, such
+	// as a group or role credential, whose credentialSubject is an array
+	// https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#credential-subject
+	AdditionalCredentialSubjects []CredentialSubject `json:"-" validate:"omitempty,dive,min=1"`
+	CredentialSchema             *CredentialSchema   `json:"credentialSchema,omitempty" validate:"omitempty"`
+	RefreshService               *RefreshService     `json:"refreshService,omitempty" validate:"omitempty"`
+	TermsOfUse                   []TermsOfUse        `json:"termsOfUse,omitempty" validate:"omitempty,dive"`
+	Evidence                     []Evidence          `json:"evidence,omitempty" validate:"omitempty"`
 	// For embedded proof support
 	// Proof is a digital signature over a credential https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#proofs-signatures
 	Proof *crypto.Proof `json:"proof,omitempty"`
 }
 
+// verifiableCredentialJSON is the JSON form of a credential, whose credentialSubject is an object, or an array when
+// the credential has multiple subjects
+type verifiableCredentialJSON struct {
+	verifiableCredentialAlias
+	CredentialSubject any `json:"credentialSubject"`
+}
+
+type verifiableCredentialAlias VerifiableCredential
+
+func (v VerifiableCredential) MarshalJSON() ([]byte, error) {
+	if len(v.AdditionalCredentialSubjects) == 0 {
+		return json.Marshal(verifiableCredentialAlias(v))
+	}
+	return json.Marshal(verifiableCredentialJSON{
+		verifiableCredentialAlias: verifiableCredentialAlias(v),
+		CredentialSubject:         v.CredentialSubjects(),
+	})
+}
+
+func (v *VerifiableCredential) UnmarshalJSON(data []byte) error {
+	var vcJSON struct {
+		verifiableCredentialAlias
+		CredentialSubject json.RawMessage `json:"credentialSubject"`
+	}
+	// the alias unmarshals every property but credentialSubject, which may be an object or an array
+	if err := json.Unmarshal(data, &vcJSON); err != nil {
+		return errors.Wrap(err, "unmarshalling credential")
+	}
+	unmarshalled := VerifiableCredential(vcJSON.verifiableCredentialAlias)
+	subjectData := bytes.TrimSpace(vcJSON.CredentialSubject)
+	if len(subjectData) > 0 && subjectData[0] == '[' {
+		var subjects []CredentialSubject
+		if err := json.Unmarshal(subjectData, &subjects); err != nil {
+			return errors.Wrap(err, "unmarshalling credential subjects")
+		}
+		if len(subjects) > 0 {
+			unmarshalled.CredentialSubject = subjects[0]
+			if len(subjects) > 1 {
+				unmarshalled.AdditionalCredentialSubjects = subjects[1:]
+			}
+		}
+	} else if len(subjectData) > 0 {
+		if err := json.Unmarshal(subjectData, &unmarshalled.CredentialSubject); err != nil {
+			return errors.Wrap(err, "unmarshalling credential subject")
+		}
+	}
+	*v = unmarshalled
+	return nil
+}
+
+// CredentialSubjects returns each of the credential's subjects, of which there is more than one when its
+// credentialSubject is an array
+func (v *VerifiableCredential) CredentialSubjects() []CredentialSubject {
+	if v.CredentialSubject == nil && len(v.AdditionalCredentialSubjects) == 0 {
+		return nil
+	}
+	return append([]CredentialSubject{v.CredentialSubject}, v.AdditionalCredentialSubjects...)
+}
+
 func (v *VerifiableCredential) GetProof() *crypto.Proof {
 	return v.Proof
 }
@@ -80,11 +151,14 @@ type RefreshService struct {
 
 // TermsOfUse In the current version of the specification TOU isn't well-defined; these fields are subject to change
 // https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#terms-of-use
+// Policies, such as IssuerPolicy and HolderPolicy, are expressed using ODRL rules https://www.w3.org/TR/odrl-model/
 type TermsOfUse struct {
-	Type        string        `json:"type,omitempty"`
+	Type        string        `json:"type" validate:"required"`
 	ID          string        `json:"id,omitempty"`
 	Profile     string        `json:"profile,omitempty"`
+	Permission  []Permission  `json:"permission,omitempty"`
 	Prohibition []Prohibition `json:"prohibition,omitempty"`
+	Obligation  []Obligation  `json:"obligation,omitempty"`
 }
 
 type Prohibition struct {
@@ -94,6 +168,12 @@ type Prohibition struct {
 	Action   []string `json:"action,omitempty"`
 }
 
+// Permission is a rule allowing the assignee to take an action on the target
+type Permission Prohibition
+
+// Obligation is a rule requiring the assignee to take an action on the target
+type Obligation Prohibition
+
 func (v *VerifiableCredential) IsEmpty() bool {
 	if v == nil {
 		return true
@@ -110,10 +190,13 @@ func (v *VerifiableCredential) IssuerID() string {
 	case string:
 		return typedIssuer
 	case []string:
-		return typedIssuer[0]
+		if len(typedIssuer) > 0 {
+			return typedIssuer[0]
+		}
 	case map[string]any:
-		idValue := typedIssuer["id"]
-		return idValue.(string)
+		if id, ok := typedIssuer["id"].(string); ok {
+			return id
+		}
 	}
 	return ""
 }
@@ -129,6 +212,7 @@ type VerifiablePresentation struct {
 	PresentationSubmission any `json:"presentation_submission,omitempty"`
 	// Verifiable credential could be our object model, a JWT, or any other valid credential representation
 	VerifiableCredential []any         `json:"verifiableCredential,omitempty"`
+	TermsOfUse           []TermsOfUse  `json:"termsOfUse,omitempty" validate:"omitempty,dive"`
 	Proof                *crypto.Proof `json:"proof,omitempty"`
 }
 
@@ -110,3 +110,52 @@ func TestVerifiableCredential_IssuerID(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifiableCredential_MultipleSubjects(t *testing.T) {
+	cred := VerifiableCredential{
+		Context:           []any{VerifiableCredentialsLinkedDataContext},
+		Type:              []any{VerifiableCredentialType, "TeamMembershipCredential"},
+		Issuer:            "did:example:issuer",
+		IssuanceDate:      "2023-01-01T00:00:00Z",
+		CredentialSubject: CredentialSubject{"id": "did:example:alice", "role": "lead"},
+		AdditionalCredentialSubjects: []CredentialSubject{
+			{"id": "did:example:bob", "role": "engineer"},
+		},
+	}
+	assert.NoError(t, cred.IsValid())
+	assert.Len(t, cred.CredentialSubjects(), 2)
+
+	t.Run("array round trip", func(tt *testing.T) {
+		credBytes, err := json.Marshal(cred)
+		assert.NoError(tt, err)
+
+		var credJSON map[string]any
+		assert.NoError(tt, json.Unmarshal(credBytes, &credJSON))
+		assert.Len(tt, credJSON["credentialSubject"], 2)
+
+		var parsed VerifiableCredential
+		assert.NoError(tt, json.Unmarshal(credBytes, &parsed))
+		assert.Equal(tt, cred, parsed)
+	})
+
+	t.Run("single subject is an object", func(tt *testing.T) {
+		single := cred
+		single.AdditionalCredentialSubjects = nil
+		credBytes, err := json.Marshal(single)
+		assert.NoError(tt, err)
+
+		var credJSON map[string]any
+		assert.NoError(tt, json.Unmarshal(credBytes, &credJSON))
+		assert.IsType(tt, map[string]any{}, credJSON["credentialSubject"])
+
+		var parsed VerifiableCredential
+		assert.NoError(tt, json.Unmarshal(credBytes, &parsed))
+		assert.Equal(tt, single, parsed)
+	})
+
+	t.Run("empty additional subject is not valid", func(tt *testing.T) {
+		invalid := cred
+		invalid.AdditionalCredentialSubjects = []CredentialSubject{{}}
+		assert.Error(tt, invalid.IsValid())
+	})
+}
@@ -15,6 +15,11 @@ import (
 
 // ToCredential turn a generic cred into its known object model
 func ToCredential(genericCred any) (jws.Headers, jwt.Token, *credential.VerifiableCredential, error) {
+	// an enveloped credential holds a v2.0 credential secured with JOSE or SD-JWT, which has no claims to map
+	if envelope, ok := credential.ToEnvelopedVerifiableCredential(genericCred); ok {
+		headers, cred, err := integrity.ParseEnvelopedVerifiableCredential(*envelope)
+		return headers, nil, cred, err
+	}
 	switch typedCred := genericCred.(type) {
 	case []byte:
 		// could be a JWT
@@ -40,6 +45,16 @@ func ToCredential(genericCred any) (jws.Headers, jwt.Token, *credential.Verifiab
 			return nil, nil, &cred, nil
 		}
 
+		// next try it as a v2.0 credential secured with JOSE, which has no claims to map
+		if integrity.IsVerifiableCredentialJOSE(typedCred) {
+			headers, cred, err := integrity.ParseVerifiableCredentialFromJOSE(typedCred)
+			return headers, nil, cred, err
+		}
+		if integrity.IsVerifiableCredentialSDJWT(typedCred) {
+			headers, cred, err := integrity.ParseVerifiableCredentialFromSDJWT(typedCred)
+			return headers, nil, cred, err
+		}
+
 		// next try it as a JWT
 		return integrity.ParseVerifiableCredentialFromJWT(typedCred)
 	case map[string]any:
@@ -67,6 +82,13 @@ func ToCredential(genericCred any) (jws.Headers, jwt.Token, *credential.Verifiab
 
 // ToCredentialJSONMap turn a generic cred into a JSON object
 func ToCredentialJSONMap(genericCred any) (map[string]any, error) {
+	if envelope, ok := credential.ToEnvelopedVerifiableCredential(genericCred); ok {
+		_, cred, err := integrity.ParseEnvelopedVerifiableCredential(*envelope)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing enveloped credential")
+		}
+		return ToCredentialJSONMap(cred)
+	}
 	switch typedCred := genericCred.(type) {
 	case []byte:
 		// could be a JWT
@@ -90,6 +112,22 @@ func ToCredentialJSONMap(genericCred any) (map[string]any, error) {
 			return credJSON, nil
 		}
 
+		// next try it as a v2.0 credential secured with JOSE, whose payload is the credential
+		if integrity.IsVerifiableCredentialJOSE(typedCred) {
+			_, cred, err := integrity.ParseVerifiableCredentialFromJOSE(typedCred)
+			if err != nil {
+				return nil, errors.Wrap(err, "parsing credential from JOSE")
+			}
+			return ToCredentialJSONMap(cred)
+		}
+		if integrity.IsVerifiableCredentialSDJWT(typedCred) {
+			_, cred, err := integrity.ParseVerifiableCredentialFromSDJWT(typedCred)
+			if err != nil {
+				return nil, errors.Wrap(err, "parsing credential from SD-JWT")
+			}
+			return ToCredentialJSONMap(cred)
+		}
+
 		// next try it as a JWT
 		_, token, _, err := integrity.ParseVerifiableCredentialFromJWT(typedCred)
 		if err != nil {
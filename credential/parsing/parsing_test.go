@@ -8,6 +8,7 @@ import (
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 )
@@ -149,6 +150,49 @@ func TestCredentialsFromInterface(t *testing.T) {
 		assert.NotEmpty(tt, genericCred)
 		assert.Equal(tt, parsedCred.Issuer, genericCred["iss"])
 	})
+
+	t.Run("JOSE Cred", func(tt *testing.T) {
+		knownJWK := jwx.PrivateKeyJWK{
+			KID: "key-0",
+			KTY: "OKP",
+			CRV: "Ed25519",
+			X:   "JYCAGl6C7gcDeKbNqtXBfpGzH0f5elifj7L6zYNj_Is",
+			D:   "pLMxJruKPovJlxF3Lu_x9Aw3qe2wcj5WhKUAXYLBjwE",
+		}
+
+		signer, err := jwx.NewJWXSignerFromJWK("signer-id", knownJWK)
+		assert.NoError(tt, err)
+
+		testCred := getTestCredential()
+		testCred.Context = []any{credential.VerifiableCredentialsV2LinkedDataContext}
+		testCred.IssuanceDate = ""
+		testCred.ValidFrom = "2021-01-01T19:23:24Z"
+		signed, err := integrity.SignVerifiableCredentialJOSE(*signer, testCred)
+		assert.NoError(tt, err)
+
+		headers, token, parsedCred, err := ToCredential(string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, integrity.VCJOSEType, headers.Type())
+		assert.Nil(tt, token)
+		assert.Equal(tt, testCred.Issuer, parsedCred.Issuer)
+		assert.Equal(tt, testCred.ValidFrom, parsedCred.ValidFrom)
+
+		genericCred, err := ToCredentialJSONMap(string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, testCred.Issuer, genericCred["issuer"])
+
+		// enveloped, as it would be within a v2.0 presentation
+		envelopeMap, err := util.ToJSONMap(credential.NewEnvelopedVerifiableCredential(credential.VCJWTMediaType, signed))
+		assert.NoError(tt, err)
+		headers, _, parsedCred, err = ToCredential(envelopeMap)
+		assert.NoError(tt, err)
+		assert.Equal(tt, integrity.VCJOSEType, headers.Type())
+		assert.Equal(tt, testCred.Issuer, parsedCred.Issuer)
+
+		genericCred, err = ToCredentialJSONMap(envelopeMap)
+		assert.NoError(tt, err)
+		assert.Equal(tt, testCred.Issuer, genericCred["issuer"])
+	})
 }
 
 func getTestCredential() credential.VerifiableCredential {
@@ -0,0 +1,171 @@
+package parsing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+// DecodedCredential is a credential read by a CredentialDecoder, with the headers and token of credentials which
+// were JWTs
+type DecodedCredential struct {
+	// Index is the position of the credential in the stream
+	Index      int
+	Headers    jws.Headers
+	Token      jwt.Token
+	Credential *credential.VerifiableCredential
+}
+
+// CredentialDecodeError is returned by CredentialDecoder.Next for a credential in the stream which could not be
+// parsed. Decoding can continue with the next credential.
+type CredentialDecodeError struct {
+	Index int
+	Err   error
+}
+
+func (e *CredentialDecodeError) Error() string {
+	return fmt.Sprintf("decoding credential<%d>: %s", e.Index, e.Err)
+}
+
+func (e *CredentialDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// CredentialDecoder reads credentials one at a time from a stream holding either a JSON array of credentials, or
+// newline delimited JSON (NDJSON) with one credential per line, so large collections, such as audit logs of millions
+// of credentials, can be processed without reading them into memory at once. Each credential is a JSON credential,
+// or a JWT credential as a JSON string; lines of NDJSON may also be JWTs without quotes.
+type CredentialDecoder struct {
+	reader *bufio.Reader
+	// decoder reads the elements of a JSON array, and is nil for NDJSON
+	decoder *json.Decoder
+	started bool
+	done    bool
+	index   int
+}
+
+// NewCredentialDecoder returns a decoder reading credentials from the given stream, whose format is detected from
+// its first character
+func NewCredentialDecoder(r io.Reader) *CredentialDecoder {
+	return &CredentialDecoder{reader: bufio.NewReader(r)}
+}
+
+// Next returns the next credential in the stream, or io.EOF once every credential has been read. A credential which
+// cannot be parsed returns a *CredentialDecodeError, after which decoding may continue; any other error means the
+// stream itself is malformed, and decoding cannot continue.
+func (d *CredentialDecoder) Next() (*DecodedCredential, error) {
+	if d.done {
+		return nil, io.EOF
+	}
+	if !d.started {
+		if err := d.start(); err != nil {
+			d.done = true
+			return nil, err
+		}
+	}
+
+	var raw []byte
+	var err error
+	if d.decoder != nil {
+		raw, err = d.nextArrayElement()
+	} else {
+		raw, err = d.nextLine()
+	}
+	if err != nil {
+		d.done = true
+		return nil, err
+	}
+
+	index := d.index
+	d.index++
+	headers, token, cred, err := parseStreamedCredential(raw)
+	if err != nil {
+		return nil, &CredentialDecodeError{Index: index, Err: err}
+	}
+	return &DecodedCredential{Index: index, Headers: headers, Token: token, Credential: cred}, nil
+}
+
+// start detects whether the stream is a JSON array or NDJSON
+func (d *CredentialDecoder) start() error {
+	d.started = true
+	for {
+		b, err := d.reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		if isJSONWhitespace(b) {
+			continue
+		}
+		if err = d.reader.UnreadByte(); err != nil {
+			return err
+		}
+		if b == '[' {
+			d.decoder = json.NewDecoder(d.reader)
+			if _, err = d.decoder.Token(); err != nil {
+				return errors.Wrap(err, "reading start of credential array")
+			}
+		}
+		return nil
+	}
+}
+
+func (d *CredentialDecoder) nextArrayElement() ([]byte, error) {
+	if !d.decoder.More() {
+		if _, err := d.decoder.Token(); err != nil {
+			return nil, errors.Wrap(err, "reading end of credential array")
+		}
+		return nil, io.EOF
+	}
+	var raw json.RawMessage
+	if err := d.decoder.Decode(&raw); err != nil {
+		return nil, errors.Wrapf(err, "reading credential<%d>", d.index)
+	}
+	return raw, nil
+}
+
+func (d *CredentialDecoder) nextLine() ([]byte, error) {
+	for {
+		line, err := d.reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, errors.Wrapf(err, "reading credential<%d>", d.index)
+		}
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			return trimmed, nil
+		}
+		if err != nil {
+			return nil, io.EOF
+		}
+	}
+}
+
+// parseStreamedCredential parses a JSON credential, a JWT credential as a JSON string, or an unquoted JWT
+func parseStreamedCredential(raw []byte) (jws.Headers, jwt.Token, *credential.VerifiableCredential, error) {
+	switch raw[0] {
+	case '{':
+		var credJSON map[string]any
+		if err := json.Unmarshal(raw, &credJSON); err != nil {
+			return nil, nil, nil, errors.Wrap(err, "unmarshalling credential")
+		}
+		return ToCredential(credJSON)
+	case '"':
+		var token string
+		if err := json.Unmarshal(raw, &token); err != nil {
+			return nil, nil, nil, errors.Wrap(err, "unmarshalling credential token")
+		}
+		return ToCredential(token)
+	default:
+		return ToCredential(string(raw))
+	}
+}
+
+func isJSONWhitespace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
@@ -0,0 +1,100 @@
+package parsing
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+func TestCredentialDecoder(t *testing.T) {
+	testCred := getTestCredential()
+	credBytes, err := json.Marshal(testCred)
+	require.NoError(t, err)
+
+	signer, err := jwx.NewJWXSignerFromJWK("signer-id", jwx.PrivateKeyJWK{
+		KID: "key-0",
+		KTY: "OKP",
+		CRV: "Ed25519",
+		X:   "JYCAGl6C7gcDeKbNqtXBfpGzH0f5elifj7L6zYNj_Is",
+		D:   "pLMxJruKPovJlxF3Lu_x9Aw3qe2wcj5WhKUAXYLBjwE",
+	})
+	require.NoError(t, err)
+	signed, err := integrity.SignVerifiableCredentialJWT(*signer, testCred)
+	require.NoError(t, err)
+
+	t.Run("JSON array", func(tt *testing.T) {
+		stream := " [" + string(credBytes) + ",\n\"" + string(signed) + "\"]\n"
+		creds := decodeAll(tt, NewCredentialDecoder(strings.NewReader(stream)))
+		require.Len(tt, creds, 2)
+		assert.Equal(tt, 0, creds[0].Index)
+		assert.Nil(tt, creds[0].Token)
+		assert.Equal(tt, testCred.Issuer, creds[0].Credential.Issuer)
+		assert.Equal(tt, 1, creds[1].Index)
+		assert.NotNil(tt, creds[1].Token)
+		assert.Equal(tt, testCred.Issuer, creds[1].Credential.Issuer)
+	})
+
+	t.Run("NDJSON", func(tt *testing.T) {
+		stream := string(credBytes) + "\n\n" + string(signed) + "\r\n\"" + string(signed) + "\""
+		creds := decodeAll(tt, NewCredentialDecoder(strings.NewReader(stream)))
+		require.Len(tt, creds, 3)
+		assert.Nil(tt, creds[0].Token)
+		assert.NotNil(tt, creds[1].Token)
+		assert.NotNil(tt, creds[2].Token)
+		assert.Equal(tt, 2, creds[2].Index)
+	})
+
+	t.Run("empty streams", func(tt *testing.T) {
+		assert.Empty(tt, decodeAll(tt, NewCredentialDecoder(strings.NewReader(""))))
+		assert.Empty(tt, decodeAll(tt, NewCredentialDecoder(strings.NewReader(" [ ] "))))
+	})
+
+	t.Run("bad credential continues", func(tt *testing.T) {
+		decoder := NewCredentialDecoder(strings.NewReader("[\"bad\", " + string(credBytes) + "]"))
+		_, err := decoder.Next()
+		var decodeErr *CredentialDecodeError
+		require.ErrorAs(tt, err, &decodeErr)
+		assert.Equal(tt, 0, decodeErr.Index)
+
+		decoded, err := decoder.Next()
+		require.NoError(tt, err)
+		assert.Equal(tt, 1, decoded.Index)
+
+		_, err = decoder.Next()
+		assert.ErrorIs(tt, err, io.EOF)
+	})
+
+	t.Run("malformed stream stops", func(tt *testing.T) {
+		decoder := NewCredentialDecoder(strings.NewReader("[" + string(credBytes) + ", {"))
+		_, err := decoder.Next()
+		require.NoError(tt, err)
+
+		_, err = decoder.Next()
+		require.Error(tt, err)
+		var decodeErr *CredentialDecodeError
+		assert.False(tt, errors.As(err, &decodeErr))
+
+		_, err = decoder.Next()
+		assert.ErrorIs(tt, err, io.EOF)
+	})
+}
+
+func decodeAll(t *testing.T, decoder *CredentialDecoder) []DecodedCredential {
+	var creds []DecodedCredential
+	for {
+		decoded, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			return creds
+		}
+		require.NoError(t, err)
+		creds = append(creds, *decoded)
+	}
+}
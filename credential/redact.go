@@ -0,0 +1,175 @@
+package credential
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/goccy/go-json"
+	"github.com/gowebpki/jcs"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// RedactionMethod is how RedactCredential redacts a claim
+type RedactionMethod string
+
+const (
+	// RemoveClaim removes redacted claims from the credential
+	RemoveClaim RedactionMethod = "remove"
+	// HashClaim replaces redacted claims with a digest of their value, so records of the same claim can be correlated
+	// without retaining the claim itself
+	HashClaim RedactionMethod = "hash"
+
+	// SHA256DigestPrefix prefixes the digests of claims hashed without a key
+	SHA256DigestPrefix = "sha256:"
+	// HMACSHA256DigestPrefix prefixes the digests of claims hashed with a key
+	HMACSHA256DigestPrefix = "hmac-sha256:"
+)
+
+// RedactionOptions configures how RedactCredential redacts claims
+type RedactionOptions struct {
+	// Method is how claims are redacted, defaulting to RemoveClaim
+	Method RedactionMethod
+	// HMACKey, if set, keys the digests of hashed claims, which prevents low entropy claims, such as a birthdate,
+	// from being recovered by hashing guesses
+	HMACKey []byte
+}
+
+// RedactCredential returns a copy of a credential whose claims at the given JSON pointers
+// https://www.rfc-editor.org/rfc/rfc6901 are removed or hashed, for logging and auditing what was verified without
+// retaining personal information. Pointers refer to the credential's JSON form, e.g. `/credentialSubject/birthDate`.
+// Hashed claims are replaced by the base64url encoded SHA-256 digest of their JCS canonical form
+// https://www.rfc-editor.org/rfc/rfc8785, prefixed with SHA256DigestPrefix or HMACSHA256DigestPrefix.
+// The copy has no proof, since its proof would not verify once claims are redacted. The original is not modified.
+func RedactCredential(cred VerifiableCredential, pointers []string, opts RedactionOptions) (*VerifiableCredential, error) {
+	method := opts.Method
+	if method == "" {
+		method = RemoveClaim
+	}
+	if method != RemoveClaim && method != HashClaim {
+		return nil, fmt.Errorf("unsupported redaction method<%s>", method)
+	}
+
+	cred.Proof = nil
+	credMap, err := util.ToJSONMap(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting credential to json map")
+	}
+	for _, pointer := range pointers {
+		if err = redactPointer(credMap, pointer, method, opts.HMACKey); err != nil {
+			return nil, errors.Wrapf(err, "redacting %s", pointer)
+		}
+	}
+
+	redactedBytes, err := json.Marshal(removeRedactedElements(credMap))
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling redacted credential")
+	}
+	var redacted VerifiableCredential
+	if err = json.Unmarshal(redactedBytes, &redacted); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling redacted credential")
+	}
+	return &redacted, nil
+}
+
+// redactedElement marks removed array elements, which are dropped once every pointer is redacted so that the indices
+// of later pointers into the same array still refer to the original elements
+type redactedElement struct{}
+
+func redactPointer(document map[string]any, pointer string, method RedactionMethod, key []byte) error {
+	tokens, err := cryptosuite.ParseJSONPointer(pointer)
+	if err != nil {
+		return err
+	}
+
+	var parent any = document
+	for i, token := range tokens {
+		last := i == len(tokens)-1
+		switch typed := parent.(type) {
+		case map[string]any:
+			value, ok := typed[token]
+			if !ok {
+				return fmt.Errorf("property<%s> not found", token)
+			}
+			if !last {
+				parent = value
+				continue
+			}
+			if method == RemoveClaim {
+				delete(typed, token)
+				return nil
+			}
+			digest, err := digestClaim(value, key)
+			if err != nil {
+				return err
+			}
+			typed[token] = digest
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(typed) {
+				return fmt.Errorf("array index<%s> not found", token)
+			}
+			if _, ok := typed[index].(redactedElement); ok {
+				return fmt.Errorf("array index<%s> is already removed", token)
+			}
+			if !last {
+				parent = typed[index]
+				continue
+			}
+			if method == RemoveClaim {
+				typed[index] = redactedElement{}
+				return nil
+			}
+			digest, err := digestClaim(typed[index], key)
+			if err != nil {
+				return err
+			}
+			typed[index] = digest
+		default:
+			return fmt.Errorf("cannot reference<%s> within a value that is not an object or array", token)
+		}
+	}
+	return nil
+}
+
+// digestClaim returns the prefixed digest of a claim's canonical form
+func digestClaim(value any, key []byte) (string, error) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling claim")
+	}
+	canonical, err := jcs.Transform(valueBytes)
+	if err != nil {
+		return "", errors.Wrap(err, "canonicalizing claim")
+	}
+	if len(key) > 0 {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(canonical)
+		return HMACSHA256DigestPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+	}
+	digest := sha256.Sum256(canonical)
+	return SHA256DigestPrefix + base64.RawURLEncoding.EncodeToString(digest[:]), nil
+}
+
+func removeRedactedElements(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for k, v := range typed {
+			typed[k] = removeRedactedElements(v)
+		}
+	case []any:
+		kept := make([]any, 0, len(typed))
+		for _, v := range typed {
+			if _, ok := v.(redactedElement); !ok {
+				kept = append(kept, removeRedactedElements(v))
+			}
+		}
+		return kept
+	}
+	return value
+}
@@ -0,0 +1,82 @@
+package credential
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func getRedactionCredential() VerifiableCredential {
+	return VerifiableCredential{
+		Context:      []any{VerifiableCredentialsLinkedDataContext},
+		ID:           "https://example.com/credentials/1872",
+		Type:         []any{"VerifiableCredential"},
+		Issuer:       "did:example:issuer",
+		IssuanceDate: "2010-01-01T19:23:24Z",
+		CredentialSubject: CredentialSubject{
+			"id":        "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			"birthDate": "1990-01-01",
+			"aliases":   []any{"a", "b", "c"},
+		},
+		Proof: &crypto.Proof{Type: "Ed25519Signature2020"},
+	}
+}
+
+func TestRedactCredential(t *testing.T) {
+	t.Run("remove claims", func(tt *testing.T) {
+		cred := getRedactionCredential()
+		redacted, err := RedactCredential(cred, []string{"/credentialSubject/birthDate", "/credentialSubject/aliases/0", "/credentialSubject/aliases/2"}, RedactionOptions{})
+		require.NoError(tt, err)
+		assert.NotContains(tt, redacted.CredentialSubject, "birthDate")
+		assert.Equal(tt, []any{"b"}, redacted.CredentialSubject["aliases"])
+		assert.Equal(tt, cred.ID, redacted.ID)
+		assert.Nil(tt, redacted.Proof)
+
+		// the original is untouched
+		assert.Equal(tt, "1990-01-01", cred.CredentialSubject["birthDate"])
+		assert.Len(tt, cred.CredentialSubject["aliases"], 3)
+		assert.NotNil(tt, cred.Proof)
+	})
+
+	t.Run("hash claims", func(tt *testing.T) {
+		cred := getRedactionCredential()
+		redacted, err := RedactCredential(cred, []string{"/credentialSubject/birthDate"}, RedactionOptions{Method: HashClaim})
+		require.NoError(tt, err)
+		digest := sha256.Sum256([]byte(`"1990-01-01"`))
+		assert.Equal(tt, SHA256DigestPrefix+base64.RawURLEncoding.EncodeToString(digest[:]), redacted.CredentialSubject["birthDate"])
+
+		keyed, err := RedactCredential(cred, []string{"/credentialSubject/birthDate"}, RedactionOptions{Method: HashClaim, HMACKey: []byte("secret")})
+		require.NoError(tt, err)
+		assert.Contains(tt, keyed.CredentialSubject["birthDate"], HMACSHA256DigestPrefix)
+
+		other, err := RedactCredential(cred, []string{"/credentialSubject/birthDate"}, RedactionOptions{Method: HashClaim, HMACKey: []byte("other")})
+		require.NoError(tt, err)
+		assert.NotEqual(tt, keyed.CredentialSubject["birthDate"], other.CredentialSubject["birthDate"])
+	})
+
+	t.Run("bad pointers", func(tt *testing.T) {
+		cred := getRedactionCredential()
+		_, err := RedactCredential(cred, []string{"credentialSubject"}, RedactionOptions{})
+		assert.ErrorContains(tt, err, "must start with '/'")
+
+		_, err = RedactCredential(cred, []string{"/credentialSubject/name"}, RedactionOptions{})
+		assert.ErrorContains(tt, err, "property<name> not found")
+
+		_, err = RedactCredential(cred, []string{"/credentialSubject/aliases/3"}, RedactionOptions{})
+		assert.ErrorContains(tt, err, "array index<3> not found")
+
+		_, err = RedactCredential(cred, []string{"/credentialSubject/aliases/1", "/credentialSubject/aliases/1"}, RedactionOptions{})
+		assert.ErrorContains(tt, err, "already removed")
+
+		_, err = RedactCredential(cred, []string{"/issuer/id"}, RedactionOptions{})
+		assert.ErrorContains(tt, err, "not an object or array")
+
+		_, err = RedactCredential(cred, nil, RedactionOptions{Method: "encrypt"})
+		assert.ErrorContains(tt, err, "unsupported redaction method<encrypt>")
+	})
+}
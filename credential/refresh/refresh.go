@@ -0,0 +1,216 @@
+package refresh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/credential/parsing"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+// Refresh services allow a holder to obtain a new credential from its issuer, e.g. when it is nearing expiry
+// https://w3c-ccg.github.io/vc-refresh-2021/
+
+const (
+	// VerifiableCredentialRefreshService2021Type is a refresh service which issues a new credential in exchange for a
+	// presentation of the existing credential https://w3c-ccg.github.io/vc-refresh-2021/#verifiablecredentialrefreshservice2021
+	VerifiableCredentialRefreshService2021Type string = "VerifiableCredentialRefreshService2021"
+	// ManualRefreshService2018Type is a refresh service the holder must visit to refresh a credential manually
+	ManualRefreshService2018Type string = "ManualRefreshService2018"
+
+	RefreshServiceContext string = "https://w3id.org/vc-refresh-service/v1"
+
+	VerifiablePresentationProperty string = "verifiablePresentation"
+)
+
+// NewRefreshService returns a VerifiableCredentialRefreshService2021 refresh service at the given URL
+func NewRefreshService(url string) credential.RefreshService {
+	return credential.RefreshService{
+		ID:   url,
+		Type: VerifiableCredentialRefreshService2021Type,
+	}
+}
+
+// SetRefreshService sets a VerifiableCredentialRefreshService2021 refresh service at the given URL on a
+// credential builder, adding the refresh service context
+func SetRefreshService(builder *credential.VerifiableCredentialBuilder, url string) error {
+	if builder == nil || builder.IsEmpty() {
+		return errors.New(credential.BuilderEmptyError)
+	}
+	if url == "" {
+		return errors.New("refresh service url cannot be empty")
+	}
+	if err := builder.AddContext(RefreshServiceContext); err != nil {
+		return errors.Wrap(err, "adding refresh service context")
+	}
+	return builder.SetRefreshService(NewRefreshService(url))
+}
+
+// NeedsRefresh returns whether a credential has a refresh service and expires within the given window of now.
+// Credentials without an expiration date never need refreshing.
+func NeedsRefresh(cred credential.VerifiableCredential, window time.Duration) (bool, error) {
+	if cred.RefreshService == nil || cred.ExpirationDate == "" {
+		return false, nil
+	}
+	expiry, err := time.Parse(time.RFC3339, cred.ExpirationDate)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse expiry date: %s", cred.ExpirationDate)
+	}
+	return time.Now().Add(window).After(expiry), nil
+}
+
+// Client refreshes credentials using their refresh service
+type Client struct {
+	*http.Client
+	// signer authenticates the holder to the refresh service by signing the presentation sent to it
+	signer *jwx.Signer
+}
+
+// NewClient returns a new refresh client using the default HTTP client. If a signer is provided, the presentations
+// sent to refresh services are secured as JWTs signed by the holder; otherwise they are sent unsigned.
+func NewClient(signer *jwx.Signer) *Client {
+	return &Client{Client: http.DefaultClient, signer: signer}
+}
+
+// Refresh exchanges a credential for a new one by sending a presentation of it to its refresh service
+// https://w3c-ccg.github.io/vc-refresh-2021/#verifiablecredentialrefreshservice2021
+// The refreshed credential must be from the same issuer as the original credential.
+// NOTE: this method does not verify the signature of the refreshed credential
+func (c *Client) Refresh(ctx context.Context, cred credential.VerifiableCredential) (*credential.VerifiableCredential, error) {
+	if cred.RefreshService == nil {
+		return nil, fmt.Errorf("credential<%s> does not have a refresh service", cred.ID)
+	}
+	switch cred.RefreshService.Type {
+	case VerifiableCredentialRefreshService2021Type:
+	case ManualRefreshService2018Type:
+		return nil, fmt.Errorf("credential<%s> must be refreshed manually at: %s", cred.ID, cred.RefreshService.ID)
+	default:
+		return nil, fmt.Errorf("refresh service type<%s> is not supported", cred.RefreshService.Type)
+	}
+
+	requestBody, err := c.buildRequest(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "building refresh request")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cred.RefreshService.ID, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "refreshing credential")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("refreshing credential, status code: %d", resp.StatusCode)
+	}
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading refresh response")
+	}
+
+	refreshed, err := parseResponse(responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing refresh response")
+	}
+	if refreshed.IssuerID() != cred.IssuerID() {
+		return nil, fmt.Errorf("refreshed credential issuer<%s> does not match credential issuer<%s>", refreshed.IssuerID(), cred.IssuerID())
+	}
+	return refreshed, nil
+}
+
+// RefreshIfNeeded refreshes a credential if it needs refreshing according to NeedsRefresh, returning the credential
+// to use and whether it was refreshed
+func (c *Client) RefreshIfNeeded(ctx context.Context, cred credential.VerifiableCredential, window time.Duration) (*credential.VerifiableCredential, bool, error) {
+	needsRefresh, err := NeedsRefresh(cred, window)
+	if err != nil {
+		return nil, false, err
+	}
+	if !needsRefresh {
+		return &cred, false, nil
+	}
+	refreshed, err := c.Refresh(ctx, cred)
+	if err != nil {
+		return nil, false, err
+	}
+	return refreshed, true, nil
+}
+
+// buildRequest presents the credential to its refresh service, signing the presentation if the client has a signer
+func (c *Client) buildRequest(cred credential.VerifiableCredential) ([]byte, error) {
+	builder := credential.NewVerifiablePresentationBuilder()
+	if err := builder.AddVerifiableCredentials(cred); err != nil {
+		return nil, err
+	}
+	if c.signer != nil {
+		if err := builder.SetHolder(c.signer.ID); err != nil {
+			return nil, err
+		}
+	}
+	presentation, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	var securedPresentation any = presentation
+	if c.signer != nil {
+		params := integrity.JWTVVPParameters{Audience: []string{cred.RefreshService.ID}}
+		token, err := integrity.SignVerifiablePresentationJWT(*c.signer, &params, *presentation)
+		if err != nil {
+			return nil, errors.Wrap(err, "signing presentation")
+		}
+		securedPresentation = string(token)
+	}
+	return json.Marshal(map[string]any{VerifiablePresentationProperty: securedPresentation})
+}
+
+// parseResponse returns the first credential of the presentation returned by a refresh service, which may be a JSON
+// presentation or a presentation secured as a JWT
+func parseResponse(body []byte) (*credential.VerifiableCredential, error) {
+	var response map[string]any
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling response")
+	}
+	maybePresentation, ok := response[VerifiablePresentationProperty]
+	if !ok {
+		return nil, fmt.Errorf("response does not contain a %s", VerifiablePresentationProperty)
+	}
+
+	var presentation *credential.VerifiablePresentation
+	switch typedPresentation := maybePresentation.(type) {
+	case string:
+		_, _, vp, err := integrity.ParseVerifiablePresentationFromJWT(typedPresentation)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing presentation from JWT")
+		}
+		presentation = vp
+	default:
+		presentationBytes, err := json.Marshal(typedPresentation)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling presentation")
+		}
+		var vp credential.VerifiablePresentation
+		if err = json.Unmarshal(presentationBytes, &vp); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling presentation")
+		}
+		presentation = &vp
+	}
+	if len(presentation.VerifiableCredential) == 0 {
+		return nil, errors.New("presentation does not contain a credential")
+	}
+	_, _, refreshed, err := parsing.ToCredential(presentation.VerifiableCredential[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing refreshed credential")
+	}
+	return refreshed, nil
+}
@@ -0,0 +1,190 @@
+package refresh
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+func TestNeedsRefresh(t *testing.T) {
+	cred := credential.VerifiableCredential{ID: "test-cred"}
+	needsRefresh, err := NeedsRefresh(cred, time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, needsRefresh)
+
+	cred.RefreshService = &credential.RefreshService{ID: "https://example.com/refresh/123", Type: VerifiableCredentialRefreshService2021Type}
+	cred.ExpirationDate = util.AsRFC3339Timestamp(time.Now().Add(30 * time.Minute))
+	needsRefresh, err = NeedsRefresh(cred, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, needsRefresh)
+
+	needsRefresh, err = NeedsRefresh(cred, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, needsRefresh)
+
+	cred.ExpirationDate = "not a date"
+	_, err = NeedsRefresh(cred, time.Minute)
+	assert.ErrorContains(t, err, "failed to parse expiry date")
+}
+
+func TestClient(t *testing.T) {
+	refreshURL := "https://example.com/refresh/123"
+	issuerSigner := getTestSigner(t, "did:example:issuer")
+	holderSigner := getTestSigner(t, "did:example:holder")
+
+	issue := func(tt *testing.T, expiry time.Duration) credential.VerifiableCredential {
+		builder := credential.NewVerifiableCredentialBuilder(credential.GenerateIDValue)
+		require.NoError(tt, builder.SetIssuer(issuerSigner.ID))
+		require.NoError(tt, builder.SetExpirationDate(util.AsRFC3339Timestamp(time.Now().Add(expiry))))
+		require.NoError(tt, builder.SetCredentialSubject(map[string]any{"id": holderSigner.ID}))
+		require.NoError(tt, SetRefreshService(&builder, refreshURL))
+		cred, err := builder.Build()
+		require.NoError(tt, err)
+		return *cred
+	}
+
+	cred := issue(t, 10*time.Minute)
+	assert.Contains(t, cred.Context, RefreshServiceContext)
+	assert.Equal(t, NewRefreshService(refreshURL), *cred.RefreshService)
+
+	refreshedCred := issue(t, 24*time.Hour)
+	refreshedToken, err := integrity.SignVerifiableCredentialJWT(*issuerSigner, refreshedCred)
+	require.NoError(t, err)
+	refreshResponse := map[string]any{
+		VerifiablePresentationProperty: map[string]any{
+			"@context":             []string{credential.VerifiableCredentialsLinkedDataContext},
+			"type":                 []string{credential.VerifiablePresentationType},
+			"verifiableCredential": []any{string(refreshedToken)},
+		},
+	}
+
+	t.Run("refresh with signed presentation", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").
+			Post("/refresh/123").
+			AddMatcher(presentedBy(holderSigner.ID, cred.ID)).
+			Reply(200).JSON(refreshResponse)
+
+		refreshed, didRefresh, err := NewClient(holderSigner).RefreshIfNeeded(context.Background(), cred, time.Hour)
+		assert.NoError(tt, err)
+		assert.True(tt, didRefresh)
+		assert.Equal(tt, refreshedCred.ID, refreshed.ID)
+		assert.True(tt, gock.IsDone())
+	})
+
+	t.Run("no refresh needed", func(tt *testing.T) {
+		refreshed, didRefresh, err := NewClient(holderSigner).RefreshIfNeeded(context.Background(), cred, time.Minute)
+		assert.NoError(tt, err)
+		assert.False(tt, didRefresh)
+		assert.Equal(tt, cred.ID, refreshed.ID)
+	})
+
+	t.Run("refresh with unsigned presentation", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").
+			Post("/refresh/123").
+			AddMatcher(presentedBy("", cred.ID)).
+			Reply(200).JSON(refreshResponse)
+
+		refreshed, err := NewClient(nil).Refresh(context.Background(), cred)
+		assert.NoError(tt, err)
+		assert.Equal(tt, refreshedCred.ID, refreshed.ID)
+	})
+
+	t.Run("refreshed credential from another issuer", func(tt *testing.T) {
+		defer gock.Off()
+		otherCred := refreshedCred
+		otherCred.Issuer = "did:example:other"
+		gock.New("https://example.com").
+			Post("/refresh/123").
+			Reply(200).JSON(map[string]any{
+			VerifiablePresentationProperty: map[string]any{
+				"type":                 []string{credential.VerifiablePresentationType},
+				"verifiableCredential": []any{otherCred},
+			},
+		})
+
+		_, err := NewClient(nil).Refresh(context.Background(), cred)
+		assert.ErrorContains(tt, err, "refreshed credential issuer<did:example:other> does not match credential issuer<did:example:issuer>")
+	})
+
+	t.Run("refresh service errors", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").
+			Post("/refresh/123").
+			Reply(403)
+
+		_, err := NewClient(nil).Refresh(context.Background(), cred)
+		assert.ErrorContains(tt, err, "refreshing credential, status code: 403")
+	})
+
+	t.Run("unsupported refresh services", func(tt *testing.T) {
+		manual := cred
+		manual.RefreshService = &credential.RefreshService{ID: "https://example.com/manual", Type: ManualRefreshService2018Type}
+		_, err := NewClient(nil).Refresh(context.Background(), manual)
+		assert.ErrorContains(tt, err, "must be refreshed manually at: https://example.com/manual")
+
+		manual.RefreshService = nil
+		_, err = NewClient(nil).Refresh(context.Background(), manual)
+		assert.ErrorContains(tt, err, "does not have a refresh service")
+	})
+}
+
+// presentedBy matches refresh requests presenting the credential with the given ID, signed by the given holder if
+// one is provided
+func presentedBy(holder, credID string) gock.MatchFunc {
+	return func(req *http.Request, _ *gock.Request) (bool, error) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return false, err
+		}
+		var request map[string]any
+		if err = json.Unmarshal(body, &request); err != nil {
+			return false, err
+		}
+
+		var presentation *credential.VerifiablePresentation
+		if token, ok := request[VerifiablePresentationProperty].(string); ok {
+			_, _, presentation, err = integrity.ParseVerifiablePresentationFromJWT(token)
+			if err != nil {
+				return false, err
+			}
+		} else {
+			presentationBytes, err := json.Marshal(request[VerifiablePresentationProperty])
+			if err != nil {
+				return false, err
+			}
+			presentation = new(credential.VerifiablePresentation)
+			if err = json.Unmarshal(presentationBytes, presentation); err != nil {
+				return false, err
+			}
+		}
+		if presentation.Holder != holder || len(presentation.VerifiableCredential) != 1 {
+			return false, nil
+		}
+		presented, ok := presentation.VerifiableCredential[0].(map[string]any)
+		return ok && presented["id"] == credID, nil
+	}
+}
+
+func getTestSigner(t *testing.T, id string) *jwx.Signer {
+	_, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	kid := id + "#key-1"
+	signer, err := jwx.NewJWXSigner(id, &kid, privKey)
+	require.NoError(t, err)
+	return signer
+}
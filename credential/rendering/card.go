@@ -0,0 +1,221 @@
+package rendering
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CardFormat is the format a credential is rendered in as a card
+type CardFormat string
+
+const (
+	// HTMLCard is a card rendered as an HTML fragment, to be embedded in a page
+	HTMLCard CardFormat = "html"
+	// SVGCard is a card rendered as a standalone SVG image
+	SVGCard CardFormat = "svg"
+
+	defaultBackgroundColor = "#ffffff"
+	defaultTextColor       = "#000000"
+
+	// dimensions of SVG cards, in pixels, and the characters of description fitting on a line
+	svgCardWidth          = 340
+	svgCardPadding        = 16
+	svgHeroHeight         = 96
+	svgHeaderHeight       = 72
+	svgLineHeight         = 18
+	svgDescriptionLineLen = 48
+)
+
+// hexColorRegex matches the HEX string colors of color resources, which are the only colors rendered
+var hexColorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// RenderCredentialCard renders a credential, of any format supported by parsing.ToCredential, as a card with the
+// given styles and data display, such as those of an output descriptor, for wallet previews and issuer testing
+func RenderCredentialCard(format CardFormat, styles *EntityStyleDescriptor, display DataDisplay, genericCred any) ([]byte, error) {
+	resolved, err := ResolveDataDisplayForCredential(display, genericCred)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving data display")
+	}
+	return RenderCard(format, styles, *resolved)
+}
+
+// RenderCard renders a resolved data display as a card with the given styles, which may be empty. Only HEX colors
+// are rendered, others taking the default black text on white. Values are escaped, and image URIs which are not safe
+// to render are replaced.
+func RenderCard(format CardFormat, styles *EntityStyleDescriptor, display ResolvedDataDisplay) ([]byte, error) {
+	view := newCardView(styles, display)
+	var tmpl *template.Template
+	switch format {
+	case HTMLCard:
+		tmpl = htmlCardTemplate
+	case SVGCard:
+		tmpl = svgCardTemplate
+	default:
+		return nil, fmt.Errorf("unsupported card format<%s>", format)
+	}
+	var card bytes.Buffer
+	if err := tmpl.Execute(&card, view); err != nil {
+		return nil, errors.Wrapf(err, "rendering %s card", format)
+	}
+	return card.Bytes(), nil
+}
+
+// cardView is a card laid out for its templates
+type cardView struct {
+	Background  string
+	Text        string
+	Thumbnail   *ImageResource
+	Hero        *ImageResource
+	Title       string
+	Subtitle    string
+	Description string
+	Properties  []LabeledDisplayValue
+
+	// SVG cards are laid out at fixed positions, in pixels
+	Width        int
+	Height       int
+	Padding      int
+	HeaderY      int
+	Lines        []svgLine
+	PropertyRows []svgLine
+}
+
+// svgLine is a line of text of an SVG card, at a vertical position, with a label for properties
+type svgLine struct {
+	Y     int
+	Label string
+	Text  string
+}
+
+func newCardView(styles *EntityStyleDescriptor, display ResolvedDataDisplay) cardView {
+	view := cardView{
+		Background: defaultBackgroundColor,
+		Text:       defaultTextColor,
+		Properties: display.Properties,
+		Width:      svgCardWidth,
+		Padding:    svgCardPadding,
+	}
+	if !styles.IsEmpty() {
+		view.Thumbnail = styles.Thumbnail
+		view.Hero = styles.Hero
+		if styles.Background != nil && hexColorRegex.MatchString(styles.Background.Color) {
+			view.Background = styles.Background.Color
+		}
+		if styles.Text != nil && hexColorRegex.MatchString(styles.Text.Color) {
+			view.Text = styles.Text.Color
+		}
+	}
+	if display.Title != nil {
+		view.Title = display.Title.Text
+	}
+	if display.Subtitle != nil {
+		view.Subtitle = display.Subtitle.Text
+	}
+	if display.Description != nil {
+		view.Description = display.Description.Text
+	}
+
+	// lay out the SVG card from the top, below the hero image and header
+	y := 0
+	if view.Hero != nil {
+		y += svgHeroHeight
+	}
+	view.HeaderY = y
+	y += svgHeaderHeight
+	for _, line := range wrapText(view.Description, svgDescriptionLineLen) {
+		y += svgLineHeight
+		view.Lines = append(view.Lines, svgLine{Y: y, Text: line})
+	}
+	if len(view.Lines) > 0 {
+		y += svgLineHeight / 2
+	}
+	for _, property := range view.Properties {
+		y += svgLineHeight
+		view.PropertyRows = append(view.PropertyRows, svgLine{Y: y, Label: property.Label, Text: property.Text})
+	}
+	view.Height = y + svgCardPadding
+	return view
+}
+
+// wrapText wraps text into lines of at most the given length, but for words longer than it
+func wrapText(text string, lineLen int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, word := range strings.Fields(text) {
+		if line.Len() > 0 && line.Len()+1+len(word) > lineLen {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteString(" ")
+		}
+		line.WriteString(word)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+var htmlCardTemplate = template.Must(template.New("html").Funcs(cardFuncs).Parse(`<div class="credential-card" style="background-color: {{.Background}}; color: {{.Text}}; border-radius: 12px; overflow: hidden; font-family: sans-serif;">
+{{- with .Hero}}
+  <img class="credential-card-hero" src="{{.URI}}" alt="{{.Alt}}" style="width: 100%; display: block;">
+{{- end}}
+  <div class="credential-card-header" style="display: flex; align-items: center; padding: 16px;">
+{{- with .Thumbnail}}
+    <img class="credential-card-thumbnail" src="{{.URI}}" alt="{{.Alt}}" style="width: 40px; height: 40px; margin-right: 16px;">
+{{- end}}
+    <div>
+{{- with .Title}}
+      <h2 class="credential-card-title" style="margin: 0; font-size: 16px;">{{.}}</h2>
+{{- end}}
+{{- with .Subtitle}}
+      <p class="credential-card-subtitle" style="margin: 0; font-size: 13px;">{{.}}</p>
+{{- end}}
+    </div>
+  </div>
+{{- with .Description}}
+  <p class="credential-card-description" style="margin: 0; padding: 0 16px 16px; font-size: 13px;">{{.}}</p>
+{{- end}}
+{{- if .Properties}}
+  <dl class="credential-card-properties" style="margin: 0; padding: 0 16px 16px; font-size: 13px;">
+{{- range .Properties}}
+    <div class="credential-card-property" style="display: flex; justify-content: space-between;"><dt>{{.Label}}</dt><dd style="margin: 0;">{{.Text}}</dd></div>
+{{- end}}
+  </dl>
+{{- end}}
+</div>
+`))
+
+var svgCardTemplate = template.Must(template.New("svg").Funcs(cardFuncs).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" role="img" aria-label="{{.Title}}" font-family="sans-serif">
+  <rect width="{{.Width}}" height="{{.Height}}" rx="12" fill="{{.Background}}"/>
+{{- with .Hero}}
+  <image href="{{.URI}}" x="0" y="0" width="{{$.Width}}" height="{{heroHeight}}" preserveAspectRatio="xMidYMid slice"><title>{{.Alt}}</title></image>
+{{- end}}
+{{- $textX := .Padding}}
+{{- with .Thumbnail}}
+  <image href="{{.URI}}" x="{{$.Padding}}" y="{{add $.HeaderY $.Padding}}" width="40" height="40"><title>{{.Alt}}</title></image>
+{{- $textX = add $.Padding 56}}
+{{- end}}
+  <text x="{{$textX}}" y="{{add .HeaderY 34}}" font-size="16" font-weight="bold" fill="{{.Text}}">{{.Title}}</text>
+  <text x="{{$textX}}" y="{{add .HeaderY 54}}" font-size="13" fill="{{.Text}}">{{.Subtitle}}</text>
+{{- range .Lines}}
+  <text x="{{$.Padding}}" y="{{.Y}}" font-size="12" fill="{{$.Text}}">{{.Text}}</text>
+{{- end}}
+{{- range .PropertyRows}}
+  <text x="{{$.Padding}}" y="{{.Y}}" font-size="13" fill="{{$.Text}}">{{.Label}}</text>
+  <text x="{{sub $.Width $.Padding}}" y="{{.Y}}" font-size="13" font-weight="bold" text-anchor="end" fill="{{$.Text}}">{{.Text}}</text>
+{{- end}}
+</svg>
+`))
+
+var cardFuncs = template.FuncMap{
+	"add":        func(a, b int) int { return a + b },
+	"sub":        func(a, b int) int { return a - b },
+	"heroHeight": func() int { return svgHeroHeight },
+}
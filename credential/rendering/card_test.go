@@ -0,0 +1,79 @@
+package rendering
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderCard(t *testing.T) {
+	vector, err := getTestVector(EntityStylesVector1)
+	require.NoError(t, err)
+	var styles EntityStyleDescriptor
+	require.NoError(t, json.Unmarshal([]byte(vector), &styles))
+
+	display := ResolvedDataDisplay{
+		Title:       &DisplayValue{Type: StringType, Value: "Driver License", Text: "Driver License"},
+		Subtitle:    &DisplayValue{Type: StringType, Value: "Class A", Text: "Class A"},
+		Description: &DisplayValue{Type: StringType, Value: "License to operate a vehicle", Text: "License to operate a vehicle"},
+		Properties: []LabeledDisplayValue{
+			{Label: "Organ Donor", DisplayValue: DisplayValue{Type: BooleanType, Value: true, Text: "true"}},
+		},
+	}
+
+	t.Run("HTML card", func(tt *testing.T) {
+		card, err := RenderCard(HTMLCard, &styles, display)
+		require.NoError(tt, err)
+		html := string(card)
+		assert.Contains(tt, html, "background-color: #ff0000")
+		assert.Contains(tt, html, "color: #d4d400")
+		assert.Contains(tt, html, `src="https://dol.wa.com/logo.png"`)
+		assert.Contains(tt, html, `src="https://dol.wa.com/people-working.png"`)
+		assert.Contains(tt, html, "Driver License")
+		assert.Contains(tt, html, "<dt>Organ Donor</dt>")
+	})
+
+	t.Run("SVG card is well formed", func(tt *testing.T) {
+		card, err := RenderCard(SVGCard, &styles, display)
+		require.NoError(tt, err)
+		var svg struct {
+			XMLName xml.Name
+			Texts   []string `xml:"text"`
+		}
+		require.NoError(tt, xml.Unmarshal(card, &svg))
+		assert.Equal(tt, "svg", svg.XMLName.Local)
+		assert.Equal(tt, []string{"Driver License", "Class A", "License to operate a vehicle", "Organ Donor", "true"}, svg.Texts)
+	})
+
+	t.Run("values and styles are escaped", func(tt *testing.T) {
+		unsafeStyles := EntityStyleDescriptor{
+			Thumbnail:  &ImageResource{URI: "javascript:alert(1)"},
+			Background: &ColorResource{Color: "red; background-image: url(https://example.com)"},
+		}
+		unsafeDisplay := ResolvedDataDisplay{
+			Title: &DisplayValue{Type: StringType, Value: "<script>", Text: "<script>"},
+		}
+		for _, format := range []CardFormat{HTMLCard, SVGCard} {
+			card, err := RenderCard(format, &unsafeStyles, unsafeDisplay)
+			require.NoError(tt, err)
+			assert.NotContains(tt, string(card), "<script>")
+			assert.NotContains(tt, string(card), "javascript:")
+			assert.NotContains(tt, string(card), "example.com")
+			assert.Contains(tt, string(card), defaultBackgroundColor)
+		}
+	})
+
+	t.Run("unsupported format", func(tt *testing.T) {
+		_, err := RenderCard("pdf", nil, display)
+		assert.ErrorContains(tt, err, "unsupported card format<pdf>")
+	})
+}
+
+func TestWrapText(t *testing.T) {
+	assert.Empty(t, wrapText("", 10))
+	assert.Equal(t, []string{"a license", "to drive"}, wrapText("a license to drive", 10))
+	assert.Equal(t, []string{"unbreakable-word", "a"}, wrapText("unbreakable-word a", 10))
+}
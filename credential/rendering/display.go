@@ -0,0 +1,250 @@
+package rendering
+
+import (
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/oliveagle/jsonpath"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/parsing"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// DisplayValue is the value a display mapping object resolves to for a credential, typed for display
+// https://identity.foundation/wallet-rendering/#using-path
+type DisplayValue struct {
+	Type   SchemaType   `json:"type"`
+	Format SchemaFormat `json:"format,omitempty"`
+	// Value is a string, a bool for the boolean type, a float64 for the number type, or an int64 for the integer
+	// type. A string of a date or time format is parsed into a time.Time.
+	Value any `json:"value"`
+	// Text is the value as text, as it is to be displayed where no richer display of its type and format is
+	Text string `json:"text"`
+	// IsFallback is whether the value is the fallback of the display mapping object, since no path resolved to a
+	// value of its schema
+	IsFallback bool `json:"isFallback,omitempty"`
+	// FallbackReason is why no path resolved to a value of the schema, for a fallback value
+	FallbackReason FallbackReason `json:"fallbackReason,omitempty"`
+}
+
+// FallbackReason is why a display mapping object's paths did not resolve to a value, such that its fallback is
+// displayed
+type FallbackReason string
+
+const (
+	// MissingValueFallback is the reason of paths which do not resolve to a value, or resolve to null
+	MissingValueFallback FallbackReason = "missing-value"
+	// TypeMismatchFallback is the reason of paths which resolve to values, none of which are of the schema's type
+	// and format
+	TypeMismatchFallback FallbackReason = "type-mismatch"
+)
+
+// LabeledDisplayValue is the value a labeled display mapping object resolves to
+type LabeledDisplayValue struct {
+	Label string `json:"label"`
+	DisplayValue
+}
+
+// ResolvedDataDisplay is a data display resolved for a credential, holding only the values which resolved
+type ResolvedDataDisplay struct {
+	Title       *DisplayValue         `json:"title,omitempty"`
+	Subtitle    *DisplayValue         `json:"subtitle,omitempty"`
+	Description *DisplayValue         `json:"description,omitempty"`
+	Properties  []LabeledDisplayValue `json:"properties,omitempty"`
+}
+
+// ResolveDataDisplayForCredential resolves a data display, such as that of an output descriptor, for a credential
+// of any format supported by parsing.ToCredential, whose paths select properties of the credential's data model
+func ResolveDataDisplayForCredential(display DataDisplay, genericCred any) (*ResolvedDataDisplay, error) {
+	_, _, cred, err := parsing.ToCredential(genericCred)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing credential")
+	}
+	credJSON, err := util.ToJSONMap(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting credential to JSON")
+	}
+	return ResolveDataDisplay(display, credJSON)
+}
+
+// ResolveDataDisplay resolves each display mapping object of a data display against a credential's JSON. Mappings
+// resolving to no value are left out, such that properties resolving to no value are not displayed.
+func ResolveDataDisplay(display DataDisplay, credJSON map[string]any) (*ResolvedDataDisplay, error) {
+	var resolved ResolvedDataDisplay
+	var err error
+	if resolved.Title, err = resolveIfPresent(display.Title, credJSON); err != nil {
+		return nil, errors.Wrap(err, "resolving title")
+	}
+	if resolved.Subtitle, err = resolveIfPresent(display.Subtitle, credJSON); err != nil {
+		return nil, errors.Wrap(err, "resolving subtitle")
+	}
+	if resolved.Description, err = resolveIfPresent(display.Description, credJSON); err != nil {
+		return nil, errors.Wrap(err, "resolving description")
+	}
+	for i, property := range display.Properties {
+		if err = property.IsValid(); err != nil {
+			return nil, errors.Wrapf(err, "property<%d> is not valid", i)
+		}
+		value, err := ResolveDisplayMappingObject(*property.DisplayMappingObject, credJSON)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving property<%s>", property.Label)
+		}
+		if value != nil {
+			resolved.Properties = append(resolved.Properties, LabeledDisplayValue{Label: property.Label, DisplayValue: *value})
+		}
+	}
+	return &resolved, nil
+}
+
+func resolveIfPresent(dmo *DisplayMappingObject, credJSON map[string]any) (*DisplayValue, error) {
+	if dmo.IsEmpty() {
+		return nil, nil
+	}
+	return ResolveDisplayMappingObject(*dmo, credJSON)
+}
+
+// ResolveDisplayMappingObject resolves a display mapping object against a credential's JSON. Its text is displayed
+// as it is. Otherwise, the value of the first of its paths which resolves to a value of its schema is displayed, where
+// of a path resolving to an array, such as by a filter, the first element of the schema is. Paths which do not
+// resolve, resolve to null, or resolve to a value not of the schema are skipped. If none resolves, its fallback is
+// displayed with the reason why, or, without a fallback, no value is, and the property is not to be rendered.
+// https://identity.foundation/wallet-rendering/#display-mapping-object
+func ResolveDisplayMappingObject(dmo DisplayMappingObject, credJSON map[string]any) (*DisplayValue, error) {
+	if err := dmo.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "display mapping object is not valid")
+	}
+	if dmo.Text != nil {
+		return &DisplayValue{Type: StringType, Value: *dmo.Text, Text: *dmo.Text}, nil
+	}
+
+	reason := MissingValueFallback
+	for _, path := range dmo.Path {
+		resolved, err := jsonpath.JsonPathLookup(credJSON, path)
+		if err != nil || resolved == nil {
+			continue
+		}
+		candidates := []any{resolved}
+		if elements, ok := resolved.([]any); ok {
+			candidates = elements
+		}
+		for _, candidate := range candidates {
+			if candidate == nil {
+				continue
+			}
+			if value, ok := toDisplayValue(candidate, *dmo.Schema); ok {
+				return value, nil
+			}
+			reason = TypeMismatchFallback
+		}
+	}
+	if dmo.Fallback == "" {
+		return nil, nil
+	}
+	return &DisplayValue{
+		Type:           StringType,
+		Value:          dmo.Fallback,
+		Text:           dmo.Fallback,
+		IsFallback:     true,
+		FallbackReason: reason,
+	}, nil
+}
+
+// toDisplayValue types a resolved value according to a display mapping schema, returning whether it is of the schema
+func toDisplayValue(resolved any, schema DisplayMappingSchema) (*DisplayValue, bool) {
+	value := DisplayValue{Type: schema.Type, Format: schema.Format}
+	switch schema.Type {
+	case StringType:
+		s, ok := resolved.(string)
+		if !ok {
+			return nil, false
+		}
+		typed, ok := typeStringFormat(s, schema.Format)
+		if !ok {
+			return nil, false
+		}
+		value.Value, value.Text = typed, s
+	case BooleanType:
+		b, ok := resolved.(bool)
+		if !ok {
+			return nil, false
+		}
+		value.Value, value.Text = b, strconv.FormatBool(b)
+	case NumberType:
+		n, ok := toNumber(resolved)
+		if !ok {
+			return nil, false
+		}
+		value.Value, value.Text = n, strconv.FormatFloat(n, 'f', -1, 64)
+	case IntegerType:
+		n, ok := toNumber(resolved)
+		if !ok || n != math.Trunc(n) {
+			return nil, false
+		}
+		value.Value, value.Text = int64(n), strconv.FormatInt(int64(n), 10)
+	default:
+		return nil, false
+	}
+	return &value, true
+}
+
+// typeStringFormat checks a string is of a format, parsing those of dates and times into a time.Time. Strings of
+// formats not known are displayed as they are.
+func typeStringFormat(s string, format SchemaFormat) (any, bool) {
+	switch format {
+	case DateTimeFormat:
+		t, err := time.Parse(time.RFC3339, s)
+		return t, err == nil
+	case DateFormat:
+		t, err := time.Parse(time.DateOnly, s)
+		return t, err == nil
+	case TimeFormat:
+		for _, layout := range []string{"15:04:05Z07:00", "15:04:05.999999999Z07:00", time.TimeOnly} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+		return nil, false
+	case EmailFormat, IDNEmailFormat:
+		_, err := mail.ParseAddress(s)
+		return s, err == nil
+	case IPV4Format:
+		ip := net.ParseIP(s)
+		return s, ip != nil && ip.To4() != nil
+	case IPV6Format:
+		ip := net.ParseIP(s)
+		return s, ip != nil && ip.To4() == nil
+	case URIFormat, IRIFormat:
+		u, err := url.Parse(s)
+		return s, err == nil && u.IsAbs()
+	case URIReferenceFormat, IRIReferenceFormat:
+		_, err := url.Parse(s)
+		return s, err == nil
+	}
+	return s, true
+}
+
+// toNumber returns a JSON number as a float64
+func toNumber(resolved any) (float64, bool) {
+	switch n := resolved.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
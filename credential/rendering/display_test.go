@@ -0,0 +1,169 @@
+package rendering
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+func TestResolveDisplayMappingObject(t *testing.T) {
+	credJSON := map[string]any{
+		"credentialSubject": map[string]any{
+			"givenName":   "Alice",
+			"dateOfBirth": "1990-01-01",
+			"age":         float64(34),
+			"height":      1.7,
+			"verified":    true,
+			"email":       "not an email",
+		},
+	}
+
+	t.Run("text is displayed as it is", func(tt *testing.T) {
+		text := "Driver License"
+		value, err := ResolveDisplayMappingObject(DisplayMappingObject{Text: &text}, credJSON)
+		require.NoError(tt, err)
+		assert.Equal(tt, &DisplayValue{Type: StringType, Value: text, Text: text}, value)
+	})
+
+	t.Run("paths are typed by their schema", func(tt *testing.T) {
+		tests := []struct {
+			path   string
+			schema DisplayMappingSchema
+			value  any
+			text   string
+		}{
+			{"$.credentialSubject.givenName", DisplayMappingSchema{Type: StringType}, "Alice", "Alice"},
+			{"$.credentialSubject.dateOfBirth", DisplayMappingSchema{Type: StringType, Format: DateFormat}, time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), "1990-01-01"},
+			{"$.credentialSubject.age", DisplayMappingSchema{Type: IntegerType}, int64(34), "34"},
+			{"$.credentialSubject.height", DisplayMappingSchema{Type: NumberType}, 1.7, "1.7"},
+			{"$.credentialSubject.verified", DisplayMappingSchema{Type: BooleanType}, true, "true"},
+		}
+		for _, test := range tests {
+			schema := test.schema
+			value, err := ResolveDisplayMappingObject(DisplayMappingObject{Path: []string{test.path}, Schema: &schema}, credJSON)
+			require.NoError(tt, err)
+			require.NotNil(tt, value, test.path)
+			assert.Equal(tt, test.value, value.Value)
+			assert.Equal(tt, test.text, value.Text)
+			assert.False(tt, value.IsFallback)
+		}
+	})
+
+	t.Run("first path of the schema is displayed", func(tt *testing.T) {
+		dmo := DisplayMappingObject{
+			Path:   []string{"$.credentialSubject.missing", "$.credentialSubject.height", "$.credentialSubject.age"},
+			Schema: &DisplayMappingSchema{Type: IntegerType},
+		}
+		value, err := ResolveDisplayMappingObject(dmo, credJSON)
+		require.NoError(tt, err)
+		assert.Equal(tt, int64(34), value.Value)
+	})
+
+	t.Run("fallback is displayed when no path resolves to a value of the schema", func(tt *testing.T) {
+		dmo := DisplayMappingObject{
+			Path:     []string{"$.credentialSubject.email"},
+			Schema:   &DisplayMappingSchema{Type: StringType, Format: EmailFormat},
+			Fallback: "Unknown",
+		}
+		value, err := ResolveDisplayMappingObject(dmo, credJSON)
+		require.NoError(tt, err)
+		assert.Equal(tt, "Unknown", value.Text)
+		assert.True(tt, value.IsFallback)
+		assert.Equal(tt, TypeMismatchFallback, value.FallbackReason)
+
+		dmo.Fallback = ""
+		value, err = ResolveDisplayMappingObject(dmo, credJSON)
+		assert.NoError(tt, err)
+		assert.Nil(tt, value)
+	})
+
+	t.Run("invalid display mapping object", func(tt *testing.T) {
+		_, err := ResolveDisplayMappingObject(DisplayMappingObject{Path: []string{"$.credentialSubject.givenName"}}, credJSON)
+		assert.ErrorContains(tt, err, "schema cannot be empty when path is present")
+	})
+}
+
+func TestResolveDataDisplayForCredential(t *testing.T) {
+	vector, err := getTestVector(DisplayMappingPathVector1)
+	require.NoError(t, err)
+	var title DisplayMappingObject
+	require.NoError(t, json.Unmarshal([]byte(vector), &title))
+
+	description := "A license to drive"
+	display := DataDisplay{
+		Title:       &title,
+		Description: &DisplayMappingObject{Text: &description},
+		Properties: []LabeledDisplayMappingObject{
+			{
+				Label: "Name",
+				DisplayMappingObject: &DisplayMappingObject{
+					Path:   []string{"$.credentialSubject.name"},
+					Schema: &DisplayMappingSchema{Type: StringType},
+				},
+			},
+			{
+				Label: "Class",
+				DisplayMappingObject: &DisplayMappingObject{
+					Path:   []string{"$.credentialSubject.class"},
+					Schema: &DisplayMappingSchema{Type: StringType},
+				},
+			},
+		},
+	}
+	cred := credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		ID:                "test-credential",
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            "did:example:issuer",
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:subject", "name": "Alice"},
+	}
+
+	resolved, err := ResolveDataDisplayForCredential(display, cred)
+	require.NoError(t, err)
+	assert.Equal(t, "Washington State Driver License", resolved.Title.Text)
+	assert.True(t, resolved.Title.IsFallback)
+	assert.Nil(t, resolved.Subtitle)
+	assert.Equal(t, description, resolved.Description.Text)
+
+	// properties which resolve to no value are not displayed
+	require.Len(t, resolved.Properties, 1)
+	assert.Equal(t, "Name", resolved.Properties[0].Label)
+	assert.Equal(t, "Alice", resolved.Properties[0].Text)
+}
+
+func TestDisplayMappingFallbackVectors(t *testing.T) {
+	vectors, err := getTestVector(DisplayMappingFallbackVectors)
+	require.NoError(t, err)
+	var tests []struct {
+		Name       string          `json:"name"`
+		Mapping    json.RawMessage `json:"mapping"`
+		Credential map[string]any  `json:"credential"`
+		Expected   *DisplayValue   `json:"expected"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(vectors), &tests))
+
+	for _, test := range tests {
+		t.Run(test.Name, func(tt *testing.T) {
+			// mappings are the wallet rendering test vectors, by file name, or inline
+			mapping := []byte(test.Mapping)
+			var fileName string
+			if json.Unmarshal(test.Mapping, &fileName) == nil {
+				vector, err := getTestVector(fileName)
+				require.NoError(tt, err)
+				mapping = []byte(vector)
+			}
+			var dmo DisplayMappingObject
+			require.NoError(tt, json.Unmarshal(mapping, &dmo))
+
+			value, err := ResolveDisplayMappingObject(dmo, test.Credential)
+			require.NoError(tt, err)
+			assert.Equal(tt, test.Expected, value)
+		})
+	}
+}
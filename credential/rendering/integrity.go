@@ -0,0 +1,221 @@
+package rendering
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/multiformats/go-multibase"
+	"github.com/multiformats/go-multihash"
+	"github.com/pkg/errors"
+)
+
+// Images of entity styles may be bound to their content, such that wallets do not render tampered issuer branding,
+// by the Subresource Integrity metadata of their integrity property https://www.w3.org/TR/SRI/#integrity-metadata
+// or by a hashlink in the hl query parameter of their URI https://datatracker.ietf.org/doc/html/draft-sporny-hashlink
+
+const (
+	hashlinkQueryParameter = "hl"
+
+	// maxImageSize is the largest image, in bytes, fetched for rendering
+	maxImageSize = 10 << 20
+)
+
+// sriAlgorithms are the hash algorithms of integrity metadata, from weakest to strongest
+var sriAlgorithms = []struct {
+	name    string
+	newHash func() hash.Hash
+}{
+	{"sha256", sha256.New},
+	{"sha384", sha512.New384},
+	{"sha512", sha512.New},
+}
+
+// NewImageIntegrity returns the integrity metadata of an image's content, using SHA-256, for issuers to set as the
+// integrity of the images of their styles
+func NewImageIntegrity(image []byte) string {
+	digest := sha256.Sum256(image)
+	return "sha256-" + base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// HasIntegrity returns whether the image is bound to its content, by integrity metadata or a hashlink
+func (ir *ImageResource) HasIntegrity() bool {
+	if ir == nil {
+		return false
+	}
+	return ir.Integrity != "" || hashlink(ir.URI) != ""
+}
+
+// VerifyIntegrity verifies the content of an image matches its integrity metadata and its hashlink, if it has them.
+// Of integrity metadata listing multiple hashes, the content must match one using the strongest algorithm listed.
+func (ir *ImageResource) VerifyIntegrity(image []byte) error {
+	if ir == nil {
+		return errors.New("image resource cannot be empty")
+	}
+	if ir.Integrity != "" {
+		if err := verifySRI(ir.Integrity, image); err != nil {
+			return errors.Wrapf(err, "verifying integrity of image<%s>", ir.URI)
+		}
+	}
+	if hl := hashlink(ir.URI); hl != "" {
+		if err := verifyHashlink(hl, image); err != nil {
+			return errors.Wrapf(err, "verifying hashlink of image<%s>", ir.URI)
+		}
+	}
+	return nil
+}
+
+// verifySRI verifies content matches integrity metadata, a whitespace separated list of hashes, each of an algorithm
+// and the base64 digest, with options which are ignored https://www.w3.org/TR/SRI/#does-response-match-metadatalist
+func verifySRI(metadata string, content []byte) error {
+	strongest := -1
+	digests := make(map[int][]string)
+	for _, token := range strings.Fields(metadata) {
+		alg, digest, ok := strings.Cut(token, "-")
+		if !ok {
+			continue
+		}
+		digest, _, _ = strings.Cut(digest, "?")
+		for i, sriAlg := range sriAlgorithms {
+			if alg == sriAlg.name {
+				digests[i] = append(digests[i], digest)
+				if i > strongest {
+					strongest = i
+				}
+			}
+		}
+	}
+	if strongest < 0 {
+		return fmt.Errorf("integrity metadata<%s> has no supported hash", metadata)
+	}
+
+	h := sriAlgorithms[strongest].newHash()
+	h.Write(content)
+	actual := h.Sum(nil)
+	for _, digest := range digests[strongest] {
+		expected, err := base64.StdEncoding.DecodeString(digest)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(expected, actual) {
+			return nil
+		}
+	}
+	return fmt.Errorf("content does not match its %s integrity metadata", sriAlgorithms[strongest].name)
+}
+
+// hashlink returns the hashlink in the hl query parameter of a URI, if any
+func hashlink(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get(hashlinkQueryParameter)
+}
+
+// verifyHashlink verifies content matches the multibase encoded multihash of a hashlink
+func verifyHashlink(hl string, content []byte) error {
+	_, mh, err := multibase.Decode(hl)
+	if err != nil {
+		return errors.Wrap(err, "decoding hashlink")
+	}
+	decoded, err := multihash.Decode(mh)
+	if err != nil {
+		return errors.Wrap(err, "decoding hashlink multihash")
+	}
+	actual, err := multihash.Sum(content, decoded.Code, decoded.Length)
+	if err != nil {
+		return errors.Wrapf(err, "hashing content with hashlink algorithm<%s>", decoded.Name)
+	}
+	if !bytes.Equal(actual, mh) {
+		return errors.New("content does not match its hashlink")
+	}
+	return nil
+}
+
+// ImageAccess is used to retrieve the images of entity styles
+type ImageAccess interface {
+	// GetImage returns the content of the image at the given URI
+	GetImage(ctx context.Context, uri string) ([]byte, error)
+}
+
+// RemoteImageAccess is used to retrieve images from a remote location
+type RemoteImageAccess struct {
+	*http.Client
+}
+
+// NewRemoteImageAccess returns a new instance of RemoteImageAccess using the default HTTP client
+func NewRemoteImageAccess() *RemoteImageAccess {
+	return &RemoteImageAccess{Client: http.DefaultClient}
+}
+
+// GetImage returns the image at the given URI by making a GET request to it
+func (ra *RemoteImageAccess) GetImage(ctx context.Context, uri string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	resp, err := ra.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting image")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("getting image, status code: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading image")
+	}
+	if len(body) > maxImageSize {
+		return nil, fmt.Errorf("image is larger than %d bytes", maxImageSize)
+	}
+	return body, nil
+}
+
+// FetchImage retrieves an image and verifies its integrity, if it has any
+func FetchImage(ctx context.Context, access ImageAccess, image ImageResource) ([]byte, error) {
+	if access == nil {
+		return nil, errors.New("image access cannot be empty")
+	}
+	content, err := access.GetImage(ctx, image.URI)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching image<%s>", image.URI)
+	}
+	if err = image.VerifyIntegrity(content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// StyleImages are the fetched, and verified, images of entity styles
+type StyleImages struct {
+	Thumbnail []byte
+	Hero      []byte
+}
+
+// FetchStyleImages retrieves the images of entity styles, verifying the integrity of those which have any. With
+// requireIntegrity, images without integrity are not fetched, and are left out.
+func FetchStyleImages(ctx context.Context, access ImageAccess, styles EntityStyleDescriptor, requireIntegrity bool) (*StyleImages, error) {
+	var images StyleImages
+	var err error
+	if styles.Thumbnail != nil && (!requireIntegrity || styles.Thumbnail.HasIntegrity()) {
+		if images.Thumbnail, err = FetchImage(ctx, access, *styles.Thumbnail); err != nil {
+			return nil, errors.Wrap(err, "fetching thumbnail")
+		}
+	}
+	if styles.Hero != nil && (!requireIntegrity || styles.Hero.HasIntegrity()) {
+		if images.Hero, err = FetchImage(ctx, access, *styles.Hero); err != nil {
+			return nil, errors.Wrap(err, "fetching hero")
+		}
+	}
+	return &images, nil
+}
@@ -0,0 +1,97 @@
+package rendering
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"testing"
+
+	"github.com/multiformats/go-multibase"
+	"github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestImageIntegrity(t *testing.T) {
+	image := []byte("issuer logo")
+	tampered := []byte("tampered logo")
+
+	t.Run("integrity metadata", func(tt *testing.T) {
+		resource := ImageResource{URI: "https://example.com/logo.png", Integrity: NewImageIntegrity(image)}
+		assert.True(tt, resource.HasIntegrity())
+		assert.NoError(tt, resource.VerifyIntegrity(image))
+		assert.ErrorContains(tt, resource.VerifyIntegrity(tampered), "content does not match its sha256 integrity metadata")
+	})
+
+	t.Run("strongest algorithm of integrity metadata is used", func(tt *testing.T) {
+		digest := sha512.Sum512(image)
+		sha512Integrity := "sha512-" + base64.StdEncoding.EncodeToString(digest[:])
+
+		// a matching weaker hash does not stand in for the strongest
+		resource := ImageResource{URI: "https://example.com/logo.png", Integrity: NewImageIntegrity(image) + " sha512-AAAA"}
+		assert.Error(tt, resource.VerifyIntegrity(image))
+
+		resource.Integrity = NewImageIntegrity(tampered) + " " + sha512Integrity + "?ct=image/png"
+		assert.NoError(tt, resource.VerifyIntegrity(image))
+
+		resource.Integrity = "md5-AAAA"
+		assert.ErrorContains(tt, resource.VerifyIntegrity(image), "has no supported hash")
+	})
+
+	t.Run("hashlink", func(tt *testing.T) {
+		mh, err := multihash.Sum(image, multihash.SHA2_256, -1)
+		require.NoError(tt, err)
+		hl, err := multibase.Encode(multibase.Base58BTC, mh)
+		require.NoError(tt, err)
+
+		resource := ImageResource{URI: "https://example.com/logo.png?hl=" + hl}
+		assert.True(tt, resource.HasIntegrity())
+		assert.NoError(tt, resource.VerifyIntegrity(image))
+		assert.ErrorContains(tt, resource.VerifyIntegrity(tampered), "content does not match its hashlink")
+	})
+
+	t.Run("images without integrity are not verified", func(tt *testing.T) {
+		resource := ImageResource{URI: "https://example.com/logo.png"}
+		assert.False(tt, resource.HasIntegrity())
+		assert.NoError(tt, resource.VerifyIntegrity(tampered))
+	})
+}
+
+func TestFetchStyleImages(t *testing.T) {
+	thumbnail := []byte("issuer logo")
+	hero := []byte("issuer hero")
+	styles := EntityStyleDescriptor{
+		Thumbnail: &ImageResource{URI: "https://example.com/logo.png", Integrity: NewImageIntegrity(thumbnail)},
+		Hero:      &ImageResource{URI: "https://example.com/hero.png"},
+	}
+
+	t.Run("images are fetched and verified", func(tt *testing.T) {
+		gock.New("https://example.com").Get("/logo.png").Reply(200).BodyString(string(thumbnail))
+		gock.New("https://example.com").Get("/hero.png").Reply(200).BodyString(string(hero))
+		defer gock.Off()
+
+		images, err := FetchStyleImages(context.Background(), NewRemoteImageAccess(), styles, false)
+		require.NoError(tt, err)
+		assert.Equal(tt, thumbnail, images.Thumbnail)
+		assert.Equal(tt, hero, images.Hero)
+	})
+
+	t.Run("images without integrity are left out when required", func(tt *testing.T) {
+		gock.New("https://example.com").Get("/logo.png").Reply(200).BodyString(string(thumbnail))
+		defer gock.Off()
+
+		images, err := FetchStyleImages(context.Background(), NewRemoteImageAccess(), styles, true)
+		require.NoError(tt, err)
+		assert.Equal(tt, thumbnail, images.Thumbnail)
+		assert.Nil(tt, images.Hero)
+	})
+
+	t.Run("tampered images are rejected", func(tt *testing.T) {
+		gock.New("https://example.com").Get("/logo.png").Reply(200).BodyString("tampered logo")
+		defer gock.Off()
+
+		_, err := FetchStyleImages(context.Background(), NewRemoteImageAccess(), styles, true)
+		assert.ErrorContains(tt, err, "verifying integrity of image<https://example.com/logo.png>")
+	})
+}
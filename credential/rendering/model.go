@@ -28,6 +28,9 @@ type ImageResource struct {
 	URI string `json:"uri" validate:"required"`
 	// Describes the alternate text for a logo image
 	Alt string `json:"alt,omitempty"`
+	// Subresource Integrity metadata binding the image to its content, such as "sha256-<base64 digest>"
+	// https://www.w3.org/TR/SRI/#integrity-metadata
+	Integrity string `json:"integrity,omitempty"`
 }
 
 type ColorResource struct {
@@ -14,6 +14,7 @@ const (
 	DisplayMappingTextVector2        string = "wr-display-mapping-text-example-2.json"
 	LabeledDisplayMappingPathVector1 string = "wr-labeled-display-mapping-path-example-1.json"
 	LabeledDisplayMappingTextVector2 string = "wr-labeled-display-mapping-text-example-2.json"
+	DisplayMappingFallbackVectors    string = "wr-display-mapping-fallback-vectors.json"
 )
 
 var (
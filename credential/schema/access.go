@@ -1,12 +1,16 @@
 package schema
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/util"
 )
 
 type VCJSONSchemaAccess interface {
@@ -39,21 +43,32 @@ func (ra *RemoteAccess) GetVCJSONSchema(ctx context.Context, t VCJSONSchemaType,
 	if ra.baseURL != nil {
 		url = *ra.baseURL + id
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "creating request")
-	}
-	resp, err := ra.Do(req)
+	body, err := getSchema(ctx, ra.Client, url)
 	if err != nil {
-		return nil, errors.Wrap(err, "getting schema")
+		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Errorf("getting schema, status code: %d", resp.StatusCode)
+	return ParseVCJSONSchema(t, body)
+}
+
+// ParseVCJSONSchema parses a vc json schema of the given type. A JsonSchemaCredential may be a JSON credential or a
+// credential secured as a JWT, in which case it is returned as its JSON representation.
+// NOTE: this method does not verify the signature of a JsonSchemaCredential
+func ParseVCJSONSchema(t VCJSONSchemaType, schemaBytes []byte) (VCJSONSchema, error) {
+	trimmed := bytes.TrimSpace(schemaBytes)
+	if t == JSONSchemaCredentialType && len(trimmed) > 0 && trimmed[0] != '{' {
+		_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(string(trimmed))
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing schema credential from JWT")
+		}
+		credJSON, err := util.ToJSONMap(cred)
+		if err != nil {
+			return nil, errors.Wrap(err, "converting schema credential to JSON")
+		}
+		return credJSON, nil
 	}
 
 	var schema VCJSONSchema
-	if err = json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+	if err := json.Unmarshal(trimmed, &schema); err != nil {
 		return nil, errors.Wrap(err, "decoding schema")
 	}
 	return schema, nil
@@ -5,6 +5,9 @@ import (
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -74,4 +77,31 @@ func TestRemoteAccess(t *testing.T) {
 		err = ValidateCredentialAgainstSchema(remoteAccess, vc)
 		assert.NoError(t, err)
 	})
+
+	t.Run("validate credential against JWT JsonSchemaCredential", func(t *testing.T) {
+		var schemaVC credential.VerifiableCredential
+		err := json.Unmarshal([]byte(schemaCred), &schemaVC)
+		require.NoError(t, err)
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(t, err)
+		signer, err := jwx.NewJWXSigner("https://example.com/issuers/14", nil, privKey)
+		require.NoError(t, err)
+		token, err := integrity.SignVerifiableCredentialJWT(*signer, schemaVC)
+		require.NoError(t, err)
+
+		gock.New("https://example.com/credentials").
+			Get("/3734").
+			Reply(200).BodyString(string(token))
+		defer gock.Off()
+
+		cred, err := getTestVector(jsonSchemaCredentialCredential1)
+		assert.NoError(t, err)
+
+		var vc credential.VerifiableCredential
+		err = json.Unmarshal([]byte(cred), &vc)
+		assert.NoError(t, err)
+
+		err = ValidateCredentialAgainstSchema(remoteAccess, vc)
+		assert.NoError(t, err)
+	})
 }
@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/schema"
+)
+
+// GenerateJSONSchemaFromType generates a VC JSON Schema https://www.w3.org/TR/vc-json-schema/#jsonschema for credentials
+// whose credentialSubject is the JSON encoding of the given Go type, annotated as described by schema.GenerateJSONSchema
+func GenerateJSONSchemaFromType(t reflect.Type, id, name, description string) (JSONSchema, error) {
+	subjectSchema, err := schema.GenerateJSONSchema(t)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating credential subject schema")
+	}
+	return newCredentialJSONSchema(subjectSchema, id, name, description)
+}
+
+// GenerateJSONSchemaFromCredential generates a VC JSON Schema https://www.w3.org/TR/vc-json-schema/#jsonschema from a
+// sample credential, whose credentialSubject's properties are all required, as inferred by schema.InferJSONSchema
+func GenerateJSONSchemaFromCredential(cred credential.VerifiableCredential, id, name, description string) (JSONSchema, error) {
+	if cred.IsEmpty() {
+		return nil, errors.New("credential is empty")
+	}
+	if len(cred.AdditionalCredentialSubjects) > 0 {
+		return nil, errors.New("cannot infer a schema from a credential with multiple subjects")
+	}
+	subjectSchema, err := schema.InferJSONSchema(cred.CredentialSubject)
+	if err != nil {
+		return nil, errors.Wrap(err, "inferring credential subject schema")
+	}
+	return newCredentialJSONSchema(subjectSchema, id, name, description)
+}
+
+// newCredentialJSONSchema wraps a credentialSubject's schema in a schema for the credential, identified by id
+func newCredentialJSONSchema(subjectSchema map[string]any, id, name, description string) (JSONSchema, error) {
+	if !isValidURI(id) {
+		return nil, fmt.Errorf("schema ID<%s> is not a valid URI", id)
+	}
+	delete(subjectSchema, JSONSchemaSchemaProperty)
+	s := JSONSchema{
+		JSONSchemaIDProperty:     id,
+		JSONSchemaSchemaProperty: Draft202012.String(),
+		"type":                   "object",
+		"properties": map[string]any{
+			"credentialSubject": subjectSchema,
+		},
+		"required": []any{"credentialSubject"},
+	}
+	if name != "" {
+		s[JSONSchemaNameProperty] = name
+	}
+	if description != "" {
+		s[JSONSchemaDescriptionProperty] = description
+	}
+	return s, nil
+}
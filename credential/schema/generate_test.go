@@ -0,0 +1,68 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type emailSubject struct {
+	ID           string `json:"id"`
+	EmailAddress string `json:"emailAddress" jsonschema:"format=email"`
+	Nickname     string `json:"nickname,omitempty" jsonschema:"maxLength=16"`
+}
+
+func TestGenerateJSONSchemaFromType(t *testing.T) {
+	t.Run("generated schema validates credentials", func(tt *testing.T) {
+		s, err := GenerateJSONSchemaFromType(reflect.TypeOf(emailSubject{}), "https://example.com/schemas/email.json", "Email", "An email address")
+		require.NoError(tt, err)
+		assert.Equal(tt, "https://example.com/schemas/email.json", s.ID())
+		assert.Equal(tt, Draft202012.String(), s.Schema())
+		assert.Equal(tt, "Email", s.Name())
+		assert.Equal(tt, "An email address", s.Description())
+
+		cred := getTestJSONSchemaCredential()
+		assert.NoError(tt, IsCredentialValidForJSONSchema(cred, VCJSONSchema(s), JSONSchemaType))
+
+		cred.CredentialSubject["nickname"] = "a nickname that is too long"
+		assert.ErrorContains(tt, IsCredentialValidForJSONSchema(cred, VCJSONSchema(s), JSONSchemaType), "credential not valid for schema")
+
+		cred = getTestJSONSchemaCredential()
+		delete(cred.CredentialSubject, "emailAddress")
+		assert.ErrorContains(tt, IsCredentialValidForJSONSchema(cred, VCJSONSchema(s), JSONSchemaType), "credential not valid for schema")
+	})
+
+	t.Run("bad input", func(tt *testing.T) {
+		_, err := GenerateJSONSchemaFromType(reflect.TypeOf(emailSubject{}), "not a uri", "", "")
+		assert.ErrorContains(tt, err, "is not a valid URI")
+
+		_, err = GenerateJSONSchemaFromType(nil, "https://example.com/schemas/email.json", "", "")
+		assert.ErrorContains(tt, err, "generating credential subject schema")
+	})
+}
+
+func TestGenerateJSONSchemaFromCredential(t *testing.T) {
+	t.Run("inferred schema validates the sample", func(tt *testing.T) {
+		cred := getTestJSONSchemaCredential()
+		s, err := GenerateJSONSchemaFromCredential(cred, "https://example.com/schemas/email.json", "", "")
+		require.NoError(tt, err)
+		assert.Empty(tt, s.Name())
+
+		subjectSchema := s["properties"].(map[string]any)["credentialSubject"].(map[string]any)
+		assert.Equal(tt, []string{"emailAddress", "id"}, subjectSchema["required"])
+		assert.NotContains(tt, subjectSchema, "$schema")
+		assert.NoError(tt, IsCredentialValidForJSONSchema(cred, VCJSONSchema(s), JSONSchemaType))
+
+		cred.CredentialSubject["emailAddress"] = 42
+		assert.Error(tt, IsCredentialValidForJSONSchema(cred, VCJSONSchema(s), JSONSchemaType))
+	})
+
+	t.Run("multiple subjects", func(tt *testing.T) {
+		cred := getTestJSONSchemaCredential()
+		cred.AdditionalCredentialSubjects = append(cred.AdditionalCredentialSubjects, map[string]any{"id": "did:example:other"})
+		_, err := GenerateJSONSchemaFromCredential(cred, "https://example.com/schemas/email.json", "", "")
+		assert.ErrorContains(tt, err, "multiple subjects")
+	})
+}
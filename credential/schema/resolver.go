@@ -0,0 +1,302 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+const (
+	// DIDURLServiceParameter and DIDURLRelativeRefParameter are the DID URL query parameters used to dereference a
+	// schema hosted at a service endpoint https://www.w3.org/TR/did-core/#did-parameters
+	DIDURLServiceParameter     = "service"
+	DIDURLRelativeRefParameter = "relativeRef"
+)
+
+// SchemaResolver resolves a schema, or a credential securing a schema, from its URI. Implementations return the raw
+// schema so that it can be parsed according to how it is referenced, e.g. as a JsonSchema or JsonSchemaCredential.
+type SchemaResolver interface {
+	// ResolveSchema returns the schema identified by the given URI
+	ResolveSchema(ctx context.Context, uri string) ([]byte, error)
+}
+
+// HTTPSchemaResolver resolves schemas by making a GET request to their URI
+type HTTPSchemaResolver struct {
+	*http.Client
+}
+
+var _ SchemaResolver = (*HTTPSchemaResolver)(nil)
+
+// NewHTTPSchemaResolver returns a new instance of HTTPSchemaResolver using the default HTTP client
+func NewHTTPSchemaResolver() *HTTPSchemaResolver {
+	return &HTTPSchemaResolver{Client: http.DefaultClient}
+}
+
+// ResolveSchema returns the schema at the given URI by making a GET request to it
+func (hr *HTTPSchemaResolver) ResolveSchema(ctx context.Context, uri string) ([]byte, error) {
+	return getSchema(ctx, hr.Client, uri)
+}
+
+// DIDSchemaResolver resolves schemas identified by DID URLs. The DID is resolved and the URL is dereferenced to one
+// of its services, either using the `service` and `relativeRef` parameters (did:example:123?service=schemas&relativeRef=/email.json)
+// or a fragment (did:example:123#email-schema). The schema is then fetched from the service endpoint.
+type DIDSchemaResolver struct {
+	resolver resolution.Resolver
+	client   *http.Client
+}
+
+var _ SchemaResolver = (*DIDSchemaResolver)(nil)
+
+// NewDIDSchemaResolver returns a new instance of DIDSchemaResolver using the given DID resolver and the default
+// HTTP client
+func NewDIDSchemaResolver(r resolution.Resolver) (*DIDSchemaResolver, error) {
+	if r == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	return &DIDSchemaResolver{resolver: r, client: http.DefaultClient}, nil
+}
+
+// ResolveSchema dereferences the given DID URL to a service endpoint and returns the schema found there
+func (dr *DIDSchemaResolver) ResolveSchema(ctx context.Context, uri string) ([]byte, error) {
+	endpoint, err := dr.dereference(ctx, uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "dereferencing DID URL<%s>", uri)
+	}
+	return getSchema(ctx, dr.client, endpoint)
+}
+
+func (dr *DIDSchemaResolver) dereference(ctx context.Context, didURL string) (string, error) {
+	if !strings.HasPrefix(didURL, "did:") {
+		return "", fmt.Errorf("not a DID URL: %s", didURL)
+	}
+	id, fragment, _ := strings.Cut(didURL, "#")
+	id, rawQuery, _ := strings.Cut(id, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing DID URL query")
+	}
+	serviceID := query.Get(DIDURLServiceParameter)
+	if serviceID == "" {
+		serviceID = fragment
+	}
+	if serviceID == "" {
+		return "", errors.New("DID URL does not reference a service")
+	}
+
+	resolved, err := dr.resolver.Resolve(ctx, id)
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving DID<%s>", id)
+	}
+	service := findService(resolved.Document, serviceID)
+	if service == nil {
+		return "", fmt.Errorf("service<%s> not found in DID<%s>", serviceID, id)
+	}
+	endpoint, ok := service.ServiceEndpoint.(string)
+	if !ok {
+		// only the first endpoint of a set is used
+		if endpoints, isSet := service.ServiceEndpoint.([]any); isSet && len(endpoints) > 0 {
+			endpoint, ok = endpoints[0].(string)
+		}
+	}
+	if !ok || endpoint == "" {
+		return "", fmt.Errorf("service<%s> does not have a URI service endpoint", serviceID)
+	}
+	return endpoint + query.Get(DIDURLRelativeRefParameter), nil
+}
+
+// findService finds a service by its ID, which may be fully qualified or just the fragment
+func findService(doc did.Document, serviceID string) *did.Service {
+	fragment := serviceFragment(serviceID)
+	for i, service := range doc.Services {
+		if service.ID == serviceID || serviceFragment(service.ID) == fragment {
+			return &doc.Services[i]
+		}
+	}
+	return nil
+}
+
+func serviceFragment(serviceID string) string {
+	if _, fragment, ok := strings.Cut(serviceID, "#"); ok {
+		return fragment
+	}
+	return serviceID
+}
+
+// LocalSchemaResolver resolves schemas from an in-memory map of schema URI to schema
+type LocalSchemaResolver map[string][]byte
+
+var _ SchemaResolver = (LocalSchemaResolver)(nil)
+
+// ResolveSchema returns the schema with the given URI
+func (lr LocalSchemaResolver) ResolveSchema(_ context.Context, uri string) ([]byte, error) {
+	schema, ok := lr[uri]
+	if !ok {
+		return nil, fmt.Errorf("schema<%s> not found", uri)
+	}
+	return schema, nil
+}
+
+// MultiSchemaResolver resolves schemas using each of its resolvers in order, returning the first schema found
+type MultiSchemaResolver []SchemaResolver
+
+var _ SchemaResolver = (MultiSchemaResolver)(nil)
+
+// ResolveSchema returns the schema with the given URI from the first resolver able to resolve it
+func (mr MultiSchemaResolver) ResolveSchema(ctx context.Context, uri string) ([]byte, error) {
+	if len(mr) == 0 {
+		return nil, errors.New("no schema resolvers provided")
+	}
+	var errs []string
+	for _, r := range mr {
+		schema, err := r.ResolveSchema(ctx, uri)
+		if err == nil {
+			return schema, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("resolving schema<%s>: %s", uri, strings.Join(errs, "; "))
+}
+
+// CachingSchemaResolver caches the schemas resolved by another resolver. Failed resolutions are not cached.
+type CachingSchemaResolver struct {
+	resolver SchemaResolver
+	ttl      time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]cachedSchema
+}
+
+type cachedSchema struct {
+	schema  []byte
+	expires time.Time
+}
+
+var _ SchemaResolver = (*CachingSchemaResolver)(nil)
+
+// NewCachingSchemaResolver returns a resolver which caches schemas resolved by the given resolver for the given
+// duration. A ttl of zero caches schemas indefinitely.
+func NewCachingSchemaResolver(r SchemaResolver, ttl time.Duration) (*CachingSchemaResolver, error) {
+	if r == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	if ttl < 0 {
+		return nil, fmt.Errorf("invalid ttl: %s", ttl)
+	}
+	return &CachingSchemaResolver{resolver: r, ttl: ttl, cache: make(map[string]cachedSchema)}, nil
+}
+
+// ResolveSchema returns the cached schema with the given URI, resolving and caching it if it is not cached or has
+// expired
+func (cr *CachingSchemaResolver) ResolveSchema(ctx context.Context, uri string) ([]byte, error) {
+	cr.mu.RLock()
+	cached, ok := cr.cache[uri]
+	cr.mu.RUnlock()
+	if ok && (cached.expires.IsZero() || time.Now().Before(cached.expires)) {
+		return cached.schema, nil
+	}
+
+	schema, err := cr.resolver.ResolveSchema(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	cached = cachedSchema{schema: schema}
+	if cr.ttl > 0 {
+		cached.expires = time.Now().Add(cr.ttl)
+	}
+	cr.mu.Lock()
+	cr.cache[uri] = cached
+	cr.mu.Unlock()
+	return schema, nil
+}
+
+// Evict removes the schema with the given URI from the cache
+func (cr *CachingSchemaResolver) Evict(uri string) {
+	cr.mu.Lock()
+	delete(cr.cache, uri)
+	cr.mu.Unlock()
+}
+
+// ResolverAccess is a VCJSONSchemaAccess which retrieves schemas using a SchemaResolver
+type ResolverAccess struct {
+	SchemaResolver
+}
+
+var _ VCJSONSchemaAccess = (*ResolverAccess)(nil)
+
+// NewResolverAccess returns a new instance of ResolverAccess using the given schema resolver
+func NewResolverAccess(r SchemaResolver) (*ResolverAccess, error) {
+	if r == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	return &ResolverAccess{SchemaResolver: r}, nil
+}
+
+// GetVCJSONSchema returns a vc json schema for the given ID and its type using the schema resolver
+func (ra *ResolverAccess) GetVCJSONSchema(ctx context.Context, t VCJSONSchemaType, id string) (VCJSONSchema, error) {
+	if !IsSupportedVCJSONSchemaType(t.String()) {
+		return nil, fmt.Errorf("credential schema type<%s> is not supported", t)
+	}
+	schemaBytes, err := ra.ResolveSchema(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting schema")
+	}
+	return ParseVCJSONSchema(t, schemaBytes)
+}
+
+// ResolveJSONSchema resolves the JSON Schema with the given URI. If the URI references a JsonSchemaCredential, the
+// JSON Schema is taken from its credential subject.
+// NOTE: this method does not verify the signature of a JsonSchemaCredential
+func ResolveJSONSchema(ctx context.Context, r SchemaResolver, uri string) (JSONSchema, error) {
+	if r == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	schemaBytes, err := r.ResolveSchema(ctx, uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving schema<%s>", uri)
+	}
+	vcs, err := ParseVCJSONSchema(JSONSchemaCredentialType, schemaBytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing schema<%s>", uri)
+	}
+	if _, isCredential := vcs["credentialSubject"]; !isCredential {
+		return JSONSchema(vcs), nil
+	}
+	s, _, err := parseJSONSchemaCredential(vcs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing schema credential<%s>", uri)
+	}
+	return s, nil
+}
+
+func getSchema(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting schema")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("getting schema, status code: %d", resp.StatusCode)
+	}
+	schema, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading schema")
+	}
+	return schema, nil
+}
@@ -0,0 +1,187 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestSchemaResolvers(t *testing.T) {
+	schema, err := getTestVector(jsonSchemaSchema1)
+	require.NoError(t, err)
+	schemaURI := "https://example.com/schemas/email.json"
+
+	t.Run("http", func(tt *testing.T) {
+		gock.New("https://example.com/schemas").
+			Get("/email.json").
+			Reply(200).BodyString(schema)
+		defer gock.Off()
+
+		resolved, err := NewHTTPSchemaResolver().ResolveSchema(context.Background(), schemaURI)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, schema, string(resolved))
+	})
+
+	t.Run("local", func(tt *testing.T) {
+		local := LocalSchemaResolver{schemaURI: []byte(schema)}
+		resolved, err := local.ResolveSchema(context.Background(), schemaURI)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, schema, string(resolved))
+
+		_, err = local.ResolveSchema(context.Background(), "https://example.com/schemas/unknown.json")
+		assert.ErrorContains(tt, err, "schema<https://example.com/schemas/unknown.json> not found")
+	})
+
+	t.Run("did url", func(tt *testing.T) {
+		didResolver := testDIDResolver{
+			"did:example:123": {
+				ID: "did:example:123",
+				Services: []did.Service{
+					{ID: "did:example:123#schemas", Type: "LinkedSchemas", ServiceEndpoint: "https://example.com/schemas"},
+					{ID: "#email-schema", Type: "LinkedSchemas", ServiceEndpoint: []any{schemaURI}},
+				},
+			},
+		}
+		resolver, err := NewDIDSchemaResolver(didResolver)
+		require.NoError(tt, err)
+
+		gock.New("https://example.com/schemas").
+			Get("/email.json").
+			Times(2).
+			Reply(200).BodyString(schema)
+		defer gock.Off()
+
+		resolved, err := resolver.ResolveSchema(context.Background(), "did:example:123?service=schemas&relativeRef=%2Femail.json")
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, schema, string(resolved))
+
+		resolved, err = resolver.ResolveSchema(context.Background(), "did:example:123#email-schema")
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, schema, string(resolved))
+
+		_, err = resolver.ResolveSchema(context.Background(), "did:example:123#unknown")
+		assert.ErrorContains(tt, err, "service<unknown> not found in DID<did:example:123>")
+
+		_, err = resolver.ResolveSchema(context.Background(), "did:example:123")
+		assert.ErrorContains(tt, err, "DID URL does not reference a service")
+
+		_, err = resolver.ResolveSchema(context.Background(), schemaURI)
+		assert.ErrorContains(tt, err, "not a DID URL")
+	})
+
+	t.Run("multi", func(tt *testing.T) {
+		multi := MultiSchemaResolver{LocalSchemaResolver{}, LocalSchemaResolver{schemaURI: []byte(schema)}}
+		resolved, err := multi.ResolveSchema(context.Background(), schemaURI)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, schema, string(resolved))
+
+		_, err = MultiSchemaResolver{LocalSchemaResolver{}}.ResolveSchema(context.Background(), "unknown")
+		assert.ErrorContains(tt, err, "resolving schema<unknown>: schema<unknown> not found")
+	})
+
+	t.Run("caching", func(tt *testing.T) {
+		counting := &countingSchemaResolver{SchemaResolver: LocalSchemaResolver{schemaURI: []byte(schema)}}
+		cached, err := NewCachingSchemaResolver(counting, 0)
+		require.NoError(tt, err)
+
+		for i := 0; i < 3; i++ {
+			resolved, err := cached.ResolveSchema(context.Background(), schemaURI)
+			assert.NoError(tt, err)
+			assert.JSONEq(tt, schema, string(resolved))
+		}
+		assert.Equal(tt, 1, counting.calls)
+
+		cached.Evict(schemaURI)
+		_, err = cached.ResolveSchema(context.Background(), schemaURI)
+		assert.NoError(tt, err)
+		assert.Equal(tt, 2, counting.calls)
+
+		// failures are not cached
+		_, err = cached.ResolveSchema(context.Background(), "unknown")
+		assert.Error(tt, err)
+		_, err = cached.ResolveSchema(context.Background(), "unknown")
+		assert.Error(tt, err)
+		assert.Equal(tt, 4, counting.calls)
+
+		// expired schemas are resolved again
+		expiring, err := NewCachingSchemaResolver(counting, time.Nanosecond)
+		require.NoError(tt, err)
+		_, err = expiring.ResolveSchema(context.Background(), schemaURI)
+		assert.NoError(tt, err)
+		time.Sleep(time.Millisecond)
+		_, err = expiring.ResolveSchema(context.Background(), schemaURI)
+		assert.NoError(tt, err)
+		assert.Equal(tt, 6, counting.calls)
+
+		_, err = NewCachingSchemaResolver(counting, -time.Second)
+		assert.ErrorContains(tt, err, "invalid ttl")
+	})
+}
+
+func TestResolverAccess(t *testing.T) {
+	schema, err := getTestVector(jsonSchemaSchema1)
+	require.NoError(t, err)
+	schemaCred, err := getTestVector(jsonSchemaCredentialSchema1)
+	require.NoError(t, err)
+	resolver := LocalSchemaResolver{
+		"https://example.com/schemas/email.json": []byte(schema),
+		"https://example.com/credentials/3734":   []byte(schemaCred),
+	}
+	access, err := NewResolverAccess(resolver)
+	require.NoError(t, err)
+
+	for _, vector := range []string{jsonSchemaCredential1, jsonSchemaCredentialCredential1} {
+		cred, err := getTestVector(vector)
+		require.NoError(t, err)
+		var vc credential.VerifiableCredential
+		require.NoError(t, json.Unmarshal([]byte(cred), &vc))
+
+		err = ValidateCredentialAgainstSchema(access, vc)
+		assert.NoError(t, err)
+	}
+
+	t.Run("resolve json schema", func(tt *testing.T) {
+		jsonSchema, err := ResolveJSONSchema(context.Background(), resolver, "https://example.com/schemas/email.json")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://example.com/schemas/email.json", jsonSchema.ID())
+
+		// the json schema is taken from a JsonSchemaCredential
+		jsonSchema, err = ResolveJSONSchema(context.Background(), resolver, "https://example.com/credentials/3734")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://example.com/schemas/email-credential-schema.json", jsonSchema.ID())
+	})
+}
+
+type countingSchemaResolver struct {
+	SchemaResolver
+	calls int
+}
+
+func (c *countingSchemaResolver) ResolveSchema(ctx context.Context, uri string) ([]byte, error) {
+	c.calls++
+	return c.SchemaResolver.ResolveSchema(ctx, uri)
+}
+
+type testDIDResolver map[string]did.Document
+
+func (r testDIDResolver) Resolve(_ context.Context, id string, _ ...resolution.Option) (*resolution.Result, error) {
+	doc, ok := r[id]
+	if !ok {
+		return nil, fmt.Errorf("did<%s> not found", id)
+	}
+	return &resolution.Result{Document: doc}, nil
+}
+
+func (testDIDResolver) Methods() []did.Method {
+	return []did.Method{"example"}
+}
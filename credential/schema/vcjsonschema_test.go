@@ -49,6 +49,46 @@ func TestValidateCredentialAgainstSchema(t *testing.T) {
 	})
 }
 
+func TestIsCredentialValidForJSONSchema_Draft202012(t *testing.T) {
+	schema := getTestJSONSchemaSchema()
+	schema["$schema"] = Draft202012.String()
+	schema["properties"] = map[string]any{
+		"credentialSubject": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"emailAddress": map[string]any{"type": "string"},
+				"aliases": map[string]any{
+					"type":        "array",
+					"prefixItems": []any{map[string]any{"type": "string"}, map[string]any{"type": "integer"}},
+				},
+			},
+			"dependentRequired": map[string]any{"aliases": []string{"emailAddress"}},
+		},
+	}
+
+	t.Run("valid credential", func(t *testing.T) {
+		cred := getTestJSONSchemaCredential()
+		cred.CredentialSubject["aliases"] = []any{"grandma", 1}
+		err := IsCredentialValidForJSONSchema(cred, schema, JSONSchemaType)
+		assert.NoError(t, err)
+	})
+
+	t.Run("prefixItems is enforced", func(t *testing.T) {
+		cred := getTestJSONSchemaCredential()
+		cred.CredentialSubject["aliases"] = []any{1, "grandma"}
+		err := IsCredentialValidForJSONSchema(cred, schema, JSONSchemaType)
+		assert.ErrorContains(t, err, "credential not valid for schema")
+	})
+
+	t.Run("dependentRequired is enforced", func(t *testing.T) {
+		cred := getTestJSONSchemaCredential()
+		delete(cred.CredentialSubject, "emailAddress")
+		cred.CredentialSubject["aliases"] = []any{"grandma", 1}
+		err := IsCredentialValidForJSONSchema(cred, schema, JSONSchemaType)
+		assert.ErrorContains(t, err, "emailAddress")
+	})
+}
+
 func TestIsCredentialValidForJSONSchema_JsonSchema(t *testing.T) {
 	t.Run("ID - The value MUST be a URL that identifies the schema associated with the verifiable credential.", func(t *testing.T) {
 		t.Run("valid id", func(t *testing.T) {
@@ -0,0 +1,54 @@
+package status
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// maxStatusListCredentialSize bounds the size in bytes of a fetched status list credential, which leaves room for a
+// base64 encoded list of maxStatusListLength bytes that does not compress, itself encoded in a JWT
+const maxStatusListCredentialSize = 32 * KB * KB
+
+// StatusListAccess is used to retrieve status list credentials referenced by credentialStatus entries
+type StatusListAccess interface {
+	// GetStatusListCredential returns the status list credential at the given URL, either as a JSON credential with
+	// an embedded proof or as a JWT
+	GetStatusListCredential(ctx context.Context, url string) ([]byte, error)
+}
+
+// RemoteAccess is used to retrieve a status list credential from a remote location
+type RemoteAccess struct {
+	*http.Client
+}
+
+// NewRemoteAccess returns a new instance of RemoteAccess using the default HTTP client
+func NewRemoteAccess() *RemoteAccess {
+	return &RemoteAccess{Client: http.DefaultClient}
+}
+
+// GetStatusListCredential returns the status list credential at the given URL by making a GET request to it
+func (ra *RemoteAccess) GetStatusListCredential(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	resp, err := ra.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting status list credential")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("getting status list credential, status code: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxStatusListCredentialSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading status list credential")
+	}
+	if len(body) > maxStatusListCredentialSize {
+		return nil, errors.Errorf("status list credential exceeds the maximum size of %d bytes", maxStatusListCredentialSize)
+	}
+	return body, nil
+}
@@ -0,0 +1,476 @@
+package status
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// https://www.w3.org/TR/vc-bitstring-status-list/
+
+const (
+	StatusRefresh StatusPurpose = "refresh"
+	StatusMessage StatusPurpose = "message"
+
+	BitstringStatusListCredentialType string = "BitstringStatusListCredential"
+	BitstringStatusListEntryType      string = "BitstringStatusListEntry"
+	BitstringStatusListType           string = "BitstringStatusList"
+
+	// VerifiableCredentialsV2Context defines the Bitstring Status List terms
+	VerifiableCredentialsV2Context string = "https://www.w3.org/ns/credentials/v2"
+
+	// BitstringStatusListMinimumBits is the minimum length of a status list bitstring, which provides herd privacy
+	BitstringStatusListMinimumBits = 16 * KB * 8
+
+	// multibaseBase64URL is the multibase prefix of the base64url (no padding) encoding used for encoded lists
+	multibaseBase64URL = 'u'
+)
+
+// BitstringStatusMessage describes the meaning of a status value, for entries with a statusSize greater than 1 or a
+// status purpose of message https://www.w3.org/TR/vc-bitstring-status-list/#bitstringstatuslistentry
+type BitstringStatusMessage struct {
+	// Status is the status value as a hex string prefixed by 0x, e.g. "0x2"
+	Status  string `json:"status" validate:"required"`
+	Message string `json:"message" validate:"required"`
+}
+
+// BitstringStatusListEntry the representation within a credential that is associated with a bitstring status list
+// https://www.w3.org/TR/vc-bitstring-status-list/#bitstringstatuslistentry
+type BitstringStatusListEntry struct {
+	ID                   string        `json:"id,omitempty"`
+	Type                 string        `json:"type" validate:"required"`
+	StatusPurpose        StatusPurpose `json:"statusPurpose" validate:"required"`
+	StatusListIndex      string        `json:"statusListIndex" validate:"required"`
+	StatusListCredential string        `json:"statusListCredential" validate:"required"`
+	// StatusSize is the number of bits of the status, which is 1 when not present
+	StatusSize      int                      `json:"statusSize,omitempty"`
+	StatusMessage   []BitstringStatusMessage `json:"statusMessage,omitempty" validate:"omitempty,dive"`
+	StatusReference any                      `json:"statusReference,omitempty"`
+}
+
+// GetStatusSize returns the number of bits of the entry's status
+func (e BitstringStatusListEntry) GetStatusSize() int {
+	if e.StatusSize == 0 {
+		return 1
+	}
+	return e.StatusSize
+}
+
+// GetStatusMessage returns the message describing a status value, if the entry has one
+func (e BitstringStatusListEntry) GetStatusMessage(status uint64) string {
+	for _, message := range e.StatusMessage {
+		value, err := strconv.ParseUint(strings.TrimPrefix(message.Status, "0x"), 16, 64)
+		if err == nil && value == status {
+			return message.Message
+		}
+	}
+	return ""
+}
+
+// BitstringStatusListCredential the credential subject value of a bitstring status list credential
+// https://www.w3.org/TR/vc-bitstring-status-list/#bitstringstatuslistcredential
+type BitstringStatusListCredential struct {
+	ID   string `json:"id" validate:"required"`
+	Type string `json:"type" validate:"required"`
+	// StatusPurpose is either a single status purpose, or a set of status purposes the list is used for
+	StatusPurpose any    `json:"statusPurpose" validate:"required"`
+	EncodedList   string `json:"encodedList" validate:"required"`
+	// TTL is the number of milliseconds for which the list may be cached
+	TTL int `json:"ttl,omitempty"`
+}
+
+// GetStatusPurposes returns the status purposes of the list
+func (b BitstringStatusListCredential) GetStatusPurposes() ([]StatusPurpose, error) {
+	purposes, err := util.InterfaceToStrings(b.StatusPurpose)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing status purpose")
+	}
+	statusPurposes := make([]StatusPurpose, 0, len(purposes))
+	for _, purpose := range purposes {
+		statusPurposes = append(statusPurposes, StatusPurpose(purpose))
+	}
+	return statusPurposes, nil
+}
+
+// BitstringStatusList is a list of statuses of statusSize bits each. The status at index 0 is stored in the left-most
+// bits of the bitstring. https://www.w3.org/TR/vc-bitstring-status-list/#bitstring-encoding
+type BitstringStatusList struct {
+	bits       []byte
+	statusSize int
+}
+
+// NewBitstringStatusList creates a list holding the given number of statuses of statusSize bits each, all initialized
+// to 0. The list is padded to hold at least BitstringStatusListMinimumBits bits.
+func NewBitstringStatusList(length, statusSize int) (*BitstringStatusList, error) {
+	if length < 0 {
+		return nil, fmt.Errorf("invalid status list length: %d", length)
+	}
+	if statusSize < 1 || statusSize > 64 {
+		return nil, fmt.Errorf("invalid status size: %d", statusSize)
+	}
+	numBits := length * statusSize
+	if numBits < BitstringStatusListMinimumBits {
+		numBits = BitstringStatusListMinimumBits
+	}
+	return &BitstringStatusList{
+		bits:       make([]byte, (numBits+7)/8),
+		statusSize: statusSize,
+	}, nil
+}
+
+// Len returns the number of statuses the list holds
+func (l *BitstringStatusList) Len() int {
+	return len(l.bits) * 8 / l.statusSize
+}
+
+// SetStatus sets the status at an index of the list
+func (l *BitstringStatusList) SetStatus(index int, status uint64) error {
+	if index < 0 || index >= l.Len() {
+		return fmt.Errorf("status list index out of range: %d", index)
+	}
+	if l.statusSize < 64 && status >= 1<<l.statusSize {
+		return fmt.Errorf("status<%d> does not fit in %d bit(s)", status, l.statusSize)
+	}
+	start := index * l.statusSize
+	for i := 0; i < l.statusSize; i++ {
+		bit := start + i
+		mask := byte(1 << (7 - bit%8))
+		if status&(1<<(l.statusSize-1-i)) != 0 {
+			l.bits[bit/8] |= mask
+		} else {
+			l.bits[bit/8] &^= mask
+		}
+	}
+	return nil
+}
+
+// GetStatus returns the status at an index of the list
+func (l *BitstringStatusList) GetStatus(index int) (uint64, error) {
+	if index < 0 || index >= l.Len() {
+		return 0, fmt.Errorf("status list index out of range: %d", index)
+	}
+	var status uint64
+	start := index * l.statusSize
+	for i := 0; i < l.statusSize; i++ {
+		bit := start + i
+		status <<= 1
+		if l.bits[bit/8]&(1<<(7-bit%8)) != 0 {
+			status |= 1
+		}
+	}
+	return status, nil
+}
+
+// Encode compresses the bitstring using GZIP and encodes the result as a multibase base64url string
+// https://www.w3.org/TR/vc-bitstring-status-list/#bitstring-generation-algorithm
+func (l *BitstringStatusList) Encode() (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(l.bits); err != nil {
+		return "", errors.Wrap(err, "compressing status list bitstring using GZIP")
+	}
+	if err := zw.Close(); err != nil {
+		return "", errors.Wrap(err, "closing gzip writer")
+	}
+	return string(multibaseBase64URL) + base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeBitstringStatusList expands an encoded list into a list of statuses of statusSize bits each
+// https://www.w3.org/TR/vc-bitstring-status-list/#bitstring-expansion-algorithm
+func DecodeBitstringStatusList(encodedList string, statusSize int) (*BitstringStatusList, error) {
+	if statusSize < 1 || statusSize > 64 {
+		return nil, fmt.Errorf("invalid status size: %d", statusSize)
+	}
+	if encodedList == "" || encodedList[0] != multibaseBase64URL {
+		return nil, errors.New("encoded list must be a multibase base64url string")
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(encodedList[1:])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding encoded list")
+	}
+	return expandBitstring(decoded, statusSize)
+}
+
+// expandBitstring decompresses a GZIP compressed bitstring into a list of statuses of statusSize bits each
+func expandBitstring(compressed []byte, statusSize int) (*BitstringStatusList, error) {
+	bits, err := gunzipStatusList(compressed)
+	if err != nil {
+		return nil, err
+	}
+	if len(bits)*8 < BitstringStatusListMinimumBits {
+		return nil, fmt.Errorf("status list bitstring has %d bits, less than the minimum of %d", len(bits)*8, BitstringStatusListMinimumBits)
+	}
+	return &BitstringStatusList{bits: bits, statusSize: statusSize}, nil
+}
+
+// NewBitstringStatusListEntry creates the credentialStatus entry of a credential which is tracked at the given index
+// of the status list credential hosted at statusListCredential. Entries with a statusSize greater than 1, or with the
+// message purpose, must describe each possible status value with a message.
+func NewBitstringStatusListEntry(statusListCredential string, index int, purpose StatusPurpose, statusSize int, messages []BitstringStatusMessage) (*BitstringStatusListEntry, error) {
+	if statusListCredential == "" {
+		return nil, errors.New("status list credential cannot be empty")
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("invalid status list index value, not a valid positive integer: %d", index)
+	}
+	if purpose == "" {
+		return nil, errors.New("status purpose cannot be empty")
+	}
+	if statusSize < 1 || statusSize > 64 {
+		return nil, fmt.Errorf("invalid status size: %d", statusSize)
+	}
+	if statusSize > 1 || purpose == StatusMessage {
+		if statusSize < 64 && len(messages) != 1<<statusSize {
+			return nil, fmt.Errorf("entries with a status size of %d must have %d status messages", statusSize, 1<<statusSize)
+		}
+	} else if len(messages) > 0 && len(messages) != 2 {
+		return nil, errors.New("entries with a status size of 1 must have 2 status messages, if any")
+	}
+	statusListIndex := strconv.Itoa(index)
+	entry := BitstringStatusListEntry{
+		ID:                   statusListCredential + "#" + statusListIndex,
+		Type:                 BitstringStatusListEntryType,
+		StatusPurpose:        purpose,
+		StatusListIndex:      statusListIndex,
+		StatusListCredential: statusListCredential,
+		StatusMessage:        messages,
+	}
+	if statusSize > 1 {
+		entry.StatusSize = statusSize
+	}
+	return &entry, nil
+}
+
+// SetBitstringStatusListEntries sets the credentialStatus of a credential being issued to one or more
+// BitstringStatusListEntry values, e.g. one per status purpose. The terms of the entries are defined by the
+// VerifiableCredentialsV2Context, which the credential should use for the entries to be covered by a Data Integrity
+// proof.
+func SetBitstringStatusListEntries(builder *credential.VerifiableCredentialBuilder, entries ...BitstringStatusListEntry) error {
+	if builder.IsEmpty() {
+		return errors.New(credential.BuilderEmptyError)
+	}
+	if len(entries) == 0 {
+		return errors.New("at least one status list entry is required")
+	}
+	statuses := make([]any, 0, len(entries))
+	for _, entry := range entries {
+		if err := util.IsValidStruct(entry); err != nil {
+			return errors.Wrap(err, "invalid status list entry")
+		}
+		entryJSON, err := util.ToJSONMap(entry)
+		if err != nil {
+			return errors.Wrap(err, "turning status list entry to JSON")
+		}
+		statuses = append(statuses, entryJSON)
+	}
+	if len(statuses) == 1 {
+		builder.CredentialStatus = statuses[0]
+	} else {
+		builder.CredentialStatus = statuses
+	}
+	return nil
+}
+
+// GenerateBitstringStatusListCredential generates a status list credential given an ID (the URI where this entity
+// will be hosted), the issuer DID, the purposes of the list, and the list itself
+// https://www.w3.org/TR/vc-bitstring-status-list/#bitstringstatuslistcredential
+func GenerateBitstringStatusListCredential(id string, issuer string, list *BitstringStatusList, purposes ...StatusPurpose) (*credential.VerifiableCredential, error) {
+	if id == "" || issuer == "" {
+		return nil, errors.New("status list credential id and issuer are required")
+	}
+	if list == nil {
+		return nil, errors.New("status list cannot be empty")
+	}
+	if len(purposes) == 0 {
+		return nil, errors.New("at least one status purpose is required")
+	}
+	encodedList, err := list.Encode()
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding status list")
+	}
+
+	var statusPurpose any = purposes[0]
+	if len(purposes) > 1 {
+		statusPurpose = purposes
+	}
+	subject, err := util.ToJSONMap(BitstringStatusListCredential{
+		ID:            id + "#list",
+		Type:          BitstringStatusListType,
+		StatusPurpose: statusPurpose,
+		EncodedList:   encodedList,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "turning status list to JSON")
+	}
+	statusListCredential := credential.VerifiableCredential{
+		Context:           []any{VerifiableCredentialsV2Context},
+		ID:                id,
+		Type:              []string{credential.VerifiableCredentialType, BitstringStatusListCredentialType},
+		Issuer:            issuer,
+		IssuanceDate:      time.Now().UTC().Format(time.RFC3339),
+		CredentialSubject: subject,
+	}
+	if err = statusListCredential.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "building status list credential")
+	}
+	return &statusListCredential, nil
+}
+
+// BitstringStatusResult is the status of a credential for one of its BitstringStatusListEntry values
+type BitstringStatusResult struct {
+	Entry  BitstringStatusListEntry
+	Status uint64
+	// Message describes the status, for entries with status messages
+	Message string
+}
+
+// IsSet returns whether the status is set, e.g. whether the credential is revoked for the revocation purpose
+func (r BitstringStatusResult) IsSet() bool {
+	return r.Status != 0
+}
+
+// GetBitstringStatusListEntries returns the BitstringStatusListEntry values of a credential's credentialStatus
+func GetBitstringStatusListEntries(cred credential.VerifiableCredential) ([]BitstringStatusListEntry, error) {
+	var statuses []any
+	switch typedStatus := cred.CredentialStatus.(type) {
+	case nil:
+		return nil, fmt.Errorf("credential<%s> does not have a credentialStatus property", cred.ID)
+	case []any:
+		statuses = typedStatus
+	case []BitstringStatusListEntry:
+		return typedStatus, nil
+	default:
+		statuses = []any{typedStatus}
+	}
+
+	var entries []BitstringStatusListEntry
+	for _, status := range statuses {
+		statusBytes, err := json.Marshal(status)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshaling credential status property")
+		}
+		var entry BitstringStatusListEntry
+		if err = json.Unmarshal(statusBytes, &entry); err != nil {
+			return nil, errors.Wrap(err, "unmarshaling credential status property")
+		}
+		// credentials may use other kinds of status alongside bitstring status lists
+		if entry.Type != BitstringStatusListEntryType {
+			continue
+		}
+		if err = util.IsValidStruct(entry); err != nil {
+			return nil, errors.Wrapf(err, "credential<%s> has an invalid BitstringStatusListEntry", cred.ID)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("credential<%s> not using the BitstringStatusList credentialStatus property", cred.ID)
+	}
+	return entries, nil
+}
+
+// ValidateCredentialInBitstringStatusList returns the status of a credential for its entry referencing the given
+// status list credential
+// https://www.w3.org/TR/vc-bitstring-status-list/#validate-algorithm
+// NOTE: this method does not perform credential signature/proof block verification
+func ValidateCredentialInBitstringStatusList(entry BitstringStatusListEntry, statusListCredential credential.VerifiableCredential) (*BitstringStatusResult, error) {
+	var subject BitstringStatusListCredential
+	subjectBytes, err := json.Marshal(statusListCredential.CredentialSubject)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not marshal status credential<%s> subject value", statusListCredential.ID)
+	}
+	if err = json.Unmarshal(subjectBytes, &subject); err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal status credential<%s> subject value into "+
+			"BitstringStatusListCredential", statusListCredential.ID)
+	}
+	if err = util.IsValidStruct(subject); err != nil {
+		return nil, errors.Wrapf(err, "credential<%s> is not a valid status credential", statusListCredential.ID)
+	}
+
+	// the status purpose of the entry must be one of the list's purposes
+	purposes, err := subject.GetStatusPurposes()
+	if err != nil {
+		return nil, errors.Wrapf(err, "status credential<%s>", statusListCredential.ID)
+	}
+	if !util.Contains(string(entry.StatusPurpose), purposesToStrings(purposes)) {
+		return nil, fmt.Errorf("purpose of credential status entry<%s>: %s, is not a purpose of status credential<%s>",
+			entry.ID, entry.StatusPurpose, statusListCredential.ID)
+	}
+
+	list, err := DecodeBitstringStatusList(subject.EncodedList, entry.GetStatusSize())
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not expand encoded list of status credential<%s>", statusListCredential.ID)
+	}
+	index, err := strconv.Atoi(entry.StatusListIndex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status list index value, not a valid positive integer: %s", entry.StatusListIndex)
+	}
+	status, err := list.GetStatus(index)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting status from status credential<%s>", statusListCredential.ID)
+	}
+	return &BitstringStatusResult{
+		Entry:   entry,
+		Status:  status,
+		Message: entry.GetStatusMessage(status),
+	}, nil
+}
+
+// CheckBitstringStatusList fetches the status list credentials referenced by each of a credential's
+// BitstringStatusListEntry values, verifies their signatures using the resolver and that they have the credential's
+// issuer, and returns the credential's status for each entry.
+// NOTE: this method does not verify the signature of the credential being checked
+func CheckBitstringStatusList(ctx context.Context, cred credential.VerifiableCredential, access StatusListAccess, r resolution.Resolver) ([]BitstringStatusResult, error) {
+	if access == nil {
+		return nil, errors.New("status list access cannot be empty")
+	}
+	if r == nil {
+		return nil, errors.New("resolution cannot be empty")
+	}
+	entries, err := GetBitstringStatusListEntries(cred)
+	if err != nil {
+		return nil, err
+	}
+
+	// entries for different purposes may share a status list credential
+	statusListCredentials := make(map[string]*credential.VerifiableCredential)
+	results := make([]BitstringStatusResult, 0, len(entries))
+	for _, entry := range entries {
+		statusListCredential, ok := statusListCredentials[entry.StatusListCredential]
+		if !ok {
+			statusListCredential, err = fetchStatusListCredential(ctx, entry.StatusListCredential, access, r)
+			if err != nil {
+				return nil, err
+			}
+			if err = checkStatusListIssuer(cred, *statusListCredential); err != nil {
+				return nil, err
+			}
+			statusListCredentials[entry.StatusListCredential] = statusListCredential
+		}
+		result, err := ValidateCredentialInBitstringStatusList(entry, *statusListCredential)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+func purposesToStrings(purposes []StatusPurpose) []string {
+	strs := make([]string, 0, len(purposes))
+	for _, purpose := range purposes {
+		strs = append(strs, string(purpose))
+	}
+	return strs
+}
@@ -0,0 +1,289 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestBitstringStatusList(t *testing.T) {
+	t.Run("single bit statuses", func(tt *testing.T) {
+		list, err := NewBitstringStatusList(10, 1)
+		assert.NoError(tt, err)
+		// the list is padded to the minimum length
+		assert.Equal(tt, BitstringStatusListMinimumBits, list.Len())
+
+		assert.NoError(tt, list.SetStatus(0, 1))
+		assert.NoError(tt, list.SetStatus(9, 1))
+		// index 0 is the left-most bit
+		assert.Equal(tt, byte(0x80), list.bits[0])
+		assert.Equal(tt, byte(0x40), list.bits[1])
+
+		encoded, err := list.Encode()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "u", encoded[:1])
+
+		decoded, err := DecodeBitstringStatusList(encoded, 1)
+		assert.NoError(tt, err)
+		for index, want := range map[int]uint64{0: 1, 1: 0, 9: 1, 10: 0} {
+			status, err := decoded.GetStatus(index)
+			assert.NoError(tt, err)
+			assert.Equal(tt, want, status)
+		}
+
+		// statuses can be cleared
+		assert.NoError(tt, list.SetStatus(0, 0))
+		status, err := list.GetStatus(0)
+		assert.NoError(tt, err)
+		assert.Zero(tt, status)
+	})
+
+	t.Run("multi bit statuses", func(tt *testing.T) {
+		list, err := NewBitstringStatusList(BitstringStatusListMinimumBits, 2)
+		assert.NoError(tt, err)
+		assert.Equal(tt, BitstringStatusListMinimumBits, list.Len())
+
+		assert.NoError(tt, list.SetStatus(1, 2))
+		assert.NoError(tt, list.SetStatus(2, 3))
+		assert.Equal(tt, byte(0b00101100), list.bits[0])
+
+		encoded, err := list.Encode()
+		assert.NoError(tt, err)
+		decoded, err := DecodeBitstringStatusList(encoded, 2)
+		assert.NoError(tt, err)
+		for index, want := range map[int]uint64{0: 0, 1: 2, 2: 3, 3: 0} {
+			status, err := decoded.GetStatus(index)
+			assert.NoError(tt, err)
+			assert.Equal(tt, want, status)
+		}
+
+		err = list.SetStatus(0, 4)
+		assert.ErrorContains(tt, err, "status<4> does not fit in 2 bit(s)")
+	})
+
+	t.Run("invalid lists", func(tt *testing.T) {
+		_, err := NewBitstringStatusList(10, 0)
+		assert.ErrorContains(tt, err, "invalid status size: 0")
+
+		list, err := NewBitstringStatusList(10, 1)
+		assert.NoError(tt, err)
+		_, err = list.GetStatus(BitstringStatusListMinimumBits)
+		assert.ErrorContains(tt, err, "status list index out of range")
+
+		_, err = DecodeBitstringStatusList("H4sIAAAAAAAA", 1)
+		assert.ErrorContains(tt, err, "encoded list must be a multibase base64url string")
+
+		short := BitstringStatusList{bits: make([]byte, 16), statusSize: 1}
+		encoded, err := short.Encode()
+		assert.NoError(tt, err)
+		_, err = DecodeBitstringStatusList(encoded, 1)
+		assert.ErrorContains(tt, err, "less than the minimum")
+
+		oversized := BitstringStatusList{bits: make([]byte, maxStatusListLength+1), statusSize: 1}
+		encoded, err = oversized.Encode()
+		assert.NoError(tt, err)
+		_, err = DecodeBitstringStatusList(encoded, 1)
+		assert.ErrorContains(tt, err, "status list bitstring exceeds the maximum length")
+	})
+}
+
+func TestNewBitstringStatusListEntry(t *testing.T) {
+	statusListURL := "https://example.com/status/3"
+	entry, err := NewBitstringStatusListEntry(statusListURL, 94567, StatusRevocation, 1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, BitstringStatusListEntry{
+		ID:                   "https://example.com/status/3#94567",
+		Type:                 BitstringStatusListEntryType,
+		StatusPurpose:        StatusRevocation,
+		StatusListIndex:      "94567",
+		StatusListCredential: statusListURL,
+	}, *entry)
+	assert.Equal(t, 1, entry.GetStatusSize())
+
+	entry, err = NewBitstringStatusListEntry(statusListURL, 3, StatusMessage, 2, getTestStatusMessages())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, entry.GetStatusSize())
+	assert.Equal(t, "pending_review", entry.GetStatusMessage(1))
+
+	_, err = NewBitstringStatusListEntry(statusListURL, 3, StatusMessage, 2, nil)
+	assert.ErrorContains(t, err, "entries with a status size of 2 must have 4 status messages")
+
+	_, err = NewBitstringStatusListEntry(statusListURL, 3, StatusMessage, 1, nil)
+	assert.ErrorContains(t, err, "entries with a status size of 1 must have 2 status messages")
+
+	_, err = NewBitstringStatusListEntry("", 3, StatusRevocation, 1, nil)
+	assert.ErrorContains(t, err, "status list credential cannot be empty")
+}
+
+func TestCheckBitstringStatusList(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	revocationURL := "https://example.com/status/revocation"
+	messageURL := "https://example.com/status/message"
+	access := make(staticStatusListAccess)
+	publish := func(tt *testing.T, url string, list *BitstringStatusList, purposes ...StatusPurpose) {
+		statusListCredential, err := GenerateBitstringStatusListCredential(url, didKey.String(), list, purposes...)
+		require.NoError(tt, err)
+		token, err := integrity.SignVerifiableCredentialJWT(*signer, *statusListCredential)
+		require.NoError(tt, err)
+		access[url] = token
+	}
+
+	revocations, err := NewBitstringStatusList(0, 1)
+	require.NoError(t, err)
+	require.NoError(t, revocations.SetStatus(5, 1))
+	publish(t, revocationURL, revocations, StatusRevocation, StatusSuspension)
+	messages, err := NewBitstringStatusList(0, 2)
+	require.NoError(t, err)
+	require.NoError(t, messages.SetStatus(3, 2))
+	publish(t, messageURL, messages, StatusMessage)
+
+	issue := func(tt *testing.T, revocationIndex int) credential.VerifiableCredential {
+		revocationEntry, err := NewBitstringStatusListEntry(revocationURL, revocationIndex, StatusRevocation, 1, nil)
+		require.NoError(tt, err)
+		suspensionEntry, err := NewBitstringStatusListEntry(revocationURL, revocationIndex+1, StatusSuspension, 1, nil)
+		require.NoError(tt, err)
+		messageEntry, err := NewBitstringStatusListEntry(messageURL, 3, StatusMessage, 2, getTestStatusMessages())
+		require.NoError(tt, err)
+
+		builder := credential.NewVerifiableCredentialBuilder(credential.GenerateIDValue)
+		require.NoError(tt, builder.SetIssuer(didKey.String()))
+		require.NoError(tt, builder.SetCredentialSubject(map[string]any{"id": "did:example:456"}))
+		require.NoError(tt, SetBitstringStatusListEntries(&builder, *revocationEntry, *suspensionEntry, *messageEntry))
+		cred, err := builder.Build()
+		require.NoError(tt, err)
+		return *cred
+	}
+
+	t.Run("revoked credential", func(tt *testing.T) {
+		results, err := CheckBitstringStatusList(context.Background(), issue(tt, 5), access, resolver)
+		assert.NoError(tt, err)
+		require.Len(tt, results, 3)
+		assert.Equal(tt, StatusRevocation, results[0].Entry.StatusPurpose)
+		assert.True(tt, results[0].IsSet())
+		assert.Equal(tt, StatusSuspension, results[1].Entry.StatusPurpose)
+		assert.False(tt, results[1].IsSet())
+		assert.Equal(tt, StatusMessage, results[2].Entry.StatusPurpose)
+		assert.Equal(tt, uint64(2), results[2].Status)
+		assert.Equal(tt, "accepted", results[2].Message)
+	})
+
+	t.Run("active credential", func(tt *testing.T) {
+		results, err := CheckBitstringStatusList(context.Background(), issue(tt, 7), access, resolver)
+		assert.NoError(tt, err)
+		require.Len(tt, results, 3)
+		assert.False(tt, results[0].IsSet())
+		assert.False(tt, results[1].IsSet())
+	})
+
+	t.Run("status reports", func(tt *testing.T) {
+		revoked, err := GetStatusReport(context.Background(), issue(tt, 5), access, resolver)
+		require.NoError(tt, err)
+		assert.Equal(tt, StatusOutcomeRevoked, revoked.Outcome)
+		assert.Len(tt, revoked.Statuses, 3)
+		assert.Equal(tt, []string{"accepted"}, revoked.Messages())
+		assert.ErrorIs(tt, revoked.Err(), ErrRevoked)
+
+		suspended, err := GetStatusReport(context.Background(), issue(tt, 4), access, resolver)
+		require.NoError(tt, err)
+		assert.Equal(tt, StatusOutcomeSuspended, suspended.Outcome)
+		assert.ErrorIs(tt, suspended.Err(), ErrSuspended)
+		assert.ErrorContains(tt, CheckStatus(context.Background(), issue(tt, 4), access, resolver), "status is set in its suspension status list")
+
+		active, err := GetStatusReport(context.Background(), issue(tt, 7), access, resolver)
+		require.NoError(tt, err)
+		assert.Equal(tt, StatusOutcomeActive, active.Outcome)
+		assert.NoError(tt, active.Err())
+		assert.NoError(tt, CheckStatus(context.Background(), issue(tt, 7), access, resolver))
+
+		entry, err := NewBitstringStatusListEntry("https://example.com/status/missing", 3, StatusRevocation, 1, nil)
+		require.NoError(tt, err)
+		unknown, err := GetStatusReport(context.Background(), credential.VerifiableCredential{ID: "test-cred", CredentialStatus: *entry}, access, resolver)
+		require.NoError(tt, err)
+		assert.Equal(tt, StatusOutcomeUnknown, unknown.Outcome)
+		assert.Contains(tt, unknown.Reason, "status list credential not found")
+		assert.ErrorIs(tt, unknown.Err(), ErrStatusUnknown)
+
+		unsupported, err := GetStatusReport(context.Background(), credential.VerifiableCredential{ID: "test-cred", CredentialStatus: map[string]any{"type": "CustomStatus"}}, access, resolver)
+		require.NoError(tt, err)
+		assert.Equal(tt, StatusOutcomeUnknown, unsupported.Outcome)
+		assert.Contains(tt, unsupported.Reason, "credential<test-cred> has an unsupported credentialStatus")
+
+		_, err = GetStatusReport(context.Background(), issue(tt, 7), nil, resolver)
+		assert.ErrorContains(tt, err, "status list access cannot be empty")
+	})
+
+	t.Run("purpose not in status list", func(tt *testing.T) {
+		entry, err := NewBitstringStatusListEntry(messageURL, 3, StatusRevocation, 1, nil)
+		require.NoError(tt, err)
+		cred := credential.VerifiableCredential{ID: "test-cred", Issuer: didKey.String(), CredentialStatus: *entry}
+		_, err = CheckBitstringStatusList(context.Background(), cred, access, resolver)
+		assert.ErrorContains(tt, err, "is not a purpose of status credential<https://example.com/status/message>")
+	})
+
+	t.Run("status list of another issuer", func(tt *testing.T) {
+		otherPrivKey, otherDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		otherExpanded, err := otherDIDKey.Expand()
+		require.NoError(tt, err)
+		otherKID := otherExpanded.VerificationMethod[0].ID
+		otherSigner, err := jwx.NewJWXSigner(otherDIDKey.String(), &otherKID, otherPrivKey)
+		require.NoError(tt, err)
+
+		otherURL := "https://example.com/status/other"
+		statusListCredential, err := GenerateBitstringStatusListCredential(otherURL, otherDIDKey.String(), revocations, StatusRevocation)
+		require.NoError(tt, err)
+		token, err := integrity.SignVerifiableCredentialJWT(*otherSigner, *statusListCredential)
+		require.NoError(tt, err)
+		access[otherURL] = token
+
+		entry, err := NewBitstringStatusListEntry(otherURL, 5, StatusRevocation, 1, nil)
+		require.NoError(tt, err)
+		cred := credential.VerifiableCredential{ID: "test-cred", Issuer: didKey.String(), CredentialStatus: *entry}
+		_, err = CheckBitstringStatusList(context.Background(), cred, access, resolver)
+		assert.ErrorContains(tt, err, "is not the issuer<"+didKey.String()+">")
+	})
+
+	t.Run("no bitstring status list entries", func(tt *testing.T) {
+		cred := credential.VerifiableCredential{ID: "test-cred", CredentialStatus: map[string]any{"id": "status", "type": StatusList2021EntryType}}
+		_, err := CheckBitstringStatusList(context.Background(), cred, access, resolver)
+		assert.ErrorContains(tt, err, "credential<test-cred> not using the BitstringStatusList credentialStatus property")
+	})
+}
+
+func getTestStatusMessages() []BitstringStatusMessage {
+	return []BitstringStatusMessage{
+		{Status: "0x0", Message: "unset"},
+		{Status: "0x1", Message: "pending_review"},
+		{Status: "0x2", Message: "accepted"},
+		{Status: "0x3", Message: "rejected"},
+	}
+}
+
+type staticStatusListAccess map[string][]byte
+
+func (s staticStatusListAccess) GetStatusListCredential(_ context.Context, url string) ([]byte, error) {
+	statusListCredential, ok := s[url]
+	if !ok {
+		return nil, fmt.Errorf("status list credential not found: %s", url)
+	}
+	return statusListCredential, nil
+}
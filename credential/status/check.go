@@ -0,0 +1,58 @@
+package status
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// CheckStatus checks a credential using a StatusList2021Entry, BitstringStatusListEntry, or legacy
+// RevocationList2020Status credentialStatus has not been revoked or suspended, fetching its status list credentials
+// and verifying their signatures. Statuses for other purposes, such as refresh or message, are not checked.
+// Credentials without a credentialStatus pass the check. The error of a credential which is revoked, suspended, or
+// whose status is unknown wraps ErrRevoked, ErrSuspended, or ErrStatusUnknown respectively; GetStatusReport describes
+// the status of each purpose.
+func CheckStatus(ctx context.Context, cred credential.VerifiableCredential, access StatusListAccess, r resolution.Resolver) error {
+	if cred.CredentialStatus == nil {
+		return nil
+	}
+	report, err := GetStatusReport(ctx, cred, access, r)
+	if err != nil {
+		return errors.Wrap(err, "checking credential status")
+	}
+	return report.Err()
+}
+
+// Checker checks the status of credentials as they are verified, using CheckStatus
+type Checker struct {
+	access   StatusListAccess
+	resolver resolution.Resolver
+}
+
+var _ integrity.StatusChecker = (*Checker)(nil)
+
+// NewChecker returns a checker fetching status list credentials with the given access, and verifying their
+// signatures with the given resolver. It is provided to verification with integrity.WithStatusCheck.
+func NewChecker(access StatusListAccess, r resolution.Resolver) (*Checker, error) {
+	if access == nil {
+		return nil, errors.New("status list access cannot be empty")
+	}
+	if r == nil {
+		return nil, errors.New("resolution cannot be empty")
+	}
+	return &Checker{access: access, resolver: r}, nil
+}
+
+// CheckStatus checks the credential has not been revoked or suspended
+func (c *Checker) CheckStatus(ctx context.Context, cred credential.VerifiableCredential) error {
+	return CheckStatus(ctx, cred, c.access, c.resolver)
+}
+
+// GetStatusReport describes the status of the credential for each of its status purposes
+func (c *Checker) GetStatusReport(ctx context.Context, cred credential.VerifiableCredential) (*StatusReport, error) {
+	return GetStatusReport(ctx, cred, c.access, c.resolver)
+}
@@ -0,0 +1,175 @@
+package status
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// StatusOutcome is the overall status of a credential, determined from the statuses of each of its status purposes
+type StatusOutcome string
+
+const (
+	// StatusOutcomeActive is the outcome of a credential which is neither revoked nor suspended, including one
+	// without a credentialStatus
+	StatusOutcomeActive StatusOutcome = "active"
+	// StatusOutcomeRevoked is the outcome of a credential whose revocation status is set
+	StatusOutcomeRevoked StatusOutcome = "revoked"
+	// StatusOutcomeSuspended is the outcome of a credential whose suspension status is set, and which is not revoked
+	StatusOutcomeSuspended StatusOutcome = "suspended"
+	// StatusOutcomeUnknown is the outcome of a credential whose status could not be determined, such as when its
+	// status list credential is unavailable or its credentialStatus is of an unsupported type
+	StatusOutcomeUnknown StatusOutcome = "unknown"
+)
+
+var (
+	// ErrRevoked is wrapped by the error of a report whose outcome is StatusOutcomeRevoked
+	ErrRevoked = errors.New("credential is revoked")
+	// ErrSuspended is wrapped by the error of a report whose outcome is StatusOutcomeSuspended
+	ErrSuspended = errors.New("credential is suspended")
+	// ErrStatusUnknown is wrapped by the error of a report whose outcome is StatusOutcomeUnknown
+	ErrStatusUnknown = errors.New("credential status is unknown")
+)
+
+// PurposeStatus is the status of a credential for one of its status purposes
+type PurposeStatus struct {
+	Purpose StatusPurpose `json:"statusPurpose"`
+	// Status is the value of the credential's status, which is 0 or 1 unless the entry has a statusSize above 1
+	Status uint64 `json:"status"`
+	// Message describes the status, for entries with status messages
+	Message string `json:"message,omitempty"`
+}
+
+// IsSet returns whether the status is set, e.g. whether the credential is revoked for the revocation purpose
+func (s PurposeStatus) IsSet() bool {
+	return s.Status != 0
+}
+
+// StatusReport describes the status of a credential for each of its status purposes, such as revocation,
+// suspension, and message, along with the resulting outcome
+type StatusReport struct {
+	CredentialID string          `json:"credentialId,omitempty"`
+	Outcome      StatusOutcome   `json:"outcome"`
+	Statuses     []PurposeStatus `json:"statuses,omitempty"`
+	// Reason describes why the status is unknown
+	Reason string `json:"reason,omitempty"`
+
+	cause error
+}
+
+// Messages returns the messages describing each set status, such as those of the message status purpose
+func (r StatusReport) Messages() []string {
+	var messages []string
+	for _, s := range r.Statuses {
+		if s.IsSet() && s.Message != "" {
+			messages = append(messages, s.Message)
+		}
+	}
+	return messages
+}
+
+// Err returns an error if the credential is not active, wrapping ErrRevoked, ErrSuspended, or ErrStatusUnknown
+// according to the outcome, which can be distinguished with errors.Is
+func (r StatusReport) Err() error {
+	switch r.Outcome {
+	case StatusOutcomeRevoked:
+		return errors.Wrapf(ErrRevoked, "credential<%s> status is set in its %s status list", r.CredentialID, StatusRevocation)
+	case StatusOutcomeSuspended:
+		return errors.Wrapf(ErrSuspended, "credential<%s> status is set in its %s status list", r.CredentialID, StatusSuspension)
+	case StatusOutcomeUnknown:
+		if r.cause != nil {
+			return fmt.Errorf("%w: %w", ErrStatusUnknown, r.cause)
+		}
+		return errors.Wrapf(ErrStatusUnknown, "credential<%s>: %s", r.CredentialID, r.Reason)
+	}
+	return nil
+}
+
+// GetStatusReport checks the status of a credential using StatusList2021Entry, BitstringStatusListEntry, or legacy
+// RevocationList2020Status credentialStatus entries, fetching its status list credentials and verifying their
+// signatures. A revoked credential has the StatusOutcomeRevoked outcome even if it is also suspended. Statuses for
+// other purposes, such as refresh or message, are reported without affecting the outcome. When the status cannot be
+// determined the outcome is StatusOutcomeUnknown; an error is only returned if the access or resolver are missing.
+func GetStatusReport(ctx context.Context, cred credential.VerifiableCredential, access StatusListAccess, r resolution.Resolver) (*StatusReport, error) {
+	if access == nil {
+		return nil, errors.New("status list access cannot be empty")
+	}
+	if r == nil {
+		return nil, errors.New("resolution cannot be empty")
+	}
+	report := StatusReport{CredentialID: cred.ID, Outcome: StatusOutcomeActive}
+	if cred.CredentialStatus == nil {
+		return &report, nil
+	}
+
+	statuses, err := getPurposeStatuses(ctx, cred, access, r)
+	if err != nil {
+		report.Outcome = StatusOutcomeUnknown
+		report.Reason = err.Error()
+		report.cause = errors.Wrap(err, "checking credential status")
+		return &report, nil
+	}
+	report.Statuses = statuses
+	for _, s := range statuses {
+		if !s.IsSet() {
+			continue
+		}
+		switch s.Purpose {
+		case StatusRevocation:
+			report.Outcome = StatusOutcomeRevoked
+		case StatusSuspension:
+			if report.Outcome != StatusOutcomeRevoked {
+				report.Outcome = StatusOutcomeSuspended
+			}
+		}
+	}
+	return &report, nil
+}
+
+// getPurposeStatuses returns the credential's status for each of its status purposes
+func getPurposeStatuses(ctx context.Context, cred credential.VerifiableCredential, access StatusListAccess, r resolution.Resolver) ([]PurposeStatus, error) {
+	if _, err := GetBitstringStatusListEntries(cred); err == nil {
+		results, err := CheckBitstringStatusList(ctx, cred, access, r)
+		if err != nil {
+			return nil, err
+		}
+		statuses := make([]PurposeStatus, 0, len(results))
+		for _, result := range results {
+			statuses = append(statuses, PurposeStatus{
+				Purpose: result.Entry.StatusPurpose,
+				Status:  result.Status,
+				Message: result.Message,
+			})
+		}
+		return statuses, nil
+	}
+
+	if _, err := GetRevocationList2020Status(cred); err == nil {
+		revoked, err := CheckRevocationList2020(ctx, cred, access, r)
+		if err != nil {
+			return nil, err
+		}
+		return []PurposeStatus{{Purpose: StatusRevocation, Status: boolToStatus(revoked)}}, nil
+	}
+
+	entry, err := getStatusEntry(cred.CredentialStatus)
+	if err != nil {
+		return nil, errors.Wrapf(err, "credential<%s> has an unsupported credentialStatus", cred.ID)
+	}
+	isSet, err := CheckStatusList2021(ctx, cred, access, r)
+	if err != nil {
+		return nil, err
+	}
+	return []PurposeStatus{{Purpose: entry.StatusPurpose, Status: boolToStatus(isSet)}}, nil
+}
+
+func boolToStatus(isSet bool) uint64 {
+	if isSet {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,124 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// RevocationList2020 is the predecessor of StatusList2021. It is supported for verification only, so the status of
+// credentials issued by older stacks can be checked.
+// https://w3c-ccg.github.io/vc-status-rl-2020/
+
+const (
+	RevocationList2020CredentialType string = "RevocationList2020Credential"
+	RevocationList2020StatusType     string = "RevocationList2020Status"
+	RevocationList2020Type           string = "RevocationList2020"
+
+	RevocationList2020Context string = "https://w3id.org/vc-revocation-list-2020/v1"
+)
+
+// RevocationList2020Status the representation within a credential that is associated with a revocation list
+// https://w3c-ccg.github.io/vc-status-rl-2020/#revocationlist2020status
+type RevocationList2020Status struct {
+	ID                       string `json:"id" validate:"required"`
+	Type                     string `json:"type" validate:"required"`
+	RevocationListIndex      string `json:"revocationListIndex" validate:"required"`
+	RevocationListCredential string `json:"revocationListCredential" validate:"required"`
+}
+
+// RevocationList2020Credential the credential subject value of a revocation list credential
+// https://w3c-ccg.github.io/vc-status-rl-2020/#revocationlist2020credential
+type RevocationList2020Credential struct {
+	ID          string `json:"id" validate:"required"`
+	Type        string `json:"type" validate:"required"`
+	EncodedList string `json:"encodedList" validate:"required"`
+}
+
+// GetRevocationList2020Status returns the RevocationList2020Status of a credential's credentialStatus property
+func GetRevocationList2020Status(cred credential.VerifiableCredential) (*RevocationList2020Status, error) {
+	statusBytes, err := json.Marshal(cred.CredentialStatus)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling credential status property")
+	}
+	var status RevocationList2020Status
+	if err = json.Unmarshal(statusBytes, &status); err != nil || status.Type != RevocationList2020StatusType {
+		return nil, fmt.Errorf("credential<%s> not using the RevocationList2020 credentialStatus property", cred.ID)
+	}
+	if err = util.IsValidStruct(status); err != nil {
+		return nil, errors.Wrapf(err, "credential<%s> has an invalid RevocationList2020Status", cred.ID)
+	}
+	return &status, nil
+}
+
+// ValidateCredentialInRevocationList2020 determines whether a credential is revoked according to a revocation list
+// credential https://w3c-ccg.github.io/vc-status-rl-2020/#validate-algorithm
+// NOTE: this method does not perform credential signature/proof block verification
+func ValidateCredentialInRevocationList2020(credentialToValidate credential.VerifiableCredential, revocationListCredential credential.VerifiableCredential) (bool, error) {
+	status, err := GetRevocationList2020Status(credentialToValidate)
+	if err != nil {
+		return false, err
+	}
+
+	var subject RevocationList2020Credential
+	subjectBytes, err := json.Marshal(revocationListCredential.CredentialSubject)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not marshal revocation list credential<%s> subject value", revocationListCredential.ID)
+	}
+	if err = json.Unmarshal(subjectBytes, &subject); err != nil {
+		return false, errors.Wrapf(err, "could not unmarshal revocation list credential<%s> subject value into "+
+			"RevocationList2020Credential", revocationListCredential.ID)
+	}
+	if err = util.IsValidStruct(subject); err != nil {
+		return false, errors.Wrapf(err, "credential<%s> is not a valid revocation list credential", revocationListCredential.ID)
+	}
+	if subject.Type != RevocationList2020Type {
+		return false, fmt.Errorf("credential<%s> is not a %s credential", revocationListCredential.ID, RevocationList2020Type)
+	}
+
+	list, err := decodeBase64Bitstring(subject.EncodedList)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not expand encoded list of revocation list credential<%s>", revocationListCredential.ID)
+	}
+	index, err := strconv.Atoi(status.RevocationListIndex)
+	if err != nil {
+		return false, fmt.Errorf("invalid revocation list index value, not a valid positive integer: %s", status.RevocationListIndex)
+	}
+	revoked, err := list.GetStatus(index)
+	if err != nil {
+		return false, errors.Wrapf(err, "getting status from revocation list credential<%s>", revocationListCredential.ID)
+	}
+	return revoked == 1, nil
+}
+
+// CheckRevocationList2020 fetches the revocation list credential referenced by a credential's
+// RevocationList2020Status, verifies its signature using the resolver and that it has the credential's issuer, and
+// returns whether the credential is revoked.
+// NOTE: this method does not verify the signature of the credential being checked
+func CheckRevocationList2020(ctx context.Context, cred credential.VerifiableCredential, access StatusListAccess, r resolution.Resolver) (bool, error) {
+	if access == nil {
+		return false, errors.New("status list access cannot be empty")
+	}
+	if r == nil {
+		return false, errors.New("resolution cannot be empty")
+	}
+	status, err := GetRevocationList2020Status(cred)
+	if err != nil {
+		return false, err
+	}
+	revocationListCredential, err := fetchStatusListCredential(ctx, status.RevocationListCredential, access, r)
+	if err != nil {
+		return false, err
+	}
+	if err = checkStatusListIssuer(cred, *revocationListCredential); err != nil {
+		return false, err
+	}
+	return ValidateCredentialInRevocationList2020(cred, *revocationListCredential)
+}
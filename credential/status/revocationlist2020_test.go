@@ -0,0 +1,127 @@
+package status
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestRevocationList2020(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	// revocation lists are encoded like bitstring status lists, without a multibase prefix
+	list, err := NewBitstringStatusList(0, 1)
+	require.NoError(t, err)
+	require.NoError(t, list.SetStatus(94567, 1))
+	encoded, err := list.Encode()
+	require.NoError(t, err)
+	encodedList := encoded[1:]
+
+	revocationListURL := "https://example.com/credentials/status/3"
+	newRevocationListCredential := func(encodedList string) credential.VerifiableCredential {
+		return credential.VerifiableCredential{
+			Context:      []any{"https://www.w3.org/2018/credentials/v1", RevocationList2020Context},
+			ID:           revocationListURL,
+			Type:         []string{"VerifiableCredential", RevocationList2020CredentialType},
+			Issuer:       didKey.String(),
+			IssuanceDate: "2020-04-05T14:27:40Z",
+			CredentialSubject: map[string]any{
+				"id":          revocationListURL + "#list",
+				"type":        RevocationList2020Type,
+				"encodedList": encodedList,
+			},
+		}
+	}
+	withStatus := func(index string) credential.VerifiableCredential {
+		return credential.VerifiableCredential{
+			Context:      []any{"https://www.w3.org/2018/credentials/v1", RevocationList2020Context},
+			ID:           "https://example.com/credentials/23894672394",
+			Type:         []string{"VerifiableCredential"},
+			Issuer:       didKey.String(),
+			IssuanceDate: "2020-04-05T14:27:42Z",
+			CredentialStatus: map[string]any{
+				"id":                       revocationListURL + "#" + index,
+				"type":                     RevocationList2020StatusType,
+				"revocationListIndex":      index,
+				"revocationListCredential": revocationListURL,
+			},
+			CredentialSubject: map[string]any{"id": "did:example:6789"},
+		}
+	}
+
+	t.Run("validate", func(tt *testing.T) {
+		revocationListCredential := newRevocationListCredential(encodedList)
+		revoked, err := ValidateCredentialInRevocationList2020(withStatus("94567"), revocationListCredential)
+		assert.NoError(tt, err)
+		assert.True(tt, revoked)
+
+		revoked, err = ValidateCredentialInRevocationList2020(withStatus("94566"), revocationListCredential)
+		assert.NoError(tt, err)
+		assert.False(tt, revoked)
+
+		// lists encoded with the padded standard alphabet are accepted too
+		compressed, err := base64.RawURLEncoding.DecodeString(encodedList)
+		require.NoError(tt, err)
+		revoked, err = ValidateCredentialInRevocationList2020(withStatus("94567"), newRevocationListCredential(base64.StdEncoding.EncodeToString(compressed)))
+		assert.NoError(tt, err)
+		assert.True(tt, revoked)
+
+		_, err = ValidateCredentialInRevocationList2020(withStatus("not-a-number"), revocationListCredential)
+		assert.ErrorContains(tt, err, "invalid revocation list index value")
+
+		_, err = ValidateCredentialInRevocationList2020(withStatus("94567"), newRevocationListCredential("not a list"))
+		assert.ErrorContains(tt, err, "could not expand encoded list")
+
+		oversized := BitstringStatusList{bits: make([]byte, maxStatusListLength+1), statusSize: 1}
+		encodedOversized, err := oversized.Encode()
+		require.NoError(tt, err)
+		_, err = ValidateCredentialInRevocationList2020(withStatus("94567"), newRevocationListCredential(encodedOversized[1:]))
+		assert.ErrorContains(tt, err, "status list bitstring exceeds the maximum length")
+	})
+
+	t.Run("check", func(tt *testing.T) {
+		revocationListCredential := newRevocationListCredential(encodedList)
+		token, err := integrity.SignVerifiableCredentialJWT(*signer, revocationListCredential)
+		require.NoError(tt, err)
+		access := staticStatusListAccess{revocationListURL: token}
+
+		revoked, err := CheckRevocationList2020(context.Background(), withStatus("94567"), access, resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, revoked)
+
+		revoked, err = CheckRevocationList2020(context.Background(), withStatus("1"), access, resolver)
+		assert.NoError(tt, err)
+		assert.False(tt, revoked)
+
+		// only the credential's issuer may revoke it
+		otherIssuer := withStatus("94567")
+		otherIssuer.Issuer = "did:example:other"
+		_, err = CheckRevocationList2020(context.Background(), otherIssuer, access, resolver)
+		assert.ErrorContains(tt, err, "is not the issuer<did:example:other>")
+	})
+
+	t.Run("not a revocation list status", func(tt *testing.T) {
+		cred := withStatus("1")
+		cred.CredentialStatus = map[string]any{"id": "status", "type": StatusList2021EntryType}
+		_, err := GetRevocationList2020Status(cred)
+		assert.ErrorContains(tt, err, "not using the RevocationList2020 credentialStatus property")
+	})
+}
@@ -3,6 +3,7 @@ package status
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -12,8 +13,10 @@ import (
 	"github.com/goccy/go-json"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
 	"github.com/TBD54566975/ssi-sdk/util"
-	"github.com/bits-and-blooms/bitset"
 	"github.com/pkg/errors"
 )
 
@@ -31,6 +34,10 @@ const (
 
 	// KB represents the size of a KB
 	KB = 1 << 10
+
+	// maxStatusListLength bounds the length in bytes of an expanded status list bitstring, which is well beyond the
+	// lists of millions of statuses issuers publish, so that a small compressed list cannot expand without bound
+	maxStatusListLength = 16 * KB * KB
 )
 
 // StatusList2021Entry the representation within a credential that is associated with a status list
@@ -163,47 +170,51 @@ func getStatusEntry(maybeCredentialStatus any) (*StatusList2021Entry, error) {
 // https://w3c-ccg.github.io/vc-status-list-2021/#bitstring-generation-algorithm
 func bitstringGeneration(statusListCredentialIndices []string) (string, error) {
 	// check to see there are no duplicate index values
-	duplicateCheck := make(map[uint]bool)
-
-	// 1. Let bitstring be a list of bits with a minimum size of 16KB, where each bit is initialized to 0 (zero).
-	b := bitset.New(16 * KB)
-
-	// 2. For each bit in bitstring, if there is a corresponding statusListIndex value in a revoked credential in
-	// issuedCredentials, set the bit to 1 (one), otherwise set the bit to 0 (zero).
+	duplicateCheck := make(map[int]bool)
+	indices := make([]int, 0, len(statusListCredentialIndices))
+	maxIndex := -1
 	for _, index := range statusListCredentialIndices {
 		indexInt, err := strconv.Atoi(index)
 		if indexInt < 0 || err != nil {
 			return "", fmt.Errorf("invalid status list index value, not a valid positive integer: %s", index)
 		}
-		indexValue := uint(indexInt)
-		if _, ok := duplicateCheck[indexValue]; ok {
-			return "", fmt.Errorf("duplicate status list index value found: %d", indexValue)
+		if _, ok := duplicateCheck[indexInt]; ok {
+			return "", fmt.Errorf("duplicate status list index value found: %d", indexInt)
+		}
+		if indexInt >= maxStatusListLength*8 {
+			return "", fmt.Errorf("status list index value exceeds the maximum length of %d bytes: %d", maxStatusListLength, indexInt)
+		}
+		duplicateCheck[indexInt] = true
+		indices = append(indices, indexInt)
+		if indexInt > maxIndex {
+			maxIndex = indexInt
 		}
-		duplicateCheck[indexValue] = true
-		b.Set(indexValue)
 	}
 
-	bitstringBinary, err := b.MarshalBinary()
+	// 1. Let bitstring be a list of bits with a minimum size of 16KB, where each bit is initialized to 0 (zero).
+	list, err := NewBitstringStatusList(maxIndex+1, 1)
 	if err != nil {
-		return "", errors.Wrap(err, "generating bitstring binary representation")
+		return "", errors.Wrap(err, "creating status list bitstring")
 	}
 
-	// 3. Generate a compressed bitstring by using the GZIP compression algorithm [RFC1952] on the bitstring and then
-	// base64-encoding [RFC4648] the result.
-	var buf bytes.Buffer
-	zw := gzip.NewWriter(&buf)
-	if _, err = zw.Write(bitstringBinary); err != nil {
-		return "", errors.Wrap(err, "compressing status list bitstring using GZIP")
+	// 2. For each bit in bitstring, if there is a corresponding statusListIndex value in a revoked credential in
+	// issuedCredentials, set the bit to 1 (one), otherwise set the bit to 0 (zero).
+	for _, index := range indices {
+		if err = list.SetStatus(index, 1); err != nil {
+			return "", err
+		}
 	}
 
-	if err = zw.Close(); err != nil {
-		return "", errors.Wrap(err, "closing gzip writer")
+	// 3. Generate a compressed bitstring by using the GZIP compression algorithm [RFC1952] on the bitstring and then
+	// base64-encoding [RFC4648] the result. Lists are base64url encoded, like those of Bitstring Status Lists, without
+	// their multibase prefix.
+	encoded, err := list.Encode()
+	if err != nil {
+		return "", err
 	}
 
-	base64Bitstring := base64.StdEncoding.EncodeToString(buf.Bytes())
-
 	// 4. Return the compressed bitstring.
-	return base64Bitstring, nil
+	return encoded[1:], nil
 }
 
 // https://w3c-ccg.github.io/vc-status-list-2021/#bitstring-expansion-algorithm
@@ -212,40 +223,61 @@ func bitstringExpansion(compressedBitstring string) ([]string, error) {
 
 	// 2. Generate an uncompressed bitstring by using the base64-decoding [RFC4648] algorithm on the compressed
 	// bitstring and then expanding the output using the GZIP decompression algorithm [RFC1952].
-	decoded, err := base64.StdEncoding.DecodeString(compressedBitstring)
+	list, err := decodeBase64Bitstring(compressedBitstring)
+	if err != nil {
+		return nil, err
+	}
+
+	// find set bits, the left-most bit being index 0, to reconstruct the status list indices
+	var expanded []string
+	for i, b := range list.bits {
+		if b == 0 {
+			continue
+		}
+		for bit := 0; bit < 8; bit++ {
+			if b&(1<<(7-bit)) != 0 {
+				expanded = append(expanded, strconv.Itoa(i*8+bit))
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// decodeBase64Bitstring expands a base64 encoded, GZIP compressed bitstring whose left-most bit is the status at
+// index 0, which is how StatusList2021 and RevocationList2020 lists are encoded, up to the maximum length of status
+// lists. Implementations disagree on the base64 alphabet and padding, so any of them are accepted.
+func decodeBase64Bitstring(encodedList string) (*BitstringStatusList, error) {
+	var decoded []byte
+	var err error
+	for _, encoding := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.StdEncoding} {
+		if decoded, err = encoding.DecodeString(strings.TrimSpace(encodedList)); err == nil {
+			break
+		}
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "decoding compressed bitstring")
 	}
+	return expandBitstring(decoded, 1)
+}
 
-	bitstringReader := bytes.NewReader(decoded)
-	zr, err := gzip.NewReader(bitstringReader)
+// gunzipStatusList expands a GZIP compressed status list bitstring, refusing to expand it beyond
+// maxStatusListLength bytes
+func gunzipStatusList(compressed []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
 	if err != nil {
 		return nil, errors.Wrap(err, "unzipping status list bitstring using GZIP")
 	}
-
-	unzipped, err := io.ReadAll(zr)
+	unzipped, err := io.ReadAll(io.LimitReader(zr, maxStatusListLength+1))
 	if err != nil {
 		return nil, errors.Wrap(err, "expanding status list bitstring using GZIP")
 	}
-
-	if err := zr.Close(); err != nil {
-		return nil, errors.Wrap(err, "closing gzip reader")
-	}
-
-	b := bitset.New(uint(len(unzipped)))
-	if err := b.UnmarshalBinary(unzipped); err != nil {
-		return nil, errors.Wrap(err, "unmarshaling binary bitstring")
+	if len(unzipped) > maxStatusListLength {
+		return nil, fmt.Errorf("status list bitstring exceeds the maximum length of %d bytes", maxStatusListLength)
 	}
-
-	// find set bits to reconstruct the status list indices
-	var expanded []string
-	var i uint
-	for i = 0; i < b.Len(); i++ {
-		if b.Test(i) {
-			expanded = append(expanded, strconv.FormatUint(uint64(i), 10))
-		}
+	if err = zr.Close(); err != nil {
+		return nil, errors.Wrap(err, "closing gzip reader")
 	}
-	return expanded, nil
+	return unzipped, nil
 }
 
 // ValidateCredentialInStatusList determines whether a credential is contained in a status list 2021 credential
@@ -266,17 +298,9 @@ func ValidateCredentialInStatusList(credentialToValidate credential.VerifiableCr
 	// NOTE: this step is assumed to be done *external* to this method call
 
 	// 4. Verify that the status purpose matches the statusPurpose value in the statusListCredential.
-	var statusCredentialValue StatusList2021Credential
-	subjectBytes, err := json.Marshal(statusCredential.CredentialSubject)
+	statusCredentialValue, err := getStatusListCredentialSubject(statusCredential)
 	if err != nil {
-		return false, errors.Wrapf(err, "could not marshal status credential<%s> subject value", statusCredential.ID)
-	}
-	if err = json.Unmarshal(subjectBytes, &statusCredentialValue); err != nil {
-		return false, errors.Wrapf(err, "could not unmarshal status credential<%s> subject value into "+
-			"StatusList2021Credential", statusCredential.ID)
-	}
-	if err = util.IsValidStruct(statusCredentialValue); err != nil {
-		return false, errors.Wrapf(err, "credential<%s> is not a valid status credential", statusCredential.ID)
+		return false, err
 	}
 	if statusPurpose != statusCredentialValue.StatusPurpose {
 		return false, fmt.Errorf("purpose of credential to validate<%s>: %s, did not match purpose of status "+
@@ -326,3 +350,147 @@ func toStatusList2021Entry(credStatus any) (*StatusList2021Entry, bool) {
 
 	return &statusListEntry, true
 }
+
+// NewStatusList2021Entry creates the credentialStatus entry of a credential which is tracked at the given index of
+// the status list credential hosted at statusListCredential
+// https://w3c-ccg.github.io/vc-status-list-2021/#statuslist2021entry
+func NewStatusList2021Entry(statusListCredential string, index int, purpose StatusPurpose) (*StatusList2021Entry, error) {
+	if statusListCredential == "" {
+		return nil, errors.New("status list credential cannot be empty")
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("invalid status list index value, not a valid positive integer: %d", index)
+	}
+	if purpose != StatusRevocation && purpose != StatusSuspension {
+		return nil, fmt.Errorf("unsupported status purpose: %s", purpose)
+	}
+	statusListIndex := strconv.Itoa(index)
+	return &StatusList2021Entry{
+		ID:                   statusListCredential + "#" + statusListIndex,
+		Type:                 StatusList2021EntryType,
+		StatusPurpose:        purpose,
+		StatusListIndex:      statusListIndex,
+		StatusListCredential: statusListCredential,
+	}, nil
+}
+
+// SetStatusList2021Entry sets a StatusList2021Entry as the credentialStatus of a credential being issued, adding the
+// status list context so the entry is covered by a Data Integrity proof
+func SetStatusList2021Entry(builder *credential.VerifiableCredentialBuilder, entry StatusList2021Entry) error {
+	if err := util.IsValidStruct(entry); err != nil {
+		return errors.Wrap(err, "invalid status list entry")
+	}
+	if err := builder.AddContext(StatusList2021Context); err != nil {
+		return errors.Wrap(err, "adding status list context")
+	}
+	if err := builder.SetCredentialStatus(entry); err != nil {
+		return errors.Wrap(err, "setting credential status")
+	}
+	return nil
+}
+
+// SignStatusList2021Credential adds a Data Integrity proof to a status list credential. Status list credentials may
+// instead be secured as JWTs using integrity.SignVerifiableCredentialJWT.
+func SignStatusList2021Credential(suite cryptosuite.CryptoSuite, signer cryptosuite.Signer, statusListCredential *credential.VerifiableCredential, opts ...cryptosuite.Option) error {
+	if statusListCredential == nil || statusListCredential.IsEmpty() {
+		return errors.New("status list credential cannot be empty")
+	}
+	if _, err := getStatusListCredentialSubject(*statusListCredential); err != nil {
+		return err
+	}
+	if err := suite.Sign(signer, statusListCredential, opts...); err != nil {
+		return errors.Wrapf(err, "signing status list credential<%s>", statusListCredential.ID)
+	}
+	return nil
+}
+
+// CheckStatusList2021 fetches the status list credential referenced by a credential's StatusList2021Entry, verifies
+// its signature using the resolver and that it has the credential's issuer, and returns whether the credential's
+// status is set, that is whether the credential has been revoked or suspended according to the entry's status purpose.
+// NOTE: this method does not verify the signature of the credential being checked
+func CheckStatusList2021(ctx context.Context, cred credential.VerifiableCredential, access StatusListAccess, r resolution.Resolver) (bool, error) {
+	if access == nil {
+		return false, errors.New("status list access cannot be empty")
+	}
+	if r == nil {
+		return false, errors.New("resolution cannot be empty")
+	}
+	entry, err := getStatusEntry(cred.CredentialStatus)
+	if err != nil {
+		return false, errors.Wrapf(err, "credential<%s> not using the StatusList2021 credentialStatus property", cred.ID)
+	}
+
+	statusListCredential, err := fetchStatusListCredential(ctx, entry.StatusListCredential, access, r)
+	if err != nil {
+		return false, err
+	}
+	if err = checkStatusListIssuer(cred, *statusListCredential); err != nil {
+		return false, err
+	}
+	return ValidateCredentialInStatusList(cred, *statusListCredential)
+}
+
+// fetchStatusListCredential fetches the status list credential at the given URL and verifies its signature
+func fetchStatusListCredential(ctx context.Context, url string, access StatusListAccess, r resolution.Resolver) (*credential.VerifiableCredential, error) {
+	statusListCredentialBytes, err := access.GetStatusListCredential(ctx, url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching status list credential<%s>", url)
+	}
+	verified, err := integrity.VerifyCredentialSignature(ctx, statusListCredentialBytes, r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "verifying status list credential<%s>", url)
+	}
+	if !verified {
+		return nil, fmt.Errorf("status list credential<%s> could not be verified", url)
+	}
+	statusListCredential, err := parseStatusListCredential(statusListCredentialBytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing status list credential<%s>", url)
+	}
+	if statusListCredential.ID != "" && statusListCredential.ID != url {
+		return nil, fmt.Errorf("status list credential<%s> does not match the credential's status list credential<%s>",
+			statusListCredential.ID, url)
+	}
+	return statusListCredential, nil
+}
+
+// checkStatusListIssuer makes sure a status list credential was issued by the issuer of the credential whose status
+// it lists, so that no one else can revoke or suspend the credential
+func checkStatusListIssuer(cred, statusListCredential credential.VerifiableCredential) error {
+	issuer := cred.IssuerID()
+	if issuer == "" || statusListCredential.IssuerID() != issuer {
+		return fmt.Errorf("status list credential<%s> issuer<%s> is not the issuer<%s> of credential<%s>",
+			statusListCredential.ID, statusListCredential.IssuerID(), issuer, cred.ID)
+	}
+	return nil
+}
+
+// parseStatusListCredential parses a status list credential which is either a JSON credential or a JWT
+func parseStatusListCredential(data []byte) (*credential.VerifiableCredential, error) {
+	var cred credential.VerifiableCredential
+	if err := json.Unmarshal(data, &cred); err == nil {
+		return &cred, nil
+	}
+	_, _, jwtCred, err := integrity.ParseVerifiableCredentialFromJWT(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, err
+	}
+	return jwtCred, nil
+}
+
+// getStatusListCredentialSubject returns the subject of a status list credential, making sure it is valid
+func getStatusListCredentialSubject(statusListCredential credential.VerifiableCredential) (*StatusList2021Credential, error) {
+	var subject StatusList2021Credential
+	subjectBytes, err := json.Marshal(statusListCredential.CredentialSubject)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not marshal status credential<%s> subject value", statusListCredential.ID)
+	}
+	if err = json.Unmarshal(subjectBytes, &subject); err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal status credential<%s> subject value into "+
+			"StatusList2021Credential", statusListCredential.ID)
+	}
+	if err = util.IsValidStruct(subject); err != nil {
+		return nil, errors.Wrapf(err, "credential<%s> is not a valid status credential", statusListCredential.ID)
+	}
+	return &subject, nil
+}
@@ -1,13 +1,26 @@
 package status
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
 	"sort"
 	"testing"
 
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
 )
 
 func TestGenerateStatusList2021Credential(t *testing.T) {
@@ -434,4 +447,210 @@ func TestBitstringGenerationAndExpansion(t *testing.T) {
 		assert.Contains(tt, err.Error(), "duplicate status list index value found: 2")
 		assert.Empty(tt, bitString)
 	})
+
+	t.Run("spec encoding", func(tt *testing.T) {
+		// the left-most bit of the bitstring is the status at index 0
+		list, err := NewBitstringStatusList(0, 1)
+		require.NoError(tt, err)
+		require.NoError(tt, list.SetStatus(0, 1))
+		require.NoError(tt, list.SetStatus(94567, 1))
+		encoded, err := list.Encode()
+		require.NoError(tt, err)
+		expanded, err := bitstringExpansion(encoded[1:])
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{"0", "94567"}, expanded)
+
+		generated, err := bitstringGeneration([]string{"9", "131071"})
+		require.NoError(tt, err)
+		decoded, err := DecodeBitstringStatusList(string(multibaseBase64URL)+generated, 1)
+		require.NoError(tt, err)
+		assert.Equal(tt, BitstringStatusListMinimumBits, decoded.Len())
+		assert.Equal(tt, byte(0x40), decoded.bits[1])
+		status, err := decoded.GetStatus(131071)
+		assert.NoError(tt, err)
+		assert.Equal(tt, uint64(1), status)
+
+		_, err = bitstringGeneration([]string{"1", "134217728"})
+		assert.ErrorContains(tt, err, "status list index value exceeds the maximum length")
+	})
+
+	t.Run("oversized bitstring", func(tt *testing.T) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write(make([]byte, maxStatusListLength+1))
+		require.NoError(tt, err)
+		require.NoError(tt, zw.Close())
+
+		_, err = bitstringExpansion(base64.StdEncoding.EncodeToString(buf.Bytes()))
+		assert.ErrorContains(tt, err, "status list bitstring exceeds the maximum length")
+	})
+}
+
+func TestStatusList2021Lifecycle(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	_, privKeyJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, privKey)
+	require.NoError(t, err)
+	signer, err := jws2020.NewJSONWebKeySigner(kid, *privKeyJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+	suite := jws2020.GetJSONWebSignature2020Suite()
+
+	statusListURL := "https://example.com/status/1"
+	issue := func(tt *testing.T, index int) credential.VerifiableCredential {
+		builder := credential.NewVerifiableCredentialBuilder(credential.GenerateIDValue)
+		require.NoError(tt, builder.SetIssuer(didKey.String()))
+		require.NoError(tt, builder.SetIssuanceDate("2021-01-01T19:23:24Z"))
+		require.NoError(tt, builder.SetCredentialSubject(map[string]any{"id": "did:example:456"}))
+		entry, err := NewStatusList2021Entry(statusListURL, index, StatusRevocation)
+		require.NoError(tt, err)
+		require.NoError(tt, SetStatusList2021Entry(&builder, *entry))
+		cred, err := builder.Build()
+		require.NoError(tt, err)
+		return *cred
+	}
+	revoked := issue(t, 42)
+	active := issue(t, 7)
+	assert.Contains(t, revoked.Context, StatusList2021Context)
+	assert.Equal(t, statusListURL+"#42", revoked.CredentialStatus.(map[string]any)["id"])
+
+	statusListCredential, err := GenerateStatusList2021Credential(statusListURL, didKey.String(), StatusRevocation, []credential.VerifiableCredential{revoked})
+	require.NoError(t, err)
+	require.NoError(t, SignStatusList2021Credential(suite, signer, statusListCredential))
+	statusListCredentialBytes, err := json.Marshal(statusListCredential)
+	require.NoError(t, err)
+
+	t.Run("data integrity status list", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Times(2).Reply(200).BodyString(string(statusListCredentialBytes))
+
+		isRevoked, err := CheckStatusList2021(context.Background(), revoked, NewRemoteAccess(), resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, isRevoked)
+
+		isRevoked, err = CheckStatusList2021(context.Background(), active, NewRemoteAccess(), resolver)
+		assert.NoError(tt, err)
+		assert.False(tt, isRevoked)
+	})
+
+	t.Run("oversized status list credential", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Reply(200).BodyString(string(make([]byte, maxStatusListCredentialSize+1)))
+
+		_, err := CheckStatusList2021(context.Background(), revoked, NewRemoteAccess(), resolver)
+		assert.ErrorContains(tt, err, "status list credential exceeds the maximum size")
+	})
+
+	t.Run("verify with status check", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Times(3).Reply(200).BodyString(string(statusListCredentialBytes))
+
+		checker, err := NewChecker(NewRemoteAccess(), resolver)
+		require.NoError(tt, err)
+
+		signedRevoked := revoked
+		require.NoError(tt, suite.Sign(signer, &signedRevoked))
+		verified, err := integrity.VerifyCredentialSignature(context.Background(), &signedRevoked, resolver, integrity.WithStatusCheck(checker))
+		assert.ErrorContains(tt, err, "status is set in its revocation status list")
+		assert.False(tt, verified)
+
+		signedActive := active
+		require.NoError(tt, suite.Sign(signer, &signedActive))
+		verified, err = integrity.VerifyCredentialSignature(context.Background(), &signedActive, resolver, integrity.WithStatusCheck(checker))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		jwtSigner, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+		require.NoError(tt, err)
+		token, err := integrity.SignVerifiableCredentialJWT(*jwtSigner, revoked)
+		require.NoError(tt, err)
+		verified, err = integrity.VerifyJWTCredential(context.Background(), string(token), resolver, integrity.WithStatusCheck(checker))
+		assert.ErrorContains(tt, err, "status is set in its revocation status list")
+		assert.False(tt, verified)
+
+		// without the option, the status is not checked
+		verified, err = integrity.VerifyJWTCredential(context.Background(), string(token), resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+	})
+
+	t.Run("jwt status list", func(tt *testing.T) {
+		unsigned, err := GenerateStatusList2021Credential(statusListURL, didKey.String(), StatusRevocation, []credential.VerifiableCredential{revoked})
+		require.NoError(tt, err)
+		jwtSigner, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+		require.NoError(tt, err)
+		token, err := integrity.SignVerifiableCredentialJWT(*jwtSigner, *unsigned)
+		require.NoError(tt, err)
+
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Reply(200).BodyString(string(token))
+
+		isRevoked, err := CheckStatusList2021(context.Background(), revoked, NewRemoteAccess(), resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, isRevoked)
+	})
+
+	t.Run("tampered status list", func(tt *testing.T) {
+		tampered := *statusListCredential
+		tampered.Issuer = "did:example:789"
+		tamperedBytes, err := json.Marshal(tampered)
+		require.NoError(tt, err)
+
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Reply(200).BodyString(string(tamperedBytes))
+
+		_, err = CheckStatusList2021(context.Background(), revoked, NewRemoteAccess(), resolver)
+		assert.ErrorContains(tt, err, "verifying status list credential<https://example.com/status/1>")
+	})
+
+	t.Run("status list of another issuer", func(tt *testing.T) {
+		otherPrivKey, otherDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		otherExpanded, err := otherDIDKey.Expand()
+		require.NoError(tt, err)
+		otherKID := otherExpanded.VerificationMethod[0].ID
+		_, otherPrivKeyJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&otherKID, otherPrivKey)
+		require.NoError(tt, err)
+		otherSigner, err := jws2020.NewJSONWebKeySigner(otherKID, *otherPrivKeyJWK, cryptosuite.AssertionMethod)
+		require.NoError(tt, err)
+
+		otherStatusList, err := GenerateStatusList2021Credential(statusListURL, otherDIDKey.String(), StatusRevocation, []credential.VerifiableCredential{active})
+		require.NoError(tt, err)
+		require.NoError(tt, SignStatusList2021Credential(suite, otherSigner, otherStatusList))
+		otherStatusListBytes, err := json.Marshal(otherStatusList)
+		require.NoError(tt, err)
+
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Reply(200).BodyString(string(otherStatusListBytes))
+
+		_, err = CheckStatusList2021(context.Background(), active, NewRemoteAccess(), resolver)
+		assert.ErrorContains(tt, err, "is not the issuer<"+didKey.String()+">")
+	})
+
+	t.Run("status list unavailable", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Reply(404)
+
+		_, err := CheckStatusList2021(context.Background(), revoked, NewRemoteAccess(), resolver)
+		assert.ErrorContains(tt, err, "status code: 404")
+	})
+
+	t.Run("invalid entries", func(tt *testing.T) {
+		_, err := NewStatusList2021Entry("", 1, StatusRevocation)
+		assert.ErrorContains(tt, err, "status list credential cannot be empty")
+
+		_, err = NewStatusList2021Entry(statusListURL, -1, StatusRevocation)
+		assert.ErrorContains(tt, err, "not a valid positive integer: -1")
+
+		_, err = NewStatusList2021Entry(statusListURL, 1, "refresh")
+		assert.ErrorContains(tt, err, "unsupported status purpose: refresh")
+
+		_, err = CheckStatusList2021(context.Background(), credential.VerifiableCredential{ID: "no-status"}, NewRemoteAccess(), resolver)
+		assert.ErrorContains(tt, err, "credential<no-status> not using the StatusList2021 credentialStatus property")
+	})
 }
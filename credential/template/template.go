@@ -0,0 +1,237 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	credschema "github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// placeholderRegex matches placeholders in claim mappings, such as {{givenName}}
+var placeholderRegex = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.\-]+)\s*\}\}`)
+
+// Template defines the credentials an issuer issues of a given kind, which are instantiated from claim maps.
+// Claim values are mapped into the credential subject using placeholders: a string value which is a single
+// placeholder, such as "{{age}}", is replaced with the claim's value as is, while placeholders within a longer string,
+// such as "{{givenName}} {{familyName}}", are replaced with the claims' values as strings.
+type Template struct {
+	ID string `json:"id" validate:"required"`
+	// Contexts are added after the base context
+	Contexts []string `json:"contexts,omitempty"`
+	// Types are added after the VerifiableCredential type
+	Types []string `json:"types" validate:"required,min=1"`
+	// Schema is the VC JSON Schema credentials are validated against before they are returned, and which is
+	// referenced by their credentialSchema property
+	Schema credschema.VCJSONSchema `json:"schema,omitempty"`
+	// SchemaType is the type of the schema, defaulting to JsonSchema
+	SchemaType credschema.VCJSONSchemaType `json:"schemaType,omitempty"`
+	// Claims map claim values, through placeholders, to the credential subject
+	Claims map[string]any `json:"claims" validate:"required"`
+	// Validity is how long credentials are valid for after they are issued; if zero they do not expire
+	Validity time.Duration `json:"validity,omitempty"`
+}
+
+// IsValid checks a template has the required properties, and that its schema is identifiable if it has one
+func (t Template) IsValid() error {
+	if err := util.IsValidStruct(t); err != nil {
+		return errors.Wrapf(err, "template<%s> is not valid", t.ID)
+	}
+	if len(t.Schema) > 0 {
+		if _, err := t.credentialSchema(); err != nil {
+			return errors.Wrapf(err, "template<%s> schema is not valid", t.ID)
+		}
+	}
+	return nil
+}
+
+// Placeholders returns the names of the claims the template maps into credentials, sorted
+func (t Template) Placeholders() []string {
+	seen := make(map[string]bool)
+	walkStrings(t.Claims, func(s string) {
+		for _, match := range placeholderRegex.FindAllStringSubmatch(s, -1) {
+			seen[match[1]] = true
+		}
+	})
+	placeholders := make([]string, 0, len(seen))
+	for name := range seen {
+		placeholders = append(placeholders, name)
+	}
+	sort.Strings(placeholders)
+	return placeholders
+}
+
+// Instantiate creates a credential from the template about the given subject, mapping the given claims into its
+// credential subject. The credential is validated against the template's schema, if it has one, and is returned
+// without a proof, ready to be signed.
+func (t Template) Instantiate(issuer, subjectID string, claims map[string]any) (*credential.VerifiableCredential, error) {
+	if err := t.IsValid(); err != nil {
+		return nil, err
+	}
+	if issuer == "" {
+		return nil, errors.New("issuer cannot be empty")
+	}
+
+	subject, err := fillClaims(t.Claims, claims)
+	if err != nil {
+		return nil, errors.Wrapf(err, "mapping claims with template<%s>", t.ID)
+	}
+	subjectMap, ok := subject.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("template<%s> claims must be an object", t.ID)
+	}
+	if subjectID != "" {
+		subjectMap[credential.VerifiableCredentialIDProperty] = subjectID
+	}
+
+	builder := credential.NewVerifiableCredentialBuilder(credential.GenerateIDValue)
+	for _, c := range t.Contexts {
+		if err = builder.AddContext(c); err != nil {
+			return nil, errors.Wrapf(err, "adding context<%s>", c)
+		}
+	}
+	for _, typ := range t.Types {
+		if err = builder.AddType(typ); err != nil {
+			return nil, errors.Wrapf(err, "adding type<%s>", typ)
+		}
+	}
+	if err = builder.SetIssuer(issuer); err != nil {
+		return nil, errors.Wrap(err, "setting issuer")
+	}
+	if err = builder.SetCredentialSubject(subjectMap); err != nil {
+		return nil, errors.Wrap(err, "setting credential subject")
+	}
+	if t.Validity > 0 {
+		if err = builder.SetExpirationDate(util.AsRFC3339Timestamp(time.Now().Add(t.Validity))); err != nil {
+			return nil, errors.Wrap(err, "setting expiration date")
+		}
+	}
+	if len(t.Schema) > 0 {
+		credSchema, err := t.credentialSchema()
+		if err != nil {
+			return nil, err
+		}
+		if err = builder.SetCredentialSchema(*credSchema); err != nil {
+			return nil, errors.Wrap(err, "setting credential schema")
+		}
+	}
+	cred, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrapf(err, "building credential from template<%s>", t.ID)
+	}
+
+	if len(t.Schema) > 0 {
+		if err = credschema.IsCredentialValidForJSONSchema(*cred, t.Schema, t.schemaType()); err != nil {
+			return nil, errors.Wrapf(err, "credential from template<%s> is not valid for its schema", t.ID)
+		}
+	}
+	return cred, nil
+}
+
+func (t Template) schemaType() credschema.VCJSONSchemaType {
+	if t.SchemaType == "" {
+		return credschema.JSONSchemaType
+	}
+	return t.SchemaType
+}
+
+// credentialSchema returns the credentialSchema property referencing the template's schema
+func (t Template) credentialSchema() (*credential.CredentialSchema, error) {
+	schemaType := t.schemaType()
+	var id string
+	switch schemaType {
+	case credschema.JSONSchemaType:
+		id = credschema.JSONSchema(t.Schema).ID()
+	case credschema.JSONSchemaCredentialType:
+		id, _ = t.Schema[credential.VerifiableCredentialIDProperty].(string)
+	default:
+		return nil, fmt.Errorf("schema type<%s> is not supported", schemaType)
+	}
+	if id == "" {
+		return nil, errors.New("schema does not have an id")
+	}
+	return &credential.CredentialSchema{ID: id, Type: schemaType.String()}, nil
+}
+
+// fillClaims replaces the placeholders in a claim mapping with the values of the given claims
+func fillClaims(mapping any, claims map[string]any) (any, error) {
+	switch m := mapping.(type) {
+	case map[string]any:
+		filled := make(map[string]any, len(m))
+		for k, v := range m {
+			value, err := fillClaims(v, claims)
+			if err != nil {
+				return nil, err
+			}
+			filled[k] = value
+		}
+		return filled, nil
+	case []any:
+		filled := make([]any, 0, len(m))
+		for _, v := range m {
+			value, err := fillClaims(v, claims)
+			if err != nil {
+				return nil, err
+			}
+			filled = append(filled, value)
+		}
+		return filled, nil
+	case string:
+		return fillString(m, claims)
+	default:
+		return mapping, nil
+	}
+}
+
+// fillString replaces the placeholders in a string. A string which is only a placeholder is replaced with the
+// claim's value, keeping its type.
+func fillString(s string, claims map[string]any) (any, error) {
+	matches := placeholderRegex.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		name := s[matches[0][2]:matches[0][3]]
+		value, ok := claims[name]
+		if !ok {
+			return nil, fmt.Errorf("claim<%s> not provided", name)
+		}
+		return value, nil
+	}
+	var missing []string
+	filled := placeholderRegex.ReplaceAllStringFunc(s, func(placeholder string) string {
+		name := placeholderRegex.FindStringSubmatch(placeholder)[1]
+		value, ok := claims[name]
+		if !ok {
+			missing = append(missing, name)
+			return placeholder
+		}
+		return fmt.Sprint(value)
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("claim(s) not provided: %s", strings.Join(missing, ", "))
+	}
+	return filled, nil
+}
+
+// walkStrings calls found with each string value in a claim mapping
+func walkStrings(mapping any, found func(s string)) {
+	switch m := mapping.(type) {
+	case map[string]any:
+		for _, v := range m {
+			walkStrings(v, found)
+		}
+	case []any:
+		for _, v := range m {
+			walkStrings(v, found)
+		}
+	case string:
+		found(m)
+	}
+}
@@ -0,0 +1,121 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	credschema "github.com/TBD54566975/ssi-sdk/credential/schema"
+)
+
+func TestTemplate(t *testing.T) {
+	getTemplate := func() Template {
+		return Template{
+			ID:       "university-degree",
+			Contexts: []string{"https://www.w3.org/2018/credentials/examples/v1"},
+			Types:    []string{"UniversityDegreeCredential"},
+			Schema: credschema.VCJSONSchema{
+				"$id":     "https://example.com/schemas/degree.json",
+				"$schema": credschema.Draft202012.String(),
+				"type":    "object",
+				"properties": map[string]any{
+					"credentialSubject": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"name": map[string]any{"type": "string"},
+							"degree": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"type": map[string]any{"type": "string"},
+									"year": map[string]any{"type": "integer"},
+								},
+								"required": []any{"type", "year"},
+							},
+						},
+						"required": []any{"name", "degree"},
+					},
+				},
+			},
+			Claims: map[string]any{
+				"name": "{{givenName}} {{familyName}}",
+				"degree": map[string]any{
+					"type": "{{degreeType}}",
+					"year": "{{ year }}",
+				},
+			},
+			Validity: 24 * time.Hour,
+		}
+	}
+	claims := map[string]any{
+		"givenName":  "Alice",
+		"familyName": "Smith",
+		"degreeType": "BachelorDegree",
+		"year":       2023,
+	}
+
+	t.Run("instantiate credential", func(tt *testing.T) {
+		tmpl := getTemplate()
+		assert.NoError(tt, tmpl.IsValid())
+		assert.Equal(tt, []string{"degreeType", "familyName", "givenName", "year"}, tmpl.Placeholders())
+
+		cred, err := tmpl.Instantiate("did:example:issuer", "did:example:subject", claims)
+		assert.NoError(tt, err)
+		require.NotNil(tt, cred)
+		assert.NotEmpty(tt, cred.ID)
+		assert.Equal(tt, []string{credential.VerifiableCredentialsLinkedDataContext, "https://www.w3.org/2018/credentials/examples/v1"}, cred.Context)
+		assert.Equal(tt, []string{credential.VerifiableCredentialType, "UniversityDegreeCredential"}, cred.Type)
+		assert.Equal(tt, "did:example:issuer", cred.IssuerID())
+		assert.NotEmpty(tt, cred.ExpirationDate)
+		assert.Nil(tt, cred.Proof)
+		assert.Equal(tt, &credential.CredentialSchema{ID: "https://example.com/schemas/degree.json", Type: credschema.JSONSchemaType.String()}, cred.CredentialSchema)
+		assert.Equal(tt, credential.CredentialSubject{
+			"id":     "did:example:subject",
+			"name":   "Alice Smith",
+			"degree": map[string]any{"type": "BachelorDegree", "year": 2023},
+		}, cred.CredentialSubject)
+
+		// the template is not modified
+		assert.Equal(tt, "{{degreeType}}", tmpl.Claims["degree"].(map[string]any)["type"])
+	})
+
+	t.Run("missing claims", func(tt *testing.T) {
+		_, err := getTemplate().Instantiate("did:example:issuer", "", map[string]any{"degreeType": "BachelorDegree", "year": 2023})
+		assert.ErrorContains(tt, err, "claim(s) not provided: givenName, familyName")
+
+		_, err = getTemplate().Instantiate("did:example:issuer", "", map[string]any{"givenName": "Alice", "familyName": "Smith", "degreeType": "BachelorDegree"})
+		assert.ErrorContains(tt, err, "claim<year> not provided")
+	})
+
+	t.Run("claims not valid for schema", func(tt *testing.T) {
+		invalidClaims := map[string]any{"givenName": "Alice", "familyName": "Smith", "degreeType": "BachelorDegree", "year": "2023"}
+		_, err := getTemplate().Instantiate("did:example:issuer", "", invalidClaims)
+		assert.ErrorContains(tt, err, "credential from template<university-degree> is not valid for its schema")
+	})
+
+	t.Run("without schema", func(tt *testing.T) {
+		tmpl := getTemplate()
+		tmpl.Schema = nil
+		cred, err := tmpl.Instantiate("did:example:issuer", "", map[string]any{"givenName": "Alice", "familyName": "Smith", "degreeType": "BachelorDegree", "year": "2023"})
+		assert.NoError(tt, err)
+		require.NotNil(tt, cred)
+		assert.Nil(tt, cred.CredentialSchema)
+		assert.NotContains(tt, cred.CredentialSubject, "id")
+	})
+
+	t.Run("invalid template", func(tt *testing.T) {
+		tmpl := getTemplate()
+		tmpl.Types = nil
+		_, err := tmpl.Instantiate("did:example:issuer", "", claims)
+		assert.ErrorContains(tt, err, "template<university-degree> is not valid")
+
+		tmpl = getTemplate()
+		delete(tmpl.Schema, "$id")
+		assert.ErrorContains(tt, tmpl.IsValid(), "schema does not have an id")
+
+		_, err = getTemplate().Instantiate("", "", claims)
+		assert.ErrorContains(tt, err, "issuer cannot be empty")
+	})
+}
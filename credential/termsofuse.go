@@ -0,0 +1,48 @@
+package credential
+
+const (
+	// IssuerPolicyType terms of use set by the issuer of a credential
+	// https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#terms-of-use
+	IssuerPolicyType string = "IssuerPolicy"
+	// HolderPolicyType terms of use set by the holder of a presentation
+	HolderPolicyType string = "HolderPolicy"
+
+	// AllVerifiersAssignee is the assignee of a rule which applies to any verifier
+	AllVerifiersAssignee string = "AllVerifiers"
+)
+
+// NewIssuerPolicy returns terms of use set by a credential's issuer, restricting how the credential may be used
+func NewIssuerPolicy(id, profile string, prohibitions ...Prohibition) TermsOfUse {
+	return TermsOfUse{
+		Type:        IssuerPolicyType,
+		ID:          id,
+		Profile:     profile,
+		Prohibition: prohibitions,
+	}
+}
+
+// NewHolderPolicy returns terms of use set by a presentation's holder, restricting how the presentation may be used
+func NewHolderPolicy(id, profile string, prohibitions ...Prohibition) TermsOfUse {
+	return TermsOfUse{
+		Type:        HolderPolicyType,
+		ID:          id,
+		Profile:     profile,
+		Prohibition: prohibitions,
+	}
+}
+
+// Prohibits returns whether the terms of use prohibit the given assignee, e.g. a verifier, from taking the given
+// action. Prohibitions without an assignee, or assigned to all verifiers, apply to any assignee.
+func (t TermsOfUse) Prohibits(assignee, action string) bool {
+	for _, prohibition := range t.Prohibition {
+		if prohibition.Assignee != "" && prohibition.Assignee != AllVerifiersAssignee && prohibition.Assignee != assignee {
+			continue
+		}
+		for _, prohibitedAction := range prohibition.Action {
+			if prohibitedAction == action {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package credential
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermsOfUse(t *testing.T) {
+	t.Run("issuer policy from JSON", func(tt *testing.T) {
+		termsJSON := `{
+  "type": "IssuerPolicy",
+  "id": "http://example.com/policies/credential/4",
+  "profile": "http://example.com/profiles/credential",
+  "prohibition": [{
+    "assigner": "https://example.edu/issuers/14",
+    "assignee": "AllVerifiers",
+    "target": "http://example.edu/credentials/3732",
+    "action": ["Archival"]
+  }]
+}`
+		var terms TermsOfUse
+		require.NoError(tt, json.Unmarshal([]byte(termsJSON), &terms))
+		assert.Equal(tt, IssuerPolicyType, terms.Type)
+		assert.True(tt, terms.Prohibits("did:example:verifier", "Archival"))
+		assert.False(tt, terms.Prohibits("did:example:verifier", "3rdPartyCorrelation"))
+	})
+
+	t.Run("holder policy", func(tt *testing.T) {
+		terms := NewHolderPolicy("http://example.com/policies/presentation/1", "", Prohibition{
+			Assignee: "did:example:verifier",
+			Action:   []string{"3rdPartyCorrelation"},
+		})
+		assert.Equal(tt, HolderPolicyType, terms.Type)
+		assert.True(tt, terms.Prohibits("did:example:verifier", "3rdPartyCorrelation"))
+		// the prohibition is only assigned to one verifier
+		assert.False(tt, terms.Prohibits("did:example:other", "3rdPartyCorrelation"))
+
+		builder := NewVerifiablePresentationBuilder()
+		err := builder.SetTermsOfUse(nil)
+		assert.ErrorContains(tt, err, "terms of use cannot be empty")
+		require.NoError(tt, builder.SetTermsOfUse([]TermsOfUse{terms}))
+		pres, err := builder.Build()
+		assert.NoError(tt, err)
+		assert.Equal(tt, []TermsOfUse{terms}, pres.TermsOfUse)
+	})
+
+	t.Run("terms must have a type", func(tt *testing.T) {
+		builder := NewVerifiablePresentationBuilder()
+		require.NoError(tt, builder.SetTermsOfUse([]TermsOfUse{{ID: "http://example.com/policies/presentation/1"}}))
+		_, err := builder.Build()
+		assert.ErrorContains(tt, err, "presentation not ready to be built")
+	})
+}
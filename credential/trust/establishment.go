@@ -0,0 +1,107 @@
+package trust
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// TrustEstablishmentDocument is a DIF Trust Establishment document, in which an author lists the entities it trusts
+// for each of a number of topics. Each topic is identified by the URI of a JSON schema which the entries listed under
+// it conform to, and each entry is keyed by the DID of the entity it describes.
+// https://identity.foundation/trust-establishment/
+type TrustEstablishmentDocument struct {
+	ID         string                               `json:"id" validate:"required"`
+	Author     string                               `json:"author" validate:"required"`
+	Created    string                               `json:"created" validate:"required"`
+	Version    string                               `json:"version,omitempty"`
+	ValidFrom  string                               `json:"validFrom,omitempty"`
+	ValidUntil string                               `json:"validUntil,omitempty"`
+	Entries    map[string]map[string]map[string]any `json:"entries" validate:"required"`
+}
+
+// IsValid checks the document has its required properties and that its validity period can be parsed
+func (d TrustEstablishmentDocument) IsValid() error {
+	if err := util.IsValidStruct(d); err != nil {
+		return errors.Wrapf(err, "trust establishment document<%s> is not valid", d.ID)
+	}
+	for _, date := range []string{d.Created, d.ValidFrom, d.ValidUntil} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, date); err != nil {
+			return errors.Wrapf(err, "trust establishment document<%s> has an invalid date", d.ID)
+		}
+	}
+	return nil
+}
+
+// IsCurrent returns whether the document is within its validity period at the given time
+func (d TrustEstablishmentDocument) IsCurrent(at time.Time) bool {
+	if validFrom, err := time.Parse(time.RFC3339, d.ValidFrom); err == nil && at.Before(validFrom) {
+		return false
+	}
+	if validUntil, err := time.Parse(time.RFC3339, d.ValidUntil); err == nil && at.After(validUntil) {
+		return false
+	}
+	return true
+}
+
+// ParseTrustEstablishmentDocument parses and validates a JSON trust establishment document
+func ParseTrustEstablishmentDocument(data []byte) (*TrustEstablishmentDocument, error) {
+	var doc TrustEstablishmentDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling trust establishment document")
+	}
+	if err := doc.IsValid(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// TrustEstablishmentRegistry trusts the issuers listed in a trust establishment document. Each credential type is
+// mapped to the topic of the document whose entries are accredited to issue it.
+type TrustEstablishmentRegistry struct {
+	doc    TrustEstablishmentDocument
+	topics map[string]string
+}
+
+var _ TrustRegistry = (*TrustEstablishmentRegistry)(nil)
+
+// NewTrustEstablishmentRegistry returns a registry trusting the entries of the given document under the topic each
+// credential type is mapped to. A topic mapped to AnyCredentialType applies to every type without its own topic.
+func NewTrustEstablishmentRegistry(doc TrustEstablishmentDocument, topics map[string]string) (*TrustEstablishmentRegistry, error) {
+	if err := doc.IsValid(); err != nil {
+		return nil, err
+	}
+	if len(topics) == 0 {
+		return nil, errors.New("at least one credential type must be mapped to a topic")
+	}
+	for credType, topic := range topics {
+		if _, ok := doc.Entries[topic]; !ok {
+			return nil, fmt.Errorf("trust establishment document<%s> has no topic<%s> for type<%s>", doc.ID, topic, credType)
+		}
+	}
+	return &TrustEstablishmentRegistry{doc: doc, topics: topics}, nil
+}
+
+// IsTrusted returns whether the issuer DID has an entry under the topic of the given credential type. Issuers are
+// not trusted when the document is outside its validity period.
+func (t *TrustEstablishmentRegistry) IsTrusted(_ context.Context, issuer, credentialType string) (bool, error) {
+	if !t.doc.IsCurrent(time.Now()) {
+		return false, fmt.Errorf("trust establishment document<%s> is not within its validity period", t.doc.ID)
+	}
+	topic, ok := t.topics[credentialType]
+	if !ok {
+		if topic, ok = t.topics[AnyCredentialType]; !ok {
+			return false, nil
+		}
+	}
+	_, trusted := t.doc.Entries[topic][issuer]
+	return trusted, nil
+}
@@ -0,0 +1,115 @@
+package trust
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+// AnyCredentialType is used in place of a credential type to accredit an issuer for every type of credential
+const AnyCredentialType = "*"
+
+// TrustRegistry answers whether an issuer is accredited to issue a type of credential
+type TrustRegistry interface {
+	// IsTrusted returns whether the issuer DID is trusted to issue credentials of the given type
+	IsTrusted(ctx context.Context, issuer, credentialType string) (bool, error)
+}
+
+// IsIssuerTrusted returns whether a credential's issuer is trusted to issue it, which requires the issuer to be
+// trusted for each of the credential's types other than the base VerifiableCredential type. A credential with only
+// the base type requires the issuer to be trusted for that type.
+func IsIssuerTrusted(ctx context.Context, registry TrustRegistry, cred credential.VerifiableCredential) (bool, error) {
+	if registry == nil {
+		return false, errors.New("trust registry cannot be empty")
+	}
+	issuer := cred.IssuerID()
+	if issuer == "" {
+		return false, errors.New("credential does not have an issuer")
+	}
+	types, err := credentialTypes(cred)
+	if err != nil {
+		return false, err
+	}
+	for _, credType := range types {
+		trusted, err := registry.IsTrusted(ctx, issuer, credType)
+		if err != nil {
+			return false, errors.Wrapf(err, "checking issuer<%s> is trusted for type<%s>", issuer, credType)
+		}
+		if !trusted {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// credentialTypes returns the types of a credential an issuer must be trusted for
+func credentialTypes(cred credential.VerifiableCredential) ([]string, error) {
+	var types []string
+	switch t := cred.Type.(type) {
+	case string:
+		types = []string{t}
+	case []string:
+		types = t
+	case []any:
+		for _, v := range t {
+			s, ok := v.(string)
+			if !ok {
+				return nil, errors.New("credential type must be a string or array of strings")
+			}
+			types = append(types, s)
+		}
+	default:
+		return nil, errors.New("credential type must be a string or array of strings")
+	}
+	var specific []string
+	for _, t := range types {
+		if t != credential.VerifiableCredentialType {
+			specific = append(specific, t)
+		}
+	}
+	if len(specific) == 0 {
+		return []string{credential.VerifiableCredentialType}, nil
+	}
+	return specific, nil
+}
+
+// StaticTrustRegistry is a fixed list of issuers and the credential types each is accredited to issue
+type StaticTrustRegistry struct {
+	issuers map[string]map[string]bool
+}
+
+var _ TrustRegistry = (*StaticTrustRegistry)(nil)
+
+// NewStaticTrustRegistry returns a registry trusting each issuer DID to issue the credential types it is mapped to.
+// An issuer mapped to AnyCredentialType is trusted to issue every type of credential.
+func NewStaticTrustRegistry(issuers map[string][]string) *StaticTrustRegistry {
+	registry := StaticTrustRegistry{issuers: make(map[string]map[string]bool, len(issuers))}
+	for issuer, types := range issuers {
+		registry.Add(issuer, types...)
+	}
+	return &registry
+}
+
+// Add trusts the issuer DID to issue the given credential types. It is not safe to call concurrently with IsTrusted.
+func (s *StaticTrustRegistry) Add(issuer string, credentialTypes ...string) {
+	if s.issuers == nil {
+		s.issuers = make(map[string]map[string]bool)
+	}
+	if _, ok := s.issuers[issuer]; !ok {
+		s.issuers[issuer] = make(map[string]bool)
+	}
+	for _, credType := range credentialTypes {
+		s.issuers[issuer][credType] = true
+	}
+}
+
+// IsTrusted returns whether the issuer DID is trusted to issue credentials of the given type
+func (s *StaticTrustRegistry) IsTrusted(_ context.Context, issuer, credentialType string) (bool, error) {
+	types, ok := s.issuers[issuer]
+	if !ok {
+		return false, nil
+	}
+	return types[credentialType] || types[AnyCredentialType], nil
+}
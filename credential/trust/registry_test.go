@@ -0,0 +1,152 @@
+package trust
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+func TestStaticTrustRegistry(t *testing.T) {
+	registry := NewStaticTrustRegistry(map[string][]string{
+		"did:example:university": {"UniversityDegreeCredential"},
+		"did:example:government": {AnyCredentialType},
+	})
+	ctx := context.Background()
+
+	trusted, err := registry.IsTrusted(ctx, "did:example:university", "UniversityDegreeCredential")
+	assert.NoError(t, err)
+	assert.True(t, trusted)
+
+	trusted, err = registry.IsTrusted(ctx, "did:example:university", "DriversLicenseCredential")
+	assert.NoError(t, err)
+	assert.False(t, trusted)
+
+	trusted, err = registry.IsTrusted(ctx, "did:example:government", "DriversLicenseCredential")
+	assert.NoError(t, err)
+	assert.True(t, trusted)
+
+	trusted, err = registry.IsTrusted(ctx, "did:example:unknown", "UniversityDegreeCredential")
+	assert.NoError(t, err)
+	assert.False(t, trusted)
+
+	registry.Add("did:example:unknown", "UniversityDegreeCredential")
+	trusted, err = registry.IsTrusted(ctx, "did:example:unknown", "UniversityDegreeCredential")
+	assert.NoError(t, err)
+	assert.True(t, trusted)
+}
+
+func TestIsIssuerTrusted(t *testing.T) {
+	registry := NewStaticTrustRegistry(map[string][]string{
+		"did:example:university": {"UniversityDegreeCredential"},
+		"did:example:any":        {credential.VerifiableCredentialType},
+	})
+	ctx := context.Background()
+	cred := credential.VerifiableCredential{
+		Type:   []any{credential.VerifiableCredentialType, "UniversityDegreeCredential"},
+		Issuer: map[string]any{"id": "did:example:university", "name": "Example University"},
+	}
+
+	t.Run("trusted for every type", func(tt *testing.T) {
+		trusted, err := IsIssuerTrusted(ctx, registry, cred)
+		assert.NoError(tt, err)
+		assert.True(tt, trusted)
+	})
+
+	t.Run("not trusted for every type", func(tt *testing.T) {
+		multiType := cred
+		multiType.Type = []string{credential.VerifiableCredentialType, "UniversityDegreeCredential", "TranscriptCredential"}
+		trusted, err := IsIssuerTrusted(ctx, registry, multiType)
+		assert.NoError(tt, err)
+		assert.False(tt, trusted)
+	})
+
+	t.Run("base type only", func(tt *testing.T) {
+		baseType := cred
+		baseType.Type = credential.VerifiableCredentialType
+		baseType.Issuer = "did:example:any"
+		trusted, err := IsIssuerTrusted(ctx, registry, baseType)
+		assert.NoError(tt, err)
+		assert.True(tt, trusted)
+	})
+
+	t.Run("no issuer", func(tt *testing.T) {
+		noIssuer := cred
+		noIssuer.Issuer = nil
+		_, err := IsIssuerTrusted(ctx, registry, noIssuer)
+		assert.ErrorContains(tt, err, "credential does not have an issuer")
+	})
+}
+
+func TestTrustEstablishmentRegistry(t *testing.T) {
+	const degreeTopic = "https://example.com/schemas/accredited-university.json"
+	doc := TrustEstablishmentDocument{
+		ID:      "https://example.com/trust/education",
+		Author:  "did:example:ministry",
+		Created: "2023-01-01T00:00:00Z",
+		Version: "1.0",
+		Entries: map[string]map[string]map[string]any{
+			degreeTopic: {
+				"did:example:university": {"accreditedSince": "2020-01-01"},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	t.Run("parse", func(tt *testing.T) {
+		data, err := json.Marshal(doc)
+		require.NoError(tt, err)
+		parsed, err := ParseTrustEstablishmentDocument(data)
+		assert.NoError(tt, err)
+		assert.Equal(tt, doc, *parsed)
+
+		_, err = ParseTrustEstablishmentDocument([]byte(`{"id": "incomplete"}`))
+		assert.ErrorContains(tt, err, "trust establishment document<incomplete> is not valid")
+	})
+
+	t.Run("trusted entries", func(tt *testing.T) {
+		registry, err := NewTrustEstablishmentRegistry(doc, map[string]string{"UniversityDegreeCredential": degreeTopic})
+		require.NoError(tt, err)
+
+		trusted, err := registry.IsTrusted(ctx, "did:example:university", "UniversityDegreeCredential")
+		assert.NoError(tt, err)
+		assert.True(tt, trusted)
+
+		trusted, err = registry.IsTrusted(ctx, "did:example:diploma-mill", "UniversityDegreeCredential")
+		assert.NoError(tt, err)
+		assert.False(tt, trusted)
+
+		trusted, err = registry.IsTrusted(ctx, "did:example:university", "DriversLicenseCredential")
+		assert.NoError(tt, err)
+		assert.False(tt, trusted)
+	})
+
+	t.Run("any credential type", func(tt *testing.T) {
+		registry, err := NewTrustEstablishmentRegistry(doc, map[string]string{AnyCredentialType: degreeTopic})
+		require.NoError(tt, err)
+
+		trusted, err := registry.IsTrusted(ctx, "did:example:university", "TranscriptCredential")
+		assert.NoError(tt, err)
+		assert.True(tt, trusted)
+	})
+
+	t.Run("unknown topic", func(tt *testing.T) {
+		_, err := NewTrustEstablishmentRegistry(doc, map[string]string{"UniversityDegreeCredential": "https://example.com/unknown.json"})
+		assert.ErrorContains(tt, err, "has no topic<https://example.com/unknown.json>")
+	})
+
+	t.Run("expired document", func(tt *testing.T) {
+		expired := doc
+		expired.ValidUntil = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		registry, err := NewTrustEstablishmentRegistry(expired, map[string]string{"UniversityDegreeCredential": degreeTopic})
+		require.NoError(tt, err)
+
+		_, err = registry.IsTrusted(ctx, "did:example:university", "UniversityDegreeCredential")
+		assert.ErrorContains(tt, err, "is not within its validity period")
+	})
+}
@@ -0,0 +1,144 @@
+package credential
+
+import (
+	"reflect"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/schema"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// TypedCredential is a verifiable credential whose credentialSubject is a Go struct of type T, describing the claims
+// of the credential's schema, instead of a generic map. The embedded credential's CredentialSubject is not used.
+// A subject's id is read from and written to the property of T with the json name "id", if there is one.
+type TypedCredential[T any] struct {
+	VerifiableCredential
+	CredentialSubject T
+}
+
+// NewTypedCredential converts a credential into a typed credential, decoding its credentialSubject into T
+func NewTypedCredential[T any](cred VerifiableCredential) (*TypedCredential[T], error) {
+	if len(cred.AdditionalCredentialSubjects) > 0 {
+		return nil, errors.New("typed credentials must have a single subject")
+	}
+	subjectBytes, err := json.Marshal(cred.CredentialSubject)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential subject")
+	}
+	var subject T
+	if err = json.Unmarshal(subjectBytes, &subject); err != nil {
+		return nil, errors.Wrapf(err, "decoding credential subject into %T", subject)
+	}
+	cred.CredentialSubject = nil
+	return &TypedCredential[T]{VerifiableCredential: cred, CredentialSubject: subject}, nil
+}
+
+// ToCredential converts the typed credential into a credential, whose credentialSubject is the JSON encoding of T
+func (tc TypedCredential[T]) ToCredential() (*VerifiableCredential, error) {
+	subject, err := util.ToJSONMap(tc.CredentialSubject)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding credential subject")
+	}
+	cred := tc.VerifiableCredential
+	cred.CredentialSubject = subject
+	return &cred, nil
+}
+
+// IsValid converts the typed credential into a credential and checks it is valid
+func (tc TypedCredential[T]) IsValid() error {
+	cred, err := tc.ToCredential()
+	if err != nil {
+		return err
+	}
+	return cred.IsValid()
+}
+
+func (tc TypedCredential[T]) MarshalJSON() ([]byte, error) {
+	cred, err := tc.ToCredential()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cred)
+}
+
+func (tc *TypedCredential[T]) UnmarshalJSON(data []byte) error {
+	var cred VerifiableCredential
+	if err := json.Unmarshal(data, &cred); err != nil {
+		return err
+	}
+	typed, err := NewTypedCredential[T](cred)
+	if err != nil {
+		return err
+	}
+	*tc = *typed
+	return nil
+}
+
+// CredentialSubjectJSONSchema generates a JSON Schema for credentials whose credentialSubject is of type T, with the
+// given $id and name, which may be used as the credentialSchema of typed credentials https://www.w3.org/TR/vc-json-schema/
+func CredentialSubjectJSONSchema[T any](id, name string) (map[string]any, error) {
+	subjectSchema, err := schema.GenerateJSONSchema(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, errors.Wrap(err, "generating credential subject schema")
+	}
+	delete(subjectSchema, "$schema")
+	credSchema := map[string]any{
+		"$schema": schema.Draft202012,
+		"type":    "object",
+		"properties": map[string]any{
+			"credentialSubject": subjectSchema,
+		},
+		"required": []any{"credentialSubject"},
+	}
+	if id != "" {
+		credSchema["$id"] = id
+	}
+	if name != "" {
+		credSchema["name"] = name
+	}
+	return credSchema, nil
+}
+
+// TypedCredentialBuilder uses the builder pattern to construct a typed credential
+type TypedCredentialBuilder[T any] struct {
+	VerifiableCredentialBuilder
+	subject *T
+}
+
+// NewTypedCredentialBuilder returns an initialized typed credential builder, populated as by NewVerifiableCredentialBuilder
+func NewTypedCredentialBuilder[T any](idValue IDValue) TypedCredentialBuilder[T] {
+	return TypedCredentialBuilder[T]{VerifiableCredentialBuilder: NewVerifiableCredentialBuilder(idValue)}
+}
+
+// SetCredentialSubject sets the typed subject of the credential
+func (tcb *TypedCredentialBuilder[T]) SetCredentialSubject(subject T) error {
+	if tcb.VerifiableCredentialBuilder.IsEmpty() {
+		return errors.New(BuilderEmptyError)
+	}
+
+	subjectMap, err := util.ToJSONMap(subject)
+	if err != nil {
+		return errors.Wrap(err, "encoding credential subject")
+	}
+	if err = tcb.VerifiableCredentialBuilder.SetCredentialSubject(subjectMap); err != nil {
+		return err
+	}
+	tcb.subject = &subject
+	return nil
+}
+
+// Build attempts to turn the builder into a valid typed credential
+func (tcb *TypedCredentialBuilder[T]) Build() (*TypedCredential[T], error) {
+	if tcb.subject == nil {
+		return nil, errors.New("credential subject must be set")
+	}
+	cred, err := tcb.VerifiableCredentialBuilder.Build()
+	if err != nil {
+		return nil, err
+	}
+	typed := TypedCredential[T]{VerifiableCredential: *cred, CredentialSubject: *tcb.subject}
+	typed.VerifiableCredential.CredentialSubject = nil
+	return &typed, nil
+}
@@ -0,0 +1,85 @@
+package credential
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/schema"
+)
+
+type degreeSubject struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name" description:"name of the degree holder"`
+	Degree degree  `json:"degree"`
+	GPA    float64 `json:"gpa,omitempty"`
+}
+
+type degree struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+func TestTypedCredential(t *testing.T) {
+	subject := degreeSubject{
+		ID:     "did:example:alice",
+		Name:   "Alice",
+		Degree: degree{Type: "BachelorDegree", Name: "Bachelor of Science"},
+	}
+
+	t.Run("build and round trip", func(tt *testing.T) {
+		builder := NewTypedCredentialBuilder[degreeSubject](GenerateIDValue)
+		_, err := builder.Build()
+		assert.ErrorContains(tt, err, "credential subject must be set")
+
+		require.NoError(tt, builder.SetIssuer("did:example:university"))
+		require.NoError(tt, builder.SetCredentialSubject(subject))
+		typed, err := builder.Build()
+		require.NoError(tt, err)
+		assert.Equal(tt, subject, typed.CredentialSubject)
+		assert.NoError(tt, typed.IsValid())
+
+		cred, err := typed.ToCredential()
+		require.NoError(tt, err)
+		assert.Equal(tt, "did:example:alice", cred.CredentialSubject.GetID())
+		assert.Equal(tt, "Bachelor of Science", cred.CredentialSubject["degree"].(map[string]any)["name"])
+
+		typedBytes, err := json.Marshal(typed)
+		require.NoError(tt, err)
+		credBytes, err := json.Marshal(cred)
+		require.NoError(tt, err)
+		assert.JSONEq(tt, string(credBytes), string(typedBytes))
+
+		var parsed TypedCredential[degreeSubject]
+		require.NoError(tt, json.Unmarshal(typedBytes, &parsed))
+		assert.Equal(tt, typed.ID, parsed.ID)
+		assert.Equal(tt, subject, parsed.CredentialSubject)
+	})
+
+	t.Run("from credential with mismatched subject", func(tt *testing.T) {
+		cred := VerifiableCredential{CredentialSubject: CredentialSubject{"name": 42}}
+		_, err := NewTypedCredential[degreeSubject](cred)
+		assert.ErrorContains(tt, err, "decoding credential subject")
+	})
+
+	t.Run("generated schema", func(tt *testing.T) {
+		credSchema, err := CredentialSubjectJSONSchema[degreeSubject]("https://example.com/schemas/degree.json", "Degree")
+		require.NoError(tt, err)
+		assert.Equal(tt, "https://example.com/schemas/degree.json", credSchema["$id"])
+
+		subjectSchema := credSchema["properties"].(map[string]any)["credentialSubject"].(map[string]any)
+		assert.Equal(tt, []string{"id", "name", "degree"}, subjectSchema["required"])
+
+		schemaBytes, err := json.Marshal(credSchema)
+		require.NoError(tt, err)
+		typed, err := NewTypedCredential[degreeSubject](VerifiableCredential{})
+		require.NoError(tt, err)
+		typed.CredentialSubject = subject
+		assert.NoError(tt, schema.IsAnyValidAgainstJSONSchema(typed, string(schemaBytes)))
+
+		invalid := VerifiableCredential{CredentialSubject: CredentialSubject{"id": "did:example:alice", "name": "Alice"}}
+		assert.Error(tt, schema.IsAnyValidAgainstJSONSchema(invalid, string(schemaBytes)))
+	})
+}
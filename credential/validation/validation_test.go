@@ -1,11 +1,23 @@
 package validation
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
 	credschema "github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/credential/trust"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidator(t *testing.T) {
@@ -96,13 +108,273 @@ func TestValidator(t *testing.T) {
 		}
 		err = validator.ValidateCredential(sampleCredential, WithSchema(knownSchema))
 		assert.NoError(tt, err)
+
+		// validate cred with schema, schema fetched using access
+		err = validator.ValidateCredential(sampleCredential, WithSchemaAccess(staticSchemaAccess{sampleCredential.CredentialSchema.ID: knownSchema}))
+		assert.NoError(tt, err)
+
+		// validate cred with schema, schema not found using access
+		err = validator.ValidateCredential(sampleCredential, WithSchemaAccess(staticSchemaAccess{}))
+		assert.ErrorContains(tt, err, "schema not found")
 	})
 }
 
+func TestValidateStatus(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	statusListURL := "https://example.com/status/1"
+	withStatus := func(index int) credential.VerifiableCredential {
+		cred := getSampleCredential()
+		cred.Issuer = didKey.String()
+		entry, err := status.NewStatusList2021Entry(statusListURL, index, status.StatusRevocation)
+		require.NoError(t, err)
+		cred.CredentialStatus = *entry
+		return cred
+	}
+	revoked := withStatus(42)
+	statusListCredential, err := status.GenerateStatusList2021Credential(statusListURL, didKey.String(), status.StatusRevocation, []credential.VerifiableCredential{revoked})
+	require.NoError(t, err)
+	token, err := integrity.SignVerifiableCredentialJWT(*signer, *statusListCredential)
+	require.NoError(t, err)
+	access := staticStatusListAccess{statusListURL: token}
+
+	validator, err := NewCredentialValidator([]Validator{{ID: "Status Check", ValidateFunc: ValidateStatus}})
+	require.NoError(t, err)
+
+	t.Run("no status", func(tt *testing.T) {
+		assert.NoError(tt, validator.ValidateCredential(getSampleCredential()))
+	})
+
+	t.Run("no status check provided", func(tt *testing.T) {
+		err := validator.ValidateCredential(revoked)
+		assert.ErrorContains(tt, err, "no status check provided")
+	})
+
+	t.Run("revoked", func(tt *testing.T) {
+		err := validator.ValidateCredential(revoked, WithStatusCheck(access, resolver))
+		assert.ErrorContains(tt, err, "credential<test-verifiable-credential> status is set in its revocation status list")
+	})
+
+	t.Run("not revoked", func(tt *testing.T) {
+		err := validator.ValidateCredential(withStatus(7), WithStatusCheck(access, resolver))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("bitstring status list", func(tt *testing.T) {
+		bitstringURL := "https://example.com/status/2"
+		list, err := status.NewBitstringStatusList(0, 1)
+		require.NoError(tt, err)
+		require.NoError(tt, list.SetStatus(3, 1))
+		bitstringCredential, err := status.GenerateBitstringStatusListCredential(bitstringURL, didKey.String(), list, status.StatusRevocation, status.StatusRefresh)
+		require.NoError(tt, err)
+		bitstringToken, err := integrity.SignVerifiableCredentialJWT(*signer, *bitstringCredential)
+		require.NoError(tt, err)
+		access[bitstringURL] = bitstringToken
+
+		withBitstringStatus := func(purpose status.StatusPurpose, index int) credential.VerifiableCredential {
+			cred := getSampleCredential()
+			cred.Issuer = didKey.String()
+			entry, err := status.NewBitstringStatusListEntry(bitstringURL, index, purpose, 1, nil)
+			require.NoError(tt, err)
+			cred.CredentialStatus = []any{*entry}
+			return cred
+		}
+		err = validator.ValidateCredential(withBitstringStatus(status.StatusRevocation, 3), WithStatusCheck(access, resolver))
+		assert.ErrorContains(tt, err, "credential<test-verifiable-credential> status is set in its revocation status list")
+
+		err = validator.ValidateCredential(withBitstringStatus(status.StatusRevocation, 4), WithStatusCheck(access, resolver))
+		assert.NoError(tt, err)
+
+		// a set refresh status does not invalidate the credential
+		err = validator.ValidateCredential(withBitstringStatus(status.StatusRefresh, 3), WithStatusCheck(access, resolver))
+		assert.NoError(tt, err)
+	})
+}
+
+type staticStatusListAccess map[string][]byte
+
+func (s staticStatusListAccess) GetStatusListCredential(_ context.Context, url string) ([]byte, error) {
+	statusListCredential, ok := s[url]
+	if !ok {
+		return nil, fmt.Errorf("status list credential not found: %s", url)
+	}
+	return statusListCredential, nil
+}
+
 func NoOpValidator(_ credential.VerifiableCredential, _ ...Option) error {
 	return nil
 }
 
+func TestValidateEvidence(t *testing.T) {
+	sampleCredential := getSampleCredential()
+
+	t.Run("no evidence", func(tt *testing.T) {
+		assert.NoError(tt, ValidateEvidence(sampleCredential))
+	})
+
+	sampleCredential.Evidence = []credential.Evidence{
+		{
+			"id":               "https://example.edu/evidence/f2aeec97-fc0d-42bf-8ca7-0548192d4231",
+			"type":             []string{credential.DocumentVerificationEvidenceType},
+			"verifier":         "https://example.edu/issuers/14",
+			"evidenceDocument": "DriversLicense",
+		},
+		{
+			"type":     "SupportingActivity",
+			"verifier": "https://example.edu/issuers/14",
+		},
+	}
+
+	t.Run("valid evidence", func(tt *testing.T) {
+		assert.NoError(tt, ValidateEvidence(sampleCredential))
+		assert.NoError(tt, ValidateEvidence(sampleCredential, WithEvidenceVerifier(credential.DocumentVerificationEvidenceType, ValidateDocumentVerificationEvidence)))
+	})
+
+	t.Run("evidence verifiers run for their type", func(tt *testing.T) {
+		var verified []string
+		trustedVerifier := func(_ credential.VerifiableCredential, evidence credential.Evidence) error {
+			verified = append(verified, evidence.GetTypes()[0])
+			if evidence["verifier"] != "https://example.edu/issuers/15" {
+				return fmt.Errorf("untrusted verifier: %s", evidence["verifier"])
+			}
+			return nil
+		}
+		err := ValidateEvidence(sampleCredential, WithEvidenceVerifier(credential.DocumentVerificationEvidenceType, trustedVerifier))
+		assert.ErrorContains(tt, err, "DocumentVerification evidence<0> failed verification: untrusted verifier")
+		assert.Equal(tt, []string{credential.DocumentVerificationEvidenceType}, verified)
+	})
+
+	t.Run("invalid evidence", func(tt *testing.T) {
+		invalid := sampleCredential
+		invalid.Evidence = []credential.Evidence{{"type": credential.DocumentVerificationEvidenceType}}
+		err := ValidateEvidence(invalid, WithEvidenceVerifier(credential.DocumentVerificationEvidenceType, ValidateDocumentVerificationEvidence))
+		assert.ErrorContains(tt, err, "invalid document verification evidence")
+
+		invalid.Evidence = []credential.Evidence{{"verifier": "https://example.edu/issuers/14"}}
+		err = ValidateEvidence(invalid)
+		assert.ErrorContains(tt, err, "evidence must have a type")
+
+		err = ValidateEvidence(sampleCredential, WithEvidenceVerifier(credential.DocumentVerificationEvidenceType, nil))
+		assert.ErrorContains(tt, err, "the option provided must be an EvidenceVerifier")
+	})
+}
+
+func TestValidateTermsOfUse(t *testing.T) {
+	sampleCredential := getSampleCredential()
+	assert.NoError(t, ValidateTermsOfUse(sampleCredential))
+
+	sampleCredential.TermsOfUse = []credential.TermsOfUse{
+		credential.NewIssuerPolicy("http://example.com/policies/credential/4", "", credential.Prohibition{
+			Assignee: credential.AllVerifiersAssignee,
+			Action:   []string{"Archival"},
+		}),
+	}
+
+	t.Run("no evaluator", func(tt *testing.T) {
+		assert.NoError(tt, ValidateTermsOfUse(sampleCredential))
+	})
+
+	t.Run("terms accepted", func(tt *testing.T) {
+		err := ValidateTermsOfUse(sampleCredential, WithTermsOfUseEvaluator(ProhibitedActionsEvaluator("did:example:verifier", "3rdPartyCorrelation")))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("terms not accepted", func(tt *testing.T) {
+		err := ValidateTermsOfUse(sampleCredential, WithTermsOfUseEvaluator(ProhibitedActionsEvaluator("did:example:verifier", "Archival")))
+		assert.ErrorContains(tt, err, "prohibit did:example:verifier from taking action: Archival")
+	})
+
+	t.Run("invalid terms", func(tt *testing.T) {
+		invalid := sampleCredential
+		invalid.TermsOfUse = []credential.TermsOfUse{{ID: "http://example.com/policies/credential/4"}}
+		err := ValidateTermsOfUse(invalid)
+		assert.ErrorContains(tt, err, "terms of use<0> are not valid")
+	})
+
+	t.Run("presentation terms", func(tt *testing.T) {
+		pres := credential.VerifiablePresentation{
+			ID: "test-presentation",
+			TermsOfUse: []credential.TermsOfUse{
+				credential.NewHolderPolicy("http://example.com/policies/presentation/1", "", credential.Prohibition{
+					Assignee: "did:example:verifier",
+					Action:   []string{"3rdPartyCorrelation"},
+				}),
+			},
+		}
+		err := ValidatePresentationTermsOfUse(pres, ProhibitedActionsEvaluator("did:example:other", "3rdPartyCorrelation"))
+		assert.NoError(tt, err)
+		err = ValidatePresentationTermsOfUse(pres, ProhibitedActionsEvaluator("did:example:verifier", "3rdPartyCorrelation"))
+		assert.ErrorContains(tt, err, "presentation<test-presentation> terms of use")
+	})
+}
+
+func TestValidateTimes(t *testing.T) {
+	sampleCredential := getSampleCredential()
+	sampleCredential.IssuanceDate = "2023-01-01T00:00:00Z"
+	sampleCredential.ExpirationDate = ""
+	sampleCredential.ValidUntil = "2024-01-01T00:00:00Z"
+
+	validator, err := NewCredentialValidator([]Validator{{ID: "Times Check", ValidateFunc: ValidateTimes}})
+	require.NoError(t, err)
+
+	err = validator.ValidateCredential(sampleCredential, WithTimeValidation(credential.TimeValidationOptions{Now: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}))
+	assert.NoError(t, err)
+
+	err = validator.ValidateCredential(sampleCredential, WithTimeValidation(credential.TimeValidationOptions{Now: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)}))
+	assert.ErrorContains(t, err, "credential is not yet valid")
+
+	// validated at the current time by default
+	err = ValidateTimes(sampleCredential)
+	assert.ErrorIs(t, err, credential.ErrExpired)
+
+	err = ValidateTimes(sampleCredential, Option{ID: TimesOption, Option: "bad"})
+	assert.ErrorContains(t, err, "the option provided must be a TimeValidationOptions")
+}
+
+func TestValidateJSONLDTerms(t *testing.T) {
+	sampleCredential := getSampleCredential()
+	sampleCredential.CredentialSubject = map[string]any{"id": "did:example:subject"}
+	assert.NoError(t, ValidateJSONLDTerms(sampleCredential))
+
+	sampleCredential.CredentialSubject["company"] = "Block"
+	err := ValidateJSONLDTerms(sampleCredential)
+	assert.ErrorContains(t, err, "has properties not defined by its contexts: credentialSubject.company")
+
+	docLoader, err := util.NewLDDocumentLoader()
+	require.NoError(t, err)
+	err = ValidateJSONLDTerms(sampleCredential, WithJSONLDDocumentLoader(docLoader))
+	assert.ErrorContains(t, err, "credentialSubject.company")
+
+	err = ValidateJSONLDTerms(sampleCredential, Option{ID: JSONLDOption, Option: "bad"})
+	assert.ErrorContains(t, err, "the option provided must be a DocumentLoader")
+}
+
+func TestValidateIssuerTrust(t *testing.T) {
+	sampleCredential := getSampleCredential()
+	sampleCredential.Type = []string{credential.VerifiableCredentialType, "EmploymentCredential"}
+
+	err := ValidateIssuerTrust(sampleCredential)
+	assert.ErrorContains(t, err, "no trust registry provided")
+
+	registry := trust.NewStaticTrustRegistry(map[string][]string{"test-issuer": {"EmploymentCredential"}})
+	assert.NoError(t, ValidateIssuerTrust(sampleCredential, WithTrustRegistry(registry)))
+
+	sampleCredential.Issuer = "other-issuer"
+	err = ValidateIssuerTrust(sampleCredential, WithTrustRegistry(registry))
+	assert.ErrorContains(t, err, "issuer<other-issuer> is not trusted to issue credential<test-verifiable-credential>")
+
+	err = ValidateIssuerTrust(sampleCredential, Option{ID: TrustOption, Option: "bad"})
+	assert.ErrorContains(t, err, "the option provided must be a TrustRegistry")
+}
+
 func getSampleCredential() credential.VerifiableCredential {
 	return credential.VerifiableCredential{
 		Context: []any{"https://www.w3.org/2018/credentials/v1",
@@ -143,3 +415,13 @@ func getVCJSONSchema() string {
   }
 }`
 }
+
+type staticSchemaAccess map[string]string
+
+func (s staticSchemaAccess) GetVCJSONSchema(_ context.Context, t credschema.VCJSONSchemaType, id string) (credschema.VCJSONSchema, error) {
+	schema, ok := s[id]
+	if !ok {
+		return nil, fmt.Errorf("schema not found: %s", id)
+	}
+	return credschema.ParseVCJSONSchema(t, []byte(schema))
+}
@@ -1,17 +1,31 @@
 package validation
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	credschema "github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/credential/trust"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
+	"github.com/piprate/json-gold/ld"
 	"github.com/pkg/errors"
 )
 
 const (
-	SchemaOption OptionKey = "schema"
+	SchemaOption       OptionKey = "schema"
+	SchemaAccessOption OptionKey = "schema-access"
+	StatusOption       OptionKey = "status"
+	EvidenceOption     OptionKey = "evidence"
+	TermsOfUseOption   OptionKey = "terms-of-use"
+	TimesOption        OptionKey = "times"
+	JSONLDOption       OptionKey = "json-ld"
+	TrustOption        OptionKey = "trust"
 )
 
 // ValidateCredential verifies a credential's object model depending on the struct tags used on VerifiableCredential
@@ -35,6 +49,28 @@ func ValidateExpiry(cred credential.VerifiableCredential, _ ...Option) error {
 	return nil
 }
 
+// WithTimeValidation configures how ValidateTimes checks a credential's validity period as a validation option
+func WithTimeValidation(opts credential.TimeValidationOptions) Option {
+	return Option{
+		ID:     TimesOption,
+		Option: opts,
+	}
+}
+
+// ValidateTimes verifies a credential is within its validity period, using its issuanceDate, expirationDate,
+// validFrom, and validUntil values. There is an optional single option which is credential.TimeValidationOptions,
+// configuring the time to validate at and the clock skew to tolerate.
+func ValidateTimes(cred credential.VerifiableCredential, opts ...Option) error {
+	var timeOpts credential.TimeValidationOptions
+	if maybeTimeOpts, err := GetValidationOption(opts, TimesOption); err == nil {
+		var ok bool
+		if timeOpts, ok = maybeTimeOpts.(credential.TimeValidationOptions); !ok {
+			return errors.New("the option provided must be a TimeValidationOptions")
+		}
+	}
+	return credential.ValidateTimes(cred, timeOpts)
+}
+
 // WithSchema provides a schema as a validation option
 func WithSchema(schema string) Option {
 	return Option{
@@ -43,11 +79,30 @@ func WithSchema(schema string) Option {
 	}
 }
 
+// WithSchemaAccess provides a means to fetch the schema referenced by a credential's credentialSchema property as
+// a validation option
+func WithSchemaAccess(access credschema.VCJSONSchemaAccess) Option {
+	return Option{
+		ID:     SchemaAccessOption,
+		Option: access,
+	}
+}
+
 // ValidateJSONSchema verifies a credential's data against a Verifiable Credential JSON Schema
-// There is a required single option which is a string JSON value representing the Credential Schema Object
+// There is a required single option which is either a string JSON value representing the Credential Schema Object,
+// or a VCJSONSchemaAccess used to fetch the schema referenced by the credential
 func ValidateJSONSchema(cred credential.VerifiableCredential, opts ...Option) error {
 	hasSchemaProperty := cred.CredentialSchema != nil
 	schema, err := GetValidationOption(opts, SchemaOption)
+	if err != nil && hasSchemaProperty {
+		if maybeAccess, accessErr := GetValidationOption(opts, SchemaAccessOption); accessErr == nil {
+			access, ok := maybeAccess.(credschema.VCJSONSchemaAccess)
+			if !ok || access == nil {
+				return errors.New("the option provided must be a VCJSONSchemaAccess")
+			}
+			return credschema.ValidateCredentialAgainstSchema(access, cred)
+		}
+	}
 	if err != nil {
 		// if the cred does not have a schema property, we cannot perform this check
 		if !hasSchemaProperty {
@@ -79,6 +134,227 @@ func optionToCredentialSchema(maybeSchema any) (*credschema.VCJSONSchema, error)
 	return &credSchema, nil
 }
 
+// StatusCheck provides what is needed to check the status of a credential: access to the status list credentials
+// it references, and a resolver to verify their signatures
+type StatusCheck struct {
+	Access   status.StatusListAccess
+	Resolver resolution.Resolver
+}
+
+// WithStatusCheck provides the means to check a credential's status as a validation option
+func WithStatusCheck(access status.StatusListAccess, r resolution.Resolver) Option {
+	return Option{
+		ID:     StatusOption,
+		Option: StatusCheck{Access: access, Resolver: r},
+	}
+}
+
+// ValidateStatus verifies a credential using a StatusList2021Entry, BitstringStatusListEntry, or legacy
+// RevocationList2020Status credentialStatus has not been revoked or suspended, fetching its status list credentials
+// with status.CheckStatus. Statuses for other purposes, such as refresh or message, do not fail validation. There is a
+// required single option which is a StatusCheck.
+func ValidateStatus(cred credential.VerifiableCredential, opts ...Option) error {
+	if cred.CredentialStatus == nil {
+		return nil
+	}
+	maybeStatusCheck, err := GetValidationOption(opts, StatusOption)
+	if err != nil {
+		return errors.Wrap(err, "cannot validate the credential's status, no status check provided")
+	}
+	statusCheck, ok := maybeStatusCheck.(StatusCheck)
+	if !ok {
+		return errors.New("the option provided must be a StatusCheck")
+	}
+	return status.CheckStatus(context.Background(), cred, statusCheck.Access, statusCheck.Resolver)
+}
+
+// EvidenceVerifier runs checks specific to a type of evidence, such as confirming a document verification was
+// performed by a trusted verifier
+type EvidenceVerifier func(cred credential.VerifiableCredential, evidence credential.Evidence) error
+
+type evidenceCheck struct {
+	evidenceType string
+	verifier     EvidenceVerifier
+}
+
+// WithEvidenceVerifier provides a verifier for evidence of the given type as a validation option. The option may
+// be provided multiple times, for different or the same types of evidence.
+func WithEvidenceVerifier(evidenceType string, verifier EvidenceVerifier) Option {
+	return Option{
+		ID:     EvidenceOption,
+		Option: evidenceCheck{evidenceType: evidenceType, verifier: verifier},
+	}
+}
+
+// ValidateDocumentVerificationEvidence is an EvidenceVerifier which checks DocumentVerification evidence has
+// its required properties
+func ValidateDocumentVerificationEvidence(_ credential.VerifiableCredential, evidence credential.Evidence) error {
+	_, err := evidence.ToDocumentVerification()
+	return err
+}
+
+// ValidateEvidence verifies each piece of a credential's evidence is valid, and runs any EvidenceVerifier provided
+// for its type. Evidence with no verifier for its type only has its object model validated.
+func ValidateEvidence(cred credential.VerifiableCredential, opts ...Option) error {
+	var checks []evidenceCheck
+	for _, opt := range opts {
+		if opt.ID != EvidenceOption {
+			continue
+		}
+		check, ok := opt.Option.(evidenceCheck)
+		if !ok || check.verifier == nil {
+			return errors.New("the option provided must be an EvidenceVerifier")
+		}
+		checks = append(checks, check)
+	}
+
+	for i, evidence := range cred.Evidence {
+		if err := evidence.IsValid(); err != nil {
+			return errors.Wrapf(err, "credential<%s> evidence<%d> is not valid", cred.ID, i)
+		}
+		for _, check := range checks {
+			if !evidence.HasType(check.evidenceType) {
+				continue
+			}
+			if err := check.verifier(cred, evidence); err != nil {
+				return errors.Wrapf(err, "credential<%s> %s evidence<%d> failed verification", cred.ID, check.evidenceType, i)
+			}
+		}
+	}
+	return nil
+}
+
+// TermsOfUseEvaluator enforces a credential or presentation's terms of use, returning an error if the relying party
+// cannot accept them
+type TermsOfUseEvaluator func(terms credential.TermsOfUse) error
+
+// WithTermsOfUseEvaluator provides an evaluator for a credential's terms of use as a validation option
+func WithTermsOfUseEvaluator(evaluator TermsOfUseEvaluator) Option {
+	return Option{
+		ID:     TermsOfUseOption,
+		Option: evaluator,
+	}
+}
+
+// ProhibitedActionsEvaluator returns a TermsOfUseEvaluator which rejects terms of use prohibiting the given assignee,
+// e.g. the relying party, from taking any of the given actions, e.g. Archival
+func ProhibitedActionsEvaluator(assignee string, actions ...string) TermsOfUseEvaluator {
+	return func(terms credential.TermsOfUse) error {
+		for _, action := range actions {
+			if terms.Prohibits(assignee, action) {
+				return fmt.Errorf("terms of use<%s> prohibit %s from taking action: %s", terms.ID, assignee, action)
+			}
+		}
+		return nil
+	}
+}
+
+// ValidateTermsOfUse verifies a credential's terms of use are well-formed and, if a TermsOfUseEvaluator is provided,
+// acceptable to the relying party
+func ValidateTermsOfUse(cred credential.VerifiableCredential, opts ...Option) error {
+	if len(cred.TermsOfUse) == 0 {
+		return nil
+	}
+	var evaluator TermsOfUseEvaluator
+	if maybeEvaluator, err := GetValidationOption(opts, TermsOfUseOption); err == nil {
+		var ok bool
+		if evaluator, ok = maybeEvaluator.(TermsOfUseEvaluator); !ok || evaluator == nil {
+			return errors.New("the option provided must be a TermsOfUseEvaluator")
+		}
+	}
+	if err := evaluateTermsOfUse(cred.TermsOfUse, evaluator); err != nil {
+		return errors.Wrapf(err, "credential<%s> terms of use", cred.ID)
+	}
+	return nil
+}
+
+// ValidatePresentationTermsOfUse verifies a presentation's terms of use, such as a HolderPolicy, are well-formed and
+// acceptable to the relying party according to the given evaluator
+func ValidatePresentationTermsOfUse(pres credential.VerifiablePresentation, evaluator TermsOfUseEvaluator) error {
+	if evaluator == nil {
+		return errors.New("terms of use evaluator cannot be empty")
+	}
+	if err := evaluateTermsOfUse(pres.TermsOfUse, evaluator); err != nil {
+		return errors.Wrapf(err, "presentation<%s> terms of use", pres.ID)
+	}
+	return nil
+}
+
+func evaluateTermsOfUse(termsOfUse []credential.TermsOfUse, evaluator TermsOfUseEvaluator) error {
+	for i, terms := range termsOfUse {
+		if err := util.IsValidStruct(terms); err != nil {
+			return errors.Wrapf(err, "terms of use<%d> are not valid", i)
+		}
+		if evaluator == nil {
+			continue
+		}
+		if err := evaluator(terms); err != nil {
+			return errors.Wrapf(err, "terms of use<%d> not accepted", i)
+		}
+	}
+	return nil
+}
+
+// WithJSONLDDocumentLoader provides the loader used to resolve a credential's contexts as a validation option
+func WithJSONLDDocumentLoader(docLoader ld.DocumentLoader) Option {
+	return Option{
+		ID:     JSONLDOption,
+		Option: docLoader,
+	}
+}
+
+// ValidateJSONLDTerms is a strict JSON-LD check, which verifies every property of a credential is defined by one of
+// its contexts. Properties which are not defined are silently dropped by JSON-LD processors, so they are not covered
+// by Data Integrity proofs, and are not understood by verifiers. There is an optional single option which is an
+// ld.DocumentLoader used to resolve the credential's contexts; by default, contexts bundled with the SDK are preloaded
+// and others are fetched. To warn about undefined terms instead, lint the credential with CheckJSONLDTerms set.
+func ValidateJSONLDTerms(cred credential.VerifiableCredential, opts ...Option) error {
+	var docLoader ld.DocumentLoader
+	if maybeLoader, err := GetValidationOption(opts, JSONLDOption); err == nil {
+		var ok bool
+		if docLoader, ok = maybeLoader.(ld.DocumentLoader); !ok || docLoader == nil {
+			return errors.New("the option provided must be a DocumentLoader")
+		}
+	}
+	undefined, err := credential.UndefinedTerms(cred, docLoader)
+	if err != nil {
+		return errors.Wrap(err, "finding undefined JSON-LD terms")
+	}
+	if len(undefined) > 0 {
+		return fmt.Errorf("credential<%s> has properties not defined by its contexts: %s", cred.ID, strings.Join(undefined, ", "))
+	}
+	return nil
+}
+
+// WithTrustRegistry provides the registry of accredited issuers as a validation option
+func WithTrustRegistry(registry trust.TrustRegistry) Option {
+	return Option{
+		ID:     TrustOption,
+		Option: registry,
+	}
+}
+
+// ValidateIssuerTrust verifies a credential's issuer is accredited to issue each of its types according to a trust
+// registry. There is a required single option which is a trust.TrustRegistry.
+func ValidateIssuerTrust(cred credential.VerifiableCredential, opts ...Option) error {
+	maybeRegistry, err := GetValidationOption(opts, TrustOption)
+	if err != nil {
+		return errors.Wrap(err, "cannot validate the credential's issuer, no trust registry provided")
+	}
+	registry, ok := maybeRegistry.(trust.TrustRegistry)
+	if !ok || registry == nil {
+		return errors.New("the option provided must be a TrustRegistry")
+	}
+	trusted, err := trust.IsIssuerTrusted(context.Background(), registry, cred)
+	if err != nil {
+		return errors.Wrapf(err, "checking issuer of credential<%s>", cred.ID)
+	}
+	if !trusted {
+		return fmt.Errorf("issuer<%s> is not trusted to issue credential<%s>", cred.IssuerID(), cred.ID)
+	}
+	return nil
+}
+
 func GetKnownVerifiers() []Validator {
 	return []Validator{
 		{
@@ -93,5 +369,13 @@ func GetKnownVerifiers() []Validator {
 			ID:           "VC JSON Schema",
 			ValidateFunc: ValidateJSONSchema,
 		},
+		{
+			ID:           "Evidence Check",
+			ValidateFunc: ValidateEvidence,
+		},
+		{
+			ID:           "Terms of Use Check",
+			ValidateFunc: ValidateTermsOfUse,
+		},
 	}
 }
@@ -0,0 +1,73 @@
+package credential
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotYetValid is returned by ValidateTimes when a credential's validity period has not started
+	ErrNotYetValid = errors.New("credential is not yet valid")
+	// ErrExpired is returned by ValidateTimes when a credential's validity period has ended
+	ErrExpired = errors.New("credential has expired")
+)
+
+// DefaultClockSkew is the tolerance applied to a credential's timestamps when no clock skew is configured
+const DefaultClockSkew = 5 * time.Minute
+
+// TimeValidationOptions configures how ValidateTimes checks a credential's validity period
+type TimeValidationOptions struct {
+	// Now is the time the credential is validated at, defaulting to the current time
+	Now time.Time
+	// ClockSkew is the tolerance applied to the credential's timestamps, accounting for differences between the
+	// issuer's and verifier's clocks. DefaultClockSkew is used if it is zero; a negative value disables it.
+	ClockSkew time.Duration
+}
+
+// ValidateTimes checks a credential is within its validity period. The period starts at its issuanceDate and
+// validFrom, and ends at its expirationDate and validUntil, whichever of them are present
+// https://www.w3.org/TR/vc-data-model-2.0/#validity-period
+// A credential whose period has not started returns an error wrapping ErrNotYetValid, and one whose period has ended
+// returns an error wrapping ErrExpired, which can be distinguished with errors.Is.
+func ValidateTimes(cred VerifiableCredential, opts TimeValidationOptions) error {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	skew := opts.ClockSkew
+	if skew == 0 {
+		skew = DefaultClockSkew
+	} else if skew < 0 {
+		skew = 0
+	}
+
+	for _, t := range []struct{ property, value string }{{"issuanceDate", cred.IssuanceDate}, {"validFrom", cred.ValidFrom}} {
+		property, value := t.property, t.value
+		if value == "" {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse %s: %s", property, value)
+		}
+		if now.Add(skew).Before(start) {
+			return errors.Wrapf(ErrNotYetValid, "credential<%s> %s is %s", cred.ID, property, value)
+		}
+	}
+
+	for _, t := range []struct{ property, value string }{{"expirationDate", cred.ExpirationDate}, {"validUntil", cred.ValidUntil}} {
+		property, value := t.property, t.value
+		if value == "" {
+			continue
+		}
+		end, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse %s: %s", property, value)
+		}
+		if now.Add(-skew).After(end) {
+			return errors.Wrapf(ErrExpired, "credential<%s> %s is %s", cred.ID, property, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package credential
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTimes(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	opts := TimeValidationOptions{Now: now}
+
+	t.Run("within validity period", func(tt *testing.T) {
+		cred := VerifiableCredential{
+			ID:             "test-cred",
+			IssuanceDate:   "2023-01-01T00:00:00Z",
+			ExpirationDate: "2024-01-01T00:00:00Z",
+		}
+		assert.NoError(tt, ValidateTimes(cred, opts))
+
+		v2Cred := VerifiableCredential{
+			ID:         "test-cred",
+			ValidFrom:  "2023-01-01T00:00:00Z",
+			ValidUntil: "2024-01-01T00:00:00Z",
+		}
+		assert.NoError(tt, ValidateTimes(v2Cred, opts))
+		assert.NoError(tt, ValidateTimes(VerifiableCredential{}, opts))
+	})
+
+	t.Run("not yet valid", func(tt *testing.T) {
+		cred := VerifiableCredential{ID: "test-cred", IssuanceDate: "2023-06-02T00:00:00Z"}
+		err := ValidateTimes(cred, opts)
+		assert.True(tt, errors.Is(err, ErrNotYetValid))
+		assert.ErrorContains(tt, err, "credential<test-cred> issuanceDate is 2023-06-02T00:00:00Z")
+
+		cred = VerifiableCredential{ID: "test-cred", IssuanceDate: "2023-01-01T00:00:00Z", ValidFrom: "2023-07-01T00:00:00Z"}
+		err = ValidateTimes(cred, opts)
+		assert.True(tt, errors.Is(err, ErrNotYetValid))
+		assert.ErrorContains(tt, err, "validFrom")
+	})
+
+	t.Run("expired", func(tt *testing.T) {
+		cred := VerifiableCredential{ID: "test-cred", ExpirationDate: "2023-05-01T00:00:00Z"}
+		err := ValidateTimes(cred, opts)
+		assert.True(tt, errors.Is(err, ErrExpired))
+		assert.False(tt, errors.Is(err, ErrNotYetValid))
+
+		cred = VerifiableCredential{ID: "test-cred", ValidUntil: "2023-05-01T00:00:00Z"}
+		err = ValidateTimes(cred, opts)
+		assert.True(tt, errors.Is(err, ErrExpired))
+		assert.ErrorContains(tt, err, "validUntil")
+	})
+
+	t.Run("clock skew", func(tt *testing.T) {
+		// issued two minutes in the verifier's future, within the default skew
+		cred := VerifiableCredential{ID: "test-cred", IssuanceDate: "2023-06-01T12:02:00Z"}
+		assert.NoError(tt, ValidateTimes(cred, opts))
+		err := ValidateTimes(cred, TimeValidationOptions{Now: now, ClockSkew: time.Minute})
+		assert.True(tt, errors.Is(err, ErrNotYetValid))
+
+		// expired two minutes ago
+		cred = VerifiableCredential{ID: "test-cred", ExpirationDate: "2023-06-01T11:58:00Z"}
+		assert.NoError(tt, ValidateTimes(cred, opts))
+		err = ValidateTimes(cred, TimeValidationOptions{Now: now, ClockSkew: -1})
+		assert.True(tt, errors.Is(err, ErrExpired))
+	})
+
+	t.Run("invalid timestamps", func(tt *testing.T) {
+		err := ValidateTimes(VerifiableCredential{ValidFrom: "yesterday"}, opts)
+		assert.ErrorContains(tt, err, "failed to parse validFrom: yesterday")
+	})
+}
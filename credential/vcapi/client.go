@@ -0,0 +1,123 @@
+package vcapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+// Client calls the endpoints of a VC API service
+type Client struct {
+	*http.Client
+	baseURL string
+}
+
+// NewClient returns a new client for the VC API served at the given URL, using the default HTTP client
+func NewClient(baseURL string) *Client {
+	return &Client{Client: http.DefaultClient, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// IssueCredential requests the service issue the given credential, returning the issued credential
+func (c *Client) IssueCredential(ctx context.Context, cred credential.VerifiableCredential) (*credential.VerifiableCredential, error) {
+	var response IssueCredentialResponse
+	if err := c.post(ctx, c.baseURL+IssueCredentialPath, IssueCredentialRequest{Credential: cred}, &response, http.StatusCreated); err != nil {
+		return nil, errors.Wrap(err, "issuing credential")
+	}
+	return &response.VerifiableCredential, nil
+}
+
+// VerifyCredential requests the service verify the given credential, which may be a JSON credential or a credential
+// secured as a JWT. A result is returned whether or not the credential is verified; an error is returned if the
+// service could not process the request.
+func (c *Client) VerifyCredential(ctx context.Context, cred any) (*VerificationResult, error) {
+	var result VerificationResult
+	if err := c.post(ctx, c.baseURL+VerifyCredentialPath, VerifyCredentialRequest{VerifiableCredential: cred}, &result, http.StatusOK, http.StatusBadRequest); err != nil {
+		return nil, errors.Wrap(err, "verifying credential")
+	}
+	return &result, nil
+}
+
+// VerifyPresentation requests the service verify the given presentation, which may be a JSON presentation or a
+// presentation secured as a JWT. A result is returned whether or not the presentation is verified; an error is
+// returned if the service could not process the request.
+func (c *Client) VerifyPresentation(ctx context.Context, pres any, opts *VerifyPresentationOptions) (*VerificationResult, error) {
+	var result VerificationResult
+	request := VerifyPresentationRequest{VerifiablePresentation: pres, Options: opts}
+	if err := c.post(ctx, c.baseURL+VerifyPresentationPath, request, &result, http.StatusOK, http.StatusBadRequest); err != nil {
+		return nil, errors.Wrap(err, "verifying presentation")
+	}
+	return &result, nil
+}
+
+// InitiateExchange initiates an exchange, returning the request for the presentation to submit
+func (c *Client) InitiateExchange(ctx context.Context, exchangeID string) (*VerifiablePresentationRequest, error) {
+	var response ExchangeResponse
+	if err := c.post(ctx, c.baseURL+ExchangesPath+"/"+exchangeID, struct{}{}, &response, http.StatusOK); err != nil {
+		return nil, errors.Wrapf(err, "initiating exchange<%s>", exchangeID)
+	}
+	if response.VerifiablePresentationRequest == nil {
+		return nil, errors.Errorf("exchange<%s> did not request a presentation", exchangeID)
+	}
+	return response.VerifiablePresentationRequest, nil
+}
+
+// Participate submits a presentation to the interaction service endpoint of a VerifiablePresentationRequest. If the
+// endpoint is a relative path, it is resolved against the client's base URL.
+func (c *Client) Participate(ctx context.Context, serviceEndpoint string, pres any) (*ExchangeResponse, error) {
+	if strings.HasPrefix(serviceEndpoint, "/") {
+		serviceEndpoint = c.baseURL + serviceEndpoint
+	}
+	var response ExchangeResponse
+	if err := c.post(ctx, serviceEndpoint, ParticipateRequest{VerifiablePresentation: pres}, &response, http.StatusOK); err != nil {
+		return nil, errors.Wrap(err, "participating in exchange")
+	}
+	return &response, nil
+}
+
+// post sends a JSON request, decoding the response into the given value if its status is one of those expected
+func (c *Client) post(ctx context.Context, url string, request, response any, expectedStatuses ...int) error {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "marshalling request")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(requestBody))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading response")
+	}
+
+	expected := false
+	for _, status := range expectedStatuses {
+		if resp.StatusCode == status {
+			expected = true
+			break
+		}
+	}
+	if !expected {
+		var errResponse ErrorResponse
+		if err = json.Unmarshal(responseBody, &errResponse); err == nil && errResponse.Message != "" {
+			return errors.Errorf("status code: %d, message: %s", resp.StatusCode, errResponse.Message)
+		}
+		return errors.Errorf("status code: %d", resp.StatusCode)
+	}
+	if err = json.Unmarshal(responseBody, response); err != nil {
+		return errors.Wrap(err, "unmarshalling response")
+	}
+	return nil
+}
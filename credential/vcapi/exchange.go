@@ -0,0 +1,191 @@
+package vcapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+)
+
+// ExchangerOptions configures an Exchanger
+type ExchangerOptions struct {
+	// BaseURL is the URL the VC API is served at, used to build the endpoints holders submit presentations to. If it
+	// is empty, the endpoints are relative paths.
+	BaseURL string
+	// Domain, if set, is the domain presentations must be bound to
+	Domain string
+	// OnSubmission is called with the verified data of each presentation submitted to an exchange
+	OnSubmission func(ctx context.Context, exchangeID, transactionID string, data []exchange.VerifiedSubmissionData)
+}
+
+// Exchanger runs exchanges in which holders present credentials fulfilling a presentation definition, serving the
+// exchanges endpoints. Each time an exchange is initiated, a transaction is started with its own challenge, which the
+// presentation submitted to it must be bound to. Transactions are held in memory and complete once a verified
+// presentation submission has been made to them.
+type Exchanger struct {
+	verifier *Verifier
+	opts     ExchangerOptions
+
+	mu           sync.Mutex
+	definitions  map[string]exchange.PresentationDefinition
+	transactions map[string]transaction
+}
+
+type transaction struct {
+	exchangeID string
+	challenge  string
+}
+
+// NewExchanger returns an exchanger verifying submitted presentations with the given verifier
+func NewExchanger(verifier *Verifier, opts ExchangerOptions) (*Exchanger, error) {
+	if verifier == nil {
+		return nil, errors.New("verifier cannot be empty")
+	}
+	return &Exchanger{
+		verifier:     verifier,
+		opts:         opts,
+		definitions:  make(map[string]exchange.PresentationDefinition),
+		transactions: make(map[string]transaction),
+	}, nil
+}
+
+// AddExchange adds an exchange, in which holders present credentials fulfilling the given presentation definition
+func (e *Exchanger) AddExchange(exchangeID string, def exchange.PresentationDefinition) error {
+	if exchangeID == "" {
+		return errors.New("exchange id cannot be empty")
+	}
+	if err := def.IsValid(); err != nil {
+		return errors.Wrapf(err, "presentation definition for exchange<%s> is not valid", exchangeID)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.definitions[exchangeID] = def
+	return nil
+}
+
+// Initiate starts a transaction in an exchange, returning the request for the presentation the holder must submit
+func (e *Exchanger) Initiate(exchangeID string) (*VerifiablePresentationRequest, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	def, ok := e.definitions[exchangeID]
+	if !ok {
+		return nil, fmt.Errorf("exchange<%s> not found", exchangeID)
+	}
+	transactionID := uuid.NewString()
+	tx := transaction{exchangeID: exchangeID, challenge: uuid.NewString()}
+	e.transactions[transactionID] = tx
+	return &VerifiablePresentationRequest{
+		Query:     []PresentationQuery{{Type: PresentationExchangeQueryType, PresentationDefinition: &def}},
+		Challenge: tx.challenge,
+		Domain:    e.opts.Domain,
+		Interact: &Interact{Service: []InteractService{{
+			Type:            UnmediatedPresentationServiceType,
+			ServiceEndpoint: strings.TrimSuffix(e.opts.BaseURL, "/") + ExchangesPath + "/" + exchangeID + "/" + transactionID,
+		}}},
+	}, nil
+}
+
+// Participate verifies a presentation submitted to a transaction of an exchange. The presentation must be bound to
+// the transaction's challenge, and be a valid submission for the exchange's presentation definition. Once a
+// presentation has been verified the transaction is complete, and no further presentations may be submitted to it.
+func (e *Exchanger) Participate(ctx context.Context, exchangeID, transactionID string, pres any) ([]exchange.VerifiedSubmissionData, error) {
+	e.mu.Lock()
+	tx, ok := e.transactions[transactionID]
+	def, hasDef := e.definitions[exchangeID]
+	e.mu.Unlock()
+	if !ok || tx.exchangeID != exchangeID || !hasDef {
+		return nil, fmt.Errorf("transaction<%s> not found in exchange<%s>", transactionID, exchangeID)
+	}
+
+	opts := VerifyPresentationOptions{Challenge: tx.challenge, Domain: e.opts.Domain}
+	if result := e.verifier.VerifyPresentation(ctx, pres, &opts); !result.IsVerified() {
+		return nil, fmt.Errorf("presentation not verified: %s", strings.Join(result.Errors, "; "))
+	}
+	vp, err := toPresentation(pres)
+	if err != nil {
+		return nil, err
+	}
+	data, err := exchange.VerifyPresentationSubmissionVP(def, *vp)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying presentation submission")
+	}
+
+	e.mu.Lock()
+	if _, ok = e.transactions[transactionID]; !ok {
+		e.mu.Unlock()
+		return nil, fmt.Errorf("transaction<%s> has already completed", transactionID)
+	}
+	delete(e.transactions, transactionID)
+	e.mu.Unlock()
+
+	if e.opts.OnSubmission != nil {
+		e.opts.OnSubmission(ctx, exchangeID, transactionID, data)
+	}
+	return data, nil
+}
+
+// InitiateHandler returns a handler initiating the exchange in the exchangeId path value
+func (e *Exchanger) InitiateHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, err := e.Initiate(r.PathValue("exchangeId"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, ExchangeResponse{VerifiablePresentationRequest: request})
+	})
+}
+
+// ParticipateHandler returns a handler verifying the presentation in a ParticipateRequest, submitted to the
+// transaction in the transactionId path value of the exchange in the exchangeId path value
+func (e *Exchanger) ParticipateHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request ParticipateRequest
+		if err := readRequest(r, &request); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if request.VerifiablePresentation == nil {
+			writeError(w, http.StatusBadRequest, errors.New("verifiablePresentation cannot be empty"))
+			return
+		}
+		if _, err := e.Participate(r.Context(), r.PathValue("exchangeId"), r.PathValue("transactionId"), request.VerifiablePresentation); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, ExchangeResponse{})
+	})
+}
+
+// toPresentation parses a JSON presentation, or a presentation secured as a JWT, as VerifyPresentationSignature does
+func toPresentation(pres any) (*credential.VerifiablePresentation, error) {
+	if token, ok := pres.(string); ok {
+		var vp credential.VerifiablePresentation
+		if err := json.Unmarshal([]byte(token), &vp); err == nil {
+			return &vp, nil
+		}
+		_, _, jwtVP, err := integrity.ParseVerifiablePresentationFromJWT(token)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing presentation from JWT")
+		}
+		return jwtVP, nil
+	}
+	presBytes, err := json.Marshal(pres)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling presentation")
+	}
+	var vp credential.VerifiablePresentation
+	if err = json.Unmarshal(presBytes, &vp); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling presentation")
+	}
+	return &vp, nil
+}
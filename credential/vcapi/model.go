@@ -0,0 +1,116 @@
+package vcapi
+
+import (
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+)
+
+// The VC API defines HTTP endpoints for issuing and verifying credentials, verifying presentations, and exchanging
+// presentations between holders and verifiers https://w3c-ccg.github.io/vc-api/
+
+const (
+	IssueCredentialPath    string = "/credentials/issue"
+	VerifyCredentialPath   string = "/credentials/verify"
+	VerifyPresentationPath string = "/presentations/verify"
+	ExchangesPath          string = "/exchanges"
+
+	// ProofCheck is the check made when the proof of a credential or presentation is verified
+	ProofCheck string = "proof"
+	// ValidationCheck is the check made when a credential is validated, such as against its validity period
+	ValidationCheck string = "validation"
+	// StatusCheck is the check made when a credential's status is checked, such as whether it is revoked or suspended
+	StatusCheck string = "status"
+
+	// PresentationExchangeQueryType queries for credentials with a DIF Presentation Definition
+	PresentationExchangeQueryType string = "PresentationExchange"
+	// UnmediatedPresentationServiceType is the interaction service holders submit presentations to directly
+	UnmediatedPresentationServiceType string = "UnmediatedPresentationService2021"
+)
+
+// IssueCredentialRequest is the body of a request to issue a credential
+// https://w3c-ccg.github.io/vc-api/#issue-credential
+type IssueCredentialRequest struct {
+	Credential credential.VerifiableCredential `json:"credential"`
+}
+
+// IssueCredentialResponse is the body of the response to a request to issue a credential
+type IssueCredentialResponse struct {
+	VerifiableCredential credential.VerifiableCredential `json:"verifiableCredential"`
+}
+
+// VerifyCredentialRequest is the body of a request to verify a credential, which may be a JSON credential or a
+// credential secured as a JWT https://w3c-ccg.github.io/vc-api/#verify-credential
+type VerifyCredentialRequest struct {
+	VerifiableCredential any `json:"verifiableCredential"`
+}
+
+// VerifyPresentationRequest is the body of a request to verify a presentation, which may be a JSON presentation or a
+// presentation secured as a JWT https://w3c-ccg.github.io/vc-api/#verify-presentation
+type VerifyPresentationRequest struct {
+	VerifiablePresentation any                        `json:"verifiablePresentation"`
+	Options                *VerifyPresentationOptions `json:"options,omitempty"`
+}
+
+// VerifyPresentationOptions are the challenge and domain the verifier supplied when requesting a presentation, which
+// the presentation's proof must be bound to
+type VerifyPresentationOptions struct {
+	Challenge string `json:"challenge,omitempty"`
+	Domain    string `json:"domain,omitempty"`
+}
+
+// VerificationResult is the result of verifying a credential or presentation, listing the checks which were made,
+// and the errors of those which failed
+type VerificationResult struct {
+	Checks   []string `json:"checks"`
+	Warnings []string `json:"warnings"`
+	Errors   []string `json:"errors"`
+}
+
+// IsVerified returns whether every check passed
+func (r VerificationResult) IsVerified() bool {
+	return len(r.Errors) == 0
+}
+
+// ErrorResponse is the body of a response to a request which could not be processed
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// ExchangeResponse is the body of the response to initiating or participating in an exchange. It holds the request
+// for the presentation the holder must submit next, if there is one.
+// https://w3c-ccg.github.io/vc-api/#initiate-exchange
+type ExchangeResponse struct {
+	VerifiablePresentationRequest *VerifiablePresentationRequest `json:"verifiablePresentationRequest,omitempty"`
+}
+
+// VerifiablePresentationRequest requests a presentation from a holder, bound to a challenge, which the holder submits
+// to one of the interaction services https://w3c-ccg.github.io/vp-request-spec/
+type VerifiablePresentationRequest struct {
+	Query     []PresentationQuery `json:"query"`
+	Challenge string              `json:"challenge"`
+	Domain    string              `json:"domain,omitempty"`
+	Interact  *Interact           `json:"interact,omitempty"`
+}
+
+// PresentationQuery describes the credentials a holder is requested to present
+type PresentationQuery struct {
+	Type                   string                           `json:"type"`
+	PresentationDefinition *exchange.PresentationDefinition `json:"presentationDefinition,omitempty"`
+}
+
+// Interact lists the services a holder can submit a presentation to
+type Interact struct {
+	Service []InteractService `json:"service"`
+}
+
+// InteractService is a service a holder can submit a presentation to
+type InteractService struct {
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+// ParticipateRequest is the body of a request submitting a presentation to an exchange
+// https://w3c-ccg.github.io/vc-api/#participate-in-an-exchange
+type ParticipateRequest struct {
+	VerifiablePresentation any `json:"verifiablePresentation"`
+}
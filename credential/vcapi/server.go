@@ -0,0 +1,278 @@
+package vcapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/credential/parsing"
+	"github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/credential/validation"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// maxRequestSize bounds the size of request bodies the handlers read
+const maxRequestSize = 1 << 20
+
+// Services are the VC API services mounted by NewServeMux; those which are nil are not mounted
+type Services struct {
+	Issuer    *Issuer
+	Verifier  *Verifier
+	Exchanger *Exchanger
+}
+
+// NewServeMux returns a mux serving the endpoints of the given services at their standard VC API paths
+func NewServeMux(services Services) *http.ServeMux {
+	mux := http.NewServeMux()
+	if services.Issuer != nil {
+		mux.Handle("POST "+IssueCredentialPath, services.Issuer)
+	}
+	if services.Verifier != nil {
+		mux.Handle("POST "+VerifyCredentialPath, services.Verifier.CredentialHandler())
+		mux.Handle("POST "+VerifyPresentationPath, services.Verifier.PresentationHandler())
+	}
+	if services.Exchanger != nil {
+		mux.Handle("POST "+ExchangesPath+"/{exchangeId}", services.Exchanger.InitiateHandler())
+		mux.Handle("POST "+ExchangesPath+"/{exchangeId}/{transactionId}", services.Exchanger.ParticipateHandler())
+	}
+	return mux
+}
+
+// Issuer issues credentials with Data Integrity proofs, serving the credentials/issue endpoint
+type Issuer struct {
+	signer cryptosuite.Signer
+	suite  cryptosuite.CryptoSuite
+}
+
+var _ http.Handler = (*Issuer)(nil)
+
+// NewIssuer returns an issuer signing credentials with the given signer and suite. If no suite is provided, the
+// JsonWebSignature2020 suite is used.
+func NewIssuer(signer cryptosuite.Signer, suite cryptosuite.CryptoSuite) (*Issuer, error) {
+	if signer == nil {
+		return nil, errors.New("signer cannot be empty")
+	}
+	if suite == nil {
+		suite = jws2020.GetJSONWebSignature2020Suite()
+	}
+	return &Issuer{signer: signer, suite: suite}, nil
+}
+
+// Issue signs a credential. If the credential has no issuer, the DID of the signer's key is used, and otherwise its
+// issuer must be that DID. If it has no issuance date, the current time is used. The given credential is not
+// modified.
+func (i *Issuer) Issue(cred credential.VerifiableCredential) (*credential.VerifiableCredential, error) {
+	if cred.IsEmpty() {
+		return nil, errors.New("credential cannot be empty")
+	}
+	signerDID, _, _ := strings.Cut(i.signer.GetKeyID(), "#")
+	if cred.Issuer == nil || cred.IssuerID() == "" {
+		cred.Issuer = signerDID
+	} else if cred.IssuerID() != signerDID {
+		return nil, fmt.Errorf("credential issuer<%s> is not the DID<%s> of the signer's key", cred.IssuerID(), signerDID)
+	}
+	if cred.IssuanceDate == "" && cred.ValidFrom == "" {
+		cred.IssuanceDate = util.GetRFC3339Timestamp()
+	}
+	if err := cred.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "credential is not valid")
+	}
+	if err := i.suite.Sign(i.signer, &cred); err != nil {
+		return nil, errors.Wrap(err, "signing credential")
+	}
+	return &cred, nil
+}
+
+// ServeHTTP issues the credential in an IssueCredentialRequest
+func (i *Issuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request IssueCredentialRequest
+	if err := readRequest(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	issued, err := i.Issue(request.Credential)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, IssueCredentialResponse{VerifiableCredential: *issued})
+}
+
+// Verifier verifies credentials and presentations, serving the credentials/verify and presentations/verify endpoints
+type Verifier struct {
+	resolver      resolution.Resolver
+	validator     *validation.CredentialValidator
+	statusChecker *status.Checker
+}
+
+// NewVerifier returns a verifier resolving the DIDs of issuers and holders with the given resolver. After their
+// proofs are verified, credentials are validated with the given validators; if none are provided, credentials are
+// validated against the data model and their validity period.
+func NewVerifier(r resolution.Resolver, validators ...validation.Validator) (*Verifier, error) {
+	if r == nil {
+		return nil, errors.New("resolution cannot be empty")
+	}
+	if len(validators) == 0 {
+		validators = []validation.Validator{
+			{ID: "Data Model Validation", ValidateFunc: validation.ValidateCredential},
+			{ID: "Validity Period Check", ValidateFunc: validation.ValidateTimes},
+		}
+	}
+	validator, err := validation.NewCredentialValidator(validators)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating credential validator")
+	}
+	return &Verifier{resolver: r, validator: validator}, nil
+}
+
+// SetStatusChecker sets the checker the status of credentials is checked with once they are verified. A revoked,
+// suspended, or unknown status fails the status check, with an error naming the outcome, and the messages of set
+// statuses, such as those of the message status purpose, are returned as warnings.
+func (v *Verifier) SetStatusChecker(checker *status.Checker) {
+	v.statusChecker = checker
+}
+
+// VerifyCredential verifies the proof of a credential of any type supported by integrity.VerifyCredentialSignature,
+// then validates it, and checks its status if a status checker is set
+func (v *Verifier) VerifyCredential(ctx context.Context, cred any) VerificationResult {
+	result := newVerificationResult(ProofCheck)
+	if verified, err := integrity.VerifyCredentialSignature(ctx, cred, v.resolver); err != nil || !verified {
+		result.Errors = append(result.Errors, failureMessage(ProofCheck, err))
+		return result
+	}
+	result.Checks = append(result.Checks, ValidationCheck)
+	_, _, parsed, err := parsing.ToCredential(cred)
+	if err != nil {
+		result.Errors = append(result.Errors, failureMessage(ValidationCheck, err))
+		return result
+	}
+	if err = v.validator.ValidateCredential(*parsed); err != nil {
+		result.Errors = append(result.Errors, failureMessage(ValidationCheck, err))
+	}
+	if v.statusChecker != nil {
+		result.Checks = append(result.Checks, StatusCheck)
+		v.checkStatus(ctx, *parsed, &result)
+	}
+	return result
+}
+
+// checkStatus adds the outcome of checking a credential's status to a verification result
+func (v *Verifier) checkStatus(ctx context.Context, cred credential.VerifiableCredential, result *VerificationResult) {
+	report, err := v.statusChecker.GetStatusReport(ctx, cred)
+	if err == nil {
+		err = report.Err()
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, failureMessage(StatusCheck, err))
+	}
+	if report != nil {
+		for _, message := range report.Messages() {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s", StatusCheck, message))
+		}
+	}
+}
+
+// VerifyPresentation verifies the proof of a presentation of any type supported by
+// integrity.VerifyPresentationSignature, and the proofs of the credentials it contains, enforcing the challenge and
+// domain in the options if they are provided
+func (v *Verifier) VerifyPresentation(ctx context.Context, pres any, opts *VerifyPresentationOptions) VerificationResult {
+	result := newVerificationResult(ProofCheck)
+	var verificationOpts []integrity.VerificationOption
+	if opts != nil {
+		verificationOpts = append(verificationOpts, integrity.WithChallenge(opts.Challenge), integrity.WithDomain(opts.Domain))
+	}
+	if verified, err := integrity.VerifyPresentationSignature(ctx, pres, v.resolver, verificationOpts...); err != nil || !verified {
+		result.Errors = append(result.Errors, failureMessage(ProofCheck, err))
+	}
+	return result
+}
+
+// CredentialHandler returns a handler verifying the credential in a VerifyCredentialRequest. A VerificationResult is
+// returned with a 200 status if the credential is verified, and a 400 status otherwise.
+func (v *Verifier) CredentialHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request VerifyCredentialRequest
+		if err := readRequest(r, &request); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if request.VerifiableCredential == nil {
+			writeError(w, http.StatusBadRequest, errors.New("verifiableCredential cannot be empty"))
+			return
+		}
+		writeVerificationResult(w, v.VerifyCredential(r.Context(), request.VerifiableCredential))
+	})
+}
+
+// PresentationHandler returns a handler verifying the presentation in a VerifyPresentationRequest. A
+// VerificationResult is returned with a 200 status if the presentation is verified, and a 400 status otherwise.
+func (v *Verifier) PresentationHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request VerifyPresentationRequest
+		if err := readRequest(r, &request); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if request.VerifiablePresentation == nil {
+			writeError(w, http.StatusBadRequest, errors.New("verifiablePresentation cannot be empty"))
+			return
+		}
+		writeVerificationResult(w, v.VerifyPresentation(r.Context(), request.VerifiablePresentation, request.Options))
+	})
+}
+
+func newVerificationResult(checks ...string) VerificationResult {
+	return VerificationResult{Checks: checks, Warnings: []string{}, Errors: []string{}}
+}
+
+func failureMessage(check string, err error) string {
+	if err == nil {
+		return fmt.Sprintf("%s: not verified", check)
+	}
+	return fmt.Sprintf("%s: %s", check, err.Error())
+}
+
+// readRequest decodes the JSON body of a request
+func readRequest(r *http.Request, v any) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		return errors.Wrap(err, "reading request")
+	}
+	if err = json.Unmarshal(body, v); err != nil {
+		return errors.Wrap(err, "unmarshalling request")
+	}
+	return nil
+}
+
+func writeVerificationResult(w http.ResponseWriter, result VerificationResult) {
+	status := http.StatusOK
+	if !result.IsVerified() {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, result)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Message: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, "marshalling response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
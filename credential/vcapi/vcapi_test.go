@@ -0,0 +1,191 @@
+package vcapi
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/schema"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// TestMain is used to set up schema caching in order to load all schemas locally
+func TestMain(m *testing.M) {
+	localSchemas, err := schema.GetAllLocalSchemas()
+	if err != nil {
+		os.Exit(1)
+	}
+	loader, err := schema.NewCachingLoader(localSchemas)
+	if err != nil {
+		os.Exit(1)
+	}
+	loader.EnableHTTPCache()
+	os.Exit(m.Run())
+}
+
+func TestVCAPI(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	issuerKey, issuerDID, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := issuerDID.Expand()
+	require.NoError(t, err)
+	issuerKID := expanded.VerificationMethod[0].ID
+	_, issuerJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&issuerKID, issuerKey)
+	require.NoError(t, err)
+	signer, err := jws2020.NewJSONWebKeySigner(issuerKID, *issuerJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+
+	holderKey, holderDID, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err = holderDID.Expand()
+	require.NoError(t, err)
+	holderKID := expanded.VerificationMethod[0].ID
+	holderSigner, err := jwx.NewJWXSigner(holderDID.String(), &holderKID, holderKey)
+	require.NoError(t, err)
+
+	issuer, err := NewIssuer(signer, nil)
+	require.NoError(t, err)
+	verifier, err := NewVerifier(resolver)
+	require.NoError(t, err)
+	var submitted []exchange.VerifiedSubmissionData
+	exchanger, err := NewExchanger(verifier, ExchangerOptions{
+		OnSubmission: func(_ context.Context, _, _ string, data []exchange.VerifiedSubmissionData) {
+			submitted = data
+		},
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewServeMux(Services{Issuer: issuer, Verifier: verifier, Exchanger: exchanger}))
+	defer server.Close()
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	unsigned := credential.VerifiableCredential{
+		Context:           []any{credential.VerifiableCredentialsLinkedDataContext, "https://w3id.org/security/suites/jws-2020/v1"},
+		ID:                "https://example.com/credentials/1",
+		Type:              []string{credential.VerifiableCredentialType},
+		CredentialSubject: map[string]any{"id": holderDID.String(), "name": "Satoshi"},
+	}
+	issued, err := client.IssueCredential(ctx, unsigned)
+	require.NoError(t, err)
+
+	t.Run("issue", func(tt *testing.T) {
+		assert.Equal(tt, issuerDID.String(), issued.IssuerID())
+		assert.NotEmpty(tt, issued.IssuanceDate)
+		assert.NotNil(tt, issued.GetProof())
+
+		_, err := client.IssueCredential(ctx, credential.VerifiableCredential{ID: "incomplete"})
+		assert.ErrorContains(tt, err, "status code: 400")
+
+		// credentials may only be issued by the DID of the signer's key
+		reissued := unsigned
+		reissued.Issuer = issuerDID.String()
+		_, err = issuer.Issue(reissued)
+		assert.NoError(tt, err)
+		forged := unsigned
+		forged.Issuer = holderDID.String()
+		_, err = issuer.Issue(forged)
+		assert.ErrorContains(tt, err, fmt.Sprintf("credential issuer<%s> is not the DID<%s> of the signer's key", holderDID.String(), issuerDID.String()))
+	})
+
+	t.Run("verify credential", func(tt *testing.T) {
+		result, err := client.VerifyCredential(ctx, issued)
+		assert.NoError(tt, err)
+		assert.True(tt, result.IsVerified())
+		assert.Equal(tt, []string{ProofCheck, ValidationCheck}, result.Checks)
+
+		tampered := *issued
+		tampered.IssuanceDate = "2022-01-01T19:23:24Z"
+		result, err = client.VerifyCredential(ctx, tampered)
+		assert.NoError(tt, err)
+		assert.False(tt, result.IsVerified())
+		assert.Len(tt, result.Errors, 1)
+	})
+
+	t.Run("verify presentation", func(tt *testing.T) {
+		pres := credential.VerifiablePresentation{
+			Context:              []any{credential.VerifiableCredentialsLinkedDataContext},
+			Type:                 []string{credential.VerifiablePresentationType},
+			Holder:               holderDID.String(),
+			VerifiableCredential: []any{issued},
+		}
+		token, err := integrity.SignVerifiablePresentationJWT(*holderSigner, &integrity.JWTVVPParameters{Challenge: "challenge"}, pres)
+		require.NoError(tt, err)
+
+		result, err := client.VerifyPresentation(ctx, string(token), &VerifyPresentationOptions{Challenge: "challenge"})
+		assert.NoError(tt, err)
+		assert.True(tt, result.IsVerified())
+
+		result, err = client.VerifyPresentation(ctx, string(token), &VerifyPresentationOptions{Challenge: "other"})
+		assert.NoError(tt, err)
+		assert.False(tt, result.IsVerified())
+		assert.Contains(tt, result.Errors[0], "challenge mismatch")
+	})
+
+	t.Run("exchange", func(tt *testing.T) {
+		def := exchange.PresentationDefinition{
+			ID: "name-definition",
+			InputDescriptors: []exchange.InputDescriptor{{
+				ID: "name",
+				Constraints: &exchange.Constraints{Fields: []exchange.Field{{
+					Path: []string{"$.credentialSubject.name"},
+				}}},
+			}},
+		}
+		require.NoError(tt, exchanger.AddExchange("name-exchange", def))
+
+		_, err := client.InitiateExchange(ctx, "unknown-exchange")
+		assert.ErrorContains(tt, err, "status code: 404")
+
+		request, err := client.InitiateExchange(ctx, "name-exchange")
+		require.NoError(tt, err)
+		require.Len(tt, request.Query, 1)
+		assert.Equal(tt, def.ID, request.Query[0].PresentationDefinition.ID)
+		require.NotNil(tt, request.Interact)
+		endpoint := request.Interact.Service[0].ServiceEndpoint
+
+		credJSON, err := util.ToJSONMap(issued)
+		require.NoError(tt, err)
+		vp, err := exchange.BuildPresentationSubmissionVP(holderDID.String(), *request.Query[0].PresentationDefinition, []exchange.NormalizedClaim{{
+			ID:             issued.ID,
+			Data:           credJSON,
+			RawClaim:       issued,
+			Format:         exchange.LDPVC.String(),
+			AlgOrProofType: string(jws2020.JSONWebSignature2020),
+		}})
+		require.NoError(tt, err)
+
+		// a presentation bound to another challenge is rejected
+		replayed, err := integrity.SignVerifiablePresentationJWT(*holderSigner, &integrity.JWTVVPParameters{Challenge: "other"}, *vp)
+		require.NoError(tt, err)
+		_, err = client.Participate(ctx, endpoint, string(replayed))
+		assert.ErrorContains(tt, err, "challenge mismatch")
+
+		token, err := integrity.SignVerifiablePresentationJWT(*holderSigner, &integrity.JWTVVPParameters{Challenge: request.Challenge}, *vp)
+		require.NoError(tt, err)
+		_, err = client.Participate(ctx, endpoint, string(token))
+		assert.NoError(tt, err)
+		require.Len(tt, submitted, 1)
+		assert.Equal(tt, "name", submitted[0].InputDescriptorID)
+
+		// the transaction is complete
+		_, err = client.Participate(ctx, endpoint, string(token))
+		assert.ErrorContains(tt, err, "not found")
+	})
+}
@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/veraison/go-cose"
+)
+
+// GetCOSEAlgorithmForPrivateKey returns the COSE signature algorithm for a private key. Supported keys are Ed25519,
+// ECDSA using the P-256, P-384, and P-521 curves, and RSA.
+func GetCOSEAlgorithmForPrivateKey(key crypto.PrivateKey) (cose.Algorithm, error) {
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return cose.AlgorithmEdDSA, nil
+	case ecdsa.PrivateKey:
+		return coseAlgorithmForCurve(k.Curve)
+	case *ecdsa.PrivateKey:
+		return coseAlgorithmForCurve(k.Curve)
+	case rsa.PrivateKey, *rsa.PrivateKey:
+		return cose.AlgorithmPS256, nil
+	default:
+		return 0, fmt.Errorf("unsupported private key type for cose: %T", k)
+	}
+}
+
+func coseAlgorithmForCurve(curve elliptic.Curve) (cose.Algorithm, error) {
+	switch curve {
+	case elliptic.P256():
+		return cose.AlgorithmES256, nil
+	case elliptic.P384():
+		return cose.AlgorithmES384, nil
+	case elliptic.P521():
+		return cose.AlgorithmES512, nil
+	default:
+		return 0, fmt.Errorf("unsupported curve for cose: %s", curve.Params().Name)
+	}
+}
+
+// NewCOSESigner returns a COSE signer for a private key, using the algorithm given by GetCOSEAlgorithmForPrivateKey
+func NewCOSESigner(key crypto.PrivateKey) (cose.Signer, error) {
+	alg, err := GetCOSEAlgorithmForPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	var signer crypto.Signer
+	switch k := key.(type) {
+	case ecdsa.PrivateKey:
+		signer = &k
+	case rsa.PrivateKey:
+		signer = &k
+	case crypto.Signer:
+		signer = k
+	}
+	coseSigner, err := cose.NewSigner(alg, signer)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cose signer")
+	}
+	return coseSigner, nil
+}
+
+// NewCOSEVerifier returns a COSE verifier for a public key and the algorithm it is expected to be used with
+func NewCOSEVerifier(alg cose.Algorithm, key crypto.PublicKey) (cose.Verifier, error) {
+	switch k := key.(type) {
+	case ecdsa.PublicKey:
+		key = &k
+	case rsa.PublicKey:
+		key = &k
+	}
+	verifier, err := cose.NewVerifier(alg, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cose verifier")
+	}
+	return verifier, nil
+}
+
+// SignCOSESign1 signs a payload with a private key, returning the COSE_Sign1 message
+// https://www.rfc-editor.org/rfc/rfc9052#name-signing-with-one-signer
+// The algorithm header is set from the key; other headers are set on the message before it is signed.
+func SignCOSESign1(key crypto.PrivateKey, headers cose.Headers, payload []byte) (*cose.Sign1Message, error) {
+	signer, err := NewCOSESigner(key)
+	if err != nil {
+		return nil, err
+	}
+	if headers.Protected == nil {
+		headers.Protected = cose.ProtectedHeader{}
+	}
+	headers.Protected.SetAlgorithm(signer.Algorithm())
+	msg := &cose.Sign1Message{Headers: headers, Payload: payload}
+	if err = msg.Sign(rand.Reader, nil, signer); err != nil {
+		return nil, errors.Wrap(err, "signing cose message")
+	}
+	return msg, nil
+}
+
+// VerifyCOSESign1 verifies a COSE_Sign1 message with a public key, using the algorithm in its protected header
+func VerifyCOSESign1(msg *cose.Sign1Message, key crypto.PublicKey) error {
+	alg, err := msg.Headers.Protected.Algorithm()
+	if err != nil {
+		return errors.Wrap(err, "getting cose algorithm")
+	}
+	verifier, err := NewCOSEVerifier(alg, key)
+	if err != nil {
+		return err
+	}
+	if err = msg.Verify(nil, verifier); err != nil {
+		return errors.Wrap(err, "verifying cose signature")
+	}
+	return nil
+}
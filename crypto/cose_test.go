@@ -0,0 +1,31 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veraison/go-cose"
+)
+
+func TestSignAndVerifyCOSESign1(t *testing.T) {
+	for _, kt := range []KeyType{Ed25519, P256, P384, P521, RSA} {
+		t.Run(string(kt), func(tt *testing.T) {
+			pubKey, privKey, err := GenerateKeyByKeyType(kt)
+			assert.NoError(tt, err)
+
+			msg, err := SignCOSESign1(privKey, cose.Headers{}, []byte("hello"))
+			assert.NoError(tt, err)
+			assert.NoError(tt, VerifyCOSESign1(msg, pubKey))
+
+			msg.Payload = []byte("goodbye")
+			assert.Error(tt, VerifyCOSESign1(msg, pubKey))
+		})
+	}
+
+	t.Run("unsupported key type", func(tt *testing.T) {
+		_, privKey, err := GenerateKeyByKeyType(SECP256k1)
+		assert.NoError(tt, err)
+		_, err = SignCOSESign1(privKey, cose.Headers{}, []byte("hello"))
+		assert.ErrorContains(tt, err, "unsupported private key type for cose")
+	})
+}
@@ -33,8 +33,12 @@ func (*Signer) Parse(token string) (jws.Headers, jwt.Token, error) {
 
 // VerifyJWS parses a token given the verifier's known algorithm and key, and returns an error, which is nil upon success.
 func (v *Verifier) VerifyJWS(token string) error {
-	key := jws.WithKey(jwa.SignatureAlgorithm(v.ALG), v.publicKey)
-	if _, err := jws.Verify([]byte(token), key); err != nil {
+	alg := jwa.SignatureAlgorithm(v.ALG)
+	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+	if alg == "Ed25519" {
+		alg = jwa.EdDSA
+	}
+	if _, err := jws.Verify([]byte(token), jws.WithKey(alg, v.publicKey)); err != nil {
 		return errors.Wrap(err, "verifying JWT")
 	}
 	return nil
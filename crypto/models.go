@@ -1,7 +1,6 @@
 package crypto
 
 type (
-	Proof              any
 	KeyType            string
 	HashType           string
 	SignatureAlgorithm string
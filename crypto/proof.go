@@ -0,0 +1,206 @@
+package crypto
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// Proof is a single embedded proof, as defined by https://www.w3.org/TR/vc-data-integrity/#proofs
+// Properties common to most suites are modeled as fields. All other properties, such as those defined by a specific
+// suite, are kept in Extensions so that a proof round-trips through JSON without loss.
+type Proof struct {
+	Context            any
+	ID                 string
+	Type               string
+	CryptoSuite        string
+	Created            string
+	Expires            string
+	VerificationMethod string
+	ProofPurpose       string
+	Challenge          string
+	Domain             string
+	Nonce              string
+	JWS                string
+	ProofValue         string
+
+	// Extensions holds properties which are not modeled as fields, keyed by their JSON name
+	Extensions map[string]any
+
+	// Set holds the proofs of a proof set https://www.w3.org/TR/vc-data-integrity/#proof-sets, when the proof was
+	// given as an array of proofs. A proof set has no properties of its own, so suites verifying it as a single proof
+	// reject it.
+	Set []Proof
+}
+
+// IsSet returns whether the proof is a proof set
+func (p *Proof) IsSet() bool {
+	return p != nil && p.Set != nil
+}
+
+// Proofs returns the proofs of a proof set, or the proof itself if it is a single proof
+func (p *Proof) Proofs() []Proof {
+	if p == nil {
+		return nil
+	}
+	if p.IsSet() {
+		return p.Set
+	}
+	return []Proof{*p}
+}
+
+// stringProperties maps the JSON names of string-valued properties to their fields
+func (p *Proof) stringProperties() map[string]*string {
+	return map[string]*string{
+		"id":                 &p.ID,
+		"type":               &p.Type,
+		"cryptosuite":        &p.CryptoSuite,
+		"created":            &p.Created,
+		"expires":            &p.Expires,
+		"verificationMethod": &p.VerificationMethod,
+		"proofPurpose":       &p.ProofPurpose,
+		"challenge":          &p.Challenge,
+		"domain":             &p.Domain,
+		"nonce":              &p.Nonce,
+		"jws":                &p.JWS,
+		"proofValue":         &p.ProofValue,
+	}
+}
+
+// GetProperty returns the value of a proof property by its JSON name, and whether it is present
+func (p *Proof) GetProperty(name string) (any, bool) {
+	if p == nil {
+		return nil, false
+	}
+	if name == "@context" {
+		return p.Context, p.Context != nil
+	}
+	if field, ok := p.stringProperties()[name]; ok && *field != "" {
+		return *field, true
+	}
+	value, ok := p.Extensions[name]
+	return value, ok
+}
+
+// SetProperty sets a proof property by its JSON name. Values for modeled properties which are not of the expected
+// type, such as a `domain` given as an array, are kept in Extensions.
+func (p *Proof) SetProperty(name string, value any) {
+	if p == nil {
+		return
+	}
+	if name == "@context" {
+		p.Context = value
+		return
+	}
+	if field, ok := p.stringProperties()[name]; ok {
+		if s, isString := value.(string); isString {
+			*field = s
+			delete(p.Extensions, name)
+			return
+		}
+		*field = ""
+	}
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[name] = value
+}
+
+// DeleteProperty removes a proof property by its JSON name
+func (p *Proof) DeleteProperty(name string) {
+	if p == nil {
+		return
+	}
+	if name == "@context" {
+		p.Context = nil
+		return
+	}
+	if field, ok := p.stringProperties()[name]; ok {
+		*field = ""
+	}
+	delete(p.Extensions, name)
+}
+
+// ToMap returns the proof as a generic JSON object, which is empty for proof sets
+func (p Proof) ToMap() map[string]any {
+	result := make(map[string]any, len(p.Extensions)+4)
+	for name, value := range p.Extensions {
+		result[name] = value
+	}
+	if p.Context != nil {
+		result["@context"] = p.Context
+	}
+	for name, field := range p.stringProperties() {
+		if *field != "" {
+			result[name] = *field
+		}
+	}
+	return result
+}
+
+func (p Proof) MarshalJSON() ([]byte, error) {
+	if p.Set != nil {
+		return json.Marshal(p.Set)
+	}
+	return json.Marshal(p.ToMap())
+}
+
+func (p *Proof) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var set []Proof
+		if err := json.Unmarshal(trimmed, &set); err != nil {
+			return errors.Wrap(err, "proof set must be an array of proof objects")
+		}
+		if len(set) == 0 {
+			return errors.New("proof set cannot be empty")
+		}
+		*p = Proof{Set: set}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// keep numbers as written so they round-trip without loss of precision
+	decoder.UseNumber()
+	var properties map[string]any
+	if err := decoder.Decode(&properties); err != nil {
+		return errors.Wrap(err, "proof must be a proof object or a proof set")
+	}
+	*p = Proof{}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p.SetProperty(name, properties[name])
+	}
+	return nil
+}
+
+// ProofFromAny converts a value which marshals to a JSON object, such as a suite-specific proof type or a generic map,
+// or to an array of them as a proof set, into a Proof
+func ProofFromAny(value any) (*Proof, error) {
+	switch typedValue := value.(type) {
+	case nil:
+		return nil, errors.New("proof cannot be empty")
+	case Proof:
+		return &typedValue, nil
+	case *Proof:
+		if typedValue == nil {
+			return nil, errors.New("proof cannot be empty")
+		}
+		return typedValue, nil
+	}
+	proofBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling proof")
+	}
+	var proof Proof
+	if err = json.Unmarshal(proofBytes, &proof); err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
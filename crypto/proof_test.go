@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProof(t *testing.T) {
+	t.Run("round trips unknown properties", func(tt *testing.T) {
+		proofJSON := `{"type":"JsonWebSignature2020","created":"2021-01-01T19:23:24Z","verificationMethod":"did:example:123#key-1","proofPurpose":"assertionMethod","jws":"abc..def","capabilityChain":["urn:zcap:root:abc"],"amount":12345678901234567890}`
+		var proof Proof
+		err := json.Unmarshal([]byte(proofJSON), &proof)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "JsonWebSignature2020", proof.Type)
+		assert.Equal(tt, "did:example:123#key-1", proof.VerificationMethod)
+		assert.Equal(tt, "abc..def", proof.JWS)
+		assert.Len(tt, proof.Extensions, 2)
+
+		chain, ok := proof.GetProperty("capabilityChain")
+		assert.True(tt, ok)
+		assert.Equal(tt, []any{"urn:zcap:root:abc"}, chain)
+
+		proofBytes, err := json.Marshal(proof)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, proofJSON, string(proofBytes))
+	})
+
+	t.Run("properties with an unexpected type are kept as extensions", func(tt *testing.T) {
+		var proof Proof
+		err := json.Unmarshal([]byte(`{"type":"DataIntegrityProof","domain":["a.example.com","b.example.com"]}`), &proof)
+		assert.NoError(tt, err)
+		assert.Empty(tt, proof.Domain)
+
+		domain, ok := proof.GetProperty("domain")
+		assert.True(tt, ok)
+		assert.Equal(tt, []any{"a.example.com", "b.example.com"}, domain)
+
+		proof.SetProperty("domain", "a.example.com")
+		assert.Equal(tt, "a.example.com", proof.Domain)
+		assert.Empty(tt, proof.Extensions)
+
+		proof.DeleteProperty("domain")
+		_, ok = proof.GetProperty("domain")
+		assert.False(tt, ok)
+	})
+
+	t.Run("proof sets", func(tt *testing.T) {
+		proofJSON := `[{"type":"DataIntegrityProof","cryptosuite":"eddsa-2022","proofValue":"z123"},{"type":"JsonWebSignature2020","jws":"abc..def"}]`
+		var proof Proof
+		err := json.Unmarshal([]byte(proofJSON), &proof)
+		assert.NoError(tt, err)
+		assert.True(tt, proof.IsSet())
+		assert.Empty(tt, proof.Type)
+
+		proofs := proof.Proofs()
+		assert.Len(tt, proofs, 2)
+		assert.Equal(tt, "eddsa-2022", proofs[0].CryptoSuite)
+		assert.Equal(tt, "abc..def", proofs[1].JWS)
+
+		proofBytes, err := json.Marshal(proof)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, proofJSON, string(proofBytes))
+
+		err = json.Unmarshal([]byte(`[]`), &proof)
+		assert.ErrorContains(tt, err, "proof set cannot be empty")
+		err = json.Unmarshal([]byte(`"proof"`), &proof)
+		assert.ErrorContains(tt, err, "proof must be a proof object or a proof set")
+
+		single := Proof{Type: "DataIntegrityProof"}
+		assert.False(tt, single.IsSet())
+		assert.Equal(tt, []Proof{single}, single.Proofs())
+	})
+
+	t.Run("from any", func(tt *testing.T) {
+		_, err := ProofFromAny(nil)
+		assert.ErrorContains(tt, err, "proof cannot be empty")
+
+		proof, err := ProofFromAny(map[string]any{"type": "DataIntegrityProof", "cryptosuite": "eddsa-2022"})
+		assert.NoError(tt, err)
+		assert.Equal(tt, "DataIntegrityProof", proof.Type)
+		assert.Equal(tt, "eddsa-2022", proof.CryptoSuite)
+
+		same, err := ProofFromAny(proof)
+		assert.NoError(tt, err)
+		assert.Same(tt, proof, same)
+
+		set, err := ProofFromAny([]any{map[string]any{"type": "DataIntegrityProof"}})
+		assert.NoError(tt, err)
+		assert.True(tt, set.IsSet())
+		assert.Equal(tt, "DataIntegrityProof", set.Proofs()[0].Type)
+	})
+}
@@ -0,0 +1,131 @@
+package cryptosuite
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// URDNA2015Algorithm identifies the URDNA2015 RDF dataset canonicalization algorithm
+// https://www.w3.org/community/reports/credentials/CG-FINAL-rdf-dataset-canonicalization-20221009/
+const URDNA2015Algorithm = "https://w3id.org/security#URDNA2015"
+
+// Canonicalizer transforms a JSON-LD document into a canonical form prior to hashing. Alternate implementations, such
+// as a faster URDNA2015 implementation or RDFC-1.0, may be provided to suites using WithCanonicalizer.
+type Canonicalizer interface {
+	// Algorithm returns an identifier for the canonicalization algorithm, e.g. URDNA2015Algorithm
+	Algorithm() string
+	// Canonicalize returns the canonical form of a document. Contexts are resolved using the provided loader, or
+	// the library's default loader when the provided loader is nil.
+	Canonicalize(document map[string]any, loader DocumentLoader) (string, error)
+}
+
+var _ Canonicalizer = (*URDNA2015Canonicalizer)(nil)
+
+// URDNA2015Canonicalizer is the default Canonicalizer, producing N-Quads using the json-gold library
+type URDNA2015Canonicalizer struct{}
+
+func (URDNA2015Canonicalizer) Algorithm() string {
+	return URDNA2015Algorithm
+}
+
+func (URDNA2015Canonicalizer) Canonicalize(document map[string]any, loader DocumentLoader) (string, error) {
+	var normalized any
+	var err error
+	if loader != nil {
+		normalized, err = util.LDNormalizeWithDocumentLoader(document, loader)
+	} else {
+		normalized, err = util.LDNormalize(document)
+	}
+	if err != nil {
+		return "", err
+	}
+	canonical, ok := normalized.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected canonical form type: %T", normalized)
+	}
+	return canonical, nil
+}
+
+// CanonicalizationCache is a bounded, least-recently-used cache of canonicalized documents. Canonicalization
+// (e.g. URDNA2015) is expensive, and signing or verifying many similar documents repeats the same work.
+// Entries are keyed by the digest of a document's JCS canonical form, which captures both the document's data and
+// its set of contexts. Since the output also depends on how contexts are resolved, a cache should only be
+// shared between suites which use equivalent document loaders.
+type CanonicalizationCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+type canonicalizationEntry struct {
+	key       string
+	canonical string
+}
+
+// NewCanonicalizationCache creates a cache holding at most maxEntries canonicalized documents
+func NewCanonicalizationCache(maxEntries int) (*CanonicalizationCache, error) {
+	if maxEntries <= 0 {
+		return nil, errors.New("max entries must be greater than zero")
+	}
+	return &CanonicalizationCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}, nil
+}
+
+// CanonicalizationCacheKey computes the cache key for a marshaled JSON document
+func CanonicalizationCacheKey(marshaled []byte) (string, error) {
+	return DocumentDigest(marshaled)
+}
+
+// Get returns the canonical form of the document with the given key, if present
+func (c *CanonicalizationCache) Get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*canonicalizationEntry).canonical, true
+}
+
+// Put adds the canonical form of a document to the cache, evicting the least recently used entry when full
+func (c *CanonicalizationCache) Put(key, canonical string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*canonicalizationEntry).canonical = canonical
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&canonicalizationEntry{key: key, canonical: canonical})
+	if c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*canonicalizationEntry).key)
+	}
+}
+
+// Len returns the number of documents in the cache
+func (c *CanonicalizationCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
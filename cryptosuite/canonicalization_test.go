@@ -0,0 +1,99 @@
+package cryptosuite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalizationCache(t *testing.T) {
+	t.Run("bad size", func(tt *testing.T) {
+		_, err := NewCanonicalizationCache(0)
+		assert.Error(tt, err)
+	})
+
+	t.Run("key ignores formatting", func(tt *testing.T) {
+		a, err := CanonicalizationCacheKey([]byte(`{"@context": ["https://www.w3.org/2018/credentials/v1"], "id": "123"}`))
+		assert.NoError(tt, err)
+		b, err := CanonicalizationCacheKey([]byte(`{"id":"123","@context":["https://www.w3.org/2018/credentials/v1"]}`))
+		assert.NoError(tt, err)
+		assert.Equal(tt, a, b)
+
+		c, err := CanonicalizationCacheKey([]byte(`{"id":"123","@context":["https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/v2"]}`))
+		assert.NoError(tt, err)
+		assert.NotEqual(tt, a, c)
+	})
+
+	t.Run("evicts least recently used", func(tt *testing.T) {
+		cache, err := NewCanonicalizationCache(2)
+		assert.NoError(tt, err)
+
+		cache.Put("a", "canonical-a")
+		cache.Put("b", "canonical-b")
+
+		// touch a so b becomes the oldest entry
+		got, ok := cache.Get("a")
+		assert.True(tt, ok)
+		assert.Equal(tt, "canonical-a", got)
+
+		cache.Put("c", "canonical-c")
+		assert.Equal(tt, 2, cache.Len())
+
+		_, ok = cache.Get("b")
+		assert.False(tt, ok)
+		_, ok = cache.Get("a")
+		assert.True(tt, ok)
+		_, ok = cache.Get("c")
+		assert.True(tt, ok)
+	})
+
+	t.Run("nil cache is a no-op", func(tt *testing.T) {
+		var cache *CanonicalizationCache
+		cache.Put("a", "canonical-a")
+		_, ok := cache.Get("a")
+		assert.False(tt, ok)
+		assert.Equal(tt, 0, cache.Len())
+	})
+}
+
+func TestURDNA2015Canonicalizer(t *testing.T) {
+	canonicalizer := URDNA2015Canonicalizer{}
+	assert.Equal(t, URDNA2015Algorithm, canonicalizer.Algorithm())
+
+	document := map[string]any{
+		"@context": map[string]any{"name": "http://schema.org/name"},
+		"@id":      "did:example:123",
+		"name":     "Satoshi",
+	}
+	canonical, err := canonicalizer.Canonicalize(document, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "<did:example:123> <http://schema.org/name> \"Satoshi\" .\n", canonical)
+
+	// contexts are resolved with the provided loader
+	loader, err := NewContextDocumentLoader(nil)
+	assert.NoError(t, err)
+	document["@context"] = "https://example.com/unknown/v1"
+	_, err = canonicalizer.Canonicalize(document, loader)
+	assert.ErrorContains(t, err, "unknown context")
+}
+
+func BenchmarkURDNA2015Canonicalizer(b *testing.B) {
+	canonicalizer := URDNA2015Canonicalizer{}
+	loader, err := NewContextDocumentLoader(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	document := map[string]any{
+		"@context":          []any{"https://www.w3.org/2018/credentials/v1"},
+		"type":              []any{"VerifiableCredential"},
+		"issuer":            "did:example:123",
+		"issuanceDate":      "2021-01-01T19:23:24Z",
+		"credentialSubject": map[string]any{"id": "did:example:456"},
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = canonicalizer.Canonicalize(document, loader); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package conformance
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+)
+
+var (
+	//go:embed vectors
+	bundledVectors embed.FS
+)
+
+// Only JsonWebSignature2020 vectors are bundled: this SDK has no DataIntegrityProof suite, such as eddsa-rdfc-2022, for
+// the W3C Data Integrity vectors to run against. Vectors of such suites, which are identified by their `cryptosuite`,
+// are run like any other once loaded with LoadVectors, and are skipped until a suite for them is registered.
+
+const (
+	// JWS2020VectorsFile holds vectors from https://github.com/decentralized-identity/JWS-Test-Suite
+	JWS2020VectorsFile = "jws2020.json"
+)
+
+// Vector is a single conformance test vector: a document with an embedded proof, the public key of the proof's
+// verification method, and whether the proof is expected to verify
+type Vector struct {
+	Name string `json:"name"`
+	// Source describes where the vector comes from, such as the URL of the test suite file
+	Source       string                      `json:"source,omitempty"`
+	Document     cryptosuite.GenericProvable `json:"document"`
+	PublicKeyJWK jwx.PublicKeyJWK            `json:"publicKeyJwk"`
+	Valid        bool                        `json:"valid"`
+}
+
+// Result is the outcome of running a single vector
+type Result struct {
+	Vector string
+	Passed bool
+	// Skipped is set when no suite is registered for the vector's proof type
+	Skipped bool
+	// Err is the error returned when verifying the vector, if any. An error is expected for invalid vectors.
+	Err error
+}
+
+// Report holds the results of running a set of vectors
+type Report struct {
+	Results []Result
+}
+
+// Passed returns whether every vector which was not skipped passed
+func (r Report) Passed() bool {
+	return len(r.Failures()) == 0
+}
+
+// Failures returns the results of vectors which failed
+func (r Report) Failures() []Result {
+	var failures []Result
+	for _, result := range r.Results {
+		if !result.Passed && !result.Skipped {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// VerifierFactory constructs a verifier for the key of a vector's verification method
+type VerifierFactory func(verificationMethod string, key jwx.PublicKeyJWK) (cryptosuite.Verifier, error)
+
+// NewJSONWebKeyVerifier is the default VerifierFactory, constructing a jws2020.JSONWebKeyVerifier
+func NewJSONWebKeyVerifier(verificationMethod string, key jwx.PublicKeyJWK) (cryptosuite.Verifier, error) {
+	return jws2020.NewJSONWebKeyVerifier(verificationMethod, key)
+}
+
+// Run verifies each vector with the suite registered for its proof type, and reports whether the outcome matched
+// the vector's expectation. A nil VerifierFactory uses NewJSONWebKeyVerifier. The options are passed to each
+// verification, e.g. to provide a document loader.
+func Run(registry *cryptosuite.Registry, vectors []Vector, newVerifier VerifierFactory, opts ...cryptosuite.Option) Report {
+	if newVerifier == nil {
+		newVerifier = NewJSONWebKeyVerifier
+	}
+	report := Report{Results: make([]Result, 0, len(vectors))}
+	for _, vector := range vectors {
+		report.Results = append(report.Results, runVector(registry, vector, newVerifier, opts...))
+	}
+	return report
+}
+
+func runVector(registry *cryptosuite.Registry, vector Vector, newVerifier VerifierFactory, opts ...cryptosuite.Option) Result {
+	result := Result{Vector: vector.Name}
+	if registry == nil {
+		result.Err = errors.New("registry cannot be empty")
+		return result
+	}
+	proof := vector.Document.GetProof()
+	if proof == nil {
+		result.Err = errors.New("vector document does not have a proof")
+		return result
+	}
+	if _, err := registry.GetSuiteForProof(*proof); err != nil {
+		result.Skipped = true
+		result.Err = err
+		return result
+	}
+	verifier, err := newVerifier(proof.VerificationMethod, vector.PublicKeyJWK)
+	if err != nil {
+		result.Err = errors.Wrap(err, "constructing verifier")
+		return result
+	}
+
+	// verify a copy so that running a vector never modifies it
+	document := make(cryptosuite.GenericProvable, len(vector.Document))
+	for k, v := range vector.Document {
+		document[k] = v
+	}
+	result.Err = registry.Verify(verifier, &document, opts...)
+	result.Passed = (result.Err == nil) == vector.Valid
+	if !result.Passed && result.Err == nil {
+		result.Err = fmt.Errorf("vector<%s> is invalid but verified", vector.Name)
+	}
+	return result
+}
+
+// LoadVectors parses a JSON array of vectors
+func LoadVectors(data []byte) ([]Vector, error) {
+	var vectors []Vector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		return nil, errors.Wrap(err, "parsing vectors")
+	}
+	for i, vector := range vectors {
+		if vector.Name == "" {
+			return nil, fmt.Errorf("vector %d does not have a name", i)
+		}
+		if vector.Document == nil {
+			return nil, fmt.Errorf("vector<%s> does not have a document", vector.Name)
+		}
+	}
+	return vectors, nil
+}
+
+// LoadBundledVectors returns the vectors held in one of the files bundled with this package, e.g. JWS2020VectorsFile
+func LoadBundledVectors(fileName string) ([]Vector, error) {
+	data, err := bundledVectors.ReadFile("vectors/" + fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading bundled vectors: %s", fileName)
+	}
+	return LoadVectors(data)
+}
@@ -0,0 +1,98 @@
+package conformance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/rsa2018"
+)
+
+func TestRun(t *testing.T) {
+	vectors, err := LoadBundledVectors(JWS2020VectorsFile)
+	require.NoError(t, err)
+	require.NotEmpty(t, vectors)
+
+	t.Run("jws2020 vectors", func(tt *testing.T) {
+		registry, err := cryptosuite.NewRegistry(jws2020.GetJSONWebSignature2020Suite())
+		assert.NoError(tt, err)
+
+		report := Run(registry, vectors, nil)
+		assert.True(tt, report.Passed(), "failures: %+v", report.Failures())
+		assert.Len(tt, report.Results, len(vectors))
+		for _, result := range report.Results {
+			assert.False(tt, result.Skipped)
+		}
+
+		// running vectors does not modify them
+		assert.NotNil(tt, vectors[0].Document.GetProof())
+	})
+
+	t.Run("unregistered proof types are skipped", func(tt *testing.T) {
+		registry, err := cryptosuite.NewRegistry(rsa2018.GetRSASignature2018Suite())
+		assert.NoError(tt, err)
+
+		report := Run(registry, vectors, nil)
+		assert.True(tt, report.Passed())
+		for _, result := range report.Results {
+			assert.True(tt, result.Skipped)
+		}
+	})
+
+	t.Run("data integrity proofs without a registered cryptosuite are skipped", func(tt *testing.T) {
+		registry, err := cryptosuite.NewRegistry(jws2020.GetJSONWebSignature2020Suite())
+		assert.NoError(tt, err)
+
+		dataIntegrityVectors, err := LoadVectors([]byte(`[{
+			"name": "eddsa-rdfc-2022",
+			"document": {
+				"@context": ["https://www.w3.org/ns/credentials/v2"],
+				"type": ["VerifiableCredential"],
+				"issuer": "did:example:123",
+				"credentialSubject": {},
+				"proof": {
+					"type": "DataIntegrityProof",
+					"cryptosuite": "eddsa-rdfc-2022",
+					"proofPurpose": "assertionMethod",
+					"verificationMethod": "did:example:123#key-0",
+					"proofValue": "z123"
+				}
+			},
+			"valid": true
+		}]`))
+		require.NoError(tt, err)
+		report := Run(registry, dataIntegrityVectors, nil)
+		assert.True(tt, report.Passed())
+		require.Len(tt, report.Results, 1)
+		assert.True(tt, report.Results[0].Skipped)
+		assert.ErrorIs(tt, report.Results[0].Err, cryptosuite.ErrUnsupportedSuite)
+	})
+
+	t.Run("reports failures", func(tt *testing.T) {
+		registry, err := cryptosuite.NewRegistry(jws2020.GetJSONWebSignature2020Suite())
+		assert.NoError(tt, err)
+
+		flipped := make([]Vector, len(vectors))
+		copy(flipped, vectors)
+		for i := range flipped {
+			flipped[i].Valid = !flipped[i].Valid
+		}
+		report := Run(registry, flipped, nil)
+		assert.False(tt, report.Passed())
+		assert.Len(tt, report.Failures(), len(vectors))
+	})
+}
+
+func TestLoadVectors(t *testing.T) {
+	_, err := LoadVectors([]byte(`[{"document": {}}]`))
+	assert.ErrorContains(t, err, "vector 0 does not have a name")
+
+	_, err = LoadVectors([]byte(`[{"name": "empty"}]`))
+	assert.ErrorContains(t, err, "vector<empty> does not have a document")
+
+	_, err = LoadBundledVectors("unknown.json")
+	assert.ErrorContains(t, err, "reading bundled vectors")
+}
@@ -22,9 +22,9 @@ type CryptoSuite interface {
 
 	// Sign https://w3c-ccg.github.io/data-integrity-spec/#proof-algorithm
 	// this method mutates the provided provable object, adding a `proof` block`
-	Sign(s Signer, p WithEmbeddedProof) error
+	Sign(s Signer, p WithEmbeddedProof, opts ...Option) error
 	// Verify https://w3c-ccg.github.io/data-integrity-spec/#proof-verification-algorithm
-	Verify(v Verifier, p WithEmbeddedProof) error
+	Verify(v Verifier, p WithEmbeddedProof, opts ...Option) error
 }
 
 type CryptoSuiteInfo interface {
@@ -86,6 +86,7 @@ type ProofOptions struct {
 // GenericProvable represents a provable that is not constrained by a specific type
 type GenericProvable map[string]any
 
+// GetProof returns the provable's proof, or nil if it has no proof or its proof is not a proof object or a proof set
 func (g *GenericProvable) GetProof() *crypto.Proof {
 	if g == nil {
 		return nil
@@ -95,8 +96,11 @@ func (g *GenericProvable) GetProof() *crypto.Proof {
 	if !gotProof {
 		return nil
 	}
-	p := crypto.Proof(proof)
-	return &p
+	p, err := crypto.ProofFromAny(proof)
+	if err != nil {
+		return nil
+	}
+	return p
 }
 
 func (g *GenericProvable) SetProof(p *crypto.Proof) {
@@ -157,8 +161,3 @@ func EnsureRequiredContexts(context []any, requiredContexts []string) []any {
 	}
 	return context
 }
-
-func getKnownContext(fileName string) (string, error) {
-	b, err := knownContexts.ReadFile("context/" + fileName)
-	return string(b), err
-}
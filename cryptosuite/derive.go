@@ -0,0 +1,90 @@
+package cryptosuite
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// DerivableSuite is a CryptoSuite which supports selective disclosure by deriving a proof from a base proof, such as
+// the BBS+ suites. A holder derives a proof revealing only part of a signed document, which a verifier checks using
+// the suite's Verify method.
+// This is currently experimental: this SDK provides no implementation, so the interface is unstable and subject to
+// change as one is added.
+type DerivableSuite interface {
+	CryptoSuite
+
+	// DeriveProof derives a selectively disclosed provable from a provable carrying a base proof. The reveal document
+	// is a JSON-LD frame matching the parts of the provable to disclose. The given provable is not modified.
+	DeriveProof(p WithEmbeddedProof, revealDocument map[string]any, opts ...Option) (*GenericProvable, error)
+}
+
+// RevealedStatements is the result of applying a reveal document to a provable, which is the input to deriving a
+// selectively disclosed proof
+type RevealedStatements struct {
+	// Document is the provable framed by the reveal document, without a proof
+	Document map[string]any
+	// Quads are the canonical N-Quads of the provable without its proof. Blank nodes are skolemized, as for
+	// DisclosureGroups.
+	Quads []string
+	// RevealIndexes are the indexes of the statements of the revealed document in Quads, in ascending order
+	RevealIndexes []int
+}
+
+// RevealStatements frames a provable with a JSON-LD reveal document and finds the canonical statements of the
+// provable which the framed document reveals. A nil canonicalizer uses the URDNA2015Canonicalizer; a nil loader uses
+// the default loader.
+func RevealStatements(p WithEmbeddedProof, revealDocument map[string]any, canonicalizer Canonicalizer, loader DocumentLoader) (*RevealedStatements, error) {
+	if p == nil {
+		return nil, errors.New("provable cannot be empty")
+	}
+	if len(revealDocument) == 0 {
+		return nil, errors.New("reveal document cannot be empty")
+	}
+	if canonicalizer == nil {
+		canonicalizer = URDNA2015Canonicalizer{}
+	}
+
+	document, err := skolemizedCopy(p)
+	if err != nil {
+		return nil, err
+	}
+	delete(document, "proof")
+	canonical, err := canonicalizer.Canonicalize(document, loader)
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing provable")
+	}
+	quads := splitQuads(canonical)
+
+	framed, err := util.LDFrame(document, revealDocument)
+	if err != nil {
+		return nil, errors.Wrap(err, "framing provable with reveal document")
+	}
+	revealed, ok := framed.(map[string]any)
+	if !ok {
+		return nil, errors.Errorf("unexpected framed document type: %T", framed)
+	}
+	canonicalRevealed, err := canonicalizer.Canonicalize(revealed, loader)
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing revealed document")
+	}
+	indexes := make(map[string]int, len(quads))
+	for i, quad := range quads {
+		indexes[quad] = i
+	}
+	revealIndexes := make(map[int]string)
+	for _, quad := range splitQuads(canonicalRevealed) {
+		i, ok := indexes[quad]
+		if !ok {
+			return nil, errors.Errorf("revealed statement is not in the provable: %s", quad)
+		}
+		revealIndexes[i] = quad
+	}
+
+	deskolemize(revealed)
+	return &RevealedStatements{
+		Document:      revealed,
+		Quads:         quads,
+		RevealIndexes: sortedIndexes(revealIndexes),
+	}, nil
+}
@@ -0,0 +1,51 @@
+package cryptosuite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevealStatements(t *testing.T) {
+	loader, err := NewContextDocumentLoader(nil)
+	assert.NoError(t, err)
+	document := GenericProvable(getSelectiveDisclosureTestDocument())
+	document["proof"] = map[string]any{"type": "TestSignature2020", "proofValue": "z123"}
+	revealDocument := map[string]any{
+		"@context": document["@context"],
+		"type":     []any{"VerifiableCredential"},
+		"credentialSubject": map[string]any{
+			"@explicit":  true,
+			"sailNumber": map[string]any{},
+		},
+	}
+
+	revealed, err := RevealStatements(&document, revealDocument, nil, loader)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, revealed.RevealIndexes)
+	assert.Less(t, len(revealed.RevealIndexes), len(revealed.Quads))
+
+	var revealedQuads []string
+	for _, i := range revealed.RevealIndexes {
+		revealedQuads = append(revealedQuads, revealed.Quads[i])
+	}
+	joined := strings.Join(revealedQuads, "\n")
+	assert.Contains(t, joined, `"Earth101"`)
+	assert.NotContains(t, joined, `"Kite"`)
+
+	// the revealed document does not carry the proof or skolem identifiers
+	subject, ok := revealed.Document["credentialSubject"].(map[string]any)
+	assert.True(t, ok)
+	assert.Equal(t, "Earth101", subject["sailNumber"])
+	assert.NotContains(t, subject, "boards")
+	assert.NotContains(t, subject, "id")
+	assert.NotContains(t, revealed.Document, "proof")
+
+	// the provable is not modified
+	assert.NotContains(t, document["credentialSubject"], "id")
+	assert.NotNil(t, document.GetProof())
+
+	_, err = RevealStatements(&document, nil, nil, loader)
+	assert.ErrorContains(t, err, "reveal document cannot be empty")
+}
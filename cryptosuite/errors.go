@@ -0,0 +1,75 @@
+package cryptosuite
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors classifying why creating or verifying a proof failed. Errors returned by suites wrap one of these
+// so callers can branch on the cause with errors.Is, e.g. errors.Is(err, ErrInvalidSignature).
+var (
+	// ErrUnsupportedSuite is returned when no suite is available for a proof's type or cryptosuite
+	ErrUnsupportedSuite = errors.New("unsupported suite")
+	// ErrKeyMismatch is returned when a key cannot be used with a proof, e.g. a JWS algorithm the key does not support
+	ErrKeyMismatch = errors.New("key mismatch")
+	// ErrCanonicalization is returned when a document or proof could not be transformed and hashed
+	ErrCanonicalization = errors.New("canonicalization failed")
+	// ErrInvalidSignature is returned when a proof's signature does not verify
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrInvalidProof is returned when a proof is malformed, or its properties, such as timestamps, are not acceptable
+	ErrInvalidProof = errors.New("invalid proof")
+)
+
+// ProofError is returned by suites when signing or verifying fails. It records the kind of failure, one of the
+// sentinel errors above, along with the proof type and verification method involved, if known.
+type ProofError struct {
+	Kind               error
+	ProofType          SignatureType
+	VerificationMethod string
+	Msg                string
+	Err                error
+}
+
+// NewProofError creates a ProofError of the given kind, wrapping an optional cause
+func NewProofError(kind error, proofType SignatureType, verificationMethod, msg string, err error) *ProofError {
+	return &ProofError{
+		Kind:               kind,
+		ProofType:          proofType,
+		VerificationMethod: verificationMethod,
+		Msg:                msg,
+		Err:                err,
+	}
+}
+
+// NewSignatureError creates a ProofError for a signature which failed to verify. The error is a key mismatch if the
+// verifier reported it could not use its key for the signature, and an invalid signature otherwise.
+func NewSignatureError(proofType SignatureType, verificationMethod, msg string, err error) *ProofError {
+	kind := ErrInvalidSignature
+	if errors.Is(err, ErrKeyMismatch) {
+		kind = ErrKeyMismatch
+	}
+	return NewProofError(kind, proofType, verificationMethod, msg, err)
+}
+
+func (e *ProofError) Error() string {
+	msg := e.Msg
+	if msg == "" && e.Kind != nil {
+		msg = e.Kind.Error()
+	}
+	if e.Err == nil {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", msg, e.Err.Error())
+}
+
+// Unwrap returns both the kind and the cause of the error, so errors.Is matches either
+func (e *ProofError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
@@ -1,13 +1,19 @@
 package jws2020
 
 import (
+	"bytes"
 	gocrypto "crypto"
+	"encoding/base64"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/pkg/errors"
@@ -168,26 +174,126 @@ func GenerateP384JSONWebKey2020() (*JSONWebKey2020, error) {
 // a message and provide a valid JSON Web Signature (JWS) value as a result.
 type JSONWebKeySigner struct {
 	jwx.Signer
-	purpose cryptosuite.ProofPurpose
-	format  cryptosuite.PayloadFormat
+	purpose    cryptosuite.ProofPurpose
+	format     cryptosuite.PayloadFormat
+	jwsOptions JWSOptions
+}
+
+// b64Header is the JWS header signalling whether the payload is base64url encoded https://www.rfc-editor.org/rfc/rfc7797
+const b64Header = "b64"
+
+// JWSOptions controls the construction and acceptance of detached JWS values. Implementations of JsonWebSignature2020
+// differ on header details, so these options allow signatures to be made and checked in the form a counterparty
+// expects. The zero value matches the behavior of earlier versions of this library, which sign the payload without
+// base64url encoding it, signalled by the `b64` and `crit` protected headers as per
+// https://www.rfc-editor.org/rfc/rfc7797
+type JWSOptions struct {
+	// HeaderOrder sets the order of members in the protected header, e.g. ["alg", "b64", "crit"]. Members which are
+	// not listed follow in lexical order. It does not change how the payload is encoded.
+	HeaderOrder []string
+	// AllowedAlgorithms restricts the `alg` values accepted when verifying. When empty any algorithm supported by the
+	// verifier's key is accepted.
+	AllowedAlgorithms []string
+	// RequireUnencodedPayload rejects signatures which do not use an unencoded payload when verifying
+	RequireUnencodedPayload bool
+}
+
+// SetJWSOptions configures how the signer constructs detached JWS values
+func (s *JSONWebKeySigner) SetJWSOptions(opts JWSOptions) {
+	s.jwsOptions = opts
 }
 
 // Sign returns a byte array signature value for a message `tbs`
 func (s *JSONWebKeySigner) Sign(tbs []byte) ([]byte, error) {
-	b64 := "b64"
+	if len(s.jwsOptions.HeaderOrder) > 0 {
+		return s.signWithHeaderOrder(tbs)
+	}
 	headers := jws.NewHeaders()
-	if err := headers.Set(b64, false); err != nil {
+	if err := headers.Set(b64Header, false); err != nil {
 		return nil, err
 	}
-	if err := headers.Set(jws.CriticalKey, []string{b64}); err != nil {
+	if err := headers.Set(jws.CriticalKey, []string{b64Header}); err != nil {
 		return nil, err
 	}
-	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
-	alg := s.ALG
-	if alg == "Ed25519" {
-		alg = jwa.EdDSA.String()
+	return jws.Sign(nil, jws.WithKey(signatureAlgorithm(s.ALG), s.PrivateKey), jws.WithHeaders(headers), jws.WithDetachedPayload(tbs))
+}
+
+// signWithHeaderOrder constructs the same detached JWS as Sign by hand, since the jwx library does not control the
+// order of protected header members
+func (s *JSONWebKeySigner) signWithHeaderOrder(tbs []byte) ([]byte, error) {
+	alg := signatureAlgorithm(s.ALG)
+	header := map[string]any{
+		jws.AlgorithmKey: alg.String(),
+		b64Header:        false,
+		jws.CriticalKey:  []string{b64Header},
 	}
-	return jws.Sign(nil, jws.WithKey(jwa.SignatureAlgorithm(alg), s.PrivateKey), jws.WithHeaders(headers), jws.WithDetachedPayload(tbs))
+	encodedHeader, err := marshalOrderedHeader(header, s.jwsOptions.HeaderOrder)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling protected header")
+	}
+
+	// the payload is unencoded, as signalled by the b64 header
+	signingInput := append([]byte(encodedHeader+"."), tbs...)
+	signer, err := jws.NewSigner(alg)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating signer")
+	}
+	signature, err := signer.Sign(signingInput, s.PrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing")
+	}
+	return []byte(encodedHeader + ".." + base64.RawURLEncoding.EncodeToString(signature)), nil
+}
+
+// marshalOrderedHeader returns the base64url encoding of a JWS header, with members in the given order followed by
+// any remaining members in lexical order
+func marshalOrderedHeader(header map[string]any, order []string) (string, error) {
+	keys := make([]string, 0, len(header))
+	seen := make(map[string]bool, len(header))
+	for _, key := range order {
+		if _, ok := header[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	var remaining []string
+	for key := range header {
+		if !seen[key] {
+			remaining = append(remaining, key)
+		}
+	}
+	sort.Strings(remaining)
+	keys = append(keys, remaining...)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		keyBytes, err := json.Marshal(key)
+		if err != nil {
+			return "", err
+		}
+		valueBytes, err := json.Marshal(header[key])
+		if err != nil {
+			return "", err
+		}
+		buf.Write(keyBytes)
+		buf.WriteByte(':')
+		buf.Write(valueBytes)
+	}
+	buf.WriteByte('}')
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// SignRaw returns a bare signature value for a message `tbs`, without a JWS envelope
+func (s *JSONWebKeySigner) SignRaw(tbs []byte) ([]byte, error) {
+	signer, err := jws.NewSigner(signatureAlgorithm(s.ALG))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating signer")
+	}
+	return signer.Sign(tbs, s.PrivateKey)
 }
 
 func (s *JSONWebKeySigner) GetKeyID() string {
@@ -234,24 +340,92 @@ func NewJSONWebKeySigner(id string, key jwx.PrivateKeyJWK, purpose cryptosuite.P
 // a message and signature, and provide a result to whether the signature is valid.
 type JSONWebKeyVerifier struct {
 	jwx.Verifier
+	jwsOptions JWSOptions
+}
+
+// SetJWSOptions configures which detached JWS values the verifier accepts
+func (v *JSONWebKeyVerifier) SetJWSOptions(opts JWSOptions) {
+	v.jwsOptions = opts
 }
 
 // Verify attempts to verify a `signature` against a given `message`, returning nil if the verification is successful
-// and an error should it fail.
+// and an error should it fail. A JWS signed with an algorithm other than the key's is reported as a
+// cryptosuite.ErrKeyMismatch.
 func (v JSONWebKeyVerifier) Verify(message, signature []byte) error {
+	if err := v.checkJWSHeader(signature); err != nil {
+		return err
+	}
 	pubKey, err := v.PublicKeyJWK.ToPublicKey()
 	if err != nil {
-		return errors.Wrap(err, "getting public key")
-	}
-	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
-	alg := v.ALG
-	if alg == "Ed25519" {
-		alg = jwa.EdDSA.String()
+		return cryptosuite.NewProofError(cryptosuite.ErrKeyMismatch, JSONWebSignature2020, v.KID, "getting public key", err)
 	}
-	_, err = jws.Verify(signature, jws.WithKey(jwa.SignatureAlgorithm(alg), pubKey), jws.WithDetachedPayload(message))
+	_, err = jws.Verify(signature, jws.WithKey(signatureAlgorithm(v.ALG), pubKey), jws.WithDetachedPayload(message))
 	return err
 }
 
+// VerifyRaw attempts to verify a bare `signature`, without a JWS envelope, against a given `message`
+func (v JSONWebKeyVerifier) VerifyRaw(message, signature []byte) error {
+	pubKey, err := v.PublicKeyJWK.ToPublicKey()
+	if err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrKeyMismatch, JSONWebSignature2020, v.KID, "getting public key", err)
+	}
+	verifier, err := jws.NewVerifier(signatureAlgorithm(v.ALG))
+	if err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrKeyMismatch, JSONWebSignature2020, v.KID, "creating verifier", err)
+	}
+	return verifier.Verify(message, signature, pubKey)
+}
+
+// checkJWSHeader makes sure a JWS's protected header is acceptable according to the verifier's options, that it
+// was signed with the algorithm of the verifier's key, and that an unencoded payload is marked critical, as
+// https://www.rfc-editor.org/rfc/rfc7797#section-6 requires
+func (v JSONWebKeyVerifier) checkJWSHeader(signature []byte) error {
+	header, err := decodeJWSHeader(signature)
+	if err != nil {
+		if len(v.jwsOptions.AllowedAlgorithms) == 0 && !v.jwsOptions.RequireUnencodedPayload {
+			// leave reporting malformed signatures to the JWS library
+			return nil
+		}
+		return err
+	}
+	if len(v.jwsOptions.AllowedAlgorithms) > 0 && !slices.Contains(v.jwsOptions.AllowedAlgorithms, header.ALG) {
+		return fmt.Errorf("jws algorithm is not allowed: %s", header.ALG)
+	}
+	if v.jwsOptions.RequireUnencodedPayload && (header.B64 == nil || *header.B64) {
+		return errors.New("jws must have an unencoded payload")
+	}
+	if header.B64 != nil && !*header.B64 && !slices.Contains(header.Crit, b64Header) {
+		return errors.New("jws with an unencoded payload must have b64 in its crit header")
+	}
+	if v.ALG != "" && header.ALG != signatureAlgorithm(v.ALG).String() {
+		msg := fmt.Sprintf("jws algorithm<%s> does not match key algorithm<%s>", header.ALG, v.ALG)
+		return cryptosuite.NewProofError(cryptosuite.ErrKeyMismatch, JSONWebSignature2020, v.KID, msg, nil)
+	}
+	return nil
+}
+
+type jwsHeader struct {
+	ALG  string   `json:"alg"`
+	B64  *bool    `json:"b64"`
+	Crit []string `json:"crit"`
+}
+
+func decodeJWSHeader(signature []byte) (*jwsHeader, error) {
+	encodedHeader, _, found := strings.Cut(string(signature), ".")
+	if !found {
+		return nil, errors.New("malformed jws")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(encodedHeader)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding jws header")
+	}
+	var header jwsHeader
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling jws header")
+	}
+	return &header, nil
+}
+
 func (v JSONWebKeyVerifier) GetKeyID() string {
 	return v.KID
 }
@@ -264,6 +438,15 @@ func NewJSONWebKeyVerifier(id string, key jwx.PublicKeyJWK) (*JSONWebKeyVerifier
 	return &JSONWebKeyVerifier{Verifier: *verifier}, nil
 }
 
+// signatureAlgorithm maps a JWK algorithm to the algorithm used by the jwx library.
+// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+func signatureAlgorithm(alg string) jwa.SignatureAlgorithm {
+	if alg == "Ed25519" {
+		return jwa.EdDSA
+	}
+	return jwa.SignatureAlgorithm(alg)
+}
+
 // PubKeyBytesToTypedKey converts a public key byte slice to a crypto.PublicKey based on a given key type, merging
 // both LD key types and JWK key types
 func PubKeyBytesToTypedKey(keyBytes []byte, kt cryptosuite.LDKeyType) (gocrypto.PublicKey, error) {
@@ -1,10 +1,14 @@
 package jws2020
 
 import (
+	"encoding/base64"
+	"strings"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJSONWebKey2020SignerVerifier(t *testing.T) {
@@ -64,3 +68,117 @@ func TestJSONWebKey2020SignerVerifier(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONWebKeySignerJWSOptions(t *testing.T) {
+	testMessage := []byte("my name is satoshi")
+	decodeHeader := func(tt *testing.T, signature []byte) string {
+		encodedHeader, rest, found := strings.Cut(string(signature), ".")
+		assert.True(tt, found)
+		assert.True(tt, strings.HasPrefix(rest, "."), "jws must be detached")
+		header, err := base64.RawURLEncoding.DecodeString(encodedHeader)
+		assert.NoError(tt, err)
+		return string(header)
+	}
+
+	for _, test := range []struct {
+		name string
+		kty  KTY
+		crv  CRV
+	}{
+		{name: "RSA-2048", kty: RSA},
+		{name: "Ed25519", kty: OKP, crv: Ed25519},
+		{name: "P-256", kty: EC, crv: P256},
+	} {
+		t.Run(test.name, func(tt *testing.T) {
+			jwk, err := GenerateJSONWebKey2020(test.kty, test.crv)
+			assert.NoError(tt, err)
+			signer, err := NewJSONWebKeySigner("signer-id", jwk.PrivateKeyJWK, cryptosuite.AssertionMethod)
+			assert.NoError(tt, err)
+			verifier, err := NewJSONWebKeyVerifier("signer-id", jwk.PublicKeyJWK)
+			assert.NoError(tt, err)
+
+			// the default serialization signals its unencoded payload in the protected header
+			signature, err := signer.Sign(testMessage)
+			assert.NoError(tt, err)
+			assert.Contains(tt, decodeHeader(tt, signature), `"b64":false,"crit":["b64"]`)
+			assert.NoError(tt, verifier.Verify(testMessage, signature))
+			assert.Error(tt, verifier.Verify([]byte("my name is not satoshi"), signature))
+		})
+	}
+
+	jwk, err := GenerateJSONWebKey2020(OKP, Ed25519)
+	require.NoError(t, err)
+	signer, err := NewJSONWebKeySigner("signer-id", jwk.PrivateKeyJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+
+	t.Run("header order", func(tt *testing.T) {
+		signer.SetJWSOptions(JWSOptions{HeaderOrder: []string{"b64", "crit", "alg"}})
+		signature, err := signer.Sign(testMessage)
+		assert.NoError(tt, err)
+		assert.Equal(tt, `{"b64":false,"crit":["b64"],"alg":"EdDSA"}`, decodeHeader(tt, signature))
+
+		verifier, err := NewJSONWebKeyVerifier("signer-id", jwk.PublicKeyJWK)
+		assert.NoError(tt, err)
+		assert.NoError(tt, verifier.Verify(testMessage, signature))
+
+		// members not listed follow in lexical order
+		signer.SetJWSOptions(JWSOptions{HeaderOrder: []string{"crit"}})
+		signature, err = signer.Sign(testMessage)
+		assert.NoError(tt, err)
+		assert.Equal(tt, `{"crit":["b64"],"alg":"EdDSA","b64":false}`, decodeHeader(tt, signature))
+
+		// ordering the header does not change how the payload is encoded
+		signer.SetJWSOptions(JWSOptions{HeaderOrder: []string{"crit", "b64"}})
+		signature, err = signer.Sign(testMessage)
+		assert.NoError(tt, err)
+		assert.Equal(tt, `{"crit":["b64"],"b64":false,"alg":"EdDSA"}`, decodeHeader(tt, signature))
+		verifier.SetJWSOptions(JWSOptions{RequireUnencodedPayload: true})
+		assert.NoError(tt, verifier.Verify(testMessage, signature))
+		assert.Error(tt, verifier.Verify([]byte("my name is not satoshi"), signature))
+	})
+
+	t.Run("verifier restrictions", func(tt *testing.T) {
+		// other implementations may encode the payload
+		encoded, err := jws.Sign(nil, jws.WithKey(signatureAlgorithm(signer.ALG), signer.PrivateKey), jws.WithDetachedPayload(testMessage))
+		assert.NoError(tt, err)
+		signer.SetJWSOptions(JWSOptions{})
+		unencoded, err := signer.Sign(testMessage)
+		assert.NoError(tt, err)
+
+		verifier, err := NewJSONWebKeyVerifier("signer-id", jwk.PublicKeyJWK)
+		assert.NoError(tt, err)
+		verifier.SetJWSOptions(JWSOptions{AllowedAlgorithms: []string{"ES256"}})
+		assert.ErrorContains(tt, verifier.Verify(testMessage, unencoded), "jws algorithm is not allowed: EdDSA")
+
+		verifier.SetJWSOptions(JWSOptions{AllowedAlgorithms: []string{"EdDSA"}, RequireUnencodedPayload: true})
+		assert.NoError(tt, verifier.Verify(testMessage, unencoded))
+		assert.ErrorContains(tt, verifier.Verify(testMessage, encoded), "jws must have an unencoded payload")
+
+		// unencoded payloads must be marked critical
+		header, err := marshalOrderedHeader(map[string]any{"alg": "EdDSA", b64Header: false}, nil)
+		assert.NoError(tt, err)
+		rawSignature, err := signer.SignRaw(append([]byte(header+"."), testMessage...))
+		assert.NoError(tt, err)
+		uncritical := []byte(header + ".." + base64.RawURLEncoding.EncodeToString(rawSignature))
+		verifier.SetJWSOptions(JWSOptions{})
+		assert.ErrorContains(tt, verifier.Verify(testMessage, uncritical), "jws with an unencoded payload must have b64 in its crit header")
+	})
+
+	t.Run("suite round trip", func(tt *testing.T) {
+		signer.SetJWSOptions(JWSOptions{})
+		verifier, err := NewJSONWebKeyVerifier("signer-id", jwk.PublicKeyJWK)
+		assert.NoError(tt, err)
+		verifier.SetJWSOptions(JWSOptions{RequireUnencodedPayload: true})
+
+		cred := TestCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		}
+		suite := GetJSONWebSignature2020Suite()
+		assert.NoError(tt, suite.Sign(signer, &cred))
+		assert.NoError(tt, suite.Verify(verifier, &cred))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
@@ -29,12 +30,38 @@ const (
 	JWSSignatureSuiteProofAlgorithm = JSONWebSignature2020
 )
 
-type JWSSignatureSuite struct{}
+type JWSSignatureSuite struct {
+	// documentLoader resolves JSON-LD contexts during canonicalization; when nil the library's default loader is used
+	documentLoader cryptosuite.DocumentLoader
+	// canonicalizationCache is an optional cache of canonicalization results
+	canonicalizationCache *cryptosuite.CanonicalizationCache
+	// canonicalizer transforms documents prior to hashing; when nil URDNA2015 is used
+	canonicalizer cryptosuite.Canonicalizer
+}
 
 func GetJSONWebSignature2020Suite() cryptosuite.CryptoSuite {
 	return new(JWSSignatureSuite)
 }
 
+// NewJSONWebSignature2020Suite returns a JsonWebSignature2020 suite configured with the provided options.
+// Supported options are cryptosuite.WithDocumentLoader, allowing for custom vocabularies and canonicalization
+// without network access, cryptosuite.WithCanonicalizationCache, and cryptosuite.WithCanonicalizer.
+func NewJSONWebSignature2020Suite(opts ...cryptosuite.Option) (cryptosuite.CryptoSuite, error) {
+	loader, err := cryptosuite.GetDocumentLoaderOption(opts)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := cryptosuite.GetCanonicalizationCacheOption(opts)
+	if err != nil {
+		return nil, err
+	}
+	canonicalizer, err := cryptosuite.GetCanonicalizerOption(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &JWSSignatureSuite{documentLoader: loader, canonicalizationCache: cache, canonicalizer: canonicalizer}, nil
+}
+
 // CryptoSuiteInfo interface
 
 var _ cryptosuite.CryptoSuiteInfo = (*JWSSignatureSuite)(nil)
@@ -47,7 +74,10 @@ func (JWSSignatureSuite) Type() cryptosuite.LDKeyType {
 	return JWSSignatureSuiteType
 }
 
-func (JWSSignatureSuite) CanonicalizationAlgorithm() string {
+func (j JWSSignatureSuite) CanonicalizationAlgorithm() string {
+	if j.canonicalizer != nil {
+		return j.canonicalizer.Algorithm()
+	}
 	return JWSSignatureSuiteCanonicalizationAlgorithm
 }
 
@@ -63,52 +93,106 @@ func (JWSSignatureSuite) RequiredContexts() []string {
 	return []string{JSONWebSignature2020Context}
 }
 
-func (j JWSSignatureSuite) Sign(s cryptosuite.Signer, p cryptosuite.WithEmbeddedProof) error {
+func (j JWSSignatureSuite) Sign(s cryptosuite.Signer, p cryptosuite.WithEmbeddedProof, opts ...cryptosuite.Option) error {
 	// create proof before running the create verify hash algorithm
 	proof := j.createProof(s.GetKeyID(), s.GetProofPurpose())
 
+	// set an expiry on the proof, if requested
+	expires, err := cryptosuite.GetExpiresOption(opts)
+	if err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, proof.VerificationMethod, "getting expires option", err)
+	}
+	if expires != nil {
+		if expires.Before(time.Now()) {
+			return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, proof.VerificationMethod, fmt.Sprintf("proof expiry must be in the future: %s", expires.String()), nil)
+		}
+		proof.Expires = AsRFC3339Timestamp(*expires)
+	}
+
+	// bind the proof to the challenge and domain of a verifier, if given
+	challenge, err := cryptosuite.GetChallengeOption(opts)
+	if err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, proof.VerificationMethod, "getting challenge option", err)
+	}
+	if challenge != "" {
+		proof.Challenge = challenge
+	}
+	if proof.Domain, err = cryptosuite.GetDomainOption(opts); err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, proof.VerificationMethod, "getting domain option", err)
+	}
+
+	// set ZCAP-LD properties for capability proof purposes
+	if err = setCapabilityProperties(&proof, opts); err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, proof.VerificationMethod, "setting capability properties", err)
+	}
+
 	// prepare proof options
 	contexts, err := cryptosuite.GetContextsFromProvable(p)
 	if err != nil {
-		return errors.Wrap(err, "getting contexts from provable")
+		return cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, JSONWebSignature2020, proof.VerificationMethod, "getting contexts from provable", err)
 	}
 
 	// make sure the suite's context(s) are included
 	contexts = cryptosuite.EnsureRequiredContexts(contexts, j.RequiredContexts())
-	opts := &cryptosuite.ProofOptions{Contexts: contexts}
+	proofOpts := &cryptosuite.ProofOptions{Contexts: capabilityProofContexts(proof.ProofPurpose, contexts)}
 
 	// 3. tbs value as a result of create verify hash
 	var genericProvable map[string]any
 	pBytes, err := json.Marshal(p)
 	if err != nil {
-		return errors.Wrap(err, "marshaling provable")
+		return cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, JSONWebSignature2020, proof.VerificationMethod, "marshaling provable", err)
 	}
 	if err = json.Unmarshal(pBytes, &genericProvable); err != nil {
-		return errors.Wrap(err, "unmarshalling provable")
+		return cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, JSONWebSignature2020, proof.VerificationMethod, "unmarshalling provable", err)
 	}
-	tbs, err := j.CreateVerifyHash(genericProvable, proof, opts)
+	tbs, err := j.CreateVerifyHash(genericProvable, proof.ToGenericProof(), proofOpts)
 	if err != nil {
-		return errors.Wrap(err, "create verify hash algorithm failed")
-	}
-
-	// 4 & 5. create the signature over the provable data as a JWS
-	signature, err := s.Sign(tbs)
-	if err != nil {
-		return errors.Wrap(err, "signing provable value")
+		return cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, JSONWebSignature2020, proof.VerificationMethod, "create verify hash algorithm failed", err)
+	}
+
+	// 4 & 5. create the signature over the provable data, either as a JWS or a multibase proof value
+	if cryptosuite.IsProofValueRequested(opts) {
+		rawSigner, ok := s.(cryptosuite.RawSigner)
+		if !ok {
+			return cryptosuite.NewProofError(cryptosuite.ErrKeyMismatch, JSONWebSignature2020, proof.VerificationMethod, "signer does not support creating a proof value", nil)
+		}
+		signature, err := rawSigner.SignRaw(tbs)
+		if err != nil {
+			return cryptosuite.NewProofError(cryptosuite.ErrKeyMismatch, JSONWebSignature2020, proof.VerificationMethod, "signing provable value", err)
+		}
+		proofValue, err := cryptosuite.EncodeProofValue(signature)
+		if err != nil {
+			return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, proof.VerificationMethod, "encoding proof value", err)
+		}
+		proof.ProofValue = proofValue
+	} else {
+		signature, err := s.Sign(tbs)
+		if err != nil {
+			return cryptosuite.NewProofError(cryptosuite.ErrKeyMismatch, JSONWebSignature2020, proof.VerificationMethod, "signing provable value", err)
+		}
+		proof.SetDetachedJWS(string(signature))
 	}
 
 	// set the signature on the proof object and return
-	proof.SetDetachedJWS(string(signature))
-	genericProof := crypto.Proof(proof)
+	genericProof := proof.ToGenericProof()
 	p.SetProof(&genericProof)
 	return nil
 }
 
-func (j JWSSignatureSuite) Verify(v cryptosuite.Verifier, p cryptosuite.WithEmbeddedProof) error {
+func (j JWSSignatureSuite) Verify(v cryptosuite.Verifier, p cryptosuite.WithEmbeddedProof, opts ...cryptosuite.Option) error {
 	proof := p.GetProof()
+	if proof == nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, "", "provable does not have a proof", nil)
+	}
 	gotProof, err := JSONWebSignatureProofFromGenericProof(*proof)
 	if err != nil {
-		return errors.Wrap(err, "preparing proof for verification; error coercing proof into JsonWebSignature2020 proof")
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, proof.VerificationMethod, "preparing proof for verification; error coercing proof into JsonWebSignature2020 proof", err)
+	}
+	verificationMethod := gotProof.VerificationMethod
+
+	// reject stale, expired, or future-dated proofs before doing any cryptographic work
+	if err = cryptosuite.VerifyProofTimestamps(gotProof.Created, gotProof.Expires, opts...); err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, verificationMethod, "verifying proof timestamps", err)
 	}
 
 	// remove proof before verifying
@@ -117,36 +201,58 @@ func (j JWSSignatureSuite) Verify(v cryptosuite.Verifier, p cryptosuite.WithEmbe
 	// make sure we set it back after we're done verifying
 	defer p.SetProof(proof)
 
-	// remove the JWS value in the proof before verification
+	// remove the JWS or proof value in the proof before verification
 	jwsCopy := []byte(gotProof.JWS)
+	proofValue := gotProof.ProofValue
+	if len(jwsCopy) > 0 && proofValue != "" {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, verificationMethod, "proof cannot contain both a jws and a proofValue", nil)
+	}
+	if len(jwsCopy) == 0 && proofValue == "" {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, verificationMethod, "proof must contain either a jws or a proofValue", nil)
+	}
 	gotProof.SetDetachedJWS("")
+	gotProof.ProofValue = ""
 
 	// prepare proof options
 	contexts, err := cryptosuite.GetContextsFromProvable(p)
 	if err != nil {
-		return errors.Wrap(err, "getting contexts from provable")
+		return cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, JSONWebSignature2020, verificationMethod, "getting contexts from provable", err)
 	}
 
 	// make sure the suite's context(s) are included
 	contexts = cryptosuite.EnsureRequiredContexts(contexts, j.RequiredContexts())
-	opts := &cryptosuite.ProofOptions{Contexts: contexts}
+	proofOpts := &cryptosuite.ProofOptions{Contexts: capabilityProofContexts(gotProof.ProofPurpose, contexts)}
 
 	// run the create verify hash algorithm on both provable and the proof
 	var genericProvable map[string]any
 	pBytes, err := json.Marshal(p)
 	if err != nil {
-		return errors.Wrap(err, "marshalling provable")
+		return cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, JSONWebSignature2020, verificationMethod, "marshalling provable", err)
 	}
 	if err = json.Unmarshal(pBytes, &genericProvable); err != nil {
-		return errors.Wrap(err, "unmarshalling provable")
+		return cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, JSONWebSignature2020, verificationMethod, "unmarshalling provable", err)
 	}
-	tbv, err := j.CreateVerifyHash(genericProvable, gotProof, opts)
+	tbv, err := j.CreateVerifyHash(genericProvable, gotProof.ToGenericProof(), proofOpts)
 	if err != nil {
-		return errors.Wrap(err, "create verify hash algorithm failed")
+		return cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, JSONWebSignature2020, verificationMethod, "create verify hash algorithm failed", err)
+	}
+
+	if proofValue != "" {
+		rawVerifier, ok := v.(cryptosuite.RawVerifier)
+		if !ok {
+			return cryptosuite.NewProofError(cryptosuite.ErrKeyMismatch, JSONWebSignature2020, verificationMethod, "verifier does not support verifying a proof value", nil)
+		}
+		signature, err := cryptosuite.DecodeProofValue(proofValue)
+		if err != nil {
+			return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, JSONWebSignature2020, verificationMethod, "decoding proof value", err)
+		}
+		if err = rawVerifier.VerifyRaw(tbv, signature); err != nil {
+			return cryptosuite.NewSignatureError(JSONWebSignature2020, verificationMethod, "verifying proof value", err)
+		}
+		return nil
 	}
-
 	if err = v.Verify(tbv, jwsCopy); err != nil {
-		return errors.Wrap(err, "verifying JWS")
+		return cryptosuite.NewSignatureError(JSONWebSignature2020, verificationMethod, "verifying JWS", err)
 	}
 	return nil
 }
@@ -164,17 +270,38 @@ func (JWSSignatureSuite) Marshal(data any) ([]byte, error) {
 	return jsonBytes, nil
 }
 
-func (JWSSignatureSuite) Canonicalize(marshaled []byte) (*string, error) {
+func (j JWSSignatureSuite) Canonicalize(marshaled []byte) (*string, error) {
+	canonicalizer := j.canonicalizer
+	if canonicalizer == nil {
+		canonicalizer = cryptosuite.URDNA2015Canonicalizer{}
+	}
+
+	var cacheKey string
+	if j.canonicalizationCache != nil {
+		key, err := cryptosuite.CanonicalizationCacheKey(marshaled)
+		if err != nil {
+			return nil, errors.Wrap(err, "computing canonicalization cache key")
+		}
+		// results of different algorithms must not be confused with one another
+		key = canonicalizer.Algorithm() + ":" + key
+		if cached, ok := j.canonicalizationCache.Get(key); ok {
+			return &cached, nil
+		}
+		cacheKey = key
+	}
+
 	// the LD library anticipates a generic golang json object to normalize
 	var generic map[string]any
 	if err := json.Unmarshal(marshaled, &generic); err != nil {
-		return nil, err
+		return nil, cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, JSONWebSignature2020, "", "unmarshalling provable document", err)
 	}
-	normalized, err := LDNormalize(generic)
+	canonicalString, err := canonicalizer.Canonicalize(generic, j.documentLoader)
 	if err != nil {
-		return nil, errors.Wrap(err, "canonicalizing provable document")
+		return nil, cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, JSONWebSignature2020, "", "canonicalizing provable document", err)
+	}
+	if cacheKey != "" {
+		j.canonicalizationCache.Put(cacheKey, canonicalString)
 	}
-	canonicalString := normalized.(string)
 	return &canonicalString, nil
 }
 
@@ -236,19 +363,12 @@ func (j JWSSignatureSuite) Digest(tbd []byte) ([]byte, error) {
 	return hash[:], nil
 }
 
-func (j JWSSignatureSuite) prepareProof(proof crypto.Proof, opts *cryptosuite.ProofOptions) (*crypto.Proof, error) {
-	proofBytes, err := json.Marshal(proof)
-	if err != nil {
-		return nil, err
-	}
-
-	var genericProof map[string]any
-	if err = json.Unmarshal(proofBytes, &genericProof); err != nil {
-		return nil, err
-	}
+func (j JWSSignatureSuite) prepareProof(proof crypto.Proof, opts *cryptosuite.ProofOptions) (map[string]any, error) {
+	genericProof := proof.ToMap()
 
-	// proof cannot have a jws value
+	// proof cannot have a jws or proof value
 	delete(genericProof, "jws")
+	delete(genericProof, "proofValue")
 
 	// make sure the proof has a timestamp
 	created, ok := genericProof["created"]
@@ -264,17 +384,23 @@ func (j JWSSignatureSuite) prepareProof(proof crypto.Proof, opts *cryptosuite.Pr
 		contexts = ArrayStrToInterface(j.RequiredContexts())
 	}
 	genericProof["@context"] = contexts
-	p := crypto.Proof(genericProof)
-	return &p, nil
+	return genericProof, nil
 }
 
 type JSONWebSignature2020Proof struct {
 	Type               cryptosuite.SignatureType `json:"type,omitempty"`
 	Created            string                    `json:"created,omitempty"`
+	Expires            string                    `json:"expires,omitempty"`
 	JWS                string                    `json:"jws,omitempty"`
+	ProofValue         string                    `json:"proofValue,omitempty"`
 	ProofPurpose       cryptosuite.ProofPurpose  `json:"proofPurpose,omitempty"`
 	Challenge          string                    `json:"challenge,omitempty"`
+	Domain             string                    `json:"domain,omitempty"`
 	VerificationMethod string                    `json:"verificationMethod,omitempty"`
+	Capability         string                    `json:"capability,omitempty"`
+	CapabilityAction   string                    `json:"capabilityAction,omitempty"`
+	InvocationTarget   string                    `json:"invocationTarget,omitempty"`
+	CapabilityChain    []any                     `json:"capabilityChain,omitempty"`
 }
 
 func JSONWebSignatureProofFromGenericProof(p crypto.Proof) (*JSONWebSignature2020Proof, error) {
@@ -289,8 +415,35 @@ func JSONWebSignatureProofFromGenericProof(p crypto.Proof) (*JSONWebSignature202
 	return &result, nil
 }
 
+// ToGenericProof returns the proof as a crypto.Proof, with ZCAP-LD properties held as extensions
 func (j *JSONWebSignature2020Proof) ToGenericProof() crypto.Proof {
-	return j
+	if j == nil {
+		return crypto.Proof{}
+	}
+	proof := crypto.Proof{
+		Type:               string(j.Type),
+		Created:            j.Created,
+		Expires:            j.Expires,
+		JWS:                j.JWS,
+		ProofValue:         j.ProofValue,
+		ProofPurpose:       string(j.ProofPurpose),
+		Challenge:          j.Challenge,
+		Domain:             j.Domain,
+		VerificationMethod: j.VerificationMethod,
+	}
+	if j.Capability != "" {
+		proof.SetProperty("capability", j.Capability)
+	}
+	if j.CapabilityAction != "" {
+		proof.SetProperty("capabilityAction", j.CapabilityAction)
+	}
+	if j.InvocationTarget != "" {
+		proof.SetProperty("invocationTarget", j.InvocationTarget)
+	}
+	if len(j.CapabilityChain) > 0 {
+		proof.SetProperty("capabilityChain", j.CapabilityChain)
+	}
+	return proof
 }
 
 func (j *JSONWebSignature2020Proof) SetDetachedJWS(jws string) {
@@ -330,3 +483,45 @@ func (j JWSSignatureSuite) createProof(verificationMethod string, purpose crypto
 		VerificationMethod: verificationMethod,
 	}
 }
+
+// capabilityProofContexts makes sure the ZCAP-LD proof properties are defined for the capability proof purposes,
+// so they are not dropped when the proof is canonicalized
+func capabilityProofContexts(purpose cryptosuite.ProofPurpose, contexts []any) []any {
+	if purpose != cryptosuite.CapabilityInvocation && purpose != cryptosuite.CapabilityDelegation {
+		return contexts
+	}
+	for _, context := range contexts {
+		if context == cryptosuite.W3CSecurityContext {
+			return contexts
+		}
+	}
+	return append([]any{cryptosuite.W3CSecurityContext}, contexts...)
+}
+
+// setCapabilityProperties sets the ZCAP-LD properties required by the capabilityInvocation and capabilityDelegation
+// proof purposes https://w3c-ccg.github.io/zcap-spec/
+func setCapabilityProperties(proof *JSONWebSignature2020Proof, opts []cryptosuite.Option) error {
+	switch proof.ProofPurpose {
+	case cryptosuite.CapabilityInvocation:
+		invocation, err := cryptosuite.GetCapabilityInvocationOption(opts)
+		if err != nil {
+			return errors.Wrap(err, "getting capability invocation option")
+		}
+		if invocation == nil || invocation.Capability == "" {
+			return errors.New("capability invocation proofs require a capability")
+		}
+		proof.Capability = invocation.Capability
+		proof.CapabilityAction = invocation.CapabilityAction
+		proof.InvocationTarget = invocation.InvocationTarget
+	case cryptosuite.CapabilityDelegation:
+		chain, err := cryptosuite.GetCapabilityChainOption(opts)
+		if err != nil {
+			return errors.Wrap(err, "getting capability chain option")
+		}
+		if len(chain) == 0 {
+			return errors.New("capability delegation proofs require a capability chain")
+		}
+		proof.CapabilityChain = ArrayStrToInterface(chain)
+	}
+	return nil
+}
@@ -1,13 +1,18 @@
 package jws2020
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/util"
 
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -192,14 +197,763 @@ func TestCredentialLDProof(t *testing.T) {
 	assert.NotEmpty(t, knownCred.Proof)
 
 	// cast to known proof type
-	p, ok := (*knownCred.Proof).(JSONWebSignature2020Proof)
-	assert.True(t, ok)
+	p, err := JSONWebSignatureProofFromGenericProof(*knownCred.Proof)
+	assert.NoError(t, err)
 	assert.Equal(t, JSONWebSignature2020, p.Type)
 	assert.NotEmpty(t, p.JWS)
 	assert.NotEmpty(t, p.Created)
 	assert.Equal(t, issuer, p.VerificationMethod)
 }
 
+func TestJSONWebSignature2020ProofTimestamps(t *testing.T) {
+	signer, jwk := getTestVectorKey0Signer(t, cryptosuite.AssertionMethod)
+	verifier, err := NewJSONWebKeyVerifier("verifier-id", jwk.PublicKeyJWK)
+	assert.NoError(t, err)
+	suite := GetJSONWebSignature2020Suite()
+
+	newCred := func() TestCredential {
+		return TestCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{},
+		}
+	}
+
+	t.Run("proof with expiry in the future verifies", func(tt *testing.T) {
+		cred := newCred()
+		err = suite.Sign(&signer, &cred, cryptosuite.WithExpires(time.Now().Add(time.Hour)))
+		assert.NoError(tt, err)
+
+		p, err := JSONWebSignatureProofFromGenericProof(*cred.Proof)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, p.Expires)
+
+		err = suite.Verify(verifier, &cred)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("cannot sign with an expiry in the past", func(tt *testing.T) {
+		cred := newCred()
+		err = suite.Sign(&signer, &cred, cryptosuite.WithExpires(time.Now().Add(-time.Hour)))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "proof expiry must be in the future")
+	})
+
+	t.Run("expired proof fails verification", func(tt *testing.T) {
+		cred := newCred()
+		err = suite.Sign(&signer, &cred, cryptosuite.WithExpires(time.Now().Add(time.Second)))
+		assert.NoError(tt, err)
+
+		err = suite.Verify(verifier, &cred, cryptosuite.WithClockSkew(0), cryptosuite.WithMaxProofAge(time.Hour))
+		assert.NoError(tt, err)
+
+		p, err := JSONWebSignatureProofFromGenericProof(*cred.Proof)
+		assert.NoError(tt, err)
+		// timestamps are checked before the signature, so tampering with the expiry surfaces as expiry
+		p.Expires = "2022-01-24T23:26:38Z"
+		expiredProof := p.ToGenericProof()
+		cred.SetProof(&expiredProof)
+		err = suite.Verify(verifier, &cred)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "proof has expired")
+	})
+
+	t.Run("stale proof fails verification with max proof age", func(tt *testing.T) {
+		// https://github.com/decentralized-identity/JWS-Test-Suite/blob/main/data/implementations/transmute/credential-0--key-0-ed25519.vc.json
+		knownProof := JSONWebSignature2020Proof{
+			Type:               "JsonWebSignature2020",
+			Created:            "2022-01-24T23:26:38Z",
+			JWS:                "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..377mL0aIk_YL_scEZh1BIzje17vD4F7U8WPo2ufgkkGLwDNXHDhN99zpnsvsozD5Si82gRbDHqFu3Rp6dLH7Ag",
+			ProofPurpose:       "assertionMethod",
+			VerificationMethod: "did:example:123#key-0",
+		}
+		proof := knownProof.ToGenericProof()
+		cred := newCred()
+		cred.SetProof(&proof)
+
+		err = suite.Verify(verifier, &cred)
+		assert.NoError(tt, err)
+
+		err = suite.Verify(verifier, &cred, cryptosuite.WithMaxProofAge(24*time.Hour))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "exceeds maximum age")
+	})
+
+	t.Run("future dated proof fails verification", func(tt *testing.T) {
+		futureProof := JSONWebSignature2020Proof{
+			Type:               "JsonWebSignature2020",
+			Created:            util.AsRFC3339Timestamp(time.Now().Add(time.Hour)),
+			JWS:                "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..377mL0aIk_YL_scEZh1BIzje17vD4F7U8WPo2ufgkkGLwDNXHDhN99zpnsvsozD5Si82gRbDHqFu3Rp6dLH7Ag",
+			ProofPurpose:       "assertionMethod",
+			VerificationMethod: "did:example:123#key-0",
+		}
+		proof := futureProof.ToGenericProof()
+		cred := newCred()
+		cred.SetProof(&proof)
+
+		err = suite.Verify(verifier, &cred)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "proof created in the future")
+
+		// within the allowed skew the timestamp check passes, leaving only the signature to fail
+		err = suite.Verify(verifier, &cred, cryptosuite.WithClockSkew(2*time.Hour))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verifying JWS")
+	})
+}
+
+func TestJSONWebSignature2020CustomDocumentLoader(t *testing.T) {
+	signer, jwk := getTestVectorKey0Signer(t, cryptosuite.AssertionMethod)
+	verifier, err := NewJSONWebKeyVerifier("verifier-id", jwk.PublicKeyJWK)
+	assert.NoError(t, err)
+
+	customContext := "https://example.com/custom/v1"
+	newCred := func() TestCredential {
+		return TestCredential{
+			Context:      []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1", customContext},
+			Type:         []string{"VerifiableCredential"},
+			Issuer:       "did:example:123",
+			IssuanceDate: "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{
+				"id":          "did:example:456",
+				"nickname":    "satoshi",
+				"memberSince": "2009",
+			},
+		}
+	}
+
+	// an offline loader with no fallback cannot resolve the custom context
+	offlineLoader, err := cryptosuite.NewContextDocumentLoader(nil)
+	assert.NoError(t, err)
+	suite, err := NewJSONWebSignature2020Suite(cryptosuite.WithDocumentLoader(offlineLoader))
+	assert.NoError(t, err)
+	cred := newCred()
+	err = suite.Sign(&signer, &cred)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown context")
+
+	// once the context is supplied, sign and verify succeed
+	err = offlineLoader.AddContext(customContext, []byte(`{"@context": {"@vocab": "https://example.com/custom#"}}`))
+	assert.NoError(t, err)
+	cred = newCred()
+	err = suite.Sign(&signer, &cred)
+	assert.NoError(t, err)
+	err = suite.Verify(verifier, &cred)
+	assert.NoError(t, err)
+
+	// the vocabulary is part of what is signed; changing it invalidates the proof
+	changedLoader, err := cryptosuite.NewContextDocumentLoader(nil)
+	assert.NoError(t, err)
+	err = changedLoader.AddContext(customContext, []byte(`{"@context": {"@vocab": "https://example.com/other#"}}`))
+	assert.NoError(t, err)
+	changedSuite, err := NewJSONWebSignature2020Suite(cryptosuite.WithDocumentLoader(changedLoader))
+	assert.NoError(t, err)
+	err = changedSuite.Verify(verifier, &cred)
+	assert.Error(t, err)
+}
+
+func TestJSONWebSignature2020CanonicalizationCache(t *testing.T) {
+	_, err := NewJSONWebSignature2020Suite(cryptosuite.Option{ID: cryptosuite.CanonicalizationCacheOption, Option: "bad"})
+	assert.Error(t, err)
+
+	signer, jwk := getTestVectorKey0Signer(t, cryptosuite.AssertionMethod)
+	verifier, err := NewJSONWebKeyVerifier("verifier-id", jwk.PublicKeyJWK)
+	assert.NoError(t, err)
+
+	cache, err := cryptosuite.NewCanonicalizationCache(10)
+	assert.NoError(t, err)
+	suite, err := NewJSONWebSignature2020Suite(cryptosuite.WithCanonicalizationCache(cache))
+	assert.NoError(t, err)
+
+	cred := TestCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            "did:example:123",
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	}
+	err = suite.Sign(&signer, &cred)
+	assert.NoError(t, err)
+
+	// both the document and the proof options are cached
+	assert.Equal(t, 2, cache.Len())
+
+	// verification reuses the cached canonical forms
+	err = suite.Verify(verifier, &cred)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cache.Len())
+
+	// the result matches an uncached suite
+	err = GetJSONWebSignature2020Suite().Verify(verifier, &cred)
+	assert.NoError(t, err)
+
+	// changing the document is not masked by the cache
+	cred.CredentialSubject = map[string]any{"id": "did:example:789"}
+	err = suite.Verify(verifier, &cred)
+	assert.Error(t, err)
+}
+
+// countingCanonicalizer wraps the default canonicalizer, recording how often it is used
+type countingCanonicalizer struct {
+	cryptosuite.URDNA2015Canonicalizer
+	algorithm string
+	calls     int
+	err       error
+}
+
+func (c *countingCanonicalizer) Algorithm() string {
+	return c.algorithm
+}
+
+func (c *countingCanonicalizer) Canonicalize(document map[string]any, loader cryptosuite.DocumentLoader) (string, error) {
+	c.calls++
+	if c.err != nil {
+		return "", c.err
+	}
+	return c.URDNA2015Canonicalizer.Canonicalize(document, loader)
+}
+
+func TestJSONWebSignature2020CustomCanonicalizer(t *testing.T) {
+	_, err := NewJSONWebSignature2020Suite(cryptosuite.Option{ID: cryptosuite.CanonicalizerOption, Option: "bad"})
+	assert.Error(t, err)
+
+	signer, jwk := getTestVectorKey0Signer(t, cryptosuite.AssertionMethod)
+	verifier, err := NewJSONWebKeyVerifier("verifier-id", jwk.PublicKeyJWK)
+	assert.NoError(t, err)
+	newCred := func() TestCredential {
+		return TestCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		}
+	}
+
+	t.Run("uses the provided canonicalizer", func(tt *testing.T) {
+		canonicalizer := &countingCanonicalizer{algorithm: "urn:example:urdna2015-wrapper"}
+		suite, err := NewJSONWebSignature2020Suite(cryptosuite.WithCanonicalizer(canonicalizer))
+		assert.NoError(tt, err)
+		assert.Equal(tt, "urn:example:urdna2015-wrapper", suite.CanonicalizationAlgorithm())
+		assert.Equal(tt, JWSSignatureSuiteCanonicalizationAlgorithm, GetJSONWebSignature2020Suite().CanonicalizationAlgorithm())
+
+		cred := newCred()
+		assert.NoError(tt, suite.Sign(&signer, &cred))
+		assert.Equal(tt, 2, canonicalizer.calls)
+
+		// the output is interchangeable with the default implementation
+		assert.NoError(tt, GetJSONWebSignature2020Suite().Verify(verifier, &cred))
+		assert.NoError(tt, suite.Verify(verifier, &cred))
+		assert.Equal(tt, 4, canonicalizer.calls)
+	})
+
+	t.Run("canonicalization errors", func(tt *testing.T) {
+		canonicalizer := &countingCanonicalizer{algorithm: "urn:example:broken", err: errors.New("canonicalizer failed")}
+		suite, err := NewJSONWebSignature2020Suite(cryptosuite.WithCanonicalizer(canonicalizer))
+		assert.NoError(tt, err)
+
+		cred := newCred()
+		err = suite.Sign(&signer, &cred)
+		assert.ErrorContains(tt, err, "canonicalizer failed")
+		assert.ErrorIs(tt, err, cryptosuite.ErrCanonicalization)
+	})
+
+	t.Run("cache entries are kept per algorithm", func(tt *testing.T) {
+		cache, err := cryptosuite.NewCanonicalizationCache(10)
+		assert.NoError(tt, err)
+		first := &countingCanonicalizer{algorithm: "urn:example:first"}
+		second := &countingCanonicalizer{algorithm: "urn:example:second"}
+		firstSuite, err := NewJSONWebSignature2020Suite(cryptosuite.WithCanonicalizationCache(cache), cryptosuite.WithCanonicalizer(first))
+		assert.NoError(tt, err)
+		secondSuite, err := NewJSONWebSignature2020Suite(cryptosuite.WithCanonicalizationCache(cache), cryptosuite.WithCanonicalizer(second))
+		assert.NoError(tt, err)
+
+		cred := newCred()
+		assert.NoError(tt, firstSuite.Sign(&signer, &cred))
+		assert.NoError(tt, secondSuite.Verify(verifier, &cred))
+		assert.Equal(tt, 2, first.calls)
+		assert.Equal(tt, 2, second.calls)
+		assert.Equal(tt, 4, cache.Len())
+	})
+}
+
+func TestJSONWebSignature2020Errors(t *testing.T) {
+	signer, jwk := getTestVectorKey0Signer(t, cryptosuite.AssertionMethod)
+	verifier, err := NewJSONWebKeyVerifier("verifier-id", jwk.PublicKeyJWK)
+	assert.NoError(t, err)
+	suite := GetJSONWebSignature2020Suite()
+	signedCred := func(tt *testing.T) TestCredential {
+		cred := TestCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		}
+		assert.NoError(tt, suite.Sign(&signer, &cred))
+		return cred
+	}
+
+	t.Run("invalid signature", func(tt *testing.T) {
+		cred := signedCred(tt)
+		cred.Issuer = "did:example:789"
+		err := suite.Verify(verifier, &cred)
+		assert.ErrorIs(tt, err, cryptosuite.ErrInvalidSignature)
+		assert.NotErrorIs(tt, err, cryptosuite.ErrKeyMismatch)
+
+		var proofErr *cryptosuite.ProofError
+		assert.True(tt, errors.As(err, &proofErr))
+		assert.Equal(tt, JSONWebSignature2020, proofErr.ProofType)
+		assert.Equal(tt, cred.Proof.VerificationMethod, proofErr.VerificationMethod)
+	})
+
+	t.Run("key mismatch", func(tt *testing.T) {
+		otherJWK, err := GenerateJSONWebKey2020(EC, P256)
+		assert.NoError(tt, err)
+		otherVerifier, err := NewJSONWebKeyVerifier("verifier-id", otherJWK.PublicKeyJWK)
+		assert.NoError(tt, err)
+
+		cred := signedCred(tt)
+		err = suite.Verify(otherVerifier, &cred)
+		assert.ErrorIs(tt, err, cryptosuite.ErrKeyMismatch)
+		assert.NotErrorIs(tt, err, cryptosuite.ErrInvalidSignature)
+		assert.ErrorContains(tt, err, "does not match key algorithm<ES256>")
+	})
+
+	t.Run("invalid proof", func(tt *testing.T) {
+		cred := signedCred(tt)
+		cred.Proof.JWS = ""
+		err := suite.Verify(verifier, &cred)
+		assert.ErrorIs(tt, err, cryptosuite.ErrInvalidProof)
+		assert.ErrorContains(tt, err, "proof must contain either a jws or a proofValue")
+
+		cred.Proof = nil
+		err = suite.Verify(verifier, &cred)
+		assert.ErrorIs(tt, err, cryptosuite.ErrInvalidProof)
+	})
+
+	t.Run("unsupported suite", func(tt *testing.T) {
+		registry, err := cryptosuite.NewRegistry(suite)
+		assert.NoError(tt, err)
+
+		cred := signedCred(tt)
+		cred.Proof.Type = "Ed25519Signature2020"
+		err = registry.Verify(verifier, &cred)
+		assert.ErrorIs(tt, err, cryptosuite.ErrUnsupportedSuite)
+		assert.ErrorContains(tt, err, "unsupported proof type: Ed25519Signature2020")
+	})
+
+	t.Run("sign errors are proof errors", func(tt *testing.T) {
+		tests := []struct {
+			name   string
+			signer cryptosuite.Signer
+			opts   []cryptosuite.Option
+			kind   error
+			msg    string
+		}{
+			{
+				name:   "expiry in the past",
+				signer: &signer,
+				opts:   []cryptosuite.Option{cryptosuite.WithExpires(time.Now().Add(-time.Hour))},
+				kind:   cryptosuite.ErrInvalidProof,
+				msg:    "proof expiry must be in the future",
+			},
+			{
+				name:   "signer without raw signing support",
+				signer: jwsOnlySigner{Signer: &signer},
+				opts:   []cryptosuite.Option{cryptosuite.WithProofValue()},
+				kind:   cryptosuite.ErrKeyMismatch,
+				msg:    "signer does not support creating a proof value",
+			},
+			{
+				name:   "signer failure",
+				signer: failingSigner{Signer: &signer},
+				kind:   cryptosuite.ErrKeyMismatch,
+				msg:    "signing provable value",
+			},
+			{
+				name:   "raw signer failure",
+				signer: failingSigner{Signer: &signer},
+				opts:   []cryptosuite.Option{cryptosuite.WithProofValue()},
+				kind:   cryptosuite.ErrKeyMismatch,
+				msg:    "signing provable value",
+			},
+		}
+		for _, test := range tests {
+			cred := TestCredential{
+				Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+				Type:              []string{"VerifiableCredential"},
+				Issuer:            "did:example:123",
+				IssuanceDate:      "2021-01-01T19:23:24Z",
+				CredentialSubject: map[string]any{"id": "did:example:456"},
+			}
+			err := suite.Sign(test.signer, &cred, test.opts...)
+			assertProofErrorKind(tt, err, test.kind, test.name)
+			assert.ErrorContains(tt, err, test.msg, test.name)
+		}
+	})
+
+	t.Run("verifier without raw verification support", func(tt *testing.T) {
+		cred := TestCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		}
+		assert.NoError(tt, suite.Sign(&signer, &cred, cryptosuite.WithProofValue()))
+		err := suite.Verify(jwsOnlyVerifier{Verifier: verifier}, &cred)
+		assertProofErrorKind(tt, err, cryptosuite.ErrKeyMismatch, "verifier without raw verification support")
+		assert.ErrorContains(tt, err, "verifier does not support verifying a proof value")
+	})
+
+	t.Run("verify errors are proof errors", func(tt *testing.T) {
+		cred := signedCred(tt)
+		cred.Issuer = "did:example:789"
+		assertProofErrorKind(tt, suite.Verify(verifier, &cred), cryptosuite.ErrInvalidSignature, "invalid signature")
+
+		cred = signedCred(tt)
+		cred.Proof.JWS = ""
+		assertProofErrorKind(tt, suite.Verify(verifier, &cred), cryptosuite.ErrInvalidProof, "invalid proof")
+
+		otherJWK, err := GenerateJSONWebKey2020(EC, P256)
+		assert.NoError(tt, err)
+		otherVerifier, err := NewJSONWebKeyVerifier("verifier-id", otherJWK.PublicKeyJWK)
+		assert.NoError(tt, err)
+		cred = signedCred(tt)
+		assertProofErrorKind(tt, suite.Verify(otherVerifier, &cred), cryptosuite.ErrKeyMismatch, "key mismatch")
+	})
+}
+
+// assertProofErrorKind asserts that an error is a ProofError of the given kind
+func assertProofErrorKind(t *testing.T, err error, kind error, msgAndArgs ...any) {
+	var proofErr *cryptosuite.ProofError
+	if assert.True(t, errors.As(err, &proofErr), msgAndArgs...) {
+		assert.Equal(t, kind, proofErr.Kind, msgAndArgs...)
+		assert.Equal(t, JSONWebSignature2020, proofErr.ProofType, msgAndArgs...)
+	}
+}
+
+// failingSigner is a JSONWebKeySigner whose signing fails
+type failingSigner struct {
+	cryptosuite.Signer
+}
+
+func (failingSigner) Sign([]byte) ([]byte, error) {
+	return nil, errors.New("signing failed")
+}
+
+func (failingSigner) SignRaw([]byte) ([]byte, error) {
+	return nil, errors.New("signing failed")
+}
+
+// jwsOnlyVerifier hides the raw verification capability of a JSONWebKeyVerifier
+type jwsOnlyVerifier struct {
+	cryptosuite.Verifier
+}
+
+func TestJSONWebSignature2020Registry(t *testing.T) {
+	signer, jwk := getTestVectorKey0Signer(t, cryptosuite.AssertionMethod)
+	verifier, err := NewJSONWebKeyVerifier("verifier-id", jwk.PublicKeyJWK)
+	assert.NoError(t, err)
+
+	registry, err := cryptosuite.NewRegistry(GetJSONWebSignature2020Suite())
+	assert.NoError(t, err)
+
+	cred := TestCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            "did:example:123",
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{},
+	}
+	err = GetJSONWebSignature2020Suite().Sign(&signer, &cred)
+	assert.NoError(t, err)
+
+	err = registry.Verify(verifier, &cred)
+	assert.NoError(t, err)
+}
+
+// jwsOnlySigner hides the raw signing capability of a JSONWebKeySigner
+type jwsOnlySigner struct {
+	cryptosuite.Signer
+}
+
+func TestJSONWebSignature2020ProofValue(t *testing.T) {
+	suite := GetJSONWebSignature2020Suite()
+	newCred := func() TestCredential {
+		return TestCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		}
+	}
+
+	for _, crv := range []CRV{Ed25519, P256, P384} {
+		t.Run(string(crv), func(tt *testing.T) {
+			kty := EC
+			if crv == Ed25519 {
+				kty = OKP
+			}
+			jwk, err := GenerateJSONWebKey2020(kty, crv)
+			assert.NoError(tt, err)
+			signer, err := NewJSONWebKeySigner("did:example:123#key-1", jwk.PrivateKeyJWK, cryptosuite.AssertionMethod)
+			assert.NoError(tt, err)
+			verifier, err := NewJSONWebKeyVerifier("did:example:123#key-1", jwk.PublicKeyJWK)
+			assert.NoError(tt, err)
+
+			cred := newCred()
+			err = suite.Sign(signer, &cred, cryptosuite.WithProofValue())
+			assert.NoError(tt, err)
+
+			p, err := JSONWebSignatureProofFromGenericProof(*cred.Proof)
+			assert.NoError(tt, err)
+			assert.Empty(tt, p.JWS)
+			assert.True(tt, strings.HasPrefix(p.ProofValue, "z"))
+
+			err = suite.Verify(verifier, &cred)
+			assert.NoError(tt, err)
+
+			// the proof survives a round trip through a generic map
+			var genericProof map[string]any
+			proofBytes, err := json.Marshal(p)
+			assert.NoError(tt, err)
+			err = json.Unmarshal(proofBytes, &genericProof)
+			assert.NoError(tt, err)
+			mapProof, err := crypto.ProofFromAny(genericProof)
+			assert.NoError(tt, err)
+			cred.SetProof(mapProof)
+			err = suite.Verify(verifier, &cred)
+			assert.NoError(tt, err)
+
+			// tampering with the document fails
+			cred.Issuer = "did:example:abc"
+			err = suite.Verify(verifier, &cred)
+			assert.ErrorContains(tt, err, "verifying proof value")
+		})
+	}
+
+	t.Run("proof with both jws and proof value", func(tt *testing.T) {
+		signer, jwk := getTestVectorKey0Signer(tt, cryptosuite.AssertionMethod)
+		verifier, err := NewJSONWebKeyVerifier("verifier-id", jwk.PublicKeyJWK)
+		assert.NoError(tt, err)
+
+		cred := newCred()
+		err = suite.Sign(&signer, &cred)
+		assert.NoError(tt, err)
+		p, err := JSONWebSignatureProofFromGenericProof(*cred.Proof)
+		assert.NoError(tt, err)
+		p.ProofValue = "z123"
+		proof := p.ToGenericProof()
+		cred.SetProof(&proof)
+
+		err = suite.Verify(verifier, &cred)
+		assert.ErrorContains(tt, err, "proof cannot contain both a jws and a proofValue")
+
+		p.ProofValue = ""
+		p.JWS = ""
+		proof = p.ToGenericProof()
+		cred.SetProof(&proof)
+		err = suite.Verify(verifier, &cred)
+		assert.ErrorContains(tt, err, "proof must contain either a jws or a proofValue")
+	})
+
+	t.Run("signer without raw signing support", func(tt *testing.T) {
+		signer, _ := getTestVectorKey0Signer(tt, cryptosuite.AssertionMethod)
+		cred := newCred()
+		err := suite.Sign(jwsOnlySigner{Signer: &signer}, &cred, cryptosuite.WithProofValue())
+		assert.ErrorContains(tt, err, "signer does not support creating a proof value")
+	})
+}
+
+func TestJSONWebSignature2020CustomDocument(t *testing.T) {
+	type trustDocument struct {
+		Context []any         `json:"@context"`
+		ID      string        `json:"id"`
+		Issuer  string        `json:"issuer"`
+		Amount  json.Number   `json:"amount"`
+		Proof   *crypto.Proof `json:"proof,omitempty"`
+	}
+
+	jwk, err := GenerateJSONWebKey2020(OKP, Ed25519)
+	assert.NoError(t, err)
+	signer, err := NewJSONWebKeySigner("did:example:123#key-1", jwk.PrivateKeyJWK, cryptosuite.AssertionMethod)
+	assert.NoError(t, err)
+	verifier, err := NewJSONWebKeyVerifier("did:example:123#key-1", jwk.PublicKeyJWK)
+	assert.NoError(t, err)
+	suite := GetJSONWebSignature2020Suite()
+
+	doc := trustDocument{
+		Context: []any{map[string]any{"@vocab": "https://example.com/trust#"}},
+		ID:      "https://example.com/trust/1",
+		Issuer:  "did:example:123",
+		Amount:  "12345678901234567890",
+	}
+	provable, err := cryptosuite.NewGenericProvable(doc)
+	assert.NoError(t, err)
+	err = suite.Sign(signer, provable)
+	assert.NoError(t, err)
+
+	var signed trustDocument
+	err = provable.ToDocument(&signed)
+	assert.NoError(t, err)
+	assert.NotNil(t, signed.Proof)
+	assert.NotEmpty(t, signed.Proof.JWS)
+	assert.Equal(t, doc.Amount, signed.Amount)
+
+	t.Run("verifies a signed document", func(tt *testing.T) {
+		toVerify, err := cryptosuite.NewGenericProvable(signed)
+		assert.NoError(tt, err)
+		err = suite.Verify(verifier, toVerify)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("tampered document", func(tt *testing.T) {
+		tampered := signed
+		tampered.Issuer = "did:example:456"
+		toVerify, err := cryptosuite.NewGenericProvable(tampered)
+		assert.NoError(tt, err)
+		err = suite.Verify(verifier, toVerify)
+		assert.Error(tt, err)
+	})
+
+	t.Run("not an object", func(tt *testing.T) {
+		_, err := cryptosuite.NewGenericProvable([]string{"a"})
+		assert.ErrorContains(tt, err, "document must be a JSON object")
+
+		_, err = cryptosuite.NewGenericProvable(nil)
+		assert.ErrorContains(tt, err, "document cannot be empty")
+	})
+}
+
+func TestJSONWebSignature2020CapabilityProofs(t *testing.T) {
+	suite := GetJSONWebSignature2020Suite()
+	verifiers := make(map[string]cryptosuite.Verifier)
+	resolveVerifier := func(verificationMethod string) (cryptosuite.Verifier, error) {
+		verifier, ok := verifiers[verificationMethod]
+		if !ok {
+			return nil, fmt.Errorf("unknown verification method: %s", verificationMethod)
+		}
+		return verifier, nil
+	}
+	newSigner := func(tt *testing.T, controller string) *JSONWebKeySigner {
+		jwk, err := GenerateJSONWebKey2020(OKP, Ed25519)
+		assert.NoError(tt, err)
+		kid := controller + "#key-1"
+		jwk.PrivateKeyJWK.KID = kid
+		signer, err := NewJSONWebKeySigner(kid, jwk.PrivateKeyJWK, cryptosuite.CapabilityDelegation)
+		assert.NoError(tt, err)
+		verifier, err := NewJSONWebKeyVerifier(kid, jwk.PublicKeyJWK)
+		assert.NoError(tt, err)
+		verifiers[kid] = verifier
+		return signer
+	}
+
+	alice := newSigner(t, "did:example:alice")
+	bob := newSigner(t, "did:example:bob")
+	carol := newSigner(t, "did:example:carol")
+
+	// alice controls the storage service and delegates read access to bob
+	root, err := cryptosuite.NewRootCapability("did:example:alice", "https://storage.example.com/alice")
+	assert.NoError(t, err)
+	root.AllowedAction = []string{"read", "write"}
+	delegated, err := cryptosuite.DelegateCapability(*root, "did:example:bob", "read")
+	assert.NoError(t, err)
+	alice.SetProofPurpose(cryptosuite.CapabilityDelegation)
+	err = suite.Sign(alice, delegated, cryptosuite.WithCapabilityChain(*root))
+	assert.NoError(t, err)
+	chain := []cryptosuite.Capability{*root, *delegated}
+	target := cryptosuite.CapabilityTarget{ID: root.InvocationTarget, Controller: "did:example:alice"}
+
+	invoke := func(tt *testing.T, signer *JSONWebKeySigner, capability cryptosuite.Capability, action string) *TestCredential {
+		request := TestCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:bob",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "https://storage.example.com/alice/documents"},
+		}
+		signer.SetProofPurpose(cryptosuite.CapabilityInvocation)
+		err := suite.Sign(signer, &request, cryptosuite.WithCapabilityInvocation(capability, action))
+		assert.NoError(tt, err)
+		return &request
+	}
+
+	t.Run("valid delegation and invocation", func(tt *testing.T) {
+		err := cryptosuite.VerifyCapabilityChain(suite, resolveVerifier, chain, target)
+		assert.NoError(tt, err)
+
+		request := invoke(tt, bob, *delegated, "read")
+		p, err := JSONWebSignatureProofFromGenericProof(*request.Proof)
+		assert.NoError(tt, err)
+		assert.Equal(tt, delegated.ID, p.Capability)
+		assert.Equal(tt, "read", p.CapabilityAction)
+		assert.Equal(tt, root.InvocationTarget, p.InvocationTarget)
+
+		err = cryptosuite.VerifyCapabilityInvocation(suite, resolveVerifier, request, chain, target)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("invocation properties are signed", func(tt *testing.T) {
+		request := invoke(tt, bob, *delegated, "read")
+		p, err := JSONWebSignatureProofFromGenericProof(*request.Proof)
+		assert.NoError(tt, err)
+		p.InvocationTarget = root.InvocationTarget + "/private"
+		proof := p.ToGenericProof()
+		request.SetProof(&proof)
+
+		err = cryptosuite.VerifyCapabilityInvocation(suite, resolveVerifier, request, chain, target)
+		assert.ErrorContains(tt, err, "verifying invocation proof")
+	})
+
+	t.Run("action not permitted", func(tt *testing.T) {
+		request := invoke(tt, bob, *delegated, "write")
+		err := cryptosuite.VerifyCapabilityInvocation(suite, resolveVerifier, request, chain, target)
+		assert.ErrorContains(tt, err, "action<write> is not permitted")
+	})
+
+	t.Run("invoked by someone other than the controller", func(tt *testing.T) {
+		request := invoke(tt, carol, *delegated, "read")
+		err := cryptosuite.VerifyCapabilityInvocation(suite, resolveVerifier, request, chain, target)
+		assert.ErrorContains(tt, err, "invocation was not made by the controller")
+	})
+
+	t.Run("delegation not signed by the parent's controller", func(tt *testing.T) {
+		forged, err := cryptosuite.DelegateCapability(*root, "did:example:carol", "write")
+		assert.NoError(tt, err)
+		carol.SetProofPurpose(cryptosuite.CapabilityDelegation)
+		err = suite.Sign(carol, forged, cryptosuite.WithCapabilityChain(*root))
+		assert.NoError(tt, err)
+
+		err = cryptosuite.VerifyCapabilityChain(suite, resolveVerifier, []cryptosuite.Capability{*root, *forged}, target)
+		assert.ErrorContains(tt, err, "was not delegated by the controller of its parent")
+	})
+
+	t.Run("tampered delegation", func(tt *testing.T) {
+		tampered := *delegated
+		tampered.AllowedAction = []string{"write"}
+		err := cryptosuite.VerifyCapabilityChain(suite, resolveVerifier, []cryptosuite.Capability{*root, tampered}, target)
+		assert.ErrorContains(tt, err, "verifying delegation proof")
+	})
+
+	t.Run("missing capability options", func(tt *testing.T) {
+		request := TestCredential{Context: []any{"https://www.w3.org/2018/credentials/v1"}, Type: []string{"VerifiableCredential"}}
+		bob.SetProofPurpose(cryptosuite.CapabilityInvocation)
+		err := suite.Sign(bob, &request)
+		assert.ErrorContains(tt, err, "capability invocation proofs require a capability")
+
+		capability, err := cryptosuite.DelegateCapability(*delegated, "did:example:carol")
+		assert.NoError(tt, err)
+		bob.SetProofPurpose(cryptosuite.CapabilityDelegation)
+		err = suite.Sign(bob, capability)
+		assert.ErrorContains(tt, err, "capability delegation proofs require a capability chain")
+	})
+}
+
 // https://github.com/decentralized-identity/JWS-Test-Suite
 func TestJSONWebSignature2020TestVectorCredential0(t *testing.T) {
 	// https://github.com/decentralized-identity/JWS-Test-Suite/blob/main/data/keys/key-0-ed25519.json
@@ -317,13 +1071,13 @@ func TestJSONWebSignature2020TestVectorPresentation0(t *testing.T) {
 
 	// verify against known working impl
 	// https://identity.foundation/JWS-Test-Suite/implementations/transmute/presentation-0--key-0-ed25519.vp.json
-	var knownProof crypto.Proof = map[string]any{
-		"type":               "JsonWebSignature2020",
-		"proofPurpose":       "authentication",
-		"challenge":          "123",
-		"verificationMethod": "did:example:123#key-0",
-		"created":            "2022-03-08T23:35:52.906Z",
-		"jws":                "eyJhbGciOiJFZERTQSIsImNyaXQiOlsiYjY0Il0sImI2NCI6ZmFsc2V9..0PvxIWgyEZ3Lmx44tgMYj6obpvZotnTRkfdOxunBVIu5UTtejPg-l3zlRrsgrgA-wPH3osTm11ubwBLlpuW1DQ",
+	knownProof := crypto.Proof{
+		Type:               "JsonWebSignature2020",
+		ProofPurpose:       "authentication",
+		Challenge:          "123",
+		VerificationMethod: "did:example:123#key-0",
+		Created:            "2022-03-08T23:35:52.906Z",
+		JWS:                "eyJhbGciOiJFZERTQSIsImNyaXQiOlsiYjY0Il0sImI2NCI6ZmFsc2V9..0PvxIWgyEZ3Lmx44tgMYj6obpvZotnTRkfdOxunBVIu5UTtejPg-l3zlRrsgrgA-wPH3osTm11ubwBLlpuW1DQ",
 	}
 	signedPres := knownPres
 	signedPres.SetProof(&knownProof)
@@ -338,12 +1092,12 @@ func TestJSONWebSignature2020TestVectorPresentation1(t *testing.T) {
 	signer, jwk := getTestVectorKey0Signer(t, cryptosuite.Authentication)
 
 	// https://github.com/decentralized-identity/JWS-Test-Suite/blob/main/data/presentations/presentation-1.json
-	var credProof crypto.Proof = map[string]any{
-		"type":               "JsonWebSignature2020",
-		"created":            "2021-10-02T17:58:00Z",
-		"proofPurpose":       "assertionMethod",
-		"verificationMethod": "did:example:123#key-0",
-		"jws":                "eyJiNjQiOmZhbHNlLCJjcml0IjpbImI2NCJdLCJhbGciOiJFZERTQSJ9..VA8VQqAerUT6AIVdHc8W8Q2aj12LOQjV_VZ1e134NU9Q20eBsNySPjNdmTWp2HkdquCnbRhBHxIbNeFEIOOhAg",
+	credProof := crypto.Proof{
+		Type:               "JsonWebSignature2020",
+		Created:            "2021-10-02T17:58:00Z",
+		ProofPurpose:       "assertionMethod",
+		VerificationMethod: "did:example:123#key-0",
+		JWS:                "eyJiNjQiOmZhbHNlLCJjcml0IjpbImI2NCJdLCJhbGciOiJFZERTQSJ9..VA8VQqAerUT6AIVdHc8W8Q2aj12LOQjV_VZ1e134NU9Q20eBsNySPjNdmTWp2HkdquCnbRhBHxIbNeFEIOOhAg",
 	}
 	knownPres := TestVerifiablePresentation{
 		Context: []string{"https://www.w3.org/2018/credentials/v1",
@@ -401,13 +1155,13 @@ func TestJSONWebSignature2020TestVectorPresentation1(t *testing.T) {
 
 	// verify against known working impl
 	// https://identity.foundation/JWS-Test-Suite/implementations/transmute/presentation-1--key-0-ed25519.vp.json
-	var knownProof crypto.Proof = map[string]any{
-		"type":               "JsonWebSignature2020",
-		"created":            "2022-03-08T23:38:19Z",
-		"verificationMethod": "did:example:123#key-0",
-		"proofPurpose":       "authentication",
-		"challenge":          "123",
-		"jws":                "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..2Lckee0hjEiXlvl3X-Sp4ghqhc6HAH1AjnGwAYWC71i6k84U5ajb79aUWfwxUIMdQcE-hwbU6roUfsMWliDxAA",
+	knownProof := crypto.Proof{
+		Type:               "JsonWebSignature2020",
+		Created:            "2022-03-08T23:38:19Z",
+		VerificationMethod: "did:example:123#key-0",
+		ProofPurpose:       "authentication",
+		Challenge:          "123",
+		JWS:                "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..2Lckee0hjEiXlvl3X-Sp4ghqhc6HAH1AjnGwAYWC71i6k84U5ajb79aUWfwxUIMdQcE-hwbU6roUfsMWliDxAA",
 	}
 	signedPres := knownPres
 	signedPres.SetProof(&knownProof)
@@ -0,0 +1,95 @@
+package cryptosuite
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// DocumentLoader retrieves JSON-LD documents, such as contexts, by URL during canonicalization.
+// Any json-gold document loader satisfies this interface.
+type DocumentLoader interface {
+	LoadDocument(u string) (*ld.RemoteDocument, error)
+}
+
+var _ DocumentLoader = (*ContextDocumentLoader)(nil)
+
+// ContextDocumentLoader serves JSON-LD contexts held in memory, and defers to a fallback loader for
+// any URL it does not know about. Without a fallback the loader never reaches the network, which allows for
+// fully offline canonicalization.
+type ContextDocumentLoader struct {
+	mu       sync.RWMutex
+	contexts map[string]any
+	fallback DocumentLoader
+}
+
+// NewContextDocumentLoader creates a loader pre-populated with the well-known contexts bundled with this library.
+// The fallback loader is optional.
+func NewContextDocumentLoader(fallback DocumentLoader) (*ContextDocumentLoader, error) {
+	loader := ContextDocumentLoader{
+		contexts: make(map[string]any),
+		fallback: fallback,
+	}
+	for url, contents := range util.GetKnownContexts() {
+		if err := loader.AddContext(url, []byte(contents)); err != nil {
+			return nil, err
+		}
+	}
+	for url, fileName := range bundledContexts {
+		contextBytes, err := knownContexts.ReadFile("context/" + fileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading bundled context: %s", fileName)
+		}
+		if err = loader.AddContext(url, contextBytes); err != nil {
+			return nil, err
+		}
+	}
+	return &loader, nil
+}
+
+// NewDefaultDocumentLoader returns a loader with the library's bundled contexts which fetches all other
+// contexts over HTTP.
+func NewDefaultDocumentLoader() (*ContextDocumentLoader, error) {
+	return NewContextDocumentLoader(ld.NewDefaultDocumentLoader(nil))
+}
+
+// AddContext registers a JSON-LD document for the given URL, replacing any document previously registered for it
+func (l *ContextDocumentLoader) AddContext(url string, document []byte) error {
+	if url == "" {
+		return errors.New("context url cannot be empty")
+	}
+	var parsed any
+	if err := json.Unmarshal(document, &parsed); err != nil {
+		return errors.Wrapf(err, "parsing context: %s", url)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.contexts[url] = parsed
+	return nil
+}
+
+// LoadDocument returns the document registered for a URL, consulting the fallback loader when it is unknown
+func (l *ContextDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	l.mu.RLock()
+	document, ok := l.contexts[u]
+	l.mu.RUnlock()
+	if ok {
+		return &ld.RemoteDocument{DocumentURL: u, Document: document}, nil
+	}
+	if l.fallback == nil {
+		return nil, ld.NewJsonLdError(ld.LoadingDocumentFailed, fmt.Sprintf("unknown context: %s", u))
+	}
+	return l.fallback.LoadDocument(u)
+}
+
+// bundledContexts maps context URLs to the files in the embedded context directory
+var bundledContexts = map[string]string{
+	JSONWebKey2020Context: "lds-jws2020-v1.json",
+	MultikeyContext:       "multikey-v1.jsonld",
+	W3CSecurityContext:    "security-v2.jsonld",
+}
@@ -0,0 +1,62 @@
+package cryptosuite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextDocumentLoader(t *testing.T) {
+	t.Run("loads bundled contexts without a fallback", func(tt *testing.T) {
+		loader, err := NewContextDocumentLoader(nil)
+		assert.NoError(tt, err)
+
+		for _, url := range []string{JSONWebKey2020Context, MultikeyContext, W3CSecurityContext, "https://www.w3.org/2018/credentials/v1"} {
+			doc, err := loader.LoadDocument(url)
+			assert.NoError(tt, err)
+			assert.Equal(tt, url, doc.DocumentURL)
+			assert.NotEmpty(tt, doc.Document)
+		}
+	})
+
+	t.Run("unknown context without a fallback", func(tt *testing.T) {
+		loader, err := NewContextDocumentLoader(nil)
+		assert.NoError(tt, err)
+
+		_, err = loader.LoadDocument("https://example.com/unknown/v1")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unknown context")
+	})
+
+	t.Run("custom context", func(tt *testing.T) {
+		loader, err := NewContextDocumentLoader(nil)
+		assert.NoError(tt, err)
+
+		err = loader.AddContext("", []byte(`{}`))
+		assert.Error(tt, err)
+
+		err = loader.AddContext("https://example.com/custom/v1", []byte(`not json`))
+		assert.Error(tt, err)
+
+		err = loader.AddContext("https://example.com/custom/v1", []byte(`{"@context": {"@vocab": "https://example.com/#"}}`))
+		assert.NoError(tt, err)
+
+		doc, err := loader.LoadDocument("https://example.com/custom/v1")
+		assert.NoError(tt, err)
+		assert.Equal(tt, map[string]any{"@context": map[string]any{"@vocab": "https://example.com/#"}}, doc.Document)
+	})
+
+	t.Run("defers to fallback", func(tt *testing.T) {
+		fallback, err := NewContextDocumentLoader(nil)
+		assert.NoError(tt, err)
+		err = fallback.AddContext("https://example.com/custom/v1", []byte(`{"@context": {}}`))
+		assert.NoError(tt, err)
+
+		loader, err := NewContextDocumentLoader(fallback)
+		assert.NoError(tt, err)
+
+		doc, err := loader.LoadDocument("https://example.com/custom/v1")
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, doc.Document)
+	})
+}
@@ -0,0 +1,275 @@
+package cryptosuite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// OptionKey uniquely represents an option to be used when signing or verifying a proof
+	OptionKey string
+)
+
+const (
+	ExpiresOption     OptionKey = "expires"
+	ChallengeOption   OptionKey = "challenge"
+	DomainOption      OptionKey = "domain"
+	MaxProofAgeOption OptionKey = "maxProofAge"
+	ClockSkewOption   OptionKey = "clockSkew"
+
+	ProofValueOption OptionKey = "proofValue"
+
+	DocumentLoaderOption        OptionKey = "documentLoader"
+	CanonicalizationCacheOption OptionKey = "canonicalizationCache"
+	CanonicalizerOption         OptionKey = "canonicalizer"
+
+	CapabilityInvocationOption OptionKey = "capabilityInvocation"
+	CapabilityChainOption      OptionKey = "capabilityChain"
+
+	MandatoryPointersOption OptionKey = "mandatoryPointers"
+
+	// DefaultClockSkew is the tolerance applied to proof timestamps when no ClockSkewOption is provided
+	DefaultClockSkew = 5 * time.Minute
+)
+
+// Option represents a single option that may be provided to a CryptoSuite when signing or verifying
+type Option struct {
+	ID     OptionKey
+	Option any
+}
+
+// GetOption returns the value of the last option matching the given ID, and whether such an option was found
+func GetOption(opts []Option, id OptionKey) (any, bool) {
+	var value any
+	var found bool
+	for _, opt := range opts {
+		if opt.ID == id {
+			value = opt.Option
+			found = true
+		}
+	}
+	return value, found
+}
+
+// WithExpires sets the `expires` property of a proof created when signing, after which the proof
+// should no longer be considered valid
+func WithExpires(expires time.Time) Option {
+	return Option{
+		ID:     ExpiresOption,
+		Option: expires,
+	}
+}
+
+// WithChallenge sets the `challenge` of a proof created when signing, such as that supplied by a verifier requesting
+// a presentation, in place of any the suite generates
+func WithChallenge(challenge string) Option {
+	return Option{
+		ID:     ChallengeOption,
+		Option: challenge,
+	}
+}
+
+// WithDomain sets the `domain` of a proof created when signing, such as that of a verifier requesting a presentation
+func WithDomain(domain string) Option {
+	return Option{
+		ID:     DomainOption,
+		Option: domain,
+	}
+}
+
+// WithMaxProofAge rejects proofs whose `created` value is older than the given duration when verifying
+func WithMaxProofAge(age time.Duration) Option {
+	return Option{
+		ID:     MaxProofAgeOption,
+		Option: age,
+	}
+}
+
+// WithClockSkew sets the tolerance applied to a proof's `created` and `expires` values when verifying,
+// accounting for differences between the signer's and verifier's clocks
+func WithClockSkew(skew time.Duration) Option {
+	return Option{
+		ID:     ClockSkewOption,
+		Option: skew,
+	}
+}
+
+// WithProofValue requests that signing produce a proof carrying a multibase `proofValue` in place of a detached JWS.
+// The signer must implement RawSigner.
+func WithProofValue() Option {
+	return Option{
+		ID:     ProofValueOption,
+		Option: true,
+	}
+}
+
+// IsProofValueRequested returns whether WithProofValue was provided in the options
+func IsProofValueRequested(opts []Option) bool {
+	maybeProofValue, ok := GetOption(opts, ProofValueOption)
+	if !ok {
+		return false
+	}
+	requested, ok := maybeProofValue.(bool)
+	return ok && requested
+}
+
+// WithDocumentLoader configures a suite to resolve JSON-LD contexts using the provided loader
+func WithDocumentLoader(loader DocumentLoader) Option {
+	return Option{
+		ID:     DocumentLoaderOption,
+		Option: loader,
+	}
+}
+
+// WithCanonicalizationCache configures a suite to reuse canonicalization results held in the provided cache
+func WithCanonicalizationCache(cache *CanonicalizationCache) Option {
+	return Option{
+		ID:     CanonicalizationCacheOption,
+		Option: cache,
+	}
+}
+
+// WithCanonicalizer configures a suite to canonicalize documents using the provided canonicalizer in place of
+// the default URDNA2015Canonicalizer
+func WithCanonicalizer(canonicalizer Canonicalizer) Option {
+	return Option{
+		ID:     CanonicalizerOption,
+		Option: canonicalizer,
+	}
+}
+
+// GetDocumentLoaderOption returns the document loader provided in the options, if present
+func GetDocumentLoaderOption(opts []Option) (DocumentLoader, error) {
+	maybeLoader, ok := GetOption(opts, DocumentLoaderOption)
+	if !ok {
+		return nil, nil
+	}
+	loader, ok := maybeLoader.(DocumentLoader)
+	if !ok {
+		return nil, fmt.Errorf("invalid document loader option type: %T", maybeLoader)
+	}
+	return loader, nil
+}
+
+// GetCanonicalizationCacheOption returns the canonicalization cache provided in the options, if present
+func GetCanonicalizationCacheOption(opts []Option) (*CanonicalizationCache, error) {
+	maybeCache, ok := GetOption(opts, CanonicalizationCacheOption)
+	if !ok {
+		return nil, nil
+	}
+	cache, ok := maybeCache.(*CanonicalizationCache)
+	if !ok {
+		return nil, fmt.Errorf("invalid canonicalization cache option type: %T", maybeCache)
+	}
+	return cache, nil
+}
+
+// GetCanonicalizerOption returns the canonicalizer provided in the options, if present
+func GetCanonicalizerOption(opts []Option) (Canonicalizer, error) {
+	maybeCanonicalizer, ok := GetOption(opts, CanonicalizerOption)
+	if !ok {
+		return nil, nil
+	}
+	canonicalizer, ok := maybeCanonicalizer.(Canonicalizer)
+	if !ok {
+		return nil, fmt.Errorf("invalid canonicalizer option type: %T", maybeCanonicalizer)
+	}
+	return canonicalizer, nil
+}
+
+// GetExpiresOption returns the expiry time provided in the options, if present
+func GetExpiresOption(opts []Option) (*time.Time, error) {
+	maybeExpires, ok := GetOption(opts, ExpiresOption)
+	if !ok {
+		return nil, nil
+	}
+	expires, ok := maybeExpires.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("invalid expires option type: %T", maybeExpires)
+	}
+	return &expires, nil
+}
+
+// GetChallengeOption returns the challenge provided in the options, if present
+func GetChallengeOption(opts []Option) (string, error) {
+	return getStringOption(opts, ChallengeOption)
+}
+
+// GetDomainOption returns the domain provided in the options, if present
+func GetDomainOption(opts []Option) (string, error) {
+	return getStringOption(opts, DomainOption)
+}
+
+func getStringOption(opts []Option, id OptionKey) (string, error) {
+	maybeValue, ok := GetOption(opts, id)
+	if !ok {
+		return "", nil
+	}
+	value, ok := maybeValue.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid %s option type: %T", id, maybeValue)
+	}
+	return value, nil
+}
+
+// VerifyProofTimestamps checks a proof's `created` and `expires` values, which are expected to be RFC3339
+// timestamps, against the current time. A proof is rejected if it was created in the future, has expired,
+// or is older than the maximum proof age when one is provided. All comparisons allow for clock skew.
+func VerifyProofTimestamps(created, expires string, opts ...Option) error {
+	skew, err := getDurationOption(opts, ClockSkewOption)
+	if err != nil {
+		return err
+	}
+	clockSkew := DefaultClockSkew
+	if skew != nil {
+		clockSkew = *skew
+	}
+	if clockSkew < 0 {
+		return errors.New("clock skew cannot be negative")
+	}
+	maxAge, err := getDurationOption(opts, MaxProofAgeOption)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if created != "" {
+		createdTime, err := time.Parse(time.RFC3339, created)
+		if err != nil {
+			return errors.Wrapf(err, "parsing proof created value: %s", created)
+		}
+		if createdTime.After(now.Add(clockSkew)) {
+			return fmt.Errorf("proof created in the future as of %s", createdTime.String())
+		}
+		if maxAge != nil && now.Sub(createdTime) > *maxAge+clockSkew {
+			return fmt.Errorf("proof created at %s exceeds maximum age of %s", createdTime.String(), maxAge.String())
+		}
+	} else if maxAge != nil {
+		return errors.New("proof has no created value; cannot enforce maximum proof age")
+	}
+
+	if expires != "" {
+		expiresTime, err := time.Parse(time.RFC3339, expires)
+		if err != nil {
+			return errors.Wrapf(err, "parsing proof expires value: %s", expires)
+		}
+		if expiresTime.Add(clockSkew).Before(now) {
+			return fmt.Errorf("proof has expired as of %s", expiresTime.String())
+		}
+	}
+	return nil
+}
+
+func getDurationOption(opts []Option, id OptionKey) (*time.Duration, error) {
+	maybeDuration, ok := GetOption(opts, id)
+	if !ok {
+		return nil, nil
+	}
+	duration, ok := maybeDuration.(time.Duration)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s option type: %T", id, maybeDuration)
+	}
+	return &duration, nil
+}
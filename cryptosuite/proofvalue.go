@@ -0,0 +1,38 @@
+package cryptosuite
+
+import (
+	"github.com/multiformats/go-multibase"
+	"github.com/pkg/errors"
+)
+
+// RawSigner is implemented by signers which can produce a bare signature rather than a JWS, as needed for proofs
+// carrying a multibase `proofValue` https://www.w3.org/TR/vc-data-integrity/#proofs
+type RawSigner interface {
+	SignRaw(tbs []byte) ([]byte, error)
+}
+
+// RawVerifier is implemented by verifiers which can check a bare signature rather than a JWS, as needed for proofs
+// carrying a multibase `proofValue`
+type RawVerifier interface {
+	VerifyRaw(message, signature []byte) error
+}
+
+// EncodeProofValue encodes a signature as a base58-btc multibase string for use as a proof's `proofValue`
+func EncodeProofValue(signature []byte) (string, error) {
+	if len(signature) == 0 {
+		return "", errors.New("signature cannot be empty")
+	}
+	return multibase.Encode(multibase.Base58BTC, signature)
+}
+
+// DecodeProofValue decodes a multibase `proofValue` into signature bytes
+func DecodeProofValue(proofValue string) ([]byte, error) {
+	if proofValue == "" {
+		return nil, errors.New("proof value cannot be empty")
+	}
+	_, decoded, err := multibase.Decode(proofValue)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding multibase proof value")
+	}
+	return decoded, nil
+}
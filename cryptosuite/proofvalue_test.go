@@ -0,0 +1,33 @@
+package cryptosuite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofValue(t *testing.T) {
+	t.Run("round trip", func(tt *testing.T) {
+		signature := []byte("a signature")
+		proofValue, err := EncodeProofValue(signature)
+		assert.NoError(tt, err)
+		assert.Equal(tt, byte('z'), proofValue[0])
+
+		decoded, err := DecodeProofValue(proofValue)
+		assert.NoError(tt, err)
+		assert.Equal(tt, signature, decoded)
+	})
+
+	t.Run("empty values", func(tt *testing.T) {
+		_, err := EncodeProofValue(nil)
+		assert.Error(tt, err)
+
+		_, err = DecodeProofValue("")
+		assert.Error(tt, err)
+	})
+
+	t.Run("not multibase", func(tt *testing.T) {
+		_, err := DecodeProofValue("!not-multibase")
+		assert.ErrorContains(tt, err, "decoding multibase proof value")
+	})
+}
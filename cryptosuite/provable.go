@@ -0,0 +1,52 @@
+package cryptosuite
+
+import (
+	"bytes"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// NewGenericProvable converts any JSON-serializable document, such as a trust establishment document or a DID
+// configuration, into a GenericProvable which can be signed and verified by any CryptoSuite. Any existing `proof`
+// property on the document is kept.
+func NewGenericProvable(document any) (*GenericProvable, error) {
+	if document == nil {
+		return nil, errors.New("document cannot be empty")
+	}
+	documentBytes, err := json.Marshal(document)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling document")
+	}
+	var provable GenericProvable
+	if err = unmarshalPreservingNumbers(documentBytes, &provable); err != nil {
+		return nil, errors.Wrap(err, "document must be a JSON object")
+	}
+	if provable == nil {
+		return nil, errors.New("document must be a JSON object")
+	}
+	return &provable, nil
+}
+
+// ToDocument decodes the provable, including its proof, into the given document, which must be a pointer. To keep
+// the proof the document should have a `proof` property, such as a field of type *crypto.Proof.
+func (g *GenericProvable) ToDocument(document any) error {
+	if g == nil {
+		return errors.New("provable cannot be empty")
+	}
+	provableBytes, err := json.Marshal(g)
+	if err != nil {
+		return errors.Wrap(err, "marshalling provable")
+	}
+	if err = json.Unmarshal(provableBytes, document); err != nil {
+		return errors.Wrap(err, "unmarshalling provable into document")
+	}
+	return nil
+}
+
+// unmarshalPreservingNumbers unmarshals JSON keeping numbers as written so that they survive signing unchanged
+func unmarshalPreservingNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,127 @@
+package cryptosuite
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+// DataIntegrityCryptoSuite is implemented by suites which produce proofs of type DataIntegrityProof, and are
+// identified by the proof's `cryptosuite` property https://www.w3.org/TR/vc-data-integrity/#dataintegrityproof
+type DataIntegrityCryptoSuite interface {
+	CryptoSuite
+	CryptoSuiteName() string
+}
+
+type registryKey struct {
+	proofType   SignatureType
+	cryptoSuite string
+}
+
+func (k registryKey) String() string {
+	if k.cryptoSuite == "" {
+		return string(k.proofType)
+	}
+	return fmt.Sprintf("%s (%s)", k.proofType, k.cryptoSuite)
+}
+
+// Registry maps proof `type` and `cryptosuite` values to CryptoSuite implementations, allowing a verifier to
+// inspect a document's proof and dispatch to the appropriate suite. Registries are safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	suites map[registryKey]CryptoSuite
+}
+
+// NewRegistry creates a registry of the given suites. Each proof type, or proof type and cryptosuite pair for
+// Data Integrity suites, may only be registered once.
+func NewRegistry(suites ...CryptoSuite) (*Registry, error) {
+	r := &Registry{suites: make(map[registryKey]CryptoSuite)}
+	for _, suite := range suites {
+		if err := r.Register(suite); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a suite to the registry
+func (r *Registry) Register(suite CryptoSuite) error {
+	if suite == nil {
+		return errors.New("suite cannot be nil")
+	}
+	key := registryKey{proofType: suite.SignatureAlgorithm()}
+	if diSuite, ok := suite.(DataIntegrityCryptoSuite); ok {
+		key.cryptoSuite = diSuite.CryptoSuiteName()
+	}
+	if key.proofType == "" {
+		return errors.New("suite must have a signature algorithm")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.suites[key]; ok {
+		return fmt.Errorf("duplicate suite for proof type: %s", key)
+	}
+	r.suites[key] = suite
+	return nil
+}
+
+// GetSuite returns the suite registered for a proof type and, for Data Integrity proofs, cryptosuite name
+func (r *Registry) GetSuite(proofType SignatureType, cryptoSuite string) (CryptoSuite, error) {
+	key := registryKey{proofType: proofType, cryptoSuite: cryptoSuite}
+	r.mu.RLock()
+	suite, ok := r.suites[key]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, NewProofError(ErrUnsupportedSuite, proofType, "", fmt.Sprintf("unsupported proof type: %s", key), nil)
+	}
+	return suite, nil
+}
+
+// GetSuiteForProof inspects the `type` and `cryptosuite` properties of a proof and returns the matching suite
+func (r *Registry) GetSuiteForProof(p crypto.Proof) (CryptoSuite, error) {
+	proofType, cryptoSuite, err := GetProofType(p)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetSuite(proofType, cryptoSuite)
+}
+
+// Verify verifies a provable's embedded proof using the suite registered for the proof's type
+func (r *Registry) Verify(v Verifier, p WithEmbeddedProof, opts ...Option) error {
+	if p == nil || p.GetProof() == nil {
+		return NewProofError(ErrInvalidProof, "", "", "provable does not have a proof", nil)
+	}
+	suite, err := r.GetSuiteForProof(*p.GetProof())
+	if err != nil {
+		return errors.Wrap(err, "selecting suite for proof")
+	}
+	return suite.Verify(v, p, opts...)
+}
+
+// SupportedProofTypes returns the distinct proof types registered, in sorted order
+func (r *Registry) SupportedProofTypes() []SignatureType {
+	seen := make(map[SignatureType]bool)
+	var proofTypes []SignatureType
+	r.mu.RLock()
+	for key := range r.suites {
+		if !seen[key.proofType] {
+			seen[key.proofType] = true
+			proofTypes = append(proofTypes, key.proofType)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(proofTypes, func(i, j int) bool { return proofTypes[i] < proofTypes[j] })
+	return proofTypes
+}
+
+// GetProofType returns the `type` and, if present, `cryptosuite` properties of a single proof
+func GetProofType(p crypto.Proof) (SignatureType, string, error) {
+	if p.Type == "" {
+		return "", "", NewProofError(ErrInvalidProof, "", p.VerificationMethod, "proof does not have a type", nil)
+	}
+	return SignatureType(p.Type), p.CryptoSuite, nil
+}
@@ -0,0 +1,104 @@
+package cryptosuite
+
+import (
+	gocrypto "crypto"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+type testSuite struct {
+	proofType   SignatureType
+	cryptoSuite string
+	verified    bool
+}
+
+func (*testSuite) ID() string                                      { return "test-suite" }
+func (*testSuite) Type() LDKeyType                                 { return MultikeyType }
+func (*testSuite) CanonicalizationAlgorithm() string               { return "" }
+func (*testSuite) MessageDigestAlgorithm() gocrypto.Hash           { return gocrypto.SHA256 }
+func (s *testSuite) SignatureAlgorithm() SignatureType             { return s.proofType }
+func (*testSuite) RequiredContexts() []string                      { return nil }
+func (*testSuite) Sign(Signer, WithEmbeddedProof, ...Option) error { return nil }
+func (s *testSuite) Verify(Verifier, WithEmbeddedProof, ...Option) error {
+	s.verified = true
+	return nil
+}
+
+type testDataIntegritySuite struct {
+	testSuite
+}
+
+func (s *testDataIntegritySuite) CryptoSuiteName() string { return s.cryptoSuite }
+
+func TestRegistry(t *testing.T) {
+	t.Run("duplicate suites", func(tt *testing.T) {
+		_, err := NewRegistry(&testSuite{proofType: "TestSignature2020"}, &testSuite{proofType: "TestSignature2020"})
+		assert.ErrorContains(tt, err, "duplicate suite for proof type: TestSignature2020")
+
+		_, err = NewRegistry(nil)
+		assert.Error(tt, err)
+	})
+
+	t.Run("dispatches by type and cryptosuite", func(tt *testing.T) {
+		legacy := &testSuite{proofType: "TestSignature2020"}
+		eddsa := &testDataIntegritySuite{testSuite{proofType: DataIntegrityProofType, cryptoSuite: "eddsa-2022"}}
+		ecdsa := &testDataIntegritySuite{testSuite{proofType: DataIntegrityProofType, cryptoSuite: "ecdsa-2019"}}
+		registry, err := NewRegistry(legacy, eddsa, ecdsa)
+		assert.NoError(tt, err)
+		assert.Equal(tt, []SignatureType{DataIntegrityProofType, "TestSignature2020"}, registry.SupportedProofTypes())
+
+		proof := crypto.Proof{Type: "DataIntegrityProof", CryptoSuite: "ecdsa-2019"}
+		provable := GenericProvable{"proof": proof}
+		err = registry.Verify(nil, &provable)
+		assert.NoError(tt, err)
+		assert.True(tt, ecdsa.verified)
+		assert.False(tt, eddsa.verified)
+		assert.False(tt, legacy.verified)
+
+		suite, err := registry.GetSuiteForProof(crypto.Proof{Type: "TestSignature2020"})
+		assert.NoError(tt, err)
+		assert.Equal(tt, legacy, suite)
+	})
+
+	t.Run("unsupported proofs", func(tt *testing.T) {
+		registry, err := NewRegistry(&testSuite{proofType: "TestSignature2020"})
+		assert.NoError(tt, err)
+
+		_, err = registry.GetSuiteForProof(crypto.Proof{Type: "DataIntegrityProof", CryptoSuite: "eddsa-2022"})
+		assert.ErrorContains(tt, err, "unsupported proof type: DataIntegrityProof (eddsa-2022)")
+		assert.ErrorIs(tt, err, ErrUnsupportedSuite)
+
+		_, err = registry.GetSuiteForProof(crypto.Proof{JWS: "abc"})
+		assert.ErrorContains(tt, err, "proof does not have a type")
+
+		err = registry.Verify(nil, &GenericProvable{})
+		assert.ErrorContains(tt, err, "provable does not have a proof")
+	})
+
+	t.Run("concurrent registration and lookup", func(tt *testing.T) {
+		registry, err := NewRegistry()
+		assert.NoError(tt, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			proofType := SignatureType(fmt.Sprintf("TestSignature%d", i))
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				assert.NoError(tt, registry.Register(&testSuite{proofType: proofType}))
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = registry.GetSuite(proofType, "")
+				_ = registry.SupportedProofTypes()
+			}()
+		}
+		wg.Wait()
+		assert.Len(tt, registry.SupportedProofTypes(), 10)
+	})
+}
@@ -0,0 +1,281 @@
+package cryptosuite
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/gowebpki/jcs"
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+const (
+	// maxRemoteDocumentSize bounds the size of a JSON-LD document fetched over HTTP
+	maxRemoteDocumentSize = 5 << 20
+	maxRemoteRedirects    = 5
+)
+
+// RemoteDocumentLoaderConfig configures which JSON-LD documents a RemoteDocumentLoader may fetch and how they are
+// cached and checked.
+type RemoteDocumentLoaderConfig struct {
+	// AllowedDomains is the set of hosts documents may be fetched from, e.g. "w3id.org". Subdomains are not
+	// implicitly allowed, though an entry of the form "*.example.com" allows any subdomain of example.com.
+	AllowedDomains []string
+	// CacheDir is an optional directory used to persist fetched documents across loader instances
+	CacheDir string
+	// Pins maps document URLs to the hex-encoded SHA-256 digest of the document's JCS canonical form. Pinned
+	// documents that do not match their digest are rejected, wherever they were loaded from.
+	Pins map[string]string
+	// Fallback is an optional loader used for documents which cannot be fetched, such as when offline or when the
+	// host is not allowed.
+	Fallback DocumentLoader
+}
+
+var _ DocumentLoader = (*RemoteDocumentLoader)(nil)
+
+// RemoteDocumentLoader fetches JSON-LD documents over HTTPS from a set of allowed domains, caching results in
+// memory and optionally on disk.
+type RemoteDocumentLoader struct {
+	client *http.Client
+	config RemoteDocumentLoaderConfig
+
+	mu    sync.RWMutex
+	cache map[string]any
+}
+
+// NewRemoteDocumentLoader creates a loader which fetches documents with the given client. Redirects to hosts
+// outside the allowlist are refused.
+func NewRemoteDocumentLoader(client *http.Client, config RemoteDocumentLoaderConfig) (*RemoteDocumentLoader, error) {
+	if client == nil {
+		return nil, errors.New("client cannot be nil")
+	}
+	if len(config.AllowedDomains) == 0 {
+		return nil, errors.New("at least one allowed domain is required")
+	}
+	if config.CacheDir != "" {
+		if err := os.MkdirAll(config.CacheDir, 0o700); err != nil {
+			return nil, errors.Wrap(err, "creating cache directory")
+		}
+	}
+	for u, pin := range config.Pins {
+		if _, err := hex.DecodeString(pin); err != nil || len(pin) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid pin for %s: expected a hex-encoded sha-256 digest", u)
+		}
+	}
+	loader := RemoteDocumentLoader{
+		config: config,
+		cache:  make(map[string]any),
+	}
+
+	// copy the client so the redirect policy does not leak into the caller's client
+	restrictedClient := *client
+	restrictedClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRemoteRedirects {
+			return errors.New("too many redirects")
+		}
+		if !loader.isAllowed(req.URL) {
+			return fmt.Errorf("redirect to disallowed host: %s", req.URL.Host)
+		}
+		return nil
+	}
+	loader.client = &restrictedClient
+	return &loader, nil
+}
+
+// GetKnownContextPins returns pins for the JSON-LD contexts bundled with this library, which can be used to make
+// sure remotely fetched copies of these contexts have not changed.
+func GetKnownContextPins() (map[string]string, error) {
+	pins := make(map[string]string)
+	for u, contents := range util.GetKnownContexts() {
+		digest, err := DocumentDigest([]byte(contents))
+		if err != nil {
+			return nil, errors.Wrapf(err, "computing digest for %s", u)
+		}
+		pins[u] = digest
+	}
+	return pins, nil
+}
+
+// DocumentDigest computes the hex-encoded SHA-256 digest of a JSON document's JCS canonical form, suitable for
+// use as a pin in RemoteDocumentLoaderConfig.
+func DocumentDigest(document []byte) (string, error) {
+	canonical, err := jcs.Transform(document)
+	if err != nil {
+		return "", errors.Wrap(err, "canonicalizing document")
+	}
+	digest := sha256.Sum256(canonical)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// LoadDocument loads a document from memory, the on-disk cache, or the network in that order. If the document
+// cannot be fetched the fallback loader is consulted. Documents failing their integrity pin are always rejected.
+func (l *RemoteDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	l.mu.RLock()
+	inMemory, ok := l.cache[u]
+	l.mu.RUnlock()
+	if ok {
+		return &ld.RemoteDocument{DocumentURL: u, Document: inMemory}, nil
+	}
+
+	if cachedBytes, cacheErr := l.readCache(u); cacheErr == nil {
+		cached, err := l.parseDocument(u, cachedBytes)
+		if err != nil {
+			return nil, err
+		}
+		l.storeDocument(u, cached, nil)
+		return &ld.RemoteDocument{DocumentURL: u, Document: cached}, nil
+	}
+
+	documentBytes, fetchErr := l.fetch(u)
+	if fetchErr != nil {
+		if l.config.Fallback == nil {
+			return nil, ld.NewJsonLdError(ld.LoadingDocumentFailed, fetchErr.Error())
+		}
+		logrus.WithError(fetchErr).Debugf("using fallback loader for document: %s", util.SanitizeLog(u))
+		return l.loadFallbackDocument(u)
+	}
+	document, err := l.parseDocument(u, documentBytes)
+	if err != nil {
+		return nil, err
+	}
+	l.storeDocument(u, document, documentBytes)
+	return &ld.RemoteDocument{DocumentURL: u, Document: document}, nil
+}
+
+func (l *RemoteDocumentLoader) isAllowed(u *url.URL) bool {
+	if u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range l.config.AllowedDomains {
+		domain = strings.ToLower(domain)
+		if strings.HasPrefix(domain, "*.") {
+			if strings.HasSuffix(host, domain[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == domain {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *RemoteDocumentLoader) fetch(u string) ([]byte, error) {
+	parsedURL, err := url.Parse(u)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing document url: %s", u)
+	}
+	if !l.isAllowed(parsedURL) {
+		return nil, fmt.Errorf("document host is not allowed: %s", u)
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Accept", "application/ld+json, application/json")
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching document: %s", u)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching document %s: unexpected status code %d", u, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteDocumentSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading document: %s", u)
+	}
+	if len(body) > maxRemoteDocumentSize {
+		return nil, fmt.Errorf("document exceeds maximum size of %d bytes: %s", maxRemoteDocumentSize, u)
+	}
+	return body, nil
+}
+
+// loadFallbackDocument loads a document with the fallback loader, checking it against its pin, if one exists
+func (l *RemoteDocumentLoader) loadFallbackDocument(u string) (*ld.RemoteDocument, error) {
+	remoteDocument, err := l.config.Fallback.LoadDocument(u)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := l.config.Pins[u]; !ok {
+		return remoteDocument, nil
+	}
+	documentBytes, err := json.Marshal(remoteDocument.Document)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshalling document: %s", u)
+	}
+	if err = l.checkPin(u, documentBytes); err != nil {
+		return nil, err
+	}
+	return remoteDocument, nil
+}
+
+// checkPin checks a document against its pin, if one exists
+func (l *RemoteDocumentLoader) checkPin(u string, documentBytes []byte) error {
+	pin, ok := l.config.Pins[u]
+	if !ok {
+		return nil
+	}
+	digest, err := DocumentDigest(documentBytes)
+	if err != nil {
+		return errors.Wrapf(err, "computing digest for document: %s", u)
+	}
+	if !strings.EqualFold(digest, pin) {
+		return fmt.Errorf("document %s does not match its pinned digest", u)
+	}
+	return nil
+}
+
+// parseDocument checks a document against its pin, if one exists, and returns its parsed form
+func (l *RemoteDocumentLoader) parseDocument(u string, documentBytes []byte) (any, error) {
+	if err := l.checkPin(u, documentBytes); err != nil {
+		return nil, err
+	}
+	var document any
+	if err := json.Unmarshal(documentBytes, &document); err != nil {
+		return nil, errors.Wrapf(err, "parsing document: %s", u)
+	}
+	return document, nil
+}
+
+// storeDocument caches a document in memory, and on disk when raw bytes are provided and a cache dir is configured
+func (l *RemoteDocumentLoader) storeDocument(u string, document any, documentBytes []byte) {
+	l.mu.Lock()
+	l.cache[u] = document
+	l.mu.Unlock()
+
+	if l.config.CacheDir == "" || documentBytes == nil {
+		return
+	}
+	if err := os.WriteFile(l.cachePath(u), documentBytes, 0o600); err != nil {
+		logrus.WithError(err).Warnf("could not write document to cache: %s", util.SanitizeLog(u))
+	}
+}
+
+func (l *RemoteDocumentLoader) readCache(u string) ([]byte, error) {
+	if l.config.CacheDir == "" {
+		return nil, errors.New("no cache directory configured")
+	}
+	return os.ReadFile(l.cachePath(u))
+}
+
+func (l *RemoteDocumentLoader) cachePath(u string) string {
+	name := sha256.Sum256([]byte(u))
+	return filepath.Join(l.config.CacheDir, hex.EncodeToString(name[:])+".jsonld")
+}
@@ -0,0 +1,180 @@
+package cryptosuite
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testContextURL = "https://contexts.example.com/custom/v1"
+	testContext    = `{"@context": {"@vocab": "https://contexts.example.com/custom#"}}`
+)
+
+func TestRemoteDocumentLoader(t *testing.T) {
+	t.Run("bad config", func(tt *testing.T) {
+		_, err := NewRemoteDocumentLoader(nil, RemoteDocumentLoaderConfig{AllowedDomains: []string{"example.com"}})
+		assert.ErrorContains(tt, err, "client cannot be nil")
+
+		_, err = NewRemoteDocumentLoader(http.DefaultClient, RemoteDocumentLoaderConfig{})
+		assert.ErrorContains(tt, err, "at least one allowed domain is required")
+
+		_, err = NewRemoteDocumentLoader(http.DefaultClient, RemoteDocumentLoaderConfig{
+			AllowedDomains: []string{"example.com"},
+			Pins:           map[string]string{testContextURL: "not-a-digest"},
+		})
+		assert.ErrorContains(tt, err, "invalid pin")
+	})
+
+	t.Run("fetches and caches allowed documents", func(tt *testing.T) {
+		client := new(http.Client)
+		httpmock.ActivateNonDefault(client)
+		defer httpmock.DeactivateAndReset()
+		httpmock.RegisterResponder(http.MethodGet, testContextURL, httpmock.NewStringResponder(http.StatusOK, testContext))
+
+		loader, err := NewRemoteDocumentLoader(client, RemoteDocumentLoaderConfig{AllowedDomains: []string{"*.example.com"}})
+		assert.NoError(tt, err)
+
+		for i := 0; i < 3; i++ {
+			doc, err := loader.LoadDocument(testContextURL)
+			assert.NoError(tt, err)
+			assert.Equal(tt, testContextURL, doc.DocumentURL)
+			assert.NotEmpty(tt, doc.Document)
+		}
+		assert.Equal(tt, 1, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("rejects hosts that are not allowed", func(tt *testing.T) {
+		client := new(http.Client)
+		httpmock.ActivateNonDefault(client)
+		defer httpmock.DeactivateAndReset()
+		httpmock.RegisterResponder(http.MethodGet, testContextURL, httpmock.NewStringResponder(http.StatusOK, testContext))
+
+		loader, err := NewRemoteDocumentLoader(client, RemoteDocumentLoaderConfig{AllowedDomains: []string{"example.com"}})
+		assert.NoError(tt, err)
+
+		_, err = loader.LoadDocument(testContextURL)
+		assert.ErrorContains(tt, err, "document host is not allowed")
+
+		_, err = loader.LoadDocument("http://example.com/insecure/v1")
+		assert.ErrorContains(tt, err, "document host is not allowed")
+		assert.Equal(tt, 0, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("enforces pins", func(tt *testing.T) {
+		client := new(http.Client)
+		httpmock.ActivateNonDefault(client)
+		defer httpmock.DeactivateAndReset()
+		httpmock.RegisterResponder(http.MethodGet, testContextURL, httpmock.NewStringResponder(http.StatusOK, testContext))
+
+		// whitespace differences do not affect the digest
+		pin, err := DocumentDigest([]byte(`{ "@context":{ "@vocab":"https://contexts.example.com/custom#" } }`))
+		assert.NoError(tt, err)
+
+		loader, err := NewRemoteDocumentLoader(client, RemoteDocumentLoaderConfig{
+			AllowedDomains: []string{"contexts.example.com"},
+			Pins:           map[string]string{testContextURL: pin},
+		})
+		assert.NoError(tt, err)
+		_, err = loader.LoadDocument(testContextURL)
+		assert.NoError(tt, err)
+
+		badPin, err := DocumentDigest([]byte(`{"@context": {}}`))
+		assert.NoError(tt, err)
+		loader, err = NewRemoteDocumentLoader(client, RemoteDocumentLoaderConfig{
+			AllowedDomains: []string{"contexts.example.com"},
+			Pins:           map[string]string{testContextURL: badPin},
+		})
+		assert.NoError(tt, err)
+		_, err = loader.LoadDocument(testContextURL)
+		assert.ErrorContains(tt, err, "does not match its pinned digest")
+	})
+
+	t.Run("uses disk cache across loaders", func(tt *testing.T) {
+		cacheDir := tt.TempDir()
+		client := new(http.Client)
+		httpmock.ActivateNonDefault(client)
+		defer httpmock.DeactivateAndReset()
+		httpmock.RegisterResponder(http.MethodGet, testContextURL, httpmock.NewStringResponder(http.StatusOK, testContext))
+
+		config := RemoteDocumentLoaderConfig{AllowedDomains: []string{"contexts.example.com"}, CacheDir: cacheDir}
+		loader, err := NewRemoteDocumentLoader(client, config)
+		assert.NoError(tt, err)
+		_, err = loader.LoadDocument(testContextURL)
+		assert.NoError(tt, err)
+
+		// a second loader reads from disk, even when the network is unavailable
+		httpmock.RegisterResponder(http.MethodGet, testContextURL, httpmock.NewStringResponder(http.StatusServiceUnavailable, ""))
+		secondLoader, err := NewRemoteDocumentLoader(client, config)
+		assert.NoError(tt, err)
+		doc, err := secondLoader.LoadDocument(testContextURL)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, doc.Document)
+		assert.Equal(tt, 1, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("uses fallback when offline", func(tt *testing.T) {
+		client := new(http.Client)
+		httpmock.ActivateNonDefault(client)
+		defer httpmock.DeactivateAndReset()
+		httpmock.RegisterResponder(http.MethodGet, JSONWebKey2020Context, httpmock.NewStringResponder(http.StatusServiceUnavailable, ""))
+
+		loader, err := NewRemoteDocumentLoader(client, RemoteDocumentLoaderConfig{AllowedDomains: []string{"w3id.org"}})
+		assert.NoError(tt, err)
+		_, err = loader.LoadDocument(JSONWebKey2020Context)
+		assert.ErrorContains(tt, err, "unexpected status code 503")
+
+		fallback, err := NewContextDocumentLoader(nil)
+		assert.NoError(tt, err)
+		loader, err = NewRemoteDocumentLoader(client, RemoteDocumentLoaderConfig{AllowedDomains: []string{"w3id.org"}, Fallback: fallback})
+		assert.NoError(tt, err)
+		doc, err := loader.LoadDocument(JSONWebKey2020Context)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, doc.Document)
+
+		// documents of the fallback loader are checked against their pins too
+		pins, err := GetKnownContextPins()
+		assert.NoError(tt, err)
+		loader, err = NewRemoteDocumentLoader(client, RemoteDocumentLoaderConfig{AllowedDomains: []string{"w3id.org"}, Pins: pins, Fallback: fallback})
+		assert.NoError(tt, err)
+		_, err = loader.LoadDocument(JSONWebKey2020Context)
+		assert.NoError(tt, err)
+
+		badPin, err := DocumentDigest([]byte(`{"@context": {}}`))
+		assert.NoError(tt, err)
+		loader, err = NewRemoteDocumentLoader(client, RemoteDocumentLoaderConfig{
+			AllowedDomains: []string{"w3id.org"},
+			Pins:           map[string]string{JSONWebKey2020Context: badPin},
+			Fallback:       fallback,
+		})
+		assert.NoError(tt, err)
+		_, err = loader.LoadDocument(JSONWebKey2020Context)
+		assert.ErrorContains(tt, err, "does not match its pinned digest")
+	})
+
+	t.Run("known context pins match bundled contexts", func(tt *testing.T) {
+		pins, err := GetKnownContextPins()
+		assert.NoError(tt, err)
+		assert.Contains(tt, pins, JSONWebKey2020Context)
+
+		bundled, err := knownContexts.ReadFile("context/" + bundledContexts[JSONWebKey2020Context])
+		assert.NoError(tt, err)
+
+		client := new(http.Client)
+		httpmock.ActivateNonDefault(client)
+		defer httpmock.DeactivateAndReset()
+		httpmock.RegisterResponder(http.MethodGet, JSONWebKey2020Context, httpmock.NewBytesResponder(http.StatusOK, bundled))
+		httpmock.RegisterResponder(http.MethodGet, W3CSecurityContext, httpmock.NewStringResponder(http.StatusOK, `{"@context": {}}`))
+
+		loader, err := NewRemoteDocumentLoader(client, RemoteDocumentLoaderConfig{AllowedDomains: []string{"w3id.org"}, Pins: pins})
+		assert.NoError(tt, err)
+		_, err = loader.LoadDocument(JSONWebKey2020Context)
+		assert.NoError(tt, err)
+
+		// a tampered copy of a known context is rejected
+		_, err = loader.LoadDocument(W3CSecurityContext)
+		assert.ErrorContains(tt, err, "does not match its pinned digest")
+	})
+}
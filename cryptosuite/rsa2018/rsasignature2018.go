@@ -0,0 +1,245 @@
+package rsa2018
+
+import (
+	gocrypto "crypto"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+)
+
+// https://w3c-ccg.github.io/lds-rsa2018/
+
+const (
+	RSASignature2018                           cryptosuite.SignatureType = "RsaSignature2018"
+	RSASignatureSuiteID                        string                    = "https://w3id.org/security#RsaSignature2018"
+	RSASignatureSuiteType                      cryptosuite.LDKeyType     = "RsaVerificationKey2018"
+	RSASignatureSuiteCanonicalizationAlgorithm string                    = "https://w3id.org/security#URDNA2015"
+	// RSASignatureSuiteDigestAlgorithm uses https://www.rfc-editor.org/rfc/rfc4634
+	RSASignatureSuiteDigestAlgorithm gocrypto.Hash = gocrypto.SHA256
+	// RSASignatureSuiteProofAlgorithm uses https://www.rfc-editor.org/rfc/rfc7797 with PS256 signatures
+	RSASignatureSuiteProofAlgorithm = RSASignature2018
+)
+
+// RSASignatureSuite supports verifying legacy RsaSignature2018 proofs, which are still produced by a number of
+// government and enterprise issuers. Signing is not supported; new proofs should use JsonWebSignature2020 which
+// supports the same RSA keys.
+type RSASignatureSuite struct {
+	// proofType is used for the create verify hash algorithm, which is shared with JsonWebSignature2020
+	proofType cryptosuite.CryptoSuiteProofType
+}
+
+func GetRSASignature2018Suite() cryptosuite.CryptoSuite {
+	return &RSASignatureSuite{proofType: new(jws2020.JWSSignatureSuite)}
+}
+
+// NewRSASignature2018Suite returns a RsaSignature2018 suite configured with the provided options.
+// Supported options are cryptosuite.WithDocumentLoader, cryptosuite.WithCanonicalizationCache, and
+// cryptosuite.WithCanonicalizer.
+func NewRSASignature2018Suite(opts ...cryptosuite.Option) (cryptosuite.CryptoSuite, error) {
+	suite, err := jws2020.NewJSONWebSignature2020Suite(opts...)
+	if err != nil {
+		return nil, err
+	}
+	proofType, ok := suite.(cryptosuite.CryptoSuiteProofType)
+	if !ok {
+		return nil, errors.New("suite does not support the create verify hash algorithm")
+	}
+	return &RSASignatureSuite{proofType: proofType}, nil
+}
+
+// CryptoSuiteInfo interface
+
+var _ cryptosuite.CryptoSuiteInfo = (*RSASignatureSuite)(nil)
+
+func (RSASignatureSuite) ID() string {
+	return RSASignatureSuiteID
+}
+
+func (RSASignatureSuite) Type() cryptosuite.LDKeyType {
+	return RSASignatureSuiteType
+}
+
+func (RSASignatureSuite) CanonicalizationAlgorithm() string {
+	return RSASignatureSuiteCanonicalizationAlgorithm
+}
+
+func (RSASignatureSuite) MessageDigestAlgorithm() gocrypto.Hash {
+	return RSASignatureSuiteDigestAlgorithm
+}
+
+func (RSASignatureSuite) SignatureAlgorithm() cryptosuite.SignatureType {
+	return RSASignatureSuiteProofAlgorithm
+}
+
+func (RSASignatureSuite) RequiredContexts() []string {
+	return []string{cryptosuite.W3CSecurityContext}
+}
+
+func (RSASignatureSuite) Sign(cryptosuite.Signer, cryptosuite.WithEmbeddedProof, ...cryptosuite.Option) error {
+	return fmt.Errorf("%s is only supported for verification; use %s to sign", RSASignature2018, jws2020.JSONWebSignature2020)
+}
+
+// Verify verifies a RsaSignature2018 proof. The verifier must accept a detached, unencoded payload JWS signed with
+// PS256, such as a jws2020.JSONWebKeyVerifier constructed from an RSA key.
+func (r RSASignatureSuite) Verify(v cryptosuite.Verifier, p cryptosuite.WithEmbeddedProof, opts ...cryptosuite.Option) error {
+	proof := p.GetProof()
+	if proof == nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, RSASignature2018, "", "provable does not have a proof", nil)
+	}
+	gotProof, err := RSASignatureProofFromGenericProof(*proof)
+	if err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, RSASignature2018, proof.VerificationMethod, "preparing proof for verification; error coercing proof into RsaSignature2018 proof", err)
+	}
+	verificationMethod := gotProof.VerificationMethod
+	if gotProof.Type != RSASignature2018 {
+		return cryptosuite.NewProofError(cryptosuite.ErrUnsupportedSuite, gotProof.Type, verificationMethod, fmt.Sprintf("unsupported proof type: %s", gotProof.Type), nil)
+	}
+
+	// reject stale, expired, or future-dated proofs before doing any cryptographic work
+	if err = cryptosuite.VerifyProofTimestamps(gotProof.Created, gotProof.Expires, opts...); err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, RSASignature2018, verificationMethod, "verifying proof timestamps", err)
+	}
+
+	// make sure the signature was made with the algorithm required by the suite
+	jwsCopy := []byte(gotProof.JWS)
+	if err = checkDetachedJWSHeader(gotProof.JWS); err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrInvalidProof, RSASignature2018, verificationMethod, "checking jws header", err)
+	}
+
+	// remove proof before verifying
+	p.SetProof(nil)
+
+	// make sure we set it back after we're done verifying
+	defer p.SetProof(proof)
+
+	// remove the JWS in the proof before verification
+	gotProof.JWS = ""
+
+	// legacy signers use the document's contexts for the proof, falling back to the security context
+	contexts, err := cryptosuite.GetContextsFromProvable(p)
+	if err != nil {
+		return errors.Wrap(err, "getting contexts from provable")
+	}
+	proofOpts := &cryptosuite.ProofOptions{Contexts: contexts}
+
+	// run the create verify hash algorithm on both provable and the proof
+	var genericProvable map[string]any
+	pBytes, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "marshalling provable")
+	}
+	if err = json.Unmarshal(pBytes, &genericProvable); err != nil {
+		return errors.Wrap(err, "unmarshalling provable")
+	}
+	tbv, err := r.CreateVerifyHash(genericProvable, gotProof.ToGenericProof(), proofOpts)
+	if err != nil {
+		return cryptosuite.NewProofError(cryptosuite.ErrCanonicalization, RSASignature2018, verificationMethod, "create verify hash algorithm failed", err)
+	}
+	if err = v.Verify(tbv, jwsCopy); err != nil {
+		return cryptosuite.NewSignatureError(RSASignature2018, verificationMethod, "verifying JWS", err)
+	}
+	return nil
+}
+
+// CreateVerifyHash runs the create verify hash algorithm, which RsaSignature2018 shares with JsonWebSignature2020.
+// When no contexts are provided the proof is canonicalized using the security context.
+func (r RSASignatureSuite) CreateVerifyHash(doc map[string]any, proof crypto.Proof, opts *cryptosuite.ProofOptions) ([]byte, error) {
+	if opts == nil || len(opts.Contexts) == 0 {
+		opts = &cryptosuite.ProofOptions{Contexts: []any{cryptosuite.W3CSecurityContext}}
+	}
+	return r.proofType.CreateVerifyHash(doc, proof, opts)
+}
+
+// checkDetachedJWSHeader makes sure a JWS is detached, with an unencoded payload, and signed using PS256
+func checkDetachedJWSHeader(detachedJWS string) error {
+	jwsParts := strings.Split(detachedJWS, ".")
+	if len(jwsParts) != 3 || jwsParts[1] != "" {
+		return errors.New("malformed jws: expected a detached jws")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(jwsParts[0])
+	if err != nil {
+		return errors.Wrap(err, "decoding jws header")
+	}
+	var header struct {
+		ALG  string   `json:"alg"`
+		B64  *bool    `json:"b64"`
+		Crit []string `json:"crit"`
+	}
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return errors.Wrap(err, "unmarshalling jws header")
+	}
+	if header.ALG != jwa.PS256.String() {
+		return fmt.Errorf("unsupported jws algorithm for %s: %s", RSASignature2018, header.ALG)
+	}
+	if header.B64 == nil || *header.B64 {
+		return errors.New("jws must have an unencoded payload")
+	}
+	return nil
+}
+
+type RSASignature2018Proof struct {
+	Type               cryptosuite.SignatureType `json:"type,omitempty"`
+	Created            string                    `json:"created,omitempty"`
+	Expires            string                    `json:"expires,omitempty"`
+	JWS                string                    `json:"jws,omitempty"`
+	ProofPurpose       cryptosuite.ProofPurpose  `json:"proofPurpose,omitempty"`
+	Challenge          string                    `json:"challenge,omitempty"`
+	Domain             string                    `json:"domain,omitempty"`
+	Nonce              string                    `json:"nonce,omitempty"`
+	VerificationMethod string                    `json:"verificationMethod,omitempty"`
+	// Creator identifies the signing key in proofs produced before the introduction of verificationMethod
+	Creator string `json:"creator,omitempty"`
+}
+
+func RSASignatureProofFromGenericProof(p crypto.Proof) (*RSASignature2018Proof, error) {
+	proofBytes, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var result RSASignature2018Proof
+	if err = json.Unmarshal(proofBytes, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ToGenericProof returns the proof as a crypto.Proof, with the legacy `creator` property held as an extension
+func (r *RSASignature2018Proof) ToGenericProof() crypto.Proof {
+	if r == nil {
+		return crypto.Proof{}
+	}
+	proof := crypto.Proof{
+		Type:               string(r.Type),
+		Created:            r.Created,
+		Expires:            r.Expires,
+		JWS:                r.JWS,
+		ProofPurpose:       string(r.ProofPurpose),
+		Challenge:          r.Challenge,
+		Domain:             r.Domain,
+		Nonce:              r.Nonce,
+		VerificationMethod: r.VerificationMethod,
+	}
+	if r.Creator != "" {
+		proof.SetProperty("creator", r.Creator)
+	}
+	return proof
+}
+
+// GetVerificationMethod returns the key used to create the proof, accounting for legacy proofs using `creator`
+func (r *RSASignature2018Proof) GetVerificationMethod() string {
+	if r == nil {
+		return ""
+	}
+	if r.VerificationMethod != "" {
+		return r.VerificationMethod
+	}
+	return r.Creator
+}
@@ -0,0 +1,154 @@
+package rsa2018
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+)
+
+func TestRSASignature2018Suite(t *testing.T) {
+	suite := GetRSASignature2018Suite()
+	jwk, err := jws2020.GenerateJSONWebKey2020(jws2020.RSA, "")
+	require.NoError(t, err)
+	signer, err := jws2020.NewJSONWebKeySigner("did:example:123#key-1", jwk.PrivateKeyJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+	verifier, err := jws2020.NewJSONWebKeyVerifier("did:example:123#key-1", jwk.PublicKeyJWK)
+	require.NoError(t, err)
+
+	t.Run("suite info", func(tt *testing.T) {
+		assert.Equal(tt, RSASignature2018, suite.SignatureAlgorithm())
+		assert.Equal(tt, []string{cryptosuite.W3CSecurityContext}, suite.RequiredContexts())
+
+		cred := getTestCredential()
+		err := suite.Sign(signer, &cred)
+		assert.ErrorContains(tt, err, "only supported for verification")
+	})
+
+	t.Run("verifies legacy proofs", func(tt *testing.T) {
+		cred := getTestCredential()
+		signLegacyProof(tt, signer, &cred, RSASignature2018Proof{VerificationMethod: "did:example:123#key-1"})
+
+		err := suite.Verify(verifier, &cred)
+		assert.NoError(tt, err)
+
+		// the proof is restored after verification
+		assert.NotNil(tt, cred.GetProof())
+	})
+
+	t.Run("verifies proofs using creator", func(tt *testing.T) {
+		cred := getTestCredential()
+		signLegacyProof(tt, signer, &cred, RSASignature2018Proof{Creator: "did:example:123#key-1"})
+
+		err := suite.Verify(verifier, &cred)
+		assert.NoError(tt, err)
+
+		p, err := RSASignatureProofFromGenericProof(*cred.GetProof())
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:123#key-1", p.GetVerificationMethod())
+	})
+
+	t.Run("tampered credential", func(tt *testing.T) {
+		cred := getTestCredential()
+		signLegacyProof(tt, signer, &cred, RSASignature2018Proof{VerificationMethod: "did:example:123#key-1"})
+
+		cred["issuer"] = "did:example:abc"
+		err := suite.Verify(verifier, &cred)
+		assert.ErrorContains(tt, err, "verifying JWS")
+		assert.ErrorIs(tt, err, cryptosuite.ErrInvalidSignature)
+	})
+
+	t.Run("rejects other algorithms", func(tt *testing.T) {
+		ecJWK, err := jws2020.GenerateJSONWebKey2020(jws2020.EC, jws2020.P256)
+		assert.NoError(tt, err)
+		ecSigner, err := jws2020.NewJSONWebKeySigner("did:example:123#key-2", ecJWK.PrivateKeyJWK, cryptosuite.AssertionMethod)
+		assert.NoError(tt, err)
+		ecVerifier, err := jws2020.NewJSONWebKeyVerifier("did:example:123#key-2", ecJWK.PublicKeyJWK)
+		assert.NoError(tt, err)
+
+		cred := getTestCredential()
+		signLegacyProof(tt, ecSigner, &cred, RSASignature2018Proof{VerificationMethod: "did:example:123#key-2"})
+		err = suite.Verify(ecVerifier, &cred)
+		assert.ErrorContains(tt, err, "unsupported jws algorithm for RsaSignature2018: ES256")
+		assert.ErrorIs(tt, err, cryptosuite.ErrInvalidProof)
+	})
+
+	t.Run("rejects encoded payloads", func(tt *testing.T) {
+		cred := getTestCredential()
+		signLegacyProof(tt, signer, &cred, RSASignature2018Proof{VerificationMethod: "did:example:123#key-1"})
+		p, err := RSASignatureProofFromGenericProof(*cred.GetProof())
+		assert.NoError(tt, err)
+
+		// swap the protected header for one without the b64 parameter
+		_, signature, _ := strings.Cut(p.JWS, ".")
+		p.JWS = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"PS256"}`)) + "." + signature
+		genericProof := p.ToGenericProof()
+		cred.SetProof(&genericProof)
+		err = suite.Verify(verifier, &cred)
+		assert.ErrorContains(tt, err, "jws must have an unencoded payload")
+	})
+
+	t.Run("rejects other proof types", func(tt *testing.T) {
+		cred := getTestCredential()
+		err := jws2020.GetJSONWebSignature2020Suite().Sign(signer, &cred)
+		assert.NoError(tt, err)
+
+		err = suite.Verify(verifier, &cred)
+		assert.ErrorContains(tt, err, "unsupported proof type: JsonWebSignature2020")
+		assert.ErrorIs(tt, err, cryptosuite.ErrUnsupportedSuite)
+	})
+
+	t.Run("dispatches from a registry", func(tt *testing.T) {
+		registry, err := cryptosuite.NewRegistry(jws2020.GetJSONWebSignature2020Suite(), suite)
+		assert.NoError(tt, err)
+
+		legacyCred := getTestCredential()
+		signLegacyProof(tt, signer, &legacyCred, RSASignature2018Proof{VerificationMethod: "did:example:123#key-1"})
+		assert.NoError(tt, registry.Verify(verifier, &legacyCred))
+
+		cred := getTestCredential()
+		assert.NoError(tt, jws2020.GetJSONWebSignature2020Suite().Sign(signer, &cred))
+		assert.NoError(tt, registry.Verify(verifier, &cred))
+	})
+}
+
+// signLegacyProof creates a RsaSignature2018 proof in the way legacy JSON-LD signature libraries do, with an
+// unencoded payload signalled in the JWS's protected header
+func signLegacyProof(t *testing.T, signer *jws2020.JSONWebKeySigner, p *cryptosuite.GenericProvable, proof RSASignature2018Proof) {
+	proof.Type = RSASignature2018
+	proof.Created = time.Now().UTC().Format(time.RFC3339)
+	proof.ProofPurpose = cryptosuite.AssertionMethod
+
+	contexts, err := cryptosuite.GetContextsFromProvable(p)
+	require.NoError(t, err)
+	suite := GetRSASignature2018Suite().(*RSASignatureSuite)
+	tbs, err := suite.CreateVerifyHash(*p, proof.ToGenericProof(), &cryptosuite.ProofOptions{Contexts: contexts})
+	require.NoError(t, err)
+	headers := jws.NewHeaders()
+	require.NoError(t, headers.Set("b64", false))
+	require.NoError(t, headers.Set(jws.CriticalKey, []string{"b64"}))
+	signature, err := jws.Sign(nil, jws.WithKey(jwa.SignatureAlgorithm(signer.ALG), signer.PrivateKey, jws.WithProtectedHeaders(headers)), jws.WithDetachedPayload(tbs))
+	require.NoError(t, err)
+
+	proof.JWS = string(signature)
+	genericProof := proof.ToGenericProof()
+	p.SetProof(&genericProof)
+}
+
+func getTestCredential() cryptosuite.GenericProvable {
+	return cryptosuite.GenericProvable{
+		"@context":          []any{"https://www.w3.org/2018/credentials/v1"},
+		"type":              []any{"VerifiableCredential"},
+		"issuer":            "did:example:123",
+		"issuanceDate":      "2021-01-01T19:23:24Z",
+		"credentialSubject": map[string]any{"id": "did:example:456"},
+	}
+}
@@ -0,0 +1,377 @@
+package cryptosuite
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// Selective disclosure suites, such as ecdsa-sd-2023 and bbs-2023, share the mechanism by which an issuer declares the
+// parts of a document a holder must always disclose, and by which the canonical form of a document is split into
+// mandatory and non-mandatory statements.
+// https://www.w3.org/TR/vc-di-ecdsa/#selective-disclosure-functions
+
+const (
+	// SkolemIDPrefix is the prefix of the IRIs used to give blank nodes stable identifiers while grouping statements
+	SkolemIDPrefix = "urn:bnid:"
+)
+
+// DisclosureGroups holds the canonical N-Quads of a document, split into the statements selected by a set of JSON
+// pointers and the remaining statements. Groups are keyed by the index of the statement in Quads.
+// Blank nodes are skolemized, that is replaced with IRIs prefixed by SkolemIDPrefix, so the same node has the same
+// identifier in the document and in any selection from it.
+type DisclosureGroups struct {
+	Quads        []string
+	Mandatory    map[int]string
+	NonMandatory map[int]string
+}
+
+// MandatoryIndexes returns the indexes of the mandatory statements in ascending order
+func (d DisclosureGroups) MandatoryIndexes() []int {
+	return sortedIndexes(d.Mandatory)
+}
+
+// NonMandatoryIndexes returns the indexes of the non-mandatory statements in ascending order
+func (d DisclosureGroups) NonMandatoryIndexes() []int {
+	return sortedIndexes(d.NonMandatory)
+}
+
+func sortedIndexes(group map[int]string) []int {
+	indexes := make([]int, 0, len(group))
+	for i := range group {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// WithMandatoryPointers sets the JSON pointers, as defined by https://www.rfc-editor.org/rfc/rfc6901, to the parts of
+// a document a holder must always disclose when deriving a proof from a selective disclosure base proof
+func WithMandatoryPointers(pointers ...string) Option {
+	return Option{
+		ID:     MandatoryPointersOption,
+		Option: pointers,
+	}
+}
+
+// GetMandatoryPointersOption returns the mandatory pointers provided in the options, if present
+func GetMandatoryPointersOption(opts []Option) ([]string, error) {
+	maybePointers, ok := GetOption(opts, MandatoryPointersOption)
+	if !ok {
+		return nil, nil
+	}
+	pointers, ok := maybePointers.([]string)
+	if !ok {
+		return nil, fmt.Errorf("invalid mandatory pointers option type: %T", maybePointers)
+	}
+	for _, pointer := range pointers {
+		if _, err := ParseJSONPointer(pointer); err != nil {
+			return nil, err
+		}
+	}
+	return pointers, nil
+}
+
+// ParseJSONPointer parses a JSON pointer into its unescaped reference tokens
+// https://www.rfc-editor.org/rfc/rfc6901
+func ParseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, errors.New("json pointer cannot be empty")
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("json pointer<%s> must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("json pointer<%s> has an invalid escape sequence", pointer)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// SelectJSONLD creates a document containing only the values identified by the given JSON pointers, along with the
+// `@context` of the document and the `id` and `type` of every object on the path to a selected value. Arrays in the
+// selection keep only their selected elements, in their original order. A nil document is returned when no pointers
+// are provided.
+// This is currently experimental, as no selective disclosure suite of this SDK uses it yet; it is subject to change.
+// https://www.w3.org/TR/vc-di-ecdsa/#selectjsonld
+func SelectJSONLD(pointers []string, document map[string]any) (map[string]any, error) {
+	if len(pointers) == 0 {
+		return nil, nil
+	}
+	selection := initialSelection(document)
+	if context, ok := document["@context"]; ok {
+		selection["@context"] = context
+	}
+	for _, pointer := range pointers {
+		if err := selectPath(pointer, document, selection); err != nil {
+			return nil, err
+		}
+	}
+	return compactSelection(selection).(map[string]any), nil
+}
+
+// sparseArray collects selected array elements by their index in the source array
+type sparseArray map[int]any
+
+func initialSelection(source map[string]any) map[string]any {
+	selection := make(map[string]any)
+	if id, ok := source["id"].(string); ok && !strings.HasPrefix(id, "_:") {
+		selection["id"] = id
+	}
+	if t, ok := source["type"]; ok {
+		selection["type"] = t
+	}
+	return selection
+}
+
+func selectPath(pointer string, document map[string]any, selection map[string]any) error {
+	tokens, err := ParseJSONPointer(pointer)
+	if err != nil {
+		return err
+	}
+	var value any = document
+	var selected any = selection
+	for i, token := range tokens {
+		var next any
+		var found bool
+		switch parent := value.(type) {
+		case map[string]any:
+			next, found = parent[token]
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err == nil && index >= 0 && index < len(parent) && strconv.Itoa(index) == token {
+				next, found = parent[index], true
+			}
+		}
+		if !found {
+			return fmt.Errorf("json pointer<%s> does not match the document", pointer)
+		}
+
+		last := i == len(tokens)-1
+		var child any
+		switch typedNext := next.(type) {
+		case map[string]any:
+			child = initialSelection(typedNext)
+		case []any:
+			child = make(sparseArray)
+		}
+		if last || child == nil {
+			// copy selected values so later pointers into them cannot modify the document
+			child = copyJSONValue(next)
+		}
+		if child, err = setSelected(selected, token, child, last); err != nil {
+			return errors.Wrapf(err, "selecting json pointer<%s>", pointer)
+		}
+		value, selected = next, child
+	}
+	return nil
+}
+
+// setSelected sets the value at the given token in a selection, keeping a value selected by an earlier pointer
+// unless overwrite is set
+func setSelected(selected any, token string, value any, overwrite bool) (any, error) {
+	switch parent := selected.(type) {
+	case map[string]any:
+		if existing, ok := parent[token]; ok && !overwrite {
+			return existing, nil
+		}
+		parent[token] = value
+	case sparseArray:
+		index, err := strconv.Atoi(token)
+		if err != nil {
+			return nil, err
+		}
+		if existing, ok := parent[index]; ok && !overwrite {
+			return existing, nil
+		}
+		parent[index] = value
+	case []any:
+		// the array was selected in full by an earlier pointer
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || index >= len(parent) {
+			return nil, fmt.Errorf("invalid array index: %s", token)
+		}
+		if !overwrite {
+			return parent[index], nil
+		}
+		parent[index] = value
+	default:
+		return nil, fmt.Errorf("cannot select into value of type %T", selected)
+	}
+	return value, nil
+}
+
+// compactSelection turns the sparse arrays of a selection into arrays holding the selected elements in order
+func compactSelection(selection any) any {
+	switch typedSelection := selection.(type) {
+	case map[string]any:
+		for key, value := range typedSelection {
+			typedSelection[key] = compactSelection(value)
+		}
+		return typedSelection
+	case sparseArray:
+		indexes := make([]int, 0, len(typedSelection))
+		for i := range typedSelection {
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+		compacted := make([]any, 0, len(indexes))
+		for _, i := range indexes {
+			compacted = append(compacted, compactSelection(typedSelection[i]))
+		}
+		return compacted
+	default:
+		return selection
+	}
+}
+
+func copyJSONValue(value any) any {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(typedValue))
+		for key, v := range typedValue {
+			copied[key] = copyJSONValue(v)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(typedValue))
+		for i, v := range typedValue {
+			copied[i] = copyJSONValue(v)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+// ComputeDisclosureGroups canonicalizes a document and groups its statements into those selected by the mandatory
+// pointers and the rest. A nil canonicalizer uses the URDNA2015Canonicalizer; a nil loader uses the default loader.
+func ComputeDisclosureGroups(document map[string]any, mandatoryPointers []string, canonicalizer Canonicalizer, loader DocumentLoader) (*DisclosureGroups, error) {
+	if document == nil {
+		return nil, errors.New("document cannot be empty")
+	}
+	if canonicalizer == nil {
+		canonicalizer = URDNA2015Canonicalizer{}
+	}
+
+	skolemized, err := skolemizedCopy(document)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := canonicalizer.Canonicalize(skolemized, loader)
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing document")
+	}
+	groups := DisclosureGroups{
+		Quads:        splitQuads(canonical),
+		Mandatory:    make(map[int]string),
+		NonMandatory: make(map[int]string),
+	}
+
+	mandatory := make(map[string]bool)
+	if len(mandatoryPointers) > 0 {
+		selection, err := SelectJSONLD(mandatoryPointers, skolemized)
+		if err != nil {
+			return nil, errors.Wrap(err, "selecting mandatory values")
+		}
+		canonicalSelection, err := canonicalizer.Canonicalize(selection, loader)
+		if err != nil {
+			return nil, errors.Wrap(err, "canonicalizing mandatory values")
+		}
+		for _, quad := range splitQuads(canonicalSelection) {
+			mandatory[quad] = true
+		}
+	}
+	for i, quad := range groups.Quads {
+		if mandatory[quad] {
+			groups.Mandatory[i] = quad
+		} else {
+			groups.NonMandatory[i] = quad
+		}
+	}
+	return &groups, nil
+}
+
+// skolemizedCopy returns a skolemized copy of a document, leaving the caller's document unmodified
+func skolemizedCopy(document any) (map[string]any, error) {
+	documentBytes, err := json.Marshal(document)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling document")
+	}
+	var skolemized map[string]any
+	if err = json.Unmarshal(documentBytes, &skolemized); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling document")
+	}
+	counter := 0
+	skolemize(skolemized, &counter)
+	return skolemized, nil
+}
+
+// deskolemize removes the identifiers added by skolemize
+func deskolemize(value any) {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		if id, ok := typedValue["id"].(string); ok && strings.HasPrefix(id, SkolemIDPrefix) {
+			delete(typedValue, "id")
+		}
+		for key, v := range typedValue {
+			if key != "@context" {
+				deskolemize(v)
+			}
+		}
+	case []any:
+		for _, item := range typedValue {
+			deskolemize(item)
+		}
+	}
+}
+
+// skolemize gives every node object without an `id` an identifier prefixed by SkolemIDPrefix. Keys are visited in
+// sorted order so the identifiers are deterministic.
+func skolemize(value any, counter *int) {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		if _, isValue := typedValue["@value"]; isValue {
+			return
+		}
+		if _, hasID := typedValue["id"]; !hasID {
+			typedValue["id"] = fmt.Sprintf("%s_:b%d", SkolemIDPrefix, *counter)
+			*counter++
+		}
+		keys := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if key == "@context" {
+				continue
+			}
+			skolemize(typedValue[key], counter)
+		}
+	case []any:
+		for _, item := range typedValue {
+			skolemize(item, counter)
+		}
+	}
+}
+
+func splitQuads(canonical string) []string {
+	lines := strings.Split(canonical, "\n")
+	quads := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line != "" {
+			quads = append(quads, line)
+		}
+	}
+	return quads
+}
@@ -0,0 +1,120 @@
+package cryptosuite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSONPointer(t *testing.T) {
+	tokens, err := ParseJSONPointer("/credentialSubject/a~1b/m~0n/0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"credentialSubject", "a/b", "m~n", "0"}, tokens)
+
+	_, err = ParseJSONPointer("")
+	assert.ErrorContains(t, err, "json pointer cannot be empty")
+
+	_, err = ParseJSONPointer("issuer")
+	assert.ErrorContains(t, err, "must start with '/'")
+
+	_, err = ParseJSONPointer("/a~2")
+	assert.ErrorContains(t, err, "invalid escape sequence")
+
+	_, err = GetMandatoryPointersOption([]Option{WithMandatoryPointers("/issuer", "bad")})
+	assert.ErrorContains(t, err, "json pointer<bad> must start with '/'")
+
+	pointers, err := GetMandatoryPointersOption([]Option{WithMandatoryPointers("/issuer")})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/issuer"}, pointers)
+}
+
+func TestSelectJSONLD(t *testing.T) {
+	document := getSelectiveDisclosureTestDocument()
+
+	t.Run("no pointers", func(tt *testing.T) {
+		selection, err := SelectJSONLD(nil, document)
+		assert.NoError(tt, err)
+		assert.Nil(tt, selection)
+	})
+
+	t.Run("keeps ids and types along the path", func(tt *testing.T) {
+		selection, err := SelectJSONLD([]string{"/issuer", "/credentialSubject/sailNumber", "/credentialSubject/boards/1/year"}, document)
+		assert.NoError(tt, err)
+		assert.Equal(tt, map[string]any{
+			"@context": document["@context"],
+			"type":     []any{"VerifiableCredential"},
+			"issuer":   "did:example:123",
+			"credentialSubject": map[string]any{
+				"sailNumber": "Earth101",
+				"boards": []any{
+					map[string]any{"type": "Board", "year": float64(2023)},
+				},
+			},
+		}, selection)
+	})
+
+	t.Run("does not modify the document", func(tt *testing.T) {
+		selection, err := SelectJSONLD([]string{"/credentialSubject", "/credentialSubject/boards/0/name"}, document)
+		assert.NoError(tt, err)
+		selection["credentialSubject"].(map[string]any)["sailNumber"] = "Mars101"
+		assert.Equal(tt, "Earth101", document["credentialSubject"].(map[string]any)["sailNumber"])
+	})
+
+	t.Run("pointer not in document", func(tt *testing.T) {
+		_, err := SelectJSONLD([]string{"/credentialSubject/boards/5"}, document)
+		assert.ErrorContains(tt, err, "json pointer</credentialSubject/boards/5> does not match the document")
+	})
+}
+
+func TestComputeDisclosureGroups(t *testing.T) {
+	loader, err := NewContextDocumentLoader(nil)
+	assert.NoError(t, err)
+	document := getSelectiveDisclosureTestDocument()
+
+	groups, err := ComputeDisclosureGroups(document, []string{"/issuer", "/credentialSubject/sailNumber"}, nil, loader)
+	assert.NoError(t, err)
+	assert.Len(t, groups.Quads, len(groups.Mandatory)+len(groups.NonMandatory))
+	assert.NotEmpty(t, groups.NonMandatory)
+
+	var mandatory []string
+	for _, i := range groups.MandatoryIndexes() {
+		mandatory = append(mandatory, groups.Quads[i])
+	}
+	joined := strings.Join(mandatory, "\n")
+	assert.Contains(t, joined, "<https://www.w3.org/2018/credentials#issuer> <did:example:123>")
+	assert.Contains(t, joined, `"Earth101"`)
+	assert.NotContains(t, joined, `"Kite"`)
+
+	// blank nodes are skolemized without modifying the document
+	assert.Contains(t, strings.Join(groups.Quads, "\n"), SkolemIDPrefix)
+	_, hasID := document["credentialSubject"].(map[string]any)["id"]
+	assert.False(t, hasID)
+
+	// grouping is deterministic
+	again, err := ComputeDisclosureGroups(document, []string{"/issuer", "/credentialSubject/sailNumber"}, nil, loader)
+	assert.NoError(t, err)
+	assert.Equal(t, groups, again)
+
+	_, err = ComputeDisclosureGroups(document, []string{"/credentialSubject/unknown"}, nil, loader)
+	assert.ErrorContains(t, err, "selecting mandatory values")
+}
+
+func getSelectiveDisclosureTestDocument() map[string]any {
+	return map[string]any{
+		"@context": []any{
+			"https://www.w3.org/2018/credentials/v1",
+			map[string]any{"@vocab": "https://windsurf.grotto-networking.com/selective#"},
+		},
+		"type":         []any{"VerifiableCredential"},
+		"issuer":       "did:example:123",
+		"issuanceDate": "2021-01-01T19:23:24Z",
+		"credentialSubject": map[string]any{
+			"sailNumber": "Earth101",
+			"boards": []any{
+				map[string]any{"type": "Board", "name": "Kite", "year": float64(2022)},
+				map[string]any{"type": "Board", "name": "Foil", "year": float64(2023)},
+			},
+		},
+	}
+}
@@ -0,0 +1,413 @@
+package cryptosuite
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+const (
+	// RootCapabilityPrefix prefixes the identifier of a root capability, followed by its encoded invocation target
+	RootCapabilityPrefix = "urn:zcap:root:"
+
+	// MaxCapabilityChainLength bounds the number of capabilities, including the root, in a delegation chain
+	MaxCapabilityChainLength = 10
+)
+
+// Capability is an authorization capability as defined by ZCAP-LD https://w3c-ccg.github.io/zcap-spec/
+// A root capability has no parent and no proof; it is authorized by virtue of its controller controlling the
+// invocation target. Delegated capabilities reference their parent and carry a capabilityDelegation proof made by
+// the parent's controller.
+type Capability struct {
+	Context          any           `json:"@context,omitempty"`
+	ID               string        `json:"id"`
+	ParentCapability string        `json:"parentCapability,omitempty"`
+	Controller       string        `json:"controller"`
+	InvocationTarget string        `json:"invocationTarget"`
+	AllowedAction    []string      `json:"allowedAction,omitempty"`
+	Expires          string        `json:"expires,omitempty"`
+	Proof            *crypto.Proof `json:"proof,omitempty"`
+}
+
+func (c *Capability) GetProof() *crypto.Proof {
+	return c.Proof
+}
+
+func (c *Capability) SetProof(p *crypto.Proof) {
+	c.Proof = p
+}
+
+// IsRoot returns whether the capability is a root capability
+func (c Capability) IsRoot() bool {
+	return c.ParentCapability == ""
+}
+
+// NewRootCapability creates the root capability for an invocation target, controlled by the given controller
+func NewRootCapability(controller, invocationTarget string) (*Capability, error) {
+	if controller == "" {
+		return nil, errors.New("controller cannot be empty")
+	}
+	if invocationTarget == "" {
+		return nil, errors.New("invocation target cannot be empty")
+	}
+	return &Capability{
+		Context:          W3CSecurityContext,
+		ID:               RootCapabilityPrefix + url.QueryEscape(invocationTarget),
+		Controller:       controller,
+		InvocationTarget: invocationTarget,
+	}, nil
+}
+
+// DelegateCapability creates an unsigned capability delegating the parent capability to a new controller. The
+// delegated capability may only permit a subset of the parent's allowed actions; when none are given, the parent's
+// are inherited. The result must be signed by the parent's controller using the capabilityDelegation proof purpose.
+func DelegateCapability(parent Capability, controller string, allowedActions ...string) (*Capability, error) {
+	if parent.ID == "" {
+		return nil, errors.New("parent capability must have an id")
+	}
+	if controller == "" {
+		return nil, errors.New("controller cannot be empty")
+	}
+	if len(allowedActions) == 0 {
+		allowedActions = parent.AllowedAction
+	}
+	if !actionsAllowed(parent.AllowedAction, allowedActions) {
+		return nil, fmt.Errorf("allowed actions %v exceed those of parent capability<%s>", allowedActions, parent.ID)
+	}
+	return &Capability{
+		Context:          W3CSecurityContext,
+		ID:               "urn:uuid:" + uuid.NewString(),
+		ParentCapability: parent.ID,
+		Controller:       controller,
+		InvocationTarget: parent.InvocationTarget,
+		AllowedAction:    allowedActions,
+		Expires:          parent.Expires,
+	}, nil
+}
+
+// CapabilityInvocationOptions holds the ZCAP-LD properties set on a capabilityInvocation proof
+type CapabilityInvocationOptions struct {
+	Capability       string
+	CapabilityAction string
+	InvocationTarget string
+}
+
+// WithCapabilityInvocation sets the capability being invoked, and the action being taken, on a proof created with
+// the capabilityInvocation proof purpose
+func WithCapabilityInvocation(capability Capability, action string) Option {
+	return Option{
+		ID: CapabilityInvocationOption,
+		Option: CapabilityInvocationOptions{
+			Capability:       capability.ID,
+			CapabilityAction: action,
+			InvocationTarget: capability.InvocationTarget,
+		},
+	}
+}
+
+// WithCapabilityChain sets the `capabilityChain` of a proof created with the capabilityDelegation proof purpose.
+// The chain lists the ancestors of the capability being delegated, starting with the root capability and ending
+// with its parent.
+func WithCapabilityChain(chain ...Capability) Option {
+	ids := make([]string, 0, len(chain))
+	for _, c := range chain {
+		ids = append(ids, c.ID)
+	}
+	return Option{
+		ID:     CapabilityChainOption,
+		Option: ids,
+	}
+}
+
+// GetCapabilityInvocationOption returns the capability invocation provided in the options, if present
+func GetCapabilityInvocationOption(opts []Option) (*CapabilityInvocationOptions, error) {
+	maybeInvocation, ok := GetOption(opts, CapabilityInvocationOption)
+	if !ok {
+		return nil, nil
+	}
+	invocation, ok := maybeInvocation.(CapabilityInvocationOptions)
+	if !ok {
+		return nil, fmt.Errorf("invalid capability invocation option type: %T", maybeInvocation)
+	}
+	return &invocation, nil
+}
+
+// GetCapabilityChainOption returns the capability chain provided in the options, if present
+func GetCapabilityChainOption(opts []Option) ([]string, error) {
+	maybeChain, ok := GetOption(opts, CapabilityChainOption)
+	if !ok {
+		return nil, nil
+	}
+	chain, ok := maybeChain.([]string)
+	if !ok {
+		return nil, fmt.Errorf("invalid capability chain option type: %T", maybeChain)
+	}
+	return chain, nil
+}
+
+// CapabilityTarget is the invocation target capabilities are verified for, and the controller the verifier knows
+// controls it, which must be the controller of the target's root capability
+type CapabilityTarget struct {
+	ID         string
+	Controller string
+}
+
+// VerifierResolver returns a verifier for the key identified by a proof's verification method
+type VerifierResolver func(verificationMethod string) (Verifier, error)
+
+// capabilityProof holds the properties of a proof relevant to ZCAP-LD
+type capabilityProof struct {
+	ProofPurpose       ProofPurpose `json:"proofPurpose"`
+	VerificationMethod string       `json:"verificationMethod"`
+	Capability         string       `json:"capability,omitempty"`
+	CapabilityAction   string       `json:"capabilityAction,omitempty"`
+	InvocationTarget   string       `json:"invocationTarget,omitempty"`
+	CapabilityChain    []any        `json:"capabilityChain,omitempty"`
+}
+
+// VerifyCapabilityChain verifies a delegation chain for an invocation target, ordered from the root capability to
+// the last delegated capability. The root capability must be the target's, controlled by the target's controller.
+// Each delegation must be signed by the controller of its parent, and may only attenuate the parent's invocation
+// target, allowed actions, and expiry.
+func VerifyCapabilityChain(suite CryptoSuite, resolveVerifier VerifierResolver, chain []Capability, target CapabilityTarget, opts ...Option) error {
+	if suite == nil {
+		return errors.New("suite cannot be nil")
+	}
+	if resolveVerifier == nil {
+		return errors.New("verifier resolver cannot be nil")
+	}
+	if len(chain) == 0 {
+		return errors.New("capability chain cannot be empty")
+	}
+	if len(chain) > MaxCapabilityChainLength {
+		return fmt.Errorf("capability chain exceeds maximum length of %d", MaxCapabilityChainLength)
+	}
+
+	root := chain[0]
+	if !root.IsRoot() {
+		return fmt.Errorf("first capability<%s> in chain must be a root capability", root.ID)
+	}
+	if root.ID == "" || root.Controller == "" || root.InvocationTarget == "" {
+		return errors.New("root capability must have an id, controller, and invocation target")
+	}
+	if target.ID == "" || target.Controller == "" {
+		return errors.New("invocation target must have an id and controller")
+	}
+	if root.ID != RootCapabilityPrefix+url.QueryEscape(target.ID) || root.InvocationTarget != target.ID {
+		return fmt.Errorf("root capability<%s> is not the root capability of invocation target<%s>", root.ID, target.ID)
+	}
+	if root.Controller != target.Controller {
+		return fmt.Errorf("root capability<%s> is not controlled by the controller of its invocation target", root.ID)
+	}
+	now := time.Now()
+	if err := checkCapabilityExpiry(root, now); err != nil {
+		return err
+	}
+
+	seen := map[string]bool{root.ID: true}
+	for i := 1; i < len(chain); i++ {
+		parent, capability := chain[i-1], chain[i]
+		if seen[capability.ID] {
+			return fmt.Errorf("capability<%s> appears more than once in chain", capability.ID)
+		}
+		seen[capability.ID] = true
+		if err := checkDelegation(parent, capability, now); err != nil {
+			return err
+		}
+
+		// the delegation must be signed by the parent's controller over the chain of ancestors
+		if capability.GetProof() == nil {
+			return fmt.Errorf("delegated capability<%s> must have a proof", capability.ID)
+		}
+		proof, err := getCapabilityProof(*capability.GetProof())
+		if err != nil {
+			return errors.Wrapf(err, "reading proof of capability<%s>", capability.ID)
+		}
+		if proof.ProofPurpose != CapabilityDelegation {
+			return fmt.Errorf("proof of capability<%s> must have purpose %s", capability.ID, CapabilityDelegation)
+		}
+		if err = checkCapabilityChainProperty(proof.CapabilityChain, chain[:i]); err != nil {
+			return errors.Wrapf(err, "checking capability chain of capability<%s>", capability.ID)
+		}
+		if !isControlledBy(proof.VerificationMethod, parent.Controller) {
+			return fmt.Errorf("capability<%s> was not delegated by the controller of its parent", capability.ID)
+		}
+		verifier, err := resolveVerifier(proof.VerificationMethod)
+		if err != nil {
+			return errors.Wrapf(err, "resolving verifier for verification method<%s>", proof.VerificationMethod)
+		}
+		if err = suite.Verify(verifier, &capability, opts...); err != nil {
+			return errors.Wrapf(err, "verifying delegation proof of capability<%s>", capability.ID)
+		}
+	}
+	return nil
+}
+
+// VerifyCapabilityInvocation verifies a document's capabilityInvocation proof against the last capability in a
+// delegation chain. The invocation must be made by the capability's controller, for an action and target permitted
+// by the capability, and the chain itself must be valid for the invocation target.
+func VerifyCapabilityInvocation(suite CryptoSuite, resolveVerifier VerifierResolver, p WithEmbeddedProof, chain []Capability, target CapabilityTarget, opts ...Option) error {
+	if p == nil || p.GetProof() == nil {
+		return errors.New("provable must have a proof")
+	}
+	if len(chain) == 0 {
+		return errors.New("capability chain cannot be empty")
+	}
+	capability := chain[len(chain)-1]
+	proof, err := getCapabilityProof(*p.GetProof())
+	if err != nil {
+		return errors.Wrap(err, "reading invocation proof")
+	}
+	if proof.ProofPurpose != CapabilityInvocation {
+		return fmt.Errorf("invocation proof must have purpose %s", CapabilityInvocation)
+	}
+	if proof.Capability != capability.ID {
+		return fmt.Errorf("invocation proof references capability<%s>, expected<%s>", proof.Capability, capability.ID)
+	}
+	if !targetAllowed(capability.InvocationTarget, proof.InvocationTarget) {
+		return fmt.Errorf("invocation target<%s> is not permitted by capability<%s>", proof.InvocationTarget, capability.ID)
+	}
+	if len(capability.AllowedAction) > 0 && !actionsAllowed(capability.AllowedAction, []string{proof.CapabilityAction}) {
+		return fmt.Errorf("action<%s> is not permitted by capability<%s>", proof.CapabilityAction, capability.ID)
+	}
+	if !isControlledBy(proof.VerificationMethod, capability.Controller) {
+		return fmt.Errorf("invocation was not made by the controller of capability<%s>", capability.ID)
+	}
+
+	if err = VerifyCapabilityChain(suite, resolveVerifier, chain, target, opts...); err != nil {
+		return errors.Wrap(err, "verifying capability chain")
+	}
+	verifier, err := resolveVerifier(proof.VerificationMethod)
+	if err != nil {
+		return errors.Wrapf(err, "resolving verifier for verification method<%s>", proof.VerificationMethod)
+	}
+	if err = suite.Verify(verifier, p, opts...); err != nil {
+		return errors.Wrap(err, "verifying invocation proof")
+	}
+	return nil
+}
+
+// checkDelegation makes sure a capability is a valid attenuation of its parent
+func checkDelegation(parent, capability Capability, now time.Time) error {
+	if capability.ID == "" || capability.Controller == "" {
+		return errors.New("delegated capability must have an id and controller")
+	}
+	if capability.ParentCapability != parent.ID {
+		return fmt.Errorf("capability<%s> has parent<%s>, expected<%s>", capability.ID, capability.ParentCapability, parent.ID)
+	}
+	if !targetAllowed(parent.InvocationTarget, capability.InvocationTarget) {
+		return fmt.Errorf("invocation target of capability<%s> is not permitted by its parent", capability.ID)
+	}
+	if len(parent.AllowedAction) > 0 &&
+		(len(capability.AllowedAction) == 0 || !actionsAllowed(parent.AllowedAction, capability.AllowedAction)) {
+		return fmt.Errorf("allowed actions of capability<%s> exceed those of its parent", capability.ID)
+	}
+	if err := checkCapabilityExpiry(capability, now); err != nil {
+		return err
+	}
+	if parent.Expires != "" {
+		if capability.Expires == "" {
+			return fmt.Errorf("capability<%s> must not outlive its parent", capability.ID)
+		}
+		parentExpires, err := time.Parse(time.RFC3339, parent.Expires)
+		if err != nil {
+			return errors.Wrapf(err, "parsing expiry of capability<%s>", parent.ID)
+		}
+		expires, err := time.Parse(time.RFC3339, capability.Expires)
+		if err != nil {
+			return errors.Wrapf(err, "parsing expiry of capability<%s>", capability.ID)
+		}
+		if expires.After(parentExpires) {
+			return fmt.Errorf("capability<%s> must not outlive its parent", capability.ID)
+		}
+	}
+	return nil
+}
+
+func checkCapabilityExpiry(capability Capability, now time.Time) error {
+	if capability.Expires == "" {
+		return nil
+	}
+	expires, err := time.Parse(time.RFC3339, capability.Expires)
+	if err != nil {
+		return errors.Wrapf(err, "parsing expiry of capability<%s>", capability.ID)
+	}
+	if now.After(expires) {
+		return fmt.Errorf("capability<%s> expired at %s", capability.ID, capability.Expires)
+	}
+	return nil
+}
+
+// checkCapabilityChainProperty makes sure a proof's capabilityChain lists the given ancestors in order. Entries may
+// be either identifiers or embedded capabilities.
+func checkCapabilityChainProperty(capabilityChain []any, ancestors []Capability) error {
+	if len(capabilityChain) != len(ancestors) {
+		return fmt.Errorf("expected %d entries, found %d", len(ancestors), len(capabilityChain))
+	}
+	for i, entry := range capabilityChain {
+		var id string
+		switch typedEntry := entry.(type) {
+		case string:
+			id = typedEntry
+		case map[string]any:
+			id, _ = typedEntry["id"].(string)
+		}
+		if id != ancestors[i].ID {
+			return fmt.Errorf("entry %d references capability<%s>, expected<%s>", i, id, ancestors[i].ID)
+		}
+	}
+	return nil
+}
+
+func getCapabilityProof(p crypto.Proof) (*capabilityProof, error) {
+	proofBytes, err := json.Marshal(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling proof")
+	}
+	var proof capabilityProof
+	if err = json.Unmarshal(proofBytes, &proof); err != nil {
+		return nil, errors.Wrap(err, "proof must be a single proof object")
+	}
+	return &proof, nil
+}
+
+// isControlledBy returns whether a verification method belongs to the given controller
+func isControlledBy(verificationMethod, controller string) bool {
+	if verificationMethod == "" || controller == "" {
+		return false
+	}
+	owner, _, _ := strings.Cut(verificationMethod, "#")
+	return owner == controller
+}
+
+// targetAllowed returns whether a target is the same as, or is nested beneath, the permitted target
+func targetAllowed(permitted, target string) bool {
+	if target == permitted {
+		return true
+	}
+	return strings.HasPrefix(target, strings.TrimSuffix(permitted, "/")+"/")
+}
+
+// actionsAllowed returns whether all actions are within the permitted set. An empty permitted set allows any action.
+func actionsAllowed(permitted, actions []string) bool {
+	if len(permitted) == 0 {
+		return true
+	}
+	allowed := make(map[string]bool, len(permitted))
+	for _, action := range permitted {
+		allowed[action] = true
+	}
+	for _, action := range actions {
+		if !allowed[action] {
+			return false
+		}
+	}
+	return true
+}
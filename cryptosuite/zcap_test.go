@@ -0,0 +1,150 @@
+package cryptosuite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestCapabilityChain(t *testing.T) {
+	suite := &testSuite{proofType: "TestSignature2020"}
+	resolveVerifier := func(string) (Verifier, error) { return nil, nil }
+
+	delegate := func(tt *testing.T, parent Capability, chain []Capability, controller string, actions ...string) Capability {
+		capability, err := DelegateCapability(parent, controller, actions...)
+		assert.NoError(tt, err)
+		ids := make([]any, 0, len(chain))
+		for _, c := range chain {
+			ids = append(ids, c.ID)
+		}
+		proof := crypto.Proof{
+			Type:               "TestSignature2020",
+			ProofPurpose:       string(CapabilityDelegation),
+			VerificationMethod: parent.Controller + "#key-1",
+		}
+		proof.SetProperty("capabilityChain", ids)
+		capability.SetProof(&proof)
+		return *capability
+	}
+
+	root, err := NewRootCapability("did:example:alice", "https://storage.example.com/alice")
+	assert.NoError(t, err)
+	root.AllowedAction = []string{"read", "write"}
+	target := CapabilityTarget{ID: root.InvocationTarget, Controller: root.Controller}
+
+	t.Run("bad root", func(tt *testing.T) {
+		_, err := NewRootCapability("", "https://storage.example.com/alice")
+		assert.Error(tt, err)
+
+		err = VerifyCapabilityChain(suite, resolveVerifier, nil, target)
+		assert.ErrorContains(tt, err, "capability chain cannot be empty")
+
+		child := delegate(tt, *root, []Capability{*root}, "did:example:bob")
+		err = VerifyCapabilityChain(suite, resolveVerifier, []Capability{child}, target)
+		assert.ErrorContains(tt, err, "must be a root capability")
+	})
+
+	t.Run("root of another target", func(tt *testing.T) {
+		bob := delegate(tt, *root, []Capability{*root}, "did:example:bob")
+		err := VerifyCapabilityChain(suite, resolveVerifier, []Capability{*root, bob}, CapabilityTarget{ID: "https://storage.example.com/bob", Controller: "did:example:alice"})
+		assert.ErrorContains(tt, err, "is not the root capability of invocation target")
+
+		other, err := NewRootCapability("did:example:mallory", root.InvocationTarget)
+		assert.NoError(tt, err)
+		err = VerifyCapabilityChain(suite, resolveVerifier, []Capability{*other}, target)
+		assert.ErrorContains(tt, err, "is not controlled by the controller of its invocation target")
+
+		forged := *root
+		forged.ID = RootCapabilityPrefix + "other"
+		err = VerifyCapabilityChain(suite, resolveVerifier, []Capability{forged}, target)
+		assert.ErrorContains(tt, err, "is not the root capability of invocation target")
+	})
+
+	t.Run("valid chain", func(tt *testing.T) {
+		bob := delegate(tt, *root, []Capability{*root}, "did:example:bob", "read")
+		carol := delegate(tt, bob, []Capability{*root, bob}, "did:example:carol")
+		assert.Equal(tt, []string{"read"}, carol.AllowedAction)
+
+		err := VerifyCapabilityChain(suite, resolveVerifier, []Capability{*root, bob, carol}, target)
+		assert.NoError(tt, err)
+		assert.True(tt, suite.verified)
+	})
+
+	t.Run("actions cannot be amplified", func(tt *testing.T) {
+		bob := delegate(tt, *root, []Capability{*root}, "did:example:bob", "read")
+		_, err := DelegateCapability(bob, "did:example:carol", "write")
+		assert.ErrorContains(tt, err, "exceed those of parent capability")
+
+		carol := delegate(tt, bob, []Capability{*root, bob}, "did:example:carol")
+		carol.AllowedAction = []string{"read", "write"}
+		err = VerifyCapabilityChain(suite, resolveVerifier, []Capability{*root, bob, carol}, target)
+		assert.ErrorContains(tt, err, "exceed those of its parent")
+	})
+
+	t.Run("target can only be narrowed", func(tt *testing.T) {
+		bob := delegate(tt, *root, []Capability{*root}, "did:example:bob")
+		bob.InvocationTarget = root.InvocationTarget + "/documents"
+		err := VerifyCapabilityChain(suite, resolveVerifier, []Capability{*root, bob}, target)
+		assert.NoError(tt, err)
+
+		bob.InvocationTarget = "https://storage.example.com/alice-other"
+		err = VerifyCapabilityChain(suite, resolveVerifier, []Capability{*root, bob}, target)
+		assert.ErrorContains(tt, err, "is not permitted by its parent")
+	})
+
+	t.Run("expiry", func(tt *testing.T) {
+		bob := delegate(tt, *root, []Capability{*root}, "did:example:bob")
+		bob.Expires = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		err := VerifyCapabilityChain(suite, resolveVerifier, []Capability{*root, bob}, target)
+		assert.ErrorContains(tt, err, "expired at")
+
+		bob.Expires = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		carol := delegate(tt, bob, []Capability{*root, bob}, "did:example:carol")
+		carol.Expires = time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339)
+		err = VerifyCapabilityChain(suite, resolveVerifier, []Capability{*root, bob, carol}, target)
+		assert.ErrorContains(tt, err, "must not outlive its parent")
+
+		carol.Expires = "tomorrow"
+		err = VerifyCapabilityChain(suite, resolveVerifier, []Capability{*root, bob, carol}, target)
+		assert.ErrorContains(tt, err, "parsing expiry of capability")
+	})
+
+	t.Run("proof must reference the chain", func(tt *testing.T) {
+		bob := delegate(tt, *root, []Capability{*root}, "did:example:bob")
+		carol := delegate(tt, bob, []Capability{bob}, "did:example:carol")
+		err := VerifyCapabilityChain(suite, resolveVerifier, []Capability{*root, bob, carol}, target)
+		assert.ErrorContains(tt, err, "checking capability chain")
+
+		carol = delegate(tt, bob, []Capability{*root, bob}, "did:example:carol")
+		carol.ParentCapability = root.ID
+		err = VerifyCapabilityChain(suite, resolveVerifier, []Capability{*root, bob, carol}, target)
+		assert.ErrorContains(tt, err, "expected<"+bob.ID+">")
+	})
+
+	t.Run("invocation", func(tt *testing.T) {
+		bob := delegate(tt, *root, []Capability{*root}, "did:example:bob", "read")
+		chain := []Capability{*root, bob}
+		proof := crypto.Proof{
+			Type:               "TestSignature2020",
+			ProofPurpose:       string(CapabilityInvocation),
+			VerificationMethod: "did:example:bob#key-1",
+		}
+		proof.SetProperty("capability", bob.ID)
+		proof.SetProperty("capabilityAction", "read")
+		proof.SetProperty("invocationTarget", root.InvocationTarget+"/documents/1")
+		request := GenericProvable{"proof": proof}
+		err := VerifyCapabilityInvocation(suite, resolveVerifier, &request, chain, target)
+		assert.NoError(tt, err)
+
+		err = VerifyCapabilityInvocation(suite, resolveVerifier, &request, []Capability{*root}, target)
+		assert.ErrorContains(tt, err, "invocation proof references capability")
+
+		proof.ProofPurpose = string(AssertionMethod)
+		request.SetProof(&proof)
+		err = VerifyCapabilityInvocation(suite, resolveVerifier, &request, chain, target)
+		assert.ErrorContains(tt, err, "invocation proof must have purpose capabilityInvocation")
+	})
+}
@@ -35,6 +35,8 @@ type Document struct {
 	CapabilityInvocation []VerificationMethodSet `json:"capabilityInvocation,omitempty" validate:"dive"`
 	CapabilityDelegation []VerificationMethodSet `json:"capabilityDelegation,omitempty" validate:"dive"`
 	Services             []Service               `json:"service,omitempty" validate:"dive"`
+	// Proof is an optional Data Integrity proof over the document, made by the DID subject or one of its controllers
+	Proof *crypto.Proof `json:"proof,omitempty"`
 }
 
 type VerificationMethod struct {
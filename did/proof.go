@@ -0,0 +1,183 @@
+package did
+
+import (
+	gocrypto "crypto"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/rsa2018"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// ControllerResolver returns the DID Document of a controller of a DID Document, which is needed to verify proofs
+// made by a controller's verification method
+type ControllerResolver func(id string) (*Document, error)
+
+// GetProof returns the Data Integrity proof of the document, if present
+func (d *Document) GetProof() *crypto.Proof {
+	if d == nil {
+		return nil
+	}
+	return d.Proof
+}
+
+// SetProof sets the Data Integrity proof of the document
+func (d *Document) SetProof(p *crypto.Proof) {
+	if d == nil {
+		return
+	}
+	d.Proof = p
+}
+
+// SignDocument adds a Data Integrity proof to a DID Document, replacing any existing proof. The signer's key ID
+// should reference a verification method of the document, or of one of its controllers, which is authorized for the
+// signer's proof purpose. As with any JSON-LD document, the proof only covers properties defined by the document's
+// contexts, so identifiers such as service IDs should be absolute rather than relative (e.g. `#service-1`).
+func SignDocument(doc *Document, suite cryptosuite.CryptoSuite, signer cryptosuite.Signer, opts ...cryptosuite.Option) error {
+	if doc.IsEmpty() {
+		return errors.New("did doc cannot be empty")
+	}
+	if suite == nil || signer == nil {
+		return errors.New("suite and signer are required to sign a did doc")
+	}
+	doc.SetProof(nil)
+	if err := suite.Sign(signer, doc, opts...); err != nil {
+		return errors.Wrapf(err, "signing did doc<%s>", doc.ID)
+	}
+	return nil
+}
+
+// VerifyDocumentProof verifies the Data Integrity proof of a DID Document. The proof may be made by a verification
+// method of the document itself, or of one of the document's controllers, whose document is obtained using
+// resolveController. In both cases the verification method must be authorized for the proof's purpose by the
+// document defining it.
+func VerifyDocumentProof(doc Document, resolveController ControllerResolver, opts ...cryptosuite.Option) error {
+	proof := doc.GetProof()
+	if proof == nil {
+		return fmt.Errorf("did doc<%s> does not have a proof", doc.ID)
+	}
+	verificationMethodID := proof.VerificationMethod
+	if verificationMethodID == "" {
+		return errors.New("proof does not have a verification method")
+	}
+
+	signingDoc := &doc
+	signerDID, _, _ := strings.Cut(verificationMethodID, "#")
+	if signerDID != "" && signerDID != doc.ID {
+		if !isController(doc, signerDID) {
+			return fmt.Errorf("verification method<%s> does not belong to did<%s> or one of its controllers", verificationMethodID, doc.ID)
+		}
+		if resolveController == nil {
+			return fmt.Errorf("cannot resolve controller<%s> of did<%s>", signerDID, doc.ID)
+		}
+		controllerDoc, err := resolveController(signerDID)
+		if err != nil {
+			return errors.Wrapf(err, "resolving controller<%s> of did<%s>", signerDID, doc.ID)
+		}
+		signingDoc = controllerDoc
+	}
+
+	method, err := getAuthorizedVerificationMethod(*signingDoc, PublicKeyPurpose(proof.ProofPurpose), verificationMethodID)
+	if err != nil {
+		return err
+	}
+	pubKey, err := extractKeyFromVerificationMethod(*method)
+	if err != nil {
+		return errors.Wrapf(err, "getting key for verification method<%s>", verificationMethodID)
+	}
+	pubKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(&verificationMethodID, pubKey)
+	if err != nil {
+		return errors.Wrapf(err, "converting key for verification method<%s>", verificationMethodID)
+	}
+	verifier, err := jws2020.NewJSONWebKeyVerifier(verificationMethodID, *pubKeyJWK)
+	if err != nil {
+		return errors.Wrapf(err, "constructing verifier for verification method<%s>", verificationMethodID)
+	}
+	registry, err := cryptosuite.NewRegistry(jws2020.GetJSONWebSignature2020Suite(), rsa2018.GetRSASignature2018Suite())
+	if err != nil {
+		return errors.Wrap(err, "constructing suite registry")
+	}
+	if err = registry.Verify(verifier, &doc, opts...); err != nil {
+		return errors.Wrapf(err, "verifying proof of did doc<%s>", doc.ID)
+	}
+	return nil
+}
+
+// isController returns whether the given DID is listed as a controller of the document
+func isController(doc Document, id string) bool {
+	if doc.Controller == nil {
+		return false
+	}
+	controllers, err := util.InterfaceToStrings(doc.Controller)
+	if err != nil {
+		return false
+	}
+	return util.Contains(id, controllers)
+}
+
+// GetAuthorizedKey returns the key of the verification method with the given ID if the document authorizes it for
+// the given purpose, such as the assertion methods which may sign the credentials the DID issues
+func GetAuthorizedKey(doc Document, purpose PublicKeyPurpose, kid string) (gocrypto.PublicKey, error) {
+	method, err := getAuthorizedVerificationMethod(doc, purpose, kid)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := extractKeyFromVerificationMethod(*method)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting key for verification method<%s>", kid)
+	}
+	return pubKey, nil
+}
+
+// getAuthorizedVerificationMethod returns the verification method with the given ID if the document authorizes it for
+// the given purpose, whether by reference or embedded in the verification relationship
+func getAuthorizedVerificationMethod(doc Document, purpose PublicKeyPurpose, kid string) (*VerificationMethod, error) {
+	var relationship []VerificationMethodSet
+	switch purpose {
+	case AssertionMethod:
+		relationship = doc.AssertionMethod
+	case Authentication:
+		relationship = doc.Authentication
+	case CapabilityInvocation:
+		relationship = doc.CapabilityInvocation
+	case CapabilityDelegation:
+		relationship = doc.CapabilityDelegation
+	default:
+		return nil, fmt.Errorf("unsupported proof purpose for a did doc proof: %s", purpose)
+	}
+
+	for _, entry := range relationship {
+		if reference, ok := entry.(string); ok {
+			if !matchesKIDConstruction(doc.ID, kid, reference) {
+				continue
+			}
+			for _, method := range doc.VerificationMethod {
+				if matchesKIDConstruction(doc.ID, kid, method.ID) {
+					return &method, nil
+				}
+			}
+			return nil, fmt.Errorf("did<%s> has no verification methods with kid: %s", doc.ID, kid)
+		}
+
+		// the verification method is embedded in the relationship
+		entryBytes, err := json.Marshal(entry)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling embedded verification method")
+		}
+		var method VerificationMethod
+		if err = json.Unmarshal(entryBytes, &method); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling embedded verification method")
+		}
+		if matchesKIDConstruction(doc.ID, kid, method.ID) {
+			return &method, nil
+		}
+	}
+	return nil, fmt.Errorf("verification method<%s> is not authorized for %s by did<%s>", kid, purpose, doc.ID)
+}
@@ -0,0 +1,104 @@
+package did
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+)
+
+func TestDocumentProof(t *testing.T) {
+	suite := jws2020.GetJSONWebSignature2020Suite()
+
+	t.Run("self-signed", func(tt *testing.T) {
+		doc, signer := getSignableTestDocument(tt, "did:example:123")
+		err := SignDocument(&doc, suite, signer)
+		assert.NoError(tt, err)
+		assert.NotNil(tt, doc.Proof)
+
+		err = VerifyDocumentProof(doc, nil)
+		assert.NoError(tt, err)
+
+		// re-signing replaces the existing proof
+		err = SignDocument(&doc, suite, signer)
+		assert.NoError(tt, err)
+		err = VerifyDocumentProof(doc, nil)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("tampered document", func(tt *testing.T) {
+		doc, signer := getSignableTestDocument(tt, "did:example:123")
+		err := SignDocument(&doc, suite, signer)
+		assert.NoError(tt, err)
+
+		doc.Services = []Service{{ID: "did:example:123#linked-domain", Type: "LinkedDomains", ServiceEndpoint: "https://evil.example.com"}}
+		err = VerifyDocumentProof(doc, nil)
+		assert.ErrorContains(tt, err, "verifying proof of did doc<did:example:123>")
+	})
+
+	t.Run("no proof", func(tt *testing.T) {
+		doc, _ := getSignableTestDocument(tt, "did:example:123")
+		err := VerifyDocumentProof(doc, nil)
+		assert.ErrorContains(tt, err, "does not have a proof")
+	})
+
+	t.Run("verification method not authorized for purpose", func(tt *testing.T) {
+		doc, signer := getSignableTestDocument(tt, "did:example:123")
+		signer.SetProofPurpose(cryptosuite.Authentication)
+		err := SignDocument(&doc, suite, signer)
+		assert.NoError(tt, err)
+
+		err = VerifyDocumentProof(doc, nil)
+		assert.ErrorContains(tt, err, "is not authorized for authentication by did<did:example:123>")
+	})
+
+	t.Run("controller-signed", func(tt *testing.T) {
+		controllerDoc, controllerSigner := getSignableTestDocument(tt, "did:example:controller")
+		doc, _ := getSignableTestDocument(tt, "did:example:123")
+		doc.Controller = "did:example:controller"
+		err := SignDocument(&doc, suite, controllerSigner)
+		assert.NoError(tt, err)
+
+		resolveController := func(id string) (*Document, error) {
+			assert.Equal(tt, "did:example:controller", id)
+			return &controllerDoc, nil
+		}
+		err = VerifyDocumentProof(doc, resolveController)
+		assert.NoError(tt, err)
+
+		err = VerifyDocumentProof(doc, nil)
+		assert.ErrorContains(tt, err, "cannot resolve controller<did:example:controller>")
+
+		// signed by a DID which is not a controller
+		doc.Controller = nil
+		err = VerifyDocumentProof(doc, resolveController)
+		assert.ErrorContains(tt, err, "does not belong to did<did:example:123> or one of its controllers")
+	})
+}
+
+func getSignableTestDocument(t *testing.T, id string) (Document, *jws2020.JSONWebKeySigner) {
+	jwk, err := jws2020.GenerateJSONWebKey2020(jws2020.OKP, jws2020.Ed25519)
+	require.NoError(t, err)
+	kid := id + "#key-1"
+	jwk.PrivateKeyJWK.KID = kid
+	jwk.PublicKeyJWK.KID = kid
+	signer, err := jws2020.NewJSONWebKeySigner(kid, jwk.PrivateKeyJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+	doc := Document{
+		Context: []any{KnownDIDContext, cryptosuite.JSONWebKey2020Context},
+		ID:      id,
+		VerificationMethod: []VerificationMethod{
+			{
+				ID:           kid,
+				Type:         cryptosuite.JSONWebKey2020Type,
+				Controller:   id,
+				PublicKeyJWK: &jwk.PublicKeyJWK,
+			},
+		},
+		AssertionMethod: []VerificationMethodSet{kid},
+	}
+	return doc, signer
+}
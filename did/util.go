@@ -60,6 +60,73 @@ func GetKeyFromVerificationMethod(did Document, kid string) (gocrypto.PublicKey,
 	return nil, errors.Errorf("did<%s> has no verification methods with kid: %s", did.ID, kid)
 }
 
+// GetKeyAgreementKey returns the fully qualified ID and public key of the first verification method of a DID's
+// keyAgreement verification relationship, whether referenced or embedded, for encrypting to the DID's controller
+func GetKeyAgreementKey(did Document) (string, gocrypto.PublicKey, error) {
+	if did.IsEmpty() {
+		return "", nil, errors.New("did doc cannot be empty")
+	}
+	if len(did.KeyAgreement) == 0 {
+		return "", nil, errors.Errorf("did<%s> has no key agreement verification methods", did.ID)
+	}
+	return getKeyAgreementKey(did, did.KeyAgreement[0])
+}
+
+// GetKeyAgreementKeys returns the public keys of every verification method of a DID's keyAgreement verification
+// relationship, by their fully qualified IDs, for encrypting to each of the keys of the DID's controller
+func GetKeyAgreementKeys(did Document) (map[string]gocrypto.PublicKey, error) {
+	if did.IsEmpty() {
+		return nil, errors.New("did doc cannot be empty")
+	}
+	if len(did.KeyAgreement) == 0 {
+		return nil, errors.Errorf("did<%s> has no key agreement verification methods", did.ID)
+	}
+	keys := make(map[string]gocrypto.PublicKey, len(did.KeyAgreement))
+	for _, entry := range did.KeyAgreement {
+		kid, pubKey, err := getKeyAgreementKey(did, entry)
+		if err != nil {
+			return nil, err
+		}
+		keys[kid] = pubKey
+	}
+	return keys, nil
+}
+
+// getKeyAgreementKey returns the fully qualified ID and public key of an entry of a DID's keyAgreement verification
+// relationship, which references a verification method of the DID or embeds one
+func getKeyAgreementKey(did Document, entry VerificationMethodSet) (string, gocrypto.PublicKey, error) {
+	var method *VerificationMethod
+	switch typedEntry := entry.(type) {
+	case string:
+		for _, vm := range did.VerificationMethod {
+			if matchesKIDConstruction(did.ID, typedEntry, vm.ID) {
+				method = &vm
+				break
+			}
+		}
+		if method == nil {
+			return "", nil, errors.Errorf("did<%s> has no verification methods with kid: %s", did.ID, typedEntry)
+		}
+	default:
+		// the verification method is embedded in the relationship
+		entryBytes, err := json.Marshal(typedEntry)
+		if err != nil {
+			return "", nil, errors.Wrap(err, "marshalling embedded verification method")
+		}
+		var embedded VerificationMethod
+		if err = json.Unmarshal(entryBytes, &embedded); err != nil {
+			return "", nil, errors.Wrap(err, "unmarshalling embedded verification method")
+		}
+		method = &embedded
+	}
+
+	pubKey, err := extractKeyFromVerificationMethod(*method)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "getting key agreement key of did<%s>", did.ID)
+	}
+	return FullyQualifiedVerificationMethodID(did.ID, method.ID), pubKey, nil
+}
+
 // matchesKIDConstruction checks if the targetID matches possible combinations of the did and kid
 func matchesKIDConstruction(did, kid, targetID string) bool {
 	maybeKID1 := kid                                // the kid == the kid
@@ -82,6 +149,16 @@ func matchesKIDConstruction(did, kid, targetID string) bool {
 
 func extractKeyFromVerificationMethod(method VerificationMethod) (gocrypto.PublicKey, error) {
 	switch {
+	case method.Type == cryptosuite.MultikeyType:
+		// the key type of a Multikey is identified by the multicodec prefix of the key
+		if method.PublicKeyMultibase == "" {
+			return nil, errors.New("multikey verification method must have a publicKeyMultibase")
+		}
+		pubKey, _, err := MultikeyToPublicKey(method.PublicKeyMultibase)
+		if err != nil {
+			return nil, errors.Wrap(err, "converting multikey")
+		}
+		return pubKey, nil
 	case method.PublicKeyMultibase != "":
 		pubKeyBytes, multiBaseErr := MultiBaseToPubKeyBytes(method.PublicKeyMultibase)
 		if multiBaseErr != nil {
@@ -298,6 +375,65 @@ func ConstructMultibaseVerificationMethod(id, controller string, pubKey []byte,
 	}, nil
 }
 
+// ConstructMultikeyVerificationMethod builds a DID verification method of type Multikey
+// https://www.w3.org/TR/controller-document/#multikey
+func ConstructMultikeyVerificationMethod(id, controller string, pubKey gocrypto.PublicKey, kt crypto.KeyType) (*VerificationMethod, error) {
+	publicKeyMultibase, err := PublicKeyToMultikey(pubKey, kt)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding multikey")
+	}
+	return &VerificationMethod{
+		ID:                 id,
+		Type:               cryptosuite.MultikeyType,
+		Controller:         controller,
+		PublicKeyMultibase: publicKeyMultibase,
+	}, nil
+}
+
+// PublicKeyToMultikey encodes a public key as a multicodec identified, base58-btc multibase value, as used by the
+// `publicKeyMultibase` property of a Multikey. Elliptic curve keys are encoded in compressed form.
+func PublicKeyToMultikey(pubKey gocrypto.PublicKey, kt crypto.KeyType) (string, error) {
+	multiCodec, err := KeyTypeToMultiCodec(kt)
+	if err != nil {
+		return "", err
+	}
+	var pubKeyBytes []byte
+	switch kt {
+	case crypto.P256, crypto.P384, crypto.P521:
+		pubKeyBytes, err = crypto.PubKeyToBytes(pubKey, crypto.ECDSAMarshalCompressed)
+	default:
+		pubKeyBytes, err = crypto.PubKeyToBytes(pubKey)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "converting public key to bytes")
+	}
+	prefix := varint.ToUvarint(uint64(multiCodec))
+	encoded, err := multibase.Encode(Base58BTCMultiBase, append(prefix, pubKeyBytes...))
+	if err != nil {
+		return "", errors.Wrap(err, "multibase encoding")
+	}
+	return encoded, nil
+}
+
+// MultikeyToPublicKey decodes the `publicKeyMultibase` value of a Multikey into a public key and its key type
+func MultikeyToPublicKey(publicKeyMultibase string) (gocrypto.PublicKey, crypto.KeyType, error) {
+	pubKeyBytes, _, kt, err := DecodeMultibaseEncodedKey(publicKeyMultibase)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "decoding multikey")
+	}
+	var pubKey gocrypto.PublicKey
+	switch kt {
+	case crypto.P256, crypto.P384, crypto.P521:
+		pubKey, err = crypto.BytesToPubKey(pubKeyBytes, kt, crypto.ECDSAUnmarshalCompressed)
+	default:
+		pubKey, err = crypto.BytesToPubKey(pubKeyBytes, kt)
+	}
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "converting bytes to %s public key", kt)
+	}
+	return pubKey, kt, nil
+}
+
 // FullyQualifiedVerificationMethodID returns a fully qualified URL for a verification method.
 func FullyQualifiedVerificationMethodID(did, verificationMethodID string) string {
 	if strings.HasPrefix(verificationMethodID, "did:") {
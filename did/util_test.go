@@ -1,6 +1,8 @@
 package did
 
 import (
+	gocrypto "crypto"
+	"strings"
 	"testing"
 
 	"github.com/mr-tron/base58"
@@ -8,6 +10,7 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 )
 
 func TestGetKeyFromVerificationInformation(t *testing.T) {
@@ -286,6 +289,77 @@ func TestGetKeyFromVerificationInformation(t *testing.T) {
 	})
 }
 
+func TestGetKeyAgreementKey(t *testing.T) {
+	pubKey, _, err := crypto.GenerateX25519Key()
+	assert.NoError(t, err)
+	pubKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(nil, pubKey)
+	assert.NoError(t, err)
+	method := VerificationMethod{
+		ID:           "#key-agreement",
+		Type:         cryptosuite.JSONWebKey2020Type,
+		Controller:   "test-did",
+		PublicKeyJWK: pubKeyJWK,
+	}
+
+	t.Run("referenced key agreement key", func(tt *testing.T) {
+		doc := Document{
+			ID:                 "test-did",
+			VerificationMethod: []VerificationMethod{method},
+			KeyAgreement:       []VerificationMethodSet{"#key-agreement"},
+		}
+		kid, key, err := GetKeyAgreementKey(doc)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "test-did#key-agreement", kid)
+		assert.Equal(tt, pubKey, key)
+	})
+
+	t.Run("embedded key agreement key", func(tt *testing.T) {
+		doc := Document{
+			ID:           "test-did",
+			KeyAgreement: []VerificationMethodSet{method},
+		}
+		kid, key, err := GetKeyAgreementKey(doc)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "test-did#key-agreement", kid)
+		assert.Equal(tt, pubKey, key)
+	})
+
+	t.Run("no key agreement keys", func(tt *testing.T) {
+		_, _, err := GetKeyAgreementKey(Document{ID: "test-did", VerificationMethod: []VerificationMethod{method}})
+		assert.ErrorContains(tt, err, "has no key agreement verification methods")
+		_, err = GetKeyAgreementKeys(Document{ID: "test-did", VerificationMethod: []VerificationMethod{method}})
+		assert.ErrorContains(tt, err, "has no key agreement verification methods")
+	})
+
+	t.Run("every key agreement key", func(tt *testing.T) {
+		otherKey, _, err := crypto.GenerateX25519Key()
+		assert.NoError(tt, err)
+		otherKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(nil, otherKey)
+		assert.NoError(tt, err)
+		embedded := VerificationMethod{
+			ID:           "#other-key-agreement",
+			Type:         cryptosuite.JSONWebKey2020Type,
+			Controller:   "test-did",
+			PublicKeyJWK: otherKeyJWK,
+		}
+		doc := Document{
+			ID:                 "test-did",
+			VerificationMethod: []VerificationMethod{method},
+			KeyAgreement:       []VerificationMethodSet{"#key-agreement", embedded},
+		}
+		keys, err := GetKeyAgreementKeys(doc)
+		assert.NoError(tt, err)
+		assert.Equal(tt, map[string]gocrypto.PublicKey{
+			"test-did#key-agreement":       pubKey,
+			"test-did#other-key-agreement": otherKey,
+		}, keys)
+
+		doc.KeyAgreement = append(doc.KeyAgreement, "#missing")
+		_, err = GetKeyAgreementKeys(doc)
+		assert.ErrorContains(tt, err, "has no verification methods with kid: #missing")
+	})
+}
+
 func TestFullyQualifiedVerificationMethodID(t *testing.T) {
 	type args struct {
 		did                  string
@@ -327,3 +401,47 @@ func TestFullyQualifiedVerificationMethodID(t *testing.T) {
 		})
 	}
 }
+
+func TestMultikeyVerificationMethod(t *testing.T) {
+	for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.SECP256k1, crypto.P256, crypto.P384, crypto.P521, crypto.RSA} {
+		t.Run(kt.String(), func(tt *testing.T) {
+			pubKey, _, err := crypto.GenerateKeyByKeyType(kt)
+			assert.NoError(tt, err)
+
+			method, err := ConstructMultikeyVerificationMethod("did:example:123#key-1", "did:example:123", pubKey, kt)
+			assert.NoError(tt, err)
+			assert.Equal(tt, cryptosuite.MultikeyType, method.Type)
+			assert.True(tt, strings.HasPrefix(method.PublicKeyMultibase, "z"))
+
+			doc := Document{ID: "did:example:123", VerificationMethod: []VerificationMethod{*method}}
+			gotKey, err := GetKeyFromVerificationMethod(doc, "did:example:123#key-1")
+			assert.NoError(tt, err)
+
+			expectedBytes, err := crypto.PubKeyToBytes(pubKey)
+			assert.NoError(tt, err)
+			gotBytes, err := crypto.PubKeyToBytes(gotKey)
+			assert.NoError(tt, err)
+			assert.Equal(tt, expectedBytes, gotBytes)
+
+			_, gotKeyType, err := MultikeyToPublicKey(method.PublicKeyMultibase)
+			assert.NoError(tt, err)
+			assert.Equal(tt, kt, gotKeyType)
+		})
+	}
+
+	t.Run("multikey without a multibase key", func(tt *testing.T) {
+		doc := Document{
+			ID: "did:example:123",
+			VerificationMethod: []VerificationMethod{
+				{ID: "#key-1", Type: cryptosuite.MultikeyType, Controller: "did:example:123", PublicKeyBase58: "abc"},
+			},
+		}
+		_, err := GetKeyFromVerificationMethod(doc, "key-1")
+		assert.ErrorContains(tt, err, "multikey verification method must have a publicKeyMultibase")
+	})
+
+	t.Run("not base58-btc encoded", func(tt *testing.T) {
+		_, _, err := MultikeyToPublicKey("mO0AB")
+		assert.ErrorContains(tt, err, "decoding multikey")
+	})
+}
@@ -11,7 +11,18 @@ import (
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
 )
 
-type Resolver struct{}
+// Resolver resolves did:web DIDs. Since the document is served by a web server, which provides no integrity for the
+// document beyond that of the transport, the resolver can verify the Data Integrity proof of a document, when it
+// requires or opts into verifying one. Otherwise documents are returned as served, with any proof unverified.
+type Resolver struct {
+	// RequireProof rejects documents which do not carry a proof, and verifies the proofs of those which do
+	RequireProof bool
+	// VerifyProof verifies the proofs of documents which carry one, without rejecting those which do not
+	VerifyProof bool
+	// ControllerResolver resolves the controllers of documents signed by a controller. When nil, only documents signed
+	// by one of their own verification methods can be verified.
+	ControllerResolver resolution.Resolver
+}
 
 var _ resolution.Resolver = (*Resolver)(nil)
 
@@ -21,7 +32,7 @@ func (Resolver) Methods() []did.Method {
 
 // Resolve fetches and returns the Document from the expected URL
 // specification: https://w3c-ccg.github.io/did-method-web/#read-resolve
-func (Resolver) Resolve(ctx context.Context, id string, _ ...resolution.Option) (*resolution.Result, error) {
+func (r Resolver) Resolve(ctx context.Context, id string, _ ...resolution.Option) (*resolution.Result, error) {
 	if !strings.HasPrefix(id, Prefix) {
 		return nil, fmt.Errorf("not a did:web DID: %s", id)
 	}
@@ -30,5 +41,23 @@ func (Resolver) Resolve(ctx context.Context, id string, _ ...resolution.Option)
 	if err != nil {
 		return nil, errors.Wrapf(err, "resolving did:web DID: %s", id)
 	}
+	if r.RequireProof || (r.VerifyProof && doc.Proof != nil) {
+		if err = did.VerifyDocumentProof(*doc, r.resolveController(ctx)); err != nil {
+			return nil, errors.Wrapf(err, "verifying proof of did:web DID: %s", id)
+		}
+	}
 	return &resolution.Result{Document: *doc}, nil
 }
+
+func (r Resolver) resolveController(ctx context.Context) did.ControllerResolver {
+	if r.ControllerResolver == nil {
+		return nil
+	}
+	return func(id string) (*did.Document, error) {
+		resolved, err := r.ControllerResolver.Resolve(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &resolved.Document, nil
+	}
+}
@@ -7,8 +7,12 @@ import (
 	"gopkg.in/h2non/gock.v1"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/did"
 )
 
 const (
@@ -162,3 +166,79 @@ func TestDIDWebValidate(t *testing.T) {
 		assert.ErrorContains(tt, err, "did:web: is missing the required domain")
 	})
 }
+
+func TestResolverDocumentProof(t *testing.T) {
+	jwk, err := jws2020.GenerateJSONWebKey2020(jws2020.OKP, jws2020.Ed25519)
+	require.NoError(t, err)
+	kid := string(didWebToBeResolved) + "#key-1"
+	jwk.PrivateKeyJWK.KID = kid
+	jwk.PublicKeyJWK.KID = kid
+	signer, err := jws2020.NewJSONWebKeySigner(kid, jwk.PrivateKeyJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+	newDoc := func() did.Document {
+		return did.Document{
+			Context: []any{did.KnownDIDContext, cryptosuite.JSONWebKey2020Context},
+			ID:      string(didWebToBeResolved),
+			VerificationMethod: []did.VerificationMethod{
+				{ID: kid, Type: cryptosuite.JSONWebKey2020Type, Controller: string(didWebToBeResolved), PublicKeyJWK: &jwk.PublicKeyJWK},
+			},
+			AssertionMethod: []did.VerificationMethodSet{kid},
+		}
+	}
+	serve := func(doc did.Document) {
+		gock.New("https://demo.ssi-sdk.com").
+			Get("/.well-known/did.json").
+			Reply(200).
+			JSON(doc)
+	}
+
+	t.Run("verifies signed documents", func(tt *testing.T) {
+		doc := newDoc()
+		require.NoError(tt, did.SignDocument(&doc, jws2020.GetJSONWebSignature2020Suite(), signer))
+		serve(doc)
+		defer gock.Off()
+
+		result, err := Resolver{RequireProof: true}.Resolve(context.Background(), string(didWebToBeResolved))
+		assert.NoError(tt, err)
+		assert.NotNil(tt, result.Document.Proof)
+	})
+
+	t.Run("rejects tampered documents", func(tt *testing.T) {
+		doc := newDoc()
+		require.NoError(tt, did.SignDocument(&doc, jws2020.GetJSONWebSignature2020Suite(), signer))
+		doc.AlsoKnownAs = "https://evil.example.com"
+		serve(doc)
+		defer gock.Off()
+
+		_, err := Resolver{VerifyProof: true}.Resolve(context.Background(), string(didWebToBeResolved))
+		assert.ErrorContains(tt, err, "verifying proof of did:web DID")
+	})
+
+	t.Run("does not verify proofs unless asked to", func(tt *testing.T) {
+		doc := newDoc()
+		doc.Proof = &crypto.Proof{Type: "UnsupportedSignature2024", ProofPurpose: "assertionMethod"}
+		serve(doc)
+		defer gock.Off()
+
+		result, err := Resolver{}.Resolve(context.Background(), string(didWebToBeResolved))
+		assert.NoError(tt, err)
+		assert.NotNil(tt, result.Document.Proof)
+	})
+
+	t.Run("documents without proofs may be verified", func(tt *testing.T) {
+		serve(newDoc())
+		defer gock.Off()
+
+		result, err := Resolver{VerifyProof: true}.Resolve(context.Background(), string(didWebToBeResolved))
+		assert.NoError(tt, err)
+		assert.Nil(tt, result.Document.Proof)
+	})
+
+	t.Run("requires a proof", func(tt *testing.T) {
+		serve(newDoc())
+		defer gock.Off()
+
+		_, err := Resolver{RequireProof: true}.Resolve(context.Background(), string(didWebToBeResolved))
+		assert.ErrorContains(tt, err, "does not have a proof")
+	})
+}
@@ -0,0 +1,128 @@
+package didcomm
+
+import (
+	"encoding/base64"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Attachment is content attached to a message, such as a credential or a presentation, passed by value, as base64 or
+// JSON, or by reference, as links to the content and its hash
+// https://identity.foundation/didcomm-messaging/spec/v2.1/#attachments
+type Attachment struct {
+	ID          string `json:"id,omitempty"`
+	Description string `json:"description,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	MediaType   string `json:"media_type,omitempty"`
+	// Format identifies the format of the attachment's content, beyond its media type, as protocols define
+	Format      string         `json:"format,omitempty"`
+	LastModTime int64          `json:"lastmod_time,omitempty"`
+	ByteCount   int64          `json:"byte_count,omitempty"`
+	Data        AttachmentData `json:"data"`
+}
+
+// AttachmentData is the content of an attachment, in exactly one of base64, json or links
+type AttachmentData struct {
+	// JWS is a detached JWS over the content
+	JWS any `json:"jws,omitempty"`
+	// Hash is the multihash of the content, which links must have
+	Hash   string   `json:"hash,omitempty"`
+	Links  []string `json:"links,omitempty"`
+	Base64 string   `json:"base64,omitempty"`
+	JSON   any      `json:"json,omitempty"`
+}
+
+// NewJSONAttachment returns an attachment of a format, with a random ID, whose content is v as JSON
+func NewJSONAttachment(format string, v any) (*Attachment, error) {
+	if v == nil {
+		return nil, errors.New("attachment content cannot be empty")
+	}
+	return &Attachment{
+		ID:        uuid.NewString(),
+		MediaType: "application/json",
+		Format:    format,
+		Data:      AttachmentData{JSON: v},
+	}, nil
+}
+
+// NewBase64Attachment returns an attachment of a format and media type, with a random ID, whose content is the
+// given bytes, as base64
+func NewBase64Attachment(format, mediaType string, content []byte) (*Attachment, error) {
+	if len(content) == 0 {
+		return nil, errors.New("attachment content cannot be empty")
+	}
+	return &Attachment{
+		ID:        uuid.NewString(),
+		MediaType: mediaType,
+		Format:    format,
+		ByteCount: int64(len(content)),
+		Data:      AttachmentData{Base64: base64.StdEncoding.EncodeToString(content)},
+	}, nil
+}
+
+// IsValid checks the attachment's data has exactly one of base64, json or links, and that links have a hash
+func (a Attachment) IsValid() error {
+	kinds := 0
+	if a.Data.Base64 != "" {
+		kinds++
+	}
+	if a.Data.JSON != nil {
+		kinds++
+	}
+	if len(a.Data.Links) > 0 {
+		kinds++
+		if a.Data.Hash == "" {
+			return errors.New("attachment links must have a hash")
+		}
+	}
+	if kinds != 1 {
+		return errors.New("attachment data must have exactly one of base64, json or links")
+	}
+	return nil
+}
+
+// Bytes returns the content of an attachment passed by value, which is JSON encoded if it is passed as JSON
+func (a Attachment) Bytes() ([]byte, error) {
+	switch {
+	case a.Data.Base64 != "":
+		// senders may pad base64, or not
+		content, err := base64.StdEncoding.DecodeString(a.Data.Base64)
+		if err != nil {
+			if content, err = base64.RawStdEncoding.DecodeString(a.Data.Base64); err != nil {
+				return nil, errors.Wrap(err, "decoding base64 attachment")
+			}
+		}
+		return content, nil
+	case a.Data.JSON != nil:
+		content, err := json.Marshal(a.Data.JSON)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling json attachment")
+		}
+		return content, nil
+	}
+	return nil, errors.Errorf("attachment<%s> is not passed by value", a.ID)
+}
+
+// GetJSON unmarshals the JSON content of an attachment passed by value into v
+func (a Attachment) GetJSON(v any) error {
+	content, err := a.Bytes()
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(content, v); err != nil {
+		return errors.Wrapf(err, "unmarshalling attachment<%s>", a.ID)
+	}
+	return nil
+}
+
+// GetAttachment returns the message's attachment of an ID
+func (m Message) GetAttachment(id string) (*Attachment, error) {
+	for _, attachment := range m.Attachments {
+		if attachment.ID == id {
+			return &attachment, nil
+		}
+	}
+	return nil, errors.Errorf("message<%s> has no attachment<%s>", m.ID, id)
+}
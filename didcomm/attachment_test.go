@@ -0,0 +1,67 @@
+package didcomm
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachment(t *testing.T) {
+	t.Run("JSON attachments", func(tt *testing.T) {
+		attachment, err := NewJSONAttachment("dif/presentation-exchange/definitions@v1.0", map[string]any{"id": "definition"})
+		require.NoError(tt, err)
+		require.NoError(tt, attachment.IsValid())
+
+		message, err := NewMessage("https://example.com/protocols/present/1.0/request", nil)
+		require.NoError(tt, err)
+		message.Attachments = []Attachment{*attachment}
+		messageBytes, err := json.Marshal(message)
+		require.NoError(tt, err)
+		parsed, err := ParseMessage(messageBytes)
+		require.NoError(tt, err)
+
+		received, err := parsed.GetAttachment(attachment.ID)
+		require.NoError(tt, err)
+		assert.Equal(tt, "dif/presentation-exchange/definitions@v1.0", received.Format)
+		var definition struct {
+			ID string `json:"id"`
+		}
+		require.NoError(tt, received.GetJSON(&definition))
+		assert.Equal(tt, "definition", definition.ID)
+
+		_, err = parsed.GetAttachment("other")
+		assert.ErrorContains(tt, err, "has no attachment<other>")
+		_, err = NewJSONAttachment("", nil)
+		assert.ErrorContains(tt, err, "attachment content cannot be empty")
+	})
+
+	t.Run("base64 attachments", func(tt *testing.T) {
+		attachment, err := NewBase64Attachment("", "text/plain", []byte("hello"))
+		require.NoError(tt, err)
+		require.NoError(tt, attachment.IsValid())
+		assert.Equal(tt, int64(5), attachment.ByteCount)
+		content, err := attachment.Bytes()
+		require.NoError(tt, err)
+		assert.Equal(tt, "hello", string(content))
+
+		// unpadded base64 is decoded too
+		attachment.Data.Base64 = "aGVsbG8"
+		content, err = attachment.Bytes()
+		require.NoError(tt, err)
+		assert.Equal(tt, "hello", string(content))
+	})
+
+	t.Run("linked attachments", func(tt *testing.T) {
+		attachment := Attachment{ID: "linked", Data: AttachmentData{Links: []string{"https://example.com/content"}}}
+		assert.ErrorContains(tt, attachment.IsValid(), "attachment links must have a hash")
+		attachment.Data.Hash = "QmWtQu5rbKU6ziFGBYYMBNQkWvdrXmiNBb8Q3HmVfLz6ug"
+		assert.NoError(tt, attachment.IsValid())
+		_, err := attachment.Bytes()
+		assert.ErrorContains(tt, err, "attachment<linked> is not passed by value")
+
+		attachment.Data.Base64 = "aGVsbG8="
+		assert.ErrorContains(tt, attachment.IsValid(), "exactly one of base64, json or links")
+	})
+}
@@ -0,0 +1,167 @@
+package didcomm
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// The discover features protocol discovers which protocols, goal codes, and headers another agent supports, by
+// querying for them, to which the agent replies with the features matching the queries it chooses to disclose.
+// https://identity.foundation/didcomm-messaging/spec/v2.1/#discover-features-protocol-20
+
+const (
+	// DiscoverFeaturesProtocol is the URI of the discover features protocol, which its message types are in
+	DiscoverFeaturesProtocol string = "https://didcomm.org/discover-features/2.0"
+
+	QueriesType  string = DiscoverFeaturesProtocol + "/queries"
+	DiscloseType string = DiscoverFeaturesProtocol + "/disclose"
+)
+
+// FeatureType is the type of feature queried and disclosed
+type FeatureType string
+
+const (
+	ProtocolFeature FeatureType = "protocol"
+	GoalCodeFeature FeatureType = "goal-code"
+	HeaderFeature   FeatureType = "header"
+
+	// matchWildcard ends queries which match every feature ID it prefixes
+	matchWildcard string = "*"
+)
+
+// Query queries for the features of a type whose IDs match, which are equal to the match or, if it ends with a
+// wildcard, prefixed by it
+type Query struct {
+	FeatureType FeatureType `json:"feature-type" validate:"required"`
+	Match       string      `json:"match" validate:"required"`
+}
+
+// Feature is a feature an agent supports, and for protocols the roles it plays in them
+type Feature struct {
+	FeatureType FeatureType `json:"feature-type" validate:"required"`
+	ID          string      `json:"id" validate:"required"`
+	Roles       []string    `json:"roles,omitempty"`
+}
+
+// QueriesBody is the body of queries
+type QueriesBody struct {
+	Queries []Query `json:"queries"`
+}
+
+// DiscloseBody is the body of disclosures
+type DiscloseBody struct {
+	Disclosures []Feature `json:"disclosures"`
+}
+
+// Matches returns whether the query matches a feature
+func (q Query) Matches(feature Feature) bool {
+	if q.FeatureType != feature.FeatureType {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(q.Match, matchWildcard); ok {
+		return strings.HasPrefix(feature.ID, prefix)
+	}
+	return q.Match == feature.ID
+}
+
+// DiscoverFeatures queries for the features of other agents from an agent's DID, and discloses the agent's features
+// matching their queries, keeping the features other agents disclosed, by their DIDs
+type DiscoverFeatures struct {
+	did      string
+	features []Feature
+
+	mu        sync.Mutex
+	disclosed map[string][]Feature
+}
+
+var _ Handler = (*DiscoverFeatures)(nil)
+
+// NewDiscoverFeatures returns the discover features protocol of an agent's DID, which discloses the given features
+func NewDiscoverFeatures(did string, features ...Feature) (*DiscoverFeatures, error) {
+	if !isDID(did) {
+		return nil, errors.Errorf("agent<%s> must be a DID", did)
+	}
+	for _, feature := range features {
+		if feature.FeatureType == "" || feature.ID == "" {
+			return nil, errors.Errorf("feature<%s> must have a type and an ID", feature.ID)
+		}
+	}
+	return &DiscoverFeatures{did: did, features: features, disclosed: make(map[string][]Feature)}, nil
+}
+
+// ProtocolFeatures returns the features of protocols, such as those a Dispatcher handles
+func ProtocolFeatures(protocols ...string) []Feature {
+	features := make([]Feature, 0, len(protocols))
+	for _, protocol := range protocols {
+		features = append(features, Feature{FeatureType: ProtocolFeature, ID: protocol})
+	}
+	return features
+}
+
+// Query returns queries to another agent for its features
+func (df *DiscoverFeatures) Query(to string, queries ...Query) (*Message, error) {
+	if len(queries) == 0 {
+		return nil, errors.New("queries cannot be empty")
+	}
+	message, err := NewMessage(QueriesType, QueriesBody{Queries: queries})
+	if err != nil {
+		return nil, err
+	}
+	message.From = df.did
+	message.To = []string{to}
+	if err = message.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid queries")
+	}
+	return message, nil
+}
+
+// Handle discloses the agent's features matching queries, which may be none, and records the features other agents
+// disclose
+func (df *DiscoverFeatures) Handle(_ context.Context, message Message) (*Message, error) {
+	switch message.Type {
+	case QueriesType:
+		var body QueriesBody
+		if err := message.GetBody(&body); err != nil {
+			return nil, err
+		}
+		disclosures := make([]Feature, 0)
+		for _, feature := range df.features {
+			for _, query := range body.Queries {
+				if query.Matches(feature) {
+					disclosures = append(disclosures, feature)
+					break
+				}
+			}
+		}
+		disclose, err := message.Reply(DiscloseType, DiscloseBody{Disclosures: disclosures})
+		if err != nil {
+			return nil, err
+		}
+		disclose.From = df.did
+		return disclose, nil
+	case DiscloseType:
+		if message.From == "" {
+			return nil, errors.Errorf("disclosures<%s> have no sender", message.ID)
+		}
+		var body DiscloseBody
+		if err := message.GetBody(&body); err != nil {
+			return nil, err
+		}
+		df.mu.Lock()
+		df.disclosed[message.From] = body.Disclosures
+		df.mu.Unlock()
+		return nil, nil
+	}
+	return nil, errors.Errorf("message<%s> of type<%s> is not a discover features message", message.ID, message.Type)
+}
+
+// Disclosed returns the features another agent last disclosed, and whether it disclosed any
+func (df *DiscoverFeatures) Disclosed(did string) ([]Feature, bool) {
+	df.mu.Lock()
+	defer df.mu.Unlock()
+	features, ok := df.disclosed[did]
+	return features, ok
+}
@@ -0,0 +1,69 @@
+package didcomm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverFeatures(t *testing.T) {
+	ctx := context.Background()
+	alice, err := NewDiscoverFeatures("did:example:alice")
+	require.NoError(t, err)
+	bobFeatures := append(ProtocolFeatures(TrustPingProtocol, DiscoverFeaturesProtocol),
+		Feature{FeatureType: GoalCodeFeature, ID: "issue-vc"},
+		Feature{FeatureType: ProtocolFeature, ID: "https://didcomm.org/issue-credential/3.0", Roles: []string{"issuer"}},
+	)
+	bob, err := NewDiscoverFeatures("did:example:bob", bobFeatures...)
+	require.NoError(t, err)
+
+	t.Run("queries and disclosures", func(tt *testing.T) {
+		queries, err := alice.Query("did:example:bob",
+			Query{FeatureType: ProtocolFeature, Match: "https://didcomm.org/issue-credential/*"},
+			Query{FeatureType: GoalCodeFeature, Match: "issue-vc"},
+		)
+		require.NoError(tt, err)
+
+		disclose, err := bob.Handle(ctx, *queries)
+		require.NoError(tt, err)
+		require.NotNil(tt, disclose)
+		assert.Equal(tt, DiscloseType, disclose.Type)
+		assert.Equal(tt, queries.ID, disclose.ThreadID)
+		var body DiscloseBody
+		require.NoError(tt, disclose.GetBody(&body))
+		assert.Equal(tt, []Feature{bobFeatures[2], bobFeatures[3]}, body.Disclosures)
+
+		_, ok := alice.Disclosed("did:example:bob")
+		assert.False(tt, ok)
+		reply, err := alice.Handle(ctx, *disclose)
+		require.NoError(tt, err)
+		assert.Nil(tt, reply)
+		disclosed, ok := alice.Disclosed("did:example:bob")
+		assert.True(tt, ok)
+		assert.Equal(tt, body.Disclosures, disclosed)
+	})
+
+	t.Run("queries matching nothing", func(tt *testing.T) {
+		queries, err := alice.Query("did:example:bob", Query{FeatureType: HeaderFeature, Match: "*"})
+		require.NoError(tt, err)
+		disclose, err := bob.Handle(ctx, *queries)
+		require.NoError(tt, err)
+		var body DiscloseBody
+		require.NoError(tt, disclose.GetBody(&body))
+		assert.Empty(tt, body.Disclosures)
+
+		_, err = alice.Query("did:example:bob")
+		assert.ErrorContains(tt, err, "queries cannot be empty")
+	})
+
+	t.Run("query matching", func(tt *testing.T) {
+		feature := Feature{FeatureType: ProtocolFeature, ID: "https://didcomm.org/trust-ping/2.0"}
+		assert.True(tt, Query{FeatureType: ProtocolFeature, Match: "https://didcomm.org/trust-ping/2.0"}.Matches(feature))
+		assert.True(tt, Query{FeatureType: ProtocolFeature, Match: "https://didcomm.org/*"}.Matches(feature))
+		assert.True(tt, Query{FeatureType: ProtocolFeature, Match: "*"}.Matches(feature))
+		assert.False(tt, Query{FeatureType: ProtocolFeature, Match: "https://didcomm.org/trust-ping/1.0"}.Matches(feature))
+		assert.False(tt, Query{FeatureType: GoalCodeFeature, Match: "*"}.Matches(feature))
+	})
+}
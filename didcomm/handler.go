@@ -0,0 +1,94 @@
+package didcomm
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Handler handles the messages of a protocol an agent receives, once unpacked, returning the reply to send to the
+// message's sender, if any
+type Handler interface {
+	Handle(ctx context.Context, message Message) (*Message, error)
+}
+
+// HandlerFunc is a function which handles messages
+type HandlerFunc func(ctx context.Context, message Message) (*Message, error)
+
+func (f HandlerFunc) Handle(ctx context.Context, message Message) (*Message, error) {
+	return f(ctx, message)
+}
+
+// Dispatcher dispatches messages to the handlers of their types
+type Dispatcher struct {
+	handlers map[string]Handler
+}
+
+var _ Handler = (*Dispatcher)(nil)
+
+// NewDispatcher returns a dispatcher without handlers
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[string]Handler)}
+}
+
+// Register registers the handler of messages of the given types
+func (d *Dispatcher) Register(handler Handler, messageTypes ...string) error {
+	if handler == nil {
+		return errors.New("handler cannot be empty")
+	}
+	if len(messageTypes) == 0 {
+		return errors.New("handler must handle at least one message type")
+	}
+	for _, messageType := range messageTypes {
+		if _, ok := d.handlers[messageType]; ok {
+			return errors.Errorf("message type<%s> already has a handler", messageType)
+		}
+	}
+	for _, messageType := range messageTypes {
+		d.handlers[messageType] = handler
+	}
+	return nil
+}
+
+// Handle handles a message with the handler of its type, after checking it is valid and has not expired
+func (d *Dispatcher) Handle(ctx context.Context, message Message) (*Message, error) {
+	handler, ok := d.handlers[message.Type]
+	if !ok {
+		return nil, errors.Errorf("message<%s> of type<%s> has no handler", message.ID, message.Type)
+	}
+	if err := message.IsValid(); err != nil {
+		return nil, errors.Wrapf(err, "invalid message<%s>", message.ID)
+	}
+	if message.IsExpired(time.Now()) {
+		return nil, errors.Errorf("message<%s> has expired", message.ID)
+	}
+	return handler.Handle(ctx, message)
+}
+
+// Protocols returns the URIs of the protocols of the message types the dispatcher handles, sorted, such that they
+// can be disclosed with the discover features protocol
+func (d *Dispatcher) Protocols() []string {
+	seen := make(map[string]bool)
+	var protocols []string
+	for messageType := range d.handlers {
+		protocol := ProtocolOf(messageType)
+		if !seen[protocol] {
+			seen[protocol] = true
+			protocols = append(protocols, protocol)
+		}
+	}
+	sort.Strings(protocols)
+	return protocols
+}
+
+// ProtocolOf returns the URI of the protocol of a message type, which is the message type without its message name
+// https://identity.foundation/didcomm-messaging/spec/v2.1/#message-type-uri
+func ProtocolOf(messageType string) string {
+	if i := strings.LastIndex(messageType, "/"); i > 0 {
+		return messageType[:i]
+	}
+	return messageType
+}
@@ -0,0 +1,52 @@
+package didcomm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	trustPing, err := NewTrustPing("did:example:bob")
+	require.NoError(t, err)
+	dispatcher := NewDispatcher()
+	require.NoError(t, dispatcher.Register(trustPing, PingType, PingResponseType))
+
+	t.Run("dispatches messages to their handlers", func(tt *testing.T) {
+		ping, err := NewMessage(PingType, PingBody{ResponseRequested: true})
+		require.NoError(tt, err)
+		ping.From = "did:example:alice"
+		response, err := dispatcher.Handle(ctx, *ping)
+		require.NoError(tt, err)
+		require.NotNil(tt, response)
+		assert.Equal(tt, PingResponseType, response.Type)
+
+		ping.SetExpiresIn(-time.Minute)
+		ping.CreatedTime = 0
+		_, err = dispatcher.Handle(ctx, *ping)
+		assert.ErrorContains(tt, err, "has expired")
+
+		other, err := NewMessage("https://didcomm.org/basicmessage/2.0/message", nil)
+		require.NoError(tt, err)
+		_, err = dispatcher.Handle(ctx, *other)
+		assert.ErrorContains(tt, err, "has no handler")
+	})
+
+	t.Run("registration", func(tt *testing.T) {
+		err := dispatcher.Register(trustPing, PingType)
+		assert.ErrorContains(tt, err, "already has a handler")
+
+		handler := HandlerFunc(func(_ context.Context, _ Message) (*Message, error) { return nil, nil })
+		require.NoError(tt, dispatcher.Register(handler, QueriesType, DiscloseType))
+		assert.Equal(tt, []string{DiscoverFeaturesProtocol, TrustPingProtocol}, dispatcher.Protocols())
+	})
+
+	t.Run("protocols of message types", func(tt *testing.T) {
+		assert.Equal(tt, TrustPingProtocol, ProtocolOf(PingType))
+		assert.Equal(tt, "ping", ProtocolOf("ping"))
+	})
+}
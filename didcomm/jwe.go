@@ -0,0 +1,296 @@
+package didcomm
+
+import (
+	gocrypto "crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"reflect"
+
+	"github.com/lestrrat-go/jwx/v2/x25519"
+	"github.com/pkg/errors"
+)
+
+// Encrypted messages are JWEs in the general JSON serialization, whose content encryption key is wrapped with AES key
+// wrap for each recipient, by a key derived with the Concat KDF from the shared secret of ECDH-ES, or ECDH-1PU, which
+// also agrees on the sender's static key, authenticating it. The JWE primitives are implemented here, since those of
+// the jwx library neither support ECDH-1PU, nor share an ephemeral key between the recipients of a JWE.
+// https://www.rfc-editor.org/rfc/rfc7518.html#section-4.6
+// https://datatracker.ietf.org/doc/html/draft-madden-jose-ecdh-1pu-04
+
+const (
+	// cekSize is the size, in bytes, of the content encryption keys of A256CBC-HS512
+	cekSize = 64
+	// kekSize is the size, in bytes, of the key encryption keys of A256KW
+	kekSize = 32
+	// cbcIVSize is the size, in bytes, of the IVs of A256CBC-HS512
+	cbcIVSize = aes.BlockSize
+)
+
+// keyWrapIV is the default initial value of AES key wrap
+// https://www.rfc-editor.org/rfc/rfc3394.html#section-2.2.3.1
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// curveNames are the JWK names of the curves of supported key agreement keys
+var curveNames = map[ecdh.Curve]string{
+	ecdh.X25519(): "X25519",
+	ecdh.P256():   "P-256",
+	ecdh.P384():   "P-384",
+	ecdh.P521():   "P-521",
+}
+
+// ephemeralKey is the public JWK of the ephemeral key of a JWE, its epk header
+type ephemeralKey struct {
+	KTY string `json:"kty"`
+	CRV string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+}
+
+// newEphemeralKey returns the JWK of the public key of an ephemeral key agreement key
+func newEphemeralKey(key *ecdh.PrivateKey) (*ephemeralKey, error) {
+	curve, ok := curveNames[key.Curve()]
+	if !ok {
+		return nil, errors.New("unsupported ephemeral key curve")
+	}
+	publicKey := key.PublicKey().Bytes()
+	if key.Curve() == ecdh.X25519() {
+		return &ephemeralKey{KTY: "OKP", CRV: curve, X: base64.RawURLEncoding.EncodeToString(publicKey)}, nil
+	}
+	// NIST curve public keys are the uncompressed point 0x04 || X || Y
+	coordinates := publicKey[1:]
+	size := len(coordinates) / 2
+	return &ephemeralKey{
+		KTY: "EC",
+		CRV: curve,
+		X:   base64.RawURLEncoding.EncodeToString(coordinates[:size]),
+		Y:   base64.RawURLEncoding.EncodeToString(coordinates[size:]),
+	}, nil
+}
+
+// publicKey returns the public key of an ephemeral key's JWK, checking it is on its curve
+func (k ephemeralKey) publicKey() (*ecdh.PublicKey, error) {
+	var curve ecdh.Curve
+	for c, name := range curveNames {
+		if name == k.CRV {
+			curve = c
+		}
+	}
+	if curve == nil {
+		return nil, errors.Errorf("ephemeral key curve<%s> is not supported", k.CRV)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding ephemeral key x")
+	}
+	if curve == ecdh.X25519() {
+		if k.KTY != "OKP" {
+			return nil, errors.Errorf("ephemeral key type<%s> is not of curve<%s>", k.KTY, k.CRV)
+		}
+		return curve.NewPublicKey(x)
+	}
+	if k.KTY != "EC" {
+		return nil, errors.Errorf("ephemeral key type<%s> is not of curve<%s>", k.KTY, k.CRV)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding ephemeral key y")
+	}
+	if len(x) != len(y) {
+		return nil, errors.New("ephemeral key coordinates are not of the same size")
+	}
+	point := append(append([]byte{0x04}, x...), y...)
+	return curve.NewPublicKey(point)
+}
+
+// toECDHPublicKey converts a supported public key agreement key, X25519 or of a NIST curve, for ECDH
+func toECDHPublicKey(key gocrypto.PublicKey) (*ecdh.PublicKey, error) {
+	// dereference the ptr
+	if reflect.ValueOf(key).Kind() == reflect.Ptr {
+		key = reflect.ValueOf(key).Elem().Interface().(gocrypto.PublicKey)
+	}
+	switch k := key.(type) {
+	case x25519.PublicKey:
+		return ecdh.X25519().NewPublicKey(k)
+	case ecdsa.PublicKey:
+		return k.ECDH()
+	default:
+		return nil, errors.Errorf("unsupported key agreement key type: %T", key)
+	}
+}
+
+// toECDHPrivateKey converts a supported private key agreement key, X25519 or of a NIST curve, for ECDH
+func toECDHPrivateKey(key gocrypto.PrivateKey) (*ecdh.PrivateKey, error) {
+	// dereference the ptr
+	if reflect.ValueOf(key).Kind() == reflect.Ptr {
+		key = reflect.ValueOf(key).Elem().Interface().(gocrypto.PrivateKey)
+	}
+	switch k := key.(type) {
+	case x25519.PrivateKey:
+		return ecdh.X25519().NewPrivateKey(k.Seed())
+	case ecdsa.PrivateKey:
+		return k.ECDH()
+	default:
+		return nil, errors.Errorf("unsupported key agreement key type: %T", key)
+	}
+}
+
+// deriveKEK derives the key encryption key of a recipient from the shared secret z with the Concat KDF, whose other
+// info is that of the algorithm, the apu and apv, and the tag of the content encryption for ECDH-1PU, if it is given
+// https://www.rfc-editor.org/rfc/rfc7518.html#section-4.6.2
+func deriveKEK(z []byte, alg string, apu, apv, tag []byte) []byte {
+	otherInfo := lengthPrefixed([]byte(alg))
+	otherInfo = append(otherInfo, lengthPrefixed(apu)...)
+	otherInfo = append(otherInfo, lengthPrefixed(apv)...)
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, kekSize*8)
+	if tag != nil {
+		otherInfo = append(otherInfo, lengthPrefixed(tag)...)
+	}
+
+	// the key encryption key is a single round of the KDF, as it is the size of the hash
+	h := sha256.New()
+	h.Write([]byte{0, 0, 0, 1})
+	h.Write(z)
+	h.Write(otherInfo)
+	return h.Sum(nil)[:kekSize]
+}
+
+func lengthPrefixed(b []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...)
+}
+
+// wrapKey wraps a key with a key encryption key with AES key wrap
+// https://www.rfc-editor.org/rfc/rfc3394.html#section-2.2.1
+func wrapKey(kek, key []byte) ([]byte, error) {
+	if len(key)%8 != 0 || len(key) < 16 {
+		return nil, errors.New("key to wrap must be a multiple of 8 bytes, and at least 16")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating key wrap cipher")
+	}
+	n := len(key) / 8
+	a := make([]byte, 8)
+	copy(a, keyWrapIV)
+	r := make([]byte, len(key))
+	copy(r, key)
+	b := make([]byte, aes.BlockSize)
+	for j := 0; j < 6; j++ {
+		for i := 0; i < n; i++ {
+			copy(b, a)
+			copy(b[8:], r[i*8:(i+1)*8])
+			block.Encrypt(b, b)
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(b[:8])^t)
+			copy(r[i*8:], b[8:])
+		}
+	}
+	return append(a, r...), nil
+}
+
+// unwrapKey unwraps a key wrapped with AES key wrap, checking its integrity
+// https://www.rfc-editor.org/rfc/rfc3394.html#section-2.2.2
+func unwrapKey(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped)%8 != 0 || len(wrapped) < 24 {
+		return nil, errors.New("wrapped key must be a multiple of 8 bytes, and at least 24")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating key wrap cipher")
+	}
+	n := len(wrapped)/8 - 1
+	a := make([]byte, 8)
+	copy(a, wrapped[:8])
+	r := make([]byte, n*8)
+	copy(r, wrapped[8:])
+	b := make([]byte, aes.BlockSize)
+	for j := 5; j >= 0; j-- {
+		for i := n - 1; i >= 0; i-- {
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(b, binary.BigEndian.Uint64(a)^t)
+			copy(b[8:], r[i*8:(i+1)*8])
+			block.Decrypt(b, b)
+			copy(a, b[:8])
+			copy(r[i*8:], b[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(a, keyWrapIV) != 1 {
+		return nil, errors.New("wrapped key failed its integrity check")
+	}
+	return r, nil
+}
+
+// encryptContent encrypts content with A256CBC-HS512, returning its IV, ciphertext and tag, which authenticates the
+// additional authenticated data too
+// https://www.rfc-editor.org/rfc/rfc7518.html#section-5.2.2.1
+func encryptContent(cek, plaintext, aad []byte) (iv, ciphertext, tag []byte, err error) {
+	if len(cek) != cekSize {
+		return nil, nil, nil, errors.Errorf("content encryption key must be %d bytes", cekSize)
+	}
+	block, err := aes.NewCipher(cek[cekSize/2:])
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "creating content cipher")
+	}
+	iv = make([]byte, cbcIVSize)
+	if _, err = rand.Read(iv); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "generating iv")
+	}
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := make([]byte, len(plaintext)+padding)
+	copy(padded, plaintext)
+	for i := len(plaintext); i < len(padded); i++ {
+		padded[i] = byte(padding)
+	}
+	ciphertext = make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+	return iv, ciphertext, contentTag(cek, aad, iv, ciphertext), nil
+}
+
+// decryptContent authenticates and decrypts content encrypted with A256CBC-HS512
+// https://www.rfc-editor.org/rfc/rfc7518.html#section-5.2.2.2
+func decryptContent(cek, aad, iv, ciphertext, tag []byte) ([]byte, error) {
+	if len(cek) != cekSize {
+		return nil, errors.Errorf("content encryption key must be %d bytes", cekSize)
+	}
+	if !hmac.Equal(tag, contentTag(cek, aad, iv, ciphertext)) {
+		return nil, errors.New("content failed authentication")
+	}
+	if len(iv) != cbcIVSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("content is not of A256CBC-HS512")
+	}
+	block, err := aes.NewCipher(cek[cekSize/2:])
+	if err != nil {
+		return nil, errors.Wrap(err, "creating content cipher")
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, errors.New("content has invalid padding")
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("content has invalid padding")
+		}
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+// contentTag is the first half of the HMAC-SHA-512, keyed by the first half of the content encryption key, of the
+// additional authenticated data, the IV, the ciphertext, and the size of the additional authenticated data in bits
+func contentTag(cek, aad, iv, ciphertext []byte) []byte {
+	mac := hmac.New(sha512.New, cek[:cekSize/2])
+	mac.Write(aad)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	mac.Write(binary.BigEndian.AppendUint64(nil, uint64(len(aad))*8))
+	return mac.Sum(nil)[:cekSize/2]
+}
@@ -0,0 +1,72 @@
+package didcomm
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyWrap(t *testing.T) {
+	// the test vector of wrapping 256 bits of key data with a 256-bit KEK
+	// https://www.rfc-editor.org/rfc/rfc3394.html#section-4.6
+	kek, err := hex.DecodeString("000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F")
+	require.NoError(t, err)
+	key, err := hex.DecodeString("00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F")
+	require.NoError(t, err)
+	expected, err := hex.DecodeString("28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21")
+	require.NoError(t, err)
+
+	wrapped, err := wrapKey(kek, key)
+	require.NoError(t, err)
+	assert.Equal(t, expected, wrapped)
+	unwrapped, err := unwrapKey(kek, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	wrapped[0] ^= 1
+	_, err = unwrapKey(kek, wrapped)
+	assert.ErrorContains(t, err, "wrapped key failed its integrity check")
+}
+
+func TestContentEncryption(t *testing.T) {
+	cek := make([]byte, cekSize)
+	_, err := rand.Read(cek)
+	require.NoError(t, err)
+	aad := []byte("protected")
+
+	for _, plaintext := range []string{"", "hello", "exactly 16 bytes"} {
+		iv, ciphertext, tag, err := encryptContent(cek, []byte(plaintext), aad)
+		require.NoError(t, err)
+		decrypted, err := decryptContent(cek, aad, iv, ciphertext, tag)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, string(decrypted))
+	}
+
+	iv, ciphertext, tag, err := encryptContent(cek, []byte("hello"), aad)
+	require.NoError(t, err)
+	_, err = decryptContent(cek, []byte("other"), iv, ciphertext, tag)
+	assert.ErrorContains(t, err, "content failed authentication")
+	_, _, _, err = encryptContent(cek[:32], []byte("hello"), aad)
+	assert.ErrorContains(t, err, "content encryption key must be 64 bytes")
+}
+
+func TestEphemeralKey(t *testing.T) {
+	for _, curve := range []ecdh.Curve{ecdh.X25519(), ecdh.P256(), ecdh.P384(), ecdh.P521()} {
+		privateKey, err := curve.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		epk, err := newEphemeralKey(privateKey)
+		require.NoError(t, err)
+		publicKey, err := epk.publicKey()
+		require.NoError(t, err)
+		assert.True(t, privateKey.PublicKey().Equal(publicKey))
+	}
+
+	_, err := ephemeralKey{KTY: "EC", CRV: "secp256k1"}.publicKey()
+	assert.ErrorContains(t, err, "ephemeral key curve<secp256k1> is not supported")
+	_, err = ephemeralKey{KTY: "EC", CRV: "X25519"}.publicKey()
+	assert.ErrorContains(t, err, "is not of curve<X25519>")
+}
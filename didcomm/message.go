@@ -0,0 +1,235 @@
+package didcomm
+
+import (
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// DIDComm Messaging v2 exchanges messages between parties identified by DIDs. Messages are plaintext JSON objects,
+// which are signed or encrypted for transport, and belong to a protocol identified by the URI of their type.
+// https://identity.foundation/didcomm-messaging/spec/v2.1/
+
+const (
+	// PlaintextMediaType is the media type of plaintext messages, the value of their typ header
+	PlaintextMediaType string = "application/didcomm-plain+json"
+
+	idHeader             string = "id"
+	typHeader            string = "typ"
+	typeHeader           string = "type"
+	fromHeader           string = "from"
+	toHeader             string = "to"
+	thidHeader           string = "thid"
+	pthidHeader          string = "pthid"
+	createdTimeHeader    string = "created_time"
+	expiresTimeHeader    string = "expires_time"
+	fromPriorHeader      string = "from_prior"
+	bodyHeader           string = "body"
+	attachmentsHeader    string = "attachments"
+	didURLFragmentPrefix string = "#"
+)
+
+// Message is a plaintext DIDComm message. Its headers which are not fields of the message, such as those of
+// extensions, are kept in Headers, such that messages round-trip through JSON.
+// https://identity.foundation/didcomm-messaging/spec/v2.1/#message-headers
+type Message struct {
+	ID   string `json:"id" validate:"required"`
+	Typ  string `json:"typ,omitempty"`
+	Type string `json:"type" validate:"required"`
+	// From is the DID of the sender, which is absent for anonymous messages
+	From string `json:"from,omitempty"`
+	// To are the DIDs, or DID URLs of keys, of the recipients
+	To []string `json:"to,omitempty"`
+	// ThreadID is the ID of the thread the message belongs to, which is the message's ID if it is absent
+	ThreadID string `json:"thid,omitempty"`
+	// ParentThreadID is the ID of the thread the message's thread is a child of
+	ParentThreadID string `json:"pthid,omitempty"`
+	// CreatedTime and ExpiresTime are in seconds since the Unix epoch, in UTC
+	CreatedTime int64 `json:"created_time,omitempty"`
+	ExpiresTime int64 `json:"expires_time,omitempty"`
+	// FromPrior is a JWT rotating the sender's DID from a prior DID
+	FromPrior   string         `json:"from_prior,omitempty"`
+	Body        map[string]any `json:"body" validate:"required"`
+	Attachments []Attachment   `json:"attachments,omitempty" validate:"omitempty,dive"`
+	// Headers are the message's other headers
+	Headers map[string]any `json:"-"`
+}
+
+// knownHeaders are the headers of a message's fields
+var knownHeaders = map[string]bool{
+	idHeader:          true,
+	typHeader:         true,
+	typeHeader:        true,
+	fromHeader:        true,
+	toHeader:          true,
+	thidHeader:        true,
+	pthidHeader:       true,
+	createdTimeHeader: true,
+	expiresTimeHeader: true,
+	fromPriorHeader:   true,
+	bodyHeader:        true,
+	attachmentsHeader: true,
+}
+
+type messageAlias Message
+
+func (m Message) MarshalJSON() ([]byte, error) {
+	if len(m.Headers) == 0 {
+		return json.Marshal(messageAlias(m))
+	}
+	headers, err := util.ToJSONMap(messageAlias(m))
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling message")
+	}
+	for header, value := range m.Headers {
+		if knownHeaders[header] {
+			return nil, errors.Errorf("header<%s> is a field of the message", header)
+		}
+		headers[header] = value
+	}
+	return json.Marshal(headers)
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var alias messageAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return errors.Wrap(err, "unmarshalling message")
+	}
+	var headers map[string]any
+	if err := json.Unmarshal(data, &headers); err != nil {
+		return errors.Wrap(err, "unmarshalling message headers")
+	}
+	for header := range knownHeaders {
+		delete(headers, header)
+	}
+	alias.Headers = nil
+	if len(headers) > 0 {
+		alias.Headers = headers
+	}
+	*m = Message(alias)
+	return nil
+}
+
+// NewMessage returns a message of a type, with a random ID, created now, whose body is the JSON object of the given
+// body
+func NewMessage(messageType string, body any) (*Message, error) {
+	if messageType == "" {
+		return nil, errors.New("message type cannot be empty")
+	}
+	bodyJSON := map[string]any{}
+	if body != nil {
+		var err error
+		if bodyJSON, err = util.ToJSONMap(body); err != nil {
+			return nil, errors.Wrap(err, "converting body to a JSON object")
+		}
+	}
+	return &Message{
+		ID:          uuid.NewString(),
+		Typ:         PlaintextMediaType,
+		Type:        messageType,
+		CreatedTime: time.Now().Unix(),
+		Body:        bodyJSON,
+	}, nil
+}
+
+// ParseMessage parses and validates a plaintext message
+func ParseMessage(data []byte) (*Message, error) {
+	var message Message
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, err
+	}
+	if err := message.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid message")
+	}
+	return &message, nil
+}
+
+// IsValid checks the message has an ID, a type and a body, that its typ, if it has one, is that of plaintext
+// messages, that its sender is a DID and its recipients DIDs or DID URLs, that it does not expire before it is
+// created, and that its attachments are valid
+func (m Message) IsValid() error {
+	if err := util.NewValidator().Struct(m); err != nil {
+		return err
+	}
+	if m.Typ != "" && m.Typ != PlaintextMediaType {
+		return errors.Errorf("typ<%s> is not that of plaintext messages", m.Typ)
+	}
+	if m.From != "" && (!isDID(m.From) || strings.Contains(m.From, didURLFragmentPrefix)) {
+		return errors.Errorf("sender<%s> must be a DID", m.From)
+	}
+	for _, to := range m.To {
+		if !isDID(to) {
+			return errors.Errorf("recipient<%s> must be a DID or DID URL", to)
+		}
+	}
+	if m.ExpiresTime != 0 && m.CreatedTime != 0 && m.ExpiresTime < m.CreatedTime {
+		return errors.New("message cannot expire before it is created")
+	}
+	for _, attachment := range m.Attachments {
+		if err := attachment.IsValid(); err != nil {
+			return errors.Wrapf(err, "invalid attachment<%s>", attachment.ID)
+		}
+	}
+	return nil
+}
+
+// Thread returns the ID of the thread of the message, which is its own ID if it starts a thread
+func (m Message) Thread() string {
+	if m.ThreadID != "" {
+		return m.ThreadID
+	}
+	return m.ID
+}
+
+// IsExpired returns whether the message expired at the given time, which is never if it has no expires time
+func (m Message) IsExpired(at time.Time) bool {
+	return m.ExpiresTime != 0 && at.Unix() >= m.ExpiresTime
+}
+
+// SetExpiresIn sets the message to expire the given duration after it is created, or after now if it has no created
+// time
+func (m *Message) SetExpiresIn(d time.Duration) {
+	created := time.Now()
+	if m.CreatedTime != 0 {
+		created = time.Unix(m.CreatedTime, 0)
+	}
+	m.ExpiresTime = created.Add(d).Unix()
+}
+
+// GetBody unmarshals the message's body into v
+func (m Message) GetBody(v any) error {
+	bodyBytes, err := json.Marshal(m.Body)
+	if err != nil {
+		return errors.Wrap(err, "marshalling body")
+	}
+	if err = json.Unmarshal(bodyBytes, v); err != nil {
+		return errors.Wrap(err, "unmarshalling body")
+	}
+	return nil
+}
+
+// Reply returns a message of a type in the message's thread, to its sender, whose body is the JSON object of the
+// given body. The reply's sender is that of the message's recipients the reply is sent from, which the caller sets.
+func (m Message) Reply(messageType string, body any) (*Message, error) {
+	if m.From == "" {
+		return nil, errors.Errorf("message<%s> has no sender to reply to", m.ID)
+	}
+	reply, err := NewMessage(messageType, body)
+	if err != nil {
+		return nil, err
+	}
+	reply.To = []string{m.From}
+	reply.ThreadID = m.Thread()
+	reply.ParentThreadID = m.ParentThreadID
+	return reply, nil
+}
+
+// isDID returns whether s is a DID, or a DID URL
+func isDID(s string) bool {
+	return strings.HasPrefix(s, "did:") && len(s) > len("did:")
+}
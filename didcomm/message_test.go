@@ -0,0 +1,127 @@
+package didcomm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// specMessage is the example plaintext message of the specification, with an extension header
+const specMessage = `{
+	"id": "1234567890",
+	"typ": "application/didcomm-plain+json",
+	"type": "https://example.com/protocols/lets_do_lunch/1.0/proposal",
+	"from": "did:example:alice",
+	"to": ["did:example:bob"],
+	"created_time": 1516269022,
+	"expires_time": 1516385931,
+	"lang": "en",
+	"body": {"messagespecificattribute": "and its value"}
+}`
+
+func TestMessage(t *testing.T) {
+	t.Run("JSON round-trip", func(tt *testing.T) {
+		message, err := ParseMessage([]byte(specMessage))
+		require.NoError(tt, err)
+		assert.Equal(tt, "1234567890", message.ID)
+		assert.Equal(tt, []string{"did:example:bob"}, message.To)
+		assert.Equal(tt, int64(1516385931), message.ExpiresTime)
+		assert.Equal(tt, "and its value", message.Body["messagespecificattribute"])
+		assert.Equal(tt, map[string]any{"lang": "en"}, message.Headers)
+
+		messageBytes, err := json.Marshal(message)
+		require.NoError(tt, err)
+		assert.JSONEq(tt, specMessage, string(messageBytes))
+
+		message.Headers["id"] = "other"
+		_, err = json.Marshal(message)
+		assert.ErrorContains(tt, err, "header<id> is a field of the message")
+	})
+
+	t.Run("new messages", func(tt *testing.T) {
+		type proposal struct {
+			Place string `json:"place"`
+		}
+		message, err := NewMessage("https://example.com/protocols/lets_do_lunch/1.0/proposal", proposal{Place: "cafe"})
+		require.NoError(tt, err)
+		assert.NoError(tt, message.IsValid())
+		assert.NotEmpty(tt, message.ID)
+		assert.Equal(tt, message.ID, message.Thread())
+		assert.WithinDuration(tt, time.Now(), time.Unix(message.CreatedTime, 0), time.Minute)
+
+		var body proposal
+		require.NoError(tt, message.GetBody(&body))
+		assert.Equal(tt, "cafe", body.Place)
+
+		message.SetExpiresIn(time.Hour)
+		assert.False(tt, message.IsExpired(time.Now()))
+		assert.True(tt, message.IsExpired(time.Now().Add(2*time.Hour)))
+
+		empty, err := NewMessage("https://example.com/protocols/ping/1.0/ping", nil)
+		require.NoError(tt, err)
+		assert.NotNil(tt, empty.Body)
+		emptyBytes, err := json.Marshal(empty)
+		require.NoError(tt, err)
+		assert.Contains(tt, string(emptyBytes), `"body":{}`)
+
+		_, err = NewMessage("", nil)
+		assert.ErrorContains(tt, err, "message type cannot be empty")
+	})
+
+	t.Run("replies", func(tt *testing.T) {
+		message, err := ParseMessage([]byte(specMessage))
+		require.NoError(tt, err)
+		message.ParentThreadID = "parent"
+		reply, err := message.Reply("https://example.com/protocols/lets_do_lunch/1.0/accept", nil)
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{"did:example:alice"}, reply.To)
+		assert.Equal(tt, message.ID, reply.ThreadID)
+		assert.Equal(tt, "parent", reply.ParentThreadID)
+
+		// replies stay in the thread
+		second, err := reply.Reply("https://example.com/protocols/lets_do_lunch/1.0/ack", nil)
+		assert.ErrorContains(tt, err, "has no sender to reply to")
+		assert.Nil(tt, second)
+		reply.From = "did:example:bob"
+		second, err = reply.Reply("https://example.com/protocols/lets_do_lunch/1.0/ack", nil)
+		require.NoError(tt, err)
+		assert.Equal(tt, message.ID, second.Thread())
+	})
+
+	t.Run("invalid messages", func(tt *testing.T) {
+		valid := func() Message {
+			message, err := ParseMessage([]byte(specMessage))
+			require.NoError(tt, err)
+			return *message
+		}
+		message := valid()
+		message.Body = nil
+		assert.Error(tt, message.IsValid())
+
+		message = valid()
+		message.Typ = "application/didcomm-signed+json"
+		assert.ErrorContains(tt, message.IsValid(), "is not that of plaintext messages")
+
+		message = valid()
+		message.From = "did:example:alice#key-1"
+		assert.ErrorContains(tt, message.IsValid(), "sender<did:example:alice#key-1> must be a DID")
+
+		message = valid()
+		message.To = []string{"did:example:bob#key-1", "bob"}
+		assert.ErrorContains(tt, message.IsValid(), "recipient<bob> must be a DID or DID URL")
+
+		message = valid()
+		message.ExpiresTime = message.CreatedTime - 1
+		assert.ErrorContains(tt, message.IsValid(), "message cannot expire before it is created")
+
+		message = valid()
+		message.Attachments = []Attachment{{ID: "empty"}}
+		assert.ErrorContains(tt, message.IsValid(), "invalid attachment<empty>")
+
+		_, err := ParseMessage([]byte(`{"id": "1234567890", "body": {}}`))
+		assert.ErrorContains(tt, err, "invalid message")
+	})
+}
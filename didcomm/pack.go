@@ -0,0 +1,486 @@
+package didcomm
+
+import (
+	"bytes"
+	"context"
+	gocrypto "crypto"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// Messages are packed for transport by encrypting them to every key agreement key of their recipients' DIDs, with
+// authcrypt, which authenticates the sender by a key agreement key of its DID, or with anoncrypt, which does not.
+// Authcrypt messages may be wrapped in anoncrypt, such that only their recipients learn their sender.
+// https://identity.foundation/didcomm-messaging/spec/v2.1/#message-encryption
+
+const (
+	// EncryptedMediaType is the media type of encrypted messages, the value of their typ header
+	EncryptedMediaType string = "application/didcomm-encrypted+json"
+	// AuthcryptAlgorithm is the key agreement algorithm of authcrypt messages
+	AuthcryptAlgorithm string = "ECDH-1PU+A256KW"
+	// AnoncryptAlgorithm is the key agreement algorithm of anoncrypt messages
+	AnoncryptAlgorithm string = "ECDH-ES+A256KW"
+	// ContentEncryptionAlgorithm is the content encryption algorithm of encrypted messages
+	ContentEncryptionAlgorithm string = "A256CBC-HS512"
+)
+
+// KeyAgreementKey is a private key agreement key of a party, X25519 or of a NIST curve, identified by the fully
+// qualified ID of its verification method in the keyAgreement verification relationship of the party's DID
+type KeyAgreementKey struct {
+	KID        string
+	PrivateKey gocrypto.PrivateKey
+}
+
+// DID returns the DID of the key's party
+func (k KeyAgreementKey) DID() string {
+	return didOf(k.KID)
+}
+
+// PackOptions configures how a message is packed
+type PackOptions struct {
+	// Sender is the key agreement key the sender authenticates the message with, with authcrypt. Messages are packed
+	// with anoncrypt if it is nil.
+	Sender *KeyAgreementKey
+	// ProtectSender wraps authcrypt messages in anoncrypt, hiding their sender from all but their recipients
+	ProtectSender bool
+}
+
+// UnpackMetadata describes how an unpacked message was packed
+type UnpackMetadata struct {
+	Encrypted bool
+	// Authenticated is whether the message was packed with authcrypt, by the sender of the message's from header
+	Authenticated bool
+	// ProtectedSender is whether the message was packed with authcrypt, wrapped in anoncrypt
+	ProtectedSender bool
+	SenderKID       string
+	RecipientKID    string
+}
+
+// Packer packs and unpacks messages, resolving the DIDs of their senders and recipients
+type Packer struct {
+	resolver resolution.Resolver
+}
+
+// NewPacker returns a packer resolving the DIDs of senders and recipients with the resolver
+func NewPacker(resolver resolution.Resolver) (*Packer, error) {
+	if resolver == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	return &Packer{resolver: resolver}, nil
+}
+
+// encryptedMessage is the general JSON serialization of the JWE of an encrypted message
+type encryptedMessage struct {
+	Protected  string      `json:"protected"`
+	Recipients []recipient `json:"recipients"`
+	IV         string      `json:"iv"`
+	Ciphertext string      `json:"ciphertext"`
+	Tag        string      `json:"tag"`
+}
+
+type recipient struct {
+	Header       recipientHeader `json:"header"`
+	EncryptedKey string          `json:"encrypted_key"`
+}
+
+type recipientHeader struct {
+	KID string `json:"kid"`
+}
+
+// protectedHeader is the header of an encrypted message which all recipients share, whose apv is the hash of the
+// IDs of their keys, and whose apu and skid identify the sender's key, for authcrypt
+type protectedHeader struct {
+	Typ  string       `json:"typ"`
+	Alg  string       `json:"alg"`
+	Enc  string       `json:"enc"`
+	APU  string       `json:"apu,omitempty"`
+	APV  string       `json:"apv"`
+	SKID string       `json:"skid,omitempty"`
+	EPK  ephemeralKey `json:"epk"`
+}
+
+// Pack encrypts a message to every key agreement key of its recipients' DIDs, or to those of its recipients which
+// are DID URLs of keys, of the curve of the sender's key for authcrypt, and of the curve of the first of its
+// recipients' keys for anoncrypt. Messages packed with authcrypt must be from the DID of the sender's key.
+func (p *Packer) Pack(ctx context.Context, message Message, opts PackOptions) ([]byte, error) {
+	if err := message.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid message")
+	}
+	if len(message.To) == 0 {
+		return nil, errors.Errorf("message<%s> has no recipients", message.ID)
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling message")
+	}
+	if opts.Sender == nil {
+		if opts.ProtectSender {
+			return nil, errors.New("only authcrypt messages have a sender to protect")
+		}
+		return p.encrypt(ctx, payload, message.To, nil)
+	}
+
+	if message.From != opts.Sender.DID() {
+		return nil, errors.Errorf("sender key<%s> is not of the message's sender<%s>", opts.Sender.KID, message.From)
+	}
+	envelope, err := p.encrypt(ctx, payload, message.To, opts.Sender)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.ProtectSender {
+		return envelope, nil
+	}
+	return p.encrypt(ctx, envelope, message.To, nil)
+}
+
+// encrypt encrypts a payload to the keys of the recipients, with authcrypt if the sender is given, and with
+// anoncrypt otherwise
+func (p *Packer) encrypt(ctx context.Context, payload []byte, to []string, sender *KeyAgreementKey) ([]byte, error) {
+	var senderKey *ecdh.PrivateKey
+	var curve ecdh.Curve
+	if sender != nil {
+		var err error
+		if senderKey, err = toECDHPrivateKey(sender.PrivateKey); err != nil {
+			return nil, errors.Wrapf(err, "converting sender key<%s>", sender.KID)
+		}
+		curve = senderKey.Curve()
+	}
+	recipientKeys, err := p.resolveRecipientKeys(ctx, to, curve)
+	if err != nil {
+		return nil, err
+	}
+	kids := sortedKIDs(recipientKeys)
+	curve = recipientKeys[kids[0]].Curve()
+	ephemeral, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating ephemeral key")
+	}
+	epk, err := newEphemeralKey(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	apv := recipientsHash(kids)
+	header := protectedHeader{
+		Typ: EncryptedMediaType,
+		Alg: AnoncryptAlgorithm,
+		Enc: ContentEncryptionAlgorithm,
+		APV: base64.RawURLEncoding.EncodeToString(apv),
+		EPK: *epk,
+	}
+	var apu []byte
+	if sender != nil {
+		apu = []byte(sender.KID)
+		header.Alg = AuthcryptAlgorithm
+		header.APU = base64.RawURLEncoding.EncodeToString(apu)
+		header.SKID = sender.KID
+	}
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling protected header")
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerBytes)
+
+	cek := make([]byte, cekSize)
+	if _, err = rand.Read(cek); err != nil {
+		return nil, errors.Wrap(err, "generating content encryption key")
+	}
+	iv, ciphertext, tag, err := encryptContent(cek, payload, []byte(protected))
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypting content")
+	}
+
+	envelope := encryptedMessage{
+		Protected:  protected,
+		IV:         base64.RawURLEncoding.EncodeToString(iv),
+		Ciphertext: base64.RawURLEncoding.EncodeToString(ciphertext),
+		Tag:        base64.RawURLEncoding.EncodeToString(tag),
+	}
+	for _, kid := range kids {
+		z, err := ephemeral.ECDH(recipientKeys[kid])
+		if err != nil {
+			return nil, errors.Wrapf(err, "agreeing on ephemeral key with recipient key<%s>", kid)
+		}
+		// ECDH-1PU agrees on the sender's static key too, and binds the key encryption key to the content's tag
+		var ccTag []byte
+		if senderKey != nil {
+			zs, err := senderKey.ECDH(recipientKeys[kid])
+			if err != nil {
+				return nil, errors.Wrapf(err, "agreeing on sender key with recipient key<%s>", kid)
+			}
+			z = append(z, zs...)
+			ccTag = tag
+		}
+		wrapped, err := wrapKey(deriveKEK(z, header.Alg, apu, apv, ccTag), cek)
+		if err != nil {
+			return nil, errors.Wrapf(err, "wrapping content encryption key for recipient key<%s>", kid)
+		}
+		envelope.Recipients = append(envelope.Recipients, recipient{
+			Header:       recipientHeader{KID: kid},
+			EncryptedKey: base64.RawURLEncoding.EncodeToString(wrapped),
+		})
+	}
+	return json.Marshal(envelope)
+}
+
+// resolveRecipientKeys returns the key agreement keys of the recipients by their IDs, which are every key of a
+// recipient's DID, or the key of a recipient which is a DID URL, of the given curve, or of that of the first of the
+// keys if it is nil
+func (p *Packer) resolveRecipientKeys(ctx context.Context, to []string, curve ecdh.Curve) (map[string]*ecdh.PublicKey, error) {
+	recipientKeys := make(map[string]*ecdh.PublicKey)
+	for _, recipientID := range to {
+		keys, err := p.resolveKeyAgreementKeys(ctx, didOf(recipientID))
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, kid := range sortedKIDs(keys) {
+			if recipientID != didOf(recipientID) && kid != recipientID {
+				continue
+			}
+			if curve == nil {
+				curve = keys[kid].Curve()
+			}
+			if keys[kid].Curve() == curve {
+				recipientKeys[kid] = keys[kid]
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("recipient<%s> has no key agreement key of curve<%s>", recipientID, curveNames[curve])
+		}
+	}
+	return recipientKeys, nil
+}
+
+// resolveKeyAgreementKeys resolves the key agreement keys of a DID which are supported for ECDH, by their IDs
+func (p *Packer) resolveKeyAgreementKeys(ctx context.Context, id string) (map[string]*ecdh.PublicKey, error) {
+	resolved, err := p.resolver.Resolve(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving did<%s>", id)
+	}
+	keys, err := did.GetKeyAgreementKeys(resolved.Document)
+	if err != nil {
+		return nil, err
+	}
+	ecdhKeys := make(map[string]*ecdh.PublicKey, len(keys))
+	for kid, key := range keys {
+		// keys of unsupported types are ignored, such that the supported keys of a DID are used
+		if ecdhKey, err := toECDHPublicKey(key); err == nil {
+			ecdhKeys[kid] = ecdhKey
+		}
+	}
+	return ecdhKeys, nil
+}
+
+// Unpack decrypts a message packed with authcrypt or anoncrypt to one of the given keys of the recipient, or parses
+// a plaintext message. Authcrypt messages must be from the DID of the sender's key, which must be a key agreement key
+// of it, and encrypted messages must be to the DID of the recipient's key.
+func (p *Packer) Unpack(ctx context.Context, envelope []byte, keys ...KeyAgreementKey) (*Message, *UnpackMetadata, error) {
+	var metadata UnpackMetadata
+	message, err := p.unpack(ctx, envelope, keys, &metadata, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return message, &metadata, nil
+}
+
+func (p *Packer) unpack(ctx context.Context, envelope []byte, keys []KeyAgreementKey, metadata *UnpackMetadata, wrapped bool) (*Message, error) {
+	var encrypted encryptedMessage
+	if err := json.Unmarshal(envelope, &encrypted); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling message")
+	}
+	if encrypted.Protected == "" {
+		if wrapped {
+			return nil, errors.New("anoncrypt messages may only wrap authcrypt messages")
+		}
+		return ParseMessage(envelope)
+	}
+
+	header, payload, recipientKID, err := p.decrypt(ctx, encrypted, keys)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Encrypted = true
+	metadata.RecipientKID = recipientKID
+	if header.Alg == AnoncryptAlgorithm {
+		if wrapped {
+			return nil, errors.New("anoncrypt messages may only wrap authcrypt messages")
+		}
+		// anoncrypt messages wrapping authcrypt messages protect their sender
+		var inner encryptedMessage
+		if err = json.Unmarshal(payload, &inner); err == nil && inner.Protected != "" {
+			metadata.ProtectedSender = true
+			return p.unpack(ctx, payload, keys, metadata, true)
+		}
+	} else {
+		metadata.Authenticated = true
+		metadata.SenderKID = header.SKID
+	}
+
+	message, err := ParseMessage(payload)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Authenticated && message.From != didOf(header.SKID) {
+		return nil, errors.Errorf("sender key<%s> is not of the message's sender<%s>", header.SKID, message.From)
+	}
+	recipientDID := didOf(recipientKID)
+	for _, to := range message.To {
+		if didOf(to) == recipientDID {
+			return message, nil
+		}
+	}
+	return nil, errors.Errorf("message<%s> is not to recipient<%s>", message.ID, recipientDID)
+}
+
+// decrypt decrypts an encrypted message with the key of one of its recipients, authenticating its sender for
+// authcrypt, and returns its protected header, its payload, and the ID of the recipient's key
+func (p *Packer) decrypt(ctx context.Context, encrypted encryptedMessage, keys []KeyAgreementKey) (*protectedHeader, []byte, string, error) {
+	headerBytes, err := base64.RawURLEncoding.DecodeString(encrypted.Protected)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "decoding protected header")
+	}
+	var header protectedHeader
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, nil, "", errors.Wrap(err, "unmarshalling protected header")
+	}
+	if header.Alg != AuthcryptAlgorithm && header.Alg != AnoncryptAlgorithm {
+		return nil, nil, "", errors.Errorf("alg<%s> is not supported", header.Alg)
+	}
+	if header.Enc != ContentEncryptionAlgorithm {
+		return nil, nil, "", errors.Errorf("enc<%s> is not supported", header.Enc)
+	}
+
+	// the recipients' key IDs are bound to the apv, such that none is added or removed
+	kids := make([]string, 0, len(encrypted.Recipients))
+	for _, r := range encrypted.Recipients {
+		kids = append(kids, r.Header.KID)
+	}
+	sort.Strings(kids)
+	apv, err := base64.RawURLEncoding.DecodeString(header.APV)
+	if err != nil || !bytes.Equal(apv, recipientsHash(kids)) {
+		return nil, nil, "", errors.New("apv is not the hash of the recipients' key ids")
+	}
+
+	var recipientKey *KeyAgreementKey
+	var wrappedKey string
+	for _, r := range encrypted.Recipients {
+		for i := range keys {
+			if keys[i].KID == r.Header.KID {
+				recipientKey, wrappedKey = &keys[i], r.EncryptedKey
+			}
+		}
+	}
+	if recipientKey == nil {
+		return nil, nil, "", errors.New("message is not encrypted to any of the given keys")
+	}
+	privateKey, err := toECDHPrivateKey(recipientKey.PrivateKey)
+	if err != nil {
+		return nil, nil, "", errors.Wrapf(err, "converting recipient key<%s>", recipientKey.KID)
+	}
+	epk, err := header.EPK.publicKey()
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "invalid epk")
+	}
+	if epk.Curve() != privateKey.Curve() {
+		return nil, nil, "", errors.Errorf("epk is not of the curve of recipient key<%s>", recipientKey.KID)
+	}
+	z, err := privateKey.ECDH(epk)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "agreeing on ephemeral key")
+	}
+	tag, err := base64.RawURLEncoding.DecodeString(encrypted.Tag)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "decoding tag")
+	}
+
+	var apu, ccTag []byte
+	if header.Alg == AuthcryptAlgorithm {
+		senderKey, err := p.resolveSenderKey(ctx, header)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if senderKey.Curve() != privateKey.Curve() {
+			return nil, nil, "", errors.Errorf("sender key<%s> is not of the curve of recipient key<%s>", header.SKID, recipientKey.KID)
+		}
+		zs, err := privateKey.ECDH(senderKey)
+		if err != nil {
+			return nil, nil, "", errors.Wrap(err, "agreeing on sender key")
+		}
+		z = append(z, zs...)
+		apu, ccTag = []byte(header.SKID), tag
+	}
+
+	wrapped, err := base64.RawURLEncoding.DecodeString(wrappedKey)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "decoding encrypted key")
+	}
+	cek, err := unwrapKey(deriveKEK(z, header.Alg, apu, apv, ccTag), wrapped)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "unwrapping content encryption key")
+	}
+	iv, err := base64.RawURLEncoding.DecodeString(encrypted.IV)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "decoding iv")
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(encrypted.Ciphertext)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "decoding ciphertext")
+	}
+	payload, err := decryptContent(cek, []byte(encrypted.Protected), iv, ciphertext, tag)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "decrypting content")
+	}
+	return &header, payload, recipientKey.KID, nil
+}
+
+// resolveSenderKey resolves the sender key of an authcrypt message, which must be a key agreement key of its DID
+func (p *Packer) resolveSenderKey(ctx context.Context, header protectedHeader) (*ecdh.PublicKey, error) {
+	if header.SKID == "" {
+		return nil, errors.New("authcrypt message has no skid")
+	}
+	if header.APU != "" && header.APU != base64.RawURLEncoding.EncodeToString([]byte(header.SKID)) {
+		return nil, errors.New("apu is not the skid")
+	}
+	keys, err := p.resolveKeyAgreementKeys(ctx, didOf(header.SKID))
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving sender key")
+	}
+	senderKey, ok := keys[header.SKID]
+	if !ok {
+		return nil, errors.Errorf("sender key<%s> is not a key agreement key of its DID", header.SKID)
+	}
+	return senderKey, nil
+}
+
+// recipientsHash is the hash of the sorted IDs of the recipients' keys, joined by dots, the apv of messages
+func recipientsHash(kids []string) []byte {
+	hash := sha256.Sum256([]byte(strings.Join(kids, ".")))
+	return hash[:]
+}
+
+func sortedKIDs(keys map[string]*ecdh.PublicKey) []string {
+	kids := make([]string, 0, len(keys))
+	for kid := range keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	return kids
+}
+
+// didOf returns the DID of a DID URL
+func didOf(didURL string) string {
+	id, _, _ := strings.Cut(didURL, didURLFragmentPrefix)
+	return id
+}
@@ -0,0 +1,190 @@
+package didcomm
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestPacker(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	packer, err := NewPacker(resolver)
+	require.NoError(t, err)
+	ctx := context.Background()
+	alice := getTestKeyAgreementKey(t)
+	bob := getTestKeyAgreementKey(t)
+	carol := getTestKeyAgreementKey(t)
+
+	newMessage := func(tt *testing.T, from string, to ...string) Message {
+		message, err := NewMessage("https://example.com/protocols/lets_do_lunch/1.0/proposal", map[string]any{"place": "cafe"})
+		require.NoError(tt, err)
+		message.From = from
+		message.To = to
+		return *message
+	}
+
+	t.Run("authcrypt", func(tt *testing.T) {
+		message := newMessage(tt, alice.DID(), bob.DID(), carol.DID())
+		packed, err := packer.Pack(ctx, message, PackOptions{Sender: &alice})
+		require.NoError(tt, err)
+		header := getTestProtectedHeader(tt, packed)
+		assert.Equal(tt, EncryptedMediaType, header.Typ)
+		assert.Equal(tt, AuthcryptAlgorithm, header.Alg)
+		assert.Equal(tt, ContentEncryptionAlgorithm, header.Enc)
+		assert.Equal(tt, alice.KID, header.SKID)
+		assert.Equal(tt, "X25519", header.EPK.CRV)
+
+		// every recipient unpacks the message
+		for _, recipientKey := range []KeyAgreementKey{bob, carol} {
+			unpacked, metadata, err := packer.Unpack(ctx, packed, recipientKey)
+			require.NoError(tt, err)
+			assert.Equal(tt, message.ID, unpacked.ID)
+			assert.Equal(tt, "cafe", unpacked.Body["place"])
+			assert.True(tt, metadata.Encrypted)
+			assert.True(tt, metadata.Authenticated)
+			assert.False(tt, metadata.ProtectedSender)
+			assert.Equal(tt, alice.KID, metadata.SenderKID)
+			assert.Equal(tt, recipientKey.KID, metadata.RecipientKID)
+		}
+
+		_, _, err = packer.Unpack(ctx, packed, alice)
+		assert.ErrorContains(tt, err, "message is not encrypted to any of the given keys")
+		_, err = packer.Pack(ctx, newMessage(tt, carol.DID(), bob.DID()), PackOptions{Sender: &alice})
+		assert.ErrorContains(tt, err, "is not of the message's sender")
+	})
+
+	t.Run("anoncrypt", func(tt *testing.T) {
+		message := newMessage(tt, "", bob.DID())
+		packed, err := packer.Pack(ctx, message, PackOptions{})
+		require.NoError(tt, err)
+		header := getTestProtectedHeader(tt, packed)
+		assert.Equal(tt, AnoncryptAlgorithm, header.Alg)
+		assert.Empty(tt, header.SKID)
+		assert.Empty(tt, header.APU)
+
+		unpacked, metadata, err := packer.Unpack(ctx, packed, bob)
+		require.NoError(tt, err)
+		assert.Equal(tt, message.ID, unpacked.ID)
+		assert.True(tt, metadata.Encrypted)
+		assert.False(tt, metadata.Authenticated)
+		assert.Empty(tt, metadata.SenderKID)
+
+		_, err = packer.Pack(ctx, message, PackOptions{ProtectSender: true})
+		assert.ErrorContains(tt, err, "only authcrypt messages have a sender to protect")
+		message.To = nil
+		_, err = packer.Pack(ctx, message, PackOptions{})
+		assert.ErrorContains(tt, err, "has no recipients")
+	})
+
+	t.Run("protected senders", func(tt *testing.T) {
+		message := newMessage(tt, alice.DID(), bob.DID())
+		packed, err := packer.Pack(ctx, message, PackOptions{Sender: &alice, ProtectSender: true})
+		require.NoError(tt, err)
+		header := getTestProtectedHeader(tt, packed)
+		assert.Equal(tt, AnoncryptAlgorithm, header.Alg)
+		assert.Empty(tt, header.SKID)
+		assert.NotContains(tt, string(packed), alice.DID())
+
+		unpacked, metadata, err := packer.Unpack(ctx, packed, bob)
+		require.NoError(tt, err)
+		assert.Equal(tt, message.ID, unpacked.ID)
+		assert.True(tt, metadata.Authenticated)
+		assert.True(tt, metadata.ProtectedSender)
+		assert.Equal(tt, alice.KID, metadata.SenderKID)
+	})
+
+	t.Run("recipients by key", func(tt *testing.T) {
+		message := newMessage(tt, alice.DID(), bob.KID)
+		packed, err := packer.Pack(ctx, message, PackOptions{Sender: &alice})
+		require.NoError(tt, err)
+		_, _, err = packer.Unpack(ctx, packed, bob)
+		require.NoError(tt, err)
+
+		message.To = []string{bob.DID() + "#other"}
+		_, err = packer.Pack(ctx, message, PackOptions{Sender: &alice})
+		assert.ErrorContains(tt, err, "has no key agreement key of curve<X25519>")
+	})
+
+	t.Run("tampered messages", func(tt *testing.T) {
+		packed, err := packer.Pack(ctx, newMessage(tt, alice.DID(), bob.DID(), carol.DID()), PackOptions{Sender: &alice})
+		require.NoError(tt, err)
+
+		var envelope encryptedMessage
+		require.NoError(tt, json.Unmarshal(packed, &envelope))
+		tampered := envelope
+		ciphertext, err := base64.RawURLEncoding.DecodeString(envelope.Ciphertext)
+		require.NoError(tt, err)
+		ciphertext[0] ^= 1
+		tampered.Ciphertext = base64.RawURLEncoding.EncodeToString(ciphertext)
+		_, _, err = packer.Unpack(ctx, getTestEnvelope(tt, tampered), bob)
+		assert.ErrorContains(tt, err, "content failed authentication")
+
+		// recipients cannot be removed without changing the apv
+		tampered = envelope
+		tampered.Recipients = envelope.Recipients[:1]
+		_, _, err = packer.Unpack(ctx, getTestEnvelope(tt, tampered), bob, carol)
+		assert.ErrorContains(tt, err, "apv is not the hash of the recipients' key ids")
+	})
+
+	t.Run("spoofed senders", func(tt *testing.T) {
+		// messages authenticated by one party cannot claim to be from another
+		payload, err := json.Marshal(newMessage(tt, carol.DID(), bob.DID()))
+		require.NoError(tt, err)
+		packed, err := packer.encrypt(ctx, payload, []string{bob.DID()}, &alice)
+		require.NoError(tt, err)
+		_, _, err = packer.Unpack(ctx, packed, bob)
+		assert.ErrorContains(tt, err, "is not of the message's sender")
+
+		// nor be to another
+		payload, err = json.Marshal(newMessage(tt, alice.DID(), carol.DID()))
+		require.NoError(tt, err)
+		packed, err = packer.encrypt(ctx, payload, []string{bob.DID()}, &alice)
+		require.NoError(tt, err)
+		_, _, err = packer.Unpack(ctx, packed, bob)
+		assert.ErrorContains(tt, err, "is not to recipient")
+	})
+
+	t.Run("plaintext messages", func(tt *testing.T) {
+		message := newMessage(tt, alice.DID(), bob.DID())
+		plaintext, err := json.Marshal(message)
+		require.NoError(tt, err)
+		unpacked, metadata, err := packer.Unpack(ctx, plaintext)
+		require.NoError(tt, err)
+		assert.Equal(tt, message.ID, unpacked.ID)
+		assert.False(tt, metadata.Encrypted)
+	})
+}
+
+// getTestKeyAgreementKey returns the key agreement key of a new X25519 did:key
+func getTestKeyAgreementKey(t *testing.T) KeyAgreementKey {
+	privateKey, didKey, err := key.GenerateDIDKey(crypto.X25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	return KeyAgreementKey{KID: expanded.VerificationMethod[0].ID, PrivateKey: privateKey}
+}
+
+func getTestProtectedHeader(t *testing.T, packed []byte) protectedHeader {
+	var envelope encryptedMessage
+	require.NoError(t, json.Unmarshal(packed, &envelope))
+	headerBytes, err := base64.RawURLEncoding.DecodeString(envelope.Protected)
+	require.NoError(t, err)
+	var header protectedHeader
+	require.NoError(t, json.Unmarshal(headerBytes, &header))
+	return header
+}
+
+func getTestEnvelope(t *testing.T, envelope encryptedMessage) []byte {
+	envelopeBytes, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	return envelopeBytes
+}
@@ -0,0 +1,188 @@
+package didcomm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// Messages are routed to their recipient through the mediators of the recipient's DIDCommMessaging service, by
+// wrapping them, for each of the service's routing keys, in a forward message to the mediator of the key, encrypted
+// with anoncrypt, which tells the mediator the next hop of the message. The first routing key is that of the mediator
+// of the service's endpoint, which may be the DID of a mediator, whose own routing keys are routed through first.
+// https://identity.foundation/didcomm-messaging/spec/v2.1/#routing-protocol-20
+
+const (
+	// ForwardMessageType is the type of forward messages
+	ForwardMessageType string = "https://didcomm.org/routing/2.0/forward"
+	// MessagingServiceType is the type of the DIDCommMessaging services of DIDs
+	MessagingServiceType string = "DIDCommMessaging"
+	// ProfileV2 is the profile of DIDComm v2, which the accept of service endpoints lists if they accept it
+	ProfileV2 string = "didcomm/v2"
+)
+
+// ServiceEndpoint is an endpoint of a DIDCommMessaging service, whose URI is that of the transport messages are sent
+// to, or the DID of a mediator
+// https://identity.foundation/didcomm-messaging/spec/v2.1/#service-endpoint
+type ServiceEndpoint struct {
+	URI         string   `json:"uri"`
+	Accept      []string `json:"accept,omitempty"`
+	RoutingKeys []string `json:"routingKeys,omitempty"`
+}
+
+// ForwardBody is the body of a forward message, whose single attachment is the message forwarded to the next hop
+type ForwardBody struct {
+	// Next is the DID, or the DID URL of a routing key, the forwarded message is for
+	Next string `json:"next"`
+}
+
+// RoutedMessage is a packed message wrapped for the route to its recipient, and the endpoint it is sent to
+type RoutedMessage struct {
+	Endpoint ServiceEndpoint
+	Message  []byte
+}
+
+// Forward is a message a mediator forwards to the next hop of its route
+type Forward struct {
+	Next    string
+	Message []byte
+}
+
+// GetServiceEndpoints returns the endpoints of a DID's DIDCommMessaging services, whose service endpoints are
+// endpoint objects, sets of them, or URIs with the routing keys and accept of their service
+func GetServiceEndpoints(doc did.Document) ([]ServiceEndpoint, error) {
+	var endpoints []ServiceEndpoint
+	for _, service := range doc.Services {
+		if service.Type != MessagingServiceType {
+			continue
+		}
+		serviceEndpointBytes, err := json.Marshal(service.ServiceEndpoint)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshalling endpoint of service<%s>", service.ID)
+		}
+		var serviceEndpoints []json.RawMessage
+		if err = json.Unmarshal(serviceEndpointBytes, &serviceEndpoints); err != nil {
+			serviceEndpoints = []json.RawMessage{serviceEndpointBytes}
+		}
+		for _, serviceEndpoint := range serviceEndpoints {
+			var uri string
+			if err = json.Unmarshal(serviceEndpoint, &uri); err == nil {
+				endpoints = append(endpoints, ServiceEndpoint{URI: uri, Accept: service.Accept, RoutingKeys: service.RoutingKeys})
+				continue
+			}
+			var endpoint ServiceEndpoint
+			if err = json.Unmarshal(serviceEndpoint, &endpoint); err != nil || endpoint.URI == "" {
+				return nil, errors.Errorf("service<%s> has an invalid endpoint", service.ID)
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints, nil
+}
+
+// Route wraps a message packed for a recipient in a forward message for each routing key of the first endpoint of
+// the recipient's DIDCommMessaging services which accepts DIDComm v2, returning the message to send to the endpoint.
+// Endpoints whose URI is the DID of a mediator are routed through the mediator's first endpoint, and its routing keys.
+func (p *Packer) Route(ctx context.Context, packed []byte, recipient string) (*RoutedMessage, error) {
+	endpoint, err := p.resolveServiceEndpoint(ctx, didOf(recipient))
+	if err != nil {
+		return nil, err
+	}
+	routingKeys := endpoint.RoutingKeys
+	if strings.HasPrefix(endpoint.URI, "did:") {
+		mediatorEndpoint, err := p.resolveServiceEndpoint(ctx, endpoint.URI)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving endpoint of mediator<%s>", endpoint.URI)
+		}
+		if strings.HasPrefix(mediatorEndpoint.URI, "did:") {
+			return nil, errors.Errorf("endpoint of mediator<%s> cannot be another mediator's DID", endpoint.URI)
+		}
+		routingKeys = append(append([]string{}, mediatorEndpoint.RoutingKeys...), routingKeys...)
+		endpoint.URI, endpoint.Accept = mediatorEndpoint.URI, mediatorEndpoint.Accept
+	}
+
+	// the message is wrapped for the last routing key first, the next hop of each being the key after it, and that of
+	// the last the recipient
+	message := packed
+	for i := len(routingKeys) - 1; i >= 0; i-- {
+		next := recipient
+		if i < len(routingKeys)-1 {
+			next = routingKeys[i+1]
+		}
+		if message, err = p.wrapForward(ctx, message, routingKeys[i], next); err != nil {
+			return nil, errors.Wrapf(err, "wrapping message for routing key<%s>", routingKeys[i])
+		}
+	}
+	return &RoutedMessage{
+		Endpoint: ServiceEndpoint{URI: endpoint.URI, Accept: endpoint.Accept, RoutingKeys: routingKeys},
+		Message:  message,
+	}, nil
+}
+
+// resolveServiceEndpoint resolves the first endpoint of a DID's DIDCommMessaging services which accepts DIDComm v2
+func (p *Packer) resolveServiceEndpoint(ctx context.Context, id string) (*ServiceEndpoint, error) {
+	resolved, err := p.resolver.Resolve(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving did<%s>", id)
+	}
+	endpoints, err := GetServiceEndpoints(resolved.Document)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		if len(endpoint.Accept) == 0 || util.Contains(ProfileV2, endpoint.Accept) {
+			return &endpoint, nil
+		}
+	}
+	return nil, errors.Errorf("did<%s> has no DIDComm v2 service endpoint", id)
+}
+
+// wrapForward wraps a packed message in a forward message to its next hop, encrypted to a routing key with anoncrypt
+func (p *Packer) wrapForward(ctx context.Context, packed []byte, routingKey, next string) ([]byte, error) {
+	forward, err := NewMessage(ForwardMessageType, ForwardBody{Next: next})
+	if err != nil {
+		return nil, err
+	}
+	attachment, err := NewJSONAttachment("", json.RawMessage(packed))
+	if err != nil {
+		return nil, err
+	}
+	forward.To = []string{routingKey}
+	forward.Attachments = []Attachment{*attachment}
+	return p.Pack(ctx, *forward, PackOptions{})
+}
+
+// UnpackForward unpacks a forward message to a mediator, with the mediator's keys, and returns the message it
+// forwards, and its next hop
+func (p *Packer) UnpackForward(ctx context.Context, envelope []byte, keys ...KeyAgreementKey) (*Forward, error) {
+	message, metadata, err := p.Unpack(ctx, envelope, keys...)
+	if err != nil {
+		return nil, err
+	}
+	if message.Type != ForwardMessageType {
+		return nil, errors.Errorf("message<%s> of type<%s> is not a forward message", message.ID, message.Type)
+	}
+	if !metadata.Encrypted {
+		return nil, errors.Errorf("forward message<%s> must be encrypted", message.ID)
+	}
+	var body ForwardBody
+	if err = message.GetBody(&body); err != nil {
+		return nil, err
+	}
+	if body.Next == "" {
+		return nil, errors.Errorf("forward message<%s> has no next hop", message.ID)
+	}
+	if len(message.Attachments) != 1 {
+		return nil, errors.Errorf("forward message<%s> must have exactly one attachment", message.ID)
+	}
+	forwarded, err := message.Attachments[0].Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &Forward{Next: body.Next, Message: forwarded}, nil
+}
@@ -0,0 +1,130 @@
+package didcomm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestRouting(t *testing.T) {
+	ctx := context.Background()
+	resolver := testDIDResolver{}
+	packer, err := NewPacker(resolver)
+	require.NoError(t, err)
+	alice := getTestKeyAgreementKey(t)
+	bob := getTestKeyAgreementKey(t)
+
+	setService := func(tt *testing.T, party KeyAgreementKey, service did.Service) {
+		resolved, err := key.Resolver{}.Resolve(ctx, party.DID())
+		require.NoError(tt, err)
+		service.ID = party.DID() + "#didcomm"
+		service.Type = MessagingServiceType
+		doc := resolved.Document
+		doc.Services = []did.Service{service}
+		resolver[party.DID()] = doc
+	}
+	packForBob := func(tt *testing.T) (Message, []byte) {
+		message, err := NewMessage("https://example.com/protocols/lets_do_lunch/1.0/proposal", nil)
+		require.NoError(tt, err)
+		message.From = alice.DID()
+		message.To = []string{bob.DID()}
+		packed, err := packer.Pack(ctx, *message, PackOptions{Sender: &alice})
+		require.NoError(tt, err)
+		return *message, packed
+	}
+
+	t.Run("through mediators", func(tt *testing.T) {
+		mediator1 := getTestKeyAgreementKey(tt)
+		mediator2 := getTestKeyAgreementKey(tt)
+		setService(tt, bob, did.Service{ServiceEndpoint: map[string]any{
+			"uri":         "https://mediator1.example.com",
+			"accept":      []string{ProfileV2},
+			"routingKeys": []string{mediator1.KID, mediator2.KID},
+		}})
+		message, packed := packForBob(tt)
+
+		routed, err := packer.Route(ctx, packed, bob.DID())
+		require.NoError(tt, err)
+		assert.Equal(tt, "https://mediator1.example.com", routed.Endpoint.URI)
+		assert.Equal(tt, []string{mediator1.KID, mediator2.KID}, routed.Endpoint.RoutingKeys)
+
+		// each mediator unwraps its forward message alone
+		_, err = packer.UnpackForward(ctx, routed.Message, mediator2)
+		assert.ErrorContains(tt, err, "message is not encrypted to any of the given keys")
+		forward, err := packer.UnpackForward(ctx, routed.Message, mediator1)
+		require.NoError(tt, err)
+		assert.Equal(tt, mediator2.KID, forward.Next)
+		forward, err = packer.UnpackForward(ctx, forward.Message, mediator2)
+		require.NoError(tt, err)
+		assert.Equal(tt, bob.DID(), forward.Next)
+
+		unpacked, metadata, err := packer.Unpack(ctx, forward.Message, bob)
+		require.NoError(tt, err)
+		assert.Equal(tt, message.ID, unpacked.ID)
+		assert.True(tt, metadata.Authenticated)
+		_, err = packer.UnpackForward(ctx, forward.Message, bob)
+		assert.ErrorContains(tt, err, "is not a forward message")
+	})
+
+	t.Run("mediator DIDs as endpoints", func(tt *testing.T) {
+		mediator := getTestKeyAgreementKey(tt)
+		setService(tt, mediator, did.Service{
+			ServiceEndpoint: "https://mediator.example.com",
+			RoutingKeys:     []string{mediator.KID},
+		})
+		setService(tt, bob, did.Service{ServiceEndpoint: []any{map[string]any{"uri": mediator.DID()}}})
+		message, packed := packForBob(tt)
+
+		routed, err := packer.Route(ctx, packed, bob.DID())
+		require.NoError(tt, err)
+		assert.Equal(tt, "https://mediator.example.com", routed.Endpoint.URI)
+		forward, err := packer.UnpackForward(ctx, routed.Message, mediator)
+		require.NoError(tt, err)
+		assert.Equal(tt, bob.DID(), forward.Next)
+		unpacked, _, err := packer.Unpack(ctx, forward.Message, bob)
+		require.NoError(tt, err)
+		assert.Equal(tt, message.ID, unpacked.ID)
+	})
+
+	t.Run("direct endpoints", func(tt *testing.T) {
+		setService(tt, bob, did.Service{ServiceEndpoint: "https://bob.example.com"})
+		_, packed := packForBob(tt)
+		routed, err := packer.Route(ctx, packed, bob.DID())
+		require.NoError(tt, err)
+		assert.Equal(tt, "https://bob.example.com", routed.Endpoint.URI)
+		assert.Equal(tt, packed, routed.Message)
+	})
+
+	t.Run("recipients without DIDComm v2 endpoints", func(tt *testing.T) {
+		_, err := packer.Route(ctx, []byte("{}"), alice.DID())
+		assert.ErrorContains(tt, err, "has no DIDComm v2 service endpoint")
+
+		setService(tt, bob, did.Service{ServiceEndpoint: "https://bob.example.com", Accept: []string{"didcomm/aip2;env=rfc19"}})
+		_, err = packer.Route(ctx, []byte("{}"), bob.DID())
+		assert.ErrorContains(tt, err, "has no DIDComm v2 service endpoint")
+
+		setService(tt, bob, did.Service{ServiceEndpoint: map[string]any{"accept": []string{ProfileV2}}})
+		_, err = packer.Route(ctx, []byte("{}"), bob.DID())
+		assert.ErrorContains(tt, err, "has an invalid endpoint")
+	})
+}
+
+// testDIDResolver resolves the DIDs of its documents, and did:keys
+type testDIDResolver map[string]did.Document
+
+func (r testDIDResolver) Resolve(ctx context.Context, id string, opts ...resolution.Option) (*resolution.Result, error) {
+	if doc, ok := r[id]; ok {
+		return &resolution.Result{Document: doc}, nil
+	}
+	return key.Resolver{}.Resolve(ctx, id, opts...)
+}
+
+func (testDIDResolver) Methods() []did.Method {
+	return []did.Method{did.KeyMethod}
+}
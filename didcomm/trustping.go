@@ -0,0 +1,112 @@
+package didcomm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The trust ping protocol checks connectivity with another agent, which responds to pings asking for a response.
+// https://identity.foundation/didcomm-messaging/spec/v2.1/#trust-ping-protocol-20
+
+const (
+	// TrustPingProtocol is the URI of the trust ping protocol, which its message types are in
+	TrustPingProtocol string = "https://didcomm.org/trust-ping/2.0"
+
+	PingType         string = TrustPingProtocol + "/ping"
+	PingResponseType string = TrustPingProtocol + "/ping-response"
+)
+
+// PingBody is the body of a ping
+type PingBody struct {
+	ResponseRequested bool `json:"response_requested"`
+}
+
+// TrustPing sends pings from, and responds to the pings to, an agent's DID, keeping the time each ping awaiting a
+// response was sent, and its round trip time once it is responded to
+type TrustPing struct {
+	did string
+
+	mu      sync.Mutex
+	pending map[string]time.Time
+	// responded are the round trip times of the pings responded to, by ID
+	responded map[string]time.Duration
+}
+
+var _ Handler = (*TrustPing)(nil)
+
+// NewTrustPing returns the trust ping protocol of an agent's DID
+func NewTrustPing(did string) (*TrustPing, error) {
+	if !isDID(did) {
+		return nil, errors.Errorf("agent<%s> must be a DID", did)
+	}
+	return &TrustPing{did: did, pending: make(map[string]time.Time), responded: make(map[string]time.Duration)}, nil
+}
+
+// Ping returns a ping to another agent, which awaits a response if one is requested
+func (tp *TrustPing) Ping(to string, responseRequested bool) (*Message, error) {
+	ping, err := NewMessage(PingType, PingBody{ResponseRequested: responseRequested})
+	if err != nil {
+		return nil, err
+	}
+	ping.From = tp.did
+	ping.To = []string{to}
+	if err = ping.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid ping")
+	}
+	if responseRequested {
+		tp.mu.Lock()
+		tp.pending[ping.ID] = time.Now()
+		tp.mu.Unlock()
+	}
+	return ping, nil
+}
+
+// Handle responds to pings asking for a response, and records the responses to pings awaiting them
+func (tp *TrustPing) Handle(_ context.Context, message Message) (*Message, error) {
+	switch message.Type {
+	case PingType:
+		var body PingBody
+		if err := message.GetBody(&body); err != nil {
+			return nil, err
+		}
+		if !body.ResponseRequested {
+			return nil, nil
+		}
+		response, err := message.Reply(PingResponseType, nil)
+		if err != nil {
+			return nil, err
+		}
+		response.From = tp.did
+		return response, nil
+	case PingResponseType:
+		tp.mu.Lock()
+		defer tp.mu.Unlock()
+		sent, ok := tp.pending[message.ThreadID]
+		if !ok {
+			return nil, errors.Errorf("ping response<%s> is not to a ping awaiting a response", message.ID)
+		}
+		delete(tp.pending, message.ThreadID)
+		tp.responded[message.ThreadID] = time.Since(sent)
+		return nil, nil
+	}
+	return nil, errors.Errorf("message<%s> of type<%s> is not a trust ping message", message.ID, message.Type)
+}
+
+// RoundTrip returns the round trip time of a ping, and whether it was responded to
+func (tp *TrustPing) RoundTrip(pingID string) (time.Duration, bool) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	rtt, ok := tp.responded[pingID]
+	return rtt, ok
+}
+
+// IsPending returns whether a ping awaits a response
+func (tp *TrustPing) IsPending(pingID string) bool {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	_, ok := tp.pending[pingID]
+	return ok
+}
@@ -0,0 +1,61 @@
+package didcomm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustPing(t *testing.T) {
+	ctx := context.Background()
+	alice, err := NewTrustPing("did:example:alice")
+	require.NoError(t, err)
+	bob, err := NewTrustPing("did:example:bob")
+	require.NoError(t, err)
+
+	t.Run("pings with responses", func(tt *testing.T) {
+		ping, err := alice.Ping("did:example:bob", true)
+		require.NoError(tt, err)
+		assert.True(tt, alice.IsPending(ping.ID))
+
+		response, err := bob.Handle(ctx, *ping)
+		require.NoError(tt, err)
+		require.NotNil(tt, response)
+		assert.Equal(tt, PingResponseType, response.Type)
+		assert.Equal(tt, ping.ID, response.ThreadID)
+		assert.Equal(tt, "did:example:bob", response.From)
+		assert.Equal(tt, []string{"did:example:alice"}, response.To)
+
+		reply, err := alice.Handle(ctx, *response)
+		require.NoError(tt, err)
+		assert.Nil(tt, reply)
+		assert.False(tt, alice.IsPending(ping.ID))
+		_, ok := alice.RoundTrip(ping.ID)
+		assert.True(tt, ok)
+
+		// each ping is responded to once
+		_, err = alice.Handle(ctx, *response)
+		assert.ErrorContains(tt, err, "is not to a ping awaiting a response")
+	})
+
+	t.Run("pings without responses", func(tt *testing.T) {
+		ping, err := alice.Ping("did:example:bob", false)
+		require.NoError(tt, err)
+		assert.False(tt, alice.IsPending(ping.ID))
+		response, err := bob.Handle(ctx, *ping)
+		require.NoError(tt, err)
+		assert.Nil(tt, response)
+	})
+
+	t.Run("other messages", func(tt *testing.T) {
+		message, err := NewMessage("https://didcomm.org/basicmessage/2.0/message", nil)
+		require.NoError(tt, err)
+		_, err = bob.Handle(ctx, *message)
+		assert.ErrorContains(tt, err, "is not a trust ping message")
+
+		_, err = NewTrustPing("alice")
+		assert.ErrorContains(tt, err, "must be a DID")
+	})
+}
@@ -0,0 +1,193 @@
+package waci
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/manifest"
+	"github.com/TBD54566975/ssi-sdk/didcomm"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// WACI-DIDComm issues credentials with the Issue Credential 3.0 protocol, in a thread in which the holder proposes
+// issuance, the issuer offers the credentials of a credential manifest, the holder requests them with a credential
+// application, and the issuer issues them with a credential response, each attached to the protocol's messages.
+// https://identity.foundation/waci-didcomm/#issue-credential-v3
+// https://github.com/decentralized-identity/waci-didcomm/blob/main/issue_credential/README.md
+
+const (
+	// IssueCredentialProtocol is the URI of the Issue Credential 3.0 protocol, which its message types are in
+	IssueCredentialProtocol string = "https://didcomm.org/issue-credential/3.0"
+
+	ProposeCredentialType string = IssueCredentialProtocol + "/propose-credential"
+	OfferCredentialType   string = IssueCredentialProtocol + "/offer-credential"
+	RequestCredentialType string = IssueCredentialProtocol + "/request-credential"
+	IssueCredentialType   string = IssueCredentialProtocol + "/issue-credential"
+
+	// CredentialManifestFormat, CredentialApplicationFormat and CredentialResponseFormat are the formats of the
+	// attachments of offers, requests, and issuances
+	CredentialManifestFormat    string = "dif/credential-manifest/manifest@v1.0"
+	CredentialApplicationFormat string = "dif/credential-manifest/application@v1.0"
+	CredentialResponseFormat    string = "dif/credential-manifest/response@v1.0"
+)
+
+// IssuanceBody is the body of the messages of the issuance protocol
+type IssuanceBody struct {
+	// GoalCode is the goal of the message, such as "issue-vc"
+	GoalCode string `json:"goal_code,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// NewProposeCredential returns a holder's proposal for an issuer to issue it credentials, which starts a thread. The
+// thread of an out-of-band invitation the proposal answers is its parent thread, which the caller sets.
+func NewProposeCredential(from, to string, body IssuanceBody) (*didcomm.Message, error) {
+	proposal, err := didcomm.NewMessage(ProposeCredentialType, body)
+	if err != nil {
+		return nil, err
+	}
+	proposal.From = from
+	proposal.To = []string{to}
+	if err = proposal.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid proposal")
+	}
+	return proposal, nil
+}
+
+// NewOfferCredential returns an issuer's reply to a proposal, offering the credentials of a credential manifest,
+// which is attached to the offer. The offer's sender is that of the proposal's recipients the issuer replies from,
+// which the caller sets.
+func NewOfferCredential(proposal didcomm.Message, cm manifest.CredentialManifest, body IssuanceBody) (*didcomm.Message, error) {
+	if proposal.Type != ProposeCredentialType {
+		return nil, errors.Errorf("message<%s> of type<%s> is not a proposal", proposal.ID, proposal.Type)
+	}
+	if err := cm.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid credential manifest")
+	}
+	return newReply(proposal, OfferCredentialType, body, CredentialManifestFormat, cm)
+}
+
+// NewRequestCredential returns a holder's reply to an offer, requesting the offered credentials with a credential
+// application for the offer's credential manifest, which is attached to the request. The request's sender is that of
+// the offer's recipients the holder replies from, which the caller sets.
+func NewRequestCredential(offer didcomm.Message, application manifest.CredentialApplicationWrapper, body IssuanceBody) (*didcomm.Message, error) {
+	cm, err := GetCredentialManifest(offer)
+	if err != nil {
+		return nil, err
+	}
+	if application.CredentialApplication.ManifestID != cm.ID {
+		return nil, errors.Errorf("application is for manifest<%s>, not the offered manifest<%s>", application.CredentialApplication.ManifestID, cm.ID)
+	}
+	if err = application.CredentialApplication.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid credential application")
+	}
+	return newReply(offer, RequestCredentialType, body, CredentialApplicationFormat, application)
+}
+
+// NewIssueCredential returns an issuer's reply to a request, issuing credentials with a credential response to the
+// request's credential application, which is attached to the issuance. The response either fulfills the application
+// with its credentials, or denies it. The issuance's sender is that of the request's recipients the issuer replies
+// from, which the caller sets.
+func NewIssueCredential(request didcomm.Message, response manifest.CredentialResponseWrapper, body IssuanceBody) (*didcomm.Message, error) {
+	application, err := GetCredentialApplication(request)
+	if err != nil {
+		return nil, err
+	}
+	applicationID := application.CredentialApplication.ID
+	if response.CredentialResponse.ApplicationID != applicationID {
+		return nil, errors.Errorf("response is to application<%s>, not the requested application<%s>", response.CredentialResponse.ApplicationID, applicationID)
+	}
+	if err = response.CredentialResponse.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid credential response")
+	}
+	return newReply(request, IssueCredentialType, body, CredentialResponseFormat, response)
+}
+
+// GetCredentialManifest returns the credential manifest attached to an offer
+func GetCredentialManifest(offer didcomm.Message) (*manifest.CredentialManifest, error) {
+	var cm manifest.CredentialManifest
+	if err := getAttachment(offer, OfferCredentialType, CredentialManifestFormat, &cm); err != nil {
+		return nil, err
+	}
+	return &cm, nil
+}
+
+// GetCredentialApplication returns the credential application, and the credentials submitted with it, attached to a
+// request
+func GetCredentialApplication(request didcomm.Message) (*manifest.CredentialApplicationWrapper, error) {
+	var application manifest.CredentialApplicationWrapper
+	if err := getAttachment(request, RequestCredentialType, CredentialApplicationFormat, &application); err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+// GetCredentialResponse returns the credential response, and the credentials it fulfills the application with,
+// attached to an issuance
+func GetCredentialResponse(issuance didcomm.Message) (*manifest.CredentialResponseWrapper, error) {
+	var response manifest.CredentialResponseWrapper
+	if err := getAttachment(issuance, IssueCredentialType, CredentialResponseFormat, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ValidateRequestCredential validates the credential application of a request against the credential manifest the
+// issuer offered, as manifest.IsValidCredentialApplicationForManifest does, returning the application and the reason
+// each unfulfilled input descriptor, by id, was not fulfilled. An application which is not valid may be denied with
+// manifest.DenyInvalidCredentialApplication.
+func ValidateRequestCredential(cm manifest.CredentialManifest, request didcomm.Message) (*manifest.CredentialApplicationWrapper, map[string]string, error) {
+	application, err := GetCredentialApplication(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	applicationJSON, err := util.ToJSONMap(application)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "converting credential application to JSON")
+	}
+	unfulfilled, err := manifest.IsValidCredentialApplicationForManifest(cm, applicationJSON)
+	if err != nil {
+		return application, unfulfilled, err
+	}
+	return application, nil, nil
+}
+
+// ValidateIssueCredential validates the credential response of an issuance against the credential manifest the
+// issuer offered, as manifest.IsValidCredentialResponseForManifest does, returning the response and the reason each
+// unfulfilled output descriptor, by id, was not fulfilled
+func ValidateIssueCredential(cm manifest.CredentialManifest, issuance didcomm.Message) (*manifest.CredentialResponseWrapper, map[string]string, error) {
+	response, err := GetCredentialResponse(issuance)
+	if err != nil {
+		return nil, nil, err
+	}
+	unfulfilled, err := manifest.IsValidCredentialResponseForManifest(cm, response.CredentialResponse, response.Credentials)
+	if err != nil {
+		return response, unfulfilled, err
+	}
+	return response, nil, nil
+}
+
+// newReply returns a reply to a message, in its thread, with v attached as JSON of a format
+func newReply(message didcomm.Message, messageType string, body any, format string, v any) (*didcomm.Message, error) {
+	reply, err := message.Reply(messageType, body)
+	if err != nil {
+		return nil, err
+	}
+	attachment, err := didcomm.NewJSONAttachment(format, v)
+	if err != nil {
+		return nil, err
+	}
+	reply.Attachments = []didcomm.Attachment{*attachment}
+	return reply, nil
+}
+
+// getAttachment unmarshals the message's attachment of a format into v, if the message is of the given type
+func getAttachment(message didcomm.Message, messageType, format string, v any) error {
+	if message.Type != messageType {
+		return errors.Errorf("message<%s> of type<%s> is not of type<%s>", message.ID, message.Type, messageType)
+	}
+	for _, attachment := range message.Attachments {
+		if attachment.Format == format {
+			return attachment.GetJSON(v)
+		}
+	}
+	return errors.Errorf("message<%s> has no attachment of format<%s>", message.ID, format)
+}
@@ -0,0 +1,165 @@
+package waci
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/manifest"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/didcomm"
+)
+
+const (
+	testIssuer = "did:example:issuer"
+	testHolder = "did:example:holder"
+)
+
+func TestIssuance(t *testing.T) {
+	cm := getTestCredentialManifest(t)
+
+	// roundTrip sends a message, as JSON, from one party to the other
+	roundTrip := func(tt *testing.T, message *didcomm.Message, from string) didcomm.Message {
+		message.From = from
+		messageBytes, err := json.Marshal(message)
+		require.NoError(tt, err)
+		received, err := didcomm.ParseMessage(messageBytes)
+		require.NoError(tt, err)
+		return *received
+	}
+	getApplication := func(tt *testing.T, manifestID string) manifest.CredentialApplicationWrapper {
+		builder := manifest.NewCredentialApplicationBuilder(manifestID)
+		require.NoError(tt, builder.SetApplicantID(testHolder))
+		require.NoError(tt, builder.SetApplicationClaimFormat(exchange.ClaimFormat{LDPVC: &exchange.LDPType{ProofType: []cryptosuite.SignatureType{jws2020.JSONWebSignature2020}}}))
+		application, err := builder.BuildWrapper()
+		require.NoError(tt, err)
+		return *application
+	}
+	getResponse := func(tt *testing.T, applicationID string) manifest.CredentialResponseWrapper {
+		credBuilder := credential.NewVerifiableCredentialBuilder(credential.GenerateIDValue)
+		require.NoError(tt, credBuilder.SetIssuer(testIssuer))
+		require.NoError(tt, credBuilder.SetCredentialSubject(map[string]any{"id": testHolder}))
+		require.NoError(tt, credBuilder.SetCredentialSchema(credential.CredentialSchema{ID: cm.OutputDescriptors[0].Schema, Type: "JsonSchema"}))
+		cred, err := credBuilder.Build()
+		require.NoError(tt, err)
+		credentials, err := manifest.NewClaimEnvelopes(*cred)
+		require.NoError(tt, err)
+
+		builder := manifest.NewCredentialResponseBuilder(cm.ID)
+		require.NoError(tt, builder.SetApplicantID(testHolder))
+		require.NoError(tt, builder.SetApplicationID(applicationID))
+		require.NoError(tt, builder.SetFulfillment([]exchange.SubmissionDescriptor{
+			{ID: cm.OutputDescriptors[0].ID, Format: exchange.LDPVC.String(), Path: "$.verifiableCredentials[0]"},
+		}))
+		response, err := builder.Build()
+		require.NoError(tt, err)
+		return manifest.CredentialResponseWrapper{CredentialResponse: *response, Credentials: credentials}
+	}
+
+	t.Run("issuance flow", func(tt *testing.T) {
+		proposal, err := NewProposeCredential(testHolder, testIssuer, IssuanceBody{GoalCode: "issue-vc"})
+		require.NoError(tt, err)
+		receivedProposal := roundTrip(tt, proposal, testHolder)
+
+		offer, err := NewOfferCredential(receivedProposal, cm, IssuanceBody{GoalCode: "issue-vc"})
+		require.NoError(tt, err)
+		receivedOffer := roundTrip(tt, offer, testIssuer)
+		assert.Equal(tt, proposal.ID, receivedOffer.Thread())
+		assert.Equal(tt, []string{testHolder}, receivedOffer.To)
+		offeredManifest, err := GetCredentialManifest(receivedOffer)
+		require.NoError(tt, err)
+		assert.Equal(tt, cm.ID, offeredManifest.ID)
+
+		request, err := NewRequestCredential(receivedOffer, getApplication(tt, offeredManifest.ID), IssuanceBody{})
+		require.NoError(tt, err)
+		receivedRequest := roundTrip(tt, request, testHolder)
+		assert.Equal(tt, proposal.ID, receivedRequest.Thread())
+		application, unfulfilled, err := ValidateRequestCredential(cm, receivedRequest)
+		require.NoError(tt, err)
+		assert.Empty(tt, unfulfilled)
+		assert.Equal(tt, testHolder, application.CredentialApplication.Applicant)
+
+		issuance, err := NewIssueCredential(receivedRequest, getResponse(tt, application.CredentialApplication.ID), IssuanceBody{})
+		require.NoError(tt, err)
+		receivedIssuance := roundTrip(tt, issuance, testIssuer)
+		assert.Equal(tt, proposal.ID, receivedIssuance.Thread())
+		response, unfulfilled, err := ValidateIssueCredential(cm, receivedIssuance)
+		require.NoError(tt, err)
+		assert.Empty(tt, unfulfilled)
+		assert.Len(tt, response.Credentials, 1)
+	})
+
+	t.Run("messages out of order", func(tt *testing.T) {
+		proposal, err := NewProposeCredential(testHolder, testIssuer, IssuanceBody{})
+		require.NoError(tt, err)
+		_, err = NewRequestCredential(*proposal, getApplication(tt, cm.ID), IssuanceBody{})
+		assert.ErrorContains(tt, err, "is not of type<"+OfferCredentialType+">")
+		_, err = NewIssueCredential(*proposal, getResponse(tt, "application"), IssuanceBody{})
+		assert.ErrorContains(tt, err, "is not of type<"+RequestCredentialType+">")
+
+		offer, err := NewOfferCredential(*proposal, cm, IssuanceBody{})
+		require.NoError(tt, err)
+		_, err = NewOfferCredential(*offer, cm, IssuanceBody{})
+		assert.ErrorContains(tt, err, "is not a proposal")
+	})
+
+	t.Run("mismatched attachments", func(tt *testing.T) {
+		proposal, err := NewProposeCredential(testHolder, testIssuer, IssuanceBody{})
+		require.NoError(tt, err)
+		offer, err := NewOfferCredential(*proposal, cm, IssuanceBody{})
+		require.NoError(tt, err)
+		offer.From = testIssuer
+
+		// applications must be for the offered manifest
+		_, err = NewRequestCredential(*offer, getApplication(tt, "other-manifest"), IssuanceBody{})
+		assert.ErrorContains(tt, err, "not the offered manifest<"+cm.ID+">")
+
+		// and responses to the requested application
+		request, err := NewRequestCredential(*offer, getApplication(tt, cm.ID), IssuanceBody{})
+		require.NoError(tt, err)
+		request.From = testHolder
+		_, err = NewIssueCredential(*request, getResponse(tt, "other-application"), IssuanceBody{})
+		assert.ErrorContains(tt, err, "not the requested application")
+
+		offer.Attachments = nil
+		_, err = GetCredentialManifest(*offer)
+		assert.ErrorContains(tt, err, "has no attachment of format<"+CredentialManifestFormat+">")
+	})
+
+	t.Run("invalid credential responses", func(tt *testing.T) {
+		proposal, err := NewProposeCredential(testHolder, testIssuer, IssuanceBody{})
+		require.NoError(tt, err)
+		offer, err := NewOfferCredential(*proposal, cm, IssuanceBody{})
+		require.NoError(tt, err)
+		offer.From = testIssuer
+		request, err := NewRequestCredential(*offer, getApplication(tt, cm.ID), IssuanceBody{})
+		require.NoError(tt, err)
+		request.From = testHolder
+		application, err := GetCredentialApplication(*request)
+		require.NoError(tt, err)
+
+		response := getResponse(tt, application.CredentialApplication.ID)
+		response.CredentialResponse.Fulfillment.DescriptorMap[0].Path = "$.verifiableCredentials[1]"
+		issuance, err := NewIssueCredential(*request, response, IssuanceBody{})
+		require.NoError(tt, err)
+		_, unfulfilled, err := ValidateIssueCredential(cm, *issuance)
+		assert.ErrorContains(tt, err, "<1>unfulfilled output descriptor(s)")
+		assert.Contains(tt, unfulfilled, cm.OutputDescriptors[0].ID)
+	})
+}
+
+func getTestCredentialManifest(t *testing.T) manifest.CredentialManifest {
+	builder := manifest.NewCredentialManifestBuilder()
+	require.NoError(t, builder.SetIssuer(manifest.Issuer{ID: testIssuer, Name: "Example Issuer"}))
+	require.NoError(t, builder.SetOutputDescriptors([]manifest.OutputDescriptor{
+		{ID: "kyc_credential", Schema: "https://example.com/schemas/kyc.json", Name: "KYC Credential"},
+	}))
+	cm, err := builder.Build()
+	require.NoError(t, err)
+	return *cm
+}
@@ -0,0 +1,193 @@
+package waci
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/didcomm"
+)
+
+// WACI-DIDComm shares presentations with the Present Proof 3.0 protocol, in a thread in which the holder proposes
+// sharing a presentation, the verifier requests one fulfilling a presentation definition, bound to a challenge and
+// domain, and the holder presents a JWT presentation whose presentation submission fulfills the definition.
+// https://identity.foundation/waci-didcomm/#present-proof-v3
+// https://github.com/decentralized-identity/waci-didcomm/blob/main/present_proof/README.md
+
+const (
+	// PresentProofProtocol is the URI of the Present Proof 3.0 protocol, which its message types are in
+	PresentProofProtocol string = "https://didcomm.org/present-proof/3.0"
+
+	ProposePresentationType string = PresentProofProtocol + "/propose-presentation"
+	RequestPresentationType string = PresentProofProtocol + "/request-presentation"
+	PresentationType        string = PresentProofProtocol + "/presentation"
+
+	// PresentationDefinitionFormat and PresentationSubmissionFormat are the formats of the attachments of requests,
+	// and presentations
+	PresentationDefinitionFormat string = "dif/presentation-exchange/definitions@v1.0"
+	PresentationSubmissionFormat string = "dif/presentation-exchange/submission@v1.0"
+
+	jwtMediaType string = "application/jwt"
+)
+
+// PresentationBody is the body of the messages of the presentation protocol
+type PresentationBody struct {
+	// GoalCode is the goal of the message, such as "streamlined-vp"
+	GoalCode string `json:"goal_code,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// PresentationRequest is the attachment of a request, the presentation definition the presentation must fulfill and
+// the options it must be bound to
+type PresentationRequest struct {
+	Options                PresentationRequestOptions      `json:"options"`
+	PresentationDefinition exchange.PresentationDefinition `json:"presentation_definition"`
+}
+
+// PresentationRequestOptions bind a presentation to its request, preventing it from being replayed
+type PresentationRequestOptions struct {
+	Challenge string `json:"challenge" validate:"required"`
+	Domain    string `json:"domain,omitempty"`
+}
+
+// NewProposePresentation returns a holder's proposal to share a presentation with a verifier, which starts a thread.
+// The thread of an out-of-band invitation the proposal answers is its parent thread, which the caller sets.
+func NewProposePresentation(from, to string, body PresentationBody) (*didcomm.Message, error) {
+	proposal, err := didcomm.NewMessage(ProposePresentationType, body)
+	if err != nil {
+		return nil, err
+	}
+	proposal.From = from
+	proposal.To = []string{to}
+	if err = proposal.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid proposal")
+	}
+	return proposal, nil
+}
+
+// NewRequestPresentation returns a verifier's reply to a proposal, requesting a presentation fulfilling a
+// presentation definition, bound to a random challenge and the verifier's domain, which is attached to the request.
+// The request's sender is that of the proposal's recipients the verifier replies from, which the caller sets.
+func NewRequestPresentation(proposal didcomm.Message, def exchange.PresentationDefinition, domain string, body PresentationBody) (*didcomm.Message, error) {
+	if proposal.Type != ProposePresentationType {
+		return nil, errors.Errorf("message<%s> of type<%s> is not a proposal", proposal.ID, proposal.Type)
+	}
+	if err := def.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid presentation definition")
+	}
+	request := PresentationRequest{
+		Options:                PresentationRequestOptions{Challenge: uuid.NewString(), Domain: domain},
+		PresentationDefinition: def,
+	}
+	return newReply(proposal, RequestPresentationType, body, PresentationDefinitionFormat, request)
+}
+
+// NewPresentation returns a holder's reply to a request, presenting the claims which fulfill the request's
+// presentation definition, which the presentation exchange matching engine selects from the given claims, in a JWT
+// presentation signed by the holder and bound to the request's options, which is attached to the presentation. The
+// presentation's sender is that of the request's recipients the holder replies from, which the caller sets.
+func NewPresentation(request didcomm.Message, signer jwx.Signer, claims []exchange.PresentationClaim, body PresentationBody) (*didcomm.Message, error) {
+	presentationRequest, err := GetPresentationRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := exchange.NormalizePresentationClaims(claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "normalizing claims")
+	}
+	vp, err := exchange.BuildPresentationSubmissionVP(signer.ID, presentationRequest.PresentationDefinition, normalized)
+	if err != nil {
+		return nil, errors.Wrap(err, "building presentation")
+	}
+	params := integrity.JWTVVPParameters{
+		Challenge: presentationRequest.Options.Challenge,
+		Domain:    presentationRequest.Options.Domain,
+	}
+	vpJWT, err := integrity.SignVerifiablePresentationJWT(signer, &params, *vp)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing presentation")
+	}
+
+	presentation, err := request.Reply(PresentationType, body)
+	if err != nil {
+		return nil, err
+	}
+	attachment, err := didcomm.NewBase64Attachment(PresentationSubmissionFormat, jwtMediaType, vpJWT)
+	if err != nil {
+		return nil, err
+	}
+	presentation.Attachments = []didcomm.Attachment{*attachment}
+	return presentation, nil
+}
+
+// GetPresentationRequest returns the presentation definition, and the options, attached to a request, which must
+// have a challenge
+func GetPresentationRequest(request didcomm.Message) (*PresentationRequest, error) {
+	var presentationRequest PresentationRequest
+	if err := getAttachment(request, RequestPresentationType, PresentationDefinitionFormat, &presentationRequest); err != nil {
+		return nil, err
+	}
+	if presentationRequest.Options.Challenge == "" {
+		return nil, errors.Errorf("request<%s> has no challenge", request.ID)
+	}
+	return &presentationRequest, nil
+}
+
+// VerifyPresentation verifies a presentation replying to a verifier's request: the presentation is in the request's
+// thread, the signature of its JWT presentation, and of the credentials it contains, is verified, the presentation is
+// signed by the message's sender, it is bound to the request's challenge and domain, and its presentation submission
+// fulfills the request's presentation definition. The verified data of each input descriptor is returned. Options
+// are applied when verifying the presentation submission, such as status checks.
+func VerifyPresentation(ctx context.Context, resolver resolution.Resolver, request, presentation didcomm.Message, opts ...exchange.VerificationOption) ([]exchange.VerifiedSubmissionData, error) {
+	presentationRequest, err := GetPresentationRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	if presentation.Type != PresentationType {
+		return nil, errors.Errorf("message<%s> of type<%s> is not of type<%s>", presentation.ID, presentation.Type, PresentationType)
+	}
+	if presentation.Thread() != request.Thread() {
+		return nil, errors.Errorf("presentation<%s> is not in the thread<%s> of the request", presentation.ID, request.Thread())
+	}
+	var vpJWT []byte
+	for _, attachment := range presentation.Attachments {
+		if attachment.Format == PresentationSubmissionFormat {
+			if vpJWT, err = attachment.Bytes(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if len(vpJWT) == 0 {
+		return nil, errors.Errorf("message<%s> has no attachment of format<%s>", presentation.ID, PresentationSubmissionFormat)
+	}
+
+	signatureOpts := []integrity.VerificationOption{integrity.WithChallenge(presentationRequest.Options.Challenge)}
+	if presentationRequest.Options.Domain != "" {
+		signatureOpts = append(signatureOpts, integrity.WithDomain(presentationRequest.Options.Domain))
+	}
+	verified, err := integrity.VerifyPresentationSignature(ctx, string(vpJWT), resolver, signatureOpts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying presentation")
+	}
+	if !verified {
+		return nil, errors.New("presentation failed signature validation")
+	}
+	_, _, vp, err := integrity.ParseVerifiablePresentationFromJWT(string(vpJWT))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing presentation")
+	}
+	if vp.Holder != presentation.From {
+		return nil, errors.Errorf("presentation<%s> is signed by<%s>, not its sender<%s>", presentation.ID, vp.Holder, presentation.From)
+	}
+	verifiedData, err := exchange.VerifyPresentationSubmissionVP(presentationRequest.PresentationDefinition, *vp, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying presentation submission")
+	}
+	return verifiedData, nil
+}
@@ -0,0 +1,160 @@
+package waci
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/didcomm"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+func TestPresentation(t *testing.T) {
+	ctx := context.Background()
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	issuer := getTestSigner(t)
+	holder := getTestSigner(t)
+	verifier := getTestSigner(t)
+	def := exchange.PresentationDefinition{
+		ID: "test-definition",
+		InputDescriptors: []exchange.InputDescriptor{{
+			ID: "name-descriptor",
+			Constraints: &exchange.Constraints{
+				Fields: []exchange.Field{{
+					Path:   []string{"$.credentialSubject.name", "$.vc.credentialSubject.name"},
+					Filter: &exchange.Filter{Type: "string", Const: "Alice"},
+				}},
+			},
+		}},
+	}
+
+	getClaim := func(tt *testing.T, name string) exchange.PresentationClaim {
+		cred := credential.VerifiableCredential{
+			Context:           []any{credential.VerifiableCredentialsLinkedDataContext},
+			ID:                "test-credential",
+			Type:              []string{credential.VerifiableCredentialType},
+			Issuer:            issuer.ID,
+			IssuanceDate:      util.GetRFC3339Timestamp(),
+			CredentialSubject: credential.CredentialSubject{"id": holder.ID, "name": name},
+		}
+		credJWT, err := integrity.SignVerifiableCredentialJWT(issuer, cred)
+		require.NoError(tt, err)
+		return exchange.PresentationClaim{
+			Token:                         util.StringPtr(string(credJWT)),
+			JWTFormat:                     exchange.JWTVC.Ptr(),
+			SignatureAlgorithmOrProofType: issuer.ALG,
+		}
+	}
+	getRequest := func(tt *testing.T, domain string) didcomm.Message {
+		proposal, err := NewProposePresentation(holder.ID, verifier.ID, PresentationBody{GoalCode: "streamlined-vp"})
+		require.NoError(tt, err)
+		request, err := NewRequestPresentation(*proposal, def, domain, PresentationBody{})
+		require.NoError(tt, err)
+		request.From = verifier.ID
+		assert.Equal(tt, proposal.ID, request.Thread())
+		return *request
+	}
+
+	t.Run("presentation flow", func(tt *testing.T) {
+		request := getRequest(tt, "https://verifier.example.com")
+		presentationRequest, err := GetPresentationRequest(request)
+		require.NoError(tt, err)
+		assert.Equal(tt, def.ID, presentationRequest.PresentationDefinition.ID)
+		assert.NotEmpty(tt, presentationRequest.Options.Challenge)
+
+		// the matching engine selects the claim fulfilling the definition
+		claims := []exchange.PresentationClaim{getClaim(tt, "Bob"), getClaim(tt, "Alice")}
+		presentation, err := NewPresentation(request, holder, claims, PresentationBody{})
+		require.NoError(tt, err)
+		presentation.From = holder.ID
+		assert.Equal(tt, request.Thread(), presentation.Thread())
+
+		verified, err := VerifyPresentation(ctx, resolver, request, *presentation)
+		require.NoError(tt, err)
+		require.Len(tt, verified, 1)
+		assert.Equal(tt, "name-descriptor", verified[0].InputDescriptorID)
+	})
+
+	t.Run("presentations without a domain", func(tt *testing.T) {
+		request := getRequest(tt, "")
+		presentation, err := NewPresentation(request, holder, []exchange.PresentationClaim{getClaim(tt, "Alice")}, PresentationBody{})
+		require.NoError(tt, err)
+		presentation.From = holder.ID
+		_, err = VerifyPresentation(ctx, resolver, request, *presentation)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("presentations of another sender", func(tt *testing.T) {
+		request := getRequest(tt, "https://verifier.example.com")
+		presentation, err := NewPresentation(request, holder, []exchange.PresentationClaim{getClaim(tt, "Alice")}, PresentationBody{})
+		require.NoError(tt, err)
+		presentation.From = verifier.ID
+		_, err = VerifyPresentation(ctx, resolver, request, *presentation)
+		assert.ErrorContains(tt, err, "not its sender<"+verifier.ID+">")
+
+		presentation.From = ""
+		_, err = VerifyPresentation(ctx, resolver, request, *presentation)
+		assert.ErrorContains(tt, err, "is signed by<"+holder.ID+">")
+	})
+
+	t.Run("unfulfilled definitions", func(tt *testing.T) {
+		request := getRequest(tt, "")
+		_, err := NewPresentation(request, holder, []exchange.PresentationClaim{getClaim(tt, "Bob")}, PresentationBody{})
+		assert.ErrorContains(tt, err, "could not be fulfilled")
+	})
+
+	t.Run("presentations for other requests", func(tt *testing.T) {
+		request := getRequest(tt, "https://verifier.example.com")
+		presentation, err := NewPresentation(request, holder, []exchange.PresentationClaim{getClaim(tt, "Alice")}, PresentationBody{})
+		require.NoError(tt, err)
+		presentation.From = holder.ID
+
+		_, err = VerifyPresentation(ctx, resolver, getRequest(tt, "https://verifier.example.com"), *presentation)
+		assert.ErrorContains(tt, err, "is not in the thread")
+
+		// a request in the same thread with another challenge
+		replayed := request
+		presentationRequest, err := GetPresentationRequest(request)
+		require.NoError(tt, err)
+		presentationRequest.Options.Challenge = "other-challenge"
+		attachment, err := didcomm.NewJSONAttachment(PresentationDefinitionFormat, presentationRequest)
+		require.NoError(tt, err)
+		replayed.Attachments = []didcomm.Attachment{*attachment}
+		_, err = VerifyPresentation(ctx, resolver, replayed, *presentation)
+		assert.ErrorContains(tt, err, "challenge mismatch")
+
+		_, err = VerifyPresentation(ctx, resolver, request, request)
+		assert.ErrorContains(tt, err, "is not of type<"+PresentationType+">")
+	})
+
+	t.Run("requests without challenges", func(tt *testing.T) {
+		request := getRequest(tt, "")
+		attachment, err := didcomm.NewJSONAttachment(PresentationDefinitionFormat, PresentationRequest{PresentationDefinition: def})
+		require.NoError(tt, err)
+		request.Attachments = []didcomm.Attachment{*attachment}
+		_, err = NewPresentation(request, holder, []exchange.PresentationClaim{getClaim(tt, "Alice")}, PresentationBody{})
+		assert.ErrorContains(tt, err, "has no challenge")
+	})
+}
+
+// getTestSigner returns the signer of a new did:key, whose KID is the DID's verification method
+func getTestSigner(t *testing.T) jwx.Signer {
+	privateKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privateKey)
+	require.NoError(t, err)
+	return *signer
+}
@@ -258,9 +258,14 @@ func prepareCredentialApplication(cm manifest.CredentialManifest, vc credential.
 		return nil, err
 	}
 
+	credentials, err := manifest.NewClaimEnvelopes(vc)
+	if err != nil {
+		return nil, err
+	}
+
 	return &manifest.CredentialApplicationWrapper{
 		CredentialApplication: *application,
-		Credentials:           []any{vc},
+		Credentials:           credentials,
 	}, nil
 }
 
@@ -325,7 +330,11 @@ func processCredentialApplication(cm manifest.CredentialManifest, ca manifest.Cr
 	}
 
 	// if it is, we can issue a credential
-	applicantCredential := ca.Credentials[0].(credential.VerifiableCredential)
+	parsedCredential, err := ca.Credentials[0].Parse()
+	if err != nil {
+		return nil, err
+	}
+	applicantCredential := parsedCredential.Credential
 	data := driversLicenseFields{
 		FirstName:   applicantCredential.CredentialSubject["firstName"].(string),
 		LastName:    applicantCredential.CredentialSubject["lastName"].(string),
@@ -355,9 +364,14 @@ func processCredentialApplication(cm manifest.CredentialManifest, ca manifest.Cr
 		return nil, err
 	}
 
+	credentials, err := manifest.NewClaimEnvelopes(licenseCredential)
+	if err != nil {
+		return nil, err
+	}
+
 	return &manifest.CredentialResponseWrapper{
 		CredentialResponse: *credentialResponse,
-		Credentials:        []any{licenseCredential},
+		Credentials:        credentials,
 	}, nil
 }
 
@@ -3,10 +3,10 @@ module github.com/TBD54566975/ssi-sdk
 go 1.23
 
 require (
-	github.com/bits-and-blooms/bitset v1.14.3
 	github.com/btcsuite/btcd/btcec/v2 v2.3.4
 	github.com/cloudflare/circl v1.4.0
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.3.0
+	github.com/fxamacker/cbor/v2 v2.5.0
 	github.com/go-playground/validator/v10 v10.22.1
 	github.com/goccy/go-json v0.10.3
 	github.com/google/uuid v1.6.0
@@ -27,6 +27,7 @@ require (
 	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/sirupsen/logrus v1.9.3
 	github.com/stretchr/testify v1.9.0
+	github.com/veraison/go-cose v1.3.0
 	golang.org/x/term v0.24.0
 	golang.org/x/text v0.18.0
 	gopkg.in/h2non/gock.v1 v1.1.2
@@ -60,6 +61,7 @@ require (
 	github.com/rogpeppe/go-internal v1.13.1 // indirect
 	github.com/segmentio/asm v1.2.0 // indirect
 	github.com/spaolacci/murmur3 v1.1.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/crypto v0.27.0 // indirect
 	golang.org/x/net v0.29.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
@@ -0,0 +1,314 @@
+package dpop
+
+import (
+	gocrypto "crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// Demonstrating Proof of Possession (DPoP) sender-constrains OAuth 2.0 access tokens, binding them to a key the
+// client proves possession of with a proof JWT sent with each request
+// https://www.rfc-editor.org/rfc/rfc9449.html
+
+const (
+	// ProofJWTType is the `typ` header of DPoP proofs
+	ProofJWTType string = "dpop+jwt"
+	// HeaderName is the HTTP header DPoP proofs are sent in
+	HeaderName string = "DPoP"
+	// NonceHeaderName is the HTTP header servers send the nonce proofs must be bound to in
+	NonceHeaderName string = "DPoP-Nonce"
+	// TokenType is the type of access tokens bound to a key, and the scheme of the Authorization header they are sent
+	// with
+	TokenType string = "DPoP"
+
+	htmClaim   = "htm"
+	htuClaim   = "htu"
+	athClaim   = "ath"
+	nonceClaim = "nonce"
+
+	defaultMaxAge = 5 * time.Minute
+	defaultLeeway = time.Minute
+)
+
+// ErrUseNonce is returned when a proof is not bound to any of the nonces of the server, which it must answer with
+// its current nonce and the use_dpop_nonce error
+var ErrUseNonce = errors.New("proof is not bound to a nonce of the server")
+
+// NewSigner generates a key of the given type for a client to prove possession of, returning its signer, identified
+// by the thumbprint of its public key. The key type must be that of a signature algorithm.
+func NewSigner(kt crypto.KeyType) (*jwx.Signer, error) {
+	_, privateKey, err := crypto.GenerateKeyByKeyType(kt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "generating %s key", kt)
+	}
+	publicKeyJWK, _, err := jwx.PrivateKeyToPrivateKeyJWK(nil, privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting private key to JWK")
+	}
+	if !jwx.IsSupportedJWXSigningVerificationAlgorithm(publicKeyJWK.ALG) {
+		return nil, errors.Errorf("key type<%s> is not supported for DPoP", kt)
+	}
+	thumbprint, err := publicKeyJWK.Thumbprint()
+	if err != nil {
+		return nil, errors.Wrap(err, "computing thumbprint")
+	}
+	return jwx.NewJWXSigner(thumbprint, nil, privateKey)
+}
+
+// ProofOptions configures a DPoP proof
+type ProofOptions struct {
+	// Nonce is the latest nonce of the server, if it sent one
+	Nonce string
+	// AccessToken is the access token the request is made with, for requests to protected resources
+	AccessToken string
+}
+
+// CreateProof returns a DPoP proof of possession of the signer's key, for a request of the given method to the given
+// URI. The public key is given as the proof's jwk header.
+func CreateProof(signer jwx.Signer, method, uri string, opts ProofOptions) (string, error) {
+	htu, err := targetURI(uri)
+	if err != nil {
+		return "", err
+	}
+	t := jwt.New()
+	if err = t.Set(jwt.JwtIDKey, uuid.NewString()); err != nil {
+		return "", errors.Wrap(err, "setting jti")
+	}
+	if err = t.Set(htmClaim, method); err != nil {
+		return "", errors.Wrap(err, "setting htm")
+	}
+	if err = t.Set(htuClaim, htu); err != nil {
+		return "", errors.Wrap(err, "setting htu")
+	}
+	if err = t.Set(jwt.IssuedAtKey, time.Now()); err != nil {
+		return "", errors.Wrap(err, "setting iat")
+	}
+	if opts.Nonce != "" {
+		if err = t.Set(nonceClaim, opts.Nonce); err != nil {
+			return "", errors.Wrap(err, "setting nonce")
+		}
+	}
+	if opts.AccessToken != "" {
+		if err = t.Set(athClaim, accessTokenHash(opts.AccessToken)); err != nil {
+			return "", errors.Wrap(err, "setting ath")
+		}
+	}
+
+	publicKeyJWK := signer.PrivateKeyJWK.ToPublicKeyJWK()
+	publicKey, err := publicKeyJWK.ToPublicKey()
+	if err != nil {
+		return "", errors.Wrap(err, "getting public key")
+	}
+	key, err := jwk.FromRaw(publicKey)
+	if err != nil {
+		return "", errors.Wrap(err, "creating jwk")
+	}
+	hdrs := jws.NewHeaders()
+	if err = hdrs.Set(jws.TypeKey, ProofJWTType); err != nil {
+		return "", errors.Wrap(err, "setting typ")
+	}
+	if err = hdrs.Set(jws.JWKKey, key); err != nil {
+		return "", errors.Wrap(err, "setting jwk")
+	}
+
+	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+	alg := signer.ALG
+	if alg == "Ed25519" {
+		alg = jwa.EdDSA.String()
+	}
+	signed, err := jwt.Sign(t, jwt.WithKey(jwa.SignatureAlgorithm(alg), signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	if err != nil {
+		return "", errors.Wrap(err, "signing proof")
+	}
+	return string(signed), nil
+}
+
+// Proof is a verified DPoP proof
+type Proof struct {
+	// JWK is the public key the proof proves possession of
+	JWK jwx.PublicKeyJWK
+	// Thumbprint is the JWK SHA-256 thumbprint of the key, which access tokens are bound to as their `jkt`
+	Thumbprint string
+	ID         string
+	IssuedAt   time.Time
+}
+
+// VerificationOptions configures the verification of a DPoP proof
+type VerificationOptions struct {
+	// Nonces are the nonces of the server the proof may be bound to; if any is given, the proof must be bound to one
+	Nonces []string
+	// AccessToken is the access token of a request to a protected resource, whose hash the proof must carry
+	AccessToken string
+	// Thumbprint is that of the key the access token is bound to, which the proof must be of
+	Thumbprint string
+	// MaxAge bounds how long ago proofs may have been issued, and defaults to 5 minutes; Leeway tolerates clock skew,
+	// and defaults to a minute
+	MaxAge time.Duration
+	Leeway time.Duration
+	// ReplayCache, if given, rejects proofs which were already accepted
+	ReplayCache ReplayCache
+}
+
+// VerifyProof verifies a DPoP proof of a request of the given method to the given URI, returning the verified proof.
+// Proofs which are not bound to any of the server's nonces fail with ErrUseNonce.
+func VerifyProof(proof, method, uri string, opts VerificationOptions) (*Proof, error) {
+	if opts.MaxAge == 0 {
+		opts.MaxAge = defaultMaxAge
+	}
+	if opts.Leeway == 0 {
+		opts.Leeway = defaultLeeway
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(proof))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting proof headers")
+	}
+	if headers.Type() != ProofJWTType {
+		return nil, errors.Errorf("proof typ<%s> must be %s", headers.Type(), ProofJWTType)
+	}
+	if alg := headers.Algorithm(); alg == jwa.NoSignature || strings.HasPrefix(alg.String(), "HS") {
+		return nil, errors.Errorf("proof alg<%s> must be asymmetric", alg)
+	}
+	key := headers.JWK()
+	if key == nil {
+		return nil, errors.New("proof must have a jwk header")
+	}
+	var rawKey any
+	if err = key.Raw(&rawKey); err != nil {
+		return nil, errors.Wrap(err, "getting proof jwk")
+	}
+	if _, isPrivate := rawKey.(gocrypto.Signer); isPrivate {
+		return nil, errors.New("proof jwk must not be a private key")
+	}
+	verifier, err := jwx.NewJWXVerifier(ProofJWTType, nil, rawKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "proof jwk")
+	}
+	_, token, err := verifier.VerifyAndParse(proof)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying proof")
+	}
+	thumbprint, err := verifier.PublicKeyJWK.Thumbprint()
+	if err != nil {
+		return nil, errors.Wrap(err, "computing thumbprint")
+	}
+
+	if token.JwtID() == "" {
+		return nil, errors.New("proof must have a jti")
+	}
+	if htm, _ := token.Get(htmClaim); htm != method {
+		return nil, errors.Errorf("proof htm<%v> is not the request's method<%s>", htm, method)
+	}
+	htu, _ := token.Get(htuClaim)
+	htuString, _ := htu.(string)
+	proofURI, err := targetURI(htuString)
+	if err != nil {
+		return nil, errors.Wrap(err, "proof htu")
+	}
+	requestURI, err := targetURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if proofURI != requestURI {
+		return nil, errors.Errorf("proof htu<%s> is not the request's uri<%s>", proofURI, requestURI)
+	}
+	now := time.Now()
+	if token.IssuedAt().IsZero() || token.IssuedAt().After(now.Add(opts.Leeway)) ||
+		token.IssuedAt().Before(now.Add(-opts.MaxAge-opts.Leeway)) {
+		return nil, errors.New("proof iat is not valid")
+	}
+	if len(opts.Nonces) > 0 {
+		nonce, _ := token.Get(nonceClaim)
+		nonceString, _ := nonce.(string)
+		if nonceString == "" || !util.Contains(nonceString, opts.Nonces) {
+			return nil, ErrUseNonce
+		}
+	}
+	if opts.AccessToken != "" {
+		if ath, _ := token.Get(athClaim); ath != accessTokenHash(opts.AccessToken) {
+			return nil, errors.New("proof ath is not the hash of the access token")
+		}
+	}
+	if opts.Thumbprint != "" && thumbprint != opts.Thumbprint {
+		return nil, errors.New("proof key is not the key the access token is bound to")
+	}
+	if opts.ReplayCache != nil && !opts.ReplayCache.Add(thumbprint+"#"+token.JwtID(), token.IssuedAt().Add(opts.MaxAge+opts.Leeway)) {
+		return nil, errors.Errorf("proof<%s> was replayed", token.JwtID())
+	}
+	return &Proof{
+		JWK:        verifier.PublicKeyJWK,
+		Thumbprint: thumbprint,
+		ID:         token.JwtID(),
+		IssuedAt:   token.IssuedAt(),
+	}, nil
+}
+
+// targetURI returns the URI of a request without its query and fragment, which proofs are bound to
+func targetURI(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing uri<%s>", uri)
+	}
+	if !parsed.IsAbs() || parsed.Host == "" {
+		return "", errors.Errorf("uri<%s> must be absolute", uri)
+	}
+	parsed.RawQuery, parsed.Fragment, parsed.RawFragment = "", "", ""
+	parsed.Scheme, parsed.Host = strings.ToLower(parsed.Scheme), strings.ToLower(parsed.Host)
+	return parsed.String(), nil
+}
+
+// accessTokenHash returns the `ath` of an access token: the base64url encoded SHA-256 hash of its ASCII encoding
+func accessTokenHash(accessToken string) string {
+	hash := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// ReplayCache records the proofs a server accepted, such that each is accepted once
+type ReplayCache interface {
+	// Add records a proof until it expires, returning false if it was already recorded
+	Add(id string, expiresAt time.Time) bool
+}
+
+// MemoryReplayCache is a ReplayCache holding proofs in memory
+type MemoryReplayCache struct {
+	mu     sync.Mutex
+	proofs map[string]time.Time
+}
+
+var _ ReplayCache = (*MemoryReplayCache)(nil)
+
+// NewMemoryReplayCache returns an empty MemoryReplayCache
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{proofs: make(map[string]time.Time)}
+}
+
+// Add records a proof until it expires, removing the proofs which expired
+func (c *MemoryReplayCache) Add(id string, expiresAt time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for recorded, recordedExpiresAt := range c.proofs {
+		if now.After(recordedExpiresAt) {
+			delete(c.proofs, recorded)
+		}
+	}
+	if _, ok := c.proofs[id]; ok {
+		return false
+	}
+	c.proofs[id] = expiresAt
+	return true
+}
@@ -0,0 +1,132 @@
+package dpop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+const testURI = "https://server.example.com/token"
+
+func TestProof(t *testing.T) {
+	t.Run("key types", func(tt *testing.T) {
+		for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.SECP256k1, crypto.P256, crypto.P384, crypto.RSA} {
+			signer, err := NewSigner(kt)
+			require.NoError(tt, err, kt)
+			proof, err := CreateProof(*signer, "POST", testURI+"?query#fragment", ProofOptions{})
+			require.NoError(tt, err, kt)
+			verified, err := VerifyProof(proof, "POST", testURI, VerificationOptions{})
+			require.NoError(tt, err, kt)
+			assert.Equal(tt, signer.ID, verified.Thumbprint, kt)
+			assert.NotEmpty(tt, verified.ID, kt)
+		}
+
+		_, err := NewSigner(crypto.X25519)
+		assert.ErrorContains(tt, err, "key type<X25519> is not supported for DPoP")
+	})
+
+	t.Run("method and uri", func(tt *testing.T) {
+		signer, err := NewSigner(crypto.P256)
+		require.NoError(tt, err)
+		proof, err := CreateProof(*signer, "POST", testURI, ProofOptions{})
+		require.NoError(tt, err)
+
+		_, err = VerifyProof(proof, "GET", testURI, VerificationOptions{})
+		assert.ErrorContains(tt, err, "proof htm<POST> is not the request's method<GET>")
+		_, err = VerifyProof(proof, "POST", "https://server.example.com/credential", VerificationOptions{})
+		assert.ErrorContains(tt, err, "is not the request's uri<https://server.example.com/credential>")
+	})
+
+	t.Run("access tokens and nonces", func(tt *testing.T) {
+		signer, err := NewSigner(crypto.Ed25519)
+		require.NoError(tt, err)
+		proof, err := CreateProof(*signer, "POST", testURI, ProofOptions{Nonce: "nonce", AccessToken: "token"})
+		require.NoError(tt, err)
+
+		_, err = VerifyProof(proof, "POST", testURI, VerificationOptions{Nonces: []string{"old", "nonce"}, AccessToken: "token", Thumbprint: signer.ID})
+		assert.NoError(tt, err)
+		_, err = VerifyProof(proof, "POST", testURI, VerificationOptions{Nonces: []string{"other"}})
+		assert.ErrorIs(tt, err, ErrUseNonce)
+		_, err = VerifyProof(proof, "POST", testURI, VerificationOptions{AccessToken: "other"})
+		assert.ErrorContains(tt, err, "proof ath is not the hash of the access token")
+
+		other, err := NewSigner(crypto.Ed25519)
+		require.NoError(tt, err)
+		_, err = VerifyProof(proof, "POST", testURI, VerificationOptions{Thumbprint: other.ID})
+		assert.ErrorContains(tt, err, "proof key is not the key the access token is bound to")
+	})
+
+	t.Run("replays", func(tt *testing.T) {
+		signer, err := NewSigner(crypto.Ed25519)
+		require.NoError(tt, err)
+		proof, err := CreateProof(*signer, "POST", testURI, ProofOptions{})
+		require.NoError(tt, err)
+
+		cache := NewMemoryReplayCache()
+		_, err = VerifyProof(proof, "POST", testURI, VerificationOptions{ReplayCache: cache})
+		assert.NoError(tt, err)
+		_, err = VerifyProof(proof, "POST", testURI, VerificationOptions{ReplayCache: cache})
+		assert.ErrorContains(tt, err, "was replayed")
+	})
+
+	t.Run("invalid proofs", func(tt *testing.T) {
+		signer, err := NewSigner(crypto.Ed25519)
+		require.NoError(tt, err)
+		publicKeyJWK := signer.PrivateKeyJWK.ToPublicKeyJWK()
+		publicKey, err := publicKeyJWK.ToPublicKey()
+		require.NoError(tt, err)
+		key, err := jwk.FromRaw(publicKey)
+		require.NoError(tt, err)
+
+		sign := func(typ string, iat time.Time) string {
+			token := jwt.New()
+			require.NoError(tt, token.Set(jwt.JwtIDKey, "id"))
+			require.NoError(tt, token.Set(htmClaim, "POST"))
+			require.NoError(tt, token.Set(htuClaim, testURI))
+			require.NoError(tt, token.Set(jwt.IssuedAtKey, iat))
+			hdrs := jws.NewHeaders()
+			require.NoError(tt, hdrs.Set(jws.TypeKey, typ))
+			require.NoError(tt, hdrs.Set(jws.JWKKey, key))
+			signed, err := jwt.Sign(token, jwt.WithKey(jwa.EdDSA, signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+			require.NoError(tt, err)
+			return string(signed)
+		}
+
+		_, err = VerifyProof(sign("JWT", time.Now()), "POST", testURI, VerificationOptions{})
+		assert.ErrorContains(tt, err, "proof typ<JWT> must be dpop+jwt")
+		_, err = VerifyProof(sign(ProofJWTType, time.Now().Add(-time.Hour)), "POST", testURI, VerificationOptions{})
+		assert.ErrorContains(tt, err, "proof iat is not valid")
+
+		// the jwk header must not disclose the private key
+		other, err := NewSigner(crypto.Ed25519)
+		require.NoError(tt, err)
+		otherKey, err := jwk.FromRaw(other.PrivateKey)
+		require.NoError(tt, err)
+		hdrs := jws.NewHeaders()
+		require.NoError(tt, hdrs.Set(jws.TypeKey, ProofJWTType))
+		require.NoError(tt, hdrs.Set(jws.JWKKey, otherKey))
+		signed, err := jwt.Sign(jwt.New(), jwt.WithKey(jwa.EdDSA, signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+		require.NoError(tt, err)
+		_, err = VerifyProof(string(signed), "POST", testURI, VerificationOptions{})
+		assert.ErrorContains(tt, err, "proof jwk must not be a private key")
+	})
+}
+
+func TestMemoryReplayCache(t *testing.T) {
+	cache := NewMemoryReplayCache()
+	assert.True(t, cache.Add("expired", time.Now().Add(-time.Second)))
+	assert.True(t, cache.Add("id", time.Now().Add(time.Minute)))
+	assert.False(t, cache.Add("id", time.Now().Add(time.Minute)))
+
+	// expired proofs are removed
+	assert.True(t, cache.Add("expired", time.Now().Add(time.Minute)))
+	assert.Len(t, cache.proofs, 2)
+}
@@ -0,0 +1,225 @@
+package federation
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxResponseSize bounds the size of the responses the client reads
+	maxResponseSize = 1 << 20
+	// maxChainLength bounds the number of superiors between an entity and a trust anchor
+	maxChainLength = 8
+
+	subParameter = "sub"
+)
+
+// TrustAnchor is an entity whose keys are known in advance, at the top of trust chains
+type TrustAnchor struct {
+	EntityID string
+	JWKS     JWKS
+}
+
+// TrustChain is a verified chain of entity statements from an entity's configuration to a trust anchor
+// https://openid.net/specs/openid-federation-1_0.html#name-trust-chain
+type TrustChain struct {
+	// Statements are the entity's configuration, followed by the subordinate statement of each superior, up to that
+	// of the trust anchor
+	Statements []string
+	// Entity is the entity's configuration, with its metadata
+	Entity      EntityStatement
+	TrustAnchor string
+	// ExpiresAt is when the first statement of the chain expires
+	ExpiresAt time.Time
+}
+
+// Client resolves trust chains from entities to the trust anchors it trusts, fetching entity statements over HTTP
+type Client struct {
+	*http.Client
+	trustAnchors []TrustAnchor
+}
+
+// NewClient returns a client trusting the given trust anchors
+func NewClient(trustAnchors ...TrustAnchor) *Client {
+	return &Client{Client: http.DefaultClient, trustAnchors: trustAnchors}
+}
+
+// ResolveTrustChain resolves a trust chain from an entity to one of the client's trust anchors, following the
+// authority hints of the entity's configuration, and of each superior's, until a trust anchor is reached. The
+// configuration of each entity of the chain must be signed by a key of the subordinate statement its superior issues
+// about it, which the trust anchor signs with a key the client trusts.
+func (c *Client) ResolveTrustChain(ctx context.Context, entityID string) (*TrustChain, error) {
+	if len(c.trustAnchors) == 0 {
+		return nil, errors.New("client has no trust anchors")
+	}
+	entity, configuration, err := c.GetEntityConfiguration(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	superiors, err := c.resolveSuperiors(ctx, *entity, configuration, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving trust chain of entity<%s>", entityID)
+	}
+	chain := TrustChain{
+		Statements:  append([]string{configuration}, superiors.Statements...),
+		Entity:      *entity,
+		TrustAnchor: superiors.TrustAnchor,
+		ExpiresAt:   entity.ExpiresAt,
+	}
+	if superiors.ExpiresAt.Before(chain.ExpiresAt) {
+		chain.ExpiresAt = superiors.ExpiresAt
+	}
+	return &chain, nil
+}
+
+// resolveSuperiors returns the chain of subordinate statements from the configuration of a subject to a trust
+// anchor, trying each of the subject's authority hints in turn
+func (c *Client) resolveSuperiors(ctx context.Context, subject EntityStatement, configuration string, depth int) (*TrustChain, error) {
+	if depth >= maxChainLength {
+		return nil, errors.Errorf("trust chain exceeds %d superiors", maxChainLength)
+	}
+	if len(subject.AuthorityHints) == 0 {
+		return nil, errors.Errorf("entity<%s> has no superiors, and is not a trust anchor", subject.Subject)
+	}
+	var errs []string
+	for _, superiorID := range subject.AuthorityHints {
+		chain, err := c.resolveSuperior(ctx, subject, configuration, superiorID, depth)
+		if err == nil {
+			return chain, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, errors.Errorf("no superior of entity<%s> chains to a trust anchor: %s", subject.Subject, strings.Join(errs, "; "))
+}
+
+// resolveSuperior returns the chain of subordinate statements from the configuration of a subject to a trust anchor
+// through one of its superiors: the superior's statement about the subject, followed by those of the superior's
+// superiors, unless the superior is a trust anchor
+func (c *Client) resolveSuperior(ctx context.Context, subject EntityStatement, configuration, superiorID string, depth int) (*TrustChain, error) {
+	anchor := c.trustAnchor(superiorID)
+	var superior *EntityStatement
+	var superiorConfiguration string
+	var err error
+	if anchor != nil {
+		superiorConfiguration, err = c.fetchStatement(ctx, entityConfigurationURL(superiorID))
+		if err == nil {
+			superior, err = VerifyEntityStatement(superiorConfiguration, anchor.JWKS)
+		}
+	} else {
+		superior, superiorConfiguration, err = c.GetEntityConfiguration(ctx, superiorID)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "entity configuration of superior<%s>", superiorID)
+	}
+	if superior.Subject != superiorID {
+		return nil, errors.Errorf("entity configuration of superior<%s> is of entity<%s>", superiorID, superior.Subject)
+	}
+	var federationEntity FederationEntityMetadata
+	if err = superior.GetMetadata(FederationEntityType, &federationEntity); err != nil || federationEntity.FetchEndpoint == "" {
+		return nil, errors.Errorf("superior<%s> has no fetch endpoint", superiorID)
+	}
+	fetchURL, err := url.Parse(federationEntity.FetchEndpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing fetch endpoint of superior<%s>", superiorID)
+	}
+	query := fetchURL.Query()
+	query.Set(subParameter, subject.Subject)
+	fetchURL.RawQuery = query.Encode()
+	subordinateStatement, err := c.fetchStatement(ctx, fetchURL.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching statement of superior<%s>", superiorID)
+	}
+
+	// the superior's statement is signed by a key of its configuration, or the trust anchor's known keys, and vouches
+	// for the subject's keys
+	superiorKeys := superior.JWKS
+	if anchor != nil {
+		superiorKeys = anchor.JWKS
+	}
+	subordinate, err := VerifyEntityStatement(subordinateStatement, superiorKeys)
+	if err != nil {
+		return nil, errors.Wrapf(err, "statement of superior<%s>", superiorID)
+	}
+	if subordinate.Issuer != superiorID || subordinate.Subject != subject.Subject {
+		return nil, errors.Errorf("statement of superior<%s> is not about entity<%s>", superiorID, subject.Subject)
+	}
+	if _, err = VerifyEntityStatement(configuration, subordinate.JWKS); err != nil {
+		return nil, errors.Wrapf(err, "entity<%s> is not signed by a key of the statement of superior<%s>", subject.Subject, superiorID)
+	}
+	if anchor != nil {
+		return &TrustChain{Statements: []string{subordinateStatement}, TrustAnchor: superiorID, ExpiresAt: subordinate.ExpiresAt}, nil
+	}
+
+	chain, err := c.resolveSuperiors(ctx, *superior, superiorConfiguration, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	chain.Statements = append([]string{subordinateStatement}, chain.Statements...)
+	if subordinate.ExpiresAt.Before(chain.ExpiresAt) {
+		chain.ExpiresAt = subordinate.ExpiresAt
+	}
+	return chain, nil
+}
+
+// GetEntityConfiguration fetches the entity configuration of an entity, returning it verified with its own keys,
+// and as signed. The configuration is only trusted once it is chained to a trust anchor.
+func (c *Client) GetEntityConfiguration(ctx context.Context, entityID string) (*EntityStatement, string, error) {
+	configuration, err := c.fetchStatement(ctx, entityConfigurationURL(entityID))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "fetching entity configuration of entity<%s>", entityID)
+	}
+	unverified, err := ParseEntityStatement(configuration)
+	if err != nil {
+		return nil, "", err
+	}
+	entity, err := VerifyEntityStatement(configuration, unverified.JWKS)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "entity configuration of entity<%s>", entityID)
+	}
+	if !entity.IsEntityConfiguration() || entity.Subject != entityID {
+		return nil, "", errors.Errorf("entity configuration of entity<%s> is issued by entity<%s> about entity<%s>", entityID, entity.Issuer, entity.Subject)
+	}
+	return entity, configuration, nil
+}
+
+// trustAnchor returns the trust anchor of an entity ID, if the client trusts it
+func (c *Client) trustAnchor(entityID string) *TrustAnchor {
+	for i := range c.trustAnchors {
+		if c.trustAnchors[i].EntityID == entityID {
+			return &c.trustAnchors[i]
+		}
+	}
+	return nil
+}
+
+// fetchStatement fetches an entity statement from a URL, which must be served as an entity statement
+func (c *Client) fetchStatement(ctx context.Context, statementURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statementURL, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Accept", EntityStatementMediaType)
+	resp, err := c.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", errors.Wrap(err, "reading response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("status code: %d", resp.StatusCode)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != EntityStatementMediaType {
+		return "", errors.Errorf("content type<%s> must be %s", mediaType, EntityStatementMediaType)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,214 @@
+package federation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// OpenID Federation authenticates the metadata of entities, such as credential issuers, with entity statements: an
+// entity's configuration is a statement about itself, signed by its own keys and naming its superiors, and each
+// superior issues a subordinate statement about its subordinates' keys. A trust chain of statements from an entity
+// to a trust anchor, whose keys are known in advance, authenticates the entity's metadata.
+// https://openid.net/specs/openid-federation-1_0.html
+
+const (
+	// EntityConfigurationPath is the path, relative to an entity ID, entity configurations are served at
+	EntityConfigurationPath string = "/.well-known/openid-federation"
+	// EntityStatementJWTType is the `typ` header of entity statements
+	EntityStatementJWTType string = "entity-statement+jwt"
+	// EntityStatementMediaType is the media type entity statements are served with
+	EntityStatementMediaType string = "application/entity-statement+jwt"
+
+	// FederationEntityType is the entity type of the metadata of entities of the federation, such as the fetch
+	// endpoint of superiors
+	FederationEntityType string = "federation_entity"
+	// CredentialIssuerEntityType is the entity type of the metadata of credential issuers
+	CredentialIssuerEntityType string = "openid_credential_issuer"
+
+	defaultStatementLifetime = 24 * time.Hour
+)
+
+// JWKS is a set of public keys of an entity
+type JWKS struct {
+	Keys []jwx.PublicKeyJWK `json:"keys"`
+}
+
+// EntityStatement is a statement of an issuer about a subject: an entity configuration, when the issuer is the
+// subject, and otherwise a subordinate statement of a superior about its subordinate. The keys are the subject's.
+// Metadata is keyed by entity type. Metadata policies of superiors are not applied.
+// https://openid.net/specs/openid-federation-1_0.html#name-entity-statement
+type EntityStatement struct {
+	Issuer  string `json:"iss"`
+	Subject string `json:"sub"`
+	JWKS    JWKS   `json:"jwks"`
+	// AuthorityHints are the entity IDs of the superiors of the subject of an entity configuration
+	AuthorityHints []string       `json:"authority_hints,omitempty"`
+	Metadata       map[string]any `json:"metadata,omitempty"`
+
+	IssuedAt  time.Time `json:"-"`
+	ExpiresAt time.Time `json:"-"`
+}
+
+// IsEntityConfiguration returns whether the statement is an entity configuration, issued by its subject
+func (s EntityStatement) IsEntityConfiguration() bool {
+	return s.Issuer == s.Subject
+}
+
+// GetMetadata unmarshals the subject's metadata of an entity type into the given value
+func (s EntityStatement) GetMetadata(entityType string, metadata any) error {
+	m, ok := s.Metadata[entityType]
+	if !ok {
+		return errors.Errorf("entity<%s> has no %s metadata", s.Subject, entityType)
+	}
+	metadataJSON, err := json.Marshal(m)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling %s metadata", entityType)
+	}
+	if err = json.Unmarshal(metadataJSON, metadata); err != nil {
+		return errors.Wrapf(err, "unmarshalling %s metadata", entityType)
+	}
+	return nil
+}
+
+// FederationEntityMetadata is the metadata of entities of the federation
+// https://openid.net/specs/openid-federation-1_0.html#name-federation-entity
+type FederationEntityMetadata struct {
+	// FetchEndpoint is where superiors serve their subordinate statements, by the subject's entity ID
+	FetchEndpoint    string `json:"federation_fetch_endpoint,omitempty"`
+	OrganizationName string `json:"organization_name,omitempty"`
+}
+
+// CreateEntityStatement signs an entity statement with a key of its issuer, identified by the signer's KID, issued
+// now and expiring after the given lifetime, which defaults to a day
+func CreateEntityStatement(signer jwx.Signer, statement EntityStatement, lifetime time.Duration) (string, error) {
+	if statement.Issuer == "" || statement.Subject == "" {
+		return "", errors.New("entity statement issuer and subject cannot be empty")
+	}
+	if len(statement.JWKS.Keys) == 0 {
+		return "", errors.New("entity statement keys cannot be empty")
+	}
+	if signer.KID == "" {
+		return "", errors.New("signer kid cannot be empty")
+	}
+	if lifetime == 0 {
+		lifetime = defaultStatementLifetime
+	}
+	claims, err := util.ToJSONMap(statement)
+	if err != nil {
+		return "", errors.Wrap(err, "getting entity statement claims")
+	}
+	t := jwt.New()
+	for k, v := range claims {
+		if err = t.Set(k, v); err != nil {
+			return "", errors.Wrapf(err, "setting %s", k)
+		}
+	}
+	now := time.Now()
+	if err = t.Set(jwt.IssuedAtKey, now); err != nil {
+		return "", errors.Wrap(err, "setting iat")
+	}
+	if err = t.Set(jwt.ExpirationKey, now.Add(lifetime)); err != nil {
+		return "", errors.Wrap(err, "setting exp")
+	}
+
+	hdrs := jws.NewHeaders()
+	if err = hdrs.Set(jws.TypeKey, EntityStatementJWTType); err != nil {
+		return "", errors.Wrap(err, "setting typ")
+	}
+	if err = hdrs.Set(jws.KeyIDKey, signer.KID); err != nil {
+		return "", errors.Wrap(err, "setting kid")
+	}
+	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+	alg := signer.ALG
+	if alg == "Ed25519" {
+		alg = jwa.EdDSA.String()
+	}
+	signed, err := jwt.Sign(t, jwt.WithKey(jwa.SignatureAlgorithm(alg), signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	if err != nil {
+		return "", errors.Wrap(err, "signing entity statement")
+	}
+	return string(signed), nil
+}
+
+// ParseEntityStatement parses an entity statement, without verifying it
+func ParseEntityStatement(statement string) (*EntityStatement, error) {
+	token, err := jwt.Parse([]byte(statement), jwt.WithVerify(false), jwt.WithValidate(false))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing entity statement")
+	}
+	return entityStatementFromToken(token)
+}
+
+// VerifyEntityStatement verifies an entity statement is signed by the key of the given keys its kid identifies, and
+// has not expired, returning its claims
+func VerifyEntityStatement(statement string, jwks JWKS) (*EntityStatement, error) {
+	headers, err := jwx.GetJWSHeaders([]byte(statement))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting entity statement headers")
+	}
+	if headers.Type() != EntityStatementJWTType {
+		return nil, errors.Errorf("entity statement typ<%s> must be %s", headers.Type(), EntityStatementJWTType)
+	}
+	kid := headers.KeyID()
+	if kid == "" {
+		return nil, errors.New("entity statement kid cannot be empty")
+	}
+	var key *jwx.PublicKeyJWK
+	for i := range jwks.Keys {
+		if jwks.Keys[i].KID == kid {
+			key = &jwks.Keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return nil, errors.Errorf("entity statement key<%s> is not a key of its issuer", kid)
+	}
+	verifier, err := jwx.NewJWXVerifierFromJWK(kid, *key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "constructing verifier for key<%s>", kid)
+	}
+	_, token, err := verifier.VerifyAndParse(statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying entity statement")
+	}
+	if token.Expiration().IsZero() {
+		return nil, errors.New("entity statement must expire")
+	}
+	return entityStatementFromToken(token)
+}
+
+// entityStatementFromToken returns the entity statement of the claims of a token
+func entityStatementFromToken(token jwt.Token) (*EntityStatement, error) {
+	claims, err := token.AsMap(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "getting entity statement claims")
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling entity statement claims")
+	}
+	var statement EntityStatement
+	if err = json.Unmarshal(claimsJSON, &statement); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling entity statement claims")
+	}
+	if statement.Issuer == "" || statement.Subject == "" {
+		return nil, errors.New("entity statement issuer and subject cannot be empty")
+	}
+	statement.IssuedAt, statement.ExpiresAt = token.IssuedAt(), token.Expiration()
+	return &statement, nil
+}
+
+// entityConfigurationURL returns the URL of the entity configuration of an entity ID
+func entityConfigurationURL(entityID string) string {
+	return strings.TrimSuffix(entityID, "/") + EntityConfigurationPath
+}
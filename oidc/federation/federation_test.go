@@ -0,0 +1,149 @@
+package federation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+func TestEntityStatement(t *testing.T) {
+	signer := getTestSigner(t, "https://entity.example.com")
+	jwks := JWKS{Keys: []jwx.PublicKeyJWK{signer.PrivateKeyJWK.ToPublicKeyJWK()}}
+
+	t.Run("signed and verified", func(tt *testing.T) {
+		statement, err := CreateEntityStatement(signer, EntityStatement{
+			Issuer:   signer.ID,
+			Subject:  signer.ID,
+			JWKS:     jwks,
+			Metadata: map[string]any{FederationEntityType: map[string]any{"organization_name": "Example"}},
+		}, time.Hour)
+		require.NoError(tt, err)
+
+		verified, err := VerifyEntityStatement(statement, jwks)
+		require.NoError(tt, err)
+		assert.True(tt, verified.IsEntityConfiguration())
+		assert.Equal(tt, jwks, verified.JWKS)
+		assert.WithinDuration(tt, time.Now().Add(time.Hour), verified.ExpiresAt, time.Minute)
+		var metadata FederationEntityMetadata
+		require.NoError(tt, verified.GetMetadata(FederationEntityType, &metadata))
+		assert.Equal(tt, "Example", metadata.OrganizationName)
+		assert.ErrorContains(tt, verified.GetMetadata(CredentialIssuerEntityType, &metadata), "has no openid_credential_issuer metadata")
+
+		// statements are verified with the key their kid identifies
+		other := getTestSigner(tt, "https://other.example.com")
+		_, err = VerifyEntityStatement(statement, JWKS{Keys: []jwx.PublicKeyJWK{other.PrivateKeyJWK.ToPublicKeyJWK()}})
+		assert.ErrorContains(tt, err, "is not a key of its issuer")
+		otherKey := other.PrivateKeyJWK.ToPublicKeyJWK()
+		otherKey.KID = signer.KID
+		_, err = VerifyEntityStatement(statement, JWKS{Keys: []jwx.PublicKeyJWK{otherKey}})
+		assert.ErrorContains(tt, err, "verifying entity statement")
+	})
+
+	t.Run("invalid statements", func(tt *testing.T) {
+		_, err := CreateEntityStatement(signer, EntityStatement{Issuer: signer.ID, Subject: signer.ID}, 0)
+		assert.ErrorContains(tt, err, "entity statement keys cannot be empty")
+		_, err = CreateEntityStatement(signer, EntityStatement{Subject: signer.ID, JWKS: jwks}, 0)
+		assert.ErrorContains(tt, err, "entity statement issuer and subject cannot be empty")
+	})
+}
+
+func TestResolveTrustChain(t *testing.T) {
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	anchor := getTestEntity(t, server.URL+"/anchor", true)
+	intermediate := getTestEntity(t, server.URL+"/intermediate", true, anchor.ID())
+	leaf := getTestEntity(t, server.URL+"/leaf", false, intermediate.ID())
+	unregistered := getTestEntity(t, server.URL+"/unregistered", false, intermediate.ID())
+	for _, entity := range []*Entity{anchor, intermediate, leaf, unregistered} {
+		entityMux, err := NewServeMux(entity)
+		require.NoError(t, err)
+		mux.Handle(entity.ID()[len(server.URL):]+"/", entityMux)
+	}
+	require.NoError(t, anchor.AddSubordinate(intermediate.ID(), intermediate.JWKS()))
+	require.NoError(t, intermediate.AddSubordinate(leaf.ID(), leaf.JWKS()))
+
+	t.Run("chain to a trust anchor", func(tt *testing.T) {
+		client := NewClient(TrustAnchor{EntityID: anchor.ID(), JWKS: anchor.JWKS()})
+		chain, err := client.ResolveTrustChain(ctx, leaf.ID())
+		require.NoError(tt, err)
+		assert.Len(tt, chain.Statements, 3)
+		assert.Equal(tt, anchor.ID(), chain.TrustAnchor)
+		assert.Equal(tt, leaf.ID(), chain.Entity.Subject)
+		assert.WithinDuration(tt, time.Now().Add(defaultStatementLifetime), chain.ExpiresAt, time.Minute)
+		var metadata FederationEntityMetadata
+		require.NoError(tt, chain.Entity.GetMetadata(FederationEntityType, &metadata))
+		assert.Equal(tt, "Leaf", metadata.OrganizationName)
+
+		// the anchor's statement is the last of the chain
+		last, err := VerifyEntityStatement(chain.Statements[2], anchor.JWKS())
+		require.NoError(tt, err)
+		assert.Equal(tt, intermediate.ID(), last.Subject)
+	})
+
+	t.Run("entities which are not subordinates", func(tt *testing.T) {
+		client := NewClient(TrustAnchor{EntityID: anchor.ID(), JWKS: anchor.JWKS()})
+		_, err := client.ResolveTrustChain(ctx, unregistered.ID())
+		assert.ErrorContains(tt, err, "status code: 404")
+	})
+
+	t.Run("untrusted anchors", func(tt *testing.T) {
+		other := getTestSigner(tt, anchor.ID())
+		client := NewClient(TrustAnchor{EntityID: anchor.ID(), JWKS: JWKS{Keys: []jwx.PublicKeyJWK{other.PrivateKeyJWK.ToPublicKeyJWK()}}})
+		_, err := client.ResolveTrustChain(ctx, leaf.ID())
+		assert.ErrorContains(tt, err, "no superior of entity")
+
+		_, err = NewClient().ResolveTrustChain(ctx, leaf.ID())
+		assert.ErrorContains(tt, err, "client has no trust anchors")
+	})
+
+	t.Run("keys the superior does not vouch for", func(tt *testing.T) {
+		rogue := getTestEntity(tt, server.URL+"/rogue", false, intermediate.ID())
+		rogueMux, err := NewServeMux(rogue)
+		require.NoError(tt, err)
+		mux.Handle("/rogue/", rogueMux)
+		require.NoError(tt, intermediate.AddSubordinate(rogue.ID(), leaf.JWKS()))
+
+		client := NewClient(TrustAnchor{EntityID: anchor.ID(), JWKS: anchor.JWKS()})
+		_, err = client.ResolveTrustChain(ctx, rogue.ID())
+		assert.ErrorContains(tt, err, "is not signed by a key of the statement of superior")
+	})
+}
+
+// getTestEntity returns an entity, with a fetch endpoint if it is a superior, under the given superiors
+func getTestEntity(t *testing.T, entityID string, superior bool, authorityHints ...string) *Entity {
+	federationEntity := map[string]any{"organization_name": "Leaf"}
+	if superior {
+		federationEntity = map[string]any{"federation_fetch_endpoint": entityID + "/fetch"}
+	}
+	entity, err := NewEntity(getTestSigner(t, entityID), EntityStatement{
+		Subject:        entityID,
+		AuthorityHints: authorityHints,
+		Metadata:       map[string]any{FederationEntityType: federationEntity},
+	}, 0)
+	require.NoError(t, err)
+	return entity
+}
+
+// getTestSigner returns the signer of a new key of an entity, identified by its thumbprint
+func getTestSigner(t *testing.T, entityID string) jwx.Signer {
+	_, privateKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	publicKeyJWK, _, err := jwx.PrivateKeyToPrivateKeyJWK(nil, privateKey)
+	require.NoError(t, err)
+	thumbprint, err := publicKeyJWK.Thumbprint()
+	require.NoError(t, err)
+	signer, err := jwx.NewJWXSigner(entityID, &thumbprint, privateKey)
+	require.NoError(t, err)
+	return *signer
+}
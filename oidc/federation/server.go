@@ -0,0 +1,169 @@
+package federation
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+// Entity is an entity of a federation, serving its entity configuration, and, if it is a superior, the subordinate
+// statements about the subordinates it registers
+type Entity struct {
+	signer        jwx.Signer
+	configuration EntityStatement
+	lifetime      time.Duration
+
+	mu           sync.RWMutex
+	subordinates map[string]JWKS
+}
+
+// NewEntity returns the entity of a configuration, whose issuer and subject are the entity's ID, and whose keys
+// default to the signer's public key. The signer signs the entity's statements, which expire after the given
+// lifetime, which defaults to a day.
+func NewEntity(signer jwx.Signer, configuration EntityStatement, lifetime time.Duration) (*Entity, error) {
+	if configuration.Subject == "" {
+		return nil, errors.New("entity id cannot be empty")
+	}
+	if configuration.Issuer == "" {
+		configuration.Issuer = configuration.Subject
+	}
+	if !configuration.IsEntityConfiguration() {
+		return nil, errors.Errorf("entity configuration issuer<%s> must be its subject<%s>", configuration.Issuer, configuration.Subject)
+	}
+	if signer.KID == "" {
+		return nil, errors.New("signer kid cannot be empty")
+	}
+	if len(configuration.JWKS.Keys) == 0 {
+		configuration.JWKS.Keys = []jwx.PublicKeyJWK{signer.PrivateKeyJWK.ToPublicKeyJWK()}
+		configuration.JWKS.Keys[0].KID = signer.KID
+	}
+	if lifetime == 0 {
+		lifetime = defaultStatementLifetime
+	}
+	return &Entity{
+		signer:        signer,
+		configuration: configuration,
+		lifetime:      lifetime,
+		subordinates:  make(map[string]JWKS),
+	}, nil
+}
+
+// ID returns the entity's ID
+func (e *Entity) ID() string {
+	return e.configuration.Subject
+}
+
+// JWKS returns the entity's keys, which are those clients trust, for trust anchors
+func (e *Entity) JWKS() JWKS {
+	return e.configuration.JWKS
+}
+
+// EntityConfiguration returns the entity's configuration, signed now
+func (e *Entity) EntityConfiguration() (string, error) {
+	return CreateEntityStatement(e.signer, e.configuration, e.lifetime)
+}
+
+// AddSubordinate registers a subordinate of the entity by its ID and keys, which the entity vouches for in its
+// subordinate statement about it
+func (e *Entity) AddSubordinate(entityID string, jwks JWKS) error {
+	if entityID == "" {
+		return errors.New("subordinate entity id cannot be empty")
+	}
+	if len(jwks.Keys) == 0 {
+		return errors.Errorf("subordinate<%s> keys cannot be empty", entityID)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.subordinates[entityID] = jwks
+	return nil
+}
+
+// SubordinateStatement returns the entity's statement about a subordinate, signed now. Statements about entities
+// which are not subordinates are not issued.
+func (e *Entity) SubordinateStatement(subject string) (string, error) {
+	e.mu.RLock()
+	jwks, ok := e.subordinates[subject]
+	e.mu.RUnlock()
+	if !ok {
+		return "", errors.Errorf("entity<%s> is not a subordinate of entity<%s>", subject, e.ID())
+	}
+	return CreateEntityStatement(e.signer, EntityStatement{Issuer: e.ID(), Subject: subject, JWKS: jwks}, e.lifetime)
+}
+
+// NewServeMux returns a mux serving the entity's configuration at its well-known path, and its subordinate statements
+// at the path of the fetch endpoint of its metadata, if it has one
+func NewServeMux(entity *Entity) (*http.ServeMux, error) {
+	entityURL, err := url.Parse(entity.ID())
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing entity id<%s>", entity.ID())
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET "+strings.TrimSuffix(entityURL.Path, "/")+EntityConfigurationPath, entity.ConfigurationHandler())
+	var federationEntity FederationEntityMetadata
+	if err = entity.configuration.GetMetadata(FederationEntityType, &federationEntity); err == nil && federationEntity.FetchEndpoint != "" {
+		fetchURL, err := url.Parse(federationEntity.FetchEndpoint)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing fetch endpoint")
+		}
+		mux.Handle("GET "+fetchURL.Path, entity.FetchHandler())
+	}
+	return mux, nil
+}
+
+// ConfigurationHandler returns a handler serving the entity's configuration
+func (e *Entity) ConfigurationHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		configuration, err := e.EntityConfiguration()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		writeStatement(w, configuration)
+	})
+}
+
+// FetchHandler returns a handler serving the entity's statement about the subordinate of the sub query parameter
+// https://openid.net/specs/openid-federation-1_0.html#name-fetch-subordinate-statement
+func (e *Entity) FetchHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject := r.URL.Query().Get(subParameter)
+		if subject == "" {
+			writeError(w, http.StatusBadRequest, "invalid_request", "sub is required")
+			return
+		}
+		if subject == e.ID() {
+			writeError(w, http.StatusBadRequest, "invalid_request", "sub cannot be the issuer")
+			return
+		}
+		statement, err := e.SubordinateStatement(subject)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "not_found", err.Error())
+			return
+		}
+		writeStatement(w, statement)
+	})
+}
+
+func writeStatement(w http.ResponseWriter, statement string) {
+	w.Header().Set("Content-Type", EntityStatementMediaType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(statement))
+}
+
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	body, err := json.Marshal(map[string]string{"error": code, "error_description": description})
+	if err != nil {
+		http.Error(w, "marshalling response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
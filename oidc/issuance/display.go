@@ -0,0 +1,266 @@
+package issuance
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+
+	"github.com/TBD54566975/ssi-sdk/credential/rendering"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// Display metadata of credentials supported is converted to and from the entity styles and data displays of wallet
+// rendering https://identity.foundation/wallet-rendering/, such that issuers maintaining both define branding once.
+// The credential's display becomes the title, description, and styles, and the display of each claim of the
+// credential subject becomes a property.
+
+const credentialSubjectPathPrefix = "$.credentialSubject."
+
+// claimValueTypes maps the value types of claims to the schemas of display mapping objects
+var claimValueTypes = map[string]rendering.DisplayMappingSchema{
+	"string":  {Type: rendering.StringType},
+	"number":  {Type: rendering.NumberType},
+	"integer": {Type: rendering.IntegerType},
+	"boolean": {Type: rendering.BooleanType},
+	"date":    {Type: rendering.StringType, Format: rendering.DateFormat},
+}
+
+// SelectCredentialDisplay returns the display of a credential for a locale: the display of the locale, else of its
+// base language, else without a locale, else the first. Returns nil if the credential has no displays.
+func SelectCredentialDisplay(displays []CredentialDisplay, locale language.Tag) *CredentialDisplay {
+	all := make([]Display, 0, len(displays))
+	for _, d := range displays {
+		all = append(all, d.Display)
+	}
+	i := selectDisplay(all, locale)
+	if i < 0 {
+		return nil
+	}
+	return &displays[i]
+}
+
+// selectDisplay returns the index of the display for a locale, as SelectCredentialDisplay selects, or -1
+func selectDisplay(displays []Display, locale language.Tag) int {
+	if len(displays) == 0 {
+		return -1
+	}
+	base, _ := locale.Base()
+	baseMatch, noLocale := -1, -1
+	for i, d := range displays {
+		if d.Locale == nil {
+			if noLocale < 0 {
+				noLocale = i
+			}
+			continue
+		}
+		if *d.Locale == locale {
+			return i
+		}
+		if dBase, _ := d.Locale.Base(); dBase == base && baseMatch < 0 {
+			baseMatch = i
+		}
+	}
+	if baseMatch >= 0 {
+		return baseMatch
+	}
+	if noLocale >= 0 {
+		return noLocale
+	}
+	return 0
+}
+
+// ToEntityStyles converts the logo and colors of a credential's display to wallet rendering entity styles, where the
+// logo is the thumbnail
+func (d CredentialDisplay) ToEntityStyles() *rendering.EntityStyleDescriptor {
+	var styles rendering.EntityStyleDescriptor
+	if d.Logo != nil && d.Logo.URL != nil {
+		styles.Thumbnail = &rendering.ImageResource{URI: d.Logo.URL.String()}
+		if d.Logo.AltText != nil {
+			styles.Thumbnail.Alt = *d.Logo.AltText
+		}
+	}
+	if d.BackgroundColor != nil {
+		styles.Background = &rendering.ColorResource{Color: *d.BackgroundColor}
+	}
+	if d.TextColor != nil {
+		styles.Text = &rendering.ColorResource{Color: *d.TextColor}
+	}
+	if styles.IsEmpty() {
+		return nil
+	}
+	return &styles
+}
+
+// ToRendering converts the display metadata of a credential supported, for a locale, to wallet rendering entity
+// styles and a data display. Claims of the credential subject are properties, in the order of the credential
+// supported, which may list claims by name or by display name, followed by the claims it does not list by name.
+func (s CredentialSupported) ToRendering(locale language.Tag) (*rendering.EntityStyleDescriptor, *rendering.DataDisplay) {
+	var styles *rendering.EntityStyleDescriptor
+	var display rendering.DataDisplay
+	if credentialDisplay := SelectCredentialDisplay(s.Display, locale); credentialDisplay != nil {
+		styles = credentialDisplay.ToEntityStyles()
+		if credentialDisplay.Name != nil {
+			display.Title = &rendering.DisplayMappingObject{Text: credentialDisplay.Name}
+		}
+		if credentialDisplay.Description != nil {
+			display.Description = &rendering.DisplayMappingObject{Text: credentialDisplay.Description}
+		}
+	}
+	if s.JWTVCJSONCredentialMetadata == nil {
+		return styles, &display
+	}
+
+	labels := make(map[string]string, len(s.CredentialSubject))
+	for name, claim := range s.CredentialSubject {
+		labels[name] = claimLabel(name, claim, locale)
+	}
+	for _, name := range orderClaims(s.CredentialSubject, s.Order, labels) {
+		schema := rendering.DisplayMappingSchema{Type: rendering.StringType}
+		if valueType := s.CredentialSubject[name].ValueType; valueType != nil {
+			if claimSchema, ok := claimValueTypes[*valueType]; ok {
+				schema = claimSchema
+			}
+		}
+		display.Properties = append(display.Properties, rendering.LabeledDisplayMappingObject{
+			Label: labels[name],
+			DisplayMappingObject: &rendering.DisplayMappingObject{
+				Path:   []string{credentialSubjectPathPrefix + name},
+				Schema: &schema,
+			},
+		})
+	}
+	return styles, &display
+}
+
+// claimLabel returns the display name of a claim for a locale or, if it has none, the claim's name
+func claimLabel(name string, claim Claim, locale language.Tag) string {
+	displays := make([]Display, 0, len(claim.Display)+len(claim.OtherDisplays))
+	for _, d := range claim.Display {
+		displays = append(displays, d)
+	}
+	// displays are keyed by locale, and sorted for a stable choice among those of the same base language
+	sort.Slice(displays, func(i, j int) bool { return displays[i].Locale.String() < displays[j].Locale.String() })
+	displays = append(displays, claim.OtherDisplays...)
+	if i := selectDisplay(displays, locale); i >= 0 && displays[i].Name != nil {
+		return *displays[i].Name
+	}
+	return name
+}
+
+// orderClaims returns the names of claims in the given order, of names or labels, followed by the others by name
+func orderClaims(claims map[string]Claim, order []string, labels map[string]string) []string {
+	names := make([]string, 0, len(claims))
+	for name := range claims {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ordered := make([]string, 0, len(claims))
+	seen := make(map[string]bool, len(claims))
+	for _, o := range order {
+		for _, name := range names {
+			if !seen[name] && (name == o || labels[name] == o) {
+				ordered = append(ordered, name)
+				seen[name] = true
+				break
+			}
+		}
+	}
+	for _, name := range names {
+		if !seen[name] {
+			ordered = append(ordered, name)
+		}
+	}
+	return ordered
+}
+
+// NewCredentialDisplay converts wallet rendering entity styles and a data display to the display of a credential for
+// a locale, which may be nil. The title and description must be text, or have a fallback, since credential displays
+// are not resolved against credentials.
+func NewCredentialDisplay(locale *language.Tag, styles *rendering.EntityStyleDescriptor, display rendering.DataDisplay) (*CredentialDisplay, error) {
+	credentialDisplay := CredentialDisplay{Display: Display{Locale: locale}}
+	credentialDisplay.Name = staticText(display.Title)
+	credentialDisplay.Description = staticText(display.Description)
+	if !styles.IsEmpty() {
+		if styles.Thumbnail != nil {
+			logoURL, err := url.Parse(styles.Thumbnail.URI)
+			if err != nil {
+				return nil, errors.Wrap(err, "parsing thumbnail uri")
+			}
+			credentialDisplay.Logo = &Logo{URL: &util.URL{URL: *logoURL}}
+			if styles.Thumbnail.Alt != "" {
+				credentialDisplay.Logo.AltText = &styles.Thumbnail.Alt
+			}
+		}
+		if styles.Background != nil && styles.Background.Color != "" {
+			credentialDisplay.BackgroundColor = &styles.Background.Color
+		}
+		if styles.Text != nil && styles.Text.Color != "" {
+			credentialDisplay.TextColor = &styles.Text.Color
+		}
+	}
+	return &credentialDisplay, nil
+}
+
+// staticText returns the text a display mapping object displays regardless of the credential, if any
+func staticText(dmo *rendering.DisplayMappingObject) *string {
+	switch {
+	case dmo.IsEmpty():
+		return nil
+	case dmo.Text != nil:
+		return dmo.Text
+	case dmo.Fallback != "":
+		return &dmo.Fallback
+	}
+	return nil
+}
+
+// NewClaimsFromDataDisplay converts the properties of a wallet rendering data display to the claims of a credential
+// subject, with display names for a locale, which may be nil, and the order of the properties. Each property must
+// have a path selecting a claim of the credential subject.
+func NewClaimsFromDataDisplay(locale *language.Tag, display rendering.DataDisplay) (map[string]Claim, []string, error) {
+	claims := make(map[string]Claim, len(display.Properties))
+	order := make([]string, 0, len(display.Properties))
+	for _, property := range display.Properties {
+		if property.DisplayMappingObject == nil {
+			return nil, nil, fmt.Errorf("property<%s> has no display mapping object", property.Label)
+		}
+		name := ""
+		for _, path := range property.Path {
+			if claimName, ok := strings.CutPrefix(path, credentialSubjectPathPrefix); ok && !strings.ContainsAny(claimName, ".[") {
+				name = claimName
+				break
+			}
+		}
+		if name == "" {
+			return nil, nil, fmt.Errorf("property<%s> does not select a claim of the credential subject", property.Label)
+		}
+		if _, ok := claims[name]; ok {
+			return nil, nil, fmt.Errorf("claim<%s> is displayed by more than one property", name)
+		}
+
+		label := property.Label
+		claimDisplay := Display{Name: &label, Locale: locale}
+		claim := Claim{Display: make(map[language.Tag]Display)}
+		if locale != nil {
+			claim.Display[*locale] = claimDisplay
+		} else {
+			claim.OtherDisplays = []Display{claimDisplay}
+		}
+		if property.Schema != nil {
+			for valueType, schema := range claimValueTypes {
+				if schema == *property.Schema {
+					claim.ValueType = &valueType
+					break
+				}
+			}
+		}
+		claims[name] = claim
+		order = append(order, name)
+	}
+	return claims, order, nil
+}
@@ -0,0 +1,105 @@
+package issuance
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+
+	"github.com/TBD54566975/ssi-sdk/credential/rendering"
+)
+
+func TestSelectCredentialDisplay(t *testing.T) {
+	name := func(n string) *string { return &n }
+	enUS, enGB, es := language.MustParse("en-US"), language.MustParse("en-GB"), language.Spanish
+	displays := []CredentialDisplay{
+		{Display: Display{Name: name("University Credential"), Locale: &enUS}},
+		{Display: Display{Name: name("Credencial Universitaria"), Locale: &es}},
+		{Display: Display{Name: name("Credential")}},
+	}
+
+	assert.Equal(t, "University Credential", *SelectCredentialDisplay(displays, enUS).Name)
+	assert.Equal(t, "University Credential", *SelectCredentialDisplay(displays, enGB).Name)
+	assert.Equal(t, "Credencial Universitaria", *SelectCredentialDisplay(displays, language.MustParse("es-CO")).Name)
+	assert.Equal(t, "Credential", *SelectCredentialDisplay(displays, language.French).Name)
+	assert.Equal(t, "University Credential", *SelectCredentialDisplay(displays[:2], language.French).Name)
+	assert.Nil(t, SelectCredentialDisplay(nil, enUS))
+}
+
+func TestCredentialSupportedToRendering(t *testing.T) {
+	var m IssuerMetadata
+	require.NoError(t, json.Unmarshal(exampleIssuerMetadata, &m))
+	require.Contains(t, m.CredentialsSupported, "UniversityDegree_JWT")
+
+	styles, display := m.CredentialsSupported["UniversityDegree_JWT"].ToRendering(language.MustParse("en-US"))
+	assert.Equal(t, &rendering.EntityStyleDescriptor{
+		Thumbnail:  &rendering.ImageResource{URI: "https://exampleuniversity.com/public/logo.png", Alt: "a square logo of a university"},
+		Background: &rendering.ColorResource{Color: "#12107c"},
+		Text:       &rendering.ColorResource{Color: "#FFFFFF"},
+	}, styles)
+	require.NotNil(t, display.Title)
+	assert.Equal(t, "University Credential", *display.Title.Text)
+	assert.Nil(t, display.Description)
+
+	// claims are in the order of their display names, followed by those not in the order
+	var labels, paths []string
+	for _, property := range display.Properties {
+		require.NoError(t, property.IsValid())
+		labels = append(labels, property.Label)
+		paths = append(paths, property.Path...)
+	}
+	assert.Equal(t, []string{"GPA", "Given Name", "Surname", "degree"}, labels)
+	assert.Equal(t, []string{
+		"$.credentialSubject.gpa",
+		"$.credentialSubject.given_name",
+		"$.credentialSubject.last_name",
+		"$.credentialSubject.degree",
+	}, paths)
+}
+
+func TestCredentialDisplayFromRendering(t *testing.T) {
+	var m IssuerMetadata
+	require.NoError(t, json.Unmarshal(exampleIssuerMetadata, &m))
+	supported := m.CredentialsSupported["UniversityDegree_JWT"]
+	enUS := language.MustParse("en-US")
+
+	t.Run("round trip", func(tt *testing.T) {
+		styles, display := supported.ToRendering(enUS)
+
+		credentialDisplay, err := NewCredentialDisplay(&enUS, styles, *display)
+		require.NoError(tt, err)
+		assert.Equal(tt, supported.Display[0], *credentialDisplay)
+
+		claims, order, err := NewClaimsFromDataDisplay(&enUS, *display)
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{"gpa", "given_name", "last_name", "degree"}, order)
+		assert.Equal(tt, "Given Name", *claims["given_name"].Display[enUS].Name)
+		assert.Equal(tt, "degree", *claims["degree"].Display[enUS].Name)
+		assert.Equal(tt, "string", *claims["degree"].ValueType)
+
+		// claims converted back display the same
+		roundTrip := CredentialSupported{
+			Display:                     []CredentialDisplay{*credentialDisplay},
+			JWTVCJSONCredentialMetadata: &JWTVCJSONCredentialMetadata{CredentialSubject: claims, Order: order},
+		}
+		roundTripStyles, roundTripDisplay := roundTrip.ToRendering(enUS)
+		assert.Equal(tt, styles, roundTripStyles)
+		assert.Equal(tt, display, roundTripDisplay)
+	})
+
+	t.Run("properties must select claims of the credential subject", func(tt *testing.T) {
+		display := rendering.DataDisplay{
+			Properties: []rendering.LabeledDisplayMappingObject{{
+				Label: "Degree",
+				DisplayMappingObject: &rendering.DisplayMappingObject{
+					Path:   []string{"$.credentialSubject.degree.name"},
+					Schema: &rendering.DisplayMappingSchema{Type: rendering.StringType},
+				},
+			}},
+		}
+		_, _, err := NewClaimsFromDataDisplay(nil, display)
+		assert.ErrorContains(tt, err, "property<Degree> does not select a claim of the credential subject")
+	})
+}
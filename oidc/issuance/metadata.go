@@ -47,6 +47,7 @@ const (
 	JWTVCJSON   Format = "jwt_vc_json"
 	JWTVCJSONLD Format = "jwt_vc_json-ld"
 	LDPVC       Format = "ldp_vc"
+	SDJWTVC     Format = "vc+sd-jwt"
 )
 
 type CredentialSupported struct {
@@ -96,6 +97,9 @@ type IssuerMetadata struct {
 	// Must use the `https` scheme.
 	BatchCredentialEndpoint *util.URL `json:"batch_credential_endpoint,omitempty"`
 
+	// Must use the `https` scheme.
+	DeferredCredentialEndpoint *util.URL `json:"deferred_credential_endpoint,omitempty"`
+
 	// Credentials supported indexes by the ID field.
 	CredentialsSupported map[string]CredentialSupported
 
@@ -107,12 +111,13 @@ type IssuerMetadata struct {
 
 func (m IssuerMetadata) MarshalJSON() ([]byte, error) {
 	imj := issuerMetadataJSON{
-		CredentialIssuer:        m.CredentialIssuer,
-		AuthorizationServer:     m.AuthorizationServer,
-		CredentialEndpoint:      m.CredentialEndpoint,
-		BatchCredentialEndpoint: m.BatchCredentialEndpoint,
-		CredentialsSupported:    make([]CredentialSupported, 0, len(m.CredentialsSupported)+len(m.OtherCredentialsSupported)),
-		Display:                 m.Display,
+		CredentialIssuer:           m.CredentialIssuer,
+		AuthorizationServer:        m.AuthorizationServer,
+		CredentialEndpoint:         m.CredentialEndpoint,
+		BatchCredentialEndpoint:    m.BatchCredentialEndpoint,
+		DeferredCredentialEndpoint: m.DeferredCredentialEndpoint,
+		CredentialsSupported:       make([]CredentialSupported, 0, len(m.CredentialsSupported)+len(m.OtherCredentialsSupported)),
+		Display:                    m.Display,
 	}
 
 	for _, v := range m.CredentialsSupported {
@@ -133,13 +138,14 @@ func (m *IssuerMetadata) UnmarshalJSON(data []byte) error {
 	}
 
 	unmarshalled := IssuerMetadata{
-		CredentialIssuer:          metadataJSON.CredentialIssuer,
-		AuthorizationServer:       metadataJSON.AuthorizationServer,
-		CredentialEndpoint:        metadataJSON.CredentialEndpoint,
-		BatchCredentialEndpoint:   metadataJSON.BatchCredentialEndpoint,
-		CredentialsSupported:      make(map[string]CredentialSupported, len(metadataJSON.CredentialsSupported)),
-		OtherCredentialsSupported: make([]CredentialSupported, 0, len(metadataJSON.CredentialsSupported)),
-		Display:                   metadataJSON.Display,
+		CredentialIssuer:           metadataJSON.CredentialIssuer,
+		AuthorizationServer:        metadataJSON.AuthorizationServer,
+		CredentialEndpoint:         metadataJSON.CredentialEndpoint,
+		BatchCredentialEndpoint:    metadataJSON.BatchCredentialEndpoint,
+		DeferredCredentialEndpoint: metadataJSON.DeferredCredentialEndpoint,
+		CredentialsSupported:       make(map[string]CredentialSupported, len(metadataJSON.CredentialsSupported)),
+		OtherCredentialsSupported:  make([]CredentialSupported, 0, len(metadataJSON.CredentialsSupported)),
+		Display:                    metadataJSON.Display,
 	}
 	for _, c := range metadataJSON.CredentialsSupported {
 		if c.ID == nil {
@@ -170,6 +176,9 @@ type issuerMetadataJSON struct {
 	// Must use the `https` scheme.
 	BatchCredentialEndpoint *util.URL `json:"batch_credential_endpoint,omitempty"`
 
+	// Must use the `https` scheme.
+	DeferredCredentialEndpoint *util.URL `json:"deferred_credential_endpoint,omitempty"`
+
 	CredentialsSupported []CredentialSupported `json:"credentials_supported,omitempty"`
 
 	Display []Display `json:"display,omitempty"`
@@ -184,6 +193,10 @@ func (m IssuerMetadata) IsValid() error {
 		return errors.Errorf("scheme for batch_credential_endpoint must be https (found %s)", m.BatchCredentialEndpoint.Scheme)
 	}
 
+	if m.DeferredCredentialEndpoint != nil && m.DeferredCredentialEndpoint.Scheme != "https" {
+		return errors.Errorf("scheme for deferred_credential_endpoint must be https (found %s)", m.DeferredCredentialEndpoint.Scheme)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,374 @@
+package oid4vci
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/oidc/issuance"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// Wallet providers attest the wallet instances they provide, and the keys those instances hold, for issuers to decide
+// whether they trust a wallet to hold their credentials. A wallet attestation authenticates the wallet instance at
+// the token endpoint, with a proof of possession of the instance's key. A key attestation attests the keys
+// credentials are bound to, and is sent as an attestation proof, or in the key_attestation header of a JWT proof.
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-wallet-attestations-in-jwt-
+// https://datatracker.ietf.org/doc/draft-ietf-oauth-attestation-based-client-auth/
+
+const (
+	// AttestationProofType is the type of proofs which are a key attestation, bound to the issuer's nonce
+	AttestationProofType string = "attestation"
+	// KeyAttestationJWTType is the `typ` header of key attestations
+	KeyAttestationJWTType string = "key-attestation+jwt"
+	// WalletAttestationJWTType is the `typ` header of wallet attestations
+	WalletAttestationJWTType string = "oauth-client-attestation+jwt"
+	// WalletAttestationPoPJWTType is the `typ` header of proofs of possession of the key of a wallet attestation
+	WalletAttestationPoPJWTType string = "oauth-client-attestation-pop+jwt"
+
+	// ClientAttestationHeader and ClientAttestationPoPHeader are the HTTP headers token requests carry a wallet
+	// attestation, and the proof of possession of its key, in
+	ClientAttestationHeader    string = "OAuth-Client-Attestation"
+	ClientAttestationPoPHeader string = "OAuth-Client-Attestation-PoP"
+
+	// keyAttestationHeader is the header of JWT proofs carrying a key attestation of the proof's key
+	keyAttestationHeader = "key_attestation"
+
+	defaultAttestationPoPLifetime = 5 * time.Minute
+)
+
+// KeyAttestation attests keys of a wallet, and how they are stored and protected, on behalf of its wallet provider
+type KeyAttestation struct {
+	// Issuer is the DID of the wallet provider, whose key signs the attestation
+	Issuer       string             `json:"iss"`
+	AttestedKeys []jwx.PublicKeyJWK `json:"attested_keys"`
+	// KeyStorage and UserAuthentication are the attack potential resistance the keys are stored and unlocked with,
+	// such as iso_18045_high
+	KeyStorage         []string `json:"key_storage,omitempty"`
+	UserAuthentication []string `json:"user_authentication,omitempty"`
+	Certification      string   `json:"certification,omitempty"`
+	// Nonce is the issuer's nonce attestations sent as proofs are bound to
+	Nonce string `json:"nonce,omitempty"`
+
+	IssuedAt  time.Time `json:"-"`
+	ExpiresAt time.Time `json:"-"`
+}
+
+// WalletAttestation attests a wallet instance, identified by its client ID, and the key it proves possession of, on
+// behalf of its wallet provider
+type WalletAttestation struct {
+	// Issuer is the DID of the wallet provider, whose key signs the attestation
+	Issuer string `json:"iss"`
+	// Subject is the client ID of the wallet instance
+	Subject      string       `json:"sub"`
+	Confirmation Confirmation `json:"cnf"`
+	WalletName   string       `json:"wallet_name,omitempty"`
+	WalletLink   string       `json:"wallet_link,omitempty"`
+
+	IssuedAt  time.Time `json:"-"`
+	ExpiresAt time.Time `json:"-"`
+}
+
+// Confirmation is the key of the wallet instance a wallet attestation is bound to
+type Confirmation struct {
+	JWK jwx.PublicKeyJWK `json:"jwk"`
+}
+
+// AttestationValidator decides whether an issuer trusts the wallets and keys of verified attestations
+type AttestationValidator interface {
+	// ValidateWalletAttestation returns an error if the wallet provider, or the wallet, of a wallet attestation is
+	// not trusted
+	ValidateWalletAttestation(ctx context.Context, attestation WalletAttestation) error
+	// ValidateKeyAttestation returns an error if the wallet provider of a key attestation, or the storage and user
+	// authentication of the attested keys, are not trusted
+	ValidateKeyAttestation(ctx context.Context, attestation KeyAttestation) error
+}
+
+// CreateKeyAttestation returns a key attestation signed by the wallet provider's signer, whose ID is the provider's
+// DID and whose KID is the verification method issuers resolve. The attestation expires after the given lifetime.
+func CreateKeyAttestation(signer jwx.Signer, attestation KeyAttestation, lifetime time.Duration) (string, error) {
+	if len(attestation.AttestedKeys) == 0 {
+		return "", errors.New("attested keys cannot be empty")
+	}
+	attestation.Issuer = signer.ID
+	return signAttestation(signer, KeyAttestationJWTType, attestation, lifetime)
+}
+
+// CreateWalletAttestation returns a wallet attestation signed by the wallet provider's signer, whose ID is the
+// provider's DID and whose KID is the verification method issuers resolve. The attestation expires after the given
+// lifetime.
+func CreateWalletAttestation(signer jwx.Signer, attestation WalletAttestation, lifetime time.Duration) (string, error) {
+	if attestation.Subject == "" {
+		return "", errors.New("wallet attestation subject cannot be empty")
+	}
+	if attestation.Confirmation.JWK.IsEmpty() {
+		return "", errors.New("wallet attestation key cannot be empty")
+	}
+	attestation.Issuer = signer.ID
+	return signAttestation(signer, WalletAttestationJWTType, attestation, lifetime)
+}
+
+// NewWalletAttestationPoP returns a proof of possession of the key of a wallet attestation, signed by the wallet
+// instance's signer, for the authorization server identified by the audience
+func NewWalletAttestationPoP(instanceSigner jwx.Signer, clientID, audience string) (string, error) {
+	t := jwt.New()
+	if err := t.Set(jwt.IssuerKey, clientID); err != nil {
+		return "", errors.Wrap(err, "setting iss")
+	}
+	if err := t.Set(jwt.AudienceKey, audience); err != nil {
+		return "", errors.Wrap(err, "setting aud")
+	}
+	if err := t.Set(jwt.JwtIDKey, uuid.NewString()); err != nil {
+		return "", errors.Wrap(err, "setting jti")
+	}
+	now := time.Now()
+	if err := t.Set(jwt.IssuedAtKey, now); err != nil {
+		return "", errors.Wrap(err, "setting iat")
+	}
+	if err := t.Set(jwt.ExpirationKey, now.Add(defaultAttestationPoPLifetime)); err != nil {
+		return "", errors.Wrap(err, "setting exp")
+	}
+	return signAttestationJWT(instanceSigner, WalletAttestationPoPJWTType, t)
+}
+
+// NewAttestationProof returns a proof of possession of the keys of a key attestation, which must be bound to the
+// credential issuer's latest nonce
+func NewAttestationProof(keyAttestation string) *Proof {
+	return &Proof{ProofType: AttestationProofType, Attestation: keyAttestation}
+}
+
+// signAttestation signs the claims of an attestation, issued now and expiring after the given lifetime
+func signAttestation(signer jwx.Signer, typ string, attestation any, lifetime time.Duration) (string, error) {
+	if lifetime <= 0 {
+		return "", errors.New("attestation lifetime must be positive")
+	}
+	claims, err := util.ToJSONMap(attestation)
+	if err != nil {
+		return "", errors.Wrap(err, "getting attestation claims")
+	}
+	t := jwt.New()
+	for k, v := range claims {
+		if err = t.Set(k, v); err != nil {
+			return "", errors.Wrapf(err, "setting %s", k)
+		}
+	}
+	now := time.Now()
+	if err = t.Set(jwt.IssuedAtKey, now); err != nil {
+		return "", errors.Wrap(err, "setting iat")
+	}
+	if err = t.Set(jwt.ExpirationKey, now.Add(lifetime)); err != nil {
+		return "", errors.Wrap(err, "setting exp")
+	}
+	return signAttestationJWT(signer, typ, t)
+}
+
+// signAttestationJWT signs a JWT of the given type, identifying the signer's key by its KID if it has one
+func signAttestationJWT(signer jwx.Signer, typ string, t jwt.Token) (string, error) {
+	hdrs := jws.NewHeaders()
+	if err := hdrs.Set(jws.TypeKey, typ); err != nil {
+		return "", errors.Wrap(err, "setting typ")
+	}
+	if signer.KID != "" {
+		if err := hdrs.Set(jws.KeyIDKey, signer.KID); err != nil {
+			return "", errors.Wrap(err, "setting kid")
+		}
+	}
+	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+	alg := signer.ALG
+	if alg == "Ed25519" {
+		alg = jwa.EdDSA.String()
+	}
+	signed, err := jwt.Sign(t, jwt.WithKey(jwa.SignatureAlgorithm(alg), signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	if err != nil {
+		return "", errors.Wrap(err, "signing attestation")
+	}
+	return string(signed), nil
+}
+
+// verifyKeyAttestation verifies a key attestation, and that the issuer trusts it
+func (i *Issuer) verifyKeyAttestation(ctx context.Context, keyAttestation string) (*KeyAttestation, *Error) {
+	if i.opts.AttestationValidator == nil {
+		return nil, newError(InvalidProof, "key attestations are not supported")
+	}
+	var attestation KeyAttestation
+	token, err := i.verifyAttestation(ctx, keyAttestation, KeyAttestationJWTType, &attestation)
+	if err != nil {
+		return nil, newError(InvalidProof, "key attestation: %s", err.Error())
+	}
+	if len(attestation.AttestedKeys) == 0 {
+		return nil, newError(InvalidProof, "key attestation has no attested keys")
+	}
+	attestation.IssuedAt, attestation.ExpiresAt = token.IssuedAt(), token.Expiration()
+	if err = i.opts.AttestationValidator.ValidateKeyAttestation(ctx, attestation); err != nil {
+		return nil, newError(InvalidProof, "key attestation is not trusted: %s", err.Error())
+	}
+	return &attestation, nil
+}
+
+// verifyAttestationProof verifies a proof which is a key attestation, bound to the session's nonce. Credentials are
+// bound to the first of the attested keys, which are not a DID's.
+func (i *Issuer) verifyAttestationProof(ctx context.Context, session Session, configuration issuance.CredentialSupported, keyAttestation string) (string, *jwx.PublicKeyJWK, *Error) {
+	if keyAttestation == "" {
+		return "", nil, newError(InvalidProof, "attestation proof cannot be empty")
+	}
+	attestation, oidErr := i.verifyKeyAttestation(ctx, keyAttestation)
+	if oidErr != nil {
+		return "", nil, oidErr
+	}
+	if session.CNonce == "" || attestation.Nonce != session.CNonce || time.Now().After(session.CNonceExpiresAt) {
+		return "", nil, newError(InvalidProof, "proof nonce is not valid")
+	}
+	if !bindingSupported(configuration, "") {
+		return "", nil, newError(InvalidProof, "proof key is not of a supported binding method")
+	}
+	return "", &attestation.AttestedKeys[0], nil
+}
+
+// verifyProofKeyAttestation verifies the key attestation of a JWT proof's headers, which must attest the proof's
+// key, and is required if the issuer requires key attestations
+func (i *Issuer) verifyProofKeyAttestation(ctx context.Context, headers jws.Headers, proofKey any) *Error {
+	header, ok := headers.Get(keyAttestationHeader)
+	if !ok {
+		if i.opts.RequireKeyAttestation {
+			return newError(InvalidProof, "key attestation is required")
+		}
+		return nil
+	}
+	keyAttestation, ok := header.(string)
+	if !ok {
+		return newError(InvalidProof, "key attestation must be a string")
+	}
+	attestation, oidErr := i.verifyKeyAttestation(ctx, keyAttestation)
+	if oidErr != nil {
+		return oidErr
+	}
+	proofJWK, err := jwx.PublicKeyToPublicKeyJWK(nil, proofKey)
+	if err != nil {
+		return newError(InvalidProof, "proof key: %s", err.Error())
+	}
+	thumbprint, err := proofJWK.Thumbprint()
+	if err != nil {
+		return newError(InvalidProof, "proof key thumbprint: %s", err.Error())
+	}
+	for _, attested := range attestation.AttestedKeys {
+		if attestedThumbprint, err := attested.Thumbprint(); err == nil && attestedThumbprint == thumbprint {
+			return nil
+		}
+	}
+	return newError(InvalidProof, "proof key is not attested by its key attestation")
+}
+
+// verifyWalletAttestation verifies the wallet attestation of a token request, the proof of possession of its key
+// for the issuer, and that the issuer trusts it
+func (i *Issuer) verifyWalletAttestation(ctx context.Context, request TokenRequest) (*WalletAttestation, *Error) {
+	if request.ClientAttestation == "" || request.ClientAttestationPoP == "" {
+		return nil, newError(InvalidClient, "wallet attestation and its proof of possession are required")
+	}
+	if i.opts.AttestationValidator == nil {
+		return nil, newError(InvalidClient, "wallet attestations are not supported")
+	}
+	var attestation WalletAttestation
+	token, err := i.verifyAttestation(ctx, request.ClientAttestation, WalletAttestationJWTType, &attestation)
+	if err != nil {
+		return nil, newError(InvalidClient, "wallet attestation: %s", err.Error())
+	}
+	if attestation.Subject == "" {
+		return nil, newError(InvalidClient, "wallet attestation has no subject")
+	}
+	if request.ClientID != "" && request.ClientID != attestation.Subject {
+		return nil, newError(InvalidClient, "client id<%s> is not the subject of the wallet attestation", request.ClientID)
+	}
+	attestation.IssuedAt, attestation.ExpiresAt = token.IssuedAt(), token.Expiration()
+
+	headers, err := jwx.GetJWSHeaders([]byte(request.ClientAttestationPoP))
+	if err != nil {
+		return nil, newError(InvalidClient, "getting wallet attestation pop headers: %s", err.Error())
+	}
+	if headers.Type() != WalletAttestationPoPJWTType {
+		return nil, newError(InvalidClient, "wallet attestation pop typ<%s> must be %s", headers.Type(), WalletAttestationPoPJWTType)
+	}
+	verifier, err := jwx.NewJWXVerifierFromJWK(attestation.Subject, attestation.Confirmation.JWK)
+	if err != nil {
+		return nil, newError(InvalidClient, "wallet attestation key: %s", err.Error())
+	}
+	_, pop, err := verifier.VerifyAndParse(request.ClientAttestationPoP)
+	if err != nil {
+		return nil, newError(InvalidClient, "verifying wallet attestation pop: %s", err.Error())
+	}
+	if pop.Issuer() != attestation.Subject {
+		return nil, newError(InvalidClient, "wallet attestation pop issuer<%s> is not the wallet attestation's subject", pop.Issuer())
+	}
+	if !util.Contains(i.metadata.CredentialIssuer.String(), pop.Audience()) {
+		return nil, newError(InvalidClient, "wallet attestation pop audience must be the credential issuer")
+	}
+	now := time.Now()
+	if pop.JwtID() == "" || pop.IssuedAt().IsZero() || pop.IssuedAt().After(now.Add(i.opts.ProofLeeway)) ||
+		pop.IssuedAt().Before(now.Add(-defaultAttestationPoPLifetime-i.opts.ProofLeeway)) {
+		return nil, newError(InvalidClient, "wallet attestation pop must have a jti, and a valid iat")
+	}
+
+	if err = i.opts.AttestationValidator.ValidateWalletAttestation(ctx, attestation); err != nil {
+		return nil, newError(InvalidClient, "wallet attestation is not trusted: %s", err.Error())
+	}
+	return &attestation, nil
+}
+
+// verifyAttestation verifies an attestation of the given type, signed by a key of its issuer's DID identified by its
+// kid, unmarshalling its claims into the given value
+func (i *Issuer) verifyAttestation(ctx context.Context, attestation, typ string, claims any) (jwt.Token, error) {
+	headers, err := jwx.GetJWSHeaders([]byte(attestation))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting headers")
+	}
+	if headers.Type() != typ {
+		return nil, errors.Errorf("typ<%s> must be %s", headers.Type(), typ)
+	}
+	kid := headers.KeyID()
+	if kid == "" {
+		return nil, errors.New("kid header is required")
+	}
+	if i.opts.Resolver == nil {
+		return nil, errors.New("attestations cannot be resolved without a resolver")
+	}
+	provider, _, _ := strings.Cut(kid, "#")
+	key, err := resolution.ResolveKeyForDID(ctx, i.opts.Resolver, provider, kid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving key<%s>", kid)
+	}
+	verifier, err := jwx.NewJWXVerifier(provider, &kid, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "key<%s>", kid)
+	}
+	_, token, err := verifier.VerifyAndParse(attestation)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying signature")
+	}
+	if token.Issuer() != provider {
+		return nil, errors.Errorf("issuer<%s> is not the DID of its key<%s>", token.Issuer(), kid)
+	}
+	if token.Expiration().IsZero() {
+		return nil, errors.New("exp is required")
+	}
+
+	claimsMap, err := token.AsMap(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting claims")
+	}
+	claimsJSON, err := json.Marshal(claimsMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling claims")
+	}
+	if err = json.Unmarshal(claimsJSON, claims); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling claims")
+	}
+	return token, nil
+}
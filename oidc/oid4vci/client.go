@@ -0,0 +1,751 @@
+package oid4vci
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/oidc/dpop"
+	"github.com/TBD54566975/ssi-sdk/oidc/federation"
+	"github.com/TBD54566975/ssi-sdk/oidc/issuance"
+	"github.com/TBD54566975/ssi-sdk/oidc/par"
+	"github.com/TBD54566975/ssi-sdk/oidc/qr"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// authorizationServerMetadataPath is the path OAuth 2.0 authorization server metadata is served at
+// https://www.rfc-editor.org/rfc/rfc8414.html#section-3
+const authorizationServerMetadataPath = "/.well-known/oauth-authorization-server"
+
+// maxResponseSize bounds the size of response bodies the client reads
+const maxResponseSize = 1 << 20
+
+// ParseCredentialOffer parses a credential offer URI, returning the offer passed by value, or the URI of the offer
+// passed by reference
+func ParseCredentialOffer(offerURI string) (offer *CredentialOffer, referenceURI string, err error) {
+	parsed, err := url.Parse(offerURI)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "parsing credential offer uri")
+	}
+	query := parsed.Query()
+	if offerJSON := query.Get(CredentialOfferParameter); offerJSON != "" {
+		var o CredentialOffer
+		if err = json.Unmarshal([]byte(offerJSON), &o); err != nil {
+			return nil, "", errors.Wrap(err, "unmarshalling credential offer")
+		}
+		if err = o.validate(); err != nil {
+			return nil, "", err
+		}
+		return &o, "", nil
+	}
+	if referenceURI = query.Get(CredentialOfferURIParameter); referenceURI != "" {
+		return nil, referenceURI, nil
+	}
+	return nil, "", errors.Errorf("uri has neither a %s nor a %s parameter", CredentialOfferParameter, CredentialOfferURIParameter)
+}
+
+// ParseCredentialOfferQRCode parses the credential offer URI of the QR code of an image, as ParseCredentialOffer does
+func ParseCredentialOfferQRCode(img image.Image) (offer *CredentialOffer, referenceURI string, err error) {
+	offerURI, err := qr.Decode(img)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "decoding QR code")
+	}
+	return ParseCredentialOffer(offerURI)
+}
+
+func (o CredentialOffer) validate() error {
+	if o.CredentialIssuer == "" {
+		return errors.New("credential offer has no credential issuer")
+	}
+	if len(o.CredentialConfigurationIDs) == 0 {
+		return errors.New("credential offer has no credential configuration ids")
+	}
+	return nil
+}
+
+// NewJWTProof returns a JWT proof of possession of the signer's key, bound to a credential issuer and the nonce it
+// last returned. The key is identified by the signer's KID, which is a DID URL for credentials bound to a DID, and
+// is otherwise given as a JWK.
+func NewJWTProof(signer jwx.Signer, credentialIssuer, nonce string) (*Proof, error) {
+	return newJWTProof(signer, "", credentialIssuer, nonce)
+}
+
+// NewKeyAttestedJWTProof returns a JWT proof as NewJWTProof does, carrying a key attestation of the signer's key in
+// its key_attestation header
+func NewKeyAttestedJWTProof(signer jwx.Signer, keyAttestation, credentialIssuer, nonce string) (*Proof, error) {
+	if keyAttestation == "" {
+		return nil, errors.New("key attestation cannot be empty")
+	}
+	return newJWTProof(signer, keyAttestation, credentialIssuer, nonce)
+}
+
+func newJWTProof(signer jwx.Signer, keyAttestation, credentialIssuer, nonce string) (*Proof, error) {
+	t := jwt.New()
+	if err := t.Set(jwt.AudienceKey, credentialIssuer); err != nil {
+		return nil, errors.Wrap(err, "setting aud")
+	}
+	if err := t.Set(jwt.IssuedAtKey, time.Now()); err != nil {
+		return nil, errors.Wrap(err, "setting iat")
+	}
+	if nonce != "" {
+		if err := t.Set(integrity.NonceProperty, nonce); err != nil {
+			return nil, errors.Wrap(err, "setting nonce")
+		}
+	}
+
+	hdrs := jws.NewHeaders()
+	if err := hdrs.Set(jws.TypeKey, ProofJWTType); err != nil {
+		return nil, errors.Wrap(err, "setting typ")
+	}
+	if signer.KID != "" {
+		if err := hdrs.Set(jws.KeyIDKey, signer.KID); err != nil {
+			return nil, errors.Wrap(err, "setting kid")
+		}
+	} else {
+		publicKeyJWK := signer.PrivateKeyJWK.ToPublicKeyJWK()
+		publicKey, err := publicKeyJWK.ToPublicKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "getting public key")
+		}
+		key, err := jwk.FromRaw(publicKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating jwk")
+		}
+		if err = hdrs.Set(jws.JWKKey, key); err != nil {
+			return nil, errors.Wrap(err, "setting jwk")
+		}
+	}
+	if keyAttestation != "" {
+		if err := hdrs.Set(keyAttestationHeader, keyAttestation); err != nil {
+			return nil, errors.Wrap(err, "setting key_attestation")
+		}
+	}
+
+	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+	alg := signer.ALG
+	if alg == "Ed25519" {
+		alg = jwa.EdDSA.String()
+	}
+	signed, err := jwt.Sign(t, jwt.WithKey(jwa.SignatureAlgorithm(alg), signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	if err != nil {
+		return nil, errors.Wrap(err, "signing proof")
+	}
+	return &Proof{ProofType: JWTProofType, JWT: string(signed)}, nil
+}
+
+// IssuedCredential is a credential issued to a wallet, parsed from the credential response
+type IssuedCredential struct {
+	CredentialConfigurationID string
+	Format                    issuance.Format
+	// Credential is the credential as issued: a string for credentials secured as JWTs, and an object for those with
+	// embedded proofs
+	Credential any
+	// Parsed is the parsed credential; for credentials secured with SD-JWT, with each of its disclosures
+	Parsed *credential.VerifiableCredential
+	// TransactionID is that of a credential whose issuance was deferred, which has no credential until it is
+	// obtained with AwaitDeferredCredential
+	TransactionID string
+}
+
+// Client is the wallet side of credential issuance, calling the endpoints of credential issuers
+type Client struct {
+	*http.Client
+	// DPoPSigner, if set, binds access tokens to its key, proving possession of the key with a DPoP proof of each
+	// request to the token and credential endpoints
+	DPoPSigner *jwx.Signer
+	// WalletAttestation, if set, is the wallet provider's attestation of the wallet instance, whose key
+	// WalletInstanceSigner proves possession of in token requests. The signer's ID is the client ID the attestation
+	// was issued to.
+	WalletAttestation    string
+	WalletInstanceSigner *jwx.Signer
+	// KeyAttestation, if set, is the wallet provider's attestation of the key proofs are signed with, which proofs
+	// carry in their key_attestation header
+	KeyAttestation string
+	// Federation, if set, authenticates the metadata of credential issuers, which is taken from the entity
+	// configuration of the issuer, chained to a trust anchor of the federation client, rather than trusted as served
+	// at its well-known path
+	Federation *federation.Client
+
+	// dpopNonces are the latest DPoP nonces of servers, by origin
+	mu         sync.Mutex
+	dpopNonces map[string]string
+}
+
+// NewClient returns a new client using the default HTTP client
+func NewClient() *Client {
+	return &Client{Client: http.DefaultClient}
+}
+
+// GetCredentialOffer returns the credential offer of a credential offer URI, fetching offers passed by reference
+func (c *Client) GetCredentialOffer(ctx context.Context, offerURI string) (*CredentialOffer, error) {
+	offer, referenceURI, err := ParseCredentialOffer(offerURI)
+	if err != nil {
+		return nil, err
+	}
+	if offer != nil {
+		return offer, nil
+	}
+	var o CredentialOffer
+	if err = c.get(ctx, referenceURI, &o); err != nil {
+		return nil, errors.Wrapf(err, "getting credential offer<%s>", referenceURI)
+	}
+	if err = o.validate(); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// GetIssuerMetadata returns the metadata of a credential issuer, from its well-known path, or from its entity
+// configuration, through a trust chain, for clients of a federation, checking the metadata is that of the issuer
+func (c *Client) GetIssuerMetadata(ctx context.Context, credentialIssuer string) (*issuance.IssuerMetadata, error) {
+	var metadata issuance.IssuerMetadata
+	if c.Federation != nil {
+		chain, err := c.Federation.ResolveTrustChain(ctx, credentialIssuer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "authenticating metadata of credential issuer<%s>", credentialIssuer)
+		}
+		if err = chain.Entity.GetMetadata(federation.CredentialIssuerEntityType, &metadata); err != nil {
+			return nil, errors.Wrapf(err, "getting metadata of credential issuer<%s>", credentialIssuer)
+		}
+	} else {
+		issuerURL, err := url.Parse(credentialIssuer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing credential issuer<%s>", credentialIssuer)
+		}
+		metadataURL := issuerURL.Scheme + "://" + issuerURL.Host + CredentialIssuerMetadataPath + strings.TrimSuffix(issuerURL.Path, "/")
+		if err = c.get(ctx, metadataURL, &metadata); err != nil {
+			return nil, errors.Wrapf(err, "getting metadata of credential issuer<%s>", credentialIssuer)
+		}
+	}
+	if metadata.CredentialIssuer.String() != credentialIssuer {
+		return nil, errors.Errorf("metadata is of credential issuer<%s>, not <%s>", metadata.CredentialIssuer.String(), credentialIssuer)
+	}
+	return &metadata, nil
+}
+
+// GetTokenEndpoint returns the token endpoint of a credential issuer: that of the metadata of its authorization
+// server, if it has one, and otherwise that of the issuer acting as its own authorization server
+func (c *Client) GetTokenEndpoint(ctx context.Context, metadata issuance.IssuerMetadata) (string, error) {
+	if metadata.AuthorizationServer == nil {
+		return strings.TrimSuffix(metadata.CredentialIssuer.String(), "/") + TokenPath, nil
+	}
+	serverMetadata, err := c.getAuthorizationServerMetadata(ctx, *metadata.AuthorizationServer)
+	if err != nil {
+		return "", err
+	}
+	if serverMetadata.TokenEndpoint == "" {
+		return "", errors.Errorf("authorization server<%s> has no token endpoint", metadata.AuthorizationServer.String())
+	}
+	return serverMetadata.TokenEndpoint, nil
+}
+
+// authorizationServerMetadata is the metadata of an authorization server the client uses
+// https://www.rfc-editor.org/rfc/rfc8414.html#section-2
+type authorizationServerMetadata struct {
+	Issuer                             string `json:"issuer"`
+	AuthorizationEndpoint              string `json:"authorization_endpoint"`
+	TokenEndpoint                      string `json:"token_endpoint"`
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint"`
+}
+
+// getAuthorizationServerMetadata returns the metadata of an authorization server, from its well-known path
+func (c *Client) getAuthorizationServerMetadata(ctx context.Context, server util.URL) (*authorizationServerMetadata, error) {
+	metadataURL := server.Scheme + "://" + server.Host + authorizationServerMetadataPath + strings.TrimSuffix(server.Path, "/")
+	var serverMetadata authorizationServerMetadata
+	if err := c.get(ctx, metadataURL, &serverMetadata); err != nil {
+		return nil, errors.Wrapf(err, "getting metadata of authorization server<%s>", server.String())
+	}
+	return &serverMetadata, nil
+}
+
+// PushAuthorizationRequest pushes an authorization request of the authorization code flow to the pushed
+// authorization request endpoint of the authorization server of an issuer, which is the issuer itself if it has
+// none, returning the URL of the authorization endpoint the end-user is sent to. The client authenticates with a
+// client assertion signed by the client signer, whose ID is the DID the request's client ID defaults to.
+func (c *Client) PushAuthorizationRequest(ctx context.Context, metadata issuance.IssuerMetadata, clientSigner jwx.Signer, request AuthorizationRequest) (string, error) {
+	server := metadata.CredentialIssuer
+	if metadata.AuthorizationServer != nil {
+		server = *metadata.AuthorizationServer
+	}
+	serverMetadata, err := c.getAuthorizationServerMetadata(ctx, server)
+	if err != nil {
+		return "", err
+	}
+	if serverMetadata.PushedAuthorizationRequestEndpoint == "" || serverMetadata.AuthorizationEndpoint == "" {
+		return "", errors.Errorf("authorization server<%s> does not support pushed authorization requests", server.String())
+	}
+	if request.ClientID == "" {
+		request.ClientID = clientSigner.ID
+	}
+	if request.ClientID != clientSigner.ID {
+		return "", errors.Errorf("client id<%s> is not that of the client signer<%s>", request.ClientID, clientSigner.ID)
+	}
+	form, err := request.Form()
+	if err != nil {
+		return "", err
+	}
+	parClient, err := par.NewClient(clientSigner)
+	if err != nil {
+		return "", err
+	}
+	parClient.Client = c.Client
+	audience := serverMetadata.Issuer
+	if audience == "" {
+		audience = server.String()
+	}
+	response, err := parClient.Push(ctx, serverMetadata.PushedAuthorizationRequestEndpoint, audience, form)
+	if err != nil {
+		return "", errors.Wrap(err, "pushing authorization request")
+	}
+	return par.AuthorizationURL(serverMetadata.AuthorizationEndpoint, request.ClientID, response.RequestURI)
+}
+
+// Token redeems a grant at a token endpoint, binding the access token to the client's DPoP key if it has one. Error
+// responses are returned as *Error.
+func (c *Client) Token(ctx context.Context, tokenEndpoint string, request TokenRequest) (*TokenResponse, error) {
+	var response TokenResponse
+	err := c.doWithDPoP(func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint, strings.NewReader(request.Form().Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if request.ClientAttestation != "" {
+			req.Header.Set(ClientAttestationHeader, request.ClientAttestation)
+			req.Header.Set(ClientAttestationPoPHeader, request.ClientAttestationPoP)
+		}
+		return req, nil
+	}, "", &response)
+	if err != nil {
+		return nil, errors.Wrap(err, "requesting token")
+	}
+	if c.DPoPSigner != nil && response.TokenType != dpop.TokenType {
+		return nil, errors.Errorf("token type<%s> is not bound to the DPoP key", response.TokenType)
+	}
+	return &response, nil
+}
+
+// Credential requests a credential at a credential endpoint with an access token, which is sent with a DPoP proof if
+// the client has a DPoP key. Error responses are returned as *Error.
+func (c *Client) Credential(ctx context.Context, credentialEndpoint, accessToken string, request CredentialRequest) (*CredentialResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling request")
+	}
+	var response CredentialResponse
+	err = c.doWithDPoP(func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, credentialEndpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, accessToken, &response)
+	if err != nil {
+		return nil, errors.Wrap(err, "requesting credential")
+	}
+	return &response, nil
+}
+
+// BatchCredential requests several credentials at a batch credential endpoint with an access token, as Credential
+// does. Error responses are returned as *Error.
+func (c *Client) BatchCredential(ctx context.Context, batchCredentialEndpoint, accessToken string, request BatchCredentialRequest) (*BatchCredentialResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling request")
+	}
+	var response BatchCredentialResponse
+	err = c.doWithDPoP(func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, batchCredentialEndpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, accessToken, &response)
+	if err != nil {
+		return nil, errors.Wrap(err, "requesting batch credential")
+	}
+	return &response, nil
+}
+
+// AcceptCredentialOffer obtains the credentials of an offer with its pre-authorized code grant, and the transaction
+// code the end-user was sent if the grant requires one: resolving the issuer's metadata, redeeming the grant for an
+// access token, and requesting each offered credential with a proof of possession of the signer's key. A proof
+// rejected for its nonce is retried once with the fresh nonce of the error. The token request carries the client's
+// wallet attestation, if it has one. Wallets expecting credentials whose issuance is deferred redeem the grant with
+// Token and request them with RequestCredentials instead, keeping the access token to await them with.
+func (c *Client) AcceptCredentialOffer(ctx context.Context, offer CredentialOffer, signer jwx.Signer, txCode string) ([]IssuedCredential, error) {
+	if offer.Grants == nil || offer.Grants.PreAuthorizedCode == nil {
+		return nil, errors.New("credential offer has no pre-authorized code grant")
+	}
+	grant := offer.Grants.PreAuthorizedCode
+	if grant.TxCode != nil && txCode == "" {
+		return nil, errors.New("credential offer requires a transaction code")
+	}
+	metadata, err := c.GetIssuerMetadata(ctx, offer.CredentialIssuer)
+	if err != nil {
+		return nil, err
+	}
+	tokenEndpoint, err := c.GetTokenEndpoint(ctx, *metadata)
+	if err != nil {
+		return nil, err
+	}
+	request := TokenRequest{
+		GrantType:         PreAuthorizedCodeGrantType,
+		PreAuthorizedCode: grant.PreAuthorizedCode,
+		TxCode:            txCode,
+	}
+	if err = c.attestWallet(&request, offer.CredentialIssuer); err != nil {
+		return nil, err
+	}
+	token, err := c.Token(ctx, tokenEndpoint, request)
+	if err != nil {
+		return nil, err
+	}
+	return c.RequestCredentials(ctx, *metadata, token.AccessToken, token.CNonce, offer.CredentialConfigurationIDs, signer)
+}
+
+// RequestCredentials requests the credentials of the given configurations of an issuer with an access token, each
+// with a proof of possession of the signer's key bound to the issuer's latest nonce, starting with the given nonce.
+// Credentials whose issuance is deferred are returned with their transaction ID, for AwaitDeferredCredential.
+func (c *Client) RequestCredentials(ctx context.Context, metadata issuance.IssuerMetadata, accessToken, cNonce string, configurationIDs []string, signer jwx.Signer) ([]IssuedCredential, error) {
+	credentialIssuer := metadata.CredentialIssuer.String()
+	credentialEndpoint := metadata.CredentialEndpoint.String()
+	issued := make([]IssuedCredential, 0, len(configurationIDs))
+	for _, id := range configurationIDs {
+		configuration, ok := metadata.CredentialsSupported[id]
+		if !ok {
+			return nil, errors.Errorf("credential configuration<%s> is not supported by credential issuer<%s>", id, credentialIssuer)
+		}
+		request := CredentialRequest{CredentialConfigurationID: id}
+		var response *CredentialResponse
+		for retried := false; ; retried = true {
+			proof, err := c.newProof(signer, credentialIssuer, cNonce)
+			if err != nil {
+				return nil, err
+			}
+			request.Proof = proof
+			response, err = c.Credential(ctx, credentialEndpoint, accessToken, request)
+			var oidErr *Error
+			if err != nil && errors.As(err, &oidErr) && oidErr.Code == InvalidProof && oidErr.CNonce != "" && !retried {
+				cNonce = oidErr.CNonce
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "requesting credential<%s>", id)
+			}
+			break
+		}
+		if response.CNonce != "" {
+			cNonce = response.CNonce
+		}
+
+		issuedCredential, err := newIssuedCredential(id, configuration.Format, *response)
+		if err != nil {
+			return nil, err
+		}
+		issued = append(issued, *issuedCredential)
+	}
+	return issued, nil
+}
+
+// RequestBatchCredentials requests an instance of a credential configuration of an issuer for each of the signers'
+// keys at once, at the issuer's batch credential endpoint, with an access token. The proofs of possession of the keys
+// are bound to the issuer's latest nonce, starting with the given nonce. A batch rejected for its nonce is retried
+// once with the fresh nonce of the error.
+func (c *Client) RequestBatchCredentials(ctx context.Context, metadata issuance.IssuerMetadata, accessToken, cNonce, configurationID string, signers []jwx.Signer) ([]IssuedCredential, error) {
+	credentialIssuer := metadata.CredentialIssuer.String()
+	if metadata.BatchCredentialEndpoint == nil {
+		return nil, errors.Errorf("credential issuer<%s> has no batch credential endpoint", credentialIssuer)
+	}
+	if len(signers) == 0 {
+		return nil, errors.New("at least one signer is required")
+	}
+	configuration, ok := metadata.CredentialsSupported[configurationID]
+	if !ok {
+		return nil, errors.Errorf("credential configuration<%s> is not supported by credential issuer<%s>", configurationID, credentialIssuer)
+	}
+	var response *BatchCredentialResponse
+	for retried := false; ; retried = true {
+		request := BatchCredentialRequest{CredentialRequests: make([]CredentialRequest, 0, len(signers))}
+		for _, signer := range signers {
+			proof, err := c.newProof(signer, credentialIssuer, cNonce)
+			if err != nil {
+				return nil, err
+			}
+			request.CredentialRequests = append(request.CredentialRequests, CredentialRequest{
+				CredentialConfigurationID: configurationID,
+				Proof:                     proof,
+			})
+		}
+		var err error
+		response, err = c.BatchCredential(ctx, metadata.BatchCredentialEndpoint.String(), accessToken, request)
+		var oidErr *Error
+		if err != nil && errors.As(err, &oidErr) && oidErr.Code == InvalidProof && oidErr.CNonce != "" && !retried {
+			cNonce = oidErr.CNonce
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "requesting credentials<%s>", configurationID)
+		}
+		break
+	}
+	if len(response.CredentialResponses) != len(signers) {
+		return nil, errors.Errorf("batch credential response has %d credentials, expected %d", len(response.CredentialResponses), len(signers))
+	}
+
+	issued := make([]IssuedCredential, 0, len(signers))
+	for _, credentialResponse := range response.CredentialResponses {
+		issuedCredential, err := newIssuedCredential(configurationID, configuration.Format, credentialResponse)
+		if err != nil {
+			return nil, err
+		}
+		issued = append(issued, *issuedCredential)
+	}
+	return issued, nil
+}
+
+// DeferredCredential requests the credential of a deferred issuance at a deferred credential endpoint with the access
+// token it was requested with, as Credential does. Credentials which are still pending are answered with an
+// IssuancePending *Error.
+func (c *Client) DeferredCredential(ctx context.Context, deferredCredentialEndpoint, accessToken, transactionID string) (*CredentialResponse, error) {
+	body, err := json.Marshal(DeferredCredentialRequest{TransactionID: transactionID})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling request")
+	}
+	var response CredentialResponse
+	err = c.doWithDPoP(func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, deferredCredentialEndpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, accessToken, &response)
+	if err != nil {
+		return nil, errors.Wrap(err, "requesting deferred credential")
+	}
+	return &response, nil
+}
+
+// AwaitDeferredCredential obtains the credential of a deferred issuance from an issuer's deferred credential
+// endpoint, with the access token it was requested with, polling at the interval the issuer asks for until the
+// credential is issued, or the context is done
+func (c *Client) AwaitDeferredCredential(ctx context.Context, metadata issuance.IssuerMetadata, accessToken string, deferred IssuedCredential) (*IssuedCredential, error) {
+	if metadata.DeferredCredentialEndpoint == nil {
+		return nil, errors.Errorf("credential issuer<%s> has no deferred credential endpoint", metadata.CredentialIssuer.String())
+	}
+	if deferred.TransactionID == "" {
+		return nil, errors.New("transaction id cannot be empty")
+	}
+	for {
+		response, err := c.DeferredCredential(ctx, metadata.DeferredCredentialEndpoint.String(), accessToken, deferred.TransactionID)
+		var oidErr *Error
+		if err != nil && errors.As(err, &oidErr) && oidErr.Code == IssuancePending {
+			interval := defaultDeferredInterval
+			if oidErr.Interval > 0 {
+				interval = time.Duration(oidErr.Interval) * time.Second
+			}
+			select {
+			case <-ctx.Done():
+				return nil, errors.Wrapf(ctx.Err(), "awaiting credential<%s>", deferred.CredentialConfigurationID)
+			case <-time.After(interval):
+			}
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "requesting credential<%s>", deferred.CredentialConfigurationID)
+		}
+		if response.Credential == nil {
+			return nil, errors.Errorf("deferred credential<%s> cannot be empty", deferred.CredentialConfigurationID)
+		}
+		return newIssuedCredential(deferred.CredentialConfigurationID, deferred.Format, *response)
+	}
+}
+
+// newIssuedCredential returns the credential of a credential response of a configuration, parsed, or the
+// transaction ID of a deferred issuance
+func newIssuedCredential(configurationID string, format issuance.Format, response CredentialResponse) (*IssuedCredential, error) {
+	issued := IssuedCredential{CredentialConfigurationID: configurationID, Format: format}
+	if response.Credential == nil && response.TransactionID != "" {
+		issued.TransactionID = response.TransactionID
+		return &issued, nil
+	}
+	parsed, err := ParseIssuedCredential(format, response.Credential)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing credential<%s>", configurationID)
+	}
+	issued.Credential, issued.Parsed = response.Credential, parsed
+	return &issued, nil
+}
+
+// newProof returns a JWT proof of possession of the signer's key, carrying the client's key attestation, if it has one
+func (c *Client) newProof(signer jwx.Signer, credentialIssuer, cNonce string) (*Proof, error) {
+	var proof *Proof
+	var err error
+	if c.KeyAttestation != "" {
+		proof, err = NewKeyAttestedJWTProof(signer, c.KeyAttestation, credentialIssuer, cNonce)
+	} else {
+		proof, err = NewJWTProof(signer, credentialIssuer, cNonce)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "creating proof")
+	}
+	return proof, nil
+}
+
+// attestWallet sets the client's wallet attestation, and a proof of possession of its key for the audience, on a
+// token request, if the client has one
+func (c *Client) attestWallet(request *TokenRequest, audience string) error {
+	if c.WalletAttestation == "" {
+		return nil
+	}
+	if c.WalletInstanceSigner == nil {
+		return errors.New("wallet attestation requires a wallet instance signer")
+	}
+	pop, err := NewWalletAttestationPoP(*c.WalletInstanceSigner, c.WalletInstanceSigner.ID, audience)
+	if err != nil {
+		return errors.Wrap(err, "creating wallet attestation pop")
+	}
+	request.ClientID = c.WalletInstanceSigner.ID
+	request.ClientAttestation, request.ClientAttestationPoP = c.WalletAttestation, pop
+	return nil
+}
+
+// ParseIssuedCredential parses the credential of a credential response in a format. Credentials are parsed, not
+// verified.
+func ParseIssuedCredential(format issuance.Format, issued any) (*credential.VerifiableCredential, error) {
+	switch format {
+	case issuance.JWTVCJSON, issuance.JWTVCJSONLD, issuance.SDJWTVC:
+		token, ok := issued.(string)
+		if !ok {
+			return nil, errors.Errorf("credential of format<%s> must be a string", format)
+		}
+		if format == issuance.SDJWTVC {
+			_, cred, err := integrity.ParseVerifiableCredentialFromSDJWT(token)
+			return cred, err
+		}
+		_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(token)
+		return cred, err
+	case issuance.LDPVC:
+		credJSON, err := json.Marshal(issued)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling credential")
+		}
+		var cred credential.VerifiableCredential
+		if err = json.Unmarshal(credJSON, &cred); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling credential")
+		}
+		return &cred, nil
+	}
+	return nil, errors.Errorf("format<%s> is not supported", format)
+}
+
+// get sends a GET request, decoding the JSON response into the given value
+func (c *Client) get(ctx context.Context, url string, response any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	return c.do(req, response)
+}
+
+// doWithDPoP sends the request of newRequest, authorized by the access token, if any. Requests of clients with a DPoP
+// key carry a proof of the key, bound to the server's latest nonce and the access token, which is sent with the DPoP
+// scheme. A proof rejected for its nonce is retried once with the nonce of the error response.
+func (c *Client) doWithDPoP(newRequest func() (*http.Request, error), accessToken string, response any) error {
+	for retried := false; ; retried = true {
+		req, err := newRequest()
+		if err != nil {
+			return errors.Wrap(err, "creating request")
+		}
+		if c.DPoPSigner == nil {
+			if accessToken != "" {
+				req.Header.Set("Authorization", BearerTokenType+" "+accessToken)
+			}
+			return c.do(req, response)
+		}
+		proof, err := dpop.CreateProof(*c.DPoPSigner, req.Method, req.URL.String(), dpop.ProofOptions{
+			Nonce:       c.dpopNonce(req.URL),
+			AccessToken: accessToken,
+		})
+		if err != nil {
+			return errors.Wrap(err, "creating DPoP proof")
+		}
+		req.Header.Set(dpop.HeaderName, proof)
+		if accessToken != "" {
+			req.Header.Set("Authorization", dpop.TokenType+" "+accessToken)
+		}
+		err = c.do(req, response)
+		var oidErr *Error
+		if err != nil && errors.As(err, &oidErr) && oidErr.Code == UseDPoPNonce && !retried {
+			continue
+		}
+		return err
+	}
+}
+
+// dpopNonce returns the latest DPoP nonce of the server of a URL
+func (c *Client) dpopNonce(u *url.URL) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dpopNonces[u.Scheme+"://"+u.Host]
+}
+
+// setDPoPNonce records the DPoP nonce of the response of a server, if it has one
+func (c *Client) setDPoPNonce(u *url.URL, resp *http.Response) {
+	nonce := resp.Header.Get(dpop.NonceHeaderName)
+	if nonce == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dpopNonces == nil {
+		c.dpopNonces = make(map[string]string)
+	}
+	c.dpopNonces[u.Scheme+"://"+u.Host] = nonce
+}
+
+// do sends a request, decoding the JSON response into the given value if its status is 200, and otherwise returning
+// the *Error of the response, if it is one
+func (c *Client) do(req *http.Request, response any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+	c.setDPoPNonce(req.URL, resp)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return errors.Wrap(err, "reading response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oidErr Error
+		if err = json.Unmarshal(body, &oidErr); err == nil && oidErr.Code != "" {
+			return &oidErr
+		}
+		return errors.Errorf("status code: %d", resp.StatusCode)
+	}
+	if err = json.Unmarshal(body, response); err != nil {
+		return errors.Wrap(err, "unmarshalling response")
+	}
+	return nil
+}
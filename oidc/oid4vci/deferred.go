@@ -0,0 +1,181 @@
+package oid4vci
+
+import (
+	"context"
+	"crypto/subtle"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+// Issuers defer the issuance of credentials which are not ready when the wallet requests them, such as those pending
+// a review of the holder's data. The credential response then has a transaction ID, which the wallet redeems at the
+// deferred credential endpoint, with the access token it requested the credential with, until the credential is
+// issued.
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0-13.html#name-deferred-credential-endpoin
+
+const (
+	defaultDeferredIssuanceLifetime = 24 * time.Hour
+	defaultDeferredInterval         = 5 * time.Second
+)
+
+// ErrIssuancePending is returned by credential sources to defer the issuance of a credential. The source is asked for
+// the credential again, with the same session, configuration, and holder, each time the wallet requests it from the
+// deferred credential endpoint.
+var ErrIssuancePending = errors.New("issuance pending")
+
+// DeferredIssuance is the issuance of a credential deferred by the credential source, until the wallet redeems its
+// transaction ID for the credential, or it expires
+type DeferredIssuance struct {
+	TransactionID string
+	// SessionID is the ID of the session whose access token the credential was requested with
+	SessionID                 string
+	CredentialConfigurationID string
+	// Holder is the DID the credential is bound to, which is empty when the wallet proved possession of a key which
+	// is not a DID's
+	Holder string
+	// HolderKey is the key the credential is bound to when it is not a DID's
+	HolderKey *jwx.PublicKeyJWK
+	ExpiresAt time.Time
+}
+
+// DeferredIssuanceStore stores deferred issuances until their credential is issued. Lookups of issuances which are not
+// stored return nil.
+type DeferredIssuanceStore interface {
+	PutDeferredIssuance(ctx context.Context, issuance DeferredIssuance) error
+	GetDeferredIssuance(ctx context.Context, transactionID string) (*DeferredIssuance, error)
+	DeleteDeferredIssuance(ctx context.Context, transactionID string) error
+}
+
+// DeferredCredential issues the credential of a deferred issuance, by its transaction ID, to the wallet which
+// requested it with an access token. The access token remains valid for the deferred issuance until the issuance
+// expires. Credentials which are still pending are answered with an IssuancePending error, carrying the interval the
+// wallet must wait before requesting the credential again. Errors are of type *Error.
+func (i *Issuer) DeferredCredential(ctx context.Context, accessToken, dpopProof string, request DeferredCredentialRequest) (*CredentialResponse, error) {
+	if i.metadata.DeferredCredentialEndpoint == nil {
+		return nil, newError(InvalidRequest, "deferred credential requests are not supported")
+	}
+	session, err := i.opts.Store.GetSessionByAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, newError(ServerError, "getting session: %s", err.Error())
+	}
+	if accessToken == "" || session == nil {
+		return nil, newError(InvalidToken, "access token is not valid")
+	}
+	if oidErr := i.verifyBoundAccessToken(*session, dpopProof, i.metadata.DeferredCredentialEndpoint.String()); oidErr != nil {
+		return nil, oidErr
+	}
+	if request.TransactionID == "" {
+		return nil, newError(InvalidRequest, "transaction id is required")
+	}
+	deferred, err := i.opts.DeferredStore.GetDeferredIssuance(ctx, request.TransactionID)
+	if err != nil {
+		return nil, newError(ServerError, "getting deferred issuance: %s", err.Error())
+	}
+	if deferred == nil || subtle.ConstantTimeCompare([]byte(deferred.SessionID), []byte(session.ID)) != 1 ||
+		time.Now().After(deferred.ExpiresAt) {
+		return nil, newError(InvalidTransactionID, "transaction id is not valid")
+	}
+
+	signed, err := i.issueCredential(ctx, *session, authorizedCredential{
+		configurationID: deferred.CredentialConfigurationID,
+		configuration:   i.metadata.CredentialsSupported[deferred.CredentialConfigurationID],
+		holder:          deferred.Holder,
+		holderKey:       deferred.HolderKey,
+	})
+	if errors.Is(err, ErrIssuancePending) {
+		oidErr := newError(IssuancePending, "credential<%s> is not yet issued", deferred.CredentialConfigurationID)
+		oidErr.Interval = int(i.opts.DeferredInterval.Seconds())
+		return nil, oidErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = i.opts.DeferredStore.DeleteDeferredIssuance(ctx, deferred.TransactionID); err != nil {
+		return nil, newError(ServerError, "deleting deferred issuance: %s", err.Error())
+	}
+	return &CredentialResponse{Credential: signed}, nil
+}
+
+// credentialResponse issues the credential of an authorized request, or defers its issuance if the credential source
+// defers it, returning a response with its transaction ID
+func (i *Issuer) credentialResponse(ctx context.Context, session Session, authorized authorizedCredential) (*CredentialResponse, error) {
+	signed, err := i.issueCredential(ctx, session, authorized)
+	if err == nil {
+		return &CredentialResponse{Credential: signed}, nil
+	}
+	if !errors.Is(err, ErrIssuancePending) {
+		return nil, err
+	}
+	if i.metadata.DeferredCredentialEndpoint == nil {
+		return nil, newError(ServerError, "credential<%s> cannot be deferred without a deferred credential endpoint", authorized.configurationID)
+	}
+	transactionID, err := randomToken()
+	if err != nil {
+		return nil, newError(ServerError, "generating transaction id")
+	}
+	err = i.opts.DeferredStore.PutDeferredIssuance(ctx, DeferredIssuance{
+		TransactionID:             transactionID,
+		SessionID:                 session.ID,
+		CredentialConfigurationID: authorized.configurationID,
+		Holder:                    authorized.holder,
+		HolderKey:                 authorized.holderKey,
+		ExpiresAt:                 time.Now().Add(i.opts.DeferredIssuanceLifetime),
+	})
+	if err != nil {
+		return nil, newError(ServerError, "storing deferred issuance: %s", err.Error())
+	}
+	return &CredentialResponse{TransactionID: transactionID}, nil
+}
+
+// MemoryDeferredIssuanceStore is a DeferredIssuanceStore holding deferred issuances in memory
+type MemoryDeferredIssuanceStore struct {
+	mu        sync.Mutex
+	issuances map[string]DeferredIssuance
+}
+
+var _ DeferredIssuanceStore = (*MemoryDeferredIssuanceStore)(nil)
+
+// NewMemoryDeferredIssuanceStore returns an empty MemoryDeferredIssuanceStore
+func NewMemoryDeferredIssuanceStore() *MemoryDeferredIssuanceStore {
+	return &MemoryDeferredIssuanceStore{issuances: make(map[string]DeferredIssuance)}
+}
+
+// PutDeferredIssuance stores a deferred issuance, removing the issuances which expired
+func (s *MemoryDeferredIssuanceStore) PutDeferredIssuance(_ context.Context, issuance DeferredIssuance) error {
+	if issuance.TransactionID == "" {
+		return errors.New("transaction id cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for transactionID, stored := range s.issuances {
+		if now.After(stored.ExpiresAt) {
+			delete(s.issuances, transactionID)
+		}
+	}
+	s.issuances[issuance.TransactionID] = issuance
+	return nil
+}
+
+// GetDeferredIssuance returns the deferred issuance of a transaction ID
+func (s *MemoryDeferredIssuanceStore) GetDeferredIssuance(_ context.Context, transactionID string) (*DeferredIssuance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issuance, ok := s.issuances[transactionID]
+	if !ok {
+		return nil, nil
+	}
+	return &issuance, nil
+}
+
+// DeleteDeferredIssuance deletes the deferred issuance of a transaction ID, once its credential is issued
+func (s *MemoryDeferredIssuanceStore) DeleteDeferredIssuance(_ context.Context, transactionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.issuances, transactionID)
+	return nil
+}
@@ -0,0 +1,906 @@
+package oid4vci
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/oidc/dpop"
+	"github.com/TBD54566975/ssi-sdk/oidc/federation"
+	"github.com/TBD54566975/ssi-sdk/oidc/issuance"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+const (
+	defaultOfferLifetime       = 10 * time.Minute
+	defaultAccessTokenLifetime = 10 * time.Minute
+	defaultCNonceLifetime      = 5 * time.Minute
+	defaultProofLeeway         = time.Minute
+	defaultDPoPNonceLifetime   = 5 * time.Minute
+	defaultMaxBatchSize        = 10
+	defaultTxCodeLength        = 6
+
+	// maxTxCodeAttempts is the number of wrong transaction codes after which a pre-authorized code is invalidated
+	maxTxCodeAttempts = 5
+
+	// tokenSize is the size, in bytes, of the random codes, tokens, and nonces issuers generate
+	tokenSize = 32
+
+	textTxCodeCharacters = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// Session is the issuance of the credentials of an offer, from the offer's creation until its access token expires
+type Session struct {
+	ID                         string
+	CredentialConfigurationIDs []string
+	// Data is given when the offer is created, for the credential source to issue credentials with
+	Data map[string]any
+
+	// PreAuthorizedCode is the code of the offer's pre-authorized code grant, until it is redeemed
+	PreAuthorizedCode string
+	// TxCode is the transaction code the pre-authorized code must be redeemed with, if any
+	TxCode string
+	// TxCodeAttempts is the number of wrong transaction codes the pre-authorized code was redeemed with
+	TxCodeAttempts int
+	// IssuerState is the issuer state of the offer's authorization code grant, until it is redeemed
+	IssuerState string
+	// ExpiresAt is when the offer's grant expires
+	ExpiresAt time.Time
+
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	// DPoPThumbprint is the JWK thumbprint of the key the access token is bound to with DPoP, if it is bound
+	DPoPThumbprint string
+	// WalletAttestation is the verified attestation of the wallet instance the access token was issued to, if it was
+	// attested
+	WalletAttestation *WalletAttestation
+	// CNonce is the nonce the wallet's next proof must be bound to
+	CNonce          string
+	CNonceExpiresAt time.Time
+}
+
+// SessionStore stores issuance sessions. Lookups of sessions which are not stored return nil.
+type SessionStore interface {
+	PutSession(ctx context.Context, session Session) error
+	GetSessionByPreAuthorizedCode(ctx context.Context, code string) (*Session, error)
+	GetSessionByIssuerState(ctx context.Context, issuerState string) (*Session, error)
+	GetSessionByAccessToken(ctx context.Context, accessToken string) (*Session, error)
+}
+
+// CredentialSource provides the unsigned credentials an issuer issues
+type CredentialSource interface {
+	// GetCredential returns the credential of a configuration to issue in a session to its holder, by the DID the
+	// credential is bound to or, when the wallet proved possession of a key which is not a DID's, by that key, which
+	// the issuer binds the credential to with a `cnf` claim. Sources return ErrIssuancePending to defer the issuance
+	// of a credential which is not ready.
+	GetCredential(ctx context.Context, session Session, configurationID, holder string, holderKey *jwx.PublicKeyJWK) (*credential.VerifiableCredential, error)
+}
+
+// AuthorizationCodeHandler redeems authorization codes for issuers acting as their own authorization server
+type AuthorizationCodeHandler interface {
+	// RedeemAuthorizationCode validates the code of a token request, returning the issuer state of the authorization
+	// request it was issued for, which is that of the offer's authorization code grant
+	RedeemAuthorizationCode(ctx context.Context, request TokenRequest) (issuerState string, err error)
+}
+
+// IssuerOptions configures an Issuer
+type IssuerOptions struct {
+	// JWTSigner signs credentials secured as JWTs, of the jwt_vc_json, jwt_vc_json-ld, and vc+sd-jwt formats
+	JWTSigner *jwx.Signer
+	// LDSigner signs credentials with embedded proofs, of the ldp_vc format, with LDSuite, which is the
+	// JsonWebSignature2020 suite if it is nil
+	LDSigner cryptosuite.Signer
+	LDSuite  cryptosuite.CryptoSuite
+	// Resolver resolves the DIDs of the keys of proofs
+	Resolver resolution.Resolver
+	// Store stores sessions, which are held in memory if it is nil
+	Store SessionStore
+	// AuthorizationCodeHandler redeems authorization codes; without it, only pre-authorized codes are redeemed
+	AuthorizationCodeHandler AuthorizationCodeHandler
+
+	// OfferLifetime, AccessTokenLifetime, and CNonceLifetime default to 10, 10, and 5 minutes
+	OfferLifetime       time.Duration
+	AccessTokenLifetime time.Duration
+	CNonceLifetime      time.Duration
+	// ProofLeeway tolerates clock skew in the iat of proofs, and of DPoP proofs, and defaults to a minute
+	ProofLeeway time.Duration
+
+	// RequireDPoP requires token requests to carry a DPoP proof, binding each access token to a key; otherwise access
+	// tokens are bound if their token request carries a proof
+	RequireDPoP bool
+	// DPoPNonces requires DPoP proofs to be bound to a nonce of the issuer, which is rotated every 5 minutes
+	DPoPNonces bool
+	// DPoPReplayCache rejects DPoP proofs which were already accepted, and holds them in memory if it is nil
+	DPoPReplayCache dpop.ReplayCache
+
+	// AttestationValidator decides whether the issuer trusts the wallets and keys of attestations; without it,
+	// attestations are not supported
+	AttestationValidator AttestationValidator
+	// RequireWalletAttestation requires token requests to carry a wallet attestation, authenticating the wallet
+	// instance; otherwise wallet attestations are verified if token requests carry one
+	RequireWalletAttestation bool
+	// RequireKeyAttestation requires proofs to be key attestations, or JWTs with a key attestation of their key
+	RequireKeyAttestation bool
+
+	// MaxBatchSize bounds the number of credential requests of batch credential requests, and defaults to 10
+	MaxBatchSize int
+
+	// DeferredStore stores the issuances the credential source defers, which are held in memory if it is nil
+	DeferredStore DeferredIssuanceStore
+	// DeferredIssuanceLifetime is how long deferred issuances can be redeemed, and defaults to 24 hours
+	DeferredIssuanceLifetime time.Duration
+	// DeferredInterval is how long wallets must wait between requests for a pending credential, and defaults to
+	// 5 seconds
+	DeferredInterval time.Duration
+}
+
+// Issuer issues the credentials of its metadata to wallets: creating offers, redeeming their grants for access
+// tokens at the token endpoint, and issuing credentials bound to the keys wallets prove possession of at the
+// credential endpoint. The nonces proofs are bound to are rotated with each credential issued, and each proof which
+// is not valid.
+type Issuer struct {
+	metadata issuance.IssuerMetadata
+	source   CredentialSource
+	opts     IssuerOptions
+
+	// mu serializes redeeming grants and nonces, such that each is redeemed once
+	mu sync.Mutex
+
+	// dpopNonces are the current and previous DPoP nonces, the current of which expires at dpopNonceExpiresAt
+	dpopMu             sync.Mutex
+	dpopNonces         []string
+	dpopNonceExpiresAt time.Time
+}
+
+// NewIssuer returns an issuer of the credentials of its metadata, which it gets from the given source
+func NewIssuer(metadata issuance.IssuerMetadata, source CredentialSource, opts IssuerOptions) (*Issuer, error) {
+	if metadata.CredentialIssuer.String() == "" {
+		return nil, errors.New("credential issuer cannot be empty")
+	}
+	if source == nil {
+		return nil, errors.New("credential source cannot be empty")
+	}
+	if opts.LDSuite == nil {
+		opts.LDSuite = jws2020.GetJSONWebSignature2020Suite()
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemorySessionStore()
+	}
+	if opts.OfferLifetime == 0 {
+		opts.OfferLifetime = defaultOfferLifetime
+	}
+	if opts.AccessTokenLifetime == 0 {
+		opts.AccessTokenLifetime = defaultAccessTokenLifetime
+	}
+	if opts.CNonceLifetime == 0 {
+		opts.CNonceLifetime = defaultCNonceLifetime
+	}
+	if opts.ProofLeeway == 0 {
+		opts.ProofLeeway = defaultProofLeeway
+	}
+	if opts.MaxBatchSize == 0 {
+		opts.MaxBatchSize = defaultMaxBatchSize
+	}
+	if opts.DPoPReplayCache == nil {
+		opts.DPoPReplayCache = dpop.NewMemoryReplayCache()
+	}
+	if opts.DeferredStore == nil {
+		opts.DeferredStore = NewMemoryDeferredIssuanceStore()
+	}
+	if opts.DeferredIssuanceLifetime == 0 {
+		opts.DeferredIssuanceLifetime = defaultDeferredIssuanceLifetime
+	}
+	if opts.DeferredInterval == 0 {
+		opts.DeferredInterval = defaultDeferredInterval
+	}
+	return &Issuer{metadata: metadata, source: source, opts: opts}, nil
+}
+
+// Metadata returns the issuer's metadata
+func (i *Issuer) Metadata() issuance.IssuerMetadata {
+	return i.metadata
+}
+
+// FederationEntity returns the issuer as an entity of a federation, whose configuration carries the issuer's
+// metadata, signed by the signer, under the superiors of the authority hints. The entity ID is the credential issuer.
+func (i *Issuer) FederationEntity(signer jwx.Signer, authorityHints ...string) (*federation.Entity, error) {
+	metadata, err := util.ToJSONMap(i.metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting issuer metadata")
+	}
+	return federation.NewEntity(signer, federation.EntityStatement{
+		Subject:        i.metadata.CredentialIssuer.String(),
+		AuthorityHints: authorityHints,
+		Metadata:       map[string]any{federation.CredentialIssuerEntityType: metadata},
+	}, 0)
+}
+
+// OfferOptions configures a credential offer
+type OfferOptions struct {
+	// PreAuthorized offers a pre-authorized code grant, and otherwise an authorization code grant
+	PreAuthorized bool
+	// TxCode, for a pre-authorized code grant, requires a transaction code of the given input mode and length, which
+	// default to numeric and 6
+	TxCode *TxCode
+	// Data is held by the offer's session, for the credential source to issue credentials with
+	Data map[string]any
+}
+
+// CreateCredentialOffer creates an offer of the credentials of the given configurations, starting a session. The
+// transaction code the offer requires, if any, is returned for the issuer to send to the end-user out of band.
+func (i *Issuer) CreateCredentialOffer(ctx context.Context, configurationIDs []string, opts OfferOptions) (*CredentialOffer, string, error) {
+	if len(configurationIDs) == 0 {
+		return nil, "", errors.New("credential configuration ids cannot be empty")
+	}
+	for _, id := range configurationIDs {
+		if _, ok := i.metadata.CredentialsSupported[id]; !ok {
+			return nil, "", errors.Errorf("credential configuration<%s> is not supported", id)
+		}
+	}
+	if opts.TxCode != nil && !opts.PreAuthorized {
+		return nil, "", errors.New("transaction codes require a pre-authorized code grant")
+	}
+
+	session := Session{
+		ID:                         uuid.NewString(),
+		CredentialConfigurationIDs: configurationIDs,
+		Data:                       opts.Data,
+		ExpiresAt:                  time.Now().Add(i.opts.OfferLifetime),
+	}
+	offer := CredentialOffer{
+		CredentialIssuer:           i.metadata.CredentialIssuer.String(),
+		CredentialConfigurationIDs: configurationIDs,
+		Grants:                     new(Grants),
+	}
+	var err error
+	if opts.PreAuthorized {
+		if session.PreAuthorizedCode, err = randomToken(); err != nil {
+			return nil, "", err
+		}
+		grant := PreAuthorizedCodeGrant{PreAuthorizedCode: session.PreAuthorizedCode}
+		if opts.TxCode != nil {
+			txCode := *opts.TxCode
+			if txCode.InputMode == "" {
+				txCode.InputMode = NumericInputMode
+			}
+			if txCode.Length == 0 {
+				txCode.Length = defaultTxCodeLength
+			}
+			if session.TxCode, err = randomTxCode(txCode); err != nil {
+				return nil, "", err
+			}
+			grant.TxCode = &txCode
+		}
+		offer.Grants.PreAuthorizedCode = &grant
+	} else {
+		if session.IssuerState, err = randomToken(); err != nil {
+			return nil, "", err
+		}
+		offer.Grants.AuthorizationCode = &AuthorizationCodeGrant{IssuerState: session.IssuerState}
+	}
+	if err = i.opts.Store.PutSession(ctx, session); err != nil {
+		return nil, "", errors.Wrap(err, "storing session")
+	}
+	return &offer, session.TxCode, nil
+}
+
+// Token redeems the grant of a token request for an access token, and the nonce the wallet's first proof must be
+// bound to. Each grant is redeemed once. The access token is bound to the key of the request's DPoP proof, if it
+// carries one, which is required if the issuer requires DPoP. The request's wallet attestation is verified if it
+// carries one, which is required if the issuer requires wallet attestations. Errors are of type *Error.
+func (i *Issuer) Token(ctx context.Context, request TokenRequest, dpopProof string) (*TokenResponse, error) {
+	var walletAttestation *WalletAttestation
+	if request.ClientAttestation != "" || request.ClientAttestationPoP != "" || i.opts.RequireWalletAttestation {
+		var oidErr *Error
+		if walletAttestation, oidErr = i.verifyWalletAttestation(ctx, request); oidErr != nil {
+			return nil, oidErr
+		}
+	}
+
+	var thumbprint string
+	if dpopProof != "" {
+		proof, oidErr := i.verifyDPoPProof(dpopProof, i.tokenEndpoint(), dpop.VerificationOptions{})
+		if oidErr != nil {
+			return nil, oidErr
+		}
+		thumbprint = proof.Thumbprint
+	} else if i.opts.RequireDPoP {
+		return nil, newError(InvalidDPoPProof, "DPoP proof is required")
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var session *Session
+	var err error
+	switch request.GrantType {
+	case PreAuthorizedCodeGrantType:
+		session, err = i.redeemPreAuthorizedCode(ctx, request)
+	case AuthorizationCodeGrantType:
+		session, err = i.redeemAuthorizationCode(ctx, request)
+	default:
+		return nil, newError(UnsupportedGrantType, "grant type<%s> is not supported", request.GrantType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if session.AccessToken, err = randomToken(); err != nil {
+		return nil, newError(ServerError, "generating access token")
+	}
+	session.AccessTokenExpiresAt = now.Add(i.opts.AccessTokenLifetime)
+	session.DPoPThumbprint = thumbprint
+	session.WalletAttestation = walletAttestation
+	if err = i.rotateCNonce(ctx, session); err != nil {
+		return nil, err
+	}
+	tokenType := BearerTokenType
+	if thumbprint != "" {
+		tokenType = dpop.TokenType
+	}
+	return &TokenResponse{
+		AccessToken:     session.AccessToken,
+		TokenType:       tokenType,
+		ExpiresIn:       int(i.opts.AccessTokenLifetime.Seconds()),
+		CNonce:          session.CNonce,
+		CNonceExpiresIn: int(i.opts.CNonceLifetime.Seconds()),
+	}, nil
+}
+
+// redeemPreAuthorizedCode returns the session of a pre-authorized code, checking its transaction code. The code is
+// invalidated once it has been redeemed with maxTxCodeAttempts wrong transaction codes.
+func (i *Issuer) redeemPreAuthorizedCode(ctx context.Context, request TokenRequest) (*Session, error) {
+	if request.PreAuthorizedCode == "" {
+		return nil, newError(InvalidRequest, "pre-authorized code cannot be empty")
+	}
+	session, err := i.opts.Store.GetSessionByPreAuthorizedCode(ctx, request.PreAuthorizedCode)
+	if err != nil {
+		return nil, newError(ServerError, "getting session: %s", err.Error())
+	}
+	if session == nil || time.Now().After(session.ExpiresAt) {
+		return nil, newError(InvalidGrant, "pre-authorized code is not valid")
+	}
+	if session.TxCode != "" {
+		if request.TxCode == "" {
+			return nil, newError(InvalidRequest, "transaction code is required")
+		}
+		if subtle.ConstantTimeCompare([]byte(session.TxCode), []byte(request.TxCode)) != 1 {
+			session.TxCodeAttempts++
+			if session.TxCodeAttempts >= maxTxCodeAttempts {
+				session.PreAuthorizedCode = ""
+			}
+			if err = i.opts.Store.PutSession(ctx, *session); err != nil {
+				return nil, newError(ServerError, "storing session: %s", err.Error())
+			}
+			return nil, newError(InvalidGrant, "transaction code is not valid")
+		}
+	}
+	session.PreAuthorizedCode = ""
+	return session, nil
+}
+
+// redeemAuthorizationCode returns the session of the issuer state the handler redeems an authorization code for
+func (i *Issuer) redeemAuthorizationCode(ctx context.Context, request TokenRequest) (*Session, error) {
+	if i.opts.AuthorizationCodeHandler == nil {
+		return nil, newError(UnsupportedGrantType, "grant type<%s> is not supported", request.GrantType)
+	}
+	issuerState, err := i.opts.AuthorizationCodeHandler.RedeemAuthorizationCode(ctx, request)
+	if err != nil {
+		var oidErr *Error
+		if errors.As(err, &oidErr) {
+			return nil, oidErr
+		}
+		return nil, newError(InvalidGrant, "authorization code is not valid: %s", err.Error())
+	}
+	session, err := i.opts.Store.GetSessionByIssuerState(ctx, issuerState)
+	if err != nil {
+		return nil, newError(ServerError, "getting session: %s", err.Error())
+	}
+	if session == nil || time.Now().After(session.ExpiresAt) {
+		return nil, newError(InvalidGrant, "authorization code is not valid for an offer")
+	}
+	session.IssuerState = ""
+	return session, nil
+}
+
+// Credential issues the credential of a credential request, made with an access token, returning the nonce the
+// wallet's next proof must be bound to. The credential is bound to the key of the request's proof, which is
+// required for credentials supporting cryptographic binding, and must be bound to the session's current nonce.
+// Access tokens bound to a key require a DPoP proof of the key, which is given for those sent with the DPoP scheme.
+// Credentials the credential source defers are answered with a transaction ID instead, for the deferred credential
+// endpoint. Errors are of type *Error; those of invalid proofs carry a fresh nonce.
+func (i *Issuer) Credential(ctx context.Context, accessToken, dpopProof string, request CredentialRequest) (*CredentialResponse, error) {
+	session, authorized, err := i.authorizeCredentials(ctx, accessToken, dpopProof, i.metadata.CredentialEndpoint.String(), []CredentialRequest{request})
+	if err != nil {
+		return nil, err
+	}
+	response, err := i.credentialResponse(ctx, *session, authorized[0])
+	if err != nil {
+		return nil, err
+	}
+	response.CNonce, response.CNonceExpiresIn = session.CNonce, int(i.opts.CNonceLifetime.Seconds())
+	return response, nil
+}
+
+// BatchCredential issues the credentials of the requests of a batch credential request, made with an access token,
+// such as instances of a credential bound to each of several keys of the wallet, returning the nonce the wallet's
+// next proofs must be bound to. The proofs of the requests are bound to the same nonce, which is rotated once for the
+// batch. The batch is issued if each of its requests is valid, as Credential requires, and is otherwise rejected.
+// Errors are of type *Error; those of invalid proofs carry a fresh nonce.
+func (i *Issuer) BatchCredential(ctx context.Context, accessToken, dpopProof string, request BatchCredentialRequest) (*BatchCredentialResponse, error) {
+	if i.metadata.BatchCredentialEndpoint == nil {
+		return nil, newError(InvalidRequest, "batch credential requests are not supported")
+	}
+	if len(request.CredentialRequests) == 0 {
+		return nil, newError(InvalidRequest, "credential requests cannot be empty")
+	}
+	if len(request.CredentialRequests) > i.opts.MaxBatchSize {
+		return nil, newError(InvalidRequest, "batch of %d credential requests exceeds the maximum of %d", len(request.CredentialRequests), i.opts.MaxBatchSize)
+	}
+	session, authorized, err := i.authorizeCredentials(ctx, accessToken, dpopProof, i.metadata.BatchCredentialEndpoint.String(), request.CredentialRequests)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]CredentialResponse, 0, len(authorized))
+	for _, a := range authorized {
+		response, err := i.credentialResponse(ctx, *session, a)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return &BatchCredentialResponse{
+		CredentialResponses: responses,
+		CNonce:              session.CNonce,
+		CNonceExpiresIn:     int(i.opts.CNonceLifetime.Seconds()),
+	}, nil
+}
+
+// authorizedCredential is a credential request authorized by its access token and proof: the requested
+// configuration, and the holder of its proof, by DID or, if the proven key is not a DID's, by key
+type authorizedCredential struct {
+	configurationID string
+	configuration   issuance.CredentialSupported
+	holder          string
+	holderKey       *jwx.PublicKeyJWK
+}
+
+// authorizeCredentials returns the session of the access token of credential requests made to an endpoint, and the
+// authorized requests. The proofs of the requests are verified against the session's nonce, which is rotated once
+// they are verified, or one fails to be, such that each nonce is used for one request, or one batch.
+func (i *Issuer) authorizeCredentials(ctx context.Context, accessToken, dpopProof, endpoint string, requests []CredentialRequest) (*Session, []authorizedCredential, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	session, err := i.opts.Store.GetSessionByAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, nil, newError(ServerError, "getting session: %s", err.Error())
+	}
+	if accessToken == "" || session == nil || time.Now().After(session.AccessTokenExpiresAt) {
+		return nil, nil, newError(InvalidToken, "access token is not valid")
+	}
+	if oidErr := i.verifyBoundAccessToken(*session, dpopProof, endpoint); oidErr != nil {
+		return nil, nil, oidErr
+	}
+	authorized := make([]authorizedCredential, 0, len(requests))
+	for _, request := range requests {
+		configurationID, configuration, oidErr := i.requestedConfiguration(*session, request)
+		if oidErr != nil {
+			return nil, nil, oidErr
+		}
+		authorized = append(authorized, authorizedCredential{configurationID: configurationID, configuration: configuration})
+	}
+
+	var oidErr *Error
+	for j, request := range requests {
+		if request.Proof != nil || len(authorized[j].configuration.CryptographicBindingMethodsSupported) > 0 {
+			if authorized[j].holder, authorized[j].holderKey, oidErr = i.verifyProof(ctx, *session, authorized[j].configuration, request.Proof); oidErr != nil {
+				break
+			}
+		}
+	}
+	if err = i.rotateCNonce(ctx, session); err != nil {
+		return nil, nil, err
+	}
+	if oidErr != nil {
+		oidErr.CNonce, oidErr.CNonceExpiresIn = session.CNonce, int(i.opts.CNonceLifetime.Seconds())
+		return nil, nil, oidErr
+	}
+	return session, authorized, nil
+}
+
+// issueCredential gets the credential of an authorized request from the credential source, and signs it, returning
+// ErrIssuancePending if the source defers its issuance
+func (i *Issuer) issueCredential(ctx context.Context, session Session, authorized authorizedCredential) (any, error) {
+	cred, err := i.source.GetCredential(ctx, session, authorized.configurationID, authorized.holder, authorized.holderKey)
+	if errors.Is(err, ErrIssuancePending) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, newError(ServerError, "getting credential<%s>: %s", authorized.configurationID, err.Error())
+	}
+	if cred == nil {
+		return nil, newError(ServerError, "credential<%s> cannot be empty", authorized.configurationID)
+	}
+	signed, oidErr := i.signCredential(authorized.configuration.Format, *cred, authorized.holderKey)
+	if oidErr != nil {
+		return nil, oidErr
+	}
+	return signed, nil
+}
+
+// requestedConfiguration returns the configuration of the session requested by ID, or by format and types
+func (i *Issuer) requestedConfiguration(session Session, request CredentialRequest) (string, issuance.CredentialSupported, *Error) {
+	if request.CredentialConfigurationID != "" {
+		if !util.Contains(request.CredentialConfigurationID, session.CredentialConfigurationIDs) {
+			return "", issuance.CredentialSupported{}, newError(UnsupportedCredentialType, "credential configuration<%s> was not offered", request.CredentialConfigurationID)
+		}
+		return request.CredentialConfigurationID, i.metadata.CredentialsSupported[request.CredentialConfigurationID], nil
+	}
+	if request.Format == "" {
+		return "", issuance.CredentialSupported{}, newError(InvalidRequest, "credential configuration id or format is required")
+	}
+
+	formatOffered := false
+	for _, id := range session.CredentialConfigurationIDs {
+		configuration := i.metadata.CredentialsSupported[id]
+		if string(configuration.Format) != request.Format {
+			continue
+		}
+		formatOffered = true
+		if request.CredentialDefinition == nil || configuration.JWTVCJSONCredentialMetadata == nil ||
+			sameTypes(request.CredentialDefinition.Type, configuration.Types) {
+			return id, configuration, nil
+		}
+	}
+	if !formatOffered {
+		return "", issuance.CredentialSupported{}, newError(UnsupportedCredentialFormat, "format<%s> was not offered", request.Format)
+	}
+	return "", issuance.CredentialSupported{}, newError(UnsupportedCredentialType, "no credential of the requested types was offered")
+}
+
+// verifyProof verifies a JWT proof of possession of a key, or a key attestation, bound to the session's nonce and
+// the issuer, returning the DID of the key, if the key is a DID's, or else the key
+func (i *Issuer) verifyProof(ctx context.Context, session Session, configuration issuance.CredentialSupported, proof *Proof) (string, *jwx.PublicKeyJWK, *Error) {
+	if proof == nil {
+		return "", nil, newError(InvalidProof, "proof is required")
+	}
+	if proof.ProofType == AttestationProofType {
+		return i.verifyAttestationProof(ctx, session, configuration, proof.Attestation)
+	}
+	if proof.ProofType != JWTProofType || proof.JWT == "" {
+		return "", nil, newError(InvalidProof, "proof type<%s> is not supported", proof.ProofType)
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(proof.JWT))
+	if err != nil {
+		return "", nil, newError(InvalidProof, "getting proof headers: %s", err.Error())
+	}
+	if headers.Type() != ProofJWTType {
+		return "", nil, newError(InvalidProof, "proof typ<%s> must be %s", headers.Type(), ProofJWTType)
+	}
+
+	// the key is a DID's verification method, by kid, or a JWK
+	var holder string
+	var verifier *jwx.Verifier
+	var proofKey any
+	if kid := headers.KeyID(); kid != "" {
+		if i.opts.Resolver == nil {
+			return "", nil, newError(InvalidProof, "proofs by kid are not supported")
+		}
+		holder, _, _ = strings.Cut(kid, "#")
+		key, err := resolution.ResolveKeyForDID(ctx, i.opts.Resolver, holder, kid)
+		if err != nil {
+			return "", nil, newError(InvalidProof, "resolving proof key<%s>: %s", kid, err.Error())
+		}
+		if verifier, err = jwx.NewJWXVerifier(holder, &kid, key); err != nil {
+			return "", nil, newError(InvalidProof, "proof key<%s>: %s", kid, err.Error())
+		}
+		proofKey = key
+	} else if jwk := headers.JWK(); jwk != nil {
+		if err = jwk.Raw(&proofKey); err != nil {
+			return "", nil, newError(InvalidProof, "proof jwk: %s", err.Error())
+		}
+		if verifier, err = jwx.NewJWXVerifier(JWTProofType, nil, proofKey); err != nil {
+			return "", nil, newError(InvalidProof, "proof jwk: %s", err.Error())
+		}
+	} else {
+		return "", nil, newError(InvalidProof, "proof must have a kid or jwk header")
+	}
+	if !bindingSupported(configuration, holder) {
+		return "", nil, newError(InvalidProof, "proof key is not of a supported binding method")
+	}
+
+	_, token, err := verifier.VerifyAndParse(proof.JWT)
+	if err != nil {
+		return "", nil, newError(InvalidProof, "verifying proof: %s", err.Error())
+	}
+	if !util.Contains(i.metadata.CredentialIssuer.String(), token.Audience()) {
+		return "", nil, newError(InvalidProof, "proof audience must be the credential issuer")
+	}
+	nonce, _ := token.Get(integrity.NonceProperty)
+	if session.CNonce == "" || nonce != session.CNonce || time.Now().After(session.CNonceExpiresAt) {
+		return "", nil, newError(InvalidProof, "proof nonce is not valid")
+	}
+	now := time.Now()
+	if token.IssuedAt().IsZero() || token.IssuedAt().After(now.Add(i.opts.ProofLeeway)) ||
+		token.IssuedAt().Before(now.Add(-i.opts.CNonceLifetime-i.opts.ProofLeeway)) {
+		return "", nil, newError(InvalidProof, "proof iat is not valid")
+	}
+	if oidErr := i.verifyProofKeyAttestation(ctx, headers, proofKey); oidErr != nil {
+		return "", nil, oidErr
+	}
+	if holder != "" {
+		return holder, nil, nil
+	}
+	holderKey, err := jwx.PublicKeyToPublicKeyJWK(nil, proofKey)
+	if err != nil {
+		return "", nil, newError(InvalidProof, "proof jwk: %s", err.Error())
+	}
+	return "", holderKey, nil
+}
+
+// bindingSupported returns whether the configuration supports binding credentials to a DID or, if holder is empty,
+// to a JWK. Configurations without binding methods support either.
+func bindingSupported(configuration issuance.CredentialSupported, holder string) bool {
+	if len(configuration.CryptographicBindingMethodsSupported) == 0 {
+		return true
+	}
+	for _, method := range configuration.CryptographicBindingMethodsSupported {
+		switch {
+		case holder == "" && method == issuance.JWKFormat:
+			return true
+		case holder != "" && method == issuance.AllDIDMethods:
+			return true
+		case holder != "" && strings.HasPrefix(holder, string(method)+":"):
+			return true
+		}
+	}
+	return false
+}
+
+// signCredential signs a credential in a format: JWT formats as a string, and ldp_vc as a credential with an
+// embedded proof. Credentials secured with SD-JWT make each claim of their credential subject but its id selectively
+// disclosable. JWT credentials bound to a holder's key which is not a DID's carry the key in a `cnf` claim.
+func (i *Issuer) signCredential(format issuance.Format, cred credential.VerifiableCredential, holderKey *jwx.PublicKeyJWK) (any, *Error) {
+	switch format {
+	case issuance.JWTVCJSON, issuance.JWTVCJSONLD, issuance.SDJWTVC:
+		if i.opts.JWTSigner == nil {
+			return nil, newError(UnsupportedCredentialFormat, "format<%s> is not supported", format)
+		}
+		var signed []byte
+		var err error
+		if format == issuance.SDJWTVC {
+			var disclosable []string
+			for claim := range cred.CredentialSubject {
+				if claim != credential.VerifiableCredentialIDProperty {
+					disclosable = append(disclosable, claim)
+				}
+			}
+			sort.Strings(disclosable)
+			if holderKey != nil {
+				signed, err = integrity.SignVerifiableCredentialSDJWTWithConfirmationKey(*i.opts.JWTSigner, cred, *holderKey, disclosable...)
+			} else {
+				signed, err = integrity.SignVerifiableCredentialSDJWT(*i.opts.JWTSigner, cred, disclosable...)
+			}
+		} else if holderKey != nil {
+			signed, err = integrity.SignVerifiableCredentialJWTWithConfirmationKey(*i.opts.JWTSigner, cred, *holderKey)
+		} else {
+			signed, err = integrity.SignVerifiableCredentialJWT(*i.opts.JWTSigner, cred)
+		}
+		if err != nil {
+			return nil, newError(ServerError, "signing credential: %s", err.Error())
+		}
+		return string(signed), nil
+	case issuance.LDPVC:
+		if i.opts.LDSigner == nil {
+			return nil, newError(UnsupportedCredentialFormat, "format<%s> is not supported", format)
+		}
+		if err := i.opts.LDSuite.Sign(i.opts.LDSigner, &cred); err != nil {
+			return nil, newError(ServerError, "signing credential: %s", err.Error())
+		}
+		return cred, nil
+	}
+	return nil, newError(UnsupportedCredentialFormat, "format<%s> is not supported", format)
+}
+
+// tokenEndpoint returns the URL of the issuer's token endpoint, which DPoP proofs of token requests are bound to
+func (i *Issuer) tokenEndpoint() string {
+	return strings.TrimSuffix(i.metadata.CredentialIssuer.String(), "/") + TokenPath
+}
+
+// verifyBoundAccessToken verifies the DPoP proof of a request to an endpoint made with the session's access token,
+// which is required if the access token is bound to a key, and must be of that key
+func (i *Issuer) verifyBoundAccessToken(session Session, dpopProof, endpoint string) *Error {
+	if session.DPoPThumbprint == "" {
+		if dpopProof != "" {
+			return newError(InvalidToken, "access token is not bound to a DPoP key")
+		}
+		return nil
+	}
+	if dpopProof == "" {
+		return newError(InvalidDPoPProof, "DPoP proof is required")
+	}
+	_, oidErr := i.verifyDPoPProof(dpopProof, endpoint, dpop.VerificationOptions{
+		AccessToken: session.AccessToken,
+		Thumbprint:  session.DPoPThumbprint,
+	})
+	return oidErr
+}
+
+// verifyDPoPProof verifies a DPoP proof of a POST request to an endpoint of the issuer, bound to one of its nonces if
+// it requires them
+func (i *Issuer) verifyDPoPProof(proof, endpoint string, opts dpop.VerificationOptions) (*dpop.Proof, *Error) {
+	if i.opts.DPoPNonces {
+		nonces, err := i.currentDPoPNonces()
+		if err != nil {
+			return nil, newError(ServerError, "%s", err.Error())
+		}
+		opts.Nonces = nonces
+	}
+	opts.Leeway = i.opts.ProofLeeway
+	opts.ReplayCache = i.opts.DPoPReplayCache
+	verified, err := dpop.VerifyProof(proof, http.MethodPost, endpoint, opts)
+	if errors.Is(err, dpop.ErrUseNonce) {
+		return nil, newError(UseDPoPNonce, "DPoP proof must be bound to the issuer's nonce")
+	}
+	if err != nil {
+		return nil, newError(InvalidDPoPProof, "%s", err.Error())
+	}
+	return verified, nil
+}
+
+// DPoPNonce returns the nonce DPoP proofs must be bound to, which is empty unless the issuer requires nonces
+func (i *Issuer) DPoPNonce() (string, error) {
+	if !i.opts.DPoPNonces {
+		return "", nil
+	}
+	nonces, err := i.currentDPoPNonces()
+	if err != nil {
+		return "", err
+	}
+	return nonces[0], nil
+}
+
+// currentDPoPNonces returns the current DPoP nonce, rotating it once it expires, and the previous nonce, which is
+// accepted until the current one expires such that proofs made just before a rotation are not rejected
+func (i *Issuer) currentDPoPNonces() ([]string, error) {
+	i.dpopMu.Lock()
+	defer i.dpopMu.Unlock()
+	if len(i.dpopNonces) > 0 && time.Now().Before(i.dpopNonceExpiresAt) {
+		return i.dpopNonces, nil
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	if len(i.dpopNonces) > 0 {
+		i.dpopNonces = []string{nonce, i.dpopNonces[0]}
+	} else {
+		i.dpopNonces = []string{nonce}
+	}
+	i.dpopNonceExpiresAt = time.Now().Add(defaultDPoPNonceLifetime)
+	return i.dpopNonces, nil
+}
+
+// rotateCNonce replaces the session's nonce, storing the session
+func (i *Issuer) rotateCNonce(ctx context.Context, session *Session) error {
+	nonce, err := randomToken()
+	if err != nil {
+		return newError(ServerError, "generating nonce")
+	}
+	session.CNonce, session.CNonceExpiresAt = nonce, time.Now().Add(i.opts.CNonceLifetime)
+	if err = i.opts.Store.PutSession(ctx, *session); err != nil {
+		return newError(ServerError, "storing session: %s", err.Error())
+	}
+	return nil
+}
+
+// sameTypes returns whether two lists of types have the same types, in any order
+func sameTypes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, t := range a {
+		if !util.Contains(t, b) {
+			return false
+		}
+	}
+	return true
+}
+
+// randomToken returns a random, URL safe, code, token, or nonce
+func randomToken() (string, error) {
+	token := make([]byte, tokenSize)
+	if _, err := rand.Read(token); err != nil {
+		return "", errors.Wrap(err, "generating random token")
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// randomTxCode returns a random transaction code of the given input mode and length
+func randomTxCode(txCode TxCode) (string, error) {
+	characters := "0123456789"
+	if txCode.InputMode == TextInputMode {
+		characters = textTxCodeCharacters
+	}
+	code := make([]byte, txCode.Length)
+	for j := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(characters))))
+		if err != nil {
+			return "", errors.Wrap(err, "generating transaction code")
+		}
+		code[j] = characters[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// MemorySessionStore is a SessionStore holding sessions in memory
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+var _ SessionStore = (*MemorySessionStore)(nil)
+
+// NewMemorySessionStore returns an empty MemorySessionStore
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]Session)}
+}
+
+// PutSession stores a session, replacing the session of the same ID
+func (s *MemorySessionStore) PutSession(_ context.Context, session Session) error {
+	if session.ID == "" {
+		return errors.New("session id cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.ID] = session
+	return nil
+}
+
+// GetSessionByPreAuthorizedCode returns the session of a pre-authorized code
+func (s *MemorySessionStore) GetSessionByPreAuthorizedCode(_ context.Context, code string) (*Session, error) {
+	return s.find(func(session Session) bool { return session.PreAuthorizedCode == code }, code), nil
+}
+
+// GetSessionByIssuerState returns the session of an issuer state
+func (s *MemorySessionStore) GetSessionByIssuerState(_ context.Context, issuerState string) (*Session, error) {
+	return s.find(func(session Session) bool { return session.IssuerState == issuerState }, issuerState), nil
+}
+
+// GetSessionByAccessToken returns the session of an access token
+func (s *MemorySessionStore) GetSessionByAccessToken(_ context.Context, accessToken string) (*Session, error) {
+	return s.find(func(session Session) bool { return session.AccessToken == accessToken }, accessToken), nil
+}
+
+// find returns the session matching a non-empty value
+func (s *MemorySessionStore) find(matches func(Session) bool, value string) *Session {
+	if value == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, session := range s.sessions {
+		if matches(session) {
+			return &session
+		}
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package oid4vci
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/oidc/qr"
+)
+
+// OpenID for Verifiable Credential Issuance defines how wallets obtain credentials from issuers with OAuth 2.0
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html
+
+const (
+	// CredentialIssuerMetadataPath is the path credential issuer metadata is served at, relative to the issuer
+	CredentialIssuerMetadataPath string = "/.well-known/openid-credential-issuer"
+	// TokenPath is the path of the token endpoint of issuers acting as their own authorization server
+	TokenPath string = "/token"
+
+	// CredentialOfferScheme is the scheme of URIs passing credential offers to wallets
+	CredentialOfferScheme string = "openid-credential-offer://"
+	// CredentialOfferParameter is the query parameter of a credential offer passed by value
+	CredentialOfferParameter string = "credential_offer"
+	// CredentialOfferURIParameter is the query parameter of a credential offer passed by reference
+	CredentialOfferURIParameter string = "credential_offer_uri"
+
+	// AuthorizationCodeGrantType is the grant of an authorization code, obtained by the wallet from the authorization
+	// endpoint
+	AuthorizationCodeGrantType string = "authorization_code"
+	// PreAuthorizedCodeGrantType is the grant of a pre-authorized code, given to the wallet in a credential offer
+	PreAuthorizedCodeGrantType string = "urn:ietf:params:oauth:grant-type:pre-authorized_code"
+
+	// BearerTokenType is the type of access tokens which are not bound to a key with DPoP
+	BearerTokenType string = "Bearer"
+
+	// JWTProofType is the type of proofs of possession of a key which are JWTs
+	JWTProofType string = "jwt"
+	// ProofJWTType is the `typ` header of JWT proofs
+	ProofJWTType string = "openid4vci-proof+jwt"
+)
+
+// CredentialOffer offers credentials to a wallet, with the grants the wallet may obtain an access token with
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-credential-offer-parameters
+type CredentialOffer struct {
+	CredentialIssuer string `json:"credential_issuer"`
+	// CredentialConfigurationIDs are the IDs of the offered credentials supported by the issuer
+	CredentialConfigurationIDs []string `json:"credential_configuration_ids"`
+	Grants                     *Grants  `json:"grants,omitempty"`
+}
+
+// URI returns the URI passing the offer to a wallet by value
+func (o CredentialOffer) URI() (string, error) {
+	offerJSON, err := json.Marshal(o)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling credential offer")
+	}
+	return CredentialOfferScheme + "?" + url.Values{CredentialOfferParameter: {string(offerJSON)}}.Encode(), nil
+}
+
+// CredentialOfferReferenceURI returns the URI passing an offer to a wallet by reference, the wallet fetching the
+// offer from the given URI
+func CredentialOfferReferenceURI(offerURI string) string {
+	return CredentialOfferScheme + "?" + url.Values{CredentialOfferURIParameter: {offerURI}}.Encode()
+}
+
+// QRCode returns a QR code passing the offer to a wallet by value or, if the offer does not fit in a QR code of the
+// options' maximum version, by reference to the given offer URI. Whether the offer is passed by reference is returned,
+// in which case the issuer must serve the offer at the offer URI.
+func (o CredentialOffer) QRCode(offerURI string, opts qr.Options) (*qr.Code, bool, error) {
+	uri, err := o.URI()
+	if err != nil {
+		return nil, false, err
+	}
+	byReference := !qr.Fits(uri, opts)
+	if byReference {
+		if offerURI == "" {
+			return nil, false, errors.New("credential offer does not fit in a QR code, and has no offer uri")
+		}
+		uri = CredentialOfferReferenceURI(offerURI)
+	}
+	code, err := qr.Encode(uri, opts)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "encoding QR code")
+	}
+	return code, byReference, nil
+}
+
+// Grants are the grants a wallet may obtain an access token with
+type Grants struct {
+	AuthorizationCode *AuthorizationCodeGrant `json:"authorization_code,omitempty"`
+	PreAuthorizedCode *PreAuthorizedCodeGrant `json:"urn:ietf:params:oauth:grant-type:pre-authorized_code,omitempty"`
+}
+
+// AuthorizationCodeGrant is the grant of an authorization code
+type AuthorizationCodeGrant struct {
+	// IssuerState binds the authorization request to the offer
+	IssuerState         string `json:"issuer_state,omitempty"`
+	AuthorizationServer string `json:"authorization_server,omitempty"`
+}
+
+// PreAuthorizedCodeGrant is the grant of a pre-authorized code, which may require a transaction code sent to the
+// end-user out of band
+type PreAuthorizedCodeGrant struct {
+	PreAuthorizedCode   string  `json:"pre-authorized_code"`
+	TxCode              *TxCode `json:"tx_code,omitempty"`
+	Interval            int     `json:"interval,omitempty"`
+	AuthorizationServer string  `json:"authorization_server,omitempty"`
+}
+
+// TxCodeInputMode is the characters of a transaction code
+type TxCodeInputMode string
+
+const (
+	NumericInputMode TxCodeInputMode = "numeric"
+	TextInputMode    TxCodeInputMode = "text"
+)
+
+// TxCode describes the transaction code a wallet must send with a pre-authorized code, for it to prompt the end-user
+type TxCode struct {
+	InputMode   TxCodeInputMode `json:"input_mode,omitempty"`
+	Length      int             `json:"length,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+// AuthorizationRequest requests authorization to obtain credentials with the authorization code flow, by the
+// authorization details of the credentials, or by scope
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-authorization-request
+type AuthorizationRequest struct {
+	ResponseType         string
+	ClientID             string
+	RedirectURI          string
+	State                string
+	Scope                string
+	AuthorizationDetails []AuthorizationDetail
+	// IssuerState is that of the authorization code grant of the offer the request is made for, if any
+	IssuerState         string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// AuthorizationDetail requests authorization to obtain the credential of a configuration
+type AuthorizationDetail struct {
+	Type                      string `json:"type"`
+	CredentialConfigurationID string `json:"credential_configuration_id"`
+}
+
+// OpenIDCredentialAuthorizationDetailType is the type of authorization details of credentials
+const OpenIDCredentialAuthorizationDetailType string = "openid_credential"
+
+// Form returns the form encoding of the authorization request, whose response type defaults to code
+func (r AuthorizationRequest) Form() (url.Values, error) {
+	responseType := r.ResponseType
+	if responseType == "" {
+		responseType = "code"
+	}
+	form := url.Values{"response_type": {responseType}}
+	for key, value := range map[string]string{
+		"client_id":             r.ClientID,
+		"redirect_uri":          r.RedirectURI,
+		"state":                 r.State,
+		"scope":                 r.Scope,
+		"issuer_state":          r.IssuerState,
+		"code_challenge":        r.CodeChallenge,
+		"code_challenge_method": r.CodeChallengeMethod,
+	} {
+		if value != "" {
+			form.Set(key, value)
+		}
+	}
+	if len(r.AuthorizationDetails) > 0 {
+		details, err := json.Marshal(r.AuthorizationDetails)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling authorization details")
+		}
+		form.Set("authorization_details", string(details))
+	}
+	return form, nil
+}
+
+// TokenRequest is the form of a request to the token endpoint, and the wallet attestation its headers carry
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-token-request
+type TokenRequest struct {
+	GrantType         string
+	PreAuthorizedCode string
+	TxCode            string
+	Code              string
+	RedirectURI       string
+	ClientID          string
+	CodeVerifier      string
+
+	// ClientAttestation and ClientAttestationPoP are the wallet attestation, and the proof of possession of its key,
+	// of the OAuth-Client-Attestation and OAuth-Client-Attestation-PoP headers, which are not part of the form
+	ClientAttestation    string
+	ClientAttestationPoP string
+}
+
+// Form returns the form encoding of the token request
+func (r TokenRequest) Form() url.Values {
+	form := url.Values{"grant_type": {r.GrantType}}
+	for key, value := range map[string]string{
+		"pre-authorized_code": r.PreAuthorizedCode,
+		"tx_code":             r.TxCode,
+		"code":                r.Code,
+		"redirect_uri":        r.RedirectURI,
+		"client_id":           r.ClientID,
+		"code_verifier":       r.CodeVerifier,
+	} {
+		if value != "" {
+			form.Set(key, value)
+		}
+	}
+	return form
+}
+
+// TokenRequestFromForm returns the token request of a form
+func TokenRequestFromForm(form url.Values) TokenRequest {
+	return TokenRequest{
+		GrantType:         form.Get("grant_type"),
+		PreAuthorizedCode: form.Get("pre-authorized_code"),
+		TxCode:            form.Get("tx_code"),
+		Code:              form.Get("code"),
+		RedirectURI:       form.Get("redirect_uri"),
+		ClientID:          form.Get("client_id"),
+		CodeVerifier:      form.Get("code_verifier"),
+	}
+}
+
+// TokenResponse is the response of the token endpoint, with the nonce the wallet's first proof must be bound to
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-token-response
+type TokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int    `json:"expires_in,omitempty"`
+	CNonce          string `json:"c_nonce,omitempty"`
+	CNonceExpiresIn int    `json:"c_nonce_expires_in,omitempty"`
+}
+
+// CredentialRequest requests a credential from the credential endpoint, by the ID of its configuration or by its
+// format and credential definition
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-credential-request
+type CredentialRequest struct {
+	CredentialConfigurationID string                `json:"credential_configuration_id,omitempty"`
+	Format                    string                `json:"format,omitempty"`
+	CredentialDefinition      *CredentialDefinition `json:"credential_definition,omitempty"`
+	Proof                     *Proof                `json:"proof,omitempty"`
+}
+
+// BatchCredentialRequest requests several credentials from the batch credential endpoint at once, such as instances
+// of a credential bound to each of several keys of the wallet. The proofs of its requests are bound to the same nonce.
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0-13.html#name-batch-credential-request
+type BatchCredentialRequest struct {
+	CredentialRequests []CredentialRequest `json:"credential_requests"`
+}
+
+// BatchCredentialResponse is the response of the batch credential endpoint, with a credential response for each
+// credential request, in order, and the nonce the wallet's next proofs must be bound to
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0-13.html#name-batch-credential-response
+type BatchCredentialResponse struct {
+	CredentialResponses []CredentialResponse `json:"credential_responses"`
+	CNonce              string               `json:"c_nonce,omitempty"`
+	CNonceExpiresIn     int                  `json:"c_nonce_expires_in,omitempty"`
+}
+
+// CredentialDefinition is the types of a requested credential
+type CredentialDefinition struct {
+	Type []string `json:"type,omitempty"`
+}
+
+// Proof is the proof of possession of the key the credential is to be bound to: a JWT signed by the key, or a key
+// attestation of the key, bound to the issuer's nonce
+type Proof struct {
+	ProofType   string `json:"proof_type"`
+	JWT         string `json:"jwt,omitempty"`
+	Attestation string `json:"attestation,omitempty"`
+}
+
+// CredentialResponse is the response of the credential endpoint, with the nonce the wallet's next proof must be
+// bound to. The credential is a string for credentials secured as JWTs, and an object for those with embedded proofs.
+// Credentials whose issuance is deferred are not in the response, which instead has the transaction ID the wallet
+// obtains them with from the deferred credential endpoint.
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-credential-response
+type CredentialResponse struct {
+	Credential      any    `json:"credential,omitempty"`
+	TransactionID   string `json:"transaction_id,omitempty"`
+	CNonce          string `json:"c_nonce,omitempty"`
+	CNonceExpiresIn int    `json:"c_nonce_expires_in,omitempty"`
+}
+
+// DeferredCredentialRequest requests the credential of a deferred issuance from the deferred credential endpoint, which
+// responds with a CredentialResponse once the credential is issued
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0-13.html#name-deferred-credential-request
+type DeferredCredentialRequest struct {
+	TransactionID string `json:"transaction_id"`
+}
+
+// ErrorCode is the code of an error response of the token or credential endpoint
+type ErrorCode string
+
+const (
+	InvalidRequest              ErrorCode = "invalid_request"
+	InvalidClient               ErrorCode = "invalid_client"
+	InvalidGrant                ErrorCode = "invalid_grant"
+	UnsupportedGrantType        ErrorCode = "unsupported_grant_type"
+	InvalidToken                ErrorCode = "invalid_token"
+	UnsupportedCredentialType   ErrorCode = "unsupported_credential_type"
+	UnsupportedCredentialFormat ErrorCode = "unsupported_credential_format"
+	InvalidProof                ErrorCode = "invalid_proof"
+	InvalidDPoPProof            ErrorCode = "invalid_dpop_proof"
+	UseDPoPNonce                ErrorCode = "use_dpop_nonce"
+	IssuancePending             ErrorCode = "issuance_pending"
+	InvalidTransactionID        ErrorCode = "invalid_transaction_id"
+	ServerError                 ErrorCode = "server_error"
+)
+
+// Error is an error response of the token or credential endpoint. Errors of invalid proofs carry a fresh nonce for
+// the wallet to retry with, and those of pending issuances the interval, in seconds, the wallet must wait before
+// requesting the credential again.
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-credential-error-response
+type Error struct {
+	Code            ErrorCode `json:"error"`
+	Description     string    `json:"error_description,omitempty"`
+	CNonce          string    `json:"c_nonce,omitempty"`
+	CNonceExpiresIn int       `json:"c_nonce_expires_in,omitempty"`
+	Interval        int       `json:"interval,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return string(e.Code)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+func newError(code ErrorCode, format string, args ...any) *Error {
+	return &Error{Code: code, Description: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,1030 @@
+package oid4vci
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/oidc/dpop"
+	"github.com/TBD54566975/ssi-sdk/oidc/federation"
+	"github.com/TBD54566975/ssi-sdk/oidc/issuance"
+	"github.com/TBD54566975/ssi-sdk/oidc/par"
+	"github.com/TBD54566975/ssi-sdk/oidc/qr"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+const testCredentialIssuer = "https://issuer.example.com"
+
+func TestIssuer(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	issuer, holderSigner := getTestIssuer(t, resolver, testCredentialIssuer)
+	ctx := context.Background()
+
+	t.Run("pre-authorized code flow", func(tt *testing.T) {
+		server := httptest.NewServer(NewServeMux(issuer))
+		defer server.Close()
+
+		offer, txCode, err := issuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{
+			PreAuthorized: true,
+			TxCode:        &TxCode{Description: "Sent to your phone"},
+			Data:          map[string]any{"name": "Alice"},
+		})
+		require.NoError(tt, err)
+		assert.Len(tt, txCode, 6)
+		assert.Equal(tt, testCredentialIssuer, offer.CredentialIssuer)
+		grant := offer.Grants.PreAuthorizedCode
+		require.NotNil(tt, grant)
+		assert.Equal(tt, &TxCode{InputMode: NumericInputMode, Length: 6, Description: "Sent to your phone"}, grant.TxCode)
+		uri, err := offer.URI()
+		require.NoError(tt, err)
+		assert.True(tt, strings.HasPrefix(uri, CredentialOfferScheme+"?credential_offer="))
+
+		// the metadata is served at its well-known path
+		resp, err := http.Get(server.URL + CredentialIssuerMetadataPath)
+		require.NoError(tt, err)
+		var metadata issuance.IssuerMetadata
+		require.NoError(tt, json.NewDecoder(resp.Body).Decode(&metadata))
+		_ = resp.Body.Close()
+		assert.Contains(tt, metadata.CredentialsSupported, "jwt")
+
+		request := TokenRequest{GrantType: PreAuthorizedCodeGrantType, PreAuthorizedCode: grant.PreAuthorizedCode, TxCode: "wrong"}
+		status, body := postForm(tt, server.URL+TokenPath, request.Form())
+		assert.Equal(tt, http.StatusBadRequest, status)
+		assert.Contains(tt, body, string(InvalidGrant))
+
+		request.TxCode = txCode
+		status, body = postForm(tt, server.URL+TokenPath, request.Form())
+		require.Equal(tt, http.StatusOK, status, body)
+		var token TokenResponse
+		require.NoError(tt, json.Unmarshal([]byte(body), &token))
+		assert.Equal(tt, BearerTokenType, token.TokenType)
+		assert.NotEmpty(tt, token.CNonce)
+
+		// pre-authorized codes are redeemed once
+		status, body = postForm(tt, server.URL+TokenPath, request.Form())
+		assert.Equal(tt, http.StatusBadRequest, status)
+		assert.Contains(tt, body, string(InvalidGrant))
+
+		proof := getTestProof(tt, holderSigner, testCredentialIssuer, token.CNonce)
+		credentialRequest := CredentialRequest{
+			Format:               string(issuance.JWTVCJSON),
+			CredentialDefinition: &CredentialDefinition{Type: []string{"UniversityDegreeCredential", "VerifiableCredential"}},
+			Proof:                proof,
+		}
+		status, body = postCredentialRequest(tt, server.URL+"/credential", token.AccessToken, credentialRequest)
+		require.Equal(tt, http.StatusOK, status, body)
+		var credentialResponse CredentialResponse
+		require.NoError(tt, json.Unmarshal([]byte(body), &credentialResponse))
+		assert.NotEqual(tt, token.CNonce, credentialResponse.CNonce)
+
+		issued, ok := credentialResponse.Credential.(string)
+		require.True(tt, ok)
+		verified, err := integrity.VerifyJWTCredential(ctx, issued, resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+		_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(issued)
+		require.NoError(tt, err)
+		assert.Equal(tt, holderSigner.ID, cred.CredentialSubject.GetID())
+		assert.Equal(tt, "Alice", cred.CredentialSubject["name"])
+
+		// nonces are used once, and invalid proofs are answered with a fresh nonce
+		status, body = postCredentialRequest(tt, server.URL+"/credential", token.AccessToken, credentialRequest)
+		assert.Equal(tt, http.StatusBadRequest, status)
+		var proofErr Error
+		require.NoError(tt, json.Unmarshal([]byte(body), &proofErr))
+		assert.Equal(tt, InvalidProof, proofErr.Code)
+		assert.Equal(tt, "proof nonce is not valid", proofErr.Description)
+		assert.NotEmpty(tt, proofErr.CNonce)
+
+		status, _ = postCredentialRequest(tt, server.URL+"/credential", "", credentialRequest)
+		assert.Equal(tt, http.StatusUnauthorized, status)
+	})
+
+	t.Run("transaction code attempts are limited", func(tt *testing.T) {
+		offer, txCode, err := issuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{
+			PreAuthorized: true,
+			TxCode:        &TxCode{},
+		})
+		require.NoError(tt, err)
+		request := TokenRequest{GrantType: PreAuthorizedCodeGrantType, PreAuthorizedCode: offer.Grants.PreAuthorizedCode.PreAuthorizedCode, TxCode: "wrong"}
+		for attempt := 0; attempt < maxTxCodeAttempts; attempt++ {
+			_, err = issuer.Token(ctx, request, "")
+			assert.ErrorContains(tt, err, "transaction code is not valid")
+		}
+
+		request.TxCode = txCode
+		_, err = issuer.Token(ctx, request, "")
+		assert.ErrorContains(tt, err, "pre-authorized code is not valid")
+	})
+
+	t.Run("credential formats", func(tt *testing.T) {
+		offer, _, err := issuer.CreateCredentialOffer(ctx, []string{"ld", "sdjwt"}, OfferOptions{
+			PreAuthorized: true,
+			Data:          map[string]any{"name": "Alice"},
+		})
+		require.NoError(tt, err)
+		token, err := issuer.Token(ctx, TokenRequest{GrantType: PreAuthorizedCodeGrantType, PreAuthorizedCode: offer.Grants.PreAuthorizedCode.PreAuthorizedCode}, "")
+		require.NoError(tt, err)
+
+		ld, err := issuer.Credential(ctx, token.AccessToken, "", CredentialRequest{
+			CredentialConfigurationID: "ld",
+			Proof:                     getTestProof(tt, holderSigner, testCredentialIssuer, token.CNonce),
+		})
+		require.NoError(tt, err)
+		ldCred, ok := ld.Credential.(credential.VerifiableCredential)
+		require.True(tt, ok)
+		verified, err := integrity.VerifyDataIntegrityCredential(ctx, ldCred, resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+
+		sdJWT, err := issuer.Credential(ctx, token.AccessToken, "", CredentialRequest{
+			Format: string(issuance.SDJWTVC),
+			Proof:  getTestProof(tt, holderSigner, testCredentialIssuer, ld.CNonce),
+		})
+		require.NoError(tt, err)
+		_, sdCred, err := integrity.ParseVerifiableCredentialFromSDJWT(sdJWT.Credential.(string))
+		require.NoError(tt, err)
+		assert.Equal(tt, "Alice", sdCred.CredentialSubject["name"])
+
+		// only offered credentials are issued
+		_, err = issuer.Credential(ctx, token.AccessToken, "", CredentialRequest{CredentialConfigurationID: "jwt"})
+		assert.ErrorContains(tt, err, "credential configuration<jwt> was not offered")
+		_, err = issuer.Credential(ctx, token.AccessToken, "", CredentialRequest{Format: string(issuance.JWTVCJSON)})
+		assert.ErrorContains(tt, err, "format<jwt_vc_json> was not offered")
+
+		// credentials supporting binding require a proof
+		_, err = issuer.Credential(ctx, token.AccessToken, "", CredentialRequest{CredentialConfigurationID: "ld"})
+		assert.ErrorContains(tt, err, "proof is required")
+	})
+
+	t.Run("batch credentials", func(tt *testing.T) {
+		server := httptest.NewServer(NewServeMux(issuer))
+		defer server.Close()
+
+		offer, _, err := issuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{
+			PreAuthorized: true,
+			Data:          map[string]any{"name": "Alice"},
+		})
+		require.NoError(tt, err)
+		token, err := issuer.Token(ctx, TokenRequest{GrantType: PreAuthorizedCodeGrantType, PreAuthorizedCode: offer.Grants.PreAuthorizedCode.PreAuthorizedCode}, "")
+		require.NoError(tt, err)
+
+		// an instance of the credential is issued for each holder key, with proofs bound to the same nonce
+		holderSigners := []jwx.Signer{holderSigner, getTestDIDKeySigner(tt)}
+		batch := BatchCredentialRequest{}
+		for _, signer := range holderSigners {
+			batch.CredentialRequests = append(batch.CredentialRequests, CredentialRequest{
+				CredentialConfigurationID: "jwt",
+				Proof:                     getTestProof(tt, signer, testCredentialIssuer, token.CNonce),
+			})
+		}
+		body, err := json.Marshal(batch)
+		require.NoError(tt, err)
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/batch_credential", strings.NewReader(string(body)))
+		require.NoError(tt, err)
+		req.Header.Set("Authorization", BearerTokenType+" "+token.AccessToken)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(tt, err)
+		status, respBody := readResponse(tt, resp)
+		require.Equal(tt, http.StatusOK, status, respBody)
+		var response BatchCredentialResponse
+		require.NoError(tt, json.Unmarshal([]byte(respBody), &response))
+		require.Len(tt, response.CredentialResponses, 2)
+		assert.NotEqual(tt, token.CNonce, response.CNonce)
+		for j, signer := range holderSigners {
+			_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(response.CredentialResponses[j].Credential.(string))
+			require.NoError(tt, err)
+			assert.Equal(tt, signer.ID, cred.CredentialSubject.GetID())
+		}
+
+		// the nonce was used by the batch
+		_, err = issuer.BatchCredential(ctx, token.AccessToken, "", batch)
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidProof, oidErr.Code)
+		assert.NotEmpty(tt, oidErr.CNonce)
+
+		// a batch with an invalid proof is rejected as a whole
+		valid := CredentialRequest{CredentialConfigurationID: "jwt", Proof: getTestProof(tt, holderSigner, testCredentialIssuer, oidErr.CNonce)}
+		invalid := CredentialRequest{CredentialConfigurationID: "jwt", Proof: getTestProof(tt, holderSigner, "https://other.example.com", oidErr.CNonce)}
+		_, err = issuer.BatchCredential(ctx, token.AccessToken, "", BatchCredentialRequest{CredentialRequests: []CredentialRequest{valid, invalid}})
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidProof, oidErr.Code)
+
+		_, err = issuer.BatchCredential(ctx, token.AccessToken, "", BatchCredentialRequest{})
+		assert.ErrorContains(tt, err, "credential requests cannot be empty")
+		_, err = issuer.BatchCredential(ctx, token.AccessToken, "", BatchCredentialRequest{CredentialRequests: make([]CredentialRequest, defaultMaxBatchSize+1)})
+		assert.ErrorContains(tt, err, "exceeds the maximum of 10")
+	})
+
+	t.Run("deferred issuance", func(tt *testing.T) {
+		deferredIssuer, deferredHolderSigner := getTestIssuer(tt, resolver, testCredentialIssuer)
+		pending := new(atomic.Int32)
+		pending.Store(2)
+		deferredIssuer.source = testDeferringSource{CredentialSource: deferredIssuer.source, pending: pending}
+
+		offer, _, err := deferredIssuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{
+			PreAuthorized: true,
+			Data:          map[string]any{"name": "Alice"},
+		})
+		require.NoError(tt, err)
+		token, err := deferredIssuer.Token(ctx, TokenRequest{GrantType: PreAuthorizedCodeGrantType, PreAuthorizedCode: offer.Grants.PreAuthorizedCode.PreAuthorizedCode}, "")
+		require.NoError(tt, err)
+		response, err := deferredIssuer.Credential(ctx, token.AccessToken, "", CredentialRequest{
+			CredentialConfigurationID: "jwt",
+			Proof:                     getTestProof(tt, deferredHolderSigner, testCredentialIssuer, token.CNonce),
+		})
+		require.NoError(tt, err)
+		assert.Nil(tt, response.Credential)
+		assert.NotEmpty(tt, response.TransactionID)
+		assert.NotEmpty(tt, response.CNonce)
+
+		// the wallet waits at the issuer's interval while the credential is pending
+		request := DeferredCredentialRequest{TransactionID: response.TransactionID}
+		_, err = deferredIssuer.DeferredCredential(ctx, token.AccessToken, "", request)
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, IssuancePending, oidErr.Code)
+		assert.Equal(tt, 5, oidErr.Interval)
+
+		// transaction ids are redeemed with the access token they were issued for
+		otherOffer, _, err := deferredIssuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{PreAuthorized: true})
+		require.NoError(tt, err)
+		otherToken, err := deferredIssuer.Token(ctx, TokenRequest{GrantType: PreAuthorizedCodeGrantType, PreAuthorizedCode: otherOffer.Grants.PreAuthorizedCode.PreAuthorizedCode}, "")
+		require.NoError(tt, err)
+		_, err = deferredIssuer.DeferredCredential(ctx, otherToken.AccessToken, "", request)
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidTransactionID, oidErr.Code)
+		_, err = deferredIssuer.DeferredCredential(ctx, token.AccessToken, "", DeferredCredentialRequest{TransactionID: "unknown"})
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidTransactionID, oidErr.Code)
+
+		deferred, err := deferredIssuer.DeferredCredential(ctx, token.AccessToken, "", request)
+		require.NoError(tt, err)
+		_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(deferred.Credential.(string))
+		require.NoError(tt, err)
+		assert.Equal(tt, deferredHolderSigner.ID, cred.CredentialSubject.GetID())
+		assert.Equal(tt, "Alice", cred.CredentialSubject["name"])
+
+		// transaction ids are redeemed once
+		_, err = deferredIssuer.DeferredCredential(ctx, token.AccessToken, "", request)
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidTransactionID, oidErr.Code)
+	})
+
+	t.Run("authorization code grant", func(tt *testing.T) {
+		offer, txCode, err := issuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{})
+		require.NoError(tt, err)
+		assert.Empty(tt, txCode)
+		require.NotNil(tt, offer.Grants.AuthorizationCode)
+
+		request := TokenRequest{GrantType: AuthorizationCodeGrantType, Code: "code"}
+		_, err = issuer.Token(ctx, request, "")
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, UnsupportedGrantType, oidErr.Code)
+
+		withHandler, _ := getTestIssuer(tt, resolver, testCredentialIssuer)
+		withHandler.opts.AuthorizationCodeHandler = testAuthorizationCodeHandler{"code": offer.Grants.AuthorizationCode.IssuerState}
+		withHandler.opts.Store = issuer.opts.Store
+		token, err := withHandler.Token(ctx, request, "")
+		require.NoError(tt, err)
+		assert.NotEmpty(tt, token.AccessToken)
+	})
+
+	t.Run("DPoP bound access tokens", func(tt *testing.T) {
+		dpopSigner, err := dpop.NewSigner(crypto.P256)
+		require.NoError(tt, err)
+		offer, _, err := issuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{PreAuthorized: true})
+		require.NoError(tt, err)
+		tokenProof, err := dpop.CreateProof(*dpopSigner, http.MethodPost, testCredentialIssuer+TokenPath, dpop.ProofOptions{})
+		require.NoError(tt, err)
+		token, err := issuer.Token(ctx, TokenRequest{GrantType: PreAuthorizedCodeGrantType, PreAuthorizedCode: offer.Grants.PreAuthorizedCode.PreAuthorizedCode}, tokenProof)
+		require.NoError(tt, err)
+		assert.Equal(tt, dpop.TokenType, token.TokenType)
+
+		request := CredentialRequest{CredentialConfigurationID: "jwt", Proof: getTestProof(tt, holderSigner, testCredentialIssuer, token.CNonce)}
+		_, err = issuer.Credential(ctx, token.AccessToken, "", request)
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidDPoPProof, oidErr.Code)
+
+		// proofs of protected resources are bound to the access token
+		unbound, err := dpop.CreateProof(*dpopSigner, http.MethodPost, testCredentialIssuer+"/credential", dpop.ProofOptions{})
+		require.NoError(tt, err)
+		_, err = issuer.Credential(ctx, token.AccessToken, unbound, request)
+		assert.ErrorContains(tt, err, "proof ath is not the hash of the access token")
+
+		credentialProof, err := dpop.CreateProof(*dpopSigner, http.MethodPost, testCredentialIssuer+"/credential", dpop.ProofOptions{AccessToken: token.AccessToken})
+		require.NoError(tt, err)
+		response, err := issuer.Credential(ctx, token.AccessToken, credentialProof, request)
+		require.NoError(tt, err)
+		assert.NotEmpty(tt, response.Credential)
+
+		// proofs are accepted once
+		request.Proof = getTestProof(tt, holderSigner, testCredentialIssuer, response.CNonce)
+		_, err = issuer.Credential(ctx, token.AccessToken, credentialProof, request)
+		assert.ErrorContains(tt, err, "was replayed")
+
+		// and must be of the key the access token is bound to
+		otherSigner, err := dpop.NewSigner(crypto.Ed25519)
+		require.NoError(tt, err)
+		otherProof, err := dpop.CreateProof(*otherSigner, http.MethodPost, testCredentialIssuer+"/credential", dpop.ProofOptions{AccessToken: token.AccessToken})
+		require.NoError(tt, err)
+		_, err = issuer.Credential(ctx, token.AccessToken, otherProof, request)
+		assert.ErrorContains(tt, err, "proof key is not the key the access token is bound to")
+	})
+
+	t.Run("wallet and key attestations", func(tt *testing.T) {
+		attestedIssuer, attestedHolderSigner := getTestIssuer(tt, resolver, testCredentialIssuer)
+		providerSigner := getTestDIDKeySigner(tt)
+		attestedIssuer.opts.AttestationValidator = testAttestationValidator{provider: providerSigner.ID}
+		attestedIssuer.opts.RequireWalletAttestation = true
+		attestedIssuer.opts.RequireKeyAttestation = true
+
+		_, instanceKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		instanceSigner, err := jwx.NewJWXSigner("wallet-instance", nil, instanceKey)
+		require.NoError(tt, err)
+		walletAttestation, err := CreateWalletAttestation(providerSigner, WalletAttestation{
+			Subject:      instanceSigner.ID,
+			Confirmation: Confirmation{JWK: instanceSigner.PrivateKeyJWK.ToPublicKeyJWK()},
+			WalletName:   "Test Wallet",
+		}, time.Hour)
+		require.NoError(tt, err)
+
+		offer, _, err := attestedIssuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{PreAuthorized: true})
+		require.NoError(tt, err)
+		request := TokenRequest{GrantType: PreAuthorizedCodeGrantType, PreAuthorizedCode: offer.Grants.PreAuthorizedCode.PreAuthorizedCode}
+		_, err = attestedIssuer.Token(ctx, request, "")
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidClient, oidErr.Code)
+		assert.Equal(tt, "wallet attestation and its proof of possession are required", oidErr.Description)
+
+		// the wallet instance proves possession of the attested key, for the issuer
+		request.ClientAttestation = walletAttestation
+		request.ClientAttestationPoP, err = NewWalletAttestationPoP(*instanceSigner, instanceSigner.ID, "https://other.example.com")
+		require.NoError(tt, err)
+		_, err = attestedIssuer.Token(ctx, request, "")
+		assert.ErrorContains(tt, err, "wallet attestation pop audience must be the credential issuer")
+		request.ClientAttestationPoP, err = NewWalletAttestationPoP(attestedHolderSigner, instanceSigner.ID, testCredentialIssuer)
+		require.NoError(tt, err)
+		_, err = attestedIssuer.Token(ctx, request, "")
+		assert.ErrorContains(tt, err, "verifying wallet attestation pop")
+
+		// of an attestation of a trusted wallet provider
+		untrustedAttestation, err := CreateWalletAttestation(getTestDIDKeySigner(tt), WalletAttestation{
+			Subject:      instanceSigner.ID,
+			Confirmation: Confirmation{JWK: instanceSigner.PrivateKeyJWK.ToPublicKeyJWK()},
+		}, time.Hour)
+		require.NoError(tt, err)
+		request.ClientAttestation = untrustedAttestation
+		request.ClientAttestationPoP, err = NewWalletAttestationPoP(*instanceSigner, instanceSigner.ID, testCredentialIssuer)
+		require.NoError(tt, err)
+		_, err = attestedIssuer.Token(ctx, request, "")
+		assert.ErrorContains(tt, err, "wallet attestation is not trusted")
+
+		request.ClientAttestation = walletAttestation
+		token, err := attestedIssuer.Token(ctx, request, "")
+		require.NoError(tt, err)
+		session, err := attestedIssuer.opts.Store.GetSessionByAccessToken(ctx, token.AccessToken)
+		require.NoError(tt, err)
+		require.NotNil(tt, session.WalletAttestation)
+		assert.Equal(tt, "Test Wallet", session.WalletAttestation.WalletName)
+		assert.Equal(tt, providerSigner.ID, session.WalletAttestation.Issuer)
+
+		// proofs carry an attestation of their key
+		credentialRequest := CredentialRequest{CredentialConfigurationID: "jwt", Proof: getTestProof(tt, attestedHolderSigner, testCredentialIssuer, token.CNonce)}
+		_, err = attestedIssuer.Credential(ctx, token.AccessToken, "", credentialRequest)
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, "key attestation is required", oidErr.Description)
+
+		holderJWK := attestedHolderSigner.PrivateKeyJWK.ToPublicKeyJWK()
+		keyAttestation, err := CreateKeyAttestation(providerSigner, KeyAttestation{
+			AttestedKeys: []jwx.PublicKeyJWK{holderJWK},
+			KeyStorage:   []string{"iso_18045_high"},
+		}, time.Hour)
+		require.NoError(tt, err)
+		credentialRequest.Proof, err = NewKeyAttestedJWTProof(attestedHolderSigner, keyAttestation, testCredentialIssuer, oidErr.CNonce)
+		require.NoError(tt, err)
+		response, err := attestedIssuer.Credential(ctx, token.AccessToken, "", credentialRequest)
+		require.NoError(tt, err)
+		assert.NotEmpty(tt, response.Credential)
+
+		otherAttestation, err := CreateKeyAttestation(providerSigner, KeyAttestation{
+			AttestedKeys: []jwx.PublicKeyJWK{instanceSigner.PrivateKeyJWK.ToPublicKeyJWK()},
+		}, time.Hour)
+		require.NoError(tt, err)
+		credentialRequest.Proof, err = NewKeyAttestedJWTProof(attestedHolderSigner, otherAttestation, testCredentialIssuer, response.CNonce)
+		require.NoError(tt, err)
+		_, err = attestedIssuer.Credential(ctx, token.AccessToken, "", credentialRequest)
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, "proof key is not attested by its key attestation", oidErr.Description)
+
+		// attestation proofs bind credentials to the attested keys, rather than to a DID
+		nonceAttestation, err := CreateKeyAttestation(providerSigner, KeyAttestation{AttestedKeys: []jwx.PublicKeyJWK{holderJWK}, Nonce: oidErr.CNonce}, time.Hour)
+		require.NoError(tt, err)
+		credentialRequest.Proof = NewAttestationProof(nonceAttestation)
+		_, err = attestedIssuer.Credential(ctx, token.AccessToken, "", credentialRequest)
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, "proof key is not of a supported binding method", oidErr.Description)
+
+		configuration := attestedIssuer.metadata.CredentialsSupported["jwt"]
+		configuration.CryptographicBindingMethodsSupported = append(configuration.CryptographicBindingMethodsSupported, issuance.JWKFormat)
+		attestedIssuer.metadata.CredentialsSupported["jwt"] = configuration
+		nonceAttestation, err = CreateKeyAttestation(providerSigner, KeyAttestation{AttestedKeys: []jwx.PublicKeyJWK{holderJWK}, Nonce: oidErr.CNonce}, time.Hour)
+		require.NoError(tt, err)
+		credentialRequest.Proof = NewAttestationProof(nonceAttestation)
+		response, err = attestedIssuer.Credential(ctx, token.AccessToken, "", credentialRequest)
+		require.NoError(tt, err)
+		_, credToken, _, err := integrity.ParseVerifiableCredentialFromJWT(response.Credential.(string))
+		require.NoError(tt, err)
+		confirmationKey, err := integrity.GetConfirmationKey(credToken)
+		require.NoError(tt, err)
+		require.NotNil(tt, confirmationKey)
+		confirmationThumbprint, err := confirmationKey.Thumbprint()
+		require.NoError(tt, err)
+		holderThumbprint, err := holderJWK.Thumbprint()
+		require.NoError(tt, err)
+		assert.Equal(tt, holderThumbprint, confirmationThumbprint)
+	})
+
+	t.Run("offers must be of supported credentials", func(tt *testing.T) {
+		_, _, err := issuer.CreateCredentialOffer(ctx, []string{"unknown"}, OfferOptions{PreAuthorized: true})
+		assert.ErrorContains(tt, err, "credential configuration<unknown> is not supported")
+		_, _, err = issuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{TxCode: &TxCode{}})
+		assert.ErrorContains(tt, err, "transaction codes require a pre-authorized code grant")
+	})
+}
+
+func TestClient(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// the issuer is served at the test server's URL, which it must know to serve its metadata
+	var handler http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { handler.ServeHTTP(w, r) }))
+	defer server.Close()
+	issuer, holderSigner := getTestIssuer(t, resolver, server.URL)
+	mux := NewServeMux(issuer)
+	var referencedOffer *CredentialOffer
+	mux.HandleFunc("GET /offer", func(w http.ResponseWriter, _ *http.Request) { writeJSON(w, http.StatusOK, referencedOffer) })
+	handler = mux
+	client := NewClient()
+
+	t.Run("accept a credential offer", func(tt *testing.T) {
+		offer, txCode, err := issuer.CreateCredentialOffer(ctx, []string{"jwt", "ld", "sdjwt"}, OfferOptions{
+			PreAuthorized: true,
+			TxCode:        &TxCode{},
+			Data:          map[string]any{"name": "Alice"},
+		})
+		require.NoError(tt, err)
+		uri, err := offer.URI()
+		require.NoError(tt, err)
+
+		parsed, err := client.GetCredentialOffer(ctx, uri)
+		require.NoError(tt, err)
+		assert.Equal(tt, offer, parsed)
+
+		_, err = client.AcceptCredentialOffer(ctx, *parsed, holderSigner, "")
+		assert.ErrorContains(tt, err, "credential offer requires a transaction code")
+
+		issued, err := client.AcceptCredentialOffer(ctx, *parsed, holderSigner, txCode)
+		require.NoError(tt, err)
+		require.Len(tt, issued, 3)
+		for _, cred := range issued {
+			assert.Equal(tt, "Alice", cred.Parsed.CredentialSubject["name"])
+			assert.Equal(tt, holderSigner.ID, cred.Parsed.CredentialSubject.GetID())
+		}
+		assert.Equal(tt, issuance.JWTVCJSON, issued[0].Format)
+		verified, err := integrity.VerifyJWTCredential(ctx, issued[0].Credential.(string), resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+		assert.Equal(tt, issuance.LDPVC, issued[1].Format)
+		verified, err = integrity.VerifyDataIntegrityCredential(ctx, *issued[1].Parsed, resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+		assert.Equal(tt, issuance.SDJWTVC, issued[2].Format)
+
+		// the grant was redeemed
+		_, err = client.AcceptCredentialOffer(ctx, *parsed, holderSigner, txCode)
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidGrant, oidErr.Code)
+	})
+
+	t.Run("batch credentials", func(tt *testing.T) {
+		offer, _, err := issuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{
+			PreAuthorized: true,
+			Data:          map[string]any{"name": "Alice"},
+		})
+		require.NoError(tt, err)
+		metadata, err := client.GetIssuerMetadata(ctx, offer.CredentialIssuer)
+		require.NoError(tt, err)
+		require.NotNil(tt, metadata.BatchCredentialEndpoint)
+		token, err := client.Token(ctx, server.URL+TokenPath, TokenRequest{
+			GrantType:         PreAuthorizedCodeGrantType,
+			PreAuthorizedCode: offer.Grants.PreAuthorizedCode.PreAuthorizedCode,
+		})
+		require.NoError(tt, err)
+
+		// a stale nonce is retried with the fresh nonce of the error
+		holderSigners := []jwx.Signer{holderSigner, getTestDIDKeySigner(tt), getTestDIDKeySigner(tt)}
+		issued, err := client.RequestBatchCredentials(ctx, *metadata, token.AccessToken, "stale", "jwt", holderSigners)
+		require.NoError(tt, err)
+		require.Len(tt, issued, 3)
+		for j, cred := range issued {
+			assert.Equal(tt, "jwt", cred.CredentialConfigurationID)
+			assert.Equal(tt, holderSigners[j].ID, cred.Parsed.CredentialSubject.GetID())
+		}
+
+		_, err = client.RequestBatchCredentials(ctx, *metadata, token.AccessToken, "", "ld", holderSigners)
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, UnsupportedCredentialType, oidErr.Code)
+	})
+
+	t.Run("deferred credentials", func(tt *testing.T) {
+		var deferredHandler http.Handler
+		deferredServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { deferredHandler.ServeHTTP(w, r) }))
+		defer deferredServer.Close()
+		deferredIssuer, deferredHolderSigner := getTestIssuer(tt, resolver, deferredServer.URL)
+		pending := new(atomic.Int32)
+		pending.Store(2)
+		deferredIssuer.source = testDeferringSource{CredentialSource: deferredIssuer.source, pending: pending}
+		deferredIssuer.opts.DeferredInterval = time.Second
+		deferredHandler = NewServeMux(deferredIssuer)
+
+		offer, _, err := deferredIssuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{
+			PreAuthorized: true,
+			Data:          map[string]any{"name": "Alice"},
+		})
+		require.NoError(tt, err)
+		metadata, err := client.GetIssuerMetadata(ctx, offer.CredentialIssuer)
+		require.NoError(tt, err)
+		token, err := client.Token(ctx, deferredServer.URL+TokenPath, TokenRequest{
+			GrantType:         PreAuthorizedCodeGrantType,
+			PreAuthorizedCode: offer.Grants.PreAuthorizedCode.PreAuthorizedCode,
+		})
+		require.NoError(tt, err)
+		issued, err := client.RequestCredentials(ctx, *metadata, token.AccessToken, token.CNonce, []string{"jwt"}, deferredHolderSigner)
+		require.NoError(tt, err)
+		require.Len(tt, issued, 1)
+		assert.NotEmpty(tt, issued[0].TransactionID)
+		assert.Nil(tt, issued[0].Parsed)
+
+		// the client polls until the credential is issued
+		awaited, err := client.AwaitDeferredCredential(ctx, *metadata, token.AccessToken, issued[0])
+		require.NoError(tt, err)
+		assert.Equal(tt, "jwt", awaited.CredentialConfigurationID)
+		assert.Equal(tt, deferredHolderSigner.ID, awaited.Parsed.CredentialSubject.GetID())
+		assert.Equal(tt, "Alice", awaited.Parsed.CredentialSubject["name"])
+
+		_, err = client.AwaitDeferredCredential(ctx, *metadata, token.AccessToken, issued[0])
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidTransactionID, oidErr.Code)
+	})
+
+	t.Run("federated issuer metadata", func(tt *testing.T) {
+		anchorMux := http.NewServeMux()
+		anchorServer := httptest.NewServer(anchorMux)
+		defer anchorServer.Close()
+		anchorSigner := getTestDIDKeySigner(tt)
+		anchor, err := federation.NewEntity(anchorSigner, federation.EntityStatement{
+			Subject: anchorServer.URL,
+			Metadata: map[string]any{
+				federation.FederationEntityType: federation.FederationEntityMetadata{FetchEndpoint: anchorServer.URL + "/fetch"},
+			},
+		}, 0)
+		require.NoError(tt, err)
+		anchorHandler, err := federation.NewServeMux(anchor)
+		require.NoError(tt, err)
+		anchorMux.Handle("/", anchorHandler)
+
+		entity, err := issuer.FederationEntity(getTestDIDKeySigner(tt), anchor.ID())
+		require.NoError(tt, err)
+		mux.Handle("GET "+federation.EntityConfigurationPath, entity.ConfigurationHandler())
+
+		federatedClient := NewClient()
+		federatedClient.Federation = federation.NewClient(federation.TrustAnchor{EntityID: anchor.ID(), JWKS: anchor.JWKS()})
+		_, err = federatedClient.GetIssuerMetadata(ctx, server.URL)
+		assert.ErrorContains(tt, err, "authenticating metadata of credential issuer")
+
+		// once the anchor vouches for the issuer's keys, its metadata is authenticated
+		require.NoError(tt, anchor.AddSubordinate(entity.ID(), entity.JWKS()))
+		metadata, err := federatedClient.GetIssuerMetadata(ctx, server.URL)
+		require.NoError(tt, err)
+		assert.Equal(tt, server.URL, metadata.CredentialIssuer.String())
+		assert.Contains(tt, metadata.CredentialsSupported, "jwt")
+	})
+
+	t.Run("pushed authorization requests", func(tt *testing.T) {
+		_, err := client.PushAuthorizationRequest(ctx, issuer.metadata, holderSigner, AuthorizationRequest{})
+		assert.ErrorContains(tt, err, "getting metadata of authorization server")
+
+		parServer, err := par.NewServer(server.URL, resolver, par.ServerOptions{})
+		require.NoError(tt, err)
+		mux.Handle("POST /par", parServer.Handler())
+		mux.HandleFunc("GET "+authorizationServerMetadataPath, func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, http.StatusOK, authorizationServerMetadata{
+				Issuer:                             server.URL,
+				AuthorizationEndpoint:              server.URL + "/authorize",
+				TokenEndpoint:                      server.URL + TokenPath,
+				PushedAuthorizationRequestEndpoint: server.URL + "/par",
+			})
+		})
+
+		authorizationURL, err := client.PushAuthorizationRequest(ctx, issuer.metadata, holderSigner, AuthorizationRequest{
+			RedirectURI:          "https://wallet.example.com/callback",
+			State:                "state",
+			IssuerState:          "issuer-state",
+			AuthorizationDetails: []AuthorizationDetail{{Type: OpenIDCredentialAuthorizationDetailType, CredentialConfigurationID: "jwt"}},
+		})
+		require.NoError(tt, err)
+		parsed, err := url.Parse(authorizationURL)
+		require.NoError(tt, err)
+		assert.Equal(tt, "/authorize", parsed.Path)
+		assert.Equal(tt, holderSigner.ID, parsed.Query().Get("client_id"))
+		assert.Empty(tt, parsed.Query().Get("redirect_uri"))
+
+		// the authorization endpoint redeems the pushed request, once
+		pushed, err := parServer.GetRequest(holderSigner.ID, parsed.Query().Get("request_uri"))
+		require.NoError(tt, err)
+		assert.Equal(tt, "code", pushed.Parameters.Get("response_type"))
+		assert.Equal(tt, "issuer-state", pushed.Parameters.Get("issuer_state"))
+		assert.JSONEq(tt, `[{"type":"openid_credential","credential_configuration_id":"jwt"}]`, pushed.Parameters.Get("authorization_details"))
+		_, err = parServer.GetRequest(holderSigner.ID, parsed.Query().Get("request_uri"))
+		assert.ErrorContains(tt, err, "is not valid")
+
+		_, err = client.PushAuthorizationRequest(ctx, issuer.metadata, holderSigner, AuthorizationRequest{ClientID: "did:example:other"})
+		assert.ErrorContains(tt, err, "is not that of the client signer")
+	})
+
+	t.Run("offer by reference", func(tt *testing.T) {
+		offer, _, err := issuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{PreAuthorized: true})
+		require.NoError(tt, err)
+		referencedOffer = offer
+
+		parsed, referenceURI, err := ParseCredentialOffer(CredentialOfferReferenceURI(server.URL + "/offer"))
+		require.NoError(tt, err)
+		assert.Nil(tt, parsed)
+		assert.Equal(tt, server.URL+"/offer", referenceURI)
+
+		fetched, err := client.GetCredentialOffer(ctx, CredentialOfferReferenceURI(server.URL+"/offer"))
+		require.NoError(tt, err)
+		assert.Equal(tt, offer, fetched)
+
+		_, _, err = ParseCredentialOffer(CredentialOfferScheme + "?credential_offer=%7B%7D")
+		assert.ErrorContains(tt, err, "credential offer has no credential issuer")
+		_, _, err = ParseCredentialOffer(CredentialOfferScheme)
+		assert.ErrorContains(tt, err, "uri has neither a credential_offer nor a credential_offer_uri parameter")
+	})
+
+	t.Run("QR codes", func(tt *testing.T) {
+		offer, _, err := issuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{PreAuthorized: true})
+		require.NoError(tt, err)
+		code, byReference, err := offer.QRCode("", qr.Options{})
+		require.NoError(tt, err)
+		assert.False(tt, byReference)
+		scanned, referenceURI, err := ParseCredentialOfferQRCode(code.Image(2))
+		require.NoError(tt, err)
+		assert.Empty(tt, referenceURI)
+		assert.Equal(tt, offer, scanned)
+
+		// offers which do not fit are passed by reference
+		opts := qr.Options{Level: qr.High, MaxVersion: 10}
+		_, _, err = offer.QRCode("", opts)
+		assert.ErrorContains(tt, err, "credential offer does not fit in a QR code, and has no offer uri")
+		code, byReference, err = offer.QRCode(server.URL+"/offer", opts)
+		require.NoError(tt, err)
+		assert.True(tt, byReference)
+		scanned, referenceURI, err = ParseCredentialOfferQRCode(code.Image(2))
+		require.NoError(tt, err)
+		assert.Nil(tt, scanned)
+		assert.Equal(tt, server.URL+"/offer", referenceURI)
+	})
+
+	t.Run("proofs of keys which are not a DID's", func(tt *testing.T) {
+		_, privateKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		jwkSigner, err := jwx.NewJWXSigner("holder", nil, privateKey)
+		require.NoError(tt, err)
+		proof, err := NewJWTProof(*jwkSigner, server.URL, "nonce")
+		require.NoError(tt, err)
+		headers, err := jwx.GetJWSHeaders([]byte(proof.JWT))
+		require.NoError(tt, err)
+		assert.NotNil(tt, headers.JWK())
+		assert.Empty(tt, headers.KeyID())
+
+		// the issuer only binds credentials to DIDs
+		offer, _, err := issuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{PreAuthorized: true})
+		require.NoError(tt, err)
+		_, err = client.AcceptCredentialOffer(ctx, *offer, *jwkSigner, "")
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidProof, oidErr.Code)
+		assert.Equal(tt, "proof key is not of a supported binding method", oidErr.Description)
+
+		// issuers binding credentials to JWKs bind them to the proven key with a cnf claim
+		var jwkHandler http.Handler
+		jwkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { jwkHandler.ServeHTTP(w, r) }))
+		defer jwkServer.Close()
+		jwkIssuer, _ := getTestIssuer(tt, resolver, jwkServer.URL)
+		for _, id := range []string{"jwt", "sdjwt"} {
+			configuration := jwkIssuer.metadata.CredentialsSupported[id]
+			configuration.CryptographicBindingMethodsSupported = []issuance.CryptographicBindingMethodSupported{issuance.JWKFormat}
+			jwkIssuer.metadata.CredentialsSupported[id] = configuration
+		}
+		jwkHandler = NewServeMux(jwkIssuer)
+
+		offer, _, err = jwkIssuer.CreateCredentialOffer(ctx, []string{"jwt", "sdjwt"}, OfferOptions{PreAuthorized: true, Data: map[string]any{"name": "Alice"}})
+		require.NoError(tt, err)
+		issued, err := client.AcceptCredentialOffer(ctx, *offer, *jwkSigner, "")
+		require.NoError(tt, err)
+		require.Len(tt, issued, 2)
+		holderJWK := jwkSigner.PrivateKeyJWK.ToPublicKeyJWK()
+		holderThumbprint, err := holderJWK.Thumbprint()
+		require.NoError(tt, err)
+		for _, cred := range issued {
+			var confirmationKey *jwx.PublicKeyJWK
+			if cred.Format == issuance.SDJWTVC {
+				confirmationKey, err = integrity.GetSDJWTConfirmationKey(cred.Credential.(string))
+			} else {
+				var credToken jwt.Token
+				_, credToken, _, err = integrity.ParseVerifiableCredentialFromJWT(cred.Credential.(string))
+				require.NoError(tt, err)
+				confirmationKey, err = integrity.GetConfirmationKey(credToken)
+			}
+			require.NoError(tt, err)
+			require.NotNil(tt, confirmationKey, cred.CredentialConfigurationID)
+			confirmationThumbprint, err := confirmationKey.Thumbprint()
+			require.NoError(tt, err)
+			assert.Equal(tt, holderThumbprint, confirmationThumbprint, cred.CredentialConfigurationID)
+		}
+	})
+
+	t.Run("DPoP with nonces", func(tt *testing.T) {
+		var dpopHandler http.Handler
+		dpopServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { dpopHandler.ServeHTTP(w, r) }))
+		defer dpopServer.Close()
+		dpopIssuer, dpopHolderSigner := getTestIssuer(tt, resolver, dpopServer.URL)
+		dpopIssuer.opts.RequireDPoP = true
+		dpopIssuer.opts.DPoPNonces = true
+		dpopHandler = NewServeMux(dpopIssuer)
+
+		offer, _, err := dpopIssuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{PreAuthorized: true, Data: map[string]any{"name": "Alice"}})
+		require.NoError(tt, err)
+		_, err = client.AcceptCredentialOffer(ctx, *offer, dpopHolderSigner, "")
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, InvalidDPoPProof, oidErr.Code)
+		assert.Equal(tt, "DPoP proof is required", oidErr.Description)
+
+		// the first proofs of the client are retried with the issuer's nonce
+		dpopSigner, err := dpop.NewSigner(crypto.Ed25519)
+		require.NoError(tt, err)
+		dpopClient := NewClient()
+		dpopClient.DPoPSigner = dpopSigner
+		offer, _, err = dpopIssuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{PreAuthorized: true, Data: map[string]any{"name": "Alice"}})
+		require.NoError(tt, err)
+		issued, err := dpopClient.AcceptCredentialOffer(ctx, *offer, dpopHolderSigner, "")
+		require.NoError(tt, err)
+		require.Len(tt, issued, 1)
+		assert.Equal(tt, "Alice", issued[0].Parsed.CredentialSubject["name"])
+
+		serverURL, err := url.Parse(dpopServer.URL)
+		require.NoError(tt, err)
+		nonce, err := dpopIssuer.DPoPNonce()
+		require.NoError(tt, err)
+		assert.Equal(tt, nonce, dpopClient.dpopNonce(serverURL))
+	})
+
+	t.Run("attested wallets and keys", func(tt *testing.T) {
+		var attestedHandler http.Handler
+		attestedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { attestedHandler.ServeHTTP(w, r) }))
+		defer attestedServer.Close()
+		attestedIssuer, attestedHolderSigner := getTestIssuer(tt, resolver, attestedServer.URL)
+		providerSigner := getTestDIDKeySigner(tt)
+		attestedIssuer.opts.AttestationValidator = testAttestationValidator{provider: providerSigner.ID}
+		attestedIssuer.opts.RequireWalletAttestation = true
+		attestedIssuer.opts.RequireKeyAttestation = true
+		attestedHandler = NewServeMux(attestedIssuer)
+
+		_, instanceKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		instanceSigner, err := jwx.NewJWXSigner("wallet-instance", nil, instanceKey)
+		require.NoError(tt, err)
+		walletAttestation, err := CreateWalletAttestation(providerSigner, WalletAttestation{
+			Subject:      instanceSigner.ID,
+			Confirmation: Confirmation{JWK: instanceSigner.PrivateKeyJWK.ToPublicKeyJWK()},
+		}, time.Hour)
+		require.NoError(tt, err)
+		keyAttestation, err := CreateKeyAttestation(providerSigner, KeyAttestation{
+			AttestedKeys: []jwx.PublicKeyJWK{attestedHolderSigner.PrivateKeyJWK.ToPublicKeyJWK()},
+		}, time.Hour)
+		require.NoError(tt, err)
+
+		attestedClient := NewClient()
+		attestedClient.WalletAttestation = walletAttestation
+		offer, _, err := attestedIssuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{PreAuthorized: true, Data: map[string]any{"name": "Alice"}})
+		require.NoError(tt, err)
+		_, err = attestedClient.AcceptCredentialOffer(ctx, *offer, attestedHolderSigner, "")
+		assert.ErrorContains(tt, err, "wallet attestation requires a wallet instance signer")
+
+		attestedClient.WalletInstanceSigner = instanceSigner
+		_, err = attestedClient.AcceptCredentialOffer(ctx, *offer, attestedHolderSigner, "")
+		var oidErr *Error
+		require.ErrorAs(tt, err, &oidErr)
+		assert.Equal(tt, "key attestation is required", oidErr.Description)
+
+		attestedClient.KeyAttestation = keyAttestation
+		offer, _, err = attestedIssuer.CreateCredentialOffer(ctx, []string{"jwt"}, OfferOptions{PreAuthorized: true, Data: map[string]any{"name": "Alice"}})
+		require.NoError(tt, err)
+		issued, err := attestedClient.AcceptCredentialOffer(ctx, *offer, attestedHolderSigner, "")
+		require.NoError(tt, err)
+		require.Len(tt, issued, 1)
+		assert.Equal(tt, "Alice", issued[0].Parsed.CredentialSubject["name"])
+	})
+}
+
+// testAttestationValidator trusts the attestations of a wallet provider
+type testAttestationValidator struct {
+	provider string
+}
+
+func (v testAttestationValidator) ValidateWalletAttestation(_ context.Context, attestation WalletAttestation) error {
+	if attestation.Issuer != v.provider {
+		return fmt.Errorf("wallet provider<%s> is not trusted", attestation.Issuer)
+	}
+	return nil
+}
+
+func (v testAttestationValidator) ValidateKeyAttestation(_ context.Context, attestation KeyAttestation) error {
+	if attestation.Issuer != v.provider {
+		return fmt.Errorf("wallet provider<%s> is not trusted", attestation.Issuer)
+	}
+	return nil
+}
+
+// testCredentialSource issues credentials of the name in the session's data to their holder
+type testCredentialSource struct {
+	issuer string
+}
+
+func (s testCredentialSource) GetCredential(_ context.Context, session Session, configurationID, holder string, _ *jwx.PublicKeyJWK) (*credential.VerifiableCredential, error) {
+	cred := credential.VerifiableCredential{
+		Context:           []any{credential.VerifiableCredentialsLinkedDataContext, "https://w3id.org/security/suites/jws-2020/v1"},
+		ID:                "https://issuer.example.com/credentials/" + configurationID,
+		Type:              []string{credential.VerifiableCredentialType, "UniversityDegreeCredential"},
+		Issuer:            s.issuer,
+		IssuanceDate:      util.GetRFC3339Timestamp(),
+		CredentialSubject: credential.CredentialSubject{"id": holder, "name": session.Data["name"]},
+	}
+	if configurationID == "sdjwt" {
+		cred.Context = []any{credential.VerifiableCredentialsV2LinkedDataContext}
+		cred.IssuanceDate, cred.ValidFrom = "", cred.IssuanceDate
+	}
+	return &cred, nil
+}
+
+// testDeferringSource defers the issuance of the credentials of its source for its first pending requests
+type testDeferringSource struct {
+	CredentialSource
+	pending *atomic.Int32
+}
+
+func (s testDeferringSource) GetCredential(ctx context.Context, session Session, configurationID, holder string, holderKey *jwx.PublicKeyJWK) (*credential.VerifiableCredential, error) {
+	if s.pending.Add(-1) >= 0 {
+		return nil, ErrIssuancePending
+	}
+	return s.CredentialSource.GetCredential(ctx, session, configurationID, holder, holderKey)
+}
+
+type testAuthorizationCodeHandler map[string]string
+
+func (h testAuthorizationCodeHandler) RedeemAuthorizationCode(_ context.Context, request TokenRequest) (string, error) {
+	return h[request.Code], nil
+}
+
+// getTestIssuer returns a credential issuer of credentials of the jwt_vc_json, ldp_vc, and vc+sd-jwt formats, and the signer
+// of a holder's did:key
+func getTestIssuer(t *testing.T, resolver resolution.Resolver, credentialIssuer string) (*Issuer, jwx.Signer) {
+	issuerKey, issuerDID, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := issuerDID.Expand()
+	require.NoError(t, err)
+	issuerKID := expanded.VerificationMethod[0].ID
+	jwtSigner, err := jwx.NewJWXSigner(issuerDID.String(), &issuerKID, issuerKey)
+	require.NoError(t, err)
+	ldSigner, err := jws2020.NewJSONWebKeySigner(issuerKID, jwtSigner.PrivateKeyJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+
+	issuerURL, err := url.Parse(credentialIssuer)
+	require.NoError(t, err)
+	credentialEndpoint, err := url.Parse(credentialIssuer + "/credential")
+	require.NoError(t, err)
+	batchCredentialEndpoint, err := url.Parse(credentialIssuer + "/batch_credential")
+	require.NoError(t, err)
+	deferredCredentialEndpoint, err := url.Parse(credentialIssuer + "/deferred_credential")
+	require.NoError(t, err)
+	supported := func(id string, format issuance.Format) issuance.CredentialSupported {
+		supported := issuance.CredentialSupported{
+			Format:                               format,
+			ID:                                   &id,
+			CryptographicBindingMethodsSupported: []issuance.CryptographicBindingMethodSupported{"did:key"},
+		}
+		if format == issuance.JWTVCJSON {
+			supported.JWTVCJSONCredentialMetadata = &issuance.JWTVCJSONCredentialMetadata{
+				Types: []string{credential.VerifiableCredentialType, "UniversityDegreeCredential"},
+			}
+		}
+		return supported
+	}
+	metadata := issuance.IssuerMetadata{
+		CredentialIssuer:           util.URL{URL: *issuerURL},
+		CredentialEndpoint:         util.URL{URL: *credentialEndpoint},
+		BatchCredentialEndpoint:    &util.URL{URL: *batchCredentialEndpoint},
+		DeferredCredentialEndpoint: &util.URL{URL: *deferredCredentialEndpoint},
+		CredentialsSupported: map[string]issuance.CredentialSupported{
+			"jwt":   supported("jwt", issuance.JWTVCJSON),
+			"ld":    supported("ld", issuance.LDPVC),
+			"sdjwt": supported("sdjwt", issuance.SDJWTVC),
+		},
+	}
+	issuer, err := NewIssuer(metadata, testCredentialSource{issuer: issuerDID.String()}, IssuerOptions{
+		JWTSigner: jwtSigner,
+		LDSigner:  ldSigner,
+		Resolver:  resolver,
+	})
+	require.NoError(t, err)
+
+	return issuer, getTestDIDKeySigner(t)
+}
+
+// getTestDIDKeySigner returns the signer of a new did:key, identified by its verification method
+func getTestDIDKeySigner(t *testing.T) jwx.Signer {
+	privateKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privateKey)
+	require.NoError(t, err)
+	return *signer
+}
+
+func getTestProof(t *testing.T, signer jwx.Signer, credentialIssuer, nonce string) *Proof {
+	proof, err := NewJWTProof(signer, credentialIssuer, nonce)
+	require.NoError(t, err)
+	return proof
+}
+
+func postForm(t *testing.T, endpoint string, form url.Values) (int, string) {
+	resp, err := http.PostForm(endpoint, form)
+	require.NoError(t, err)
+	return readResponse(t, resp)
+}
+
+func postCredentialRequest(t *testing.T, endpoint, accessToken string, request CredentialRequest) (int, string) {
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(string(body)))
+	require.NoError(t, err)
+	if accessToken != "" {
+		req.Header.Set("Authorization", BearerTokenType+" "+accessToken)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return readResponse(t, resp)
+}
+
+func readResponse(t *testing.T, resp *http.Response) (int, string) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
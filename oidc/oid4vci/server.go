@@ -0,0 +1,208 @@
+package oid4vci
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/oidc/dpop"
+)
+
+// maxRequestSize bounds the size of request bodies the handlers read
+const maxRequestSize = 1 << 20
+
+// NewServeMux returns a mux serving the issuer's metadata at its well-known path, its token endpoint at TokenPath,
+// and its credential, batch credential, and deferred credential endpoints at the paths of those of its metadata
+func NewServeMux(issuer *Issuer) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET "+CredentialIssuerMetadataPath+strings.TrimSuffix(issuer.metadata.CredentialIssuer.Path, "/"), issuer.MetadataHandler())
+	mux.Handle("POST "+TokenPath, issuer.TokenHandler())
+	mux.Handle("POST "+issuer.metadata.CredentialEndpoint.Path, issuer.CredentialHandler())
+	if issuer.metadata.BatchCredentialEndpoint != nil {
+		mux.Handle("POST "+issuer.metadata.BatchCredentialEndpoint.Path, issuer.BatchCredentialHandler())
+	}
+	if issuer.metadata.DeferredCredentialEndpoint != nil {
+		mux.Handle("POST "+issuer.metadata.DeferredCredentialEndpoint.Path, issuer.DeferredCredentialHandler())
+	}
+	return mux
+}
+
+// MetadataHandler returns a handler serving the issuer's metadata
+func (i *Issuer) MetadataHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, i.metadata)
+	})
+}
+
+// TokenHandler returns a handler redeeming the grant of the form encoded TokenRequest of a request, binding the
+// access token to the key of the request's DPoP proof, if it has one, and attesting the wallet by the wallet
+// attestation of the request's OAuth-Client-Attestation headers, if it has one
+func (i *Issuer) TokenHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i.setDPoPNonce(w)
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+		if err := r.ParseForm(); err != nil {
+			writeError(w, newError(InvalidRequest, "parsing form: %s", err.Error()), false)
+			return
+		}
+		request := TokenRequestFromForm(r.PostForm)
+		request.ClientAttestation = r.Header.Get(ClientAttestationHeader)
+		request.ClientAttestationPoP = r.Header.Get(ClientAttestationPoPHeader)
+		response, err := i.Token(r.Context(), request, r.Header.Get(dpop.HeaderName))
+		if err != nil {
+			writeError(w, err, false)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, response)
+	})
+}
+
+// CredentialHandler returns a handler issuing the credential of the CredentialRequest of a request, authorized by the
+// access token of its Authorization header: a bearer token, or a token bound to the key of the request's DPoP proof
+// sent with the DPoP scheme
+func (i *Issuer) CredentialHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i.setDPoPNonce(w)
+		accessToken, dpopProof, oidErr := accessTokenOf(r)
+		if oidErr != nil {
+			writeError(w, oidErr, true)
+			return
+		}
+		var request CredentialRequest
+		if err := readRequest(r, &request); err != nil {
+			writeError(w, newError(InvalidRequest, "%s", err.Error()), true)
+			return
+		}
+		response, err := i.Credential(r.Context(), accessToken, dpopProof, request)
+		if err != nil {
+			writeError(w, err, true)
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+}
+
+// BatchCredentialHandler returns a handler issuing the credentials of the BatchCredentialRequest of a request,
+// authorized by the access token of its Authorization header as for the credential endpoint
+func (i *Issuer) BatchCredentialHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i.setDPoPNonce(w)
+		accessToken, dpopProof, oidErr := accessTokenOf(r)
+		if oidErr != nil {
+			writeError(w, oidErr, true)
+			return
+		}
+		var request BatchCredentialRequest
+		if err := readRequest(r, &request); err != nil {
+			writeError(w, newError(InvalidRequest, "%s", err.Error()), true)
+			return
+		}
+		response, err := i.BatchCredential(r.Context(), accessToken, dpopProof, request)
+		if err != nil {
+			writeError(w, err, true)
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+}
+
+// DeferredCredentialHandler returns a handler issuing the credential of the deferred issuance of the
+// DeferredCredentialRequest of a request, authorized by the access token of its Authorization header as for the
+// credential endpoint
+func (i *Issuer) DeferredCredentialHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i.setDPoPNonce(w)
+		accessToken, dpopProof, oidErr := accessTokenOf(r)
+		if oidErr != nil {
+			writeError(w, oidErr, true)
+			return
+		}
+		var request DeferredCredentialRequest
+		if err := readRequest(r, &request); err != nil {
+			writeError(w, newError(InvalidRequest, "%s", err.Error()), true)
+			return
+		}
+		response, err := i.DeferredCredential(r.Context(), accessToken, dpopProof, request)
+		if err != nil {
+			writeError(w, err, true)
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+}
+
+// accessTokenOf returns the access token of a request's Authorization header, and its DPoP proof, which is required
+// for access tokens sent with the DPoP scheme
+func accessTokenOf(r *http.Request) (accessToken, dpopProof string, oidErr *Error) {
+	authorization := r.Header.Get("Authorization")
+	accessToken, ok := strings.CutPrefix(authorization, BearerTokenType+" ")
+	if ok {
+		return accessToken, "", nil
+	}
+	if accessToken, ok = strings.CutPrefix(authorization, dpop.TokenType+" "); !ok {
+		return "", "", newError(InvalidToken, "access token is required")
+	}
+	if dpopProof = r.Header.Get(dpop.HeaderName); dpopProof == "" {
+		return "", "", newError(InvalidDPoPProof, "DPoP proof is required")
+	}
+	return accessToken, dpopProof, nil
+}
+
+// readRequest decodes the JSON body of a request
+func readRequest(r *http.Request, v any) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		return errors.Wrap(err, "reading request")
+	}
+	if err = json.Unmarshal(body, v); err != nil {
+		return errors.Wrap(err, "unmarshalling request")
+	}
+	return nil
+}
+
+// setDPoPNonce sets the DPoP-Nonce header of a response to the issuer's nonce, if it requires nonces
+func (i *Issuer) setDPoPNonce(w http.ResponseWriter) {
+	nonce, err := i.DPoPNonce()
+	if err == nil && nonce != "" {
+		w.Header().Set(dpop.NonceHeaderName, nonce)
+	}
+}
+
+// writeError writes an error response: 401 for invalid access tokens, and for DPoP errors of requests to protected
+// resources, such as the credential endpoint, 500 for server errors, and 400 otherwise
+func writeError(w http.ResponseWriter, err error, protectedResource bool) {
+	var oidErr *Error
+	if !errors.As(err, &oidErr) {
+		oidErr = newError(ServerError, "%s", err.Error())
+	}
+	status := http.StatusBadRequest
+	switch oidErr.Code {
+	case InvalidToken:
+		status = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", BearerTokenType+` error="`+string(InvalidToken)+`"`)
+	case InvalidDPoPProof, UseDPoPNonce:
+		if protectedResource {
+			status = http.StatusUnauthorized
+			w.Header().Set("WWW-Authenticate", dpop.TokenType+` error="`+string(oidErr.Code)+`"`)
+		}
+	case ServerError:
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, oidErr)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, "marshalling response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
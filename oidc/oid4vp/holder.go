@@ -0,0 +1,443 @@
+package oid4vp
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/oidc/par"
+	"github.com/TBD54566975/ssi-sdk/oidc/qr"
+	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/TBD54566975/ssi-sdk/wallet"
+)
+
+// maxResponseSize bounds the size of response bodies the holder reads
+const maxResponseSize = 1 << 20
+
+// credentialSubjectPathPrefix prefixes the paths of input descriptor fields selecting claims of a credential subject
+const credentialSubjectPathPrefix = "$.credentialSubject."
+
+// Holder responds to authorization requests with presentations of the credentials of a wallet, as the holder
+// identified by its signer's DID, to which the credentials are expected to be issued
+type Holder struct {
+	*http.Client
+	signer   jwx.Signer
+	store    wallet.CredentialStore
+	resolver resolution.Resolver
+
+	ldSuite  cryptosuite.CryptoSuite
+	ldSigner cryptosuite.Signer
+
+	requestObjects RequestObjectCache
+	pushedRequests *par.Server
+}
+
+// NewHolder returns a holder identified by the DID of its signer, whose KID is the verification method presentations
+// are signed with. The resolver resolves the DIDs of verifiers signing request objects.
+func NewHolder(signer jwx.Signer, store wallet.CredentialStore, resolver resolution.Resolver) (*Holder, error) {
+	if !strings.HasPrefix(signer.ID, "did:") {
+		return nil, fmt.Errorf("signer id<%s> must be a DID", signer.ID)
+	}
+	if signer.KID == "" {
+		return nil, errors.New("signer kid cannot be empty")
+	}
+	if store == nil {
+		return nil, errors.New("credential store cannot be empty")
+	}
+	if resolver == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	return &Holder{
+		Client:         http.DefaultClient,
+		signer:         signer,
+		store:          store,
+		resolver:       resolver,
+		requestObjects: NewMemoryRequestObjectCache(),
+	}, nil
+}
+
+// SetLDSigner lets the holder sign Data Integrity presentations, in the ldp_vp format, for verifiers which support
+// them and not jwt_vp. The signer's key should be a verification method of the holder's DID.
+func (h *Holder) SetLDSigner(suite cryptosuite.CryptoSuite, signer cryptosuite.Signer) {
+	h.ldSuite = suite
+	h.ldSigner = signer
+}
+
+// GetAuthorizationRequest returns the authorization request passed to the wallet by a URI, of OpenID for Verifiable
+// Presentations or of a Self-Issued OpenID Provider, verifying it if it is a signed request object, passed by value
+// or fetched from its request URI as GetRequestObject does, and fetching its presentation definition if it is passed
+// by reference. Requests of verifiers identified by a DID must be signed.
+func (h *Holder) GetAuthorizationRequest(ctx context.Context, uri string) (*AuthorizationRequest, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing authorization request uri")
+	}
+	values := parsed.Query()
+	clientID := values.Get(clientIDParameter)
+	var request *AuthorizationRequest
+	switch {
+	case values.Has(requestURIParameter):
+		if values.Has(requestParameter) {
+			return nil, errors.New("request cannot be passed both by value and by reference")
+		}
+		request, err = h.GetRequestObject(ctx, clientID, values.Get(requestURIParameter), values.Get(requestURIMethodParameter))
+		if err != nil {
+			return nil, err
+		}
+	case values.Has(requestParameter):
+		if request, err = h.VerifyRequestObject(ctx, clientID, values.Get(requestParameter)); err != nil {
+			return nil, err
+		}
+	default:
+		if request, err = AuthorizationRequestFromValues(values); err != nil {
+			return nil, err
+		}
+		if request.ClientIDScheme == DIDClientIDScheme || strings.HasPrefix(request.ClientID, "did:") {
+			return nil, fmt.Errorf("request of client<%s> identified by a DID must be signed", request.ClientID)
+		}
+		if request.ClientID != request.ResponseURI && request.ClientID != request.RedirectURI {
+			return nil, fmt.Errorf("client id<%s> of an unsigned request must be its response or redirect uri", request.ClientID)
+		}
+	}
+	if err = request.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid authorization request")
+	}
+
+	if !request.RequestsVPToken() {
+		return request, nil
+	}
+	if request.PresentationDefinitionURI != "" {
+		var def exchange.PresentationDefinition
+		if err = h.get(ctx, request.PresentationDefinitionURI, &def); err != nil {
+			return nil, errors.Wrapf(err, "getting presentation definition<%s>", request.PresentationDefinitionURI)
+		}
+		request.PresentationDefinition = &def
+	}
+	if err = request.PresentationDefinition.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid presentation definition")
+	}
+	return request, nil
+}
+
+// GetAuthorizationRequestFromQRCode returns the authorization request passed to the wallet by the URI of the QR code
+// of an image, as GetAuthorizationRequest does
+func (h *Holder) GetAuthorizationRequestFromQRCode(ctx context.Context, img image.Image) (*AuthorizationRequest, error) {
+	uri, err := qr.Decode(img)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding QR code")
+	}
+	return h.GetAuthorizationRequest(ctx, uri)
+}
+
+// VerifyRequestObject verifies a signed request object of the given client, and returns its request. The request
+// object must be signed by a key of the client's DID, identified by its kid.
+func (h *Holder) VerifyRequestObject(ctx context.Context, clientID, requestObject string) (*AuthorizationRequest, error) {
+	request, _, err := h.verifyRequestObject(ctx, clientID, requestObject)
+	return request, err
+}
+
+// verifyRequestObject verifies a signed request object as VerifyRequestObject does, and returns its request and
+// its token
+func (h *Holder) verifyRequestObject(ctx context.Context, clientID, requestObject string) (*AuthorizationRequest, jwt.Token, error) {
+	if !strings.HasPrefix(clientID, "did:") {
+		return nil, nil, fmt.Errorf("client id<%s> of a signed request must be a DID", clientID)
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(requestObject))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "getting request object headers")
+	}
+	if headers.Type() != RequestObjectJWTType {
+		return nil, nil, fmt.Errorf("request object typ<%s> must be %s", headers.Type(), RequestObjectJWTType)
+	}
+	kid := headers.KeyID()
+	if !strings.HasPrefix(kid, clientID+"#") {
+		return nil, nil, fmt.Errorf("request object kid<%s> is not a key of client<%s>", kid, clientID)
+	}
+	key, err := resolution.ResolveKeyForDID(ctx, h.resolver, clientID, kid)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "resolving request object key<%s>", kid)
+	}
+	verifier, err := jwx.NewJWXVerifier(clientID, &kid, key)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "constructing verifier for request object key<%s>", kid)
+	}
+	_, token, err := verifier.VerifyAndParse(requestObject)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "verifying request object")
+	}
+	if token.Issuer() != "" && token.Issuer() != clientID {
+		return nil, nil, fmt.Errorf("request object issuer<%s> is not client<%s>", token.Issuer(), clientID)
+	}
+
+	claims, err := token.AsMap(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "getting request object claims")
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "marshalling request object claims")
+	}
+	var request AuthorizationRequest
+	if err = json.Unmarshal(claimsJSON, &request); err != nil {
+		return nil, nil, errors.Wrap(err, "unmarshalling request object claims")
+	}
+	if request.ClientID != clientID {
+		return nil, nil, fmt.Errorf("request object client id<%s> is not client<%s>", request.ClientID, clientID)
+	}
+	return &request, token, nil
+}
+
+// CreateAuthorizationResponse responds to an authorization request with presentations of the stored credentials
+// which best fulfill each input descriptor of its presentation definition. JWT and Data Integrity credentials are
+// presented together, in a jwt_vp presentation, or an ldp_vp presentation if the verifier does not support jwt_vp
+// and the holder has an LD signer. SD-JWT credentials are each submitted on their own, disclosing the claims the
+// input descriptor's fields select, with a key binding JWT. Every presentation is bound to the request's nonce and
+// client ID, as is the self-issued ID token of requests for one.
+func (h *Holder) CreateAuthorizationResponse(ctx context.Context, request AuthorizationRequest) (*AuthorizationResponse, error) {
+	var idToken string
+	if request.RequestsIDToken() {
+		var err error
+		if idToken, err = h.CreateIDToken(request); err != nil {
+			return nil, err
+		}
+		if !request.RequestsVPToken() {
+			return &AuthorizationResponse{IDToken: idToken, State: request.State}, nil
+		}
+	}
+
+	def := request.PresentationDefinition
+	if def == nil {
+		return nil, errors.New("presentation definition of the request cannot be empty")
+	}
+	matches, err := wallet.FindForPresentationDefinition(ctx, h.store, *def)
+	if err != nil {
+		return nil, errors.Wrap(err, "finding credentials for presentation definition")
+	}
+
+	var presentations []any
+	var submitted []exchange.SubmittedPresentation
+	var vpDescriptors []exchange.InputDescriptor
+	var vpCreds []wallet.StoredCredential
+	seen := make(map[string]bool)
+	for _, id := range def.InputDescriptors {
+		creds := matches[id.ID]
+		if len(creds) == 0 {
+			return nil, fmt.Errorf("no credential fulfills input descriptor<%s>", id.ID)
+		}
+		cred := creds[0]
+		if cred.Format() != exchange.SDJWTVC.CredentialFormat() {
+			vpDescriptors = append(vpDescriptors, id)
+			if !seen[cred.ID] {
+				seen[cred.ID] = true
+				vpCreds = append(vpCreds, cred)
+			}
+			continue
+		}
+
+		selected, err := integrity.SelectSDJWTDisclosures(cred.Token, disclosedClaims(id)...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "selecting disclosures of credential<%s>", cred.ID)
+		}
+		presented, err := integrity.AddSDJWTKeyBinding(h.signer, selected, request.ClientID, request.Nonce)
+		if err != nil {
+			return nil, errors.Wrapf(err, "binding credential<%s>", cred.ID)
+		}
+		presentations = append(presentations, presented)
+		submitted = append(submitted, exchange.SubmittedPresentation{
+			Format:        exchange.SDJWTVC.String(),
+			DescriptorMap: []exchange.SubmissionDescriptor{{ID: id.ID, Format: exchange.SDJWTVC.String(), Path: "$"}},
+		})
+	}
+	if len(vpCreds) > 0 {
+		vpDef := exchange.PresentationDefinition{ID: def.ID, InputDescriptors: vpDescriptors}
+		presentation, vpSubmitted, err := h.createPresentation(request, vpDef, vpCreds)
+		if err != nil {
+			return nil, err
+		}
+		presentations = append([]any{presentation}, presentations...)
+		submitted = append([]exchange.SubmittedPresentation{*vpSubmitted}, submitted...)
+	}
+
+	submission, err := exchange.CombinePresentationSubmissions(*def, submitted)
+	if err != nil {
+		return nil, errors.Wrap(err, "building presentation submission")
+	}
+	response := AuthorizationResponse{VPToken: presentations, PresentationSubmission: submission, IDToken: idToken, State: request.State}
+	if len(presentations) == 1 {
+		// a single presentation is the vp_token itself, selected by the path $
+		response.VPToken = presentations[0]
+		for i, d := range submission.DescriptorMap {
+			submission.DescriptorMap[i].Path = "$" + strings.TrimPrefix(d.Path, "$[0]")
+		}
+	}
+	return &response, nil
+}
+
+// createPresentation presents JWT and Data Integrity credentials fulfilling the input descriptors of a presentation
+// definition in a presentation bound to a request, and returns the presentation with its descriptor map
+func (h *Holder) createPresentation(request AuthorizationRequest, def exchange.PresentationDefinition, creds []wallet.StoredCredential) (any, *exchange.SubmittedPresentation, error) {
+	claims := make([]exchange.PresentationClaim, 0, len(creds))
+	for _, cred := range creds {
+		claim := exchange.PresentationClaim{}
+		if cred.Token != "" {
+			headers, err := jwx.GetJWSHeaders([]byte(cred.Token))
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "getting headers of credential<%s>", cred.ID)
+			}
+			claim.Token = util.StringPtr(cred.Token)
+			claim.JWTFormat = exchange.JWTVC.Ptr()
+			claim.SignatureAlgorithmOrProofType = headers.Algorithm().String()
+		} else {
+			ldCred := cred.Credential
+			claim.Credential = &ldCred
+			claim.LDPFormat = exchange.LDPVC.Ptr()
+			if proof := ldCred.GetProof(); proof != nil {
+				claim.SignatureAlgorithmOrProofType = proof.Type
+			}
+		}
+		claims = append(claims, claim)
+	}
+	normalized, err := exchange.NormalizePresentationClaims(claims)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "normalizing credentials")
+	}
+	vp, err := exchange.BuildPresentationSubmissionVP(h.signer.ID, def, normalized)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "building presentation")
+	}
+	embedded, ok := vp.PresentationSubmission.(exchange.PresentationSubmission)
+	if !ok {
+		return nil, nil, errors.New("presentation has no presentation submission")
+	}
+
+	if h.prefersLDP(request.ClientMetadata) {
+		if err = h.ldSuite.Sign(h.ldSigner, vp, cryptosuite.WithChallenge(request.Nonce), cryptosuite.WithDomain(request.ClientID)); err != nil {
+			return nil, nil, errors.Wrap(err, "signing presentation")
+		}
+		return *vp, &exchange.SubmittedPresentation{Format: exchange.LDPVP.String(), DescriptorMap: embedded.DescriptorMap}, nil
+	}
+	vpJWT, err := integrity.SignVerifiablePresentationJWT(h.signer, &integrity.JWTVVPParameters{Challenge: request.Nonce, Domain: request.ClientID}, *vp)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "signing presentation")
+	}
+	return string(vpJWT), &exchange.SubmittedPresentation{Format: exchange.JWTVP.String(), DescriptorMap: embedded.DescriptorMap}, nil
+}
+
+// prefersLDP returns whether presentations for a verifier are signed as ldp_vp: the holder must have an LD signer,
+// and the verifier must support ldp_vp and not jwt_vp
+func (h *Holder) prefersLDP(metadata *ClientMetadata) bool {
+	if h.ldSigner == nil || h.ldSuite == nil || metadata == nil || metadata.VPFormats == nil {
+		return false
+	}
+	return metadata.VPFormats.LDPVP != nil && metadata.VPFormats.JWTVP == nil
+}
+
+// disclosedClaims returns the names of the claims of a credential subject which the fields of an input descriptor
+// select, and which an SD-JWT credential fulfilling it discloses
+func disclosedClaims(id exchange.InputDescriptor) []string {
+	if id.Constraints == nil {
+		return nil
+	}
+	var claims []string
+	for _, field := range id.Constraints.Fields {
+		for _, path := range field.Path {
+			if !strings.HasPrefix(path, credentialSubjectPathPrefix) {
+				continue
+			}
+			claim := strings.TrimPrefix(path, credentialSubjectPathPrefix)
+			if end := strings.IndexAny(claim, ".["); end >= 0 {
+				claim = claim[:end]
+			}
+			claims = append(claims, claim)
+		}
+	}
+	return claims
+}
+
+// SubmitAuthorizationResponse returns an authorization response to the verifier. A response of the direct_post
+// response mode is posted to the request's response URI, which may return a URI to redirect the user agent to. For
+// the fragment response mode, the returned URI is the request's redirect URI with the response in its fragment.
+// Responses of JWT response modes are secured as by SecureAuthorizationResponse.
+func (h *Holder) SubmitAuthorizationResponse(ctx context.Context, request AuthorizationRequest, response AuthorizationResponse) (string, error) {
+	form, err := h.responseForm(request, response)
+	if err != nil {
+		return "", err
+	}
+	switch request.ResponseMode {
+	case DirectPostResponseMode, DirectPostJWTResponseMode:
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.ResponseURI, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", errors.Wrap(err, "creating request")
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		var posted DirectPostResponse
+		if err = h.do(req, &posted); err != nil {
+			return "", errors.Wrapf(err, "posting response to<%s>", request.ResponseURI)
+		}
+		return posted.RedirectURI, nil
+	case "", FragmentResponseMode, FragmentJWTResponseMode:
+		if request.RedirectURI == "" {
+			return "", fmt.Errorf("response mode<%s> requires a redirect uri", request.ResponseMode)
+		}
+		return request.RedirectURI + "#" + form.Encode(), nil
+	default:
+		return "", fmt.Errorf("response mode<%s> is not supported", request.ResponseMode)
+	}
+}
+
+// responseForm returns the form encoding of a response, which is a JWT secured response for JWT response modes
+func (h *Holder) responseForm(request AuthorizationRequest, response AuthorizationResponse) (url.Values, error) {
+	if !request.ResponseMode.IsJWT() {
+		return response.Form()
+	}
+	secured, err := h.SecureAuthorizationResponse(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return url.Values{responseParameter: {secured}}, nil
+}
+
+func (h *Holder) get(ctx context.Context, url string, response any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	return h.do(req, response)
+}
+
+// do sends a request and unmarshalls its JSON response, which may be empty
+func (h *Holder) do(req *http.Request, response any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := h.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return errors.Wrap(err, "reading response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("status code: %d", resp.StatusCode)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if err = json.Unmarshal(body, response); err != nil {
+		return errors.Wrap(err, "unmarshalling response")
+	}
+	return nil
+}
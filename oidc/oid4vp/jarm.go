@@ -0,0 +1,298 @@
+package oid4vp
+
+import (
+	"context"
+	gocrypto "crypto"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// JWT Secured Authorization Response Mode (JARM) secures responses as JWTs, signed by the wallet, encrypted to the
+// verifier, or both
+// https://openid.net/specs/oauth-v2-jarm.html
+// https://openid.net/specs/openid-4-verifiable-presentations-1_0.html#name-signed-and-encrypted-respon
+
+const (
+	// DefaultResponseEncryptionAlg is the key management algorithm responses are encrypted with, when the verifier
+	// asks for encrypted responses without naming one
+	DefaultResponseEncryptionAlg = jwa.ECDH_ES
+	// DefaultResponseEncryptionEnc is the content encryption algorithm responses are encrypted with, when the verifier
+	// names none
+	DefaultResponseEncryptionEnc = jwa.A256GCM
+
+	// responseLifetime is how long a signed response is valid for
+	responseLifetime = 10 * time.Minute
+)
+
+// SetResponseEncryptionKey sets the key agreement key wallets encrypt JWT secured responses to. Requests of JWT
+// response modes then carry the key's public JWK, identified by the given kid, in their client metadata.
+func (v *Verifier) SetResponseEncryptionKey(kid string, key gocrypto.PrivateKey) error {
+	publicKeyJWK, _, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, key)
+	if err != nil {
+		return errors.Wrap(err, "converting response encryption key to JWK")
+	}
+	publicKeyJWK.Use = "enc"
+	publicKeyJWK.ALG = DefaultResponseEncryptionAlg.String()
+	v.encryptionKey = key
+	v.encryptionKeyJWK = publicKeyJWK
+	return nil
+}
+
+// responseEncryptionMetadata returns the client metadata of a request, with the verifier's response encryption key
+// and algorithms if the request's response mode is a JWT response mode and the verifier has one
+func (v *Verifier) responseEncryptionMetadata(request AuthorizationRequest) *ClientMetadata {
+	if !request.ResponseMode.IsJWT() || v.encryptionKeyJWK == nil {
+		return request.ClientMetadata
+	}
+	var metadata ClientMetadata
+	if request.ClientMetadata != nil {
+		metadata = *request.ClientMetadata
+	}
+	metadata.AuthorizationEncryptedResponseAlg = DefaultResponseEncryptionAlg.String()
+	metadata.AuthorizationEncryptedResponseEnc = DefaultResponseEncryptionEnc.String()
+	metadata.JWKS = &JWKS{Keys: []jwx.PublicKeyJWK{*v.encryptionKeyJWK}}
+	return &metadata
+}
+
+// ParseAuthorizationResponse returns the response posted to the verifier's response URI as a form, or passed in the
+// fragment of its redirect URI. The JWT secured responses of JWT response modes are decrypted and verified, as by
+// VerifyAuthorizationResponseJWT.
+func (v *Verifier) ParseAuthorizationResponse(ctx context.Context, request AuthorizationRequest, form url.Values) (*AuthorizationResponse, error) {
+	if !request.ResponseMode.IsJWT() {
+		return AuthorizationResponseFromForm(form)
+	}
+	responseJWT := form.Get(responseParameter)
+	if responseJWT == "" {
+		return nil, fmt.Errorf("response mode<%s> requires a response", request.ResponseMode)
+	}
+	return v.VerifyAuthorizationResponseJWT(ctx, request, responseJWT)
+}
+
+// VerifyAuthorizationResponseJWT returns the response of a JWT secured response to a request. An encrypted response is
+// decrypted with the verifier's response encryption key, and must be encrypted if the request's client metadata asks
+// for it. A signed response must be signed by a key of the DID it is issued by, identified by its kid, for the
+// verifier's client ID, and must not have expired. Responses must be signed unless they are encrypted and the request
+// does not ask for a signing algorithm.
+func (v *Verifier) VerifyAuthorizationResponseJWT(ctx context.Context, request AuthorizationRequest, responseJWT string) (*AuthorizationResponse, error) {
+	metadata := request.ClientMetadata
+	if metadata == nil {
+		metadata = &ClientMetadata{}
+	}
+
+	payload := []byte(responseJWT)
+	encrypted := strings.Count(responseJWT, ".") == 4
+	if encrypted {
+		if v.encryptionKey == nil {
+			return nil, errors.New("verifier has no response encryption key")
+		}
+		alg := DefaultResponseEncryptionAlg
+		if metadata.AuthorizationEncryptedResponseAlg != "" {
+			alg = jwa.KeyEncryptionAlgorithm(metadata.AuthorizationEncryptedResponseAlg)
+		}
+		key, err := jwk.FromRaw(v.encryptionKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "converting response encryption key to JWK")
+		}
+		if payload, err = jwe.Decrypt(payload, jwe.WithKey(alg, key)); err != nil {
+			return nil, errors.Wrap(err, "decrypting response")
+		}
+	} else if metadata.AuthorizationEncryptedResponseAlg != "" {
+		return nil, errors.New("response must be encrypted")
+	}
+
+	var response AuthorizationResponse
+	if !strings.HasPrefix(strings.TrimSpace(string(payload)), "{") {
+		claims, err := v.verifyResponseSignature(ctx, request.ClientID, string(payload))
+		if err != nil {
+			return nil, err
+		}
+		claimsJSON, err := json.Marshal(claims)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling response claims")
+		}
+		if err = json.Unmarshal(claimsJSON, &response); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling response claims")
+		}
+		return &response, nil
+	}
+	if !encrypted || metadata.AuthorizationSignedResponseAlg != "" {
+		return nil, errors.New("response must be signed")
+	}
+	if err := json.Unmarshal(payload, &response); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling response")
+	}
+	return &response, nil
+}
+
+// verifyResponseSignature verifies a signed response for the given audience, and returns its claims
+func (v *Verifier) verifyResponseSignature(ctx context.Context, audience, responseJWT string) (map[string]any, error) {
+	headers, err := jwx.GetJWSHeaders([]byte(responseJWT))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting response headers")
+	}
+	kid := headers.KeyID()
+	issuer, _, ok := strings.Cut(kid, "#")
+	if !ok {
+		return nil, fmt.Errorf("response kid<%s> must be a DID URL", kid)
+	}
+	key, err := resolution.ResolveKeyForDID(ctx, v.resolver, issuer, kid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving response key<%s>", kid)
+	}
+	verifier, err := jwx.NewJWXVerifier(issuer, &kid, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "constructing verifier for response key<%s>", kid)
+	}
+	_, token, err := verifier.VerifyAndParse(responseJWT)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying response")
+	}
+	if token.Issuer() != issuer {
+		return nil, fmt.Errorf("response issuer<%s> is not the DID of its key<%s>", token.Issuer(), kid)
+	}
+	if !util.Contains(audience, token.Audience()) {
+		return nil, fmt.Errorf("response audience %s does not contain [%s]", token.Audience(), audience)
+	}
+	if token.Expiration().IsZero() || token.Expiration().Before(time.Now()) {
+		return nil, errors.New("response has expired")
+	}
+	return token.AsMap(ctx)
+}
+
+// SecureAuthorizationResponse secures a response to a request of a JWT response mode as a JWT secured response. The
+// response is signed by the holder for the verifier's client ID, with the algorithm the request's client metadata
+// names if any, and is encrypted to the first encryption key of its JWKS if the metadata asks for encryption. An
+// encrypted response is only signed if the metadata names a signing algorithm.
+func (h *Holder) SecureAuthorizationResponse(request AuthorizationRequest, response AuthorizationResponse) (string, error) {
+	metadata := request.ClientMetadata
+	if metadata == nil {
+		metadata = &ClientMetadata{}
+	}
+	encrypt := metadata.AuthorizationEncryptedResponseAlg != ""
+
+	var payload []byte
+	var err error
+	if !encrypt || metadata.AuthorizationSignedResponseAlg != "" {
+		if payload, err = h.signResponse(request.ClientID, metadata.AuthorizationSignedResponseAlg, response); err != nil {
+			return "", err
+		}
+	} else if payload, err = json.Marshal(response); err != nil {
+		return "", errors.Wrap(err, "marshalling response")
+	}
+	if !encrypt {
+		return string(payload), nil
+	}
+
+	encrypted, err := encryptResponse(payload, *metadata)
+	if err != nil {
+		return "", err
+	}
+	return string(encrypted), nil
+}
+
+// signResponse signs the claims of a response as a JWT issued by the holder for an audience
+func (h *Holder) signResponse(audience, requiredAlg string, response AuthorizationResponse) ([]byte, error) {
+	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+	alg := h.signer.ALG
+	if alg == "Ed25519" {
+		alg = jwa.EdDSA.String()
+	}
+	if requiredAlg != "" && requiredAlg != alg {
+		return nil, fmt.Errorf("verifier requires responses signed with alg<%s>, holder signs with<%s>", requiredAlg, alg)
+	}
+	claims, err := util.ToJSONMap(response)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting response claims")
+	}
+	t := jwt.New()
+	for k, val := range claims {
+		if err = t.Set(k, val); err != nil {
+			return nil, errors.Wrapf(err, "setting %s", k)
+		}
+	}
+	now := time.Now()
+	if err = t.Set(jwt.IssuerKey, h.signer.ID); err != nil {
+		return nil, errors.Wrap(err, "setting iss")
+	}
+	if err = t.Set(jwt.AudienceKey, audience); err != nil {
+		return nil, errors.Wrap(err, "setting aud")
+	}
+	if err = t.Set(jwt.IssuedAtKey, now); err != nil {
+		return nil, errors.Wrap(err, "setting iat")
+	}
+	if err = t.Set(jwt.ExpirationKey, now.Add(responseLifetime)); err != nil {
+		return nil, errors.Wrap(err, "setting exp")
+	}
+	hdrs := jws.NewHeaders()
+	if err = hdrs.Set(jws.KeyIDKey, h.signer.KID); err != nil {
+		return nil, errors.Wrap(err, "setting kid")
+	}
+	signed, err := jwt.Sign(t, jwt.WithKey(jwa.SignatureAlgorithm(alg), h.signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	if err != nil {
+		return nil, errors.Wrap(err, "signing response")
+	}
+	return signed, nil
+}
+
+// encryptResponse encrypts a response, signed or not, as a compact JWE to the first encryption key of a verifier's
+// JWKS, with the algorithms of its client metadata
+func encryptResponse(payload []byte, metadata ClientMetadata) ([]byte, error) {
+	if metadata.JWKS == nil {
+		return nil, errors.New("verifier has no keys to encrypt the response to")
+	}
+	var encryptionKey *jwx.PublicKeyJWK
+	for i, k := range metadata.JWKS.Keys {
+		if k.Use == "" || k.Use == "enc" {
+			encryptionKey = &metadata.JWKS.Keys[i]
+			break
+		}
+	}
+	if encryptionKey == nil {
+		return nil, errors.New("verifier has no encryption key")
+	}
+	publicKey, err := encryptionKey.ToPublicKey()
+	if err != nil {
+		return nil, errors.Wrapf(err, "converting verifier key<%s>", encryptionKey.KID)
+	}
+	key, err := jwk.FromRaw(publicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting verifier key to JWK")
+	}
+
+	alg := jwa.KeyEncryptionAlgorithm(metadata.AuthorizationEncryptedResponseAlg)
+	enc := DefaultResponseEncryptionEnc
+	if metadata.AuthorizationEncryptedResponseEnc != "" {
+		enc = jwa.ContentEncryptionAlgorithm(metadata.AuthorizationEncryptedResponseEnc)
+	}
+	headers := jwe.NewHeaders()
+	if encryptionKey.KID != "" {
+		if err = headers.Set(jwe.KeyIDKey, encryptionKey.KID); err != nil {
+			return nil, errors.Wrap(err, "setting kid")
+		}
+	}
+	if payload[0] != '{' {
+		// a signed response is nested in the encrypted one
+		if err = headers.Set(jwe.ContentTypeKey, "JWT"); err != nil {
+			return nil, errors.Wrap(err, "setting cty")
+		}
+	}
+	encrypted, err := jwe.Encrypt(payload, jwe.WithKey(alg, key), jwe.WithContentEncryption(enc), jwe.WithProtectedHeaders(headers))
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypting response")
+	}
+	return encrypted, nil
+}
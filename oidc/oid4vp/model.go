@@ -0,0 +1,353 @@
+package oid4vp
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// OpenID for Verifiable Presentations defines how verifiers request presentations from wallets with OAuth 2.0
+// https://openid.net/specs/openid-4-verifiable-presentations-1_0.html
+
+const (
+	// AuthorizationRequestScheme is the scheme of URIs passing authorization requests to wallets
+	AuthorizationRequestScheme string = "openid4vp://"
+	// SelfIssuedRequestScheme is the scheme of URIs passing Self-Issued OpenID Provider requests to wallets
+	// https://openid.net/specs/openid-connect-self-issued-v2-1_0.html
+	SelfIssuedRequestScheme string = "openid://"
+
+	// VPTokenResponseType is the response type of requests for a vp_token
+	VPTokenResponseType string = "vp_token"
+	// IDTokenResponseType is the response type of Self-Issued OpenID Provider requests for an ID token, which is
+	// combined with vp_token, as "vp_token id_token", in requests for both
+	IDTokenResponseType string = "id_token"
+
+	// RequestObjectJWTType is the `typ` header of signed request objects
+	RequestObjectJWTType string = "oauth-authz-req+jwt"
+	// RequestObjectMediaType is the media type of signed request objects served at a request_uri
+	RequestObjectMediaType string = "application/oauth-authz-req+jwt"
+	// SelfIssuedAudience is the `aud` of request objects for wallets whose issuer is not known to the verifier
+	SelfIssuedAudience string = "https://self-issued.me/v2"
+
+	clientIDParameter                  = "client_id"
+	clientIDSchemeParameter            = "client_id_scheme"
+	responseTypeParameter              = "response_type"
+	responseModeParameter              = "response_mode"
+	responseURIParameter               = "response_uri"
+	redirectURIParameter               = "redirect_uri"
+	nonceParameter                     = "nonce"
+	stateParameter                     = "state"
+	presentationDefinitionParameter    = "presentation_definition"
+	presentationDefinitionURIParameter = "presentation_definition_uri"
+	clientMetadataParameter            = "client_metadata"
+	requestParameter                   = "request"
+	requestURIParameter                = "request_uri"
+	requestURIMethodParameter          = "request_uri_method"
+	walletNonceParameter               = "wallet_nonce"
+	vpTokenParameter                   = "vp_token"
+	idTokenParameter                   = "id_token"
+	presentationSubmissionParameter    = "presentation_submission"
+	responseParameter                  = "response"
+)
+
+// ResponseMode is how the wallet returns the authorization response to the verifier
+type ResponseMode string
+
+const (
+	// FragmentResponseMode returns the response in the fragment of the redirect URI
+	FragmentResponseMode ResponseMode = "fragment"
+	// DirectPostResponseMode posts the response as a form to the response URI, for cross-device flows
+	DirectPostResponseMode ResponseMode = "direct_post"
+	// FragmentJWTResponseMode returns the response as a JWT secured response in the fragment of the redirect URI
+	// https://openid.net/specs/oauth-v2-jarm.html
+	FragmentJWTResponseMode ResponseMode = "fragment.jwt"
+	// DirectPostJWTResponseMode posts the response as a JWT secured response to the response URI
+	DirectPostJWTResponseMode ResponseMode = "direct_post.jwt"
+)
+
+// IsJWT returns whether responses of the response mode are JWT secured responses, signed by the wallet, encrypted to
+// the verifier, or both
+func (m ResponseMode) IsJWT() bool {
+	return strings.HasSuffix(string(m), ".jwt")
+}
+
+// ClientIDScheme is how the wallet identifies the verifier by its client ID
+type ClientIDScheme string
+
+const (
+	// DIDClientIDScheme is the scheme of verifiers identified by a DID, whose request objects are signed by a key
+	// of the DID's
+	DIDClientIDScheme ClientIDScheme = "did"
+	// RedirectURIClientIDScheme is the scheme of verifiers identified by their redirect or response URI, whose
+	// requests are not signed
+	RedirectURIClientIDScheme ClientIDScheme = "redirect_uri"
+)
+
+// ClientMetadata is the metadata of a verifier, passed in its authorization request
+type ClientMetadata struct {
+	ClientName string `json:"client_name,omitempty"`
+	// VPFormats are the formats of presentations and credentials, and their algorithms, the verifier supports
+	VPFormats *exchange.ClaimFormat `json:"vp_formats,omitempty"`
+
+	// AuthorizationSignedResponseAlg is the algorithm JWT secured responses must be signed with
+	AuthorizationSignedResponseAlg string `json:"authorization_signed_response_alg,omitempty"`
+	// AuthorizationEncryptedResponseAlg and AuthorizationEncryptedResponseEnc are the key management and content
+	// encryption algorithms JWT secured responses are encrypted to a key of the verifier's JWKS with. Responses are
+	// not encrypted unless the key management algorithm is given.
+	AuthorizationEncryptedResponseAlg string `json:"authorization_encrypted_response_alg,omitempty"`
+	AuthorizationEncryptedResponseEnc string `json:"authorization_encrypted_response_enc,omitempty"`
+	JWKS                              *JWKS  `json:"jwks,omitempty"`
+}
+
+// JWKS is a set of public keys of a verifier
+type JWKS struct {
+	Keys []jwx.PublicKeyJWK `json:"keys"`
+}
+
+// AuthorizationRequest requests presentations fulfilling a presentation definition from a wallet. Its JSON
+// representation is the claims of its request object.
+// https://openid.net/specs/openid-4-verifiable-presentations-1_0.html#name-authorization-request
+type AuthorizationRequest struct {
+	ClientID       string         `json:"client_id"`
+	ClientIDScheme ClientIDScheme `json:"client_id_scheme,omitempty"`
+	ResponseType   string         `json:"response_type"`
+	ResponseMode   ResponseMode   `json:"response_mode,omitempty"`
+	// ResponseURI is where responses of the direct_post response mode are posted
+	ResponseURI string `json:"response_uri,omitempty"`
+	RedirectURI string `json:"redirect_uri,omitempty"`
+	// Nonce binds the presentations of the response to the request
+	Nonce string `json:"nonce"`
+	// State binds the response to the verifier's session
+	State string `json:"state,omitempty"`
+
+	PresentationDefinition    *exchange.PresentationDefinition `json:"presentation_definition,omitempty"`
+	PresentationDefinitionURI string                           `json:"presentation_definition_uri,omitempty"`
+	ClientMetadata            *ClientMetadata                  `json:"client_metadata,omitempty"`
+
+	// WalletNonce is the nonce of the wallet a request object fetched with the post request URI method is bound to
+	WalletNonce string `json:"wallet_nonce,omitempty"`
+}
+
+// RequestsVPToken returns whether the request's response type asks for a vp_token
+func (r AuthorizationRequest) RequestsVPToken() bool {
+	return util.Contains(VPTokenResponseType, strings.Fields(r.ResponseType))
+}
+
+// RequestsIDToken returns whether the request's response type asks for a self-issued ID token
+func (r AuthorizationRequest) RequestsIDToken() bool {
+	return util.Contains(IDTokenResponseType, strings.Fields(r.ResponseType))
+}
+
+// IsValid checks the request has the parameters the wallet needs to respond to it
+func (r AuthorizationRequest) IsValid() error {
+	if r.ClientID == "" {
+		return errors.New("client id cannot be empty")
+	}
+	responseTypes := strings.Fields(r.ResponseType)
+	if len(responseTypes) == 0 || len(responseTypes) > 2 || (len(responseTypes) == 2 && responseTypes[0] == responseTypes[1]) {
+		return fmt.Errorf("response type<%s> is not supported", r.ResponseType)
+	}
+	for _, responseType := range responseTypes {
+		if responseType != VPTokenResponseType && responseType != IDTokenResponseType {
+			return fmt.Errorf("response type<%s> is not supported", r.ResponseType)
+		}
+	}
+	if r.Nonce == "" {
+		return errors.New("nonce cannot be empty")
+	}
+	if !r.RequestsVPToken() {
+		if r.PresentationDefinition != nil || r.PresentationDefinitionURI != "" {
+			return fmt.Errorf("response type<%s> cannot have a presentation definition", r.ResponseType)
+		}
+	} else if (r.PresentationDefinition == nil) == (r.PresentationDefinitionURI == "") {
+		return errors.New("exactly one of presentation definition and presentation definition uri is required")
+	}
+	switch r.ResponseMode {
+	case DirectPostResponseMode, DirectPostJWTResponseMode:
+		if r.ResponseURI == "" {
+			return fmt.Errorf("response mode<%s> requires a response uri", r.ResponseMode)
+		}
+		if r.RedirectURI != "" {
+			return fmt.Errorf("response mode<%s> cannot have a redirect uri", r.ResponseMode)
+		}
+	case "", FragmentResponseMode, FragmentJWTResponseMode:
+		if r.RedirectURI == "" {
+			return fmt.Errorf("response mode<%s> requires a redirect uri", r.ResponseMode)
+		}
+	default:
+		return fmt.Errorf("response mode<%s> is not supported", r.ResponseMode)
+	}
+	return nil
+}
+
+// Values returns the query parameters passing the request by value, in which the presentation definition and client
+// metadata are JSON encoded
+func (r AuthorizationRequest) Values() (url.Values, error) {
+	values := url.Values{}
+	for key, value := range map[string]string{
+		clientIDParameter:                  r.ClientID,
+		clientIDSchemeParameter:            string(r.ClientIDScheme),
+		responseTypeParameter:              r.ResponseType,
+		responseModeParameter:              string(r.ResponseMode),
+		responseURIParameter:               r.ResponseURI,
+		redirectURIParameter:               r.RedirectURI,
+		nonceParameter:                     r.Nonce,
+		stateParameter:                     r.State,
+		presentationDefinitionURIParameter: r.PresentationDefinitionURI,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	if r.PresentationDefinition != nil {
+		defJSON, err := json.Marshal(r.PresentationDefinition)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling presentation definition")
+		}
+		values.Set(presentationDefinitionParameter, string(defJSON))
+	}
+	if r.ClientMetadata != nil {
+		metadataJSON, err := json.Marshal(r.ClientMetadata)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling client metadata")
+		}
+		values.Set(clientMetadataParameter, string(metadataJSON))
+	}
+	return values, nil
+}
+
+// URI returns the URI passing the request to a wallet by value, of the openid:// scheme for requests for an ID token
+// alone
+func (r AuthorizationRequest) URI() (string, error) {
+	values, err := r.Values()
+	if err != nil {
+		return "", err
+	}
+	if !r.RequestsVPToken() {
+		return SelfIssuedRequestScheme + "?" + values.Encode(), nil
+	}
+	return AuthorizationRequestScheme + "?" + values.Encode(), nil
+}
+
+// AuthorizationRequestFromValues returns the request passed by value in query parameters
+func AuthorizationRequestFromValues(values url.Values) (*AuthorizationRequest, error) {
+	request := AuthorizationRequest{
+		ClientID:                  values.Get(clientIDParameter),
+		ClientIDScheme:            ClientIDScheme(values.Get(clientIDSchemeParameter)),
+		ResponseType:              values.Get(responseTypeParameter),
+		ResponseMode:              ResponseMode(values.Get(responseModeParameter)),
+		ResponseURI:               values.Get(responseURIParameter),
+		RedirectURI:               values.Get(redirectURIParameter),
+		Nonce:                     values.Get(nonceParameter),
+		State:                     values.Get(stateParameter),
+		PresentationDefinitionURI: values.Get(presentationDefinitionURIParameter),
+	}
+	if defJSON := values.Get(presentationDefinitionParameter); defJSON != "" {
+		var def exchange.PresentationDefinition
+		if err := json.Unmarshal([]byte(defJSON), &def); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling presentation definition")
+		}
+		request.PresentationDefinition = &def
+	}
+	if metadataJSON := values.Get(clientMetadataParameter); metadataJSON != "" {
+		var metadata ClientMetadata
+		if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling client metadata")
+		}
+		request.ClientMetadata = &metadata
+	}
+	return &request, nil
+}
+
+// RequestObjectReferenceURI returns the URI passing a request to a wallet by reference, the wallet fetching the
+// signed request object of the given client from the given URI
+func RequestObjectReferenceURI(clientID, requestURI string) string {
+	return AuthorizationRequestScheme + "?" + url.Values{
+		clientIDParameter:   {clientID},
+		requestURIParameter: {requestURI},
+	}.Encode()
+}
+
+// AuthorizationResponse is the response of a wallet to an authorization request, with the presentations it submits,
+// and its self-issued ID token if the request asks for one
+// https://openid.net/specs/openid-4-verifiable-presentations-1_0.html#name-response
+type AuthorizationResponse struct {
+	// VPToken is a presentation, or an array of presentations, each of which is a JWT or a JSON object, or a
+	// credential submitted on its own, such as a vc+sd-jwt
+	VPToken                any                              `json:"vp_token,omitempty"`
+	PresentationSubmission *exchange.PresentationSubmission `json:"presentation_submission,omitempty"`
+	IDToken                string                           `json:"id_token,omitempty"`
+	State                  string                           `json:"state,omitempty"`
+}
+
+// Form returns the form encoding of the response, in which the vp_token is a string if it is a single JWT, and is
+// otherwise JSON encoded, as is the presentation submission
+func (r AuthorizationResponse) Form() (url.Values, error) {
+	form := url.Values{}
+	if r.VPToken != nil {
+		if token, ok := r.VPToken.(string); ok {
+			form.Set(vpTokenParameter, token)
+		} else {
+			tokenJSON, err := json.Marshal(r.VPToken)
+			if err != nil {
+				return nil, errors.Wrap(err, "marshalling vp token")
+			}
+			form.Set(vpTokenParameter, string(tokenJSON))
+		}
+		submissionJSON, err := json.Marshal(r.PresentationSubmission)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling presentation submission")
+		}
+		form.Set(presentationSubmissionParameter, string(submissionJSON))
+	}
+	if r.IDToken != "" {
+		form.Set(idTokenParameter, r.IDToken)
+	}
+	if r.State != "" {
+		form.Set(stateParameter, r.State)
+	}
+	return form, nil
+}
+
+// AuthorizationResponseFromForm returns the response of a form, such as that posted to the response URI of the
+// direct_post response mode
+func AuthorizationResponseFromForm(form url.Values) (*AuthorizationResponse, error) {
+	response := AuthorizationResponse{IDToken: form.Get(idTokenParameter), State: form.Get(stateParameter)}
+	token := form.Get(vpTokenParameter)
+	if token == "" {
+		if response.IDToken != "" {
+			return &response, nil
+		}
+		return nil, errors.New("vp token cannot be empty")
+	}
+	if strings.HasPrefix(token, "{") || strings.HasPrefix(token, "[") {
+		if err := json.Unmarshal([]byte(token), &response.VPToken); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling vp token")
+		}
+	} else {
+		response.VPToken = token
+	}
+	submissionJSON := form.Get(presentationSubmissionParameter)
+	if submissionJSON == "" {
+		return nil, errors.New("presentation submission cannot be empty")
+	}
+	var submission exchange.PresentationSubmission
+	if err := json.Unmarshal([]byte(submissionJSON), &submission); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling presentation submission")
+	}
+	response.PresentationSubmission = &submission
+	return &response, nil
+}
+
+// DirectPostResponse is the response of a verifier to an authorization response posted to its response URI, which
+// may redirect the user agent to continue the verifier's session
+type DirectPostResponse struct {
+	RedirectURI string `json:"redirect_uri,omitempty"`
+}
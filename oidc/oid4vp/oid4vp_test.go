@@ -0,0 +1,655 @@
+package oid4vp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/oidc/par"
+	"github.com/TBD54566975/ssi-sdk/oidc/qr"
+	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/TBD54566975/ssi-sdk/wallet"
+)
+
+func TestVerifier(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	verifierSigner := getTestSigner(t)
+	issuerSigner := getTestSigner(t)
+	holderSigner := getTestSigner(t)
+	verifier, err := NewVerifier(verifierSigner, resolver)
+	require.NoError(t, err)
+	def := getTestPresentationDefinition()
+	ctx := context.Background()
+
+	t.Run("request by value", func(tt *testing.T) {
+		request, err := verifier.CreateAuthorizationRequest(def, RequestOptions{
+			ResponseURI:    "https://verifier.example.com/response",
+			ClientMetadata: &ClientMetadata{ClientName: "Verifier"},
+		})
+		require.NoError(tt, err)
+		assert.Equal(tt, verifierSigner.ID, request.ClientID)
+		assert.Equal(tt, DIDClientIDScheme, request.ClientIDScheme)
+		assert.Equal(tt, DirectPostResponseMode, request.ResponseMode)
+		assert.NotEmpty(tt, request.Nonce)
+		assert.NotEmpty(tt, request.State)
+
+		uri, err := request.URI()
+		require.NoError(tt, err)
+		assert.True(tt, strings.HasPrefix(uri, AuthorizationRequestScheme+"?"))
+		parsed, err := url.Parse(uri)
+		require.NoError(tt, err)
+		fromValues, err := AuthorizationRequestFromValues(parsed.Query())
+		require.NoError(tt, err)
+		assert.Equal(tt, request, fromValues)
+	})
+
+	t.Run("signed request object", func(tt *testing.T) {
+		request, err := verifier.CreateAuthorizationRequest(def, RequestOptions{PresentationDefinitionURI: "https://verifier.example.com/definition"})
+		require.NoError(tt, err)
+		assert.Equal(tt, FragmentResponseMode, request.ResponseMode)
+		assert.Nil(tt, request.PresentationDefinition)
+
+		requestObject, err := verifier.SignRequestObject(*request)
+		require.NoError(tt, err)
+		headers, err := jwx.GetJWSHeaders(requestObject)
+		require.NoError(tt, err)
+		assert.Equal(tt, RequestObjectJWTType, headers.Type())
+		assert.Equal(tt, verifierSigner.KID, headers.KeyID())
+
+		jwtVerifier, err := verifierSigner.ToVerifier(verifierSigner.ID)
+		require.NoError(tt, err)
+		_, token, err := jwtVerifier.VerifyAndParse(string(requestObject))
+		require.NoError(tt, err)
+		assert.Equal(tt, verifierSigner.ID, token.Issuer())
+		assert.Equal(tt, []string{SelfIssuedAudience}, token.Audience())
+		nonce, _ := token.Get(nonceParameter)
+		assert.Equal(tt, request.Nonce, nonce)
+
+		_, err = verifier.SignRequestObject(AuthorizationRequest{ClientID: "did:example:other"})
+		assert.ErrorContains(tt, err, "request client id<did:example:other> is not the verifier's")
+	})
+
+	t.Run("QR codes", func(tt *testing.T) {
+		request, err := verifier.CreateAuthorizationRequest(def, RequestOptions{ResponseURI: "https://verifier.example.com/response"})
+		require.NoError(tt, err)
+
+		// the signed request object does not fit in a small QR code, which passes it by reference
+		opts := qr.Options{MaxVersion: 10}
+		code, byReference, err := verifier.RequestQRCode(*request, "https://verifier.example.com/requests/1", opts)
+		require.NoError(tt, err)
+		assert.True(tt, byReference)
+		scanned, err := qr.Decode(code.Image(2))
+		require.NoError(tt, err)
+		assert.Equal(tt, RequestObjectReferenceURI(verifier.ClientID(), "https://verifier.example.com/requests/1"), scanned)
+
+		_, _, err = verifier.RequestQRCode(*request, "", opts)
+		assert.ErrorContains(tt, err, "request does not fit in a QR code, and has no request uri")
+
+		code, byReference, err = verifier.RequestQRCode(*request, "", qr.Options{Level: qr.Low, MaxVersion: qr.MaxVersion})
+		require.NoError(tt, err)
+		assert.False(tt, byReference)
+		scanned, err = qr.Decode(code.Image(2))
+		require.NoError(tt, err)
+		assert.True(tt, strings.HasPrefix(scanned, AuthorizationRequestScheme+"?"))
+		assert.Contains(tt, scanned, requestParameter+"=")
+	})
+
+	t.Run("verify response", func(tt *testing.T) {
+		request, err := verifier.CreateAuthorizationRequest(def, RequestOptions{ResponseURI: "https://verifier.example.com/response"})
+		require.NoError(tt, err)
+		vpToken, submission := getTestVPToken(tt, issuerSigner, holderSigner, def, request.Nonce, request.ClientID)
+
+		// the response is posted as a form
+		form, err := AuthorizationResponse{VPToken: vpToken, PresentationSubmission: submission, State: request.State}.Form()
+		require.NoError(tt, err)
+		response, err := AuthorizationResponseFromForm(form)
+		require.NoError(tt, err)
+		assert.Equal(tt, vpToken, response.VPToken)
+
+		verified, err := verifier.VerifyAuthorizationResponse(ctx, *request, *response, VerificationOptions{})
+		require.NoError(tt, err)
+		require.Len(tt, verified, 1)
+		assert.Equal(tt, "name-descriptor", verified[0].InputDescriptorID)
+
+		// an array of presentations
+		arrayResponse := AuthorizationResponse{VPToken: []any{vpToken}, PresentationSubmission: arraySubmission(submission), State: request.State}
+		_, err = verifier.VerifyAuthorizationResponse(ctx, *request, arrayResponse, VerificationOptions{})
+		require.NoError(tt, err)
+
+		wrongState := *response
+		wrongState.State = "other"
+		_, err = verifier.VerifyAuthorizationResponse(ctx, *request, wrongState, VerificationOptions{})
+		assert.ErrorContains(tt, err, "response state does not match the request's")
+
+		// presentations bound to another request are replays
+		otherRequest, err := verifier.CreateAuthorizationRequest(def, RequestOptions{ResponseURI: "https://verifier.example.com/response"})
+		require.NoError(tt, err)
+		otherRequest.State = request.State
+		_, err = verifier.VerifyAuthorizationResponse(ctx, *otherRequest, *response, VerificationOptions{})
+		assert.ErrorContains(tt, err, "challenge mismatch")
+
+		// presentation definitions passed by reference must be given
+		byReference := *request
+		byReference.PresentationDefinition = nil
+		_, err = verifier.VerifyAuthorizationResponse(ctx, byReference, *response, VerificationOptions{})
+		assert.ErrorContains(tt, err, "presentation definition of the request cannot be empty")
+		_, err = verifier.VerifyAuthorizationResponse(ctx, byReference, *response, VerificationOptions{PresentationDefinition: &def})
+		assert.NoError(tt, err)
+	})
+
+	t.Run("invalid requests", func(tt *testing.T) {
+		_, err := verifier.CreateAuthorizationRequest(def, RequestOptions{ResponseMode: DirectPostResponseMode})
+		assert.ErrorContains(tt, err, "response mode<direct_post> requires a response uri")
+		_, err = verifier.CreateAuthorizationRequest(def, RequestOptions{})
+		assert.ErrorContains(tt, err, "response mode<fragment> requires a redirect uri")
+		_, err = verifier.CreateIDTokenRequest(RequestOptions{PresentationDefinitionURI: "https://verifier.example.com/definition"})
+		assert.ErrorContains(tt, err, "cannot have a presentation definition")
+		invalid := AuthorizationRequest{ClientID: verifier.ClientID(), ResponseType: "vp_token vp_token", Nonce: "nonce", RedirectURI: "https://verifier.example.com/callback"}
+		assert.ErrorContains(tt, invalid.IsValid(), "response type<vp_token vp_token> is not supported")
+		invalid.ResponseType = IDTokenResponseType
+		invalid.PresentationDefinition = &def
+		assert.ErrorContains(tt, invalid.IsValid(), "response type<id_token> cannot have a presentation definition")
+		_, err = NewVerifier(jwx.Signer{ID: "verifier"}, resolver)
+		assert.ErrorContains(tt, err, "signer id<verifier> must be a DID")
+	})
+}
+
+func TestHolder(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	verifierSigner := getTestSigner(t)
+	issuerSigner := getTestSigner(t)
+	holderSigner := getTestSigner(t)
+	verifier, err := NewVerifier(verifierSigner, resolver)
+	require.NoError(t, err)
+	def := getTestPresentationDefinition()
+	ctx := context.Background()
+
+	// the verifier's response uri verifies posted responses to its current request
+	var request *AuthorizationRequest
+	var verified []exchange.VerifiedSubmissionData
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(def)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		response, err := verifier.ParseAuthorizationResponse(r.Context(), *request, r.PostForm)
+		if err == nil {
+			verified, err = verifier.VerifyAuthorizationResponse(r.Context(), *request, *response, VerificationOptions{PresentationDefinition: &def})
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(DirectPostResponse{RedirectURI: "https://verifier.example.com/done"})
+	}))
+	defer server.Close()
+
+	getHolder := func(tt *testing.T, creds ...any) *Holder {
+		store := wallet.NewMemoryCredentialStore()
+		for _, cred := range creds {
+			stored, err := wallet.NewStoredCredential(cred)
+			require.NoError(tt, err)
+			require.NoError(tt, store.Put(ctx, *stored))
+		}
+		holder, err := NewHolder(holderSigner, store, resolver)
+		require.NoError(tt, err)
+		return holder
+	}
+	newRequest := func(tt *testing.T, opts RequestOptions) *AuthorizationRequest {
+		opts.ResponseURI = server.URL
+		newRequest, err := verifier.CreateAuthorizationRequest(def, opts)
+		require.NoError(tt, err)
+		return newRequest
+	}
+
+	t.Run("signed request by value", func(tt *testing.T) {
+		holder := getHolder(tt)
+		request = newRequest(tt, RequestOptions{PresentationDefinitionURI: server.URL})
+		uri, err := verifier.SignedRequestURI(*request)
+		require.NoError(tt, err)
+
+		received, err := holder.GetAuthorizationRequest(ctx, uri)
+		require.NoError(tt, err)
+		assert.Equal(tt, request.Nonce, received.Nonce)
+		assert.Equal(tt, request.ResponseURI, received.ResponseURI)
+		require.NotNil(tt, received.PresentationDefinition)
+		assert.Equal(tt, def.ID, received.PresentationDefinition.ID)
+
+		// requests are scanned from QR codes
+		code, _, err := verifier.RequestQRCode(*request, "", qr.Options{Level: qr.Low, MaxVersion: qr.MaxVersion})
+		require.NoError(tt, err)
+		scanned, err := holder.GetAuthorizationRequestFromQRCode(ctx, code.Image(2))
+		require.NoError(tt, err)
+		assert.Equal(tt, request.Nonce, scanned.Nonce)
+
+		// requests of verifiers identified by a DID must be signed
+		unsigned, err := request.URI()
+		require.NoError(tt, err)
+		_, err = holder.GetAuthorizationRequest(ctx, unsigned)
+		assert.ErrorContains(tt, err, "identified by a DID must be signed")
+
+		// request objects must be signed by the client
+		otherVerifier, err := NewVerifier(getTestSigner(tt), resolver)
+		require.NoError(tt, err)
+		otherRequest := *request
+		otherRequest.ClientID = otherVerifier.ClientID()
+		requestObject, err := otherVerifier.SignRequestObject(otherRequest)
+		require.NoError(tt, err)
+		_, err = holder.GetAuthorizationRequest(ctx, RequestObjectURI(request.ClientID, string(requestObject)))
+		assert.ErrorContains(tt, err, "is not a key of client")
+	})
+
+	t.Run("JWT credential", func(tt *testing.T) {
+		credJWT, err := integrity.SignVerifiableCredentialJWT(issuerSigner, getTestHolderCredential(issuerSigner, holderSigner))
+		require.NoError(tt, err)
+		holder := getHolder(tt, string(credJWT))
+		request = newRequest(tt, RequestOptions{})
+
+		response, err := holder.CreateAuthorizationResponse(ctx, *request)
+		require.NoError(tt, err)
+		vpToken, ok := response.VPToken.(string)
+		require.True(tt, ok)
+		assert.Equal(tt, 2, strings.Count(vpToken, "."))
+		require.Len(tt, response.PresentationSubmission.DescriptorMap, 1)
+		assert.Equal(tt, "$", response.PresentationSubmission.DescriptorMap[0].Path)
+		assert.Equal(tt, exchange.JWTVP.String(), response.PresentationSubmission.DescriptorMap[0].Format)
+
+		redirectURI, err := holder.SubmitAuthorizationResponse(ctx, *request, *response)
+		require.NoError(tt, err)
+		assert.Equal(tt, "https://verifier.example.com/done", redirectURI)
+		require.Len(tt, verified, 1)
+		assert.Equal(tt, "name-descriptor", verified[0].InputDescriptorID)
+	})
+
+	t.Run("SD-JWT credential", func(tt *testing.T) {
+		cred := getTestHolderCredential(issuerSigner, holderSigner)
+		cred.Context = []any{credential.VerifiableCredentialsV2LinkedDataContext}
+		sdJWT, err := integrity.SignVerifiableCredentialSDJWT(issuerSigner, cred, "name", "degree")
+		require.NoError(tt, err)
+		holder := getHolder(tt, string(sdJWT))
+		request = newRequest(tt, RequestOptions{})
+
+		response, err := holder.CreateAuthorizationResponse(ctx, *request)
+		require.NoError(tt, err)
+		require.Len(tt, response.PresentationSubmission.DescriptorMap, 1)
+		assert.Equal(tt, exchange.SubmissionDescriptor{ID: "name-descriptor", Format: exchange.SDJWTVC.String(), Path: "$"}, response.PresentationSubmission.DescriptorMap[0])
+
+		// only the claims the input descriptor selects are disclosed
+		_, presented, err := integrity.ParseVerifiableCredentialFromSDJWT(response.VPToken.(string))
+		require.NoError(tt, err)
+		assert.Equal(tt, "Alice", presented.CredentialSubject["name"])
+		assert.NotContains(tt, presented.CredentialSubject, "degree")
+
+		_, err = holder.SubmitAuthorizationResponse(ctx, *request, *response)
+		require.NoError(tt, err)
+		require.Len(tt, verified, 1)
+
+		// the key binding JWT binds the credential to the request
+		otherRequest := newRequest(tt, RequestOptions{})
+		otherRequest.State = request.State
+		_, err = verifier.VerifyAuthorizationResponse(ctx, *otherRequest, *response, VerificationOptions{})
+		assert.ErrorContains(tt, err, "challenge mismatch")
+	})
+
+	t.Run("LD credential", func(tt *testing.T) {
+		suite := jws2020.GetJSONWebSignature2020Suite()
+		issuerLDSigner, err := jws2020.NewJSONWebKeySigner(issuerSigner.KID, issuerSigner.PrivateKeyJWK, cryptosuite.AssertionMethod)
+		require.NoError(tt, err)
+		cred := getTestHolderCredential(issuerSigner, holderSigner)
+		require.NoError(tt, suite.Sign(issuerLDSigner, &cred))
+		holder := getHolder(tt, cred)
+		holderLDSigner, err := jws2020.NewJSONWebKeySigner(holderSigner.KID, holderSigner.PrivateKeyJWK, cryptosuite.Authentication)
+		require.NoError(tt, err)
+		holder.SetLDSigner(suite, holderLDSigner)
+		request = newRequest(tt, RequestOptions{ClientMetadata: &ClientMetadata{VPFormats: &exchange.ClaimFormat{LDPVP: &exchange.LDPType{}}}})
+
+		response, err := holder.CreateAuthorizationResponse(ctx, *request)
+		require.NoError(tt, err)
+		presentation, ok := response.VPToken.(credential.VerifiablePresentation)
+		require.True(tt, ok)
+		assert.Equal(tt, request.Nonce, presentation.GetProof().Challenge)
+		assert.Equal(tt, request.ClientID, presentation.GetProof().Domain)
+		assert.Equal(tt, exchange.LDPVP.String(), response.PresentationSubmission.DescriptorMap[0].Format)
+
+		_, err = holder.SubmitAuthorizationResponse(ctx, *request, *response)
+		require.NoError(tt, err)
+		require.Len(tt, verified, 1)
+	})
+
+	t.Run("JWT secured responses", func(tt *testing.T) {
+		credJWT, err := integrity.SignVerifiableCredentialJWT(issuerSigner, getTestHolderCredential(issuerSigner, holderSigner))
+		require.NoError(tt, err)
+		holder := getHolder(tt, string(credJWT))
+
+		// signed
+		request = newRequest(tt, RequestOptions{ResponseMode: DirectPostJWTResponseMode})
+		response, err := holder.CreateAuthorizationResponse(ctx, *request)
+		require.NoError(tt, err)
+		signed, err := holder.SecureAuthorizationResponse(*request, *response)
+		require.NoError(tt, err)
+		assert.Equal(tt, 2, strings.Count(signed, "."))
+		_, err = holder.SubmitAuthorizationResponse(ctx, *request, *response)
+		require.NoError(tt, err)
+		require.Len(tt, verified, 1)
+
+		// responses for another verifier are rejected
+		otherRequest := *request
+		otherRequest.ClientID = "did:example:other"
+		_, err = verifier.VerifyAuthorizationResponseJWT(ctx, otherRequest, signed)
+		assert.ErrorContains(tt, err, "does not contain [did:example:other]")
+
+		// encrypted to the verifier's key
+		_, encryptionKey, err := crypto.GenerateX25519Key()
+		require.NoError(tt, err)
+		require.NoError(tt, verifier.SetResponseEncryptionKey(verifier.ClientID()+"#enc", encryptionKey))
+		request = newRequest(tt, RequestOptions{ResponseMode: DirectPostJWTResponseMode})
+		require.NotNil(tt, request.ClientMetadata)
+		require.NotNil(tt, request.ClientMetadata.JWKS)
+		assert.Equal(tt, verifier.ClientID()+"#enc", request.ClientMetadata.JWKS.Keys[0].KID)
+		response, err = holder.CreateAuthorizationResponse(ctx, *request)
+		require.NoError(tt, err)
+		encrypted, err := holder.SecureAuthorizationResponse(*request, *response)
+		require.NoError(tt, err)
+		assert.Equal(tt, 4, strings.Count(encrypted, "."))
+		decrypted, err := verifier.VerifyAuthorizationResponseJWT(ctx, *request, encrypted)
+		require.NoError(tt, err)
+		assert.Equal(tt, response.VPToken, decrypted.VPToken)
+		assert.Equal(tt, request.State, decrypted.State)
+		_, err = holder.SubmitAuthorizationResponse(ctx, *request, *response)
+		require.NoError(tt, err)
+
+		// responses must be encrypted when the verifier asks for it
+		_, err = verifier.VerifyAuthorizationResponseJWT(ctx, *request, signed)
+		assert.ErrorContains(tt, err, "response must be encrypted")
+
+		// signed, then encrypted
+		request = newRequest(tt, RequestOptions{
+			ResponseMode:   DirectPostJWTResponseMode,
+			ClientMetadata: &ClientMetadata{AuthorizationSignedResponseAlg: "EdDSA"},
+		})
+		response, err = holder.CreateAuthorizationResponse(ctx, *request)
+		require.NoError(tt, err)
+		_, err = holder.SubmitAuthorizationResponse(ctx, *request, *response)
+		require.NoError(tt, err)
+		_, err = verifier.VerifyAuthorizationResponseJWT(ctx, *request, encrypted)
+		assert.ErrorContains(tt, err, "response must be signed")
+	})
+
+	t.Run("signed request by reference", func(tt *testing.T) {
+		// the verifier serves its current request's request object, counting the request objects it serves
+		fetches := 0
+		mux := http.NewServeMux()
+		mux.Handle("/requests/current", verifier.RequestObjectHandler(func(*http.Request) (*AuthorizationRequest, error) {
+			fetches++
+			return request, nil
+		}))
+		mux.HandleFunc("/requests/unsigned", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(request)
+		})
+		mux.HandleFunc("/requests/unbound", func(w http.ResponseWriter, _ *http.Request) {
+			requestObject, err := verifier.SignRequestObject(*request)
+			require.NoError(tt, err)
+			w.Header().Set("Content-Type", RequestObjectMediaType)
+			_, _ = w.Write(requestObject)
+		})
+		requestServer := httptest.NewTLSServer(mux)
+		defer requestServer.Close()
+		holder := getHolder(tt)
+		holder.Client = requestServer.Client()
+
+		request = newRequest(tt, RequestOptions{})
+		uri := RequestObjectReferenceURI(request.ClientID, requestServer.URL+"/requests/current")
+		received, err := holder.GetAuthorizationRequest(ctx, uri)
+		require.NoError(tt, err)
+		assert.Equal(tt, request.Nonce, received.Nonce)
+		assert.Equal(tt, request.ResponseURI, received.ResponseURI)
+
+		// request objects fetched with a GET are cached, for their client
+		_, err = holder.GetAuthorizationRequest(ctx, uri)
+		require.NoError(tt, err)
+		assert.Equal(tt, 1, fetches)
+		_, err = holder.GetAuthorizationRequest(ctx, RequestObjectReferenceURI("did:example:other", requestServer.URL+"/requests/current"))
+		assert.ErrorContains(tt, err, "is not of client<did:example:other>")
+
+		// those fetched with a POST are bound to the wallet's nonce, and are not cached
+		received, err = holder.GetAuthorizationRequest(ctx, uri+"&request_uri_method=post")
+		require.NoError(tt, err)
+		assert.NotEmpty(tt, received.WalletNonce)
+		assert.Equal(tt, 2, fetches)
+		unbound := RequestObjectReferenceURI(request.ClientID, requestServer.URL+"/requests/unbound")
+		_, err = holder.GetAuthorizationRequest(ctx, unbound+"&request_uri_method=post")
+		assert.ErrorContains(tt, err, "request object is not bound to the wallet nonce")
+		_, err = holder.GetAuthorizationRequest(ctx, uri+"&request_uri_method=put")
+		assert.ErrorContains(tt, err, "request uri method<put> is not supported")
+
+		// requests by reference are not downgraded to unsigned requests, or to requests fetched without TLS
+		_, err = holder.GetAuthorizationRequest(ctx, RequestObjectReferenceURI(request.ClientID, requestServer.URL+"/requests/unsigned"))
+		assert.ErrorContains(tt, err, "content type<application/json> must be application/oauth-authz-req+jwt")
+		_, err = holder.GetAuthorizationRequest(ctx, RequestObjectReferenceURI(request.ClientID, server.URL+"/requests/current"))
+		assert.ErrorContains(tt, err, "must be https")
+		signed, err := verifier.SignedRequestURI(*request)
+		require.NoError(tt, err)
+		_, err = holder.GetAuthorizationRequest(ctx, signed+"&request_uri="+url.QueryEscape(requestServer.URL+"/requests/current"))
+		assert.ErrorContains(tt, err, "request cannot be passed both by value and by reference")
+	})
+
+	t.Run("pushed request objects", func(tt *testing.T) {
+		// the wallet's pushed authorization request endpoint authenticates verifiers by their DID
+		walletServer := httptest.NewServer(nil)
+		defer walletServer.Close()
+		pushedRequests, err := par.NewServer(walletServer.URL, resolver, par.ServerOptions{})
+		require.NoError(tt, err)
+		walletServer.Config.Handler = pushedRequests.Handler()
+		holder := getHolder(tt)
+
+		request = newRequest(tt, RequestOptions{})
+		uri, err := verifier.PushAuthorizationRequest(ctx, walletServer.URL, walletServer.URL, *request)
+		require.NoError(tt, err)
+		_, err = holder.GetAuthorizationRequest(ctx, uri)
+		assert.ErrorContains(tt, err, "the holder has no pushed requests")
+
+		holder.SetPushedRequests(pushedRequests)
+		uri, err = verifier.PushAuthorizationRequest(ctx, walletServer.URL, walletServer.URL, *request)
+		require.NoError(tt, err)
+		received, err := holder.GetAuthorizationRequest(ctx, uri)
+		require.NoError(tt, err)
+		assert.Equal(tt, request.Nonce, received.Nonce)
+		assert.Equal(tt, request.ResponseURI, received.ResponseURI)
+
+		// pushed requests are redeemed once, by their client
+		_, err = holder.GetAuthorizationRequest(ctx, uri)
+		assert.ErrorContains(tt, err, "is not valid")
+		uri, err = verifier.PushAuthorizationRequest(ctx, walletServer.URL, walletServer.URL, *request)
+		require.NoError(tt, err)
+		requestURI, err := url.Parse(uri)
+		require.NoError(tt, err)
+		_, err = holder.GetAuthorizationRequest(ctx, RequestObjectReferenceURI("did:example:other", requestURI.Query().Get("request_uri")))
+		assert.ErrorContains(tt, err, "is not of client<did:example:other>")
+
+		// client assertions are for the wallet alone
+		_, err = verifier.PushAuthorizationRequest(ctx, walletServer.URL, "https://other.example.com", *request)
+		var parErr *par.Error
+		require.ErrorAs(tt, err, &parErr)
+		assert.Equal(tt, par.InvalidClient, parErr.Code)
+	})
+
+	t.Run("self-issued ID tokens", func(tt *testing.T) {
+		requestServer := httptest.NewTLSServer(verifier.RequestObjectHandler(func(*http.Request) (*AuthorizationRequest, error) {
+			return request, nil
+		}))
+		defer requestServer.Close()
+		holder := getHolder(tt)
+		holder.Client = requestServer.Client()
+
+		var err error
+		request, err = verifier.CreateIDTokenRequest(RequestOptions{ResponseURI: server.URL})
+		require.NoError(tt, err)
+		received, err := holder.GetAuthorizationRequest(ctx, RequestObjectReferenceURI(request.ClientID, requestServer.URL))
+		require.NoError(tt, err)
+		assert.True(tt, received.RequestsIDToken())
+		assert.False(tt, received.RequestsVPToken())
+
+		response, err := holder.CreateAuthorizationResponse(ctx, *received)
+		require.NoError(tt, err)
+		assert.Nil(tt, response.VPToken)
+		subject, err := verifier.VerifyIDToken(ctx, *request, response.IDToken)
+		require.NoError(tt, err)
+		assert.Equal(tt, holderSigner.ID, subject)
+		redirectURI, err := holder.SubmitAuthorizationResponse(ctx, *received, *response)
+		require.NoError(tt, err)
+		assert.Equal(tt, "https://verifier.example.com/done", redirectURI)
+
+		otherRequest, err := verifier.CreateIDTokenRequest(RequestOptions{ResponseURI: server.URL})
+		require.NoError(tt, err)
+		_, err = verifier.VerifyIDToken(ctx, *otherRequest, response.IDToken)
+		assert.ErrorContains(tt, err, "id token nonce does not match the request's")
+
+		// ID tokens are requested along with presentations
+		credJWT, err := integrity.SignVerifiableCredentialJWT(issuerSigner, getTestHolderCredential(issuerSigner, holderSigner))
+		require.NoError(tt, err)
+		request = newRequest(tt, RequestOptions{IDToken: true})
+		assert.Equal(tt, "vp_token id_token", request.ResponseType)
+		response, err = getHolder(tt, string(credJWT)).CreateAuthorizationResponse(ctx, *request)
+		require.NoError(tt, err)
+		assert.NotEmpty(tt, response.IDToken)
+		_, err = holder.SubmitAuthorizationResponse(ctx, *request, *response)
+		require.NoError(tt, err)
+		response.IDToken = ""
+		_, err = holder.SubmitAuthorizationResponse(ctx, *request, *response)
+		assert.ErrorContains(tt, err, "status code: 400")
+	})
+
+	t.Run("no matching credential", func(tt *testing.T) {
+		holder := getHolder(tt)
+		_, err := holder.CreateAuthorizationResponse(ctx, *newRequest(tt, RequestOptions{}))
+		assert.ErrorContains(tt, err, "no credential fulfills input descriptor<name-descriptor>")
+	})
+
+	t.Run("fragment response", func(tt *testing.T) {
+		holder := getHolder(tt)
+		fragment := AuthorizationRequest{ResponseMode: FragmentResponseMode, RedirectURI: "https://verifier.example.com/callback"}
+		uri, err := holder.SubmitAuthorizationResponse(ctx, fragment, AuthorizationResponse{VPToken: "token", State: "state"})
+		require.NoError(tt, err)
+		assert.True(tt, strings.HasPrefix(uri, "https://verifier.example.com/callback#"))
+		assert.Contains(tt, uri, "state=state")
+	})
+}
+
+func getTestPresentationDefinition() exchange.PresentationDefinition {
+	return exchange.PresentationDefinition{
+		ID: "test-definition",
+		InputDescriptors: []exchange.InputDescriptor{{
+			ID: "name-descriptor",
+			Constraints: &exchange.Constraints{
+				Fields: []exchange.Field{{
+					ID:     "name",
+					Path:   []string{"$.credentialSubject.name", "$.vc.credentialSubject.name"},
+					Filter: &exchange.Filter{Type: "string", Const: "Alice"},
+				}},
+			},
+		}},
+	}
+}
+
+// getTestSigner returns the signer of a new did:key, whose KID is the DID's verification method
+func getTestSigner(t *testing.T) jwx.Signer {
+	privateKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privateKey)
+	require.NoError(t, err)
+	return *signer
+}
+
+// getTestHolderCredential returns a credential issued to the holder, whose subject fulfills the test presentation
+// definition
+func getTestHolderCredential(issuer, holder jwx.Signer) credential.VerifiableCredential {
+	return credential.VerifiableCredential{
+		Context:           []any{credential.VerifiableCredentialsLinkedDataContext},
+		ID:                "urn:uuid:" + uuid.NewString(),
+		Type:              []string{credential.VerifiableCredentialType},
+		Issuer:            issuer.ID,
+		IssuanceDate:      util.GetRFC3339Timestamp(),
+		CredentialSubject: credential.CredentialSubject{"id": holder.ID, "name": "Alice", "degree": "BSc"},
+	}
+}
+
+// getTestVPToken returns the vp_token of a JWT presentation by the holder of a JWT credential issued to them, bound to
+// a nonce and client ID, and its presentation submission
+func getTestVPToken(t *testing.T, issuer, holder jwx.Signer, def exchange.PresentationDefinition, nonce, clientID string) (string, *exchange.PresentationSubmission) {
+	cred := credential.VerifiableCredential{
+		Context:           []any{credential.VerifiableCredentialsLinkedDataContext},
+		ID:                "test-credential",
+		Type:              []string{credential.VerifiableCredentialType},
+		Issuer:            issuer.ID,
+		IssuanceDate:      util.GetRFC3339Timestamp(),
+		CredentialSubject: credential.CredentialSubject{"id": holder.ID, "name": "Alice"},
+	}
+	credJWT, err := integrity.SignVerifiableCredentialJWT(issuer, cred)
+	require.NoError(t, err)
+	claims, err := exchange.NormalizePresentationClaims([]exchange.PresentationClaim{{
+		Token:                         util.StringPtr(string(credJWT)),
+		JWTFormat:                     exchange.JWTVC.Ptr(),
+		SignatureAlgorithmOrProofType: holder.ALG,
+	}})
+	require.NoError(t, err)
+	vp, err := exchange.BuildPresentationSubmissionVP(holder.ID, def, claims)
+	require.NoError(t, err)
+	vpJWT, err := integrity.SignVerifiablePresentationJWT(holder, &integrity.JWTVVPParameters{Challenge: nonce, Domain: clientID}, *vp)
+	require.NoError(t, err)
+
+	// the submission of the vp_token nests that embedded in the presentation
+	var embedded exchange.PresentationSubmission
+	embeddedJSON, err := json.Marshal(vp.PresentationSubmission)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(embeddedJSON, &embedded))
+	submission := exchange.PresentationSubmission{ID: "test-submission", DefinitionID: def.ID}
+	for _, d := range embedded.DescriptorMap {
+		nested := d
+		nested.Path = "$." + integrity.VPJWTProperty + strings.TrimPrefix(d.Path, "$")
+		submission.DescriptorMap = append(submission.DescriptorMap, exchange.SubmissionDescriptor{
+			ID:         d.ID,
+			Format:     exchange.JWTVP.String(),
+			Path:       "$",
+			PathNested: &nested,
+		})
+	}
+	return string(vpJWT), &submission
+}
+
+// arraySubmission returns the submission of a vp_token of a single presentation as that of an array of it
+func arraySubmission(submission *exchange.PresentationSubmission) *exchange.PresentationSubmission {
+	array := *submission
+	array.DescriptorMap = nil
+	for _, d := range submission.DescriptorMap {
+		d.Path = "$[0]"
+		array.DescriptorMap = append(array.DescriptorMap, d)
+	}
+	return &array
+}
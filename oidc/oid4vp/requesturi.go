@@ -0,0 +1,206 @@
+package oid4vp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/oidc/par"
+)
+
+// Request objects are passed to wallets by reference in the request_uri parameter, from which wallets fetch them with
+// a GET, or by posting a nonce the verifier binds the request object to, for the post request URI method. Wallets
+// use the parameters of the request object alone, ignoring those of the URI, such that no parameter of a signed
+// request is downgraded by one which is not signed.
+// https://openid.net/specs/openid-4-verifiable-presentations-1_0.html#name-passing-authorization-reque
+// https://www.rfc-editor.org/rfc/rfc9101.html#section-5.2
+
+const (
+	// RequestURIMethodGet and RequestURIMethodPost are how wallets fetch request objects from their request URI
+	RequestURIMethodGet  string = "get"
+	RequestURIMethodPost string = "post"
+
+	// defaultRequestObjectLifetime bounds how long request objects fetched with a GET are cached
+	defaultRequestObjectLifetime = 5 * time.Minute
+)
+
+// RequestObjectCache caches the verified requests of request objects fetched from request URIs, such that a request
+// URI scanned again is not fetched again
+type RequestObjectCache interface {
+	// Get returns the request fetched from a request URI, if it is cached and has not expired
+	Get(requestURI string) (*AuthorizationRequest, bool)
+	// Put caches the request fetched from a request URI until it expires
+	Put(requestURI string, request AuthorizationRequest, expiresAt time.Time)
+}
+
+// MemoryRequestObjectCache is a RequestObjectCache holding requests in memory
+type MemoryRequestObjectCache struct {
+	mu       sync.Mutex
+	requests map[string]cachedRequest
+}
+
+type cachedRequest struct {
+	request   AuthorizationRequest
+	expiresAt time.Time
+}
+
+var _ RequestObjectCache = (*MemoryRequestObjectCache)(nil)
+
+// NewMemoryRequestObjectCache returns an empty MemoryRequestObjectCache
+func NewMemoryRequestObjectCache() *MemoryRequestObjectCache {
+	return &MemoryRequestObjectCache{requests: make(map[string]cachedRequest)}
+}
+
+// Get returns a copy of the request fetched from a request URI, if it has not expired
+func (c *MemoryRequestObjectCache) Get(requestURI string) (*AuthorizationRequest, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.requests[requestURI]
+	if !ok || time.Now().After(cached.expiresAt) {
+		return nil, false
+	}
+	request := cached.request
+	return &request, true
+}
+
+// Put caches the request fetched from a request URI until it expires, removing the requests which expired
+func (c *MemoryRequestObjectCache) Put(requestURI string, request AuthorizationRequest, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for cachedURI, cached := range c.requests {
+		if now.After(cached.expiresAt) {
+			delete(c.requests, cachedURI)
+		}
+	}
+	c.requests[requestURI] = cachedRequest{request: request, expiresAt: expiresAt}
+}
+
+// SetRequestObjectCache sets the cache of the requests the holder fetches from request URIs, which are held in
+// memory by default
+func (h *Holder) SetRequestObjectCache(cache RequestObjectCache) {
+	h.requestObjects = cache
+}
+
+// SetPushedRequests sets the pushed authorization request endpoint of the wallet, whose pushed requests the holder
+// redeems for the request URIs of pushed requests
+func (h *Holder) SetPushedRequests(server *par.Server) {
+	h.pushedRequests = server
+}
+
+// GetRequestObject fetches the signed request object of the given client from a request URI with the request URI
+// method, which is get if it is empty, and returns its verified request. The request URI must be https, and its
+// response must be a request object signed by a key of the client's DID. Request objects fetched with the post
+// method must be bound to the nonce the holder posts, and are not cached; those fetched with a GET are cached until
+// they expire, for at most 5 minutes. Request URIs of requests pushed to the wallet are redeemed from its pushed
+// authorization request endpoint, once.
+func (h *Holder) GetRequestObject(ctx context.Context, clientID, requestURI, method string) (*AuthorizationRequest, error) {
+	if strings.HasPrefix(requestURI, par.RequestURIPrefix) {
+		return h.getPushedRequest(ctx, clientID, requestURI)
+	}
+	parsed, err := url.Parse(requestURI)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing request uri")
+	}
+	if parsed.Scheme != "https" {
+		return nil, fmt.Errorf("request uri<%s> must be https", requestURI)
+	}
+
+	var walletNonce string
+	switch method {
+	case "", RequestURIMethodGet:
+		if cached, ok := h.requestObjects.Get(requestURI); ok {
+			if cached.ClientID != clientID {
+				return nil, fmt.Errorf("request uri<%s> is not of client<%s>", requestURI, clientID)
+			}
+			return cached, nil
+		}
+	case RequestURIMethodPost:
+		if walletNonce, err = randomToken(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("request uri method<%s> is not supported", method)
+	}
+
+	requestObject, err := h.fetchRequestObject(ctx, requestURI, walletNonce)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching request object<%s>", requestURI)
+	}
+	request, token, err := h.verifyRequestObject(ctx, clientID, requestObject)
+	if err != nil {
+		return nil, err
+	}
+	if walletNonce != "" {
+		if request.WalletNonce != walletNonce {
+			return nil, errors.New("request object is not bound to the wallet nonce")
+		}
+		return request, nil
+	}
+	expiresAt := time.Now().Add(defaultRequestObjectLifetime)
+	if exp := token.Expiration(); !exp.IsZero() && exp.Before(expiresAt) {
+		expiresAt = exp
+	}
+	h.requestObjects.Put(requestURI, *request, expiresAt)
+	return request, nil
+}
+
+// getPushedRequest redeems the pushed request of a request URI of the given client, and returns its verified request.
+// Pushed requests must be signed request objects.
+func (h *Holder) getPushedRequest(ctx context.Context, clientID, requestURI string) (*AuthorizationRequest, error) {
+	if h.pushedRequests == nil {
+		return nil, fmt.Errorf("request uri<%s> is of a pushed request, and the holder has no pushed requests", requestURI)
+	}
+	pushed, err := h.pushedRequests.GetRequest(clientID, requestURI)
+	if err != nil {
+		return nil, err
+	}
+	if pushed.RequestObject == "" {
+		return nil, fmt.Errorf("pushed request of client<%s> must be a signed request object", clientID)
+	}
+	return h.VerifyRequestObject(ctx, clientID, pushed.RequestObject)
+}
+
+// fetchRequestObject fetches the request object of a request URI, posting the wallet nonce if it is given, and
+// returns it if it is served as a signed request object
+func (h *Holder) fetchRequestObject(ctx context.Context, requestURI, walletNonce string) (string, error) {
+	var req *http.Request
+	var err error
+	if walletNonce == "" {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, requestURI, nil)
+	} else {
+		form := url.Values{walletNonceParameter: {walletNonce}}
+		if req, err = http.NewRequestWithContext(ctx, http.MethodPost, requestURI, strings.NewReader(form.Encode())); err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Accept", RequestObjectMediaType)
+	resp, err := h.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", errors.Wrap(err, "reading response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("status code: %d", resp.StatusCode)
+	}
+	// a request object of any other media type, such as an unsigned JSON request, is not accepted
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != RequestObjectMediaType {
+		return "", fmt.Errorf("content type<%s> must be %s", mediaType, RequestObjectMediaType)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
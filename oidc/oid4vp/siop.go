@@ -0,0 +1,96 @@
+package oid4vp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// Self-Issued OpenID Providers authenticate the end-user to verifiers with self-issued ID tokens, issued by the
+// holder's DID as its own subject
+// https://openid.net/specs/openid-connect-self-issued-v2-1_0.html#name-self-issued-id-token
+
+// idTokenLifetime is how long self-issued ID tokens are valid
+const idTokenLifetime = 10 * time.Minute
+
+// CreateIDTokenRequest creates a Self-Issued OpenID Provider request for a self-issued ID token alone, with a random
+// nonce and state, authenticating the holder by its DID
+func (v *Verifier) CreateIDTokenRequest(opts RequestOptions) (*AuthorizationRequest, error) {
+	if opts.PresentationDefinitionURI != "" {
+		return nil, errors.New("requests for an id token alone cannot have a presentation definition")
+	}
+	request, err := v.newAuthorizationRequest(IDTokenResponseType, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = request.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid authorization request")
+	}
+	return request, nil
+}
+
+// CreateIDToken returns a self-issued ID token for a request, whose issuer and subject are the holder's DID, bound to
+// the request's nonce and client ID
+func (h *Holder) CreateIDToken(request AuthorizationRequest) (string, error) {
+	idToken, err := h.signer.SignWithDefaults(map[string]any{
+		jwt.SubjectKey:    h.signer.ID,
+		jwt.AudienceKey:   request.ClientID,
+		jwt.ExpirationKey: time.Now().Add(idTokenLifetime).Unix(),
+		nonceParameter:    request.Nonce,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "signing id token")
+	}
+	return string(idToken), nil
+}
+
+// VerifyIDToken verifies the self-issued ID token of a response to a request, returning its subject, which is the
+// DID of the holder whose key signed it. The token must be bound to the request's nonce and the verifier's client
+// ID, and must not have expired.
+func (v *Verifier) VerifyIDToken(ctx context.Context, request AuthorizationRequest, idToken string) (string, error) {
+	if idToken == "" {
+		return "", errors.New("id token cannot be empty")
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(idToken))
+	if err != nil {
+		return "", errors.Wrap(err, "getting id token headers")
+	}
+	kid := headers.KeyID()
+	subject, _, _ := strings.Cut(kid, "#")
+	if !strings.HasPrefix(subject, "did:") {
+		return "", fmt.Errorf("id token kid<%s> is not a DID URL", kid)
+	}
+	key, err := resolution.ResolveKeyForDID(ctx, v.resolver, subject, kid)
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving id token key<%s>", kid)
+	}
+	verifier, err := jwx.NewJWXVerifier(subject, &kid, key)
+	if err != nil {
+		return "", errors.Wrapf(err, "constructing verifier for id token key<%s>", kid)
+	}
+	_, token, err := verifier.VerifyAndParse(idToken)
+	if err != nil {
+		return "", errors.Wrap(err, "verifying id token")
+	}
+	if token.Subject() != subject || token.Issuer() != subject {
+		return "", fmt.Errorf("id token must be issued by its subject, the DID of its key<%s>", kid)
+	}
+	if !util.Contains(v.ClientID(), token.Audience()) {
+		return "", errors.New("id token audience must be the verifier")
+	}
+	if nonce, _ := token.Get(nonceParameter); nonce != request.Nonce {
+		return "", errors.New("id token nonce does not match the request's")
+	}
+	if token.Expiration().IsZero() {
+		return "", errors.New("id token must expire")
+	}
+	return subject, nil
+}
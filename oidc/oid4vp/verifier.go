@@ -0,0 +1,399 @@
+package oid4vp
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/oidc/par"
+	"github.com/TBD54566975/ssi-sdk/oidc/qr"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// nonceSize is the size, in bytes, of the random nonces and states of authorization requests
+const nonceSize = 32
+
+// maxRequestSize bounds the size of request bodies the verifier's handlers read
+const maxRequestSize = 1 << 20
+
+// Verifier requests presentations from wallets as the client identified by its signer's DID, and verifies the
+// presentations of their responses
+type Verifier struct {
+	signer   jwx.Signer
+	resolver resolution.Resolver
+
+	encryptionKey    gocrypto.PrivateKey
+	encryptionKeyJWK *jwx.PublicKeyJWK
+}
+
+// NewVerifier returns a verifier identified by the DID of its signer, whose KID is the verification method request
+// objects are signed with. The resolver resolves the DIDs of the holders and issuers of presentations.
+func NewVerifier(signer jwx.Signer, resolver resolution.Resolver) (*Verifier, error) {
+	if !strings.HasPrefix(signer.ID, "did:") {
+		return nil, fmt.Errorf("signer id<%s> must be a DID", signer.ID)
+	}
+	if signer.KID == "" {
+		return nil, errors.New("signer kid cannot be empty")
+	}
+	if resolver == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	return &Verifier{signer: signer, resolver: resolver}, nil
+}
+
+// ClientID returns the client ID of the verifier, which is its DID
+func (v *Verifier) ClientID() string {
+	return v.signer.ID
+}
+
+// RequestOptions configures an authorization request
+type RequestOptions struct {
+	// ResponseMode is the response mode of the request, which is direct_post if a response URI is given and fragment
+	// otherwise
+	ResponseMode ResponseMode
+	ResponseURI  string
+	RedirectURI  string
+	// PresentationDefinitionURI passes the presentation definition by reference rather than by value
+	PresentationDefinitionURI string
+	ClientMetadata            *ClientMetadata
+	// IDToken asks for a self-issued ID token of the holder along with its presentations
+	IDToken bool
+}
+
+// CreateAuthorizationRequest creates a request for presentations fulfilling a presentation definition, with a random
+// nonce and state, and for a self-issued ID token if the options ask for one. Requests of JWT response modes ask for
+// responses encrypted to the verifier's response encryption key, if it has one.
+func (v *Verifier) CreateAuthorizationRequest(def exchange.PresentationDefinition, opts RequestOptions) (*AuthorizationRequest, error) {
+	if err := def.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid presentation definition")
+	}
+	responseType := VPTokenResponseType
+	if opts.IDToken {
+		responseType += " " + IDTokenResponseType
+	}
+	request, err := v.newAuthorizationRequest(responseType, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.PresentationDefinitionURI != "" {
+		request.PresentationDefinitionURI = opts.PresentationDefinitionURI
+	} else {
+		request.PresentationDefinition = &def
+	}
+	if err = request.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid authorization request")
+	}
+	return request, nil
+}
+
+// newAuthorizationRequest returns a request of a response type, with a random nonce and state
+func (v *Verifier) newAuthorizationRequest(responseType string, opts RequestOptions) (*AuthorizationRequest, error) {
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	request := AuthorizationRequest{
+		ClientID:       v.ClientID(),
+		ClientIDScheme: DIDClientIDScheme,
+		ResponseType:   responseType,
+		ResponseMode:   opts.ResponseMode,
+		ResponseURI:    opts.ResponseURI,
+		RedirectURI:    opts.RedirectURI,
+		Nonce:          nonce,
+		State:          state,
+		ClientMetadata: opts.ClientMetadata,
+	}
+	if request.ResponseMode == "" {
+		request.ResponseMode = FragmentResponseMode
+		if request.ResponseURI != "" {
+			request.ResponseMode = DirectPostResponseMode
+		}
+	}
+	request.ClientMetadata = v.responseEncryptionMetadata(request)
+	return &request, nil
+}
+
+// SignRequestObject signs a request as a request object, a JWT issued by the verifier's DID, to be passed to wallets
+// by reference or as the `request` parameter
+// https://www.rfc-editor.org/rfc/rfc9101.html
+func (v *Verifier) SignRequestObject(request AuthorizationRequest) ([]byte, error) {
+	if request.ClientID != v.ClientID() {
+		return nil, fmt.Errorf("request client id<%s> is not the verifier's", request.ClientID)
+	}
+	claims, err := util.ToJSONMap(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting request claims")
+	}
+	t := jwt.New()
+	for k, val := range claims {
+		if err = t.Set(k, val); err != nil {
+			return nil, errors.Wrapf(err, "setting %s", k)
+		}
+	}
+	if err = t.Set(jwt.IssuerKey, v.ClientID()); err != nil {
+		return nil, errors.Wrap(err, "setting iss")
+	}
+	if err = t.Set(jwt.AudienceKey, SelfIssuedAudience); err != nil {
+		return nil, errors.Wrap(err, "setting aud")
+	}
+	if err = t.Set(jwt.IssuedAtKey, time.Now()); err != nil {
+		return nil, errors.Wrap(err, "setting iat")
+	}
+
+	hdrs := jws.NewHeaders()
+	if err = hdrs.Set(jws.KeyIDKey, v.signer.KID); err != nil {
+		return nil, errors.Wrap(err, "setting kid")
+	}
+	if err = hdrs.Set(jws.TypeKey, RequestObjectJWTType); err != nil {
+		return nil, errors.Wrap(err, "setting typ")
+	}
+	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+	alg := v.signer.ALG
+	if alg == "Ed25519" {
+		alg = jwa.EdDSA.String()
+	}
+	signed, err := jwt.Sign(t, jwt.WithKey(jwa.SignatureAlgorithm(alg), v.signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	if err != nil {
+		return nil, errors.Wrap(err, "signing request object")
+	}
+	return signed, nil
+}
+
+// SignedRequestURI returns the URI passing a request to a wallet as a signed request object, by value
+func (v *Verifier) SignedRequestURI(request AuthorizationRequest) (string, error) {
+	requestObject, err := v.SignRequestObject(request)
+	if err != nil {
+		return "", err
+	}
+	return RequestObjectURI(request.ClientID, string(requestObject)), nil
+}
+
+// RequestObjectURI returns the URI passing a signed request object of the given client to a wallet by value
+func RequestObjectURI(clientID, requestObject string) string {
+	return AuthorizationRequestScheme + "?" + url.Values{
+		clientIDParameter: {clientID},
+		requestParameter:  {requestObject},
+	}.Encode()
+}
+
+// PushAuthorizationRequest pushes a request as a signed request object to the pushed authorization request endpoint
+// of a wallet, whose issuer identifier is the audience, authenticating the verifier with a client assertion signed by
+// its DID, and returns the URI passing the request to the wallet by the request URI of the pushed request
+// https://www.rfc-editor.org/rfc/rfc9126.html
+func (v *Verifier) PushAuthorizationRequest(ctx context.Context, endpoint, audience string, request AuthorizationRequest) (string, error) {
+	requestObject, err := v.SignRequestObject(request)
+	if err != nil {
+		return "", err
+	}
+	client, err := par.NewClient(v.signer)
+	if err != nil {
+		return "", err
+	}
+	response, err := client.Push(ctx, endpoint, audience, url.Values{requestParameter: {string(requestObject)}})
+	if err != nil {
+		return "", errors.Wrap(err, "pushing authorization request")
+	}
+	return RequestObjectReferenceURI(request.ClientID, response.RequestURI), nil
+}
+
+// RequestObjectHandler returns a handler serving the signed request objects of requests passed by reference, at the
+// request URIs the handler is routed to. The request of each HTTP request is that getRequest returns, which may be
+// nil if it has none. Wallets fetching request objects with the post request URI method are sent the request bound
+// to the wallet nonce they post.
+func (v *Verifier) RequestObjectHandler(getRequest func(r *http.Request) (*AuthorizationRequest, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		request, err := getRequest(r)
+		if err != nil || request == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPost {
+			r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+			if err = r.ParseForm(); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			bound := *request
+			bound.WalletNonce = r.PostForm.Get(walletNonceParameter)
+			request = &bound
+		}
+		requestObject, err := v.SignRequestObject(*request)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", RequestObjectMediaType)
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(requestObject)
+	})
+}
+
+// RequestQRCode returns a QR code passing a request to a wallet as a signed request object, by value or, if the
+// request does not fit in a QR code of the options' maximum version, by reference to the given request URI. Whether
+// the request is passed by reference is returned, in which case the verifier must serve the request's signed request
+// object at the request URI.
+func (v *Verifier) RequestQRCode(request AuthorizationRequest, requestURI string, opts qr.Options) (*qr.Code, bool, error) {
+	uri, err := v.SignedRequestURI(request)
+	if err != nil {
+		return nil, false, err
+	}
+	byReference := !qr.Fits(uri, opts)
+	if byReference {
+		if requestURI == "" {
+			return nil, false, errors.New("request does not fit in a QR code, and has no request uri")
+		}
+		uri = RequestObjectReferenceURI(request.ClientID, requestURI)
+	}
+	code, err := qr.Encode(uri, opts)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "encoding QR code")
+	}
+	return code, byReference, nil
+}
+
+// VerifyAuthorizationResponse verifies the response to an authorization request: the response's state is the
+// request's, the signature of each presentation, and of the credentials it contains, is verified, presentations are
+// bound to the request's nonce and the verifier's client ID, and the presentation submission fulfills the request's
+// presentation definition, which must be given if the request passed it by reference. The verified data of each
+// input descriptor is returned. Options are applied when verifying the signatures of presentations and credentials,
+// and when verifying the presentation submission. The self-issued ID token of requests for one is verified as
+// VerifyIDToken does; requests for an ID token alone have no verified data.
+func (v *Verifier) VerifyAuthorizationResponse(ctx context.Context, request AuthorizationRequest, response AuthorizationResponse, opts VerificationOptions) ([]exchange.VerifiedSubmissionData, error) {
+	if request.State != "" && response.State != request.State {
+		return nil, errors.New("response state does not match the request's")
+	}
+	if request.RequestsIDToken() {
+		if _, err := v.VerifyIDToken(ctx, request, response.IDToken); err != nil {
+			return nil, err
+		}
+		if !request.RequestsVPToken() {
+			return nil, nil
+		}
+	}
+	if response.PresentationSubmission == nil {
+		return nil, errors.New("presentation submission cannot be empty")
+	}
+	def := request.PresentationDefinition
+	if opts.PresentationDefinition != nil {
+		def = opts.PresentationDefinition
+	}
+	if def == nil {
+		return nil, errors.New("presentation definition of the request cannot be empty")
+	}
+
+	presentations, submission, err := vpTokenPresentations(response.VPToken, *response.PresentationSubmission)
+	if err != nil {
+		return nil, err
+	}
+	signatureOpts := append([]integrity.VerificationOption{
+		integrity.WithChallenge(request.Nonce),
+		integrity.WithDomain(request.ClientID),
+	}, opts.SignatureOptions...)
+	for i, presentation := range presentations {
+		if err = v.verifyPresentation(ctx, request, presentation, signatureOpts); err != nil {
+			return nil, errors.Wrapf(err, "verifying presentation<%d>", i)
+		}
+	}
+	verified, err := exchange.VerifyMultiPresentationSubmission(*def, *submission, presentations, opts.SubmissionOptions...)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying presentation submission")
+	}
+	return verified, nil
+}
+
+// VerificationOptions configures the verification of an authorization response
+type VerificationOptions struct {
+	// PresentationDefinition is that of requests passing it by reference
+	PresentationDefinition *exchange.PresentationDefinition
+	// SignatureOptions are applied when verifying the signatures of presentations and the credentials they contain
+	SignatureOptions []integrity.VerificationOption
+	// SubmissionOptions are applied when verifying the presentation submission, such as status checks
+	SubmissionOptions []exchange.VerificationOption
+}
+
+// verifyPresentation verifies the signature of a presentation of a vp_token, and of the credentials it contains. An
+// SD-JWT credential submitted on its own is verified with its key binding JWT, which binds it to the request.
+func (v *Verifier) verifyPresentation(ctx context.Context, request AuthorizationRequest, presentation any, opts []integrity.VerificationOption) error {
+	if token, ok := presentation.(string); ok && integrity.IsVerifiableCredentialSDJWT(token) {
+		verified, err := integrity.VerifyCredentialSignature(ctx, token, v.resolver, opts...)
+		if err != nil {
+			return err
+		}
+		if !verified {
+			return errors.New("credential failed signature validation")
+		}
+		return integrity.VerifySDJWTKeyBinding(ctx, token, v.resolver, request.ClientID, request.Nonce)
+	}
+	verified, err := integrity.VerifyPresentationSignature(ctx, presentation, v.resolver, opts...)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return errors.New("presentation failed signature validation")
+	}
+	return nil
+}
+
+// vpTokenPresentations returns the presentations of a vp_token, which is a presentation or an array of presentations,
+// and the presentation submission with paths relative to the array of presentations
+func vpTokenPresentations(vpToken any, submission exchange.PresentationSubmission) ([]any, *exchange.PresentationSubmission, error) {
+	switch token := vpToken.(type) {
+	case nil:
+		return nil, nil, errors.New("vp token cannot be empty")
+	case []any:
+		if len(token) == 0 {
+			return nil, nil, errors.New("vp token cannot be empty")
+		}
+		return token, &submission, nil
+	case []string:
+		presentations := make([]any, 0, len(token))
+		for _, presentation := range token {
+			presentations = append(presentations, presentation)
+		}
+		return vpTokenPresentations(presentations, submission)
+	}
+
+	// a single presentation is selected by the path $, and is the first of the array
+	arraySubmission := submission
+	arraySubmission.DescriptorMap = make([]exchange.SubmissionDescriptor, 0, len(submission.DescriptorMap))
+	for _, d := range submission.DescriptorMap {
+		if !strings.HasPrefix(d.Path, "$") {
+			return nil, nil, fmt.Errorf("submission descriptor<%s> path<%s> must start with $", d.ID, d.Path)
+		}
+		d.Path = "$[0]" + strings.TrimPrefix(d.Path, "$")
+		arraySubmission.DescriptorMap = append(arraySubmission.DescriptorMap, d)
+	}
+	return []any{vpToken}, &arraySubmission, nil
+}
+
+// randomToken returns a random, URL safe, nonce or state
+func randomToken() (string, error) {
+	token := make([]byte, nonceSize)
+	if _, err := rand.Read(token); err != nil {
+		return "", errors.Wrap(err, "generating random token")
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
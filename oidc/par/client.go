@@ -0,0 +1,94 @@
+package par
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+// maxResponseSize bounds the size of the responses the client reads
+const maxResponseSize = 1 << 20
+
+// Client pushes the authorization requests of a client identified by the DID of its signer
+type Client struct {
+	*http.Client
+	signer jwx.Signer
+}
+
+// NewClient returns a client pushing requests of the client of the signer, whose ID is the client's DID, using the
+// default HTTP client
+func NewClient(signer jwx.Signer) (*Client, error) {
+	if !strings.HasPrefix(signer.ID, "did:") {
+		return nil, errors.Errorf("client id<%s> must be a DID", signer.ID)
+	}
+	return &Client{Client: http.DefaultClient, signer: signer}, nil
+}
+
+// Push pushes the parameters of an authorization request to the pushed authorization request endpoint of an
+// authorization server, whose issuer identifier is the audience, authenticating the client with a client assertion.
+// The parameters may carry a signed request object in the request parameter. Error responses are returned as *Error.
+func (c *Client) Push(ctx context.Context, endpoint, audience string, params url.Values) (*Response, error) {
+	assertion, err := NewClientAssertion(c.signer, audience)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	for key, values := range params {
+		form[key] = values
+	}
+	form.Set(ClientIDParameter, c.signer.ID)
+	form.Set(ClientAssertionTypeParameter, JWTBearerClientAssertionType)
+	form.Set(ClientAssertionParameter, assertion)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading response")
+	}
+	if resp.StatusCode != http.StatusCreated {
+		var parErr Error
+		if err = json.Unmarshal(body, &parErr); err == nil && parErr.Code != "" {
+			return nil, &parErr
+		}
+		return nil, errors.Errorf("status code: %d", resp.StatusCode)
+	}
+	var response Response
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling response")
+	}
+	if !strings.HasPrefix(response.RequestURI, RequestURIPrefix) {
+		return nil, errors.Errorf("request uri<%s> is not of a pushed request", response.RequestURI)
+	}
+	return &response, nil
+}
+
+// AuthorizationURL returns the URL of an authorization endpoint passing the request URI of a pushed request of a
+// client, in place of the request's parameters
+func AuthorizationURL(authorizationEndpoint, clientID, requestURI string) (string, error) {
+	u, err := url.Parse(authorizationEndpoint)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing authorization endpoint")
+	}
+	query := u.Query()
+	query.Set(ClientIDParameter, clientID)
+	query.Set(RequestURIParameter, requestURI)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
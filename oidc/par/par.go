@@ -0,0 +1,151 @@
+package par
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// Pushed Authorization Requests (PAR) let clients push the parameters of an authorization request, or its signed
+// request object, to the authorization server directly, which returns a request URI the client passes to the
+// authorization endpoint instead. Clients identified by a DID authenticate with a client assertion signed by a key
+// of their DID.
+// https://www.rfc-editor.org/rfc/rfc9126.html
+// https://www.rfc-editor.org/rfc/rfc7523.html#section-2.2
+
+const (
+	// RequestURIPrefix is the prefix of the request URIs of pushed requests
+	RequestURIPrefix string = "urn:ietf:params:oauth:request_uri:"
+	// JWTBearerClientAssertionType is the type of client assertions which are JWTs signed by the client
+	JWTBearerClientAssertionType string = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	ClientIDParameter            string = "client_id"
+	RequestParameter             string = "request"
+	RequestURIParameter          string = "request_uri"
+	ClientAssertionParameter     string = "client_assertion"
+	ClientAssertionTypeParameter string = "client_assertion_type"
+
+	defaultRequestLifetime   = time.Minute
+	defaultAssertionLifetime = 5 * time.Minute
+	defaultLeeway            = time.Minute
+)
+
+// Response is the response of the pushed authorization request endpoint, with the request URI of the pushed request
+// https://www.rfc-editor.org/rfc/rfc9126.html#section-2.2
+type Response struct {
+	RequestURI string `json:"request_uri"`
+	ExpiresIn  int    `json:"expires_in"`
+}
+
+// ErrorCode is the code of an error response of the pushed authorization request endpoint
+type ErrorCode string
+
+const (
+	InvalidRequest       ErrorCode = "invalid_request"
+	InvalidClient        ErrorCode = "invalid_client"
+	InvalidRequestObject ErrorCode = "invalid_request_object"
+	ServerError          ErrorCode = "server_error"
+)
+
+// Error is an error response of the pushed authorization request endpoint
+// https://www.rfc-editor.org/rfc/rfc9126.html#section-2.3
+type Error struct {
+	Code        ErrorCode `json:"error"`
+	Description string    `json:"error_description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return string(e.Code)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+func newError(code ErrorCode, format string, args ...any) *Error {
+	return &Error{Code: code, Description: fmt.Sprintf(format, args...)}
+}
+
+// NewClientAssertion returns a client assertion authenticating the client of the signer, whose ID is the client's
+// DID, to an authorization server, which is the audience. The signer's KID identifies the key of the DID which signs
+// it.
+func NewClientAssertion(signer jwx.Signer, audience string) (string, error) {
+	if !strings.HasPrefix(signer.ID, "did:") {
+		return "", errors.Errorf("client id<%s> must be a DID", signer.ID)
+	}
+	if !strings.HasPrefix(signer.KID, signer.ID+"#") {
+		return "", errors.Errorf("signer kid<%s> is not a key of client<%s>", signer.KID, signer.ID)
+	}
+	if audience == "" {
+		return "", errors.New("audience cannot be empty")
+	}
+	assertion, err := signer.SignWithDefaults(map[string]any{
+		jwt.SubjectKey:    signer.ID,
+		jwt.AudienceKey:   audience,
+		jwt.JwtIDKey:      uuid.NewString(),
+		jwt.ExpirationKey: time.Now().Add(defaultAssertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "signing client assertion")
+	}
+	return string(assertion), nil
+}
+
+// verifyClientJWT verifies a JWT of a client, signed by a key of the client's DID identified by its kid, and
+// returns its token
+func verifyClientJWT(ctx context.Context, resolver resolution.Resolver, clientID, token string) (jwt.Token, error) {
+	headers, err := jwx.GetJWSHeaders([]byte(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting headers")
+	}
+	kid := headers.KeyID()
+	if !strings.HasPrefix(kid, clientID+"#") {
+		return nil, errors.Errorf("kid<%s> is not a key of client<%s>", kid, clientID)
+	}
+	key, err := resolution.ResolveKeyForDID(ctx, resolver, clientID, kid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving key<%s>", kid)
+	}
+	verifier, err := jwx.NewJWXVerifier(clientID, &kid, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "constructing verifier for key<%s>", kid)
+	}
+	_, parsed, err := verifier.VerifyAndParse(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying")
+	}
+	if parsed.Issuer() != "" && parsed.Issuer() != clientID {
+		return nil, errors.Errorf("issuer<%s> is not client<%s>", parsed.Issuer(), clientID)
+	}
+	return parsed, nil
+}
+
+// verifyClientAssertion verifies a client assertion of a client to the audience: signed by a key of the client's
+// DID, issued by the client about itself, and expiring
+func verifyClientAssertion(ctx context.Context, resolver resolution.Resolver, clientID, audience, assertion string, leeway time.Duration) (jwt.Token, error) {
+	token, err := verifyClientJWT(ctx, resolver, clientID, assertion)
+	if err != nil {
+		return nil, err
+	}
+	if token.Issuer() != clientID || token.Subject() != clientID {
+		return nil, errors.Errorf("client assertion must be issued by client<%s> about itself", clientID)
+	}
+	if !util.Contains(audience, token.Audience()) {
+		return nil, errors.Errorf("client assertion audience must be <%s>", audience)
+	}
+	if token.JwtID() == "" || token.Expiration().IsZero() {
+		return nil, errors.New("client assertion must have a jti, and expire")
+	}
+	if token.IssuedAt().After(time.Now().Add(leeway)) {
+		return nil, errors.New("client assertion is issued in the future")
+	}
+	return token, nil
+}
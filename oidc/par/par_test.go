@@ -0,0 +1,165 @@
+package par
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestPushedAuthorizationRequests(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	httpServer := httptest.NewServer(nil)
+	defer httpServer.Close()
+	server, err := NewServer(httpServer.URL, resolver, ServerOptions{})
+	require.NoError(t, err)
+	httpServer.Config.Handler = server.Handler()
+	clientSigner := getTestSigner(t)
+	client, err := NewClient(clientSigner)
+	require.NoError(t, err)
+
+	t.Run("push and redeem a request", func(tt *testing.T) {
+		params := url.Values{"response_type": {"code"}, "scope": {"openid"}}
+		response, err := client.Push(ctx, httpServer.URL, httpServer.URL, params)
+		require.NoError(tt, err)
+		assert.Equal(tt, int(defaultRequestLifetime.Seconds()), response.ExpiresIn)
+
+		authorizationURL, err := AuthorizationURL("https://as.example.com/authorize?prompt=login", clientSigner.ID, response.RequestURI)
+		require.NoError(tt, err)
+		parsed, err := url.Parse(authorizationURL)
+		require.NoError(tt, err)
+		assert.Equal(tt, "login", parsed.Query().Get("prompt"))
+		assert.Equal(tt, response.RequestURI, parsed.Query().Get(RequestURIParameter))
+
+		// requests are redeemed once, by their client, without the parameters authenticating it
+		_, err = server.GetRequest("did:example:other", response.RequestURI)
+		assert.ErrorContains(tt, err, "is not of client<did:example:other>")
+		pushed, err := server.GetRequest(clientSigner.ID, response.RequestURI)
+		require.NoError(tt, err)
+		assert.Equal(tt, "openid", pushed.Parameters.Get("scope"))
+		assert.Equal(tt, clientSigner.ID, pushed.Parameters.Get(ClientIDParameter))
+		assert.False(tt, pushed.Parameters.Has(ClientAssertionParameter))
+		assert.Empty(tt, pushed.RequestObject)
+		_, err = server.GetRequest(clientSigner.ID, response.RequestURI)
+		assert.ErrorContains(tt, err, "is not valid")
+		_, err = server.GetRequest(clientSigner.ID, "https://as.example.com/request")
+		assert.ErrorContains(tt, err, "is not of a pushed request")
+	})
+
+	t.Run("request objects", func(tt *testing.T) {
+		requestObject, err := clientSigner.SignWithDefaults(map[string]any{ClientIDParameter: clientSigner.ID, "scope": "openid"})
+		require.NoError(tt, err)
+		response, err := client.Push(ctx, httpServer.URL, httpServer.URL, url.Values{RequestParameter: {string(requestObject)}})
+		require.NoError(tt, err)
+		pushed, err := server.GetRequest(clientSigner.ID, response.RequestURI)
+		require.NoError(tt, err)
+		assert.Equal(tt, string(requestObject), pushed.RequestObject)
+
+		// request objects must be of the client, and signed by it
+		otherSigner := getTestSigner(tt)
+		otherObject, err := otherSigner.SignWithDefaults(map[string]any{ClientIDParameter: clientSigner.ID})
+		require.NoError(tt, err)
+		_, err = client.Push(ctx, httpServer.URL, httpServer.URL, url.Values{RequestParameter: {string(otherObject)}})
+		assertErrorCode(tt, err, InvalidRequestObject)
+		otherObject, err = clientSigner.SignWithDefaults(map[string]any{ClientIDParameter: otherSigner.ID})
+		require.NoError(tt, err)
+		_, err = client.Push(ctx, httpServer.URL, httpServer.URL, url.Values{RequestParameter: {string(otherObject)}})
+		assertErrorCode(tt, err, InvalidRequestObject)
+	})
+
+	t.Run("client authentication", func(tt *testing.T) {
+		_, err := client.Push(ctx, httpServer.URL, "https://other.example.com", url.Values{})
+		assertErrorCode(tt, err, InvalidClient)
+
+		// client assertions are used once
+		assertion, err := NewClientAssertion(clientSigner, httpServer.URL)
+		require.NoError(tt, err)
+		form := url.Values{
+			ClientIDParameter:            {clientSigner.ID},
+			ClientAssertionTypeParameter: {JWTBearerClientAssertionType},
+			ClientAssertionParameter:     {assertion},
+		}
+		_, err = server.Push(ctx, form)
+		require.NoError(tt, err)
+		_, err = server.Push(ctx, form)
+		assertErrorCode(tt, err, InvalidClient)
+
+		// client assertions are signed by a key of the client's DID, about the client
+		otherSigner := getTestSigner(tt)
+		otherAssertion, err := NewClientAssertion(otherSigner, httpServer.URL)
+		require.NoError(tt, err)
+		form.Set(ClientAssertionParameter, otherAssertion)
+		_, err = server.Push(ctx, form)
+		assertErrorCode(tt, err, InvalidClient)
+		unexpiring, err := clientSigner.SignWithDefaults(map[string]any{
+			jwt.SubjectKey:  clientSigner.ID,
+			jwt.AudienceKey: httpServer.URL,
+			jwt.JwtIDKey:    uuid.NewString(),
+		})
+		require.NoError(tt, err)
+		form.Set(ClientAssertionParameter, string(unexpiring))
+		_, err = server.Push(ctx, form)
+		assertErrorCode(tt, err, InvalidClient)
+
+		// clients are identified by a DID
+		_, privateKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		kid := "key-1"
+		jwkSigner, err := jwx.NewJWXSigner("client", &kid, privateKey)
+		require.NoError(tt, err)
+		_, err = NewClient(*jwkSigner)
+		assert.ErrorContains(tt, err, "client id<client> must be a DID")
+		_, err = server.Push(ctx, url.Values{ClientIDParameter: {"client"}})
+		assertErrorCode(tt, err, InvalidClient)
+		_, err = server.Push(ctx, url.Values{})
+		assertErrorCode(tt, err, InvalidRequest)
+	})
+
+	t.Run("expired requests", func(tt *testing.T) {
+		expiring, err := NewServer(httpServer.URL, resolver, ServerOptions{RequestLifetime: time.Nanosecond})
+		require.NoError(tt, err)
+		assertion, err := NewClientAssertion(clientSigner, httpServer.URL)
+		require.NoError(tt, err)
+		response, err := expiring.Push(ctx, url.Values{
+			ClientIDParameter:            {clientSigner.ID},
+			ClientAssertionTypeParameter: {JWTBearerClientAssertionType},
+			ClientAssertionParameter:     {assertion},
+		})
+		require.NoError(tt, err)
+		time.Sleep(time.Millisecond)
+		_, err = expiring.GetRequest(clientSigner.ID, response.RequestURI)
+		assert.ErrorContains(tt, err, "is not valid")
+	})
+}
+
+func assertErrorCode(t *testing.T, err error, code ErrorCode) {
+	var parErr *Error
+	require.ErrorAs(t, err, &parErr)
+	assert.Equal(t, code, parErr.Code)
+}
+
+// getTestSigner returns the signer of a new did:key, identified by its verification method
+func getTestSigner(t *testing.T) jwx.Signer {
+	privateKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privateKey)
+	require.NoError(t, err)
+	return *signer
+}
@@ -0,0 +1,218 @@
+package par
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/oidc/dpop"
+)
+
+const (
+	// maxRequestSize bounds the size of the pushed requests the handler reads
+	maxRequestSize = 1 << 20
+	// requestURISize is the size, in bytes, of the random part of request URIs
+	requestURISize = 32
+)
+
+// PushedRequest is an authorization request pushed by an authenticated client, until it is redeemed at the
+// authorization endpoint by its request URI
+type PushedRequest struct {
+	ClientID string
+	// Parameters are the parameters of the request, without those authenticating the client
+	Parameters url.Values
+	// RequestObject is the signed request object of the request, if it was pushed as one, which is signed by a key
+	// of the client's DID
+	RequestObject string
+	ExpiresAt     time.Time
+}
+
+// ServerOptions configures a Server
+type ServerOptions struct {
+	// RequestLifetime is how long pushed requests can be redeemed, and defaults to a minute
+	RequestLifetime time.Duration
+	// Leeway tolerates clock skew in the iat of client assertions, and defaults to a minute
+	Leeway time.Duration
+	// ReplayCache rejects client assertions which were already used, and holds them in memory if it is nil
+	ReplayCache dpop.ReplayCache
+}
+
+// Server is the pushed authorization request endpoint of an authorization server, holding the requests clients
+// push until the authorization endpoint redeems them. Clients are identified by their DID, and authenticate with a
+// client assertion signed by a key of it.
+type Server struct {
+	audience string
+	resolver resolution.Resolver
+	opts     ServerOptions
+
+	mu       sync.Mutex
+	requests map[string]PushedRequest
+}
+
+// NewServer returns the pushed authorization request endpoint of an authorization server, whose issuer identifier is
+// the audience of client assertions, resolving the DIDs of clients with the resolver
+func NewServer(audience string, resolver resolution.Resolver, opts ServerOptions) (*Server, error) {
+	if audience == "" {
+		return nil, errors.New("audience cannot be empty")
+	}
+	if resolver == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	if opts.RequestLifetime == 0 {
+		opts.RequestLifetime = defaultRequestLifetime
+	}
+	if opts.Leeway == 0 {
+		opts.Leeway = defaultLeeway
+	}
+	if opts.ReplayCache == nil {
+		opts.ReplayCache = dpop.NewMemoryReplayCache()
+	}
+	return &Server{audience: audience, resolver: resolver, opts: opts, requests: make(map[string]PushedRequest)}, nil
+}
+
+// Push authenticates the client of a pushed authorization request's form, and holds the request, returning its
+// request URI. Requests pushed as a signed request object must be signed by a key of the client's DID, and be of
+// the client. Errors are of type *Error.
+func (s *Server) Push(ctx context.Context, form url.Values) (*Response, error) {
+	clientID := form.Get(ClientIDParameter)
+	if clientID == "" {
+		return nil, newError(InvalidRequest, "client_id is required")
+	}
+	if form.Has(RequestURIParameter) {
+		return nil, newError(InvalidRequest, "request_uri cannot be pushed")
+	}
+	if oidErr := s.authenticateClient(ctx, clientID, form); oidErr != nil {
+		return nil, oidErr
+	}
+
+	pushed := PushedRequest{ClientID: clientID, Parameters: url.Values{}, ExpiresAt: time.Now().Add(s.opts.RequestLifetime)}
+	for key, values := range form {
+		if key != ClientAssertionParameter && key != ClientAssertionTypeParameter {
+			pushed.Parameters[key] = values
+		}
+	}
+	if requestObject := form.Get(RequestParameter); requestObject != "" {
+		token, err := verifyClientJWT(ctx, s.resolver, clientID, requestObject)
+		if err != nil {
+			return nil, newError(InvalidRequestObject, "request object: %s", err.Error())
+		}
+		if requestClientID, _ := token.Get(ClientIDParameter); requestClientID != clientID {
+			return nil, newError(InvalidRequestObject, "request object is not of client<%s>", clientID)
+		}
+		pushed.RequestObject = requestObject
+	}
+
+	requestURI, err := randomRequestURI()
+	if err != nil {
+		return nil, newError(ServerError, "%s", err.Error())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for uri, request := range s.requests {
+		if now.After(request.ExpiresAt) {
+			delete(s.requests, uri)
+		}
+	}
+	s.requests[requestURI] = pushed
+	return &Response{RequestURI: requestURI, ExpiresIn: int(s.opts.RequestLifetime.Seconds())}, nil
+}
+
+// GetRequest redeems the pushed request of a request URI, for the authorization endpoint, which must be of the given
+// client. Pushed requests are redeemed once.
+func (s *Server) GetRequest(clientID, requestURI string) (*PushedRequest, error) {
+	if !strings.HasPrefix(requestURI, RequestURIPrefix) {
+		return nil, errors.Errorf("request uri<%s> is not of a pushed request", requestURI)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pushed, ok := s.requests[requestURI]
+	if !ok || time.Now().After(pushed.ExpiresAt) {
+		return nil, errors.Errorf("request uri<%s> is not valid", requestURI)
+	}
+	if pushed.ClientID != clientID {
+		return nil, errors.Errorf("request uri<%s> is not of client<%s>", requestURI, clientID)
+	}
+	delete(s.requests, requestURI)
+	return &pushed, nil
+}
+
+// Handler returns a handler pushing the form encoded authorization request of a request
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+		if err := r.ParseForm(); err != nil {
+			writeError(w, newError(InvalidRequest, "parsing form: %s", err.Error()))
+			return
+		}
+		response, err := s.Push(r.Context(), r.PostForm)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, response)
+	})
+}
+
+// authenticateClient verifies the client assertion of a form authenticates the client, and was not used before
+func (s *Server) authenticateClient(ctx context.Context, clientID string, form url.Values) *Error {
+	if !strings.HasPrefix(clientID, "did:") {
+		return newError(InvalidClient, "client id<%s> must be a DID", clientID)
+	}
+	if form.Get(ClientAssertionTypeParameter) != JWTBearerClientAssertionType {
+		return newError(InvalidClient, "client assertion type must be %s", JWTBearerClientAssertionType)
+	}
+	token, err := verifyClientAssertion(ctx, s.resolver, clientID, s.audience, form.Get(ClientAssertionParameter), s.opts.Leeway)
+	if err != nil {
+		return newError(InvalidClient, "client assertion: %s", err.Error())
+	}
+	if !s.opts.ReplayCache.Add(clientID+" "+token.JwtID(), token.Expiration().Add(s.opts.Leeway)) {
+		return newError(InvalidClient, "client assertion was already used")
+	}
+	return nil
+}
+
+// randomRequestURI returns a random request URI of a pushed request
+func randomRequestURI() (string, error) {
+	b := make([]byte, requestURISize)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "generating request uri")
+	}
+	return RequestURIPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var oidErr *Error
+	if !errors.As(err, &oidErr) {
+		oidErr = newError(ServerError, "%s", err.Error())
+	}
+	status := http.StatusBadRequest
+	switch oidErr.Code {
+	case InvalidClient:
+		status = http.StatusUnauthorized
+	case ServerError:
+		status = http.StatusInternalServerError
+	}
+	writeJSON(w, status, oidErr)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, "marshalling response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
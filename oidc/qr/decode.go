@@ -0,0 +1,269 @@
+package qr
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	numericModeIndicator      = 0x1
+	alphanumericModeIndicator = 0x2
+
+	alphanumericCharacters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+)
+
+// Decode returns the content of the QR code of an image, such as a rendered code or a screenshot of one. The code
+// must be upright and undistorted, on a light background, as codes are not located in photographs. Codes with
+// damaged codewords are rejected rather than corrected.
+func Decode(img image.Image) (string, error) {
+	code, err := sample(img)
+	if err != nil {
+		return "", err
+	}
+	level, mask, err := code.readFormatBits()
+	if err != nil {
+		return "", err
+	}
+	code.Level = level
+	code.applyMask(mask)
+
+	var codewords []byte
+	var current byte
+	i := 0
+	code.forEachDataModule(func(x, y int) {
+		if code.modules[y][x] {
+			current |= 1 << (7 - i%8)
+		}
+		if i++; i%8 == 0 {
+			codewords = append(codewords, current)
+			current = 0
+		}
+	})
+	data, err := checkErrorCorrection(codewords, code.Version, level)
+	if err != nil {
+		return "", err
+	}
+	return decodeData(data, code.Version)
+}
+
+// sample locates the modules of the code of an image, by the bounds of its dark pixels and the width of its top left
+// finder pattern, which is 7 modules wide
+func sample(img image.Image) (*Code, error) {
+	bounds := img.Bounds()
+	dark := func(x, y int) bool {
+		return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y < 128
+	}
+	minX, minY, maxX, maxY := bounds.Max.X, bounds.Max.Y, bounds.Min.X-1, bounds.Min.Y-1
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if dark(x, y) {
+				minX, minY, maxX, maxY = min(minX, x), min(minY, y), max(maxX, x), max(maxY, y)
+			}
+		}
+	}
+	if maxX < minX {
+		return nil, errors.New("image has no QR code")
+	}
+	finderWidth := 0
+	for x := minX; x <= maxX && dark(x, minY); x++ {
+		finderWidth++
+	}
+	moduleSize := float64(finderWidth) / 7
+	size := int(math.Round(float64(maxX-minX+1) / moduleSize))
+	if size != int(math.Round(float64(maxY-minY+1)/moduleSize)) || (size-17)%4 != 0 {
+		return nil, errors.New("image has no QR code")
+	}
+	version := (size - 17) / 4
+	if version < MinVersion || version > MaxVersion {
+		return nil, errors.Errorf("QR code version<%d> is not supported", version)
+	}
+
+	code := newCode(version, Medium)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			px := minX + int((float64(x)+0.5)*moduleSize)
+			py := minY + int((float64(y)+0.5)*moduleSize)
+			code.modules[y][x] = dark(px, py)
+		}
+	}
+	return code, nil
+}
+
+// readFormatBits returns the level and mask of the format information closest to either of the code's copies
+func (c *Code) readFormatBits() (Level, int, error) {
+	var first, second int
+	read := func(value *int, x, y, i int) {
+		if c.modules[y][x] {
+			*value |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		read(&first, 8, i, i)
+	}
+	read(&first, 8, 7, 6)
+	read(&first, 8, 8, 7)
+	read(&first, 7, 8, 8)
+	for i := 9; i < 15; i++ {
+		read(&first, 14-i, 8, i)
+	}
+	for i := 0; i < 8; i++ {
+		read(&second, c.Size-1-i, 8, i)
+	}
+	for i := 8; i < 15; i++ {
+		read(&second, 8, c.Size-15+i, i)
+	}
+
+	// format information is corrected for up to 3 errors
+	bestLevel, bestMask, bestDistance := Medium, 0, 4
+	for _, level := range []Level{Medium, Low, Quartile, High} {
+		for mask := 0; mask < 8; mask++ {
+			expected := formatBits(level, mask)
+			distance := min(bits.OnesCount(uint(first^expected)), bits.OnesCount(uint(second^expected)))
+			if distance < bestDistance {
+				bestLevel, bestMask, bestDistance = level, mask, distance
+			}
+		}
+	}
+	if bestDistance > 3 {
+		return 0, 0, errors.New("QR code format information is not valid")
+	}
+	return bestLevel, bestMask, nil
+}
+
+// checkErrorCorrection de-interleaves the codewords of a version at a level, returning the data codewords of the
+// blocks whose error correction codewords are those of their data
+func checkErrorCorrection(codewords []byte, version int, level Level) ([]byte, error) {
+	numBlocks, numShortBlocks, shortDataLen, eccLen := blockLayout(version, level)
+	dataBlocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortDataLen; i++ {
+		for j := range dataBlocks {
+			if i < shortDataLen || j >= numShortBlocks {
+				dataBlocks[j] = append(dataBlocks[j], codewords[k])
+				k++
+			}
+		}
+	}
+	eccBlocks := make([][]byte, numBlocks)
+	for i := 0; i < eccLen; i++ {
+		for j := range eccBlocks {
+			eccBlocks[j] = append(eccBlocks[j], codewords[k])
+			k++
+		}
+	}
+
+	divisor := reedSolomonDivisor(eccLen)
+	data := make([]byte, 0, numDataCodewords(version, level))
+	for j, block := range dataBlocks {
+		if !bytes.Equal(reedSolomonRemainder(block, divisor), eccBlocks[j]) {
+			return nil, errors.New("QR code is damaged")
+		}
+		data = append(data, block...)
+	}
+	return data, nil
+}
+
+// decodeData decodes the numeric, alphanumeric, and byte mode segments of data codewords, until the terminator
+func decodeData(data []byte, version int) (string, error) {
+	r := bitReader{data: data}
+	var content strings.Builder
+	for r.remaining() >= 4 {
+		mode := r.read(4)
+		if mode == 0 {
+			break
+		}
+		switch mode {
+		case numericModeIndicator:
+			count := r.read(countBits(version, 10, 12, 14))
+			for ; count >= 3; count -= 3 {
+				content.WriteString(padDigits(r.read(10), 3))
+			}
+			if count == 2 {
+				content.WriteString(padDigits(r.read(7), 2))
+			} else if count == 1 {
+				content.WriteString(padDigits(r.read(4), 1))
+			}
+		case alphanumericModeIndicator:
+			count := r.read(countBits(version, 9, 11, 13))
+			for ; count >= 2; count -= 2 {
+				pair := r.read(11)
+				if pair/45 >= len(alphanumericCharacters) {
+					return "", errors.New("QR code has an invalid alphanumeric segment")
+				}
+				content.WriteByte(alphanumericCharacters[pair/45])
+				content.WriteByte(alphanumericCharacters[pair%45])
+			}
+			if count == 1 {
+				single := r.read(6)
+				if single >= len(alphanumericCharacters) {
+					return "", errors.New("QR code has an invalid alphanumeric segment")
+				}
+				content.WriteByte(alphanumericCharacters[single])
+			}
+		case byteModeIndicator:
+			count := r.read(countBits(version, 8, 16, 16))
+			for ; count > 0; count-- {
+				content.WriteByte(byte(r.read(8)))
+			}
+		default:
+			return "", errors.Errorf("QR code segment mode<%d> is not supported", mode)
+		}
+		if r.overflowed {
+			return "", errors.New("QR code segment exceeds its data")
+		}
+	}
+	return content.String(), nil
+}
+
+// countBits returns the width of the character count of a segment mode for versions 1-9, 10-26, and 27-40
+func countBits(version, small, medium, large int) int {
+	switch {
+	case version <= 9:
+		return small
+	case version <= 26:
+		return medium
+	}
+	return large
+}
+
+// padDigits formats a number with leading zeros to the given number of digits
+func padDigits(n, digits int) string {
+	s := make([]byte, digits)
+	for i := digits - 1; i >= 0; i-- {
+		s[i] = byte('0' + n%10)
+		n /= 10
+	}
+	return string(s)
+}
+
+// bitReader reads bits from bytes, most significant first
+type bitReader struct {
+	data       []byte
+	offset     int
+	overflowed bool
+}
+
+func (r *bitReader) remaining() int {
+	return len(r.data)*8 - r.offset
+}
+
+// read returns the next n bits, recording reads past the end of the data, which are zero
+func (r *bitReader) read(n int) int {
+	value := 0
+	for i := 0; i < n; i++ {
+		value <<= 1
+		if r.offset >= len(r.data)*8 {
+			r.overflowed = true
+			continue
+		}
+		value |= int(r.data[r.offset>>3] >> (7 - r.offset&7) & 1)
+		r.offset++
+	}
+	return value
+}
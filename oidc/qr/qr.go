@@ -0,0 +1,503 @@
+package qr
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+
+	"github.com/pkg/errors"
+)
+
+// QR codes carry the URIs of cross-device flows, such as credential offers and authorization requests, from the
+// screen of an issuer or verifier to the camera of a wallet. Content is encoded in byte mode.
+// https://www.iso.org/standard/62021.html
+
+// Level is the error correction level of a QR code, which trades capacity for resilience to damage
+type Level int
+
+const (
+	// Medium recovers 15% of codewords, and is the default
+	Medium Level = iota
+	// Low recovers 7% of codewords
+	Low
+	// Quartile recovers 25% of codewords
+	Quartile
+	// High recovers 30% of codewords
+	High
+)
+
+const (
+	MinVersion = 1
+	MaxVersion = 40
+
+	// DefaultMaxVersion bounds the version of QR codes by default, above which codes are too dense to be reliably
+	// scanned from a screen
+	DefaultMaxVersion = 25
+
+	// QuietZone is the width, in modules, of the light border of QR code images
+	QuietZone = 4
+
+	byteModeIndicator = 0x4
+)
+
+// Options configures the encoding of a QR code
+type Options struct {
+	Level Level
+	// MaxVersion bounds the version, and so the size, of the code, and defaults to DefaultMaxVersion
+	MaxVersion int
+}
+
+// Code is a QR code: a square of modules, each dark or light
+type Code struct {
+	Version int
+	Level   Level
+	// Size is the width and height of the code in modules, without its quiet zone
+	Size int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Encode returns the QR code of the smallest version encoding the content at the options' error correction level,
+// failing if the content does not fit in a code of the maximum version
+func Encode(content string, opts Options) (*Code, error) {
+	if opts.MaxVersion == 0 {
+		opts.MaxVersion = DefaultMaxVersion
+	}
+	if opts.MaxVersion < MinVersion || opts.MaxVersion > MaxVersion {
+		return nil, errors.Errorf("max version<%d> must be between %d and %d", opts.MaxVersion, MinVersion, MaxVersion)
+	}
+	if opts.Level < Medium || opts.Level > High {
+		return nil, errors.Errorf("error correction level<%d> is not supported", opts.Level)
+	}
+	version := versionFor(len(content), opts)
+	if version == 0 {
+		return nil, errors.Errorf("content of %d bytes does not fit in a QR code of version %d", len(content), opts.MaxVersion)
+	}
+
+	code := newCode(version, opts.Level)
+	code.drawCodewords(addErrorCorrection(encodeData(content, version, opts.Level), version, opts.Level))
+	code.applyBestMask()
+	return code, nil
+}
+
+// Fits returns whether content fits in a QR code of at most the options' maximum version
+func Fits(content string, opts Options) bool {
+	if opts.MaxVersion == 0 {
+		opts.MaxVersion = DefaultMaxVersion
+	}
+	return versionFor(len(content), opts) != 0
+}
+
+// versionFor returns the smallest version whose capacity holds content of the given length, or 0 if none does
+func versionFor(length int, opts Options) int {
+	for version := MinVersion; version <= opts.MaxVersion && version <= MaxVersion; version++ {
+		if 4+byteCountBits(version)+8*length <= 8*numDataCodewords(version, opts.Level) &&
+			length < 1<<byteCountBits(version) {
+			return version
+		}
+	}
+	return 0
+}
+
+// Dark returns whether the module at column x and row y is dark
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y][x]
+}
+
+// Image returns an image of the code, each module a square of the given number of pixels, surrounded by its quiet
+// zone
+func (c *Code) Image(moduleSize int) *image.Gray {
+	if moduleSize < 1 {
+		moduleSize = 1
+	}
+	width := (c.Size + 2*QuietZone) * moduleSize
+	img := image.NewGray(image.Rect(0, 0, width, width))
+	for py := 0; py < width; py++ {
+		for px := 0; px < width; px++ {
+			shade := color.White
+			if c.Dark(px/moduleSize-QuietZone, py/moduleSize-QuietZone) {
+				shade = color.Black
+			}
+			img.SetGray(px, py, color.GrayModel.Convert(shade).(color.Gray))
+		}
+	}
+	return img
+}
+
+// PNG returns a PNG image of the code, each module a square of the given number of pixels
+func (c *Code) PNG(moduleSize int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(moduleSize)); err != nil {
+		return nil, errors.Wrap(err, "encoding png")
+	}
+	return buf.Bytes(), nil
+}
+
+// newCode returns a code of a version with its function patterns drawn, and its data modules light
+func newCode(version int, level Level) *Code {
+	size := version*4 + 17
+	code := Code{Version: version, Level: level, Size: size}
+	code.modules = make([][]bool, size)
+	code.isFunction = make([][]bool, size)
+	for y := range code.modules {
+		code.modules[y] = make([]bool, size)
+		code.isFunction[y] = make([]bool, size)
+	}
+	code.drawFunctionPatterns()
+	return &code
+}
+
+func (c *Code) setFunctionModule(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunction[y][x] = true
+}
+
+// drawFunctionPatterns draws the timing, finder, and alignment patterns, and the version information, reserving the
+// modules of the format information
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunctionModule(6, i, i%2 == 0)
+		c.setFunctionModule(i, 6, i%2 == 0)
+	}
+
+	c.drawFinderPattern(3, 3)
+	c.drawFinderPattern(c.Size-4, 3)
+	c.drawFinderPattern(3, c.Size-4)
+
+	positions := alignmentPatternPositions(c.Version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// alignment patterns do not overlap the finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignmentPattern(x, y)
+		}
+	}
+
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// drawFinderPattern draws a finder pattern, with its separator, centered at the given module
+func (c *Code) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunctionModule(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignmentPattern draws an alignment pattern centered at the given module
+func (c *Code) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunctionModule(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the format information of the code's level and a mask, and the dark module
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(c.Level, mask)
+	for i := 0; i <= 5; i++ {
+		c.setFunctionModule(8, i, bit(bits, i))
+	}
+	c.setFunctionModule(8, 7, bit(bits, 6))
+	c.setFunctionModule(8, 8, bit(bits, 7))
+	c.setFunctionModule(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunctionModule(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunctionModule(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunctionModule(8, c.Size-15+i, bit(bits, i))
+	}
+	c.setFunctionModule(8, c.Size-8, true)
+}
+
+// drawVersion draws both copies of the version information of versions 7 and above
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	bits := versionBits(c.Version)
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		c.setFunctionModule(a, b, bit(bits, i))
+		c.setFunctionModule(b, a, bit(bits, i))
+	}
+}
+
+// formatBits returns the format information of a level and mask, with its BCH error correction bits
+func formatBits(level Level, mask int) int {
+	data := levelFormatBits(level)<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// levelFormatBits returns the two bits of a level in the format information
+func levelFormatBits(level Level) int {
+	switch level {
+	case Low:
+		return 1
+	case Quartile:
+		return 3
+	case High:
+		return 2
+	}
+	return 0
+}
+
+// versionBits returns the version information of a version, with its BCH error correction bits
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+// alignmentPatternPositions returns the row and column positions of the centers of the alignment patterns of a
+// version
+func alignmentPatternPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	if version == 32 {
+		step = 26
+	}
+	positions := make([]int, numAlign)
+	positions[0] = 6
+	for i, pos := numAlign-1, version*4+10; i > 0; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// encodeData returns the data codewords of content in byte mode, with its terminator and padding
+func encodeData(content string, version int, level Level) []byte {
+	var bits bitBuffer
+	bits.append(byteModeIndicator, 4)
+	bits.append(len(content), byteCountBits(version))
+	for i := 0; i < len(content); i++ {
+		bits.append(int(content[i]), 8)
+	}
+
+	capacity := numDataCodewords(version, level) * 8
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	return bits.bytes()
+}
+
+// byteCountBits returns the width of the character count of byte mode segments of a version
+func byteCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// drawCodewords draws the codewords in the zigzag order of the data modules, from the bottom right corner up and
+// down in columns of two
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	c.forEachDataModule(func(x, y int) {
+		if i < len(codewords)*8 {
+			c.modules[y][x] = codewords[i>>3]>>(7-i&7)&1 == 1
+			i++
+		}
+	})
+}
+
+// forEachDataModule calls f with each module which is not a function module, in the order codewords are drawn
+func (c *Code) forEachDataModule(f func(x, y int)) {
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		// the vertical timing pattern is skipped
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !c.isFunction[y][x] {
+					f(x, y)
+				}
+			}
+		}
+	}
+}
+
+// applyBestMask applies the mask of the lowest penalty, drawing its format information
+func (c *Code) applyBestMask() {
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if penalty := c.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		// masks are their own inverse
+		c.applyMask(mask)
+	}
+	c.applyMask(best)
+	c.drawFormatBits(best)
+}
+
+// applyMask inverts the data modules the mask's pattern selects
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.isFunction[y][x] && masked(mask, x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// masked returns whether the module at column x and row y is inverted by a mask
+func masked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// penalty scores the modules by the rules of mask evaluation: runs of modules of the same color, 2x2 blocks of the
+// same color, patterns resembling finder patterns, and an imbalance of dark and light modules
+func (c *Code) penalty() int {
+	const (
+		runPenalty     = 3
+		blockPenalty   = 3
+		finderPenalty  = 40
+		balancePenalty = 10
+	)
+	finderLike := [][]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+
+	result, dark := 0, 0
+	for line := 0; line < c.Size; line++ {
+		for _, horizontal := range []bool{true, false} {
+			at := func(i int) bool {
+				if horizontal {
+					return c.modules[line][i]
+				}
+				return c.modules[i][line]
+			}
+			run := 1
+			for i := 1; i <= c.Size; i++ {
+				if i < c.Size && at(i) == at(i-1) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					result += runPenalty + run - 5
+				}
+				run = 1
+			}
+			for i := 0; i+11 <= c.Size; i++ {
+				for _, pattern := range finderLike {
+					matches := true
+					for j, d := range pattern {
+						if at(i+j) != d {
+							matches = false
+							break
+						}
+					}
+					if matches {
+						result += finderPenalty
+					}
+				}
+			}
+		}
+	}
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size {
+				d := c.modules[y][x]
+				if c.modules[y][x+1] == d && c.modules[y+1][x] == d && c.modules[y+1][x+1] == d {
+					result += blockPenalty
+				}
+			}
+		}
+	}
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return result + k*balancePenalty
+}
+
+// bitBuffer is a sequence of bits, each a byte of 0 or 1
+type bitBuffer []byte
+
+// append appends the low n bits of the value, most significant first
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, byte(value>>i&1))
+	}
+}
+
+// bytes packs the bits into bytes, most significant bit first
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, (len(b)+7)/8)
+	for i, v := range b {
+		result[i>>3] |= v << (7 - i&7)
+	}
+	return result
+}
+
+func bit(value, i int) bool {
+	return value>>i&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,123 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	t.Run("error correction and format information", func(tt *testing.T) {
+		// the data codewords of HELLO WORLD at 1-M, and their error correction codewords
+		data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+		ecc := reedSolomonRemainder(data, reedSolomonDivisor(10))
+		assert.Equal(tt, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, ecc)
+
+		assert.Equal(tt, 0b111011111000100, formatBits(Low, 0))
+		assert.Equal(tt, 0b101010000010010, formatBits(Medium, 0))
+		assert.Equal(tt, 0b000111110010010100, versionBits(7))
+		assert.Equal(tt, []int{6, 34, 60, 86, 112, 138}, alignmentPatternPositions(32))
+	})
+
+	t.Run("capacity", func(tt *testing.T) {
+		assert.Equal(tt, 19, numDataCodewords(1, Low))
+		assert.Equal(tt, 2956, numDataCodewords(40, Low))
+		assert.Equal(tt, 1276, numDataCodewords(40, High))
+
+		code, err := Encode(strings.Repeat("a", 17), Options{Level: Low})
+		require.NoError(tt, err)
+		assert.Equal(tt, 1, code.Version)
+		assert.Equal(tt, 21, code.Size)
+		code, err = Encode(strings.Repeat("a", 18), Options{Level: Low})
+		require.NoError(tt, err)
+		assert.Equal(tt, 2, code.Version)
+
+		content := strings.Repeat("a", 1000)
+		assert.False(tt, Fits(content, Options{}))
+		_, err = Encode(content, Options{})
+		assert.ErrorContains(tt, err, "content of 1000 bytes does not fit in a QR code of version 25")
+		assert.True(tt, Fits(content, Options{MaxVersion: MaxVersion}))
+		_, err = Encode(content, Options{MaxVersion: 41})
+		assert.ErrorContains(tt, err, "max version<41> must be between 1 and 40")
+	})
+
+	t.Run("finder patterns and quiet zone", func(tt *testing.T) {
+		code, err := Encode("openid-credential-offer://", Options{})
+		require.NoError(tt, err)
+		for _, corner := range [][2]int{{0, 0}, {code.Size - 7, 0}, {0, code.Size - 7}} {
+			for i := 0; i < 7; i++ {
+				assert.True(tt, code.Dark(corner[0]+i, corner[1]))
+				assert.True(tt, code.Dark(corner[0], corner[1]+i))
+			}
+			assert.False(tt, code.Dark(corner[0]+1, corner[1]+1))
+			assert.True(tt, code.Dark(corner[0]+3, corner[1]+3))
+		}
+
+		img := code.Image(2)
+		assert.Equal(tt, (code.Size+2*QuietZone)*2, img.Bounds().Dx())
+		assert.Equal(tt, uint8(0xff), img.GrayAt(QuietZone*2-1, QuietZone*2).Y)
+		assert.Equal(tt, uint8(0), img.GrayAt(QuietZone*2, QuietZone*2).Y)
+	})
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("round trip", func(tt *testing.T) {
+		for _, level := range []Level{Low, Medium, Quartile, High} {
+			for _, length := range []int{0, 1, 42, 300, 1200} {
+				content := strings.Repeat("openid4vp://?request=eyJ🔑", length/26+1)[:length]
+				code, err := Encode(content, Options{Level: level, MaxVersion: MaxVersion})
+				require.NoError(tt, err)
+				decoded, err := Decode(code.Image(3))
+				require.NoError(tt, err, fmt.Sprintf("level %d, version %d", level, code.Version))
+				assert.Equal(tt, content, decoded)
+			}
+		}
+	})
+
+	t.Run("png", func(tt *testing.T) {
+		code, err := Encode("openid-credential-offer://?credential_offer_uri=https%3A%2F%2Fissuer.example.com%2Foffer", Options{})
+		require.NoError(tt, err)
+		encoded, err := code.PNG(4)
+		require.NoError(tt, err)
+		img, err := png.Decode(bytes.NewReader(encoded))
+		require.NoError(tt, err)
+		decoded, err := Decode(img)
+		require.NoError(tt, err)
+		assert.Equal(tt, "openid-credential-offer://?credential_offer_uri=https%3A%2F%2Fissuer.example.com%2Foffer", decoded)
+	})
+
+	t.Run("damaged codes", func(tt *testing.T) {
+		code, err := Encode("openid4vp://", Options{})
+		require.NoError(tt, err)
+		code.modules[code.Size-1][code.Size-1] = !code.modules[code.Size-1][code.Size-1]
+		_, err = Decode(code.Image(2))
+		assert.ErrorContains(tt, err, "QR code is damaged")
+
+		blank := image.NewGray(image.Rect(0, 0, 10, 10))
+		copy(blank.Pix, bytes.Repeat([]byte{0xff}, len(blank.Pix)))
+		_, err = Decode(blank)
+		assert.ErrorContains(tt, err, "image has no QR code")
+	})
+
+	t.Run("numeric and alphanumeric segments", func(tt *testing.T) {
+		var bits bitBuffer
+		bits.append(numericModeIndicator, 4)
+		bits.append(5, 10)
+		bits.append(12, 10)
+		bits.append(34, 7)
+		bits.append(alphanumericModeIndicator, 4)
+		bits.append(3, 9)
+		bits.append(17*45+14, 11)
+		bits.append(36, 6)
+		bits.append(0, 4)
+		decoded, err := decodeData(bits.bytes(), 1)
+		require.NoError(tt, err)
+		assert.Equal(tt, "01234HE ", decoded)
+	})
+}
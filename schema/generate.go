@@ -0,0 +1,254 @@
+package schema
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+const (
+	// Draft202012 is the JSON Schema version generated schemas declare
+	Draft202012 = "https://json-schema.org/draft/2020-12/schema"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// GenerateJSONSchema generates a JSON Schema describing the JSON encoding of a Go type. Struct fields are named by
+// their json tags, and are required unless they are tagged omitempty. Fields tagged `json:"-"` and unexported fields
+// are left out. A field's description may be given with a `description` tag, and further keywords with a `jsonschema`
+// tag of comma separated keywords, such as `jsonschema:"format=email,minLength=3,enum=a|b"`. The `required` and
+// `optional` keywords override whether a field is required.
+// Recursive types are not supported.
+func GenerateJSONSchema(t reflect.Type) (map[string]any, error) {
+	if t == nil {
+		return nil, errors.New("cannot generate a schema without a type")
+	}
+	s, err := generateSchema(t, make(map[reflect.Type]bool))
+	if err != nil {
+		return nil, err
+	}
+	s["$schema"] = Draft202012
+	return s, nil
+}
+
+func generateSchema(t reflect.Type, visiting map[reflect.Type]bool) (map[string]any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// types with their own text encoding are strings, as are times
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}, nil
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return map[string]any{"type": "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Interface:
+		// any value is allowed
+		return map[string]any{}, nil
+	case reflect.Slice, reflect.Array:
+		// byte slices are base64 encoded
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}, nil
+		}
+		items, err := generateSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map<%s> must have string keys", t)
+		}
+		values, err := generateSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return generateStructSchema(t, visiting)
+	default:
+		return nil, fmt.Errorf("type<%s> cannot be described by a JSON schema", t)
+	}
+}
+
+func generateStructSchema(t reflect.Type, visiting map[reflect.Type]bool) (map[string]any, error) {
+	if visiting[t] {
+		return nil, fmt.Errorf("recursive type<%s> is not supported", t)
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := make(map[string]any)
+	var required []string
+	if err := addStructProperties(t, visiting, properties, &required); err != nil {
+		return nil, err
+	}
+	s := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s, nil
+}
+
+// addStructProperties adds the properties of a struct's fields, including those of its embedded structs, which are
+// encoded as if they were fields of the struct itself
+func addStructProperties(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]any, required *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			if err := addStructProperties(fieldType, visiting, properties, required); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := generateSchema(field.Type, visiting)
+		if err != nil {
+			return errors.Wrapf(err, "generating schema for field<%s>", field.Name)
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		isRequired := !strings.Contains(options, "omitempty")
+		if annotations := field.Tag.Get("jsonschema"); annotations != "" {
+			if isRequired, err = applyAnnotations(property, annotations, isRequired); err != nil {
+				return errors.Wrapf(err, "applying annotations of field<%s>", field.Name)
+			}
+		}
+		properties[name] = property
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// applyAnnotations adds the keywords of a `jsonschema` tag to a property's schema, returning whether the property is
+// required
+func applyAnnotations(property map[string]any, annotations string, isRequired bool) (bool, error) {
+	for _, annotation := range strings.Split(annotations, ",") {
+		keyword, value, hasValue := strings.Cut(strings.TrimSpace(annotation), "=")
+		switch keyword {
+		case "required":
+			isRequired = true
+		case "optional":
+			isRequired = false
+		case "title", "description", "format", "pattern":
+			property[keyword] = value
+		case "enum":
+			var enum []any
+			for _, v := range strings.Split(value, "|") {
+				enum = append(enum, v)
+			}
+			property[keyword] = enum
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return false, errors.Wrapf(err, "parsing %s", keyword)
+			}
+			property[keyword] = number
+		case "minLength", "maxLength", "minItems", "maxItems":
+			length, err := strconv.Atoi(value)
+			if err != nil {
+				return false, errors.Wrapf(err, "parsing %s", keyword)
+			}
+			property[keyword] = length
+		default:
+			return false, fmt.Errorf("unknown keyword<%s>", keyword)
+		}
+		if !hasValue && keyword != "required" && keyword != "optional" {
+			return false, fmt.Errorf("keyword<%s> must have a value", keyword)
+		}
+	}
+	return isRequired, nil
+}
+
+// InferJSONSchema infers a JSON Schema from a sample of JSON data, such as a credential's subject, in which each
+// property of an object in the sample is required, and the items of an array are described by its first item
+func InferJSONSchema(sample any) (map[string]any, error) {
+	sampleJSON, err := util.AnyToJSONInterface(sample)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting sample to JSON")
+	}
+	s := inferSchema(sampleJSON)
+	s["$schema"] = Draft202012
+	return s, nil
+}
+
+func inferSchema(value any) map[string]any {
+	switch typed := value.(type) {
+	case map[string]any:
+		properties := make(map[string]any, len(typed))
+		required := make([]string, 0, len(typed))
+		for k, v := range typed {
+			properties[k] = inferSchema(v)
+			required = append(required, k)
+		}
+		sort.Strings(required)
+		s := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	case []any:
+		if len(typed) == 0 {
+			return map[string]any{"type": "array"}
+		}
+		return map[string]any{"type": "array", "items": inferSchema(typed[0])}
+	case string:
+		return map[string]any{"type": "string"}
+	case bool:
+		return map[string]any{"type": "boolean"}
+	case float64:
+		if typed == math.Trunc(typed) {
+			return map[string]any{"type": "integer"}
+		}
+		return map[string]any{"type": "number"}
+	case nil:
+		return map[string]any{"type": "null"}
+	}
+	return map[string]any{}
+}
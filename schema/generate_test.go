@@ -0,0 +1,151 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type generatedAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city,omitempty"`
+}
+
+type generatedPerson struct {
+	generatedAddress
+	Name     string             `json:"name" description:"full name"`
+	Age      int                `json:"age,omitempty"`
+	Born     time.Time          `json:"born"`
+	Tags     []string           `json:"tags,omitempty"`
+	Scores   map[string]float64 `json:"scores,omitempty"`
+	Friend   *generatedAddress  `json:"friend,omitempty"`
+	Extra    any                `json:"extra,omitempty"`
+	Ignored  string             `json:"-"`
+	internal string
+}
+
+type recursive struct {
+	Next *recursive `json:"next,omitempty"`
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	t.Run("struct", func(tt *testing.T) {
+		s, err := GenerateJSONSchema(reflect.TypeOf(generatedPerson{}))
+		require.NoError(tt, err)
+		assert.Equal(tt, Draft202012, s["$schema"])
+		assert.Equal(tt, []string{"street", "name", "born"}, s["required"])
+
+		properties := s["properties"].(map[string]any)
+		assert.Len(tt, properties, 9)
+		assert.Equal(tt, map[string]any{"type": "string", "description": "full name"}, properties["name"])
+		assert.Equal(tt, map[string]any{"type": "string", "format": "date-time"}, properties["born"])
+		assert.Equal(tt, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, properties["tags"])
+		assert.Equal(tt, map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "number"}}, properties["scores"])
+
+		schemaBytes, err := json.Marshal(s)
+		require.NoError(tt, err)
+		assert.NoError(tt, IsValidJSONSchema(string(schemaBytes)))
+
+		person := generatedPerson{Name: "Alice", Born: time.Now(), Friend: &generatedAddress{Street: "Main St"}}
+		assert.NoError(tt, IsAnyValidAgainstJSONSchema(person, string(schemaBytes)))
+		assert.Error(tt, IsAnyValidAgainstJSONSchema(map[string]any{"name": "Alice"}, string(schemaBytes)))
+	})
+
+	t.Run("unsupported types", func(tt *testing.T) {
+		_, err := GenerateJSONSchema(nil)
+		assert.ErrorContains(tt, err, "cannot generate a schema without a type")
+
+		_, err = GenerateJSONSchema(reflect.TypeOf(recursive{}))
+		assert.ErrorContains(tt, err, "recursive type")
+
+		_, err = GenerateJSONSchema(reflect.TypeOf(map[int]string{}))
+		assert.ErrorContains(tt, err, "must have string keys")
+
+		_, err = GenerateJSONSchema(reflect.TypeOf(make(chan int)))
+		assert.ErrorContains(tt, err, "cannot be described by a JSON schema")
+	})
+}
+
+type annotatedContact struct {
+	Email   string `json:"email" jsonschema:"format=email,minLength=3"`
+	Kind    string `json:"kind,omitempty" jsonschema:"enum=home|work,required"`
+	Rating  int    `json:"rating" jsonschema:"minimum=1,maximum=5,optional"`
+	Comment string `json:"comment,omitempty" jsonschema:"title=Comment,pattern=^[a-z ]*$"`
+}
+
+type badAnnotation struct {
+	Name string `json:"name" jsonschema:"unknown=1"`
+}
+
+func TestGenerateJSONSchemaAnnotations(t *testing.T) {
+	t.Run("annotated struct", func(tt *testing.T) {
+		s, err := GenerateJSONSchema(reflect.TypeOf(annotatedContact{}))
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{"email", "kind"}, s["required"])
+
+		properties := s["properties"].(map[string]any)
+		assert.Equal(tt, map[string]any{"type": "string", "format": "email", "minLength": 3}, properties["email"])
+		assert.Equal(tt, map[string]any{"type": "string", "enum": []any{"home", "work"}}, properties["kind"])
+		assert.Equal(tt, map[string]any{"type": "integer", "minimum": 1.0, "maximum": 5.0}, properties["rating"])
+		assert.Equal(tt, map[string]any{"type": "string", "title": "Comment", "pattern": "^[a-z ]*$"}, properties["comment"])
+
+		schemaBytes, err := json.Marshal(s)
+		require.NoError(tt, err)
+		assert.NoError(tt, IsAnyValidAgainstJSONSchema(annotatedContact{Email: "a@b.c", Kind: "home"}, string(schemaBytes)))
+		assert.Error(tt, IsAnyValidAgainstJSONSchema(annotatedContact{Email: "a@b.c", Kind: "other"}, string(schemaBytes)))
+		assert.Error(tt, IsAnyValidAgainstJSONSchema(annotatedContact{Email: "a@b.c", Kind: "work", Rating: 6}, string(schemaBytes)))
+	})
+
+	t.Run("bad annotations", func(tt *testing.T) {
+		_, err := GenerateJSONSchema(reflect.TypeOf(badAnnotation{}))
+		assert.ErrorContains(tt, err, "unknown keyword<unknown>")
+
+		_, err = GenerateJSONSchema(reflect.TypeOf(struct {
+			Name string `json:"name" jsonschema:"minLength=many"`
+		}{}))
+		assert.ErrorContains(tt, err, "parsing minLength")
+
+		_, err = GenerateJSONSchema(reflect.TypeOf(struct {
+			Name string `json:"name" jsonschema:"format"`
+		}{}))
+		assert.ErrorContains(tt, err, "must have a value")
+	})
+}
+
+func TestInferJSONSchema(t *testing.T) {
+	sample := map[string]any{
+		"name":     "Alice",
+		"age":      30,
+		"height":   1.7,
+		"verified": true,
+		"address":  map[string]any{"city": "Paris"},
+		"tags":     []string{"a", "b"},
+		"empty":    []any{},
+		"nothing":  nil,
+	}
+	s, err := InferJSONSchema(sample)
+	require.NoError(t, err)
+	assert.Equal(t, Draft202012, s["$schema"])
+	assert.Equal(t, []string{"address", "age", "empty", "height", "name", "nothing", "tags", "verified"}, s["required"])
+
+	properties := s["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "integer"}, properties["age"])
+	assert.Equal(t, map[string]any{"type": "number"}, properties["height"])
+	assert.Equal(t, map[string]any{"type": "boolean"}, properties["verified"])
+	assert.Equal(t, map[string]any{"type": "null"}, properties["nothing"])
+	assert.Equal(t, map[string]any{"type": "array"}, properties["empty"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, properties["tags"])
+	assert.Equal(t, map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"city": map[string]any{"type": "string"}},
+		"required":   []string{"city"},
+	}, properties["address"])
+
+	schemaBytes, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.NoError(t, IsAnyValidAgainstJSONSchema(sample, string(schemaBytes)))
+}
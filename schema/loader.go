@@ -28,6 +28,7 @@ const (
 	// Presentation Exchange Schemas
 
 	PresentationDefinitionSchema              File = "pe-presentation-definition.json"
+	PresentationDefinitionV1Schema            File = "pe-v1-presentation-definition.json"
 	PresentationDefinitionEnvelopeSchema      File = "pe-presentation-definition-envelope.json"
 	PresentationSubmissionSchema              File = "pe-presentation-submission.json"
 	PresentationClaimFormatDesignationsSchema File = "pe-definition-claim-format-designations.json"
@@ -0,0 +1,213 @@
+package sdjwt
+
+import (
+	"bytes"
+	gocrypto "crypto"
+	"encoding/base64"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+)
+
+const (
+	cnfClaimName    = "cnf"
+	jwkClaimName    = "jwk"
+	nonceClaimName  = "nonce"
+	sdHashClaimName = "sd_hash"
+
+	// KeyBindingJWTType is the `typ` header of a Key Binding JWT as specified in
+	// https://www.ietf.org/archive/id/draft-ietf-oauth-selective-disclosure-jwt-07.html#name-key-binding-jwt
+	KeyBindingJWTType = "kb+jwt"
+)
+
+// AddConfirmationKey returns the JSON-encoded claimsData with a `cnf` claim containing the holder's public key as a
+// JWK, as specified in https://www.rfc-editor.org/rfc/rfc7800.html#section-3.2. The resulting claims should be passed
+// to BlindAndSign, without blinding the `cnf` claim, so that verifiers can check Key Binding JWTs against it.
+func AddConfirmationKey(claimsData []byte, holderPublicKey gocrypto.PublicKey) ([]byte, error) {
+	var claimsMap map[string]any
+	if err := json.Unmarshal(claimsData, &claimsMap); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling claims")
+	}
+	holderJWK, err := jwk.FromRaw(holderPublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting holder key to jwk")
+	}
+	if _, isPrivate := holderJWK.(jwk.AsymmetricKey); isPrivate {
+		if holderJWK, err = jwk.PublicKeyOf(holderJWK); err != nil {
+			return nil, errors.Wrap(err, "getting public jwk of holder key")
+		}
+	}
+	claimsMap[cnfClaimName] = map[string]any{jwkClaimName: holderJWK}
+	return json.Marshal(claimsMap)
+}
+
+// GetConfirmationKey returns the holder's public key from the `cnf` claim of an SD-JWT.
+func GetConfirmationKey(t jwt.Token) (jwk.Key, error) {
+	cnf, ok := t.Get(cnfClaimName)
+	if !ok {
+		return nil, errors.New("cnf claim not found")
+	}
+	cnfMap, ok := cnf.(map[string]any)
+	if !ok {
+		return nil, errors.New("cnf claim must be an object")
+	}
+	holderJWK, ok := cnfMap[jwkClaimName]
+	if !ok {
+		return nil, errors.New("cnf claim does not contain a jwk")
+	}
+	holderJWKData, err := json.Marshal(holderJWK)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling cnf jwk")
+	}
+	key, err := jwk.ParseKey(holderJWKData)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing cnf jwk")
+	}
+	return key, nil
+}
+
+// CreateKeyBindingJWT returns a Key Binding JWT for the given presentation, which must be a Combined Format for
+// Presentation without a Key Binding JWT, i.e. ending with `~`. The JWT is signed by the holder's private key, and
+// binds the presentation to the verifier's audience and nonce as specified in
+// https://www.ietf.org/archive/id/draft-ietf-oauth-selective-disclosure-jwt-07.html#name-key-binding-jwt
+// The sd_hash claim is computed with sha-256, the default _sd_alg.
+func CreateKeyBindingJWT(presentation []byte, holderKey gocrypto.PrivateKey, alg jwa.SignatureAlgorithm, audience, nonce string) ([]byte, error) {
+	if !bytes.HasSuffix(presentation, []byte("~")) {
+		return nil, errors.New("presentation must end with a ~ and not contain a key binding jwt")
+	}
+	if alg == jwa.NoSignature {
+		return nil, errors.New("key binding jwt cannot use the none algorithm")
+	}
+	if audience == "" || nonce == "" {
+		return nil, errors.New("audience and nonce are required")
+	}
+
+	t := jwt.New()
+	if err := t.Set(jwt.IssuedAtKey, time.Now()); err != nil {
+		return nil, errors.Wrap(err, "setting iat")
+	}
+	if err := t.Set(jwt.AudienceKey, audience); err != nil {
+		return nil, errors.Wrap(err, "setting aud")
+	}
+	if err := t.Set(nonceClaimName, nonce); err != nil {
+		return nil, errors.Wrap(err, "setting nonce")
+	}
+	if err := t.Set(sdHashClaimName, presentationDigest(presentation, sha256Digest)); err != nil {
+		return nil, errors.Wrap(err, "setting sd_hash")
+	}
+
+	hdrs := jws.NewHeaders()
+	if err := hdrs.Set(jws.TypeKey, KeyBindingJWTType); err != nil {
+		return nil, errors.Wrap(err, "setting typ header")
+	}
+	signed, err := jwt.Sign(t, jwt.WithKey(alg, holderKey, jws.WithProtectedHeaders(hdrs)))
+	if err != nil {
+		return nil, errors.Wrap(err, "signing key binding jwt")
+	}
+	return signed, nil
+}
+
+// CreatePresentationWithKeyBinding creates the Combined Format for Presentation like CreatePresentation, appending a
+// Key Binding JWT created with CreateKeyBindingJWT.
+func CreatePresentationWithKeyBinding(jwtAndDisclosures []byte, disclosuresToPresent []int, holderKey gocrypto.PrivateKey, alg jwa.SignatureAlgorithm, audience, nonce string) ([]byte, error) {
+	presentation := CreatePresentation(jwtAndDisclosures, disclosuresToPresent, nil)
+	kbJWT, err := CreateKeyBindingJWT(presentation, holderKey, alg, audience, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return append(presentation, kbJWT...), nil
+}
+
+// verifyKeyBinding verifies the Key Binding JWT of a presentation as specified in
+// https://www.ietf.org/archive/id/draft-ietf-oauth-selective-disclosure-jwt-07.html#name-verification-by-the-verifier
+// The presentation is the Combined Format for Presentation without the Key Binding JWT.
+func verifyKeyBinding(presentation []byte, kbJWT string, sdToken jwt.Token, hashAlg HashFunc, verificationOptions VerificationOptions) error {
+	// If Key Binding JWT is not provided, the Verifier MUST reject the Presentation.
+	if len(kbJWT) == 0 {
+		return errors.New("holder binding required, but key binding jwt not found")
+	}
+
+	// Determine the public key for the Holder from the SD-JWT.
+	var holderKey any
+	if verificationOptions.ResolveHolderKey != nil {
+		holderKey = verificationOptions.ResolveHolderKey(sdToken)
+	} else {
+		confirmationKey, err := GetConfirmationKey(sdToken)
+		if err != nil {
+			return errors.Wrap(err, "getting holder key")
+		}
+		holderKey = confirmationKey
+	}
+	if holderKey == nil {
+		return errors.New("holder key not found")
+	}
+
+	// Ensure that a signing algorithm was used that was deemed secure for the application. The none algorithm MUST
+	// NOT be accepted. The typ header must be kb+jwt.
+	msg, err := jws.Parse([]byte(kbJWT))
+	if err != nil {
+		return errors.Wrap(err, "parsing key binding jwt")
+	}
+	if len(msg.Signatures()) != 1 {
+		return errors.New("key binding jwt must have exactly one signature")
+	}
+	headers := msg.Signatures()[0].ProtectedHeaders()
+	if headers.Type() != KeyBindingJWTType {
+		return errors.Errorf("key binding jwt typ<%s> must be %s", headers.Type(), KeyBindingJWTType)
+	}
+	alg := headers.Algorithm()
+	if alg == jwa.NoSignature || alg == "" {
+		return errors.New("key binding jwt must be signed")
+	}
+
+	// Validate the signature over the Key Binding JWT.
+	// Check that the Key Binding JWT is valid using nbf, iat, and exp claims, if provided in the Key Binding JWT.
+	kbToken, err := jwt.Parse([]byte(kbJWT), jwt.WithKey(alg, holderKey), jwt.WithValidate(true))
+	if err != nil {
+		return errors.Wrap(err, "parsing and validating key binding jwt")
+	}
+	if kbToken.IssuedAt().IsZero() {
+		return errors.New("iat must be present in key binding jwt")
+	}
+
+	// Determine that the Key Binding JWT is bound to the current transaction and was created for this Verifier
+	// (replay protection) by checking its nonce and aud.
+	nonce, ok := kbToken.Get(nonceClaimName)
+	if !ok {
+		return errors.New("nonce must be present in key binding jwt")
+	}
+	if nonce != verificationOptions.DesiredNonce {
+		return errors.New("nonce found does not match desiredNonce")
+	}
+	audienceFound := false
+	for _, audience := range kbToken.Audience() {
+		if audience == verificationOptions.DesiredAudience {
+			audienceFound = true
+			break
+		}
+	}
+	if !audienceFound {
+		return errors.New("desired audience not found")
+	}
+
+	// Check the sd_hash claim matches the digest of the SD-JWT and Disclosures presented with it.
+	sdHash, ok := kbToken.Get(sdHashClaimName)
+	if !ok {
+		return errors.New("sd_hash must be present in key binding jwt")
+	}
+	if sdHash != presentationDigest(presentation, hashAlg) {
+		return errors.New("sd_hash does not match the presentation")
+	}
+	return nil
+}
+
+// presentationDigest returns the base64url-encoded digest of a Combined Format for Presentation without a Key
+// Binding JWT, which is used as the sd_hash claim.
+func presentationDigest(presentation []byte, hashAlg HashFunc) string {
+	return base64.RawURLEncoding.EncodeToString(hashAlg(presentation))
+}
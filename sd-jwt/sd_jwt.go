@@ -429,8 +429,8 @@ type VerificationOptions struct {
 	DesiredNonce, DesiredAudience string
 
 	// Function that goes from a token, to the public key of the holder bound to the confirmation claim. The key will
-	// be used for integrity checking.
-	// Needed only when HolderBindingOption == VerifyHolderBinding.
+	// be used for integrity checking. If it is not set, the key is taken from the `cnf` claim of the SD-JWT.
+	// Used only when HolderBindingOption == VerifyHolderBinding.
 	ResolveHolderKey func(jwt.Token) gocrypto.PublicKey
 }
 
@@ -479,44 +479,10 @@ func VerifySDPresentation(presentation []byte, verificationOptions VerificationO
 	}
 
 	if verificationOptions.HolderBindingOption == VerifyHolderBinding {
-		// If Holder Binding JWT is not provided, the Verifier MUST reject the Presentation.
-		holderBindingJWT := sdParts[len(presentation)-1]
-		if len(holderBindingJWT) == 0 {
-			return nil, errors.New("holder binding required, but holder binding JWT not found")
-		}
-
-		// Determine the public key for the Holder from the SD-JWT.
-		holderKey := verificationOptions.ResolveHolderKey(sdToken)
-
-		// Ensure that a signing algorithm was used that was deemed secure for the application. Refer to [RFC8725], Sections 3.1 and 3.2 for details. The none algorithm MUST NOT be accepted.
-		// TODO(https://github.com/TBD54566975/ssi-sdk/issues/377): support holder binding properly as specified in RFC7800. Alg should be coming from CNF.
-		holderBindingAlg := jwa.ES256K
-
-		// Validate the signature over the Holder Binding JWT.
-		// Check that the Holder Binding JWT is valid using nbf, iat, and exp claims, if provided in the Holder Binding JWT.
-		// Determine that the Holder Binding JWT is bound to the current transaction and was created for this Verifier (replay protection). This is usually achieved by a nonce and aud field within the Holder Binding JWT.
-		holderBindingToken, err := jwt.Parse([]byte(holderBindingJWT), jwt.WithKey(holderBindingAlg, holderKey), jwt.WithValidate(true))
-		if err != nil {
-			return nil, errors.Wrap(err, "parsing and validating holder binding jwt")
-		}
-
-		nonce, ok := holderBindingToken.Get("nonce")
-		if !ok {
-			return nil, errors.New("nonce must be present in holder binding jwt")
-		}
-		if nonce != verificationOptions.DesiredNonce {
-			return nil, errors.New("nonce found does not match desiredNonce")
-		}
-
-		audienceFound := false
-		for _, audience := range holderBindingToken.Audience() {
-			if audience == verificationOptions.DesiredAudience {
-				audienceFound = true
-				break
-			}
-		}
-		if !audienceFound {
-			return nil, errors.New("desired audience not found")
+		// The Key Binding JWT signs over the SD-JWT and the Disclosures, i.e. everything up to and including the last ~
+		kbJWT := sdParts[n]
+		if err := verifyKeyBinding(presentation[:len(presentation)-len(kbJWT)], kbJWT, sdToken, hashAlg, verificationOptions); err != nil {
+			return nil, errors.Wrap(err, "verifying key binding")
 		}
 	}
 	return tokenClaims, nil
@@ -191,7 +191,7 @@ func createSigner(t *testing.T) *jwx.Signer {
 	issuerKID := expandedIssuerDID.VerificationMethod[0].ID
 	assert.NotEmpty(t, issuerKID)
 
-	issuerSigner, err := jwx.NewJWXSigner(issuerDID.String(), issuerKID, issuerPrivKey)
+	issuerSigner, err := jwx.NewJWXSigner(issuerDID.String(), &issuerKID, issuerPrivKey)
 	assert.NoError(t, err)
 	return issuerSigner
 }
@@ -646,3 +646,108 @@ func TestVerifyIssuance(t *testing.T) {
 		assert.ErrorContains(t, err, fmt.Sprintf("digest %q not found", fakeDisclosure.Digest(sha256Digest)))
 	})
 }
+
+func TestVerifySDPresentation_KeyBinding(t *testing.T) {
+	issuerSigner := createSigner(t)
+	publicKeyJWK := issuerSigner.ToPublicKeyJWK()
+	issuerKey, err := publicKeyJWK.ToPublicKey()
+	assert.NoError(t, err)
+	holderPublicKey, holderKey, err := crypto.GenerateKeyByKeyType(crypto.P256)
+	assert.NoError(t, err)
+
+	claims, err := AddConfirmationKey([]byte(`{"given_name": "John", "family_name": "Doe"}`), holderPublicKey)
+	assert.NoError(t, err)
+	sdjwtSigner := SDJWTSigner{
+		disclosureFactory: disclosureFactory{saltGen: &mockGenerator{}},
+		signer:            &lestratSigner{*issuerSigner},
+	}
+	jwtAndDisclosures, err := sdjwtSigner.BlindAndSign(claims, map[string]BlindOption{
+		"given_name":  FlatBlindOption{},
+		"family_name": FlatBlindOption{},
+	})
+	assert.NoError(t, err)
+	disclosureIndices, err := SelectDisclosures(jwtAndDisclosures, map[string]struct{}{"given_name": {}})
+	assert.NoError(t, err)
+
+	verificationOptions := VerificationOptions{
+		HolderBindingOption: VerifyHolderBinding,
+		Alg:                 issuerSigner.ALG,
+		IssuerKey:           issuerKey,
+		DesiredNonce:        "my_sample_nonce",
+		DesiredAudience:     "my_intended_aud",
+	}
+
+	t.Run("valid key binding", func(tt *testing.T) {
+		sdPresentation, err := CreatePresentationWithKeyBinding(jwtAndDisclosures, disclosureIndices, holderKey, jwa.ES256, "my_intended_aud", "my_sample_nonce")
+		assert.NoError(tt, err)
+
+		processedPayload, err := VerifySDPresentation(sdPresentation, verificationOptions)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "John", processedPayload["given_name"])
+		assert.NotContains(tt, processedPayload, "family_name")
+		assert.Contains(tt, processedPayload, "cnf")
+
+		resolvedOptions := verificationOptions
+		resolvedOptions.ResolveHolderKey = func(jwt.Token) gocrypto.PublicKey {
+			return holderPublicKey
+		}
+		_, err = VerifySDPresentation(sdPresentation, resolvedOptions)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("missing key binding", func(tt *testing.T) {
+		sdPresentation := CreatePresentation(jwtAndDisclosures, disclosureIndices, nil)
+		_, err := VerifySDPresentation(sdPresentation, verificationOptions)
+		assert.ErrorContains(tt, err, "key binding jwt not found")
+	})
+
+	t.Run("wrong nonce or audience", func(tt *testing.T) {
+		sdPresentation, err := CreatePresentationWithKeyBinding(jwtAndDisclosures, disclosureIndices, holderKey, jwa.ES256, "my_intended_aud", "other_nonce")
+		assert.NoError(tt, err)
+		_, err = VerifySDPresentation(sdPresentation, verificationOptions)
+		assert.ErrorContains(tt, err, "nonce found does not match desiredNonce")
+
+		sdPresentation, err = CreatePresentationWithKeyBinding(jwtAndDisclosures, disclosureIndices, holderKey, jwa.ES256, "other_aud", "my_sample_nonce")
+		assert.NoError(tt, err)
+		_, err = VerifySDPresentation(sdPresentation, verificationOptions)
+		assert.ErrorContains(tt, err, "desired audience not found")
+	})
+
+	t.Run("key binding for other disclosures", func(tt *testing.T) {
+		presentation := CreatePresentation(jwtAndDisclosures, disclosureIndices, nil)
+		kbJWT, err := CreateKeyBindingJWT(presentation, holderKey, jwa.ES256, "my_intended_aud", "my_sample_nonce")
+		assert.NoError(tt, err)
+
+		allIndices, err := SelectDisclosures(jwtAndDisclosures, map[string]struct{}{"given_name": {}, "family_name": {}})
+		assert.NoError(tt, err)
+		sdPresentation := CreatePresentation(jwtAndDisclosures, allIndices, kbJWT)
+		_, err = VerifySDPresentation(sdPresentation, verificationOptions)
+		assert.ErrorContains(tt, err, "sd_hash does not match the presentation")
+	})
+
+	t.Run("key binding signed by another key", func(tt *testing.T) {
+		otherKey, _, err := key.GenerateDIDKey(crypto.P256)
+		assert.NoError(tt, err)
+		sdPresentation, err := CreatePresentationWithKeyBinding(jwtAndDisclosures, disclosureIndices, otherKey, jwa.ES256, "my_intended_aud", "my_sample_nonce")
+		assert.NoError(tt, err)
+		_, err = VerifySDPresentation(sdPresentation, verificationOptions)
+		assert.ErrorContains(tt, err, "parsing and validating key binding jwt")
+	})
+
+	t.Run("key binding without kb+jwt type", func(tt *testing.T) {
+		presentation := CreatePresentation(jwtAndDisclosures, disclosureIndices, nil)
+		token := jwt.New()
+		assert.NoError(tt, token.Set("nonce", "my_sample_nonce"))
+		kbJWT, err := jwt.Sign(token, jwt.WithKey(jwa.ES256, holderKey))
+		assert.NoError(tt, err)
+		_, err = VerifySDPresentation(append(presentation, kbJWT...), verificationOptions)
+		assert.ErrorContains(tt, err, "must be kb+jwt")
+	})
+
+	t.Run("create key binding errors", func(tt *testing.T) {
+		_, err := CreateKeyBindingJWT([]byte("somejwt~disclosure~kbjwt"), holderKey, jwa.ES256, "my_intended_aud", "my_sample_nonce")
+		assert.ErrorContains(tt, err, "must end with a ~")
+		_, err = CreateKeyBindingJWT([]byte("somejwt~"), holderKey, jwa.ES256, "", "my_sample_nonce")
+		assert.ErrorContains(tt, err, "audience and nonce are required")
+	})
+}
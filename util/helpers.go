@@ -58,16 +58,23 @@ var w3idCitizenshipV1 string
 //go:embed known_contexts/w3_ns_odrl.json
 var w3NamespaceODRL string
 
-func NewLDProcessor() (*LDProcessor, error) {
-	// JSON LD processing
-	proc := ld.NewJsonLdProcessor()
+//go:embed known_contexts/w3id_vc_status_list_2021_v1.json
+var w3idVCStatusList2021V1 string
 
+func NewLDProcessor() (*LDProcessor, error) {
 	// Initialize a new doc loader with caching capability
 	// LDProcessor is expected to be re-used for multiple json-ld operations
 	docLoader, err := NewLDDocumentLoader()
 	if err != nil {
 		return nil, err
 	}
+	return NewLDProcessorWithDocumentLoader(docLoader), nil
+}
+
+// NewLDProcessorWithDocumentLoader creates an LDProcessor which resolves contexts using the provided loader
+func NewLDProcessorWithDocumentLoader(docLoader ld.DocumentLoader) *LDProcessor {
+	// JSON LD processing
+	proc := ld.NewJsonLdProcessor()
 	options := ld.NewJsonLdOptions("")
 	options.Format = "application/n-quads"
 	options.Algorithm = "URDNA2015"
@@ -77,7 +84,23 @@ func NewLDProcessor() (*LDProcessor, error) {
 	return &LDProcessor{
 		JsonLdProcessor: proc,
 		JsonLdOptions:   options,
-	}, nil
+	}
+}
+
+// GetKnownContexts returns the JSON-LD contexts bundled with this library, keyed by URL
+func GetKnownContexts() map[string]string {
+	return map[string]string{
+		"https://www.w3.org/2018/credentials/v1":          w3c2018CredentialsV1,
+		"https://www.w3.org/2018/credentials/examples/v1": w3c2018CredentialsExamplesV1,
+		"https://www.w3.org/ns/did/v1":                    w3cNamespaceDIDV1,
+		"https://w3c.github.io/vc-di-bbs/contexts/v1":     w3cVCDIBBSV1,
+		"https://w3id.org/security/suites/jws-2020/v1":    w3cJWS2020V1,
+		"https://w3id.org/security/v1":                    w3idSecurityV1,
+		"https://w3id.org/security/v2":                    w3idSecurityV2,
+		"https://w3id.org/citizenship/v1":                 w3idCitizenshipV1,
+		"https://www.w3.org/ns/odrl.jsonld":               w3NamespaceODRL,
+		"https://w3id.org/vc/status-list/2021/v1":         w3idVCStatusList2021V1,
+	}
 }
 
 func NewLDDocumentLoader() (*ld.CachingDocumentLoader, error) {
@@ -85,32 +108,10 @@ func NewLDDocumentLoader() (*ld.CachingDocumentLoader, error) {
 	docLoader := ld.NewCachingDocumentLoader(rfcDocLoader)
 
 	// We cache the contexts we know we'll use over and over.
-	if err := preloadContext(docLoader, w3c2018CredentialsV1, "https://www.w3.org/2018/credentials/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3c2018CredentialsExamplesV1, "https://www.w3.org/2018/credentials/examples/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3cNamespaceDIDV1, "https://www.w3.org/ns/did/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3cVCDIBBSV1, "https://w3c.github.io/vc-di-bbs/contexts/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3cJWS2020V1, "https://w3id.org/security/suites/jws-2020/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3idSecurityV1, "https://w3id.org/security/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3idSecurityV2, "https://w3id.org/security/v2"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3idCitizenshipV1, "https://w3id.org/citizenship/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3NamespaceODRL, "https://www.w3.org/ns/odrl.jsonld"); err != nil {
-		return nil, err
+	for url, contents := range GetKnownContexts() {
+		if err := preloadContext(docLoader, contents, url); err != nil {
+			return nil, err
+		}
 	}
 	return docLoader, nil
 }
@@ -162,6 +163,12 @@ func LDNormalize(document any) (any, error) {
 	return processor.Normalize(document, processor.GetOptions())
 }
 
+// LDNormalizeWithDocumentLoader runs URDNA2015 normalization, resolving contexts using the provided loader
+func LDNormalizeWithDocumentLoader(document any, docLoader ld.DocumentLoader) (any, error) {
+	processor := NewLDProcessorWithDocumentLoader(docLoader)
+	return processor.Normalize(document, processor.GetOptions())
+}
+
 // LDFrame runs https://www.w3.org/TR/json-ld11-framing/ to transform the data in a document according to its frame
 func LDFrame(document any, frame any) (any, error) {
 	docAny := document
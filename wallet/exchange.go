@@ -0,0 +1,100 @@
+package wallet
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/parsing"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// FindForPresentationDefinition returns the stored credentials which can fulfill each input descriptor of a
+// presentation definition, keyed by the ID of the input descriptor. The credentials of each input descriptor are
+// ranked by their ScoreInputDescriptor score, highest first. Input descriptors which no stored credential can fulfill
+// have no credentials.
+func FindForPresentationDefinition(ctx context.Context, store CredentialStore, def exchange.PresentationDefinition) (map[string][]StoredCredential, error) {
+	creds, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matches := make(map[string][]StoredCredential, len(def.InputDescriptors))
+	for _, id := range def.InputDescriptors {
+		matches[id.ID] = nil
+		var scores []float64
+		for _, cred := range creds {
+			if score, ok := ScoreInputDescriptor(cred, id); ok {
+				matches[id.ID] = append(matches[id.ID], cred)
+				scores = append(scores, score)
+			}
+		}
+		sort.Stable(rankedCredentials{creds: matches[id.ID], scores: scores})
+	}
+	return matches, nil
+}
+
+// MatchesInputDescriptor returns whether a stored credential can fulfill an input descriptor: it must be in one of
+// the input descriptor's formats, match a path of each of its non-optional fields with data passing the field's
+// filter, and satisfy a required subject_is_issuer constraint
+// https://identity.foundation/presentation-exchange/#input-evaluation
+func MatchesInputDescriptor(cred StoredCredential, id exchange.InputDescriptor) bool {
+	_, ok := ScoreInputDescriptor(cred, id)
+	return ok
+}
+
+// ScoreInputDescriptor returns whether a stored credential can fulfill an input descriptor, as MatchesInputDescriptor
+// does, and if so its score: the fraction of the input descriptor's fields, optional or not, which it matches
+func ScoreInputDescriptor(cred StoredCredential, id exchange.InputDescriptor) (float64, bool) {
+	if id.Format != nil {
+		var formats []string
+		switch cred.Format() {
+		case exchange.SDJWTVC.CredentialFormat():
+			formats = []string{exchange.SDJWTVC.String()}
+		case exchange.JWTVC.CredentialFormat():
+			formats = []string{exchange.JWT.String(), exchange.JWTVC.String()}
+		default:
+			formats = []string{exchange.LDP.String(), exchange.LDPVC.String()}
+		}
+		formatValues := id.Format.FormatValues()
+		if !slices.ContainsFunc(formats, func(f string) bool { return util.Contains(f, formatValues) }) {
+			return 0, false
+		}
+	}
+	if id.Constraints == nil {
+		return 1, true
+	}
+
+	credJSON, err := parsing.ToCredentialJSONMap(cred.Raw())
+	if err != nil {
+		return 0, false
+	}
+	score, ok := exchange.ScoreClaim(id.Constraints.Fields, credJSON)
+	if !ok {
+		return 0, false
+	}
+
+	if subjectIsIssuer := id.Constraints.SubjectIsIssuer; subjectIsIssuer != nil && *subjectIsIssuer == exchange.Required {
+		subject, ok := cred.Credential.CredentialSubject[credential.VerifiableCredentialIDProperty]
+		if !ok || subject != cred.Credential.IssuerID() {
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// rankedCredentials sorts credentials by their scores, highest first
+type rankedCredentials struct {
+	creds  []StoredCredential
+	scores []float64
+}
+
+func (r rankedCredentials) Len() int { return len(r.creds) }
+
+func (r rankedCredentials) Less(i, j int) bool { return r.scores[i] > r.scores[j] }
+
+func (r rankedCredentials) Swap(i, j int) {
+	r.creds[i], r.creds[j] = r.creds[j], r.creds[i]
+	r.scores[i], r.scores[j] = r.scores[j], r.scores[i]
+}
@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+)
+
+func TestFindForPresentationDefinition(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCredentialStore()
+
+	degree, err := NewStoredCredential(getTestCredential("urn:uuid:degree", "did:example:university", "UniversityDegreeCredential"))
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, *degree))
+
+	_, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	signer, err := jwx.NewJWXSigner("did:example:dmv", nil, privKey)
+	require.NoError(t, err)
+	token, err := integrity.SignVerifiableCredentialJWT(*signer, getTestCredential("urn:uuid:license", "did:example:dmv", "DriversLicenseCredential"))
+	require.NoError(t, err)
+	license, err := NewStoredCredential(string(token))
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, *license))
+
+	def := exchange.PresentationDefinition{
+		ID: "test-definition",
+		InputDescriptors: []exchange.InputDescriptor{
+			{
+				ID: "any-name",
+				Constraints: &exchange.Constraints{
+					Fields: []exchange.Field{{Path: []string{"$.vc.credentialSubject.name", "$.credentialSubject.name"}}},
+				},
+			},
+			{
+				ID: "license",
+				Constraints: &exchange.Constraints{
+					Fields: []exchange.Field{
+						{
+							Path:   []string{"$.iss", "$.issuer"},
+							Filter: &exchange.Filter{Type: "string", Pattern: "^did:example:dmv$"},
+						},
+						{Path: []string{"$.vc.credentialSubject.age"}, Optional: true},
+					},
+				},
+			},
+			{
+				ID: "ranked",
+				Constraints: &exchange.Constraints{
+					Fields: []exchange.Field{
+						{Path: []string{"$.vc.credentialSubject.name", "$.credentialSubject.name"}},
+						{
+							Path:     []string{"$.iss", "$.issuer"},
+							Filter:   &exchange.Filter{Type: "string", Const: "did:example:dmv"},
+							Optional: true,
+						},
+					},
+				},
+			},
+			{
+				ID:     "ldp-only",
+				Format: &exchange.ClaimFormat{LDPVC: &exchange.LDPType{ProofType: []cryptosuite.SignatureType{"JsonWebSignature2020"}}},
+			},
+			{
+				ID: "self-issued",
+				Constraints: &exchange.Constraints{
+					SubjectIsIssuer: exchange.Required.Ptr(),
+					Fields:          []exchange.Field{{Path: []string{"$.credentialSubject.name"}}},
+				},
+			},
+		},
+	}
+
+	matches, err := FindForPresentationDefinition(ctx, store, def)
+	assert.NoError(t, err)
+	ids := func(creds []StoredCredential) []string {
+		var found []string
+		for _, cred := range creds {
+			found = append(found, cred.ID)
+		}
+		return found
+	}
+	assert.Equal(t, []string{"urn:uuid:degree", "urn:uuid:license"}, ids(matches["any-name"]))
+	assert.Equal(t, []string{"urn:uuid:license"}, ids(matches["license"]))
+	assert.Equal(t, []string{"urn:uuid:license", "urn:uuid:degree"}, ids(matches["ranked"]))
+	assert.Equal(t, []string{"urn:uuid:degree"}, ids(matches["ldp-only"]))
+	assert.Contains(t, matches, "self-issued")
+	assert.Empty(t, matches["self-issued"])
+}
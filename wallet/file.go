@@ -0,0 +1,133 @@
+package wallet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+const storedCredentialFileExtension = ".json"
+
+// FileCredentialStore is a CredentialStore holding each credential as a JSON file in a directory. Files are named by
+// the digest of the credential's ID, since IDs are often URIs which are not valid file names.
+type FileCredentialStore struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+var _ CredentialStore = (*FileCredentialStore)(nil)
+
+// NewFileCredentialStore creates a credential store in the given directory, creating the directory if needed
+func NewFileCredentialStore(dir string) (*FileCredentialStore, error) {
+	if dir == "" {
+		return nil, errors.New("directory cannot be empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrapf(err, "creating directory<%s>", dir)
+	}
+	return &FileCredentialStore{dir: dir}, nil
+}
+
+func (f *FileCredentialStore) Put(_ context.Context, cred StoredCredential) error {
+	if cred.ID == "" {
+		return errors.New("credential ID cannot be empty")
+	}
+	credBytes, err := json.Marshal(cred)
+	if err != nil {
+		return errors.Wrap(err, "marshalling credential")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// write to a temporary file first so that a credential is never partially written
+	tmp, err := os.CreateTemp(f.dir, "*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "creating credential file")
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err = tmp.Write(credBytes); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "writing credential file")
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "writing credential file")
+	}
+	if err = os.Rename(tmp.Name(), f.path(cred.ID)); err != nil {
+		return errors.Wrapf(err, "storing credential<%s>", cred.ID)
+	}
+	return nil
+}
+
+func (f *FileCredentialStore) Get(_ context.Context, id string) (*StoredCredential, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return readStoredCredential(f.path(id), id)
+}
+
+func (f *FileCredentialStore) Delete(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.Remove(f.path(id)); err != nil {
+		if os.IsNotExist(err) {
+			return errors.Wrapf(ErrCredentialNotFound, "credential<%s>", id)
+		}
+		return errors.Wrapf(err, "deleting credential<%s>", id)
+	}
+	return nil
+}
+
+func (f *FileCredentialStore) List(_ context.Context) ([]StoredCredential, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading directory<%s>", f.dir)
+	}
+	var creds []StoredCredential
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), storedCredentialFileExtension) {
+			continue
+		}
+		cred, err := readStoredCredential(filepath.Join(f.dir, entry.Name()), entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, *cred)
+	}
+	sortCredentials(creds)
+	return creds, nil
+}
+
+func (f *FileCredentialStore) Query(ctx context.Context, q Query) ([]StoredCredential, error) {
+	creds, err := f.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filterCredentials(creds, q), nil
+}
+
+func (f *FileCredentialStore) path(id string) string {
+	digest := sha256.Sum256([]byte(id))
+	return filepath.Join(f.dir, hex.EncodeToString(digest[:])+storedCredentialFileExtension)
+}
+
+func readStoredCredential(path, id string) (*StoredCredential, error) {
+	credBytes, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Wrapf(ErrCredentialNotFound, "credential<%s>", id)
+		}
+		return nil, errors.Wrapf(err, "reading credential<%s>", id)
+	}
+	var cred StoredCredential
+	if err = json.Unmarshal(credBytes, &cred); err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling credential<%s>", id)
+	}
+	return &cred, nil
+}
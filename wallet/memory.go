@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// MemoryCredentialStore is a CredentialStore holding credentials in memory
+type MemoryCredentialStore struct {
+	mu    sync.RWMutex
+	creds map[string]StoredCredential
+}
+
+var _ CredentialStore = (*MemoryCredentialStore)(nil)
+
+// NewMemoryCredentialStore creates an empty in-memory credential store
+func NewMemoryCredentialStore() *MemoryCredentialStore {
+	return &MemoryCredentialStore{creds: make(map[string]StoredCredential)}
+}
+
+func (m *MemoryCredentialStore) Put(_ context.Context, cred StoredCredential) error {
+	if cred.ID == "" {
+		return errors.New("credential ID cannot be empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.creds[cred.ID] = cred
+	return nil
+}
+
+func (m *MemoryCredentialStore) Get(_ context.Context, id string) (*StoredCredential, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cred, ok := m.creds[id]
+	if !ok {
+		return nil, errors.Wrapf(ErrCredentialNotFound, "credential<%s>", id)
+	}
+	return &cred, nil
+}
+
+func (m *MemoryCredentialStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.creds[id]; !ok {
+		return errors.Wrapf(ErrCredentialNotFound, "credential<%s>", id)
+	}
+	delete(m.creds, id)
+	return nil
+}
+
+func (m *MemoryCredentialStore) List(_ context.Context) ([]StoredCredential, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	creds := make([]StoredCredential, 0, len(m.creds))
+	for _, cred := range m.creds {
+		creds = append(creds, cred)
+	}
+	sortCredentials(creds)
+	return creds, nil
+}
+
+func (m *MemoryCredentialStore) Query(ctx context.Context, q Query) ([]StoredCredential, error) {
+	creds, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filterCredentials(creds, q), nil
+}
@@ -0,0 +1,169 @@
+package wallet
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/oliveagle/jsonpath"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/credential/parsing"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// ErrCredentialNotFound is returned when a credential is not in a store
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// CredentialStore holds the credentials of a wallet, and finds those matching a query
+type CredentialStore interface {
+	// Put adds a credential to the store, replacing any credential with the same ID
+	Put(ctx context.Context, cred StoredCredential) error
+	// Get returns the credential with the given ID, or ErrCredentialNotFound
+	Get(ctx context.Context, id string) (*StoredCredential, error)
+	// Delete removes the credential with the given ID, or returns ErrCredentialNotFound
+	Delete(ctx context.Context, id string) error
+	// List returns all credentials in the store, ordered by ID
+	List(ctx context.Context) ([]StoredCredential, error)
+	// Query returns the credentials matching a query, ordered by ID
+	Query(ctx context.Context, q Query) ([]StoredCredential, error)
+}
+
+// StoredCredential is a credential held in a wallet. Credentials may be stored as their data model, or as the
+// VC-JWT or SD-JWT they were issued as, which is kept so that it can be presented with its signature.
+type StoredCredential struct {
+	ID string `json:"id"`
+	// Credential is the credential's data model, which is parsed from the token of JWT credentials
+	Credential credential.VerifiableCredential `json:"credential"`
+	// Token is the VC-JWT of a credential issued as a JWT, or the SD-JWT, with all its disclosures, of a credential
+	// issued as an SD-JWT
+	Token string `json:"token,omitempty"`
+}
+
+// NewStoredCredential prepares a credential of any format supported by parsing.ToCredential to be stored. Its ID
+// is the credential's id, or a new UUID if it does not have one.
+func NewStoredCredential(genericCred any) (*StoredCredential, error) {
+	if genericCred == nil {
+		return nil, errors.New("credential cannot be empty")
+	}
+	_, token, cred, err := parsing.ToCredential(genericCred)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing credential")
+	}
+	stored := StoredCredential{ID: cred.ID, Credential: *cred}
+	var raw string
+	switch typedCred := genericCred.(type) {
+	case string:
+		raw = typedCred
+	case []byte:
+		raw = string(typedCred)
+	}
+	if token != nil || integrity.IsVerifiableCredentialSDJWT(raw) {
+		stored.Token = raw
+	}
+	if stored.ID == "" {
+		stored.ID = uuid.NewString()
+	}
+	return &stored, nil
+}
+
+// Raw returns the credential as it should be presented: its token for JWT and SD-JWT credentials, and its data model
+// otherwise
+func (s StoredCredential) Raw() any {
+	if s.Token != "" {
+		return s.Token
+	}
+	return s.Credential
+}
+
+// Format returns the presentation exchange format of the credential: vc+sd-jwt for SD-JWT credentials, jwt_vc for
+// JWT credentials, and ldp_vc otherwise
+func (s StoredCredential) Format() exchange.CredentialFormat {
+	switch {
+	case s.Token == "":
+		return exchange.LDPVC.CredentialFormat()
+	case integrity.IsVerifiableCredentialSDJWT(s.Token):
+		return exchange.SDJWTVC.CredentialFormat()
+	default:
+		return exchange.JWTVC.CredentialFormat()
+	}
+}
+
+// Query selects credentials by their properties. All non-empty properties of a query must match.
+type Query struct {
+	// Types the credential must have all of
+	Types []string
+	// Issuer is the ID of the credential's issuer
+	Issuer string
+	// SchemaID is the ID of the credential's credentialSchema
+	SchemaID string
+	// JSONPaths must each resolve to a value in the JSON representation of the credential, e.g.
+	// $.credentialSubject.degree for a credential stored as its data model, or $.vc.credentialSubject.degree for a
+	// credential stored as a JWT
+	JSONPaths []string
+}
+
+// Matches returns whether a stored credential matches the query
+func (q Query) Matches(cred StoredCredential) bool {
+	if len(q.Types) > 0 {
+		types, err := util.InterfaceToStrings(cred.Credential.Type)
+		if err != nil {
+			return false
+		}
+		for _, t := range q.Types {
+			if !util.Contains(t, types) {
+				return false
+			}
+		}
+	}
+	if q.Issuer != "" && cred.Credential.IssuerID() != q.Issuer {
+		return false
+	}
+	if q.SchemaID != "" && (cred.Credential.CredentialSchema == nil || cred.Credential.CredentialSchema.ID != q.SchemaID) {
+		return false
+	}
+	if len(q.JSONPaths) > 0 {
+		credJSON, err := parsing.ToCredentialJSONMap(cred.Raw())
+		if err != nil {
+			return false
+		}
+		return matchesPaths(credJSON, q.JSONPaths)
+	}
+	return true
+}
+
+// matchesPaths returns whether every path resolves against the same subject of the credential
+func matchesPaths(credJSON map[string]any, paths []string) bool {
+	for _, subjectJSON := range exchange.SubjectViews(credJSON) {
+		matched := true
+		for _, path := range paths {
+			if _, err := jsonpath.JsonPathLookup(subjectJSON, path); err != nil {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// filterCredentials returns the credentials matching a query, ordered by ID
+func filterCredentials(creds []StoredCredential, q Query) []StoredCredential {
+	var matched []StoredCredential
+	for _, cred := range creds {
+		if q.Matches(cred) {
+			matched = append(matched, cred)
+		}
+	}
+	sortCredentials(matched)
+	return matched
+}
+
+func sortCredentials(creds []StoredCredential) {
+	sort.Slice(creds, func(i, j int) bool { return creds[i].ID < creds[j].ID })
+}
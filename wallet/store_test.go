@@ -0,0 +1,131 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+func TestCredentialStores(t *testing.T) {
+	fileStore, err := NewFileCredentialStore(t.TempDir())
+	require.NoError(t, err)
+	stores := map[string]CredentialStore{
+		"memory": NewMemoryCredentialStore(),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(tt *testing.T) {
+			ctx := context.Background()
+			degree, err := NewStoredCredential(getTestCredential("urn:uuid:degree", "did:example:university", "UniversityDegreeCredential"))
+			assert.NoError(tt, err)
+			license, err := NewStoredCredential(getTestCredential("urn:uuid:license", "did:example:dmv", "DriversLicenseCredential"))
+			assert.NoError(tt, err)
+			assert.NoError(tt, store.Put(ctx, *degree))
+			assert.NoError(tt, store.Put(ctx, *license))
+
+			got, err := store.Get(ctx, "urn:uuid:degree")
+			assert.NoError(tt, err)
+			assert.Equal(tt, degree.ID, got.ID)
+			assert.Equal(tt, degree.Credential.IssuerID(), got.Credential.IssuerID())
+
+			all, err := store.List(ctx)
+			assert.NoError(tt, err)
+			assert.Len(tt, all, 2)
+			assert.Equal(tt, "urn:uuid:degree", all[0].ID)
+
+			found, err := store.Query(ctx, Query{Types: []string{"DriversLicenseCredential"}})
+			assert.NoError(tt, err)
+			require.Len(tt, found, 1)
+			assert.Equal(tt, "urn:uuid:license", found[0].ID)
+
+			found, err = store.Query(ctx, Query{Issuer: "did:example:university", JSONPaths: []string{"$.credentialSubject.name"}})
+			assert.NoError(tt, err)
+			require.Len(tt, found, 1)
+			assert.Equal(tt, "urn:uuid:degree", found[0].ID)
+
+			found, err = store.Query(ctx, Query{SchemaID: "https://example.com/schemas/unknown.json"})
+			assert.NoError(tt, err)
+			assert.Empty(tt, found)
+
+			assert.NoError(tt, store.Delete(ctx, "urn:uuid:degree"))
+			_, err = store.Get(ctx, "urn:uuid:degree")
+			assert.ErrorIs(tt, err, ErrCredentialNotFound)
+			assert.ErrorIs(tt, store.Delete(ctx, "urn:uuid:degree"), ErrCredentialNotFound)
+
+			assert.ErrorContains(tt, store.Put(ctx, StoredCredential{}), "credential ID cannot be empty")
+		})
+	}
+}
+
+func TestNewStoredCredential(t *testing.T) {
+	t.Run("data model credential", func(tt *testing.T) {
+		cred := getTestCredential("", "did:example:university", "UniversityDegreeCredential")
+		stored, err := NewStoredCredential(cred)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, stored.ID)
+		assert.Empty(tt, stored.Token)
+		assert.Equal(tt, cred, stored.Raw())
+		assert.Equal(tt, exchange.LDPVC.CredentialFormat(), stored.Format())
+	})
+
+	t.Run("JWT credential", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		signer, err := jwx.NewJWXSigner("did:example:university", nil, privKey)
+		require.NoError(tt, err)
+		token, err := integrity.SignVerifiableCredentialJWT(*signer, getTestCredential("urn:uuid:degree", "did:example:university", "UniversityDegreeCredential"))
+		require.NoError(tt, err)
+
+		stored, err := NewStoredCredential(string(token))
+		assert.NoError(tt, err)
+		assert.Equal(tt, "urn:uuid:degree", stored.ID)
+		assert.Equal(tt, string(token), stored.Raw())
+		assert.Equal(tt, "did:example:university", stored.Credential.IssuerID())
+		assert.Equal(tt, exchange.JWTVC.CredentialFormat(), stored.Format())
+	})
+
+	t.Run("SD-JWT credential", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		signer, err := jwx.NewJWXSigner("did:example:university", nil, privKey)
+		require.NoError(tt, err)
+		cred := getTestCredential("urn:uuid:degree", "did:example:university", "UniversityDegreeCredential")
+		cred.Context = []any{credential.VerifiableCredentialsV2LinkedDataContext}
+		token, err := integrity.SignVerifiableCredentialSDJWT(*signer, cred, "name")
+		require.NoError(tt, err)
+
+		stored, err := NewStoredCredential(string(token))
+		assert.NoError(tt, err)
+		assert.Equal(tt, string(token), stored.Raw())
+		assert.Equal(tt, "Alice", stored.Credential.CredentialSubject["name"])
+		assert.Equal(tt, exchange.SDJWTVC.CredentialFormat(), stored.Format())
+	})
+
+	t.Run("empty credential", func(tt *testing.T) {
+		_, err := NewStoredCredential(nil)
+		assert.ErrorContains(tt, err, "credential cannot be empty")
+	})
+}
+
+func getTestCredential(id, issuer, credType string) credential.VerifiableCredential {
+	return credential.VerifiableCredential{
+		Context:      []any{credential.VerifiableCredentialsLinkedDataContext},
+		ID:           id,
+		Type:         []any{credential.VerifiableCredentialType, credType},
+		Issuer:       issuer,
+		IssuanceDate: "2023-01-01T00:00:00Z",
+		CredentialSubject: map[string]any{
+			"id":   "did:example:holder",
+			"name": "Alice",
+		},
+	}
+}